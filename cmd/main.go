@@ -4,18 +4,8 @@ import (
 	"treex/treex/cmd"
 )
 
-var (
-	version   = "dev"
-	commit    = "unknown"
-	buildDate = "unknown"
-)
-
+// Version information is set on treex/treex/cmd by the release build's ldflags
 func main() {
-	// Set version information for the CLI
-	cmd.Version = version
-	cmd.Commit = commit
-	cmd.BuildDate = buildDate
-
 	// Execute the root command
 	cmd.Execute()
 }
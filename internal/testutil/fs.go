@@ -81,3 +81,17 @@ func SimpleTestTree() map[string]interface{} {
 func (fs *TestFS) SetFileTime(path string, modTime time.Time) error {
 	return fs.Chtimes(path, modTime, modTime)
 }
+
+// OpenHookFS calls OnOpen before every Open, letting tests react to the walk reaching a path
+// (for example to cancel a context part way through a traversal)
+type OpenHookFS struct {
+	afero.Fs
+	OnOpen func(name string)
+}
+
+func (fs *OpenHookFS) Open(name string) (afero.File, error) {
+	if fs.OnOpen != nil {
+		fs.OnOpen(name)
+	}
+	return fs.Fs.Open(name)
+}
@@ -0,0 +1,21 @@
+package testutil
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	gitplugin "treex/treex/plugins/git"
+)
+
+// MustInitRepo creates an empty git repository whose worktree is root, kept in memory
+// like the rest of the test filesystem
+func (fs *TestFS) MustInitRepo(root string) *git.Repository {
+	if err := fs.MkdirAll(root, 0755); err != nil {
+		panic(fmt.Sprintf("failed to create %s: %v", root, err))
+	}
+	repo, err := gitplugin.InitRepository(fs.Fs, root)
+	if err != nil {
+		panic(fmt.Sprintf("failed to init repository at %s: %v", root, err))
+	}
+	return repo
+}
@@ -3,7 +3,7 @@ package testutil_test
 import (
 	"testing"
 
-	"treex/treex/internal/testutil"
+	"treex/internal/testutil"
 )
 
 func TestTreeHelpers(t *testing.T) {
@@ -0,0 +1,190 @@
+// Package treex is the supported programmatic API for embedding treex in other Go tools.
+//
+// It is a thin facade over the internal packages (tree building, the infofile plugin
+// and rendering), so callers depend on one import and a small set of functions:
+//
+//	tree, err := treex.BuildAnnotatedTree(afero.NewOsFs(), ".", treex.DefaultOptions())
+//	if err != nil {
+//		return err
+//	}
+//	for _, annotation := range treex.CollectAnnotations(tree) {
+//		fmt.Println(annotation.Path, annotation.Notes)
+//	}
+//	return treex.Render(os.Stdout, tree, treex.FormatPlain)
+//
+// Paths in trees and annotations are relative to the root passed to BuildAnnotatedTree
+// and always use forward slashes.
+package treex
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	core "treex/treex"
+	"treex/treex/pathutil"
+	"treex/treex/plugins/infofile"
+	"treex/treex/query"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+// Tree is a built tree: its root node plus build statistics
+type Tree = core.TreeResult
+
+// Node is a file or directory in a Tree
+type Node = types.Node
+
+// Issue is a problem found in a .info file by Validate
+type Issue = infofile.Issue
+
+// Format selects the output produced by Render
+type Format = rendering.OutputFormat
+
+// Output formats accepted by Render
+const (
+	FormatPlain = rendering.FormatPlain // Text tree without colors
+	FormatTerm  = rendering.FormatTerm  // Text tree with terminal colors
+	FormatJSON  = rendering.FormatJSON  // Nested JSON document
+)
+
+// FormatRenderer renders trees in an output format added with RegisterRenderer
+type FormatRenderer = rendering.FormatRenderer
+
+// RegisterRenderer adds a custom output format, named with the "x-" prefix (e.g.
+// "x-orgchart"), to Render and to the treex command's --format
+// Call it from an init function, before rendering or running the command.
+func RegisterRenderer(renderer FormatRenderer) error {
+	return rendering.RegisterRenderer(renderer)
+}
+
+// Options controls which entries BuildAnnotatedTree includes
+type Options struct {
+	MaxDepth        int      // Maximum depth to traverse (0 = no limit)
+	Excludes        []string // Gitignore-style patterns to exclude
+	BuiltinIgnores  bool     // Skip VCS directories, build artifacts and similar
+	IncludeHidden   bool     // Include entries whose names start with "."
+	DirectoriesOnly bool     // Only include directories
+}
+
+// Annotation is an annotated path in a Tree
+type Annotation struct {
+	Path  string `json:"path"`
+	Notes string `json:"notes"`
+}
+
+// DefaultOptions returns the options used by the treex command without flags
+func DefaultOptions() Options {
+	defaults := core.DefaultTreeConfig(".")
+	return Options{
+		MaxDepth:        defaults.MaxDepth,
+		Excludes:        []string{},
+		BuiltinIgnores:  defaults.BuiltinIgnores,
+		IncludeHidden:   defaults.IncludeHidden,
+		DirectoriesOnly: defaults.DirectoriesOnly,
+	}
+}
+
+// BuildAnnotatedTree builds the tree rooted at root on fs and attaches the annotations
+// found in .info files. A nil fs uses the OS filesystem.
+func BuildAnnotatedTree(fs afero.Fs, root string, opts Options) (*Tree, error) {
+	return BuildAnnotatedTreeContext(context.Background(), fs, root, opts)
+}
+
+// BuildAnnotatedTreeContext is BuildAnnotatedTree with cancellation
+// A walk canceled part way returns the entries collected so far (Tree.Partial) with ctx's error
+func BuildAnnotatedTreeContext(ctx context.Context, fs afero.Fs, root string, opts Options) (*Tree, error) {
+	if fs == nil {
+		fs = afero.NewOsFs()
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path %q: %w", root, err)
+	}
+	info, err := fs.Stat(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %q: %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("cannot read %q: not a directory", root)
+	}
+
+	// Build inside a filesystem rooted at the directory, so node paths and
+	// annotations are resolved relative to it
+	config := core.DefaultTreeConfig("/")
+	config.Filesystem = afero.NewBasePathFs(fs, absRoot)
+	config.MaxDepth = opts.MaxDepth
+	config.ExcludeGlobs = opts.Excludes
+	config.BuiltinIgnores = opts.BuiltinIgnores
+	config.IncludeHidden = opts.IncludeHidden
+	config.DirectoriesOnly = opts.DirectoriesOnly
+
+	return core.BuildTreeContext(ctx, config)
+}
+
+// CollectAnnotations returns the annotated entries of a tree in tree order
+func CollectAnnotations(tree *Tree) []Annotation {
+	annotations := []Annotation{}
+	if tree == nil {
+		return annotations
+	}
+
+	var walk func(node *Node)
+	walk = func(node *Node) {
+		if annotation := node.GetAnnotation(); annotation != nil && annotation.Notes != "" {
+			annotations = append(annotations, Annotation{Path: pathutil.Normalize(node.Path), Notes: annotation.Notes})
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	if tree.Root != nil {
+		walk(tree.Root)
+	}
+	return annotations
+}
+
+// Select returns the entries of a tree matching selector, in tree order. Selectors are
+// the ones of "treex query", e.g. dir[name=internal] > file[ext=go][annotated=false].
+func Select(tree *Tree, selector string) ([]*Node, error) {
+	q, err := query.ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil || tree.Root == nil {
+		return nil, nil
+	}
+	return query.Find(tree.Root, q), nil
+}
+
+// Render writes a tree to w in the given format, including annotation notes
+// Text output is not wrapped, so it is stable regardless of the terminal
+func Render(w io.Writer, tree *Tree, format Format) error {
+	return RenderContext(context.Background(), w, tree, format)
+}
+
+// RenderContext is Render with cancellation; nothing is written once ctx is canceled
+func RenderContext(ctx context.Context, w io.Writer, tree *Tree, format Format) error {
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format:    format,
+		Writer:    w,
+		ShowNotes: true,
+		Width:     -1,
+	})
+	return renderer.RenderTreeContext(ctx, tree)
+}
+
+// AddAnnotation adds or replaces the annotation for path (relative to root) in the
+// .info file of its parent directory. Returns the .info path relative to root.
+func AddAnnotation(fs afero.Fs, root, path, notes string) (string, error) {
+	return infofile.AddAnnotation(fs, root, path, notes)
+}
+
+// Validate reports .info entries below root that annotate missing paths, have no
+// text, or repeat a path
+func Validate(fs afero.Fs, root string) ([]Issue, error) {
+	return infofile.Validate(fs, root)
+}
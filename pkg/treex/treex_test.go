@@ -0,0 +1,147 @@
+package treex_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/pkg/treex"
+	"treex/treex/rendering"
+)
+
+// newProject creates an annotated project at /project in memory
+func newProject(t *testing.T) afero.Fs {
+	t.Helper()
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":     "README.md  Project overview\nsrc  Source code\n",
+		"README.md": "# project",
+		"src": map[string]interface{}{
+			".info":   "main.go  Entry point\n",
+			"main.go": "package main",
+		},
+		"node_modules": map[string]interface{}{"dep.js": "module.exports = {}"},
+	})
+	return fs
+}
+
+func TestBuildAnnotatedTree(t *testing.T) {
+	fs := newProject(t)
+
+	tree, err := treex.BuildAnnotatedTree(fs, "/project", treex.DefaultOptions())
+	require.NoError(t, err)
+
+	assert.Equal(t, []treex.Annotation{
+		{Path: "README.md", Notes: "Project overview"},
+		{Path: "src", Notes: "Source code"},
+		{Path: "src/main.go", Notes: "Entry point"},
+	}, treex.CollectAnnotations(tree))
+
+	for _, child := range tree.Root.Children {
+		assert.NotEqual(t, "node_modules", child.Name, "built-in ignores apply by default")
+	}
+}
+
+func TestBuildAnnotatedTreeOptions(t *testing.T) {
+	fs := newProject(t)
+
+	opts := treex.DefaultOptions()
+	opts.DirectoriesOnly = true
+	opts.BuiltinIgnores = false
+	opts.Excludes = []string{"node_modules"}
+
+	tree, err := treex.BuildAnnotatedTree(fs, "/project", opts)
+	require.NoError(t, err)
+	require.Len(t, tree.Root.Children, 1)
+	assert.Equal(t, "src", tree.Root.Children[0].Name)
+}
+
+func TestBuildAnnotatedTreeErrors(t *testing.T) {
+	fs := newProject(t)
+
+	_, err := treex.BuildAnnotatedTree(fs, "/missing", treex.DefaultOptions())
+	assert.Error(t, err)
+
+	_, err = treex.BuildAnnotatedTree(fs, "/project/README.md", treex.DefaultOptions())
+	assert.ErrorContains(t, err, "not a directory")
+}
+
+func TestSelect(t *testing.T) {
+	fs := newProject(t)
+	require.NoError(t, afero.WriteFile(fs, "/project/src/util.go", []byte("package main"), 0644))
+	tree, err := treex.BuildAnnotatedTree(fs, "/project", treex.DefaultOptions())
+	require.NoError(t, err)
+
+	nodes, err := treex.Select(tree, "dir[name=src] > file[ext=go][annotated=false]")
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	assert.Equal(t, "src/util.go", nodes[0].Path)
+
+	_, err = treex.Select(tree, "file[")
+	assert.Error(t, err)
+}
+
+func TestRender(t *testing.T) {
+	fs := newProject(t)
+	tree, err := treex.BuildAnnotatedTree(fs, "/project", treex.DefaultOptions())
+	require.NoError(t, err)
+
+	var plain bytes.Buffer
+	require.NoError(t, treex.Render(&plain, tree, treex.FormatPlain))
+	assert.Contains(t, plain.String(), "main.go")
+	assert.Contains(t, plain.String(), "Entry point")
+
+	var out bytes.Buffer
+	require.NoError(t, treex.Render(&out, tree, treex.FormatJSON))
+	assert.True(t, json.Valid(out.Bytes()))
+}
+
+// namesFormat lists the root's children, one per line
+type namesFormat struct{}
+
+func (namesFormat) Format() treex.Format { return "x-names" }
+
+func (namesFormat) Description() string { return "Top-level entry names" }
+
+func (namesFormat) Render(ctx context.Context, config rendering.RenderConfig, tree *treex.Tree) error {
+	for _, child := range tree.Root.Children {
+		fmt.Fprintln(config.Writer, child.Name)
+	}
+	return nil
+}
+
+// Registered once per test binary, as an embedding program's init function would
+var registerNames = treex.RegisterRenderer(namesFormat{})
+
+func TestRegisterRenderer(t *testing.T) {
+	require.NoError(t, registerNames)
+	assert.ErrorContains(t, treex.RegisterRenderer(namesFormat{}), "already registered")
+
+	tree, err := treex.BuildAnnotatedTree(newProject(t), "/project", treex.DefaultOptions())
+	require.NoError(t, err)
+	var out bytes.Buffer
+	require.NoError(t, treex.Render(&out, tree, "x-names"))
+	assert.Equal(t, ".info\nREADME.md\nsrc\n", out.String())
+}
+
+func TestAddAnnotationAndValidate(t *testing.T) {
+	fs := newProject(t)
+
+	infoPath, err := treex.AddAnnotation(fs, "/project", "src/main.go", "Program entry point")
+	require.NoError(t, err)
+	assert.Equal(t, "src/.info", infoPath)
+
+	issues, err := treex.Validate(fs, "/project")
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	tree, err := treex.BuildAnnotatedTree(fs, "/project", treex.DefaultOptions())
+	require.NoError(t, err)
+	assert.Contains(t, treex.CollectAnnotations(tree), treex.Annotation{Path: "src/main.go", Notes: "Program entry point"})
+}
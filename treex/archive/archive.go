@@ -0,0 +1,290 @@
+// Package archive presents tar and zip archives as read-only filesystems so trees
+// (and the .info files packed with them) can be shown without extracting to disk.
+//
+// Open loads the archive entries into an in-memory afero filesystem rooted at "/".
+// Directories missing from the archive are created from the paths of their entries;
+// links and special files are skipped, and entries escaping the root are rejected.
+// Only the names, modes and sizes of files are kept: a tree does not need their content,
+// and a large release or a gzip bomb would not fit in memory. The files treex reads to
+// draw the tree (.info files, .gitignore, .treex.toml) are the exception, up to
+// MaxContentSize each; opening any other file fails.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// Root is the directory of the returned filesystem that holds the archive contents
+const Root = "/"
+
+// MaxContentSize is the largest file whose content Open keeps; larger ones are listed only
+const MaxContentSize = 1 << 20
+
+// suffixes maps recognized archive extensions to their format
+var suffixes = []struct {
+	suffix string
+	format string
+}{
+	{".tar.gz", "tgz"},
+	{".tgz", "tgz"},
+	{".tar", "tar"},
+	{".zip", "zip"},
+}
+
+// IsArchive reports whether name has an archive extension Open understands
+func IsArchive(name string) bool {
+	return formatOf(name) != ""
+}
+
+// formatOf returns the archive format for name, or "" when it is not an archive
+func formatOf(name string) string {
+	lower := strings.ToLower(name)
+	for _, s := range suffixes {
+		if strings.HasSuffix(lower, s.suffix) {
+			return s.format
+		}
+	}
+	return ""
+}
+
+// Open reads the archive at name from fs and returns its contents as a read-only filesystem
+func Open(fs afero.Fs, name string) (afero.Fs, error) {
+	format := formatOf(name)
+	if format == "" {
+		return nil, fmt.Errorf("unsupported archive %q (expected .tar, .tar.gz, .tgz or .zip)", name)
+	}
+
+	file, err := fs.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	contents := &metadataFs{Fs: afero.NewMemMapFs(), sizes: make(map[string]int64)}
+	switch format {
+	case "zip":
+		info, err := file.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		err = loadZip(contents, file, info.Size())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+	case "tgz":
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		defer func() { _ = gz.Close() }()
+		if err := loadTar(contents, gz); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+	default:
+		if err := loadTar(contents, file); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+	}
+	return afero.NewReadOnlyFs(contents), nil
+}
+
+// loadTar records the directories and regular files of a tar stream in fs
+func loadTar(fs *metadataFs, r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = addDir(fs, header.Name, header.FileInfo().Mode())
+		case tar.TypeReg:
+			err = addFile(fs, header.Name, header.FileInfo().Mode(), header.Size, tr)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// loadZip records the directories and regular files of a zip archive in fs
+func loadZip(fs *metadataFs, r io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	for _, entry := range zr.File {
+		mode := entry.Mode()
+		switch {
+		case mode.IsDir():
+			err = addDir(fs, entry.Name, mode)
+		case mode.IsRegular():
+			err = addZipFile(fs, entry)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addZipFile records one zip entry in fs; its content is only decompressed when kept
+func addZipFile(fs *metadataFs, entry *zip.File) error {
+	size := int64(entry.UncompressedSize64)
+	if !keepsContent(entry.Name, size) {
+		return addFile(fs, entry.Name, entry.Mode(), size, nil)
+	}
+	rc, err := entry.Open()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", entry.Name, err)
+	}
+	defer func() { _ = rc.Close() }()
+	return addFile(fs, entry.Name, entry.Mode(), size, rc)
+}
+
+// addDir creates the directory for an archive entry
+func addDir(fs *metadataFs, name string, mode os.FileMode) error {
+	target, err := entryPath(name)
+	if err != nil {
+		return err
+	}
+	return fs.MkdirAll(target, mode.Perm()|0700)
+}
+
+// addFile records an archive entry of size bytes, creating its parent directories; its
+// content is copied from content when keepsContent allows, and recorded as missing otherwise
+func addFile(fs *metadataFs, name string, mode os.FileMode, size int64, content io.Reader) error {
+	target, err := entryPath(name)
+	if err != nil {
+		return err
+	}
+	if err := fs.MkdirAll(path.Dir(target), 0755); err != nil {
+		return err
+	}
+	file, err := fs.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	if content == nil || !keepsContent(name, size) {
+		fs.sizes[target] = size
+		return file.Close()
+	}
+	delete(fs.sizes, target)
+	if _, err := io.Copy(file, io.LimitReader(content, MaxContentSize)); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return file.Close()
+}
+
+// keepsContent reports whether Open keeps the content of the file name of size bytes
+func keepsContent(name string, size int64) bool {
+	if size > MaxContentSize {
+		return false
+	}
+	base := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	return strings.HasSuffix(base, ".info") || base == ".info.local" || base == ".gitignore" || base == ".treex.toml"
+}
+
+// metadataFs is the filesystem of an archive: a MemMapFs holding its directories and
+// kept files, and empty placeholders for the other files, whose sizes it reports
+type metadataFs struct {
+	afero.Fs
+	sizes map[string]int64 // Archive size of the files without content, by path
+}
+
+// errNoContent is returned when opening a file whose content was not loaded
+var errNoContent = errors.New("content not loaded from the archive")
+
+func (m *metadataFs) Open(name string) (afero.File, error) {
+	return m.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (m *metadataFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	clean := path.Clean("/" + filepath.ToSlash(name))
+	if _, listed := m.sizes[clean]; listed && flag == os.O_RDONLY {
+		return nil, &os.PathError{Op: "open", Path: name, Err: errNoContent}
+	}
+	file, err := m.Fs.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &metadataFile{File: file, fs: m, path: clean}, nil
+}
+
+func (m *metadataFs) Stat(name string) (os.FileInfo, error) {
+	info, err := m.Fs.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.sized(path.Clean("/"+filepath.ToSlash(name)), info), nil
+}
+
+// sized returns info with the archive size of the file at p, when its content was dropped
+func (m *metadataFs) sized(p string, info os.FileInfo) os.FileInfo {
+	if size, listed := m.sizes[p]; listed {
+		return sizedInfo{FileInfo: info, size: size}
+	}
+	return info
+}
+
+// metadataFile reports archive sizes for the entries of a directory
+type metadataFile struct {
+	afero.File
+	fs   *metadataFs
+	path string
+}
+
+func (f *metadataFile) Stat() (os.FileInfo, error) {
+	info, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return f.fs.sized(f.path, info), nil
+}
+
+func (f *metadataFile) Readdir(count int) ([]os.FileInfo, error) {
+	infos, err := f.File.Readdir(count)
+	for i, info := range infos {
+		infos[i] = f.fs.sized(path.Join(f.path, info.Name()), info)
+	}
+	return infos, err
+}
+
+// sizedInfo is the FileInfo of a file whose content was dropped, with its archive size
+type sizedInfo struct {
+	os.FileInfo
+	size int64
+}
+
+func (i sizedInfo) Size() int64 { return i.size }
+
+// entryPath maps an archive entry name to its absolute path below Root
+func entryPath(name string) (string, error) {
+	slashed := strings.ReplaceAll(name, "\\", "/")
+	for _, part := range strings.Split(slashed, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("archive entry %q escapes the archive root", name)
+		}
+	}
+	return path.Clean("/" + slashed), nil
+}
@@ -0,0 +1,195 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex"
+	"treex/treex/archive"
+	"treex/treex/types"
+
+	_ "treex/treex/plugins/infofile"
+)
+
+// entries are the archive members used by the tests; names ending in "/" are directories
+var entries = []struct{ name, content string }{
+	{"release/", ""},
+	{"release/.info", "bin  Executables\nbin/tool  The tool\n"},
+	{"release/bin/tool", "#!/bin/sh\n"}, // bin/ has no entry of its own
+	{"release/README.md", "# Release\n"},
+}
+
+func tarBytes(t *testing.T, names []struct{ name, content string }) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range names {
+		header := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		if e.name[len(e.name)-1] == '/' {
+			header.Typeflag, header.Mode, header.Size = tar.TypeDir, 0755, 0
+		}
+		require.NoError(t, tw.WriteHeader(header))
+		_, err := tw.Write([]byte(e.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func zipBytes(t *testing.T) []byte {
+	return zipBytesOf(t, entries)
+}
+
+func zipBytesOf(t *testing.T, names []struct{ name, content string }) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range names {
+		w, err := zw.Create(e.name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(e.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestIsArchive(t *testing.T) {
+	for name, want := range map[string]bool{
+		"release.tar.gz": true,
+		"RELEASE.TGZ":    true,
+		"release.tar":    true,
+		"dist/app.zip":   true,
+		"notes.gz":       false,
+		"src":            false,
+	} {
+		assert.Equal(t, want, archive.IsArchive(name), name)
+	}
+}
+
+func TestOpenFormats(t *testing.T) {
+	tarData := tarBytes(t, entries)
+	fs := testutil.NewTestFS()
+	require.NoError(t, afero.WriteFile(fs, "/out/release.tar", tarData, 0644))
+	require.NoError(t, afero.WriteFile(fs, "/out/release.tar.gz", gzipBytes(t, tarData), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/out/release.zip", zipBytes(t), 0644))
+
+	for _, name := range []string{"/out/release.tar", "/out/release.tar.gz", "/out/release.zip"} {
+		t.Run(name, func(t *testing.T) {
+			contents, err := archive.Open(fs, name)
+			require.NoError(t, err)
+
+			info, err := contents.Stat("/release/bin")
+			require.NoError(t, err)
+			assert.True(t, info.IsDir(), "implicit directories are created")
+
+			data, err := afero.ReadFile(contents, "/release/.info")
+			require.NoError(t, err)
+			assert.Equal(t, "bin  Executables\nbin/tool  The tool\n", string(data))
+
+			assert.Error(t, afero.WriteFile(contents, "/release/new", nil, 0644), "archives are read-only")
+		})
+	}
+}
+
+func TestOpenKeepsOnlyMetadata(t *testing.T) {
+	large := strings.Repeat("x", archive.MaxContentSize+1)
+	members := []struct{ name, content string }{
+		{"release/.info", "README.md  Overview\n"},
+		{"release/README.md", "# Release\n"},
+		{"release/data.bin", large},
+		{"release/big/.info", large},
+	}
+	fs := testutil.NewTestFS()
+	require.NoError(t, afero.WriteFile(fs, "/release.tgz", gzipBytes(t, tarBytes(t, members)), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/release.zip", zipBytesOf(t, members), 0644))
+
+	for _, name := range []string{"/release.tgz", "/release.zip"} {
+		t.Run(name, func(t *testing.T) {
+			contents, err := archive.Open(fs, name)
+			require.NoError(t, err)
+
+			info, err := contents.Stat("/release/data.bin")
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(large)), info.Size(), "sizes come from the archive")
+			_, err = contents.Open("/release/data.bin")
+			assert.ErrorContains(t, err, "content not loaded from the archive")
+			_, err = afero.ReadFile(contents, "/release/big/.info")
+			assert.Error(t, err, ".info files over the cap are listed only")
+
+			listing, err := afero.ReadDir(contents, "/release")
+			require.NoError(t, err)
+			sizes := make(map[string]int64)
+			for _, entry := range listing {
+				sizes[entry.Name()] = entry.Size()
+			}
+			assert.Equal(t, int64(len(large)), sizes["data.bin"])
+			assert.Equal(t, int64(len("# Release\n")), sizes["README.md"])
+
+			data, err := afero.ReadFile(contents, "/release/.info")
+			require.NoError(t, err)
+			assert.Equal(t, "README.md  Overview\n", string(data))
+		})
+	}
+}
+
+func TestOpenRejectsEscapingEntries(t *testing.T) {
+	fs := testutil.NewTestFS()
+	data := tarBytes(t, []struct{ name, content string }{{"../evil", "x"}})
+	require.NoError(t, afero.WriteFile(fs, "/evil.tar", data, 0644))
+
+	_, err := archive.Open(fs, "/evil.tar")
+	assert.ErrorContains(t, err, "escapes the archive root")
+}
+
+func TestOpenErrors(t *testing.T) {
+	fs := testutil.NewTestFS()
+	require.NoError(t, afero.WriteFile(fs, "/broken.zip", []byte("not a zip"), 0644))
+
+	_, err := archive.Open(fs, "/broken.zip")
+	assert.Error(t, err)
+	_, err = archive.Open(fs, "/missing.tar")
+	assert.Error(t, err)
+	_, err = archive.Open(fs, "/notes.txt")
+	assert.ErrorContains(t, err, "unsupported archive")
+}
+
+func TestBuildTreeFromArchive(t *testing.T) {
+	fs := testutil.NewTestFS()
+	require.NoError(t, afero.WriteFile(fs, "/release.tgz", gzipBytes(t, tarBytes(t, entries)), 0644))
+	contents, err := archive.Open(fs, "/release.tgz")
+	require.NoError(t, err)
+
+	config := treex.DefaultTreeConfig(archive.Root)
+	config.Filesystem = contents
+	result, err := treex.BuildTree(config)
+	require.NoError(t, err)
+
+	notes := make(map[string]string)
+	var walk func(*types.Node)
+	walk = func(node *types.Node) {
+		if annotation := node.GetAnnotation(); annotation != nil {
+			notes[node.Path] = annotation.Notes
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(result.Root)
+	assert.Equal(t, map[string]string{"release/bin": "Executables", "release/bin/tool": "The tool"}, notes)
+}
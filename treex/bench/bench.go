@@ -0,0 +1,109 @@
+// Package bench measures the phases of showing a tree (building it, merging .info
+// annotations and rendering it) on synthetic or real trees, as a baseline for
+// performance work.
+//
+// Generate writes a synthetic tree of a given size; Run times the phases on any tree.
+// The go test -bench suites of this package use both on 10k, 100k and 1M node trees:
+//
+//	go test ./treex/bench -bench . -benchmem
+//
+// treex bench runs the same measurement on a user's own repository.
+package bench
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/afero"
+	"treex/treex"
+	"treex/treex/plugins/infofile"
+	"treex/treex/rendering"
+)
+
+// Report holds the timings of one measured tree
+type Report struct {
+	Root      string        `json:"root"`
+	Runs      int           `json:"runs"`
+	Nodes     int           `json:"nodes"`     // Entries in the built tree, root excluded
+	Annotated int           `json:"annotated"` // Annotated entries in the built tree
+	Build     time.Duration `json:"build_ns"`  // treex.BuildTree, including annotation enrichment
+	Merge     time.Duration `json:"merge_ns"`  // Merging every .info file (infofile.Gather)
+	Render    time.Duration `json:"render_ns"` // Plain text rendering with annotations
+}
+
+// Total is the time of all measured phases
+func (r Report) Total() time.Duration {
+	return r.Build + r.Merge + r.Render
+}
+
+// Run measures the phases on the tree at root on fs, runs times each, and reports the
+// fastest time of each phase
+func Run(fs afero.Fs, root string, runs int) (Report, error) {
+	report := Report{Root: root, Runs: max(runs, 1)}
+	for i := 0; i < report.Runs; i++ {
+		result, build, err := Build(fs, root)
+		if err != nil {
+			return report, err
+		}
+		merge, err := Merge(fs, root)
+		if err != nil {
+			return report, err
+		}
+		render, err := Render(result)
+		if err != nil {
+			return report, err
+		}
+
+		report.Nodes = result.Stats.TotalFiles + result.Stats.TotalDirectories - 1
+		report.Annotated = result.Stats.Annotated
+		report.Build = fastest(report.Build, build, i)
+		report.Merge = fastest(report.Merge, merge, i)
+		report.Render = fastest(report.Render, render, i)
+	}
+	return report, nil
+}
+
+// Build builds the tree at root the way treex shows it by default
+func Build(fs afero.Fs, root string) (*treex.TreeResult, time.Duration, error) {
+	config := treex.DefaultTreeConfig(root)
+	config.Filesystem = fs
+	start := time.Now()
+	result, err := treex.BuildTree(config)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build %s: %w", root, err)
+	}
+	return result, time.Since(start), nil
+}
+
+// Merge merges every .info file below root
+func Merge(fs afero.Fs, root string) (time.Duration, error) {
+	start := time.Now()
+	if _, err := infofile.Gather(fs, root); err != nil {
+		return 0, fmt.Errorf("failed to merge annotations in %s: %w", root, err)
+	}
+	return time.Since(start), nil
+}
+
+// Render renders result as plain text with annotations, discarding the output
+func Render(result *treex.TreeResult) (time.Duration, error) {
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format:    rendering.FormatPlain,
+		Writer:    io.Discard,
+		ShowNotes: true,
+		Width:     -1,
+	})
+	start := time.Now()
+	if err := renderer.RenderTree(result); err != nil {
+		return 0, fmt.Errorf("failed to render: %w", err)
+	}
+	return time.Since(start), nil
+}
+
+// fastest keeps the lower of the best time so far and the time of run i
+func fastest(best, current time.Duration, run int) time.Duration {
+	if run == 0 || current < best {
+		return current
+	}
+	return best
+}
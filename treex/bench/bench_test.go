@@ -0,0 +1,136 @@
+package bench_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex/bench"
+	"treex/treex/plugins/infofile"
+)
+
+func TestGenerate(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	generated, err := bench.Generate(fs, "/tree", bench.Spec{Nodes: 50, FanOut: 2, Files: 3, Annotated: 0.5})
+	require.NoError(t, err)
+
+	assert.Equal(t, 50, generated.Directories+generated.Files)
+	assert.Equal(t, 25, generated.Annotated)
+	exists, _ := afero.Exists(fs, "/tree/dir1/dir0/file2.go")
+	assert.True(t, exists, "directories are filled breadth first")
+
+	annotations, err := infofile.Gather(fs, "/tree")
+	require.NoError(t, err)
+	assert.Len(t, annotations, generated.Annotated)
+	assert.Equal(t, "Synthetic annotation for file0.go", annotations["/tree/file0.go"].Notes)
+}
+
+func TestGenerateDefaults(t *testing.T) {
+	generated, err := bench.Generate(afero.NewMemMapFs(), "/tree", bench.Spec{Nodes: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, 1000, generated.Directories+generated.Files)
+	assert.Equal(t, 100, generated.Annotated, "one entry in ten is annotated")
+
+	generated, err = bench.Generate(afero.NewMemMapFs(), "/tree", bench.Spec{Nodes: 100, Annotated: -1})
+	require.NoError(t, err)
+	assert.Zero(t, generated.Annotated)
+	assert.Zero(t, generated.InfoFiles)
+}
+
+func TestRun(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	generated, err := bench.Generate(fs, "/tree", bench.Spec{Nodes: 200})
+	require.NoError(t, err)
+
+	report, err := bench.Run(fs, "/tree", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Runs)
+	assert.Equal(t, 200+generated.InfoFiles, report.Nodes, ".info files are entries of the tree")
+	assert.Equal(t, generated.Annotated, report.Annotated)
+	assert.Positive(t, report.Build)
+	assert.Positive(t, report.Render)
+	assert.Equal(t, report.Build+report.Merge+report.Render, report.Total())
+
+	_, err = bench.Run(fs, "/missing", 1)
+	assert.Error(t, err)
+}
+
+// sizes are the synthetic tree sizes of the benchmarks; 1M nodes is skipped with -short
+var sizes = []int{10_000, 100_000, 1_000_000}
+
+// trees caches generated trees across benchmarks, keyed by size
+var trees = map[int]afero.Fs{}
+
+// syntheticTree returns a generated tree of nodes entries rooted at /tree
+func syntheticTree(b *testing.B, nodes int) afero.Fs {
+	if nodes >= 1_000_000 && testing.Short() {
+		b.Skip("1M node tree skipped with -short")
+	}
+	if fs, ok := trees[nodes]; ok {
+		return fs
+	}
+	fs := afero.NewMemMapFs()
+	if _, err := bench.Generate(fs, "/tree", bench.Spec{Nodes: nodes}); err != nil {
+		b.Fatal(err)
+	}
+	trees[nodes] = fs
+	return fs
+}
+
+// sizeName names a benchmark after its tree size: 10k, 100k, 1M
+func sizeName(nodes int) string {
+	if nodes >= 1_000_000 {
+		return fmt.Sprintf("%dM", nodes/1_000_000)
+	}
+	return fmt.Sprintf("%dk", nodes/1_000)
+}
+
+func BenchmarkBuild(b *testing.B) {
+	for _, nodes := range sizes {
+		b.Run(sizeName(nodes), func(b *testing.B) {
+			fs := syntheticTree(b, nodes)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := bench.Build(fs, "/tree"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMerge(b *testing.B) {
+	for _, nodes := range sizes {
+		b.Run(sizeName(nodes), func(b *testing.B) {
+			fs := syntheticTree(b, nodes)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := bench.Merge(fs, "/tree"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRender(b *testing.B) {
+	for _, nodes := range sizes {
+		b.Run(sizeName(nodes), func(b *testing.B) {
+			result, _, err := bench.Build(syntheticTree(b, nodes), "/tree")
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := bench.Render(result); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
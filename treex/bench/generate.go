@@ -0,0 +1,97 @@
+package bench
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// Spec describes a synthetic tree for Generate
+type Spec struct {
+	Nodes     int     // Files and directories to create below the root (.info files not counted)
+	FanOut    int     // Subdirectories per directory (default 8)
+	Files     int     // Files per directory (0 = 16, negative = none)
+	Annotated float64 // Share of entries annotated in .info files, up to 1 (0 = 0.1, negative = none)
+}
+
+// Generated counts what Generate created
+type Generated struct {
+	Directories int
+	Files       int
+	Annotated   int
+	InfoFiles   int
+}
+
+// Generate creates the tree described by spec below root on fs
+// Directories are filled breadth first, each with Files files (file0.go, file1.go, ...)
+// then FanOut subdirectories (dir0, dir1, ...), until spec.Nodes entries exist. Every
+// directory holding an annotated entry gets a .info file, so the same spec always
+// yields the same tree.
+func Generate(fs afero.Fs, root string, spec Spec) (Generated, error) {
+	fanOut, files := spec.FanOut, spec.Files
+	if fanOut <= 0 {
+		fanOut = 8
+	}
+	if files < 0 {
+		files = 0
+	} else if files == 0 {
+		files = 16
+	}
+	annotateEvery := 0
+	switch share := spec.Annotated; {
+	case share == 0:
+		annotateEvery = 10
+	case share > 0:
+		annotateEvery = max(int(1/min(share, 1)+0.5), 1)
+	}
+
+	var generated Generated
+	if err := fs.MkdirAll(root, 0755); err != nil {
+		return generated, fmt.Errorf("failed to create %s: %w", root, err)
+	}
+
+	created := 0
+	queue := []string{root}
+	for len(queue) > 0 && created < spec.Nodes {
+		dir := queue[0]
+		queue = queue[1:]
+
+		var info strings.Builder
+		annotate := func(name string) {
+			if annotateEvery > 0 && created%annotateEvery == 0 {
+				fmt.Fprintf(&info, "%s  Synthetic annotation for %s\n", name, name)
+				generated.Annotated++
+			}
+			created++
+		}
+
+		for i := 0; i < files && created < spec.Nodes; i++ {
+			name := fmt.Sprintf("file%d.go", i)
+			if err := afero.WriteFile(fs, filepath.Join(dir, name), []byte("package synthetic\n"), 0644); err != nil {
+				return generated, fmt.Errorf("failed to create %s: %w", name, err)
+			}
+			generated.Files++
+			annotate(name)
+		}
+		for i := 0; i < fanOut && created < spec.Nodes; i++ {
+			name := fmt.Sprintf("dir%d", i)
+			subdir := filepath.Join(dir, name)
+			if err := fs.Mkdir(subdir, 0755); err != nil {
+				return generated, fmt.Errorf("failed to create %s: %w", subdir, err)
+			}
+			generated.Directories++
+			annotate(name + "/")
+			queue = append(queue, subdir)
+		}
+
+		if info.Len() > 0 {
+			if err := afero.WriteFile(fs, filepath.Join(dir, ".info"), []byte(info.String()), 0644); err != nil {
+				return generated, fmt.Errorf("failed to write .info in %s: %w", dir, err)
+			}
+			generated.InfoFiles++
+		}
+	}
+	return generated, nil
+}
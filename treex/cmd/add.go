@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"treex/treex/plugins/infofile"
+)
+
+var (
+	// addAnnotation is the annotation text applied to every path given to add
+	addAnnotation string
+	// addFromStdin reads path<TAB>annotation lines from stdin instead of arguments
+	addFromStdin bool
+	// addEdit writes the annotation text in $VISUAL or $EDITOR
+	addEdit bool
+	// addDiff prints the .info changes as diffs instead of writing them (--dry-run, --diff)
+	addDiff bool
+)
+
+// addCmd writes annotations for many paths into their nearest .info files
+var addCmd = &cobra.Command{
+	Use:   "add <path|glob>... --annotation <text>",
+	Short: "Annotate paths in their .info files",
+	Long: `Annotate one or more paths. Each entry is written to the .info file of the
+path's parent directory, replacing an existing entry for the same name.
+
+Arguments may be globs ("cmd/*", "**/*_test.go"); quote them so the shell
+does not expand them. With --from-stdin, paths and annotations are read as
+path<TAB>annotation lines instead (blank lines and # comments are skipped).
+With --edit, the annotation is written in $VISUAL or $EDITOR (falling back to
+vi), starting from the current annotation when a single path is given.
+
+Paths are relative to the current directory. Every path is checked before
+any .info file is written.`,
+	Example: `  treex add 'cmd/*' --annotation "CLI entry points"
+  treex add README.md -a "Project overview"
+  treex add --edit internal/scheduler
+  treex add --diff src/api -a "HTTP handlers"
+  git ls-files '*.proto' | sed 's/$/\tProtocol definition/' | treex add --from-stdin`,
+	ValidArgsFunction: completeTreePaths,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAdd(cmd.OutOrStdout(), cmd.InOrStdin(), ".", args)
+	},
+}
+
+func init() {
+	addCmd.Flags().StringVarP(&addAnnotation, "annotation", "a", "", "Annotation text for every matched path")
+	addCmd.Flags().BoolVar(&addFromStdin, "from-stdin", false, "Read path<TAB>annotation lines from stdin")
+	addCmd.Flags().BoolVar(&addEdit, "edit", false, "Write the annotation in $EDITOR")
+	addDiffFlags(addCmd.Flags(), &addDiff)
+	rootCmd.AddCommand(addCmd)
+}
+
+// runAdd collects the annotations requested by targets (or stdin) and writes them below rootPath
+func runAdd(out io.Writer, in io.Reader, rootPath string, targets []string) error {
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+
+	var annotations []infofile.Annotation
+	switch {
+	case addFromStdin:
+		if len(targets) > 0 || addAnnotation != "" || addEdit {
+			return fmt.Errorf("--from-stdin cannot be combined with paths, --annotation or --edit")
+		}
+		if annotations, err = readAnnotationLines(in); err != nil {
+			return err
+		}
+	case len(targets) == 0:
+		return fmt.Errorf("no paths given (use --from-stdin to read them from stdin)")
+	case addEdit && addAnnotation != "":
+		return fmt.Errorf("--edit cannot be combined with --annotation")
+	case !addEdit && strings.TrimSpace(addAnnotation) == "":
+		return fmt.Errorf("--annotation is required (or use --edit)")
+	default:
+		var paths []string
+		for _, target := range targets {
+			matches, err := expandTarget(appFs, absRoot, target)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, matches...)
+		}
+
+		notes := addAnnotation
+		if addEdit {
+			if notes, err = editAnnotation(absRoot, paths); err != nil {
+				return err
+			}
+			if notes == "" {
+				return fmt.Errorf("annotation is empty, nothing written")
+			}
+		}
+		for _, match := range paths {
+			annotations = append(annotations, infofile.Annotation{Path: match, Notes: notes})
+		}
+	}
+
+	if len(annotations) == 0 {
+		return fmt.Errorf("no annotations to add")
+	}
+
+	var written []string
+	write := func(fs afero.Fs) error {
+		written, err = infofile.AddAnnotations(fs, absRoot, annotations)
+		return err
+	}
+	if addDiff {
+		return previewEdit(out, absRoot, write)
+	}
+	if err := withUndo(absRoot, append([]string{"add"}, targets...), write); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Annotated %d paths in %d .info files\n", len(annotations), len(written))
+	for _, infoPath := range written {
+		fmt.Fprintf(out, "  %s\n", infoPath)
+	}
+	return nil
+}
+
+// expandTarget returns the paths below root matching a glob, or the target itself when it
+// has no glob characters. .info files are never matched.
+func expandTarget(fs afero.Fs, root, target string) ([]string, error) {
+	target = filepath.ToSlash(target)
+	if !strings.ContainsAny(target, "*?[{") {
+		return []string{target}, nil
+	}
+
+	pattern := path.Clean(strings.TrimPrefix(target, "./"))
+	if !doublestar.ValidatePattern(pattern) {
+		return nil, fmt.Errorf("invalid glob %q", target)
+	}
+
+	found, err := doublestar.Glob(afero.NewIOFS(afero.NewBasePathFs(fs, root)), pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand %q: %w", target, err)
+	}
+
+	var matches []string
+	for _, match := range found {
+		if path.Base(match) != ".info" {
+			matches = append(matches, match)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no paths match %q", target)
+	}
+	return matches, nil
+}
+
+// readAnnotationLines parses path<TAB>annotation lines
+func readAnnotationLines(in io.Reader) ([]infofile.Annotation, error) {
+	var annotations []infofile.Annotation
+	scanner := bufio.NewScanner(in)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		targetPath, notes, found := strings.Cut(line, "\t")
+		if !found {
+			return nil, fmt.Errorf("stdin line %d: expected path<TAB>annotation", lineNum)
+		}
+		annotations = append(annotations, infofile.Annotation{Path: strings.TrimSpace(targetPath), Notes: notes})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return annotations, nil
+}
+
+// runEditor opens file in the user's editor and waits for it to exit (replaced in tests)
+var runEditor = func(file string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// Editors are often configured with arguments, e.g. "code --wait"
+	args := append(strings.Fields(editor), file)
+	command := exec.Command(args[0], args[1:]...)
+	command.Stdin, command.Stdout, command.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := command.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return nil
+}
+
+// editAnnotation lets the user write the annotation for paths in their editor
+// The template holds the current annotation of a single path; # lines are dropped and the
+// remaining lines joined, as .info entries are single-line
+func editAnnotation(root string, paths []string) (string, error) {
+	var template strings.Builder
+	if len(paths) == 1 {
+		template.WriteString(currentAnnotation(root, paths[0]))
+		template.WriteString("\n")
+	}
+	template.WriteString("\n# Annotation for " + strings.Join(paths, ", ") + "\n")
+	template.WriteString("# Lines starting with '#' are ignored and lines are joined with spaces.\n")
+	template.WriteString("# An empty annotation aborts without writing.\n")
+
+	// The editor is an external process, so the template lives on the OS filesystem
+	file, err := os.CreateTemp("", "treex-annotation-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create annotation template: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(template.String())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write annotation template: %w", err)
+	}
+
+	if err := runEditor(file.Name()); err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read annotation: %w", err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(strings.Fields(strings.Join(lines, " ")), " "), nil
+}
+
+// currentAnnotation returns the entry for target in its parent's .info file, if any
+func currentAnnotation(root, target string) string {
+	target = path.Clean(filepath.ToSlash(target))
+	infoPath := filepath.Join(root, filepath.FromSlash(path.Dir(target)), ".info")
+	content, err := afero.ReadFile(appFs, infoPath)
+	if err != nil {
+		return ""
+	}
+	entries, _ := infofile.Parse(content) // Entries above an oversize line are still found
+	for _, entry := range entries {
+		if path.Clean(entry.Path) == path.Base(target) {
+			return entry.Notes
+		}
+	}
+	return ""
+}
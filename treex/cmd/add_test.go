@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withAddProject points appFs at an in-memory project and resets the add flags afterwards
+func withAddProject(t *testing.T) afero.Fs {
+	t.Helper()
+
+	return withAppFs(t, "/project", map[string]interface{}{
+		"README.md": "# project",
+		"cmd": map[string]interface{}{
+			".info":   "root.go  Old text\n",
+			"root.go": "package cmd",
+			"add.go":  "package cmd",
+			"sub":     map[string]interface{}{"deep.go": "package sub"},
+		},
+	}, func() { addAnnotation, addFromStdin, addEdit, addDiff = "", false, false, false })
+}
+
+func TestAddGlob(t *testing.T) {
+	fs := withAddProject(t)
+	addAnnotation = "CLI entry points"
+
+	var out bytes.Buffer
+	require.NoError(t, runAdd(&out, nil, "/project", []string{"cmd/*.go", "README.md"}))
+	assert.Equal(t, "Annotated 3 paths in 2 .info files\n  .info\n  cmd/.info\n", out.String())
+
+	content, err := afero.ReadFile(fs, "/project/cmd/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "root.go  CLI entry points\nadd.go  CLI entry points\n", string(content))
+
+	content, err = afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "README.md  CLI entry points\n", string(content))
+}
+
+func TestAddDiffWritesNothing(t *testing.T) {
+	fs := withAddProject(t)
+	addAnnotation, addDiff = "Entry point", true
+
+	var out bytes.Buffer
+	require.NoError(t, runAdd(&out, nil, "/project", []string{"cmd/root.go", "README.md"}))
+	assert.Equal(t, `--- /dev/null
++++ b/.info
+@@ -0,0 +1 @@
++README.md  Entry point
+--- a/cmd/.info
++++ b/cmd/.info
+@@ -1 +1 @@
+-root.go  Old text
++root.go  Entry point
+`, out.String())
+
+	exists, _ := afero.Exists(fs, "/project/.info")
+	assert.False(t, exists)
+	content, err := afero.ReadFile(fs, "/project/cmd/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "root.go  Old text\n", string(content))
+	exists, _ = afero.Exists(fs, "/project/.treex")
+	assert.False(t, exists, "previews are not journaled")
+}
+
+func TestAddRecursiveGlobDistributesToNearestInfo(t *testing.T) {
+	fs := withAddProject(t)
+	addAnnotation = "Go source"
+
+	var out bytes.Buffer
+	require.NoError(t, runAdd(&out, nil, "/project", []string{"**/*.go"}))
+
+	content, err := afero.ReadFile(fs, "/project/cmd/sub/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "deep.go  Go source\n", string(content))
+}
+
+func TestAddFromStdin(t *testing.T) {
+	fs := withAddProject(t)
+	addFromStdin = true
+
+	in := strings.NewReader("# generated\ncmd/add.go\tThe add command\r\n\nREADME.md\tOverview\n")
+	var out bytes.Buffer
+	require.NoError(t, runAdd(&out, in, "/project", nil))
+
+	content, err := afero.ReadFile(fs, "/project/cmd/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "root.go  Old text\nadd.go  The add command\n", string(content))
+}
+
+func TestAddErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		annotation string
+		fromStdin  bool
+		edit       bool
+		stdin      string
+		expected   string
+	}{
+		{name: "no paths", annotation: "text", expected: "no paths given"},
+		{name: "no annotation", args: []string{"README.md"}, expected: "--annotation is required"},
+		{name: "glob without matches", args: []string{"*.rs"}, annotation: "text", expected: `no paths match "*.rs"`},
+		{name: "missing path", args: []string{"missing.go"}, annotation: "text", expected: "path does not exist"},
+		{name: "stdin with args", args: []string{"README.md"}, fromStdin: true, expected: "cannot be combined"},
+		{name: "edit with annotation", args: []string{"README.md"}, annotation: "text", edit: true, expected: "--edit cannot be combined"},
+		{name: "stdin with edit", fromStdin: true, edit: true, expected: "cannot be combined"},
+		{name: "stdin without tab", fromStdin: true, stdin: "README.md overview\n", expected: "stdin line 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withAddProject(t)
+			addAnnotation, addFromStdin, addEdit = tt.annotation, tt.fromStdin, tt.edit
+
+			err := runAdd(&bytes.Buffer{}, strings.NewReader(tt.stdin), "/project", tt.args)
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+// withEditor replaces the editor with edit, which receives the template and returns the new content
+func withEditor(t *testing.T, edit func(template string) string) {
+	t.Helper()
+
+	originalEditor := runEditor
+	runEditor = func(file string) error {
+		template, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(file, []byte(edit(string(template))), 0600)
+	}
+	t.Cleanup(func() { runEditor = originalEditor })
+}
+
+func TestAddEdit(t *testing.T) {
+	fs := withAddProject(t)
+	addEdit = true
+
+	var template string
+	withEditor(t, func(content string) string {
+		template = content
+		return "Cobra root command,\nwires every subcommand.\n# ignored comment\n"
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, runAdd(&out, nil, "/project", []string{"cmd/root.go"}))
+	assert.True(t, strings.HasPrefix(template, "Old text\n"), "template starts with the current annotation")
+	assert.Contains(t, template, "# Annotation for cmd/root.go")
+
+	content, err := afero.ReadFile(fs, "/project/cmd/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "root.go  Cobra root command, wires every subcommand.\n", string(content))
+}
+
+func TestAddEditEmptyAborts(t *testing.T) {
+	fs := withAddProject(t)
+	addEdit = true
+	withEditor(t, func(string) string { return "# nothing but comments\n\n" })
+
+	err := runAdd(&bytes.Buffer{}, nil, "/project", []string{"README.md"})
+	assert.ErrorContains(t, err, "annotation is empty")
+
+	exists, _ := afero.Exists(fs, "/project/.info")
+	assert.False(t, exists)
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"treex/treex/bench"
+)
+
+// Options of the bench command
+var (
+	benchRuns       int    // Times each phase runs; the fastest is reported
+	benchJSON       bool   // Output the report as JSON
+	benchGenerate   int    // Measure a synthetic tree of this many entries instead of a path
+	benchCPUProfile string // Write a CPU profile of the runs to this file
+)
+
+// benchCmd times building, merging and rendering a tree, to profile treex on a repository
+var benchCmd = &cobra.Command{
+	Use:    "bench [path]",
+	Short:  "Time building, annotation merging and rendering of a tree",
+	Hidden: true,
+	Long: `Time the phases of showing a tree: building it (with annotation enrichment),
+merging its .info files and rendering it as plain text. Each phase runs
+--runs times and the fastest time is reported.
+
+--generate measures an in-memory synthetic tree of the given size instead,
+and --cpuprofile writes a pprof CPU profile of the runs for go tool pprof.`,
+	Example: `  treex bench                          # Time the current directory
+  treex bench --runs 10 --json src     # Machine-readable timings for src
+  treex bench --generate 100000        # A synthetic 100k entry tree
+  treex bench --cpuprofile cpu.out .   # Profile, then: go tool pprof cpu.out`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootPath := "."
+		if len(args) > 0 {
+			rootPath = args[0]
+		}
+		return runBench(cmd.OutOrStdout(), rootPath)
+	},
+}
+
+func init() {
+	benchCmd.Flags().IntVar(&benchRuns, "runs", 3, "Times each phase runs; the fastest is reported")
+	benchCmd.Flags().BoolVar(&benchJSON, "json", false, "Output the timings as JSON")
+	benchCmd.Flags().IntVar(&benchGenerate, "generate", 0, "Measure a synthetic in-memory tree of this many entries instead of a path")
+	benchCmd.Flags().StringVar(&benchCPUProfile, "cpuprofile", "", "Write a CPU profile of the runs to this file")
+	rootCmd.AddCommand(benchCmd)
+}
+
+// runBench measures the tree at rootPath, or a generated one, and prints the timings
+func runBench(out io.Writer, rootPath string) error {
+	if benchRuns < 1 {
+		return fmt.Errorf("--runs must be at least 1, got %d", benchRuns)
+	}
+
+	fs, root := appFs, rootPath
+	if benchGenerate > 0 {
+		fs, root = afero.NewMemMapFs(), "/synthetic"
+		if _, err := bench.Generate(fs, root, bench.Spec{Nodes: benchGenerate}); err != nil {
+			return err
+		}
+	} else {
+		absRoot, err := filepath.Abs(rootPath)
+		if err != nil {
+			return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+		}
+		if _, err := appFs.Stat(absRoot); err != nil {
+			return fmt.Errorf("cannot access path %q: %w", rootPath, err)
+		}
+		root = absRoot
+	}
+
+	if benchCPUProfile != "" {
+		profile, err := os.Create(benchCPUProfile)
+		if err != nil {
+			return fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		defer func() { _ = profile.Close() }()
+		if err := pprof.StartCPUProfile(profile); err != nil {
+			return fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	report, err := bench.Run(fs, root, benchRuns)
+	if err != nil {
+		return err
+	}
+
+	if benchJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	runs := "runs"
+	if report.Runs == 1 {
+		runs = "run"
+	}
+	fmt.Fprintf(out, "%s: %d entries, %d annotated (fastest of %d %s)\n", report.Root, report.Nodes, report.Annotated, report.Runs, runs)
+	for _, phase := range []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"build", report.Build},
+		{"merge", report.Merge},
+		{"render", report.Render},
+		{"total", report.Total()},
+	} {
+		fmt.Fprintf(out, "  %-7s %10s\n", phase.name, phase.duration.Round(time.Microsecond))
+	}
+	return nil
+}
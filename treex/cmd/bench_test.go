@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/bench"
+)
+
+func TestBench(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info": "src  Sources\n",
+		"src":   map[string]interface{}{"main.go": "package main"},
+	})
+	originalFs := appFs
+	appFs = fs
+	t.Cleanup(func() {
+		appFs = originalFs
+		benchRuns, benchJSON, benchGenerate = 3, false, 0
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, runBench(&out, "/project"))
+		assert.Contains(t, out.String(), "/project: 3 entries, 1 annotated (fastest of 3 runs)\n")
+		assert.Contains(t, out.String(), "  build   ")
+		assert.Contains(t, out.String(), "  total   ")
+	})
+
+	t.Run("generated tree as json", func(t *testing.T) {
+		benchRuns, benchJSON, benchGenerate = 1, true, 100
+		t.Cleanup(func() { benchRuns, benchJSON, benchGenerate = 3, false, 0 })
+
+		var out bytes.Buffer
+		require.NoError(t, runBench(&out, "ignored"))
+		var report bench.Report
+		require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+		assert.Equal(t, "/synthetic", report.Root)
+		assert.Equal(t, 1, report.Runs)
+		assert.GreaterOrEqual(t, report.Nodes, 100)
+		assert.Equal(t, 10, report.Annotated)
+	})
+
+	t.Run("invalid runs", func(t *testing.T) {
+		benchRuns = 0
+		t.Cleanup(func() { benchRuns = 3 })
+		assert.ErrorContains(t, runBench(&bytes.Buffer{}, "/project"), "--runs must be at least 1")
+	})
+}
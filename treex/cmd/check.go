@@ -0,0 +1,411 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"treex/treex/config"
+	"treex/treex/pathutil"
+	gitplugin "treex/treex/plugins/git"
+	"treex/treex/plugins/infofile"
+)
+
+var (
+	// checkStaged validates only the .info files touched by staged changes
+	checkStaged bool
+	// checkChangedPaths names a file ("-" for stdin) listing changed paths to check against
+	checkChangedPaths string
+	// checkFormat selects text, GitHub Actions or JSON output
+	checkFormat string
+	// checkFix removes the entries reported as problems
+	checkFix bool
+	// checkProse also checks annotation text against the [prose] rules of .treex.toml
+	checkProse bool
+)
+
+// checkCmd validates .info files and fails when they have problems
+var checkCmd = &cobra.Command{
+	Use:   "check [path]",
+	Short: "Validate .info files",
+	Long: `Validate the .info files below a path and exit with an error when any entry
+annotates a missing path, has no annotation text, repeats a path already
+annotated in the same file, or links to a missing path: relative markdown
+links ([setup](docs/setup.md), "/" for the root) and code spans that look
+like paths (` + "`scripts/build.sh`" + `) are resolved from the .info file's directory.
+@include lines naming a file that cannot be read, or leading back to a file
+they are included from, fail the check too.
+
+With --staged, only problems the next commit touches are reported: any in a
+staged .info file, and entries anywhere above a staged deletion that annotate
+the deleted path. Working tree content is validated.
+
+With --changed-paths, the same is done for a list of changed paths read from
+a file or stdin ("-"), such as the output of git diff --name-only
+--no-renames in CI. Listed paths missing on disk count as deleted.
+
+With --prose, annotation text is also checked against the [prose] section of
+.treex.toml: min-length, max-length, sentence-case and banned-words, plus
+trailing whitespace, which is always reported:
+
+  [prose]
+  max-length    = 80
+  sentence-case = true
+  banned-words  = ["simply", "obviously"]
+
+With --fix, the offending entries are removed instead; with --staged the
+fixed files are staged again. Broken links, includes and prose problems are
+left to fix by hand.
+
+With --workspace, the .info files of every repository the workspace file
+lists are checked, and problems are named with the repository first.
+
+--format github prints problems as GitHub Actions ::error commands, which
+appear inline on pull requests. --format json prints {"valid", "issues"} as
+described by "treex schema validation".`,
+	Example: `  treex check                # Validate every .info file
+  treex check --staged       # Validate what the next commit touches
+  treex check --fix          # Remove broken entries
+  treex check --prose        # Enforce the writing rules of .treex.toml
+  git diff --name-only --no-renames origin/main... |
+    treex check --changed-paths - --format github`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if workspaceFile != "" && len(args) > 0 {
+			return fmt.Errorf("--workspace cannot be combined with a path")
+		}
+		return paged(cmd, func(out io.Writer) error { return runCheck(out, cmd.InOrStdin(), rootArg(args)) })
+	},
+}
+
+func init() {
+	checkCmd.Flags().BoolVar(&checkStaged, "staged", false, "Only check .info files touched by staged changes")
+	checkCmd.Flags().StringVar(&checkChangedPaths, "changed-paths", "", "File listing changed paths (\"-\" for stdin) to check against")
+	checkCmd.Flags().BoolVar(&checkFix, "fix", false, "Remove the entries reported as problems")
+	checkCmd.Flags().BoolVar(&checkProse, "prose", false, "Also check annotation text against the [prose] rules of .treex.toml")
+	checkCmd.Flags().StringVar(&checkFormat, "format", "text", "Output format: text, github or json")
+	rootCmd.AddCommand(checkCmd)
+}
+
+// runCheck validates (and with --fix repairs) the .info files below rootPath
+func runCheck(out io.Writer, in io.Reader, rootPath string) error {
+	if checkStaged && checkChangedPaths != "" {
+		return fmt.Errorf("--staged cannot be combined with --changed-paths")
+	}
+	if checkFormat != "text" && checkFormat != "github" && checkFormat != "json" {
+		return fmt.Errorf("unknown format %q (use text, github or json)", checkFormat)
+	}
+	if workspaceFile != "" {
+		return runWorkspaceCheck(out)
+	}
+
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+	if info, err := appFs.Stat(absRoot); err != nil || !info.IsDir() {
+		return ioError(fmt.Errorf("cannot check %q: not an accessible directory", rootPath))
+	}
+
+	var issues []infofile.Issue
+	var scope *changeScope
+	switch {
+	case checkStaged:
+		scope, err = stagedScope(absRoot)
+	case checkChangedPaths == "-":
+		scope, err = changedPathsScope(in, absRoot)
+	case checkChangedPaths != "":
+		var file afero.File
+		if file, err = appFs.Open(checkChangedPaths); err != nil {
+			return fmt.Errorf("failed to open changed paths: %w", err)
+		}
+		defer file.Close()
+		scope, err = changedPathsScope(file, absRoot)
+	}
+	if err != nil {
+		return err
+	}
+
+	if scope != nil {
+		found, err := infofile.ValidateFiles(appFs, absRoot, scope.infoFiles)
+		if err != nil {
+			return ioError(err)
+		}
+		for _, issue := range found {
+			if scope.relevant(issue) {
+				issues = append(issues, issue)
+			}
+		}
+	} else if issues, err = infofile.Validate(appFs, absRoot); err != nil {
+		return ioError(err)
+	}
+
+	var proseIssues []infofile.Issue
+	if checkProse {
+		if proseIssues, err = checkAnnotationProse(absRoot, scope); err != nil {
+			return err
+		}
+	}
+
+	issues = append(issues, proseIssues...)
+	reportIssues(out, issues, checkFormat, "treex check")
+
+	// Broken references and prose problems need editing; --fix removes the rest
+	var removable, manual []infofile.Issue
+	for _, issue := range issues {
+		if issue.Removable() {
+			removable = append(removable, issue)
+		} else {
+			manual = append(manual, issue)
+		}
+	}
+	if len(removable) == 0 {
+		return manualFixError(manual)
+	}
+	if !checkFix {
+		err := fmt.Errorf("%d problems in .info files (run treex check --fix to remove them)", len(removable))
+		if manualErr := manualFixError(manual); manualErr != nil {
+			err = fmt.Errorf("%w; %w", err, manualErr)
+		}
+		return issuesError(err)
+	}
+
+	var fixed []string
+	err = withUndo(absRoot, []string{"check", "--fix"}, func(fs afero.Fs) error {
+		fixed, err = infofile.FixIssues(fs, absRoot, removable)
+		return err
+	})
+	if err != nil {
+		return ioError(err)
+	}
+	if scope != nil && scope.repoRoot != "" {
+		staged := make([]string, len(fixed))
+		for i, infoFile := range fixed {
+			rel, err := filepath.Rel(scope.repoRoot, filepath.Join(absRoot, filepath.FromSlash(infoFile)))
+			if err != nil {
+				return err
+			}
+			staged[i] = filepath.ToSlash(rel)
+		}
+		if err := gitplugin.Stage(appFs, scope.repoRoot, staged); err != nil {
+			return err
+		}
+	}
+	if checkFormat != "json" {
+		fmt.Fprintf(out, "Removed %d entries from %d .info files\n", len(removable), len(fixed))
+	}
+	return manualFixError(manual)
+}
+
+// runWorkspaceCheck validates the .info files of every repository of the --workspace
+// file, naming files and paths with the repository name first
+func runWorkspaceCheck(out io.Writer) error {
+	if checkStaged || checkChangedPaths != "" || checkFix {
+		return fmt.Errorf("--workspace cannot be combined with --staged, --changed-paths or --fix")
+	}
+	label, paths, names, err := loadWorkspace()
+	if err != nil {
+		return err
+	}
+
+	var issues []infofile.Issue
+	for i, repoPath := range paths {
+		found, err := infofile.Validate(appFs, repoPath)
+		if err != nil {
+			return ioError(err)
+		}
+		if checkProse {
+			proseIssues, err := checkAnnotationProse(repoPath, nil)
+			if err != nil {
+				return err
+			}
+			found = append(found, proseIssues...)
+		}
+		for _, issue := range found {
+			issue.InfoFile = path.Join(names[i], issue.InfoFile)
+			if issue.Path != "" {
+				issue.Path = path.Join(names[i], issue.Path)
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	reportIssues(out, issues, checkFormat, "treex check")
+	if len(issues) > 0 {
+		return issuesError(fmt.Errorf("%d problems in the .info files of workspace %s", len(issues), label))
+	}
+	return nil
+}
+
+// checkAnnotationProse checks annotation text against the [prose] rules of the project
+// configuration, limited to the .info files scope touches when it is not nil
+func checkAnnotationProse(absRoot string, scope *changeScope) ([]infofile.Issue, error) {
+	cfg, err := config.Load(appFs, absRoot)
+	if err != nil {
+		return nil, err
+	}
+	rules := infofile.ProseRules{
+		MinLength:    cfg.Prose.MinLength,
+		MaxLength:    cfg.Prose.MaxLength,
+		SentenceCase: cfg.Prose.SentenceCase,
+		BannedWords:  cfg.Prose.BannedWords,
+	}
+
+	if scope == nil {
+		return infofile.CheckProse(appFs, absRoot, rules)
+	}
+	found, err := infofile.CheckProseFiles(appFs, absRoot, scope.infoFiles, rules)
+	if err != nil {
+		return nil, err
+	}
+	var issues []infofile.Issue
+	for _, issue := range found {
+		if scope.relevant(issue) {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// manualFixError fails the check for problems --fix leaves alone
+func manualFixError(issues []infofile.Issue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	return issuesError(fmt.Errorf("%d annotations to fix by hand", len(issues)))
+}
+
+// changeScope is what a set of changes touches below the checked root (paths relative to it)
+type changeScope struct {
+	repoRoot  string          // Repository whose index the changes come from ("" for --changed-paths)
+	infoFiles []string        // Changed .info files and the .info files above deletions
+	changed   map[string]bool // Changed .info files, validated in full
+	deleted   map[string]bool // Deleted paths
+}
+
+// relevant reports whether an issue concerns the changes: any problem in a changed .info
+// file, or an entry elsewhere annotating a deleted path
+func (s *changeScope) relevant(issue infofile.Issue) bool {
+	return s.changed[issue.InfoFile] || s.deleted[issue.Path]
+}
+
+// newChangeScope collects the .info files to check for changes relative to the root
+func newChangeScope(changes []gitplugin.StagedChange) *changeScope {
+	scope := &changeScope{changed: make(map[string]bool), deleted: make(map[string]bool)}
+	seen := make(map[string]bool)
+	add := func(infoFile string) {
+		if !seen[infoFile] {
+			seen[infoFile] = true
+			scope.infoFiles = append(scope.infoFiles, infoFile)
+		}
+	}
+	for _, change := range changes {
+		switch {
+		case change.Deleted:
+			scope.deleted[change.Path] = true
+			for dir := path.Dir(change.Path); ; dir = path.Dir(dir) {
+				add(path.Join(dir, ".info"))
+				if dir == "." {
+					break
+				}
+			}
+		case path.Base(change.Path) == ".info":
+			scope.changed[change.Path] = true
+			add(change.Path)
+		}
+	}
+	return scope
+}
+
+// stagedScope finds the repository containing absRoot and what its index touches
+func stagedScope(absRoot string) (*changeScope, error) {
+	repoRoot := gitplugin.RepositoryRoot(appFs, absRoot)
+	if repoRoot == "" {
+		return nil, fmt.Errorf("--staged needs a git repository, none found at %s", absRoot)
+	}
+	staged, err := gitplugin.StagedChanges(appFs, repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := filepath.Rel(repoRoot, absRoot)
+	if err != nil {
+		return nil, err
+	}
+	prefix = filepath.ToSlash(prefix)
+
+	var changes []gitplugin.StagedChange
+	for _, change := range staged {
+		if prefix != "." {
+			if !strings.HasPrefix(change.Path, prefix+"/") {
+				continue
+			}
+			change.Path = strings.TrimPrefix(change.Path, prefix+"/")
+		}
+		changes = append(changes, change)
+	}
+
+	scope := newChangeScope(changes)
+	scope.repoRoot = repoRoot
+	return scope, nil
+}
+
+// changedPathsScope reads a list of changed paths (relative to absRoot, one per line) and
+// treats those missing on disk as deleted
+func changedPathsScope(in io.Reader, absRoot string) (*changeScope, error) {
+	var changes []gitplugin.StagedChange
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		rel := pathutil.Normalize(line)
+		if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+			continue
+		}
+		exists, _ := afero.Exists(appFs, filepath.Join(absRoot, filepath.FromSlash(rel)))
+		changes = append(changes, gitplugin.StagedChange{Path: rel, Deleted: !exists})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read changed paths: %w", err)
+	}
+	return newChangeScope(changes), nil
+}
+
+// reportIssues prints issues one per line, as GitHub annotations titled title when
+// format is "github", or as a validation result document when format is "json"
+func reportIssues(out io.Writer, issues []infofile.Issue, format, title string) {
+	if format == "json" {
+		if issues == nil {
+			issues = []infofile.Issue{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(map[string]interface{}{"valid": len(issues) == 0, "issues": issues})
+		return
+	}
+	for _, issue := range issues {
+		if format == "github" {
+			writeGitHubAnnotation(out, title, issue)
+			continue
+		}
+		fmt.Fprintf(out, "%s:%d: %s: %s\n", issue.InfoFile, issue.Line, issue.Path, issue.Message)
+	}
+}
+
+// writeGitHubAnnotation prints an issue as a GitHub Actions workflow command, which
+// shows up inline on pull requests
+func writeGitHubAnnotation(out io.Writer, title string, issue infofile.Issue) {
+	property := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	message := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	fmt.Fprintf(out, "::error file=%s,line=%d,title=%s::%s\n",
+		property.Replace(issue.InfoFile), issue.Line, property.Replace(title), message.Replace(issue.Path+": "+issue.Message))
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex/schema"
+)
+
+// resetCheckFlags restores the check and hook flags a test changed
+func resetCheckFlags() {
+	checkStaged, checkFix, checkProse, hookFix, hookForce = false, false, false, false, false
+	checkChangedPaths, checkFormat = "", "text"
+}
+
+func TestCheckReportsAndFixes(t *testing.T) {
+	fs := withAppFs(t, "/project", map[string]interface{}{
+		".info":     "README.md  Overview\nmissing.go  Gone\nREADME.md  Again\n",
+		"README.md": "# project",
+	}, resetCheckFlags)
+
+	var out bytes.Buffer
+	err := runCheck(&out, nil, "/project")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 problems")
+	assert.Equal(t, exitIssues, exitCode(err))
+	assert.Equal(t, ".info:2: missing.go: annotated path does not exist\n.info:3: README.md: path already annotated on line 1\n", out.String())
+
+	out.Reset()
+	checkFix = true
+	require.NoError(t, runCheck(&out, nil, "/project"))
+	assert.Contains(t, out.String(), "Removed 2 entries from 1 .info files\n")
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "README.md  Overview\n", string(content))
+
+	out.Reset()
+	checkFix = false
+	require.NoError(t, runCheck(&out, nil, "/project"))
+	assert.Empty(t, out.String())
+}
+
+func TestCheckMissingRoot(t *testing.T) {
+	withAppFs(t, "/", nil, resetCheckFlags)
+
+	var out bytes.Buffer
+	err := runCheck(&out, nil, "/nowhere")
+	assert.Equal(t, exitIO, exitCode(err))
+
+	checkFormat = "yaml"
+	err = runCheck(&out, nil, "/nowhere")
+	assert.Equal(t, exitUsage, exitCode(err))
+}
+
+func TestCheckJSONFormat(t *testing.T) {
+	withAppFs(t, "/project", map[string]interface{}{
+		".info":     "README.md  Overview\nmissing.go  Gone\n",
+		"README.md": "# project",
+	}, resetCheckFlags)
+	checkFormat = "json"
+
+	var out bytes.Buffer
+	require.Error(t, runCheck(&out, nil, "/project"))
+	require.NoError(t, schema.Validate("validation", out.Bytes()), out.String())
+	assert.Contains(t, out.String(), `"type": "missing-path"`)
+
+	out.Reset()
+	checkFix = true
+	require.NoError(t, runCheck(&out, nil, "/project"))
+	require.NoError(t, schema.Validate("validation", out.Bytes()), out.String())
+
+	out.Reset()
+	require.NoError(t, runCheck(&out, nil, "/project"))
+	assert.Equal(t, "{\n  \"issues\": [],\n  \"valid\": true\n}\n", out.String())
+}
+
+func TestCheckStaged(t *testing.T) {
+	fs := withAppFs(t, "/project", map[string]interface{}{
+		".info": "docs/old.md  Old doc\n",
+		"docs":  map[string]interface{}{"old.md": "old", ".info": "gone.md  Unstaged problem\n"},
+	}, resetCheckFlags)
+	repo := fs.MustInitRepo("/project")
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	_, err = worktree.Add(".info")
+	require.NoError(t, err)
+	_, err = worktree.Add("docs/old.md")
+	require.NoError(t, err)
+	_, err = worktree.Commit("Initial commit", &git.CommitOptions{Author: &object.Signature{Name: "Test", Email: "test@example.com"}})
+	require.NoError(t, err)
+
+	_, err = worktree.Remove("docs/old.md")
+	require.NoError(t, err)
+	checkStaged = true
+
+	var out bytes.Buffer
+	require.Error(t, runCheck(&out, nil, "/project"))
+	assert.Equal(t, ".info:1: docs/old.md: annotated path does not exist\n", out.String(),
+		"unstaged problems in docs/.info are not reported")
+
+	out.Reset()
+	checkFix = true
+	require.NoError(t, runCheck(&out, nil, "/project"))
+
+	status, err := worktree.Status()
+	require.NoError(t, err)
+	assert.Equal(t, git.Modified, status.File(".info").Staging, "the fixed .info file is staged again")
+}
+
+func TestHookInstall(t *testing.T) {
+	fs := withAppFs(t, "/repo", map[string]interface{}{
+		".git": map[string]interface{}{"HEAD": "ref: refs/heads/main"},
+		"src":  map[string]interface{}{"main.go": "package main"},
+	}, resetCheckFlags)
+
+	var out bytes.Buffer
+	require.NoError(t, runHookInstall(&out, "/repo/src"))
+	content, err := afero.ReadFile(fs, "/repo/.git/hooks/pre-commit")
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\n"+hookMarker+"\nexec treex check --staged\n", string(content))
+	info, err := fs.Stat("/repo/.git/hooks/pre-commit")
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	hookFix = true
+	require.NoError(t, runHookInstall(&out, "/repo"), "treex hooks are replaced")
+	content, _ = afero.ReadFile(fs, "/repo/.git/hooks/pre-commit")
+	assert.Contains(t, string(content), "exec treex check --staged --fix\n")
+
+	require.NoError(t, afero.WriteFile(fs, "/repo/.git/hooks/pre-commit", []byte("#!/bin/sh\nmake lint\n"), 0755))
+	err = runHookInstall(&out, "/repo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--force")
+
+	hookForce = true
+	require.NoError(t, runHookInstall(&out, "/repo"))
+}
+
+func TestHookInstallHonorsHooksPath(t *testing.T) {
+	fs := withAppFs(t, "/repo", map[string]interface{}{
+		".git": map[string]interface{}{"HEAD": "ref: refs/heads/main", "config": "[core]\n\thooksPath = .husky\n"},
+	}, resetCheckFlags)
+
+	var out bytes.Buffer
+	require.NoError(t, runHookInstall(&out, "/repo"))
+	content, err := afero.ReadFile(fs, "/repo/.husky/pre-commit")
+	require.NoError(t, err)
+	assert.Contains(t, string(content), hookMarker)
+	exists, _ := afero.Exists(fs, "/repo/.git/hooks/pre-commit")
+	assert.False(t, exists)
+}
+
+func TestCheckChangedPathsGitHubFormat(t *testing.T) {
+	withAppFs(t, "/project", map[string]interface{}{
+		".info":     "README.md  Overview\nold,\\ name.go  Renamed away\nother.go  Unrelated drift\n",
+		"README.md": "# project",
+		"docs":      map[string]interface{}{".info": "guide.md  Deleted guide\n"},
+	}, resetCheckFlags)
+	checkChangedPaths = "-"
+	checkFormat = "github"
+
+	in := strings.NewReader("README.md\n./old, name.go\ndocs/guide.md\nnew.go\n")
+	var out bytes.Buffer
+	err := runCheck(&out, in, "/project")
+	require.Error(t, err)
+	assert.Equal(t, "::error file=.info,line=2,title=treex check::old, name.go: annotated path does not exist\n"+
+		"::error file=docs/.info,line=1,title=treex check::docs/guide.md: annotated path does not exist\n", out.String())
+
+	checkStaged = true
+	assert.Error(t, runCheck(&out, strings.NewReader(""), "/project"), "--staged and --changed-paths conflict")
+}
+
+func TestCheckProse(t *testing.T) {
+	fs := withAppFs(t, "/project", map[string]interface{}{
+		".treex.toml": "[prose]\nsentence-case = true\n",
+		".info":       "README.md  overview\nmissing.go  Gone\n",
+		"README.md":   "# project",
+	}, resetCheckFlags)
+
+	var out bytes.Buffer
+	require.Error(t, runCheck(&out, nil, "/project"))
+	assert.NotContains(t, out.String(), "capital letter", "prose is only checked with --prose")
+
+	checkProse = true
+	out.Reset()
+	err := runCheck(&out, nil, "/project")
+	require.Error(t, err)
+	assert.Equal(t, "1 problems in .info files (run treex check --fix to remove them); 1 annotations to fix by hand", err.Error())
+	assert.Equal(t, ".info:2: missing.go: annotated path does not exist\n.info:1: README.md: annotation should start with a capital letter\n", out.String())
+
+	checkFix = true
+	out.Reset()
+	err = runCheck(&out, nil, "/project")
+	assert.EqualError(t, err, "1 annotations to fix by hand", "--fix leaves prose problems")
+	content, _ := afero.ReadFile(fs, "/project/.info")
+	assert.Equal(t, "README.md  overview\n", string(content))
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"treex/treex"
+)
+
+// churnJSON and churnCSV select the export format of the churn command
+var (
+	churnJSON bool
+	churnCSV  bool
+)
+
+// churnCmd exports how often and how recently each path changed in git
+var churnCmd = &cobra.Command{
+	Use:   "churn [path]",
+	Short: "Show how often and how recently paths changed in git",
+	Long: `Show, for each entry of the tree, the commits that changed it and how many
+days ago the newest of them was authored, read from the first-parent history
+of HEAD. A directory counts the commits changing anything below it.
+
+The terminal listing puts the most changed entries first: the hotspots that
+most deserve a good annotation. --json and --csv export every entry in tree
+order. To see the same history as colors in the tree, use treex --heat churn
+or --heat age.
+
+The tree is built with the same filters as "treex" (--exclude, --level, ...).`,
+	Example: `  treex churn                # Most changed entries of the current directory
+  treex churn --csv > churn.csv
+  treex --heat age src       # The tree, recently changed entries hottest`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootPath := "."
+		if len(args) > 0 {
+			rootPath = args[0]
+		}
+		return paged(cmd, func(out io.Writer) error { return runChurn(out, rootPath, time.Now()) })
+	},
+}
+
+func init() {
+	churnCmd.Flags().BoolVar(&churnJSON, "json", false, "Output the history of every entry as JSON")
+	churnCmd.Flags().BoolVar(&churnCSV, "csv", false, "Output the history of every entry as CSV")
+	rootCmd.AddCommand(churnCmd)
+}
+
+// runChurn builds the tree for rootPath and prints the git history of its entries, with
+// ages counted back from now
+func runChurn(out io.Writer, rootPath string, now time.Time) error {
+	if churnJSON && churnCSV {
+		return fmt.Errorf("--json and --csv cannot be used together")
+	}
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+	if _, err := appFs.Stat(absRoot); err != nil {
+		return fmt.Errorf("cannot access path %q: %w", rootPath, err)
+	}
+
+	result, err := treex.BuildTree(buildTreeConfig(absRoot))
+	if err != nil {
+		return fmt.Errorf("failed to build tree: %w", err)
+	}
+	entries, err := heatEntries(context.Background(), absRoot, result.Root, now)
+	if err != nil {
+		return err
+	}
+	if entries == nil {
+		return fmt.Errorf("%s is not inside a git repository", rootPath)
+	}
+
+	switch {
+	case churnJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	case churnCSV:
+		return writeChurnCSV(out, entries)
+	}
+
+	// Hotspots first: the most changed entries, then the most recently changed
+	var changed []treex.PathHeat
+	for _, entry := range entries[1:] {
+		if entry.Commits > 0 {
+			changed = append(changed, entry)
+		}
+	}
+	if len(changed) == 0 {
+		fmt.Fprintf(out, "No history for %s\n", rootPath)
+		return nil
+	}
+	sort.SliceStable(changed, func(i, j int) bool {
+		if changed[i].Commits != changed[j].Commits {
+			return changed[i].Commits > changed[j].Commits
+		}
+		return changed[i].AgeDays < changed[j].AgeDays
+	})
+	for _, entry := range changed {
+		name := entry.Path
+		if entry.IsDir {
+			name += "/"
+		}
+		fmt.Fprintf(out, "%6d  %5dd  %s\n", entry.Commits, entry.AgeDays, name)
+	}
+	return nil
+}
+
+// writeChurnCSV writes entries as CSV with a header row; entries never committed have an
+// empty last_modified
+func writeChurnCSV(out io.Writer, entries []treex.PathHeat) error {
+	writer := csv.NewWriter(out)
+	if err := writer.Write([]string{"path", "type", "commits", "last_modified", "age_days"}); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		kind, lastModified := "file", ""
+		if entry.IsDir {
+			kind = "directory"
+		}
+		if entry.LastModified != nil {
+			lastModified = entry.LastModified.UTC().Format(time.RFC3339)
+		}
+		if err := writer.Write([]string{entry.Path, kind, strconv.Itoa(entry.Commits), lastModified, strconv.Itoa(entry.AgeDays)}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChurn(t *testing.T) {
+	fs := withAppFs(t, "/project", nil, func() { churnJSON, churnCSV = false, false })
+	require.NoError(t, fs.MkdirAll("/elsewhere", 0755))
+	repo := fs.MustInitRepo("/project")
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	when := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i, name := range []string{"main.go", "README.md", "main.go"} {
+		require.NoError(t, afero.WriteFile(fs, "/project/"+name, []byte{byte('a' + i)}, 0644))
+		_, err = worktree.Add(name)
+		require.NoError(t, err)
+		_, err = worktree.Commit("Change "+name, &git.CommitOptions{Author: &object.Signature{Name: "Ana", Email: "ana@example.com", When: when.AddDate(0, 0, i)}})
+		require.NoError(t, err)
+	}
+	require.NoError(t, afero.WriteFile(fs, "/project/new.go", []byte("package main"), 0644))
+
+	now := time.Date(2026, 10, 13, 12, 0, 0, 0, time.UTC)
+
+	var out bytes.Buffer
+	require.NoError(t, runChurn(&out, "/project", now))
+	assert.Equal(t, "     2     10d  main.go\n     1     11d  README.md\n", out.String())
+
+	out.Reset()
+	churnCSV = true
+	require.NoError(t, runChurn(&out, "/project", now))
+	assert.Equal(t, "path,type,commits,last_modified,age_days\n"+
+		".,directory,3,2026-10-03T12:00:00Z,10\n"+
+		"README.md,file,1,2026-10-02T12:00:00Z,11\n"+
+		"main.go,file,2,2026-10-03T12:00:00Z,10\n"+
+		"new.go,file,0,,0\n", out.String())
+
+	churnJSON = true
+	assert.Error(t, runChurn(&out, "/project", now), "--json and --csv together")
+
+	churnJSON, churnCSV = false, false
+	err = runChurn(&out, "/elsewhere", now)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not inside a git repository")
+}
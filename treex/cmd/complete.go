@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"io"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"treex/treex/pathcollection"
+	"treex/treex/pattern"
+	"treex/treex/plugins/infofile"
+)
+
+// completeTreePaths completes path arguments against the tree below the current
+// directory, one directory level at a time, hiding what the tree hides by default
+// (built-in ignores and .gitignore). Paths outside the current directory fall back to
+// the shell's file completion.
+func completeTreePaths(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	dir, ok := completionDir(toComplete)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	absRoot, err := filepath.Abs(".")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	depth := strings.Count(dir, "/") + 1
+	filter := pattern.NewFilterBuilder(appFs).
+		AddBuiltinIgnores(true).
+		AddGitignore(filepath.Join(absRoot, ".gitignore"), false).
+		Build()
+	filter.AddPattern(completionScope{dir: dir})
+
+	paths, err := pathcollection.NewConfigurator(appFs).
+		WithRoot(absRoot).
+		WithMaxDepth(depth).
+		WithFilter(filter).
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))). // Warnings would corrupt the completion output
+		Collect()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	given := make(map[string]bool, len(args))
+	for _, arg := range args {
+		given[path.Clean(filepath.ToSlash(arg))] = true
+	}
+	var candidates []string
+	directive := cobra.ShellCompDirectiveNoFileComp
+	for _, p := range paths {
+		if p.Depth != depth || given[p.Path] || !strings.HasPrefix(p.Path, toComplete) {
+			continue
+		}
+		if p.IsDir {
+			// Directories complete with their slash, so the next level can follow
+			candidates = append(candidates, p.Path+"/")
+			directive |= cobra.ShellCompDirectiveNoSpace
+			continue
+		}
+		candidates = append(candidates, p.Path)
+	}
+	sort.Strings(candidates)
+	return candidates, directive
+}
+
+// completeAnnotatedPaths completes path arguments with the paths annotated in the .info
+// files below the current directory
+func completeAnnotatedPaths(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if _, ok := completionDir(toComplete); !ok {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	absRoot, err := filepath.Abs(".")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	annotations, err := infofile.Gather(appFs, absRoot)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var candidates []string
+	for target := range annotations {
+		rel, err := filepath.Rel(absRoot, filepath.FromSlash(target))
+		if err != nil || rel == "." {
+			continue
+		}
+		if rel = filepath.ToSlash(rel); strings.HasPrefix(rel, toComplete) {
+			candidates = append(candidates, rel)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionDir returns the directory part of a partially typed path ("src/" for
+// "src/ma"), or false for paths completion does not cover (absolute, or leaving the
+// current directory)
+func completionDir(toComplete string) (string, bool) {
+	if path.IsAbs(toComplete) || filepath.IsAbs(toComplete) || strings.HasPrefix(toComplete, "./") {
+		return "", false
+	}
+	dir, _ := path.Split(toComplete)
+	for _, part := range strings.Split(dir, "/") {
+		if part == ".." || part == "." {
+			return "", false
+		}
+	}
+	return dir, true
+}
+
+// completionScope excludes paths that are neither in the completed directory nor lead to it
+type completionScope struct {
+	dir string // Directory being completed, with a trailing slash ("" for the current directory)
+}
+
+// Matches implements pattern.Pattern
+func (s completionScope) Matches(p string, isDir bool) bool {
+	return p != "." && !strings.HasPrefix(p, s.dir) && !strings.HasPrefix(s.dir, p+"/")
+}
+
+// String implements pattern.Pattern
+func (s completionScope) String() string {
+	return "completion scope " + s.dir
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withCompletionFs makes the current directory an in-memory project for the test
+func withCompletionFs(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	withAppFs(t, wd, map[string]interface{}{
+		".info":      "README.md  Overview\nsrc/api  HTTP handlers\n",
+		".gitignore": "dist/\n",
+		"README.md":  "# project",
+		"Makefile":   "all:",
+		"dist":       map[string]interface{}{"app": "binary"},
+		"node_modules": map[string]interface{}{
+			"dep": map[string]interface{}{"index.js": ""},
+		},
+		"src": map[string]interface{}{
+			".info":   "main.go  Entry point\n",
+			"main.go": "package main",
+			"api":     map[string]interface{}{"server.go": "package api"},
+		},
+	}, nil)
+}
+
+func TestCompleteTreePaths(t *testing.T) {
+	withCompletionFs(t)
+
+	candidates, directive := completeTreePaths(addCmd, nil, "")
+	assert.Equal(t, []string{".gitignore", ".info", "Makefile", "README.md", "src/"}, candidates,
+		"ignored paths are not offered")
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp|cobra.ShellCompDirectiveNoSpace, directive)
+
+	candidates, _ = completeTreePaths(addCmd, nil, "src/")
+	assert.Equal(t, []string{"src/.info", "src/api/", "src/main.go"}, candidates)
+
+	candidates, directive = completeTreePaths(addCmd, []string{"src/main.go"}, "src/m")
+	assert.Empty(t, candidates, "paths already given are not offered again")
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	candidates, _ = completeTreePaths(addCmd, nil, "M")
+	assert.Equal(t, []string{"Makefile"}, candidates)
+
+	for _, outside := range []string{"../", "/etc/", "./src/"} {
+		_, directive = completeTreePaths(addCmd, nil, outside)
+		assert.Equal(t, cobra.ShellCompDirectiveDefault, directive, outside)
+	}
+}
+
+func TestCompleteAnnotatedPaths(t *testing.T) {
+	withCompletionFs(t)
+
+	candidates, directive := completeAnnotatedPaths(logCmd, nil, "")
+	assert.Equal(t, []string{"README.md", "src/api", "src/main.go"}, candidates)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	candidates, _ = completeAnnotatedPaths(logCmd, nil, "src/")
+	assert.Equal(t, []string{"src/api", "src/main.go"}, candidates)
+}
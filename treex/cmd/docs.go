@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"treex/treex"
+	"treex/treex/site"
+)
+
+var (
+	// docsOut is the directory the documentation site is written to
+	docsOut string
+	// docsTitle names the site (default: the root directory's name)
+	docsTitle string
+)
+
+// docsCmd groups documentation related subcommands
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Publish the annotated tree as documentation",
+}
+
+// docsGenerateCmd writes a static documentation site
+var docsGenerateCmd = &cobra.Command{
+	Use:   "generate [path]",
+	Short: "Generate a static documentation site from the annotated tree",
+	Long: `Generate a static site documenting the annotated tree: an index page with the
+root annotation and top-level entries, one page per top-level directory with
+breadcrumbs, its tree and annotated paths, and a search index
+(search-index.json) used by the search box.
+
+The tree is built with the same filters as "treex" (--exclude, --level, ...);
+the output directory is left out of it. Publish the output directory with
+GitHub Pages or any static file host.`,
+	Example: `  treex docs generate --out site/
+  treex docs generate --title "Acme API" --exclude testdata --out public`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDocsGenerate(cmd.OutOrStdout(), rootArg(args))
+	},
+}
+
+func init() {
+	docsGenerateCmd.Flags().StringVarP(&docsOut, "out", "o", "site", "Directory to write the site to")
+	docsGenerateCmd.Flags().StringVar(&docsTitle, "title", "", "Site title (default: the root directory's name)")
+	docsCmd.AddCommand(docsGenerateCmd)
+	rootCmd.AddCommand(docsCmd)
+}
+
+// runDocsGenerate builds the tree for rootPath and writes its site to docsOut
+func runDocsGenerate(out io.Writer, rootPath string) error {
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+	if info, err := appFs.Stat(absRoot); err != nil || !info.IsDir() {
+		return fmt.Errorf("cannot document %q: not an accessible directory", rootPath)
+	}
+	absOut, err := filepath.Abs(docsOut)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", docsOut, err)
+	}
+
+	config := buildTreeConfig(absRoot)
+	if rel, err := filepath.Rel(absRoot, absOut); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		config.ExcludeGlobs = append(config.ExcludeGlobs, filepath.ToSlash(rel))
+	}
+	result, err := treex.BuildTree(config)
+	if err != nil {
+		return fmt.Errorf("failed to build tree: %w", err)
+	}
+
+	title := docsTitle
+	if title == "" {
+		title = filepath.Base(absRoot)
+	}
+	written, err := site.Generate(appFs, result.Root, absOut, site.Options{Title: title})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Wrote %d files to %s\n", len(written), docsOut)
+	return nil
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+)
+
+func TestDocsGenerateLeavesOutputOutOfTheTree(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info": "src  Sources\n",
+		"src":   map[string]interface{}{"main.go": "package main"},
+		"site":  map[string]interface{}{"old.html": "stale"},
+	})
+	originalFs := appFs
+	appFs = fs
+	t.Cleanup(func() {
+		appFs = originalFs
+		docsOut, docsTitle = "site", ""
+	})
+	docsOut = "/project/site"
+
+	var out bytes.Buffer
+	require.NoError(t, runDocsGenerate(&out, "/project"))
+	assert.Equal(t, "Wrote 4 files to /project/site\n", out.String())
+
+	index, err := afero.ReadFile(fs, "/project/site/index.html")
+	require.NoError(t, err)
+	assert.Contains(t, string(index), "<title>project</title>")
+	assert.Contains(t, string(index), `<a href="src/index.html">src/</a>`)
+	assert.NotContains(t, string(index), "site/")
+}
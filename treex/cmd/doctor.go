@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	projectconfig "treex/treex/config"
+	"treex/treex/display"
+	"treex/treex/i18n"
+	"treex/treex/plugins/infofile"
+	"treex/treex/rendering"
+	"treex/treex/suggest"
+)
+
+// doctorCmd prints a diagnostic report of the environment treex runs in
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [path]",
+	Short: "Print a diagnostic report to paste into bug reports",
+	Long: `Print what treex sees of its environment: the terminal and color settings,
+the locale, git, the configuration files it reads and a summary of the .info
+files below a path (default: the current directory) and their problems.
+
+Nothing is sent anywhere; the report only goes to standard output, ready to
+paste into a bug report. It names files and environment variables, never
+their secrets: API keys in suggest.yaml are not read.`,
+	Example: `  treex doctor
+  treex doctor ./project | pbcopy`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDoctor(cmd.OutOrStdout(), rootArg(args), systemDoctorEnv())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// doctorEnv is what the doctor report reads besides the filesystem
+type doctorEnv struct {
+	getenv     func(string) string
+	terminal   bool // Standard output is a terminal
+	width      int  // Terminal size (0 when unknown)
+	height     int
+	git        string // Path of the git executable ("" when not found)
+	gitVersion string // Output of git --version
+}
+
+// systemDoctorEnv reads the doctor environment of the running process
+func systemDoctorEnv() doctorEnv {
+	env := doctorEnv{getenv: os.Getenv, terminal: isTerminal(os.Stdout)}
+	if env.terminal {
+		env.width, env.height, _ = term.GetSize(os.Stdout.Fd())
+	}
+	if git, err := exec.LookPath("git"); err == nil {
+		env.git = git
+		if version, err := exec.Command(git, "--version").Output(); err == nil {
+			env.gitVersion = strings.TrimSpace(string(version))
+		}
+	}
+	return env
+}
+
+// doctorVariables are the environment variables that change how treex behaves
+var doctorVariables = []string{
+	"TERM", "COLORTERM", "NO_COLOR", "CLICOLOR", "CLICOLOR_FORCE", formatEnvVar,
+	"LC_ALL", "LC_CTYPE", "LC_MESSAGES", "LANG", "XDG_CONFIG_HOME", "XDG_CACHE_HOME",
+}
+
+// runDoctor writes the diagnostic report for the tree below rootPath
+func runDoctor(out io.Writer, rootPath string, env doctorEnv) error {
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+	if info, err := appFs.Stat(absRoot); err != nil || !info.IsDir() {
+		return ioError(fmt.Errorf("cannot diagnose %q: not an accessible directory", rootPath))
+	}
+
+	fmt.Fprintln(out, "treex doctor")
+	row := func(label, value string) { fmt.Fprintf(out, "  %s %s\n", rendering.PadRight(label+":", 16), value) }
+	section := func(title string) { fmt.Fprintf(out, "\n%s\n", title) }
+
+	section("treex")
+	row("version", fmt.Sprintf("%s (commit %s, built %s)", Version, Commit, BuildDate))
+	row("go", runtime.Version()+" "+runtime.GOOS+"/"+runtime.GOARCH)
+
+	section("Terminal")
+	if env.terminal {
+		row("stdout", fmt.Sprintf("terminal, %dx%d", env.width, env.height))
+	} else {
+		row("stdout", "not a terminal")
+	}
+	format, noColor, err := selectFormat("", false, false, formatContext{getenv: env.getenv, piped: !env.terminal})
+	if err != nil {
+		row("format", err.Error())
+	} else {
+		enabled, forced := rendering.ColorSettings(format, noColor, env.getenv)
+		row("format", string(format))
+		row("colors", fmt.Sprintf("%s (%s)", onOff(enabled && (forced || env.terminal)), rendering.ColorModeFromEnv(env.getenv)))
+	}
+
+	section("Environment")
+	for _, name := range doctorVariables {
+		value := env.getenv(name)
+		if value == "" {
+			value = "(unset)"
+		}
+		row(name, value)
+	}
+
+	section("Locale")
+	row("UTF-8", yesNo(localeIsUTF8(env.getenv)))
+	row("language", i18n.Language().String())
+
+	section("Git")
+	if env.git == "" {
+		row("git", "not found in PATH")
+	} else {
+		row("git", env.git)
+		row("version", env.gitVersion)
+	}
+
+	section("Configuration")
+	row(projectconfig.FileName, configStatus(filepath.Join(absRoot, projectconfig.FileName), func() error {
+		_, err := projectconfig.Load(appFs, absRoot)
+		return err
+	}))
+	row("suggest", configStatus(suggest.DefaultConfigPath(), nil))
+	row("themes", configStatus(rendering.DefaultThemesDir(), nil))
+	row("icons", configStatus(display.DefaultIconsFile(), nil))
+
+	section("Annotations")
+	infoFiles, err := countInfoFiles(absRoot)
+	if err != nil {
+		return ioError(err)
+	}
+	issues, err := infofile.Validate(appFs, absRoot)
+	if err != nil {
+		return ioError(err)
+	}
+	row("root", absRoot)
+	row(".info files", fmt.Sprint(infoFiles))
+	row("problems", issueSummary(issues))
+	return nil
+}
+
+// configStatus describes a configuration file or directory: its path, whether it exists
+// and, through validate, whether it loads
+func configStatus(path string, validate func() error) string {
+	if _, err := appFs.Stat(path); err != nil {
+		return path + " (not found)"
+	}
+	if validate != nil {
+		if err := validate(); err != nil {
+			return path + " (invalid: " + err.Error() + ")"
+		}
+	}
+	return path + " (found)"
+}
+
+// countInfoFiles counts the .info files below root
+func countInfoFiles(root string) (int, error) {
+	count := 0
+	err := afero.Walk(appFs, root, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			if filePath == root {
+				return err
+			}
+			return nil
+		}
+		if !info.IsDir() && info.Name() == ".info" {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// issueSummary counts validation issues by type: "3 (2 missing-path, 1 no-text)"
+func issueSummary(issues []infofile.Issue) string {
+	if len(issues) == 0 {
+		return "none"
+	}
+	counts := make(map[infofile.IssueType]int)
+	for _, issue := range issues {
+		counts[issue.Type]++
+	}
+	types := make([]string, 0, len(counts))
+	for issueType := range counts {
+		types = append(types, string(issueType))
+	}
+	sort.Strings(types)
+	for i, issueType := range types {
+		types[i] = fmt.Sprintf("%d %s", counts[infofile.IssueType(issueType)], issueType)
+	}
+	return fmt.Sprintf("%d (%s); run treex check for details", len(issues), strings.Join(types, ", "))
+}
+
+// localeIsUTF8 reports whether the locale that sets the character type (LC_ALL,
+// LC_CTYPE, then LANG) names UTF-8
+func localeIsUTF8(getenv func(string) string) bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := getenv(name); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+	return false
+}
+
+// yesNo and onOff spell out a boolean in the report
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+func onOff(value bool) string {
+	if value {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+)
+
+// doctorGetenv returns an environment lookup over vars
+func doctorGetenv(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestDoctorReport(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":       "README.md  Project overview\ngone.go  Removed long ago\n",
+		".treex.toml": "[prose]\nmax-length = 80\n",
+		"README.md":   "# project",
+		"src": map[string]interface{}{
+			".info":   "main.go\n",
+			"main.go": "package main",
+		},
+	})
+	originalFs := appFs
+	appFs = fs
+	t.Cleanup(func() { appFs = originalFs })
+
+	env := doctorEnv{
+		getenv:     doctorGetenv(map[string]string{"TERM": "xterm-256color", "LANG": "pt_BR.UTF-8"}),
+		terminal:   true,
+		width:      120,
+		height:     40,
+		git:        "/usr/bin/git",
+		gitVersion: "git version 2.43.0",
+	}
+	var out bytes.Buffer
+	require.NoError(t, runDoctor(&out, "/project", env))
+
+	report := out.String()
+	assert.Contains(t, report, "  stdout:          terminal, 120x40\n")
+	assert.Contains(t, report, "  format:          term\n")
+	assert.Contains(t, report, "  colors:          on (auto)\n")
+	assert.Contains(t, report, "  NO_COLOR:        (unset)\n")
+	assert.Contains(t, report, "  UTF-8:           yes\n")
+	assert.Contains(t, report, "  version:         git version 2.43.0\n")
+	assert.Contains(t, report, "  .treex.toml:     /project/.treex.toml (found)\n")
+	assert.Contains(t, report, "  .info files:     2\n")
+	assert.Contains(t, report, "  problems:        2 (1 missing-path, 1 no-text); run treex check for details\n")
+}
+
+func TestDoctorReportWithoutTerminalOrGit(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{".treex.toml": "unknown = 1\n"})
+	originalFs := appFs
+	appFs = fs
+	t.Cleanup(func() { appFs = originalFs })
+
+	env := doctorEnv{getenv: doctorGetenv(map[string]string{"NO_COLOR": "1", "LC_ALL": "C"})}
+	var out bytes.Buffer
+	require.NoError(t, runDoctor(&out, "/project", env))
+
+	report := out.String()
+	assert.Contains(t, report, "  stdout:          not a terminal\n")
+	assert.Contains(t, report, "  colors:          off (never)\n")
+	assert.Contains(t, report, "  UTF-8:           no\n")
+	assert.Contains(t, report, "  git:             not found in PATH\n")
+	assert.Contains(t, report, `(invalid: invalid .treex.toml: unknown key "unknown")`)
+	assert.Contains(t, report, "  problems:        none\n")
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"treex/treex"
+	"treex/treex/rendering"
+)
+
+// dupesMinSize and dupesJSON are the flags of the dupes command
+var (
+	dupesMinSize string
+	dupesJSON    bool
+)
+
+// dupesCmd reports files with identical contents
+var dupesCmd = &cobra.Command{
+	Use:   "dupes [path]",
+	Short: "Find duplicate files by content",
+	Long: `Find files with identical contents and show them in a tree, each marked with
+the number of its group. Groups are ordered by the space their extra copies
+take, largest first, and a footer totals what keeping one copy of each would
+reclaim.
+
+Only files sharing a size are read, and their contents are compared by
+xxhash. Empty files are never reported; --min-size skips smaller files too.
+The tree is built with the same filters as "treex" (--exclude, --level, ...).`,
+	Example: `  treex dupes                   # Duplicates in the current directory
+  treex dupes --min-size 1MB    # Only duplicates of 1MB or more
+  treex dupes --json assets     # Machine-readable groups for assets`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootPath := "."
+		if len(args) > 0 {
+			rootPath = args[0]
+		}
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+		return paged(cmd, func(out io.Writer) error { return runDupes(ctx, out, rootPath) })
+	},
+}
+
+func init() {
+	dupesCmd.Flags().StringVar(&dupesMinSize, "min-size", "", "Skip files smaller than this size (e.g. 10KB, 1MB)")
+	dupesCmd.Flags().BoolVar(&dupesJSON, "json", false, "Output duplicate groups as JSON")
+	rootCmd.AddCommand(dupesCmd)
+}
+
+// runDupes builds the tree for rootPath and renders its duplicate files
+func runDupes(ctx context.Context, out io.Writer, rootPath string) error {
+	minSize, err := rendering.ParseSize(dupesMinSize)
+	if err != nil {
+		return fmt.Errorf("invalid --min-size: %w", err)
+	}
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+	if _, err := appFs.Stat(absRoot); err != nil {
+		return fmt.Errorf("cannot access path %q: %w", rootPath, err)
+	}
+
+	result, err := treex.BuildTreeContext(ctx, buildTreeConfig(absRoot))
+	if err != nil {
+		return fmt.Errorf("failed to build tree: %w", err)
+	}
+	report, err := treex.FindDuplicates(ctx, appFs, absRoot, result.Root, minSize)
+	if err != nil {
+		return fmt.Errorf("failed to compare files: %w", err)
+	}
+	treex.PruneToDuplicates(result.Root, report)
+
+	format := rendering.FormatTerm
+	if dupesJSON {
+		format = rendering.FormatJSON
+	}
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format:    format,
+		Writer:    out,
+		ShowNotes: true,
+	})
+	return renderer.RenderDuplicates(result.Root, report)
+}
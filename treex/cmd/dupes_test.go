@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+)
+
+func TestRunDupes(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"a.txt": "same contents",
+		"b.txt": "same contents",
+		"c.txt": "other",
+	})
+	originalFs := appFs
+	appFs = fs
+	t.Cleanup(func() {
+		appFs = originalFs
+		dupesMinSize, dupesJSON = "", false
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, runDupes(context.Background(), &out, "/project"))
+	assert.Contains(t, out.String(), "#1 a.txt")
+	assert.Contains(t, out.String(), "#1 b.txt")
+	assert.NotContains(t, out.String(), "c.txt")
+	assert.Contains(t, out.String(), "1 groups of duplicates: 2 files, 13 B reclaimable")
+
+	out.Reset()
+	dupesJSON = true
+	require.NoError(t, runDupes(context.Background(), &out, "/project"))
+	var report struct {
+		Groups []struct {
+			Paths []string `json:"paths"`
+		} `json:"groups"`
+		Reclaimable int64 `json:"reclaimable"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	require.Len(t, report.Groups, 1)
+	assert.Equal(t, []string{"a.txt", "b.txt"}, report.Groups[0].Paths)
+	assert.Equal(t, int64(13), report.Reclaimable)
+
+	out.Reset()
+	dupesMinSize, dupesJSON = "1KB", false
+	require.NoError(t, runDupes(context.Background(), &out, "/project"))
+	assert.Equal(t, "No duplicate files\n", out.String())
+
+	dupesMinSize = "lots"
+	assert.Error(t, runDupes(context.Background(), &out, "/project"))
+}
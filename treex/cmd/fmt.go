@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"treex/treex/plugins/infofile"
+)
+
+var (
+	// fmtCheck lists the .info files that are not formatted instead of rewriting them
+	fmtCheck bool
+	// fmtDiff prints the rewrites as diffs without writing them (--diff, --dry-run)
+	fmtDiff bool
+)
+
+// fmtCmd rewrites .info files in their canonical layout
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [path]",
+	Short: "Format .info files: sort entries and align annotations",
+	Long: `Rewrite the .info files below a path in one canonical layout, as gofmt does
+for Go code:
+
+  - entries are sorted directories first, then by path, within each group of
+    lines separated by blank lines ("." comes first)
+  - comment lines directly above an entry move with it; a heading comment
+    stays put when a blank line separates it from the entries
+  - annotations start in one column, two spaces past the longest path
+  - indentation, trailing whitespace and extra blank lines are removed
+
+The byte order mark and line endings of each file are kept. With --check,
+nothing is written: the files that are not formatted are listed and the
+command fails, for use in CI. Rewrites can be reverted with treex undo.`,
+	Example: `  treex fmt                  # Format every .info file
+  treex fmt --diff           # Show what would change
+  treex fmt --check          # Fail in CI when a file is not formatted`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFmt(cmd.OutOrStdout(), rootArg(args))
+	},
+}
+
+func init() {
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "List unformatted .info files and fail instead of rewriting them")
+	addDiffFlags(fmtCmd.Flags(), &fmtDiff)
+	rootCmd.AddCommand(fmtCmd)
+}
+
+// runFmt formats (or with --check lists) the .info files below rootPath
+func runFmt(out io.Writer, rootPath string) error {
+	if fmtCheck && fmtDiff {
+		return fmt.Errorf("--check cannot be combined with --diff or --dry-run")
+	}
+
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+	if info, err := appFs.Stat(absRoot); err != nil || !info.IsDir() {
+		return ioError(fmt.Errorf("cannot format %q: not an accessible directory", rootPath))
+	}
+
+	if fmtCheck {
+		unformatted, err := infofile.FormatTree(appFs, absRoot, false)
+		if err != nil {
+			return ioError(err)
+		}
+		for _, infoFile := range unformatted {
+			fmt.Fprintln(out, infoFile)
+		}
+		if len(unformatted) > 0 {
+			return issuesError(fmt.Errorf("%d .info files are not formatted (run treex fmt)", len(unformatted)))
+		}
+		return nil
+	}
+
+	var formatted []string
+	write := func(fs afero.Fs) error {
+		formatted, err = infofile.FormatTree(fs, absRoot, true)
+		return err
+	}
+	if fmtDiff {
+		return previewEdit(out, absRoot, write)
+	}
+	if err := withUndo(absRoot, []string{"fmt"}, write); err != nil {
+		return ioError(err)
+	}
+	fmt.Fprintf(out, "Formatted %d .info files\n", len(formatted))
+	for _, infoFile := range formatted {
+		fmt.Fprintf(out, "  %s\n", infoFile)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withFmtProject points appFs at a project whose root .info file is not formatted
+func withFmtProject(t *testing.T) afero.Fs {
+	t.Helper()
+
+	return withAppFs(t, "/project", map[string]interface{}{
+		".info":   "main.go Entry point\nsrc  Sources\n",
+		"main.go": "package main",
+		"src": map[string]interface{}{
+			".info":   "util.go  Helpers\n",
+			"util.go": "package main",
+		},
+	}, func() { fmtCheck, fmtDiff = false, false })
+}
+
+func TestFmtCheck(t *testing.T) {
+	fs := withFmtProject(t)
+	fmtCheck = true
+
+	var out bytes.Buffer
+	err := runFmt(&out, "/project")
+	require.Error(t, err)
+	assert.Equal(t, exitIssues, exitCode(err))
+	assert.Equal(t, ".info\n", out.String())
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "main.go Entry point\nsrc  Sources\n", string(content), "--check writes nothing")
+}
+
+func TestFmtWritesAndUndoes(t *testing.T) {
+	fs := withFmtProject(t)
+
+	var out bytes.Buffer
+	require.NoError(t, runFmt(&out, "/project"))
+	assert.Equal(t, "Formatted 1 .info files\n  .info\n", out.String())
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "src      Sources\nmain.go  Entry point\n", string(content))
+
+	fmtCheck = true
+	out.Reset()
+	require.NoError(t, runFmt(&out, "/project"))
+	assert.Empty(t, out.String())
+
+	require.NoError(t, runUndo(&out, "/project"))
+	content, err = afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "main.go Entry point\nsrc  Sources\n", string(content))
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"treex/treex/rendering"
+)
+
+// porcelainOutput asks for output meant for scripts: JSON when piped, plain text otherwise
+var porcelainOutput bool
+
+// formatEnvVar sets the output format when --format is not given
+const formatEnvVar = "TREEX_FORMAT"
+
+// docsGenerators are the programs whose output ends up in documentation; run by one of
+// them (mdbook's cmd preprocessor, cog, embedme, ...), the tree is written as markdown
+var docsGenerators = []string{"cog", "docusaurus", "embedme", "hugo", "jekyll", "mdbook", "mkdocs", "sphinx-build", "vitepress"}
+
+// formatContext is what selectFormat reads besides the flags
+type formatContext struct {
+	getenv func(string) string
+	piped  bool   // Standard output is not a terminal
+	parent string // Name of the parent process ("" when unknown)
+}
+
+// selectFormat picks the output format, trying in order: --format, --porcelain (json
+// when piped, plain otherwise), $TREEX_FORMAT, markdown when run by a docs generator,
+// then term. noColor is set for terminal output when TERM is dumb, unless
+// CLICOLOR_FORCE asks for colors anyway.
+func selectFormat(value string, explicit, porcelain bool, env formatContext) (format rendering.OutputFormat, noColor bool, err error) {
+	switch {
+	case explicit:
+		format, err = parseOutputFormat(value, rendering.DefaultRenderers)
+	case porcelain && env.piped:
+		format = rendering.FormatJSON
+	case porcelain:
+		format = rendering.FormatPlain
+	case env.getenv(formatEnvVar) != "":
+		if format, err = parseOutputFormat(env.getenv(formatEnvVar), rendering.DefaultRenderers); err != nil {
+			err = fmt.Errorf("$%s: %w", formatEnvVar, err)
+		}
+	case isDocsGenerator(env.parent):
+		format = rendering.FormatMarkdown
+	default:
+		format = rendering.FormatTerm
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	dumb := env.getenv("TERM") == "dumb" && rendering.ColorModeFromEnv(env.getenv) != rendering.ColorAlways
+	return format, format == rendering.FormatTerm && dumb, nil
+}
+
+// isDocsGenerator reports whether a process name is one of docsGenerators
+func isDocsGenerator(name string) bool {
+	for _, generator := range docsGenerators {
+		if name == generator {
+			return true
+		}
+	}
+	return false
+}
+
+// parentProcessName returns the name of the process that started treex, or "" where it
+// cannot be read (only Linux exposes it, through /proc)
+func parentProcessName() string {
+	comm, err := os.ReadFile("/proc/" + strconv.Itoa(os.Getppid()) + "/comm")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(comm))
+}
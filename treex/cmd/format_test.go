@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex/rendering"
+)
+
+// formatEnv returns a formatContext reading env as the environment
+func formatEnv(env map[string]string, piped bool, parent string) formatContext {
+	return formatContext{getenv: func(key string) string { return env[key] }, piped: piped, parent: parent}
+}
+
+func TestSelectFormat(t *testing.T) {
+	for name, tc := range map[string]struct {
+		value     string
+		explicit  bool
+		porcelain bool
+		context   formatContext
+		expected  rendering.OutputFormat
+	}{
+		"default":                        {context: formatEnv(nil, false, "zsh"), expected: rendering.FormatTerm},
+		"piped output stays term":        {context: formatEnv(nil, true, ""), expected: rendering.FormatTerm},
+		"--format":                       {value: "flat", explicit: true, context: formatEnv(nil, false, ""), expected: rendering.FormatFlat},
+		"--format beats everything":      {value: "dot", explicit: true, porcelain: true, context: formatEnv(map[string]string{"TREEX_FORMAT": "json"}, true, "mkdocs"), expected: rendering.FormatDot},
+		"--porcelain when piped":         {porcelain: true, context: formatEnv(nil, true, ""), expected: rendering.FormatJSON},
+		"--porcelain on a terminal":      {porcelain: true, context: formatEnv(nil, false, ""), expected: rendering.FormatPlain},
+		"--porcelain beats TREEX_FORMAT": {porcelain: true, context: formatEnv(map[string]string{"TREEX_FORMAT": "flat"}, true, ""), expected: rendering.FormatJSON},
+		"TREEX_FORMAT":                   {context: formatEnv(map[string]string{"TREEX_FORMAT": "flat"}, false, ""), expected: rendering.FormatFlat},
+		"TREEX_FORMAT beats docs tools":  {context: formatEnv(map[string]string{"TREEX_FORMAT": "plain"}, true, "mdbook"), expected: rendering.FormatPlain},
+		"docs generator parent":          {context: formatEnv(nil, true, "mkdocs"), expected: rendering.FormatMarkdown},
+		"other parents":                  {context: formatEnv(nil, true, "make"), expected: rendering.FormatTerm},
+	} {
+		t.Run(name, func(t *testing.T) {
+			format, _, err := selectFormat(tc.value, tc.explicit, tc.porcelain, tc.context)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, format)
+		})
+	}
+}
+
+func TestSelectFormatErrors(t *testing.T) {
+	_, _, err := selectFormat("yaml", true, false, formatEnv(nil, false, ""))
+	assert.ErrorContains(t, err, `unknown format "yaml"`)
+
+	_, _, err = selectFormat("term", false, false, formatEnv(map[string]string{"TREEX_FORMAT": "yaml"}, false, ""))
+	assert.ErrorContains(t, err, `$TREEX_FORMAT: unknown format "yaml"`)
+}
+
+func TestSelectFormatDumbTerminal(t *testing.T) {
+	_, noColor, err := selectFormat("term", false, false, formatEnv(map[string]string{"TERM": "dumb"}, false, ""))
+	require.NoError(t, err)
+	assert.True(t, noColor)
+
+	_, noColor, err = selectFormat("term", false, false, formatEnv(map[string]string{"TERM": "dumb", "CLICOLOR_FORCE": "1"}, false, ""))
+	require.NoError(t, err)
+	assert.False(t, noColor, "CLICOLOR_FORCE keeps colors")
+
+	_, noColor, err = selectFormat("term", false, false, formatEnv(map[string]string{"TERM": "xterm-256color"}, false, ""))
+	require.NoError(t, err)
+	assert.False(t, noColor)
+}
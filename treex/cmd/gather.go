@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"treex/treex/plugins/infofile"
+)
+
+// relocateDryRun prints the .info rewrites as diffs without writing them (--dry-run, --diff)
+var relocateDryRun bool
+
+// gatherCmd consolidates every annotation into the root .info file
+var gatherCmd = &cobra.Command{
+	Use:   "gather [path]",
+	Short: "Move all annotations into the root .info file",
+	Long: `Consolidate the annotations of every .info file below the root into the
+root .info file, with paths relative to the root. Where several .info files
+annotate the same path, the annotation that is shown today (the one closest
+to the path) is kept and the others are dropped. .info files left empty are
+removed; comments and entries for missing paths stay where they are.`,
+	Example: `  treex gather --dry-run     # Show the rewrites as a diff
+  treex gather && treex undo # Try it, then revert`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRelocate(cmd.OutOrStdout(), rootArg(args), "gather", infofile.PlanGather)
+	},
+}
+
+// distributeCmd pushes annotations into the .info file closest to each path
+var distributeCmd = &cobra.Command{
+	Use:   "distribute [path]",
+	Short: "Move annotations into the .info file of each path's directory",
+	Long: `Move every annotation into the .info file of the annotated path's parent
+directory, the file "treex add" writes to, creating .info files as needed.
+This is the inverse of gather. Shadowed duplicates are dropped and .info
+files left empty are removed.`,
+	Example: `  treex distribute --dry-run # Show the rewrites as a diff
+  treex distribute docs      # Only below docs/`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRelocate(cmd.OutOrStdout(), rootArg(args), "distribute", infofile.PlanDistribute)
+	},
+}
+
+func init() {
+	for _, command := range []*cobra.Command{gatherCmd, distributeCmd} {
+		addDiffFlags(command.Flags(), &relocateDryRun)
+		rootCmd.AddCommand(command)
+	}
+}
+
+// rootArg returns the optional path argument, defaulting to the current directory
+func rootArg(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return "."
+}
+
+// runRelocate plans the .info rewrites below rootPath and applies (or diffs) them
+func runRelocate(out io.Writer, rootPath, command string, plan func(afero.Fs, string) ([]infofile.Rewrite, error)) error {
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+	if info, err := appFs.Stat(absRoot); err != nil || !info.IsDir() {
+		return fmt.Errorf("cannot %s %q: not an accessible directory", command, rootPath)
+	}
+
+	rewrites, err := plan(appFs, absRoot)
+	if err != nil {
+		return err
+	}
+	if len(rewrites) == 0 {
+		fmt.Fprintln(out, "Nothing to move")
+		return nil
+	}
+
+	apply := func(fs afero.Fs) error {
+		return infofile.ApplyRewrites(fs, absRoot, rewrites)
+	}
+	if relocateDryRun {
+		return previewEdit(out, absRoot, apply)
+	}
+
+	err = withUndo(absRoot, []string{command}, apply)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Rewrote %d .info files\n", len(rewrites))
+	for _, rewrite := range rewrites {
+		switch {
+		case rewrite.After == nil:
+			fmt.Fprintf(out, "  %s (removed)\n", rewrite.InfoFile)
+		case rewrite.Before == nil:
+			fmt.Fprintf(out, "  %s (created)\n", rewrite.InfoFile)
+		default:
+			fmt.Fprintf(out, "  %s\n", rewrite.InfoFile)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex/plugins/infofile"
+)
+
+// withRelocateProject points appFs at a project with annotations in two .info files
+func withRelocateProject(t *testing.T) afero.Fs {
+	t.Helper()
+
+	return withAppFs(t, "/project", map[string]interface{}{
+		".info":     "README.md  Overview\n",
+		"README.md": "# project",
+		"cmd": map[string]interface{}{
+			".info":   "root.go  Root command\n",
+			"root.go": "package cmd",
+		},
+	}, func() { relocateDryRun = false })
+}
+
+func TestGatherDryRunPrintsDiff(t *testing.T) {
+	fs := withRelocateProject(t)
+	relocateDryRun = true
+
+	var out bytes.Buffer
+	require.NoError(t, runRelocate(&out, "/project", "gather", infofile.PlanGather))
+	assert.Equal(t, `--- a/.info
++++ b/.info
+@@ -1 +1,2 @@
+ README.md  Overview
++cmd/root.go  Root command
+--- a/cmd/.info
++++ /dev/null
+@@ -1 +0,0 @@
+-root.go  Root command
+`, out.String())
+
+	exists, _ := afero.Exists(fs, "/project/cmd/.info")
+	assert.True(t, exists, "dry run writes nothing")
+}
+
+func TestGatherThenDistribute(t *testing.T) {
+	fs := withRelocateProject(t)
+
+	var out bytes.Buffer
+	require.NoError(t, runRelocate(&out, "/project", "gather", infofile.PlanGather))
+	assert.Equal(t, "Rewrote 2 .info files\n  .info\n  cmd/.info (removed)\n", out.String())
+
+	out.Reset()
+	require.NoError(t, runRelocate(&out, "/project", "distribute", infofile.PlanDistribute))
+	assert.Equal(t, "Rewrote 2 .info files\n  .info\n  cmd/.info (created)\n", out.String())
+
+	content, err := afero.ReadFile(fs, "/project/cmd/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "root.go  Root command\n", string(content))
+
+	out.Reset()
+	require.NoError(t, runRelocate(&out, "/project", "distribute", infofile.PlanDistribute))
+	assert.Equal(t, "Nothing to move\n", out.String())
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"treex/treex/plugins/infofile"
+	"treex/treex/treetext"
+)
+
+var (
+	// genInfoRoot is the directory the diagram's paths are relative to
+	genInfoRoot string
+	// genInfoDiff prints the .info changes as diffs instead of writing them (--diff, --dry-run)
+	genInfoDiff bool
+)
+
+// genInfoCmd turns an annotated tree diagram into .info files
+var genInfoCmd = &cobra.Command{
+	Use:   "gen-info [file]",
+	Short: "Write .info files from an annotated tree diagram",
+	Long: `Read a tree diagram with annotations and write them to .info files, each in
+the .info file of the entry's parent directory.
+
+The diagram may be treex output (any --charset, colors, icons, --long, wrapped
+annotations), tree(1) output with or without sizes, or a hand-drawn tree using
+the same connectors. Annotations follow the name after a tab or at least two
+spaces. The first line is the root; other paths are relative to --root.
+
+Reads stdin when no file (or "-") is given. Lines that cannot be read are
+reported rather than guessed at, and every path must exist.`,
+	Example: `  treex > tree.txt && $EDITOR tree.txt && treex gen-info tree.txt
+  pbpaste | treex gen-info --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		input := "-"
+		if len(args) > 0 {
+			input = args[0]
+		}
+		return runGenInfo(cmd.OutOrStdout(), cmd.InOrStdin(), input)
+	},
+}
+
+func init() {
+	genInfoCmd.Flags().StringVar(&genInfoRoot, "root", ".", "Directory the diagram's paths are relative to")
+	addDiffFlags(genInfoCmd.Flags(), &genInfoDiff)
+	rootCmd.AddCommand(genInfoCmd)
+}
+
+// runGenInfo parses the diagram in input (a file, or "-" for in) and writes its annotations
+func runGenInfo(out io.Writer, in io.Reader, input string) error {
+	if input != "-" {
+		file, err := appFs.Open(input)
+		if err != nil {
+			return fmt.Errorf("cannot read %q: %w", input, err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	entries, err := treetext.Parse(in)
+	if err != nil {
+		return fmt.Errorf("cannot parse tree: %w", err)
+	}
+
+	var annotations []infofile.Annotation
+	for _, entry := range entries {
+		if entry.Notes != "" {
+			annotations = append(annotations, infofile.Annotation{Path: entry.Path, Notes: entry.Notes})
+		}
+	}
+	if len(annotations) == 0 {
+		return fmt.Errorf("no annotations found in the tree (separate them from names with two spaces or a tab)")
+	}
+
+	absRoot, err := filepath.Abs(genInfoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", genInfoRoot, err)
+	}
+	var written []string
+	write := func(fs afero.Fs) error {
+		written, err = infofile.AddAnnotations(fs, absRoot, annotations)
+		return err
+	}
+	if genInfoDiff {
+		err = previewEdit(out, absRoot, write)
+	} else {
+		err = withUndo(absRoot, []string{"gen-info"}, write)
+	}
+	if err != nil {
+		return fmt.Errorf("%w (paths are relative to --root %s)", err, genInfoRoot)
+	}
+	if genInfoDiff {
+		return nil
+	}
+	fmt.Fprintf(out, "Annotated %d paths in %d .info files\n", len(annotations), len(written))
+	for _, infoPath := range written {
+		fmt.Fprintf(out, "  %s\n", infoPath)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// styledTree is treex output with colors and an aligned, wrapped annotation column
+const styledTree = "\x1b[1m.\x1b[0m\n" +
+	"├─ README.md   \x1b[2mProject overview\x1b[0m\n" +
+	"└─ src         Source code that wraps\n" +
+	"               onto a second line\n" +
+	"   └─ main.go   Entry point\n"
+
+func withGenInfoProject(t *testing.T) afero.Fs {
+	t.Helper()
+
+	return withAppFs(t, "/project", map[string]interface{}{
+		"README.md": "# project",
+		"src":       map[string]interface{}{"main.go": "package main"},
+		"tree.txt":  styledTree,
+	}, func() { genInfoRoot, genInfoDiff = ".", false })
+}
+
+func TestGenInfo(t *testing.T) {
+	fs := withGenInfoProject(t)
+	genInfoRoot = "/project"
+
+	var out bytes.Buffer
+	require.NoError(t, runGenInfo(&out, nil, "/project/tree.txt"))
+	assert.Equal(t, "Annotated 3 paths in 2 .info files\n  .info\n  src/.info\n", out.String())
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "README.md  Project overview\nsrc  Source code that wraps onto a second line\n", string(content))
+
+	content, err = afero.ReadFile(fs, "/project/src/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "main.go  Entry point\n", string(content))
+}
+
+func TestGenInfoDiffFromStdin(t *testing.T) {
+	fs := withGenInfoProject(t)
+	genInfoRoot, genInfoDiff = "/project", true
+
+	var out bytes.Buffer
+	require.NoError(t, runGenInfo(&out, strings.NewReader(styledTree), "-"))
+	assert.Equal(t, "--- /dev/null\n+++ b/.info\n@@ -0,0 +1,2 @@\n+README.md  Project overview\n+src  Source code that wraps onto a second line\n"+
+		"--- /dev/null\n+++ b/src/.info\n@@ -0,0 +1 @@\n+main.go  Entry point\n", out.String())
+
+	exists, _ := afero.Exists(fs, "/project/.info")
+	assert.False(t, exists)
+}
+
+func TestGenInfoErrors(t *testing.T) {
+	withGenInfoProject(t)
+	genInfoRoot = "/project"
+
+	tests := map[string]string{
+		".\n├─ README.md\n":         "no annotations found",
+		".\nnot a tree line\n":      "cannot parse tree: line 2",
+		".\n├─ missing.go   Gone\n": `cannot annotate "missing.go": path does not exist (paths are relative to --root /project)`,
+	}
+	for input, expected := range tests {
+		err := runGenInfo(&bytes.Buffer{}, strings.NewReader(input), "-")
+		assert.ErrorContains(t, err, expected)
+	}
+
+	assert.ErrorContains(t, runGenInfo(&bytes.Buffer{}, nil, "/missing.txt"), `cannot read "/missing.txt"`)
+}
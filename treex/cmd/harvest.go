@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"treex/treex/harvest"
+	"treex/treex/plugins/infofile"
+)
+
+var (
+	// harvestDiff prints the .info changes as diffs instead of writing them (--diff, --dry-run)
+	harvestDiff bool
+)
+
+// harvestCmd derives annotations from READMEs and doc comments
+var harvestCmd = &cobra.Command{
+	Use:   "harvest [path]",
+	Short: "Derive annotations from READMEs, Go package docs and Python docstrings",
+	Long: `Derive annotations from documentation the project already has:
+
+  directories  Go package doc comment, else the first sentence of README.md,
+               else the docstring of __init__.py
+  .py files    Module docstring
+
+Harvested entries are written to the .info file of each path's parent
+directory below a "# treex:generated <source>" comment. Running harvest again
+refreshes those entries; annotations written by hand are never replaced.
+Hidden entries and built-in ignores (VCS, dependencies, build output) are
+skipped.`,
+	Example: `  treex harvest              # Harvest the current directory
+  treex harvest --dry-run    # Show the .info changes as diffs`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootPath := "."
+		if len(args) > 0 {
+			rootPath = args[0]
+		}
+		return runHarvest(cmd.OutOrStdout(), rootPath)
+	},
+}
+
+func init() {
+	addDiffFlags(harvestCmd.Flags(), &harvestDiff)
+	rootCmd.AddCommand(harvestCmd)
+}
+
+// runHarvest collects annotations below rootPath and writes (or lists) them
+func runHarvest(out io.Writer, rootPath string) error {
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+	if info, err := appFs.Stat(absRoot); err != nil || !info.IsDir() {
+		return fmt.Errorf("cannot harvest %q: not an accessible directory", rootPath)
+	}
+
+	candidates, err := harvest.Harvest(appFs, absRoot)
+	if err != nil {
+		return fmt.Errorf("failed to harvest %q: %w", rootPath, err)
+	}
+	if len(candidates) == 0 {
+		fmt.Fprintln(out, "No documentation found to harvest")
+		return nil
+	}
+
+	var written, kept []string
+	write := func(fs afero.Fs) error {
+		written, kept, err = infofile.WriteGenerated(fs, absRoot, candidates)
+		return err
+	}
+	if harvestDiff {
+		return previewEdit(out, absRoot, write)
+	}
+	if err := withUndo(absRoot, []string{"harvest"}, write); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Harvested %d annotations into %d .info files\n", len(candidates)-len(kept), len(written))
+	for _, infoPath := range written {
+		fmt.Fprintf(out, "  %s\n", infoPath)
+	}
+	if len(kept) > 0 {
+		fmt.Fprintf(out, "Kept %d hand-written annotations:\n", len(kept))
+		for _, path := range kept {
+			fmt.Fprintf(out, "  %s\n", path)
+		}
+	}
+	return nil
+}
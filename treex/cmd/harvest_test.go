@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+)
+
+func TestRunHarvest(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info": "docs  Written by hand\n",
+		"docs":  map[string]interface{}{"README.md": "# Docs\n\nUser guides.\n"},
+		"pkg":   map[string]interface{}{"doc.go": "// Package pkg does things.\npackage pkg\n"},
+	})
+
+	originalFs := appFs
+	appFs = fs
+	defer func() {
+		appFs = originalFs
+		harvestDiff = false
+	}()
+
+	harvestDiff = true
+	var out bytes.Buffer
+	require.NoError(t, runHarvest(&out, "/project"))
+	assert.Equal(t, "--- a/.info\n+++ b/.info\n@@ -1 +1,3 @@\n docs  Written by hand\n+# treex:generated go-doc\n+pkg  Package pkg does things\n", out.String())
+
+	harvestDiff = false
+	out.Reset()
+	require.NoError(t, runHarvest(&out, "/project"))
+	assert.Equal(t, "Harvested 1 annotations into 1 .info files\n  .info\nKept 1 hand-written annotations:\n  docs\n", out.String())
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "docs  Written by hand\n# treex:generated go-doc\npkg  Package pkg does things\n", string(content))
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	gitplugin "treex/treex/plugins/git"
+)
+
+// hookMarker identifies pre-commit hooks written by treex
+const hookMarker = "# Installed by treex hook install"
+
+var (
+	// hookFix installs a hook that removes broken entries instead of blocking the commit
+	hookFix bool
+	// hookForce replaces a pre-commit hook that treex did not write
+	hookForce bool
+)
+
+// hookCmd groups git hook management
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage the git pre-commit hook",
+}
+
+// hookInstallCmd writes a pre-commit hook running treex check --staged
+var hookInstallCmd = &cobra.Command{
+	Use:   "install [path]",
+	Short: "Install a pre-commit hook that validates staged .info files",
+	Long: `Write the pre-commit hook of the repository containing path so every commit
+runs "treex check --staged" and is blocked when staged .info files have
+problems. The hook goes in the directory core.hooksPath names when the
+repository sets it (as husky and lefthook do), else in .git/hooks.
+
+With --fix the hook runs "treex check --staged --fix" instead, removing
+broken entries and staging the result.
+
+An existing hook that treex did not install is left alone unless --force is
+given.`,
+	Example: `  treex hook install
+  treex hook install --fix`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runHookInstall(cmd.OutOrStdout(), rootArg(args))
+	},
+}
+
+func init() {
+	hookInstallCmd.Flags().BoolVar(&hookFix, "fix", false, "Fix staged .info files instead of blocking the commit")
+	hookInstallCmd.Flags().BoolVar(&hookForce, "force", false, "Replace an existing pre-commit hook")
+	hookCmd.AddCommand(hookInstallCmd)
+	rootCmd.AddCommand(hookCmd)
+}
+
+// runHookInstall writes the pre-commit hook of the repository containing rootPath
+func runHookInstall(out io.Writer, rootPath string) error {
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+	repoRoot := gitplugin.RepositoryRoot(appFs, absRoot)
+	if repoRoot == "" {
+		return fmt.Errorf("no git repository found at %s", absRoot)
+	}
+	gitDir := filepath.Join(repoRoot, ".git")
+	if info, err := appFs.Stat(gitDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s is not a directory (linked worktrees and submodules are not supported)", gitDir)
+	}
+
+	hooksDir, err := gitplugin.HooksDir(appFs, repoRoot)
+	if err != nil {
+		return err
+	}
+	hookPath := filepath.Join(hooksDir, "pre-commit")
+	if existing, err := afero.ReadFile(appFs, hookPath); err == nil && !strings.Contains(string(existing), hookMarker) && !hookForce {
+		return fmt.Errorf("%s already exists (use --force to replace it)", hookPath)
+	}
+
+	command := "treex check --staged"
+	if hookFix {
+		command += " --fix"
+	}
+	script := "#!/bin/sh\n" + hookMarker + "\n" + "exec " + command + "\n"
+
+	if err := appFs.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if err := afero.WriteFile(appFs, hookPath, []byte(script), 0755); err != nil {
+		return fmt.Errorf("failed to write %s: %w", hookPath, err)
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := appFs.Chmod(hookPath, 0755); err != nil {
+		return fmt.Errorf("failed to make %s executable: %w", hookPath, err)
+	}
+	fmt.Fprintf(out, "Installed pre-commit hook running %q\n  %s\n", command, hookPath)
+	return nil
+}
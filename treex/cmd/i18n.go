@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"treex/treex/i18n"
+)
+
+// usageTemplate is cobra's default usage template with translated headings
+const usageTemplate = `{{T "Usage:"}}{{if .Runnable}}
+  {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} [command]{{end}}{{if gt (len .Aliases) 0}}
+
+{{T "Aliases:"}}
+  {{.NameAndAliases}}{{end}}{{if .HasExample}}
+
+{{T "Examples:"}}
+{{.Example}}{{end}}{{if .HasAvailableSubCommands}}
+
+{{T "Available Commands:"}}{{range .Commands}}{{if (or .IsAvailableCommand (eq .Name "help"))}}
+  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableLocalFlags}}
+
+{{T "Flags:"}}
+{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableInheritedFlags}}
+
+{{T "Global Flags:"}}
+{{.InheritedFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableSubCommands}}
+
+{{Tf "Use \"%s [command] --help\" for more information about a command." .CommandPath}}{{end}}
+`
+
+func init() {
+	cobra.AddTemplateFuncs(map[string]interface{}{"T": i18n.Translate, "Tf": i18n.T})
+}
+
+// initLanguage selects the language of help, summaries and warnings: --lang, else the
+// environment's (LC_ALL, LC_MESSAGES, LANG)
+// An unsupported --lang is an error; an unsupported environment falls back to English.
+func initLanguage() error {
+	if lang == "" {
+		tag, _ := i18n.Match(i18n.LanguageFromEnv(os.Getenv))
+		i18n.SetLanguage(tag)
+		return nil
+	}
+	tag, ok := i18n.Match(lang)
+	if !ok {
+		var supported []string
+		for _, tag := range i18n.Languages() {
+			supported = append(supported, tag.String())
+		}
+		return fmt.Errorf("unsupported language %q (supported: %s)", lang, strings.Join(supported, ", "))
+	}
+	i18n.SetLanguage(tag)
+	return nil
+}
+
+// localizeHelp translates the descriptions shown in command's help: its own, its
+// subcommands' and its flags'. The returned function restores the English text.
+func localizeHelp(command *cobra.Command) (restore func()) {
+	var undo []func()
+	translate := func(text *string) {
+		original := *text
+		undo = append(undo, func() { *text = original })
+		*text = i18n.Translate(original)
+	}
+
+	translate(&command.Short)
+	for _, sub := range command.Commands() {
+		translate(&sub.Short)
+	}
+	visit := func(flag *pflag.Flag) { translate(&flag.Usage) }
+	command.LocalFlags().VisitAll(visit)
+	command.InheritedFlags().VisitAll(visit)
+
+	return func() {
+		for _, fn := range undo {
+			fn()
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"treex/treex/i18n"
+)
+
+func TestInitLanguage(t *testing.T) {
+	defer func() {
+		lang = ""
+		i18n.SetLanguage(language.English)
+	}()
+
+	lang = "pt_BR.UTF-8"
+	require.NoError(t, initLanguage())
+	assert.Equal(t, language.BrazilianPortuguese, i18n.Language())
+
+	lang = "de"
+	assert.ErrorContains(t, initLanguage(), `unsupported language "de" (supported: en, pt-BR)`)
+
+	lang = ""
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "de_DE.UTF-8")
+	require.NoError(t, initLanguage(), "an unsupported environment is not an error")
+	assert.Equal(t, language.English, i18n.Language())
+}
+
+func TestLocalizedHelp(t *testing.T) {
+	defer func() {
+		lang = ""
+		i18n.SetLanguage(language.English)
+		metaCmd.SetOut(nil)
+	}()
+
+	var out bytes.Buffer
+	metaCmd.SetOut(&out)
+	lang = "pt-BR"
+	metaCmd.HelpFunc()(metaCmd, nil)
+
+	help := out.String()
+	assert.Contains(t, help, "Uso:\n  treex meta [command]")
+	assert.Contains(t, help, "Comandos disponíveis:\n  grammar     Imprime uma gramática TextMate para arquivos .info")
+	assert.Contains(t, help, "Opções globais:")
+	assert.Contains(t, help, "Mostra apenas diretórios")
+	assert.Contains(t, help, `Use "treex meta [comando] --help" para mais informações sobre um comando.`)
+
+	assert.Equal(t, "Print a TextMate grammar for .info files", metaGrammarCmd.Short, "help restores the English text")
+	assert.Equal(t, "Show directories only", metaCmd.InheritedFlags().Lookup("directory").Usage)
+}
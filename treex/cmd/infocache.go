@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"treex/treex/plugins/infofile"
+)
+
+// infoCacheDir returns where the tree command keeps parsed .info files between runs
+// (~/.cache/treex/info on Linux; replaced in tests)
+var infoCacheDir = func() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "treex", "info")
+	}
+	return filepath.Join(dir, "treex", "info")
+}
+
+// infoCacheFs holds the cache files (replaced in tests)
+var infoCacheFs afero.Fs = afero.NewOsFs()
+
+// cacheable reports whether the .info files of trees on fs can be cached between runs:
+// only files on the OS filesystem outlive the process (replaced in tests)
+var cacheable = func(fs afero.Fs) bool {
+	_, ok := fs.(*afero.OsFs)
+	return ok
+}
+
+// infoCache holds the .info files parsed for the roots being shown, set by useInfoCache
+var infoCache *infofile.ParseCache
+
+// useInfoCache loads the .info files parsed by earlier runs on the same roots, for
+// cacheInfoFiles to reuse, and returns the function that saves the cache for the next run
+func useInfoCache(rootPaths []string) (save func()) {
+	absRoots := make([]string, len(rootPaths))
+	for i, rootPath := range rootPaths {
+		absRoot, err := filepath.Abs(rootPath)
+		if err != nil {
+			return func() {}
+		}
+		absRoots[i] = absRoot
+	}
+	sum := sha256.Sum256([]byte(strings.Join(absRoots, "\n")))
+	cachePath := filepath.Join(infoCacheDir(), hex.EncodeToString(sum[:])[:16]+".json")
+
+	cache := infofile.LoadParseCache(infoCacheFs, cachePath)
+	infoCache = cache
+	return func() {
+		infoCache = nil
+		// A cache that cannot be written only costs the next run a re-parse
+		_ = cache.Save(infoCacheFs, cachePath)
+	}
+}
+
+// cacheInfoFiles makes .info parsing reuse the cache loaded by useInfoCache while a tree
+// on fs is built, and returns the function that stops it. Only trees walked on a
+// cacheable filesystem are cached: an archive's in-memory files never are.
+func cacheInfoFiles(fs afero.Fs) (stop func()) {
+	if infoCache == nil || !cacheable(fs) {
+		return func() {}
+	}
+	infofile.UseCache(infoCache)
+	return func() { infofile.UseCache(nil) }
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins/infofile"
+)
+
+func TestUseInfoCache(t *testing.T) {
+	fs, cacheFs := testutil.NewTestFS(), testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":   "main.go  Entry point\n",
+		"main.go": "package main\n",
+	})
+
+	originalFs, originalCacheFs, originalDir, originalCacheable := appFs, infoCacheFs, infoCacheDir, cacheable
+	appFs, infoCacheFs, infoCacheDir = fs, cacheFs, func() string { return "/cache" }
+	cacheable = func(walked afero.Fs) bool { return walked == afero.Fs(fs) }
+	t.Cleanup(func() {
+		appFs, infoCacheFs, infoCacheDir, cacheable = originalFs, originalCacheFs, originalDir, originalCacheable
+	})
+
+	save := useInfoCache([]string{"/project"})
+	stop := cacheInfoFiles(appFs)
+	_, err := infofile.Gather(appFs, "/project")
+	require.NoError(t, err)
+	stop()
+	save()
+
+	cached, err := afero.Glob(cacheFs, "/cache/*.json")
+	require.NoError(t, err)
+	require.Len(t, cached, 1, "the cache is saved per set of roots")
+	content, err := afero.ReadFile(cacheFs, cached[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "Entry point")
+
+	t.Run("tree on another filesystem", func(t *testing.T) {
+		// Archives are walked in memory, whatever appFs is
+		other := testutil.NewTestFS()
+		other.MustCreateTree("/release", map[string]interface{}{".info": "bin  Executables\n", "bin": nil})
+
+		save := useInfoCache([]string{"/release.tgz"})
+		stop := cacheInfoFiles(other)
+		_, err := infofile.Gather(other, "/release")
+		require.NoError(t, err)
+		stop()
+		save()
+
+		cached, err := afero.Glob(cacheFs, "/cache/*.json")
+		require.NoError(t, err)
+		assert.Len(t, cached, 1)
+	})
+}
+
+func TestCacheable(t *testing.T) {
+	assert.True(t, cacheable(afero.NewOsFs()))
+	assert.False(t, cacheable(testutil.NewTestFS()), "in-memory trees do not outlive the run")
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"treex/treex/maketree"
+	"treex/treex/manual"
+	"treex/treex/plugins/infofile"
+)
+
+var (
+	// internalDocsMan and internalDocsMarkdown select the page formats (neither = both)
+	internalDocsMan      bool
+	internalDocsMarkdown bool
+	// internalDocsOut is the directory the pages are written to
+	internalDocsOut string
+	// internalDocsTemplates is a make-tree template directory listed on its page
+	internalDocsTemplates string
+)
+
+// internalDocsCmd groups the commands used to build treex's own documentation
+var internalDocsCmd = &cobra.Command{
+	Use:    "internal-docs",
+	Short:  "Build treex's own documentation",
+	Hidden: true,
+}
+
+// internalDocsGenCmd writes the reference pages of every command
+var internalDocsGenCmd = &cobra.Command{
+	Use:   "gen",
+	Short: "Write a man page and a markdown page for every command",
+	Long: `Write the reference pages of treex and its subcommands, built from their help
+text: man pages to <out>/man1/<command>.1 and markdown pages to
+<out>/markdown/<command>.md. With neither --man nor --markdown, both are
+written.
+
+--template-dir adds a TEMPLATES section to the make-tree page listing the
+templates in that directory, described by the annotations of its .info files,
+next to the built-in ones. SOURCE_DATE_EPOCH sets the date in the man pages,
+for reproducible packages.`,
+	Example: `  treex internal-docs gen --out dist/docs
+  treex internal-docs gen --man --out dist/man --template-dir packaging/templates`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runInternalDocsGen(cmd.OutOrStdout(), cmd.Root())
+	},
+}
+
+func init() {
+	internalDocsGenCmd.Flags().BoolVar(&internalDocsMan, "man", false, "Write man pages")
+	internalDocsGenCmd.Flags().BoolVar(&internalDocsMarkdown, "markdown", false, "Write markdown pages")
+	internalDocsGenCmd.Flags().StringVarP(&internalDocsOut, "out", "o", "dist/docs", "Directory to write the pages to")
+	internalDocsGenCmd.Flags().StringVar(&internalDocsTemplates, "template-dir", "",
+		"make-tree template directory to list on its page, with the annotations of its .info files")
+	internalDocsCmd.AddCommand(internalDocsGenCmd)
+	rootCmd.AddCommand(internalDocsCmd)
+}
+
+// runInternalDocsGen writes the pages of root's command tree to internalDocsOut
+func runInternalDocsGen(out io.Writer, root *cobra.Command) error {
+	date, err := sourceDate(os.Getenv)
+	if err != nil {
+		return err
+	}
+	options := manual.Options{Version: Version, Date: date}
+	if internalDocsTemplates != "" {
+		section, err := templatesSection(internalDocsTemplates)
+		if err != nil {
+			return err
+		}
+		options.Sections = map[string][]manual.Section{makeTreeCmd.CommandPath(): {section}}
+	}
+	pages := manual.Pages(root, options)
+
+	man, markdown := internalDocsMan, internalDocsMarkdown
+	if !man && !markdown {
+		man, markdown = true, true
+	}
+	var written []string
+	if man {
+		files, err := manual.WriteMan(appFs, filepath.Join(internalDocsOut, "man1"), pages)
+		if err != nil {
+			return err
+		}
+		written = append(written, files...)
+	}
+	if markdown {
+		files, err := manual.WriteMarkdown(appFs, filepath.Join(internalDocsOut, "markdown"), pages)
+		if err != nil {
+			return err
+		}
+		written = append(written, files...)
+	}
+
+	fmt.Fprintf(out, "Wrote %d pages to %s\n", len(written), internalDocsOut)
+	return nil
+}
+
+// sourceDate returns the date of the pages: $SOURCE_DATE_EPOCH when set, else now
+func sourceDate(getenv func(string) string) (time.Time, error) {
+	epoch := getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return time.Now().UTC(), nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %w", epoch, err)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// templatesSection lists the make-tree templates of dir, described by their annotations,
+// and the built-in templates dir does not override
+func templatesSection(dir string) (manual.Section, error) {
+	entries, err := afero.ReadDir(appFs, dir)
+	if err != nil {
+		return manual.Section{}, fmt.Errorf("cannot read template directory: %w", err)
+	}
+	annotations, err := infofile.GatherShared(appFs, dir)
+	if err != nil {
+		return manual.Section{}, fmt.Errorf("cannot read the annotations of %s: %w", dir, err)
+	}
+
+	section := manual.Section{
+		Title: "Templates",
+		Text:  `An annotation containing "@template:<name>" fills the new file from the template.`,
+	}
+	overridden := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ".tmpl")
+		overridden[name] = true
+		section.Entries = append(section.Entries, manual.Entry{
+			Term: "@template:" + name,
+			Text: annotations[path.Join(filepath.ToSlash(dir), entry.Name())].Notes,
+		})
+	}
+
+	var builtins []string
+	for _, name := range maketree.BuiltinTemplateNames() {
+		if !overridden[name] {
+			builtins = append(builtins, name)
+		}
+	}
+	if len(builtins) > 0 {
+		section.Text += " Built-in templates: " + strings.Join(builtins, ", ") + "."
+	}
+	return section, nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+)
+
+func TestInternalDocsGen(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/templates", map[string]interface{}{
+		".info":           "service.go.tmpl  An HTTP service with graceful shutdown\n",
+		"service.go.tmpl": "package {{.Package}}\n",
+		"readme":          "# {{.DirName}}\n",
+	})
+	originalFs := appFs
+	appFs = fs
+	t.Cleanup(func() {
+		appFs = originalFs
+		internalDocsMan, internalDocsMarkdown = false, false
+		internalDocsOut, internalDocsTemplates = "dist/docs", ""
+	})
+	internalDocsOut, internalDocsTemplates, internalDocsMarkdown = "/out", "/templates", true
+
+	var out bytes.Buffer
+	require.NoError(t, runInternalDocsGen(&out, rootCmd))
+	assert.Contains(t, out.String(), "pages to /out\n")
+
+	exists, _ := afero.DirExists(fs, "/out/man1")
+	assert.False(t, exists, "--markdown alone writes no man pages")
+	page, err := afero.ReadFile(fs, "/out/markdown/treex-make-tree.md")
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "## Templates\n")
+	assert.Contains(t, string(page), "- `@template:service.go`: An HTTP service with graceful shutdown\n")
+	assert.Contains(t, string(page), "- `@template:readme`\n")
+	assert.Contains(t, string(page), "Built-in templates: gitignore, go-main, go-package, go-test, python.")
+
+	hidden, _ := afero.Exists(fs, "/out/markdown/treex-internal-docs.md")
+	assert.False(t, hidden, "hidden commands get no page")
+}
+
+func TestSourceDate(t *testing.T) {
+	date, err := sourceDate(func(string) string { return "1700000000" })
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, time.November, 14, 22, 13, 20, 0, time.UTC), date)
+
+	_, err = sourceDate(func(string) string { return "yesterday" })
+	assert.ErrorContains(t, err, "invalid SOURCE_DATE_EPOCH")
+}
+
+func TestTemplatesSectionWithoutDirectory(t *testing.T) {
+	originalFs := appFs
+	appFs = testutil.NewTestFS()
+	t.Cleanup(func() { appFs = originalFs })
+
+	_, err := templatesSection("/missing")
+	assert.ErrorContains(t, err, "cannot read template directory")
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"treex/treex"
+	"treex/treex/config"
+	"treex/treex/lint"
+)
+
+// lintFormat selects text or GitHub Actions output
+var lintFormat string
+
+// lintCmd checks a tree against the structure rules in .treex.toml
+var lintCmd = &cobra.Command{
+	Use:   "lint [path]",
+	Short: "Check the tree against structure rules from .treex.toml",
+	Long: `Check the tree below a path against the [[lint.rule]] entries of the
+.treex.toml file at its root, and exit with an error when any rule is broken.
+
+Rule kinds:
+
+  no-files            directories matching paths may only contain directories
+  require-annotation  paths matching paths must be annotated
+  file-location       files whose name matches files must live in a directory
+                      whose name matches dirs
+
+  [[lint.rule]]
+  id    = "no-loose-src-files"
+  kind  = "no-files"
+  paths = ["src"]
+
+  [[lint.rule]]
+  id    = "services-annotated"
+  kind  = "require-annotation"
+  paths = ["services/*"]
+
+  [[lint.rule]]
+  id    = "tests-in-test-dirs"
+  kind  = "file-location"
+  files = ["*_test.py"]
+  dirs  = ["*_test", "tests"]
+
+paths are globs relative to the root ("**" spans directories); message
+replaces the default finding text. Findings are reported like treex check
+problems, against the rule's line in .treex.toml; --format github prints
+them as GitHub Actions ::error commands.`,
+	Example: `  treex lint                 # Check the current directory
+  treex lint --format github # Annotate pull requests in CI`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return paged(cmd, func(out io.Writer) error { return runLint(out, rootArg(args)) })
+	},
+}
+
+func init() {
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "Output format: text or github")
+	rootCmd.AddCommand(lintCmd)
+}
+
+// runLint evaluates the .treex.toml rules for the tree below rootPath
+func runLint(out io.Writer, rootPath string) error {
+	if lintFormat != "text" && lintFormat != "github" {
+		return fmt.Errorf("unknown format %q (use text or github)", lintFormat)
+	}
+
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+	if info, err := appFs.Stat(absRoot); err != nil || !info.IsDir() {
+		return ioError(fmt.Errorf("cannot lint %q: not an accessible directory", rootPath))
+	}
+
+	cfg, err := config.Load(appFs, absRoot)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Lint.Rules) == 0 {
+		return fmt.Errorf("no lint rules: add [[lint.rule]] entries to %s", filepath.Join(rootPath, config.FileName))
+	}
+
+	treeConfig := treex.DefaultTreeConfig(absRoot)
+	treeConfig.Filesystem = appFs
+	result, err := treex.BuildTree(treeConfig)
+	if err != nil {
+		return ioError(fmt.Errorf("failed to build tree: %w", err))
+	}
+
+	issues, err := lint.Check(result.Root, cfg.Lint.Rules)
+	if err != nil {
+		return err
+	}
+	reportIssues(out, issues, lintFormat, "treex lint")
+	if len(issues) > 0 {
+		return issuesError(fmt.Errorf("%d lint findings", len(issues)))
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+)
+
+func TestRunLint(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".treex.toml": "[[lint.rule]]\nid = \"src-dirs-only\"\nkind = \"no-files\"\npaths = [\"src\"]\n",
+		"src":         map[string]interface{}{"main.go": "package main", "pkg": map[string]interface{}{"a.go": ""}},
+	})
+	fs.MustCreateTree("/empty", map[string]interface{}{"README.md": ""})
+
+	originalFs := appFs
+	appFs = fs
+	defer func() {
+		appFs = originalFs
+		lintFormat = "text"
+	}()
+
+	var out bytes.Buffer
+	err := runLint(&out, "/project")
+	assert.EqualError(t, err, "1 lint findings")
+	assert.Equal(t, ".treex.toml:1: src/main.go: src-dirs-only: files are not allowed directly in src\n", out.String())
+
+	out.Reset()
+	lintFormat = "github"
+	require.Error(t, runLint(&out, "/project"))
+	assert.Equal(t, "::error file=.treex.toml,line=1,title=treex lint::src/main.go: src-dirs-only: files are not allowed directly in src\n", out.String())
+
+	assert.ErrorContains(t, runLint(&out, "/empty"), "no lint rules")
+}
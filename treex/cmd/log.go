@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	gitplugin "treex/treex/plugins/git"
+)
+
+// logJSON selects JSON output for the log command
+var logJSON bool
+
+// logCmd shows how the annotation of a path changed over the git history
+var logCmd = &cobra.Command{
+	Use:   "log <path>",
+	Short: "Show the git history of a path's annotation",
+	Long: `Show the commits that changed the annotation of a path, newest first: who
+changed it, when, in which .info file and line, and the text before and after.
+
+The annotation is read in each commit the way treex shows it, from the
+closest .info file annotating the path, following first parents from HEAD.
+Moving an entry between .info files without changing its text is not listed.`,
+	Example: `  treex log src/api           # History of the src/api annotation
+  treex log --json README.md  # Machine-readable history`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeAnnotatedPaths(cmd, args, toComplete)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return paged(cmd, func(out io.Writer) error { return runLog(out, args[0]) })
+	},
+}
+
+func init() {
+	logCmd.Flags().BoolVar(&logJSON, "json", false, "Output the history as JSON")
+	rootCmd.AddCommand(logCmd)
+}
+
+// runLog prints the annotation history of targetPath
+func runLog(out io.Writer, targetPath string) error {
+	absTarget, err := filepath.Abs(targetPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", targetPath, err)
+	}
+	repoRoot := gitplugin.RepositoryRoot(appFs, filepath.Dir(absTarget))
+	if repoRoot == "" {
+		return fmt.Errorf("%s is not inside a git repository", targetPath)
+	}
+	rel, err := filepath.Rel(repoRoot, absTarget)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", targetPath, err)
+	}
+
+	changes, err := gitplugin.AnnotationHistory(appFs, repoRoot, filepath.ToSlash(rel))
+	if err != nil {
+		return err
+	}
+
+	if logJSON {
+		if changes == nil {
+			changes = []gitplugin.AnnotationChange{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(changes)
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintf(out, "No annotation history for %s\n", targetPath)
+		return nil
+	}
+	for i, change := range changes {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "%s %s %s  %s\n", change.Commit[:7], change.When.Format("2006-01-02"), change.Author, change.Summary)
+		fmt.Fprintf(out, "  %s:%d\n", change.InfoFile, change.Line)
+		if change.Before != "" {
+			fmt.Fprintf(out, "  - %s\n", change.Before)
+		}
+		if change.After != "" {
+			fmt.Fprintf(out, "  + %s\n", change.After)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog(t *testing.T) {
+	fs := withAppFs(t, "/project", map[string]interface{}{"main.go": "package main"}, func() { logJSON = false })
+	require.NoError(t, fs.MkdirAll("/elsewhere", 0755))
+	repo := fs.MustInitRepo("/project")
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	when := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, notes := range []string{"First draft", "Command line entry point"} {
+		require.NoError(t, afero.WriteFile(fs, "/project/.info", []byte("main.go  "+notes+"\n"), 0644))
+		_, err = worktree.Add(".info")
+		require.NoError(t, err)
+		_, err = worktree.Commit("Annotate main.go", &git.CommitOptions{Author: &object.Signature{Name: "Ana", Email: "ana@example.com", When: when}})
+		require.NoError(t, err)
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, runLog(&out, "/project/main.go"))
+	hash := regexp.MustCompile(`^[0-9a-f]{7} `)
+	assert.Regexp(t, hash, out.String())
+	assert.Contains(t, out.String(), " 2024-03-01 Ana  Annotate main.go\n  .info:1\n  - First draft\n  + Command line entry point\n\n")
+	assert.Contains(t, out.String(), "  .info:1\n  + First draft\n")
+
+	out.Reset()
+	require.NoError(t, runLog(&out, "/project/other.go"))
+	assert.Contains(t, out.String(), "No annotation history for")
+
+	out.Reset()
+	logJSON = true
+	require.NoError(t, runLog(&out, "/project/other.go"))
+	assert.Equal(t, "[]\n", out.String())
+
+	err = runLog(&out, "/elsewhere")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not inside a git repository")
+}
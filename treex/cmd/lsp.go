@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"treex/treex/lsp"
+)
+
+// lspCmd runs a Language Server on stdio so editors can check .info files as they are written
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a Language Server for .info files on stdio",
+	Long: `Run a Language Server Protocol (LSP) server on stdin/stdout for editing
+.info files. Configure your editor to start "treex lsp" for files named .info.
+
+Features:
+  Diagnostics   Missing paths, paths outside the .info file's directory,
+                duplicate entries and empty annotations, as you type
+  Completion    Paths relative to the .info file's directory
+  Definition    Jump from an entry to the annotated file or directory
+  Hover         Where an entry resolves to and whether it exists`,
+	Example:      `  treex lsp    # Started by the editor, not run by hand`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		srv := lsp.NewServer(lsp.Config{Filesystem: appFs, Version: Version})
+		return srv.Serve(cmd.InOrStdin(), cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"treex/treex/maketree"
+	"treex/treex/treetext"
+)
+
+var (
+	// makeTreeRoot is the directory the tree is created in
+	makeTreeRoot string
+	// makeTreeTemplateDir overrides the user templates directory
+	makeTreeTemplateDir string
+	// makeTreeForce overwrites existing files
+	makeTreeForce bool
+	// makeTreeDiff prints the files that would change as diffs instead of writing them
+	// (--diff, --dry-run)
+	makeTreeDiff bool
+	// makeTreeFormat selects the input format: auto, text, json or yaml
+	makeTreeFormat string
+)
+
+// makeTreeCmd scaffolds files and directories from a tree diagram
+var makeTreeCmd = &cobra.Command{
+	Use:   "make-tree [file]",
+	Short: "Create files and directories from a tree diagram",
+	Long: `Create the files and directories drawn in a tree diagram (the formats read by
+"treex gen-info"). Entries with children or a trailing "/" are directories;
+annotations are written to .info files.
+
+File contents:
+
+  A fenced block below an entry becomes the file's content:
+
+    └─ main.go   Entry point
+       ` + "```" + `
+       package main
+       ` + "```" + `
+
+  "@template:<name>" in an annotation fills the file from a template, looked up
+  as <name> or <name>.tmpl in the template directory, then among the built-ins
+  (go-main, go-package, go-test, python, readme, gitignore). Templates use Go
+  text/template with .Name, .Path, .Dir, .DirName, .Package and .Notes.
+
+Structure input (--format json|yaml) is the nested map used by test fixtures:
+a string is a file with that content, a map is a directory, null is an empty
+directory, and a directory's "__info" key maps entry names to annotations:
+
+  {"cmd": {"main.go": "package main\n", "__info": {"main.go": "Entry point"}}}
+
+The format is detected from the file extension (.json, .yaml, .yml) and is
+text otherwise. Existing files are skipped unless --force is given. Reads
+stdin when no file (or "-") is given.`,
+	Example: `  treex make-tree layout.txt --root new-project
+  treex make-tree --dry-run < layout.txt
+  treex make-tree --diff --force layout.txt   # What would be overwritten
+  generate-layout | treex make-tree --format json --root out`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		input := "-"
+		if len(args) > 0 {
+			input = args[0]
+		}
+		return runMakeTree(cmd.OutOrStdout(), cmd.InOrStdin(), input)
+	},
+}
+
+func init() {
+	makeTreeCmd.Flags().StringVar(&makeTreeRoot, "root", ".", "Directory to create the tree in")
+	makeTreeCmd.Flags().StringVar(&makeTreeTemplateDir, "template-dir", "",
+		"Directory containing file templates (default: ~/.config/treex/templates)")
+	makeTreeCmd.Flags().BoolVar(&makeTreeForce, "force", false, "Overwrite existing files")
+	addDiffFlags(makeTreeCmd.Flags(), &makeTreeDiff)
+	makeTreeCmd.Flags().StringVar(&makeTreeFormat, "format", "auto", "Input format: auto, text, json or yaml")
+	rootCmd.AddCommand(makeTreeCmd)
+}
+
+// runMakeTree parses the diagram in input (a file, or "-" for in) and creates it
+func runMakeTree(out io.Writer, in io.Reader, input string) error {
+	if input != "-" {
+		file, err := appFs.Open(input)
+		if err != nil {
+			return fmt.Errorf("cannot read %q: %w", input, err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	entries, err := readTreeSpec(in, input, makeTreeFormat)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("the tree has no entries")
+	}
+
+	absRoot, err := filepath.Abs(makeTreeRoot)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", makeTreeRoot, err)
+	}
+	templateDir := makeTreeTemplateDir
+	if templateDir == "" {
+		templateDir = maketree.DefaultTemplateDir()
+	}
+
+	var result *maketree.MakeTreeResult
+	create := func(fs afero.Fs) error {
+		result, err = maketree.MakeTree(fs, absRoot, entries, maketree.MakeTreeOptions{
+			TemplateDir: templateDir,
+			Overwrite:   makeTreeForce,
+		})
+		return err
+	}
+	if makeTreeDiff {
+		return previewEdit(out, absRoot, create)
+	}
+	if err := withUndo(absRoot, []string{"make-tree"}, create); err != nil {
+		return err
+	}
+	printMakeTreeResult(out, result)
+	return nil
+}
+
+// readTreeSpec parses a tree diagram or structure input; format "auto" (or "") picks
+// json or yaml from the extension of input and text otherwise
+func readTreeSpec(in io.Reader, input, format string) ([]treetext.Entry, error) {
+	requested := format
+	if format == "" || format == "auto" {
+		switch strings.ToLower(filepath.Ext(input)) {
+		case ".json":
+			format = "json"
+		case ".yaml", ".yml":
+			format = "yaml"
+		default:
+			format = "text"
+		}
+	}
+
+	switch format {
+	case "text":
+		entries, err := treetext.Parse(in)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse tree: %w", err)
+		}
+		return entries, nil
+	case "json", "yaml":
+		content, err := io.ReadAll(in)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %q: %w", input, err)
+		}
+		return maketree.ParseStructure(content, format)
+	default:
+		return nil, fmt.Errorf("unknown format %q (valid: auto, text, json, yaml)", requested)
+	}
+}
+
+// printMakeTreeResult summarizes what was created
+func printMakeTreeResult(out io.Writer, result *maketree.MakeTreeResult) {
+	fmt.Fprintf(out, "Created %d directories and %d files\n", len(result.Directories), len(result.Files))
+
+	if len(result.Templates) > 0 {
+		paths := make([]string, 0, len(result.Templates))
+		for path := range result.Templates {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		fmt.Fprintln(out, "Templates applied:")
+		for _, path := range paths {
+			fmt.Fprintf(out, "  %s  (%s)\n", path, result.Templates[path])
+		}
+	}
+	if len(result.Skipped) > 0 {
+		fmt.Fprintln(out, "Skipped existing files (use --force to overwrite):")
+		for _, path := range result.Skipped {
+			fmt.Fprintf(out, "  %s\n", path)
+		}
+	}
+	if len(result.InfoFiles) > 0 {
+		fmt.Fprintf(out, "Annotations written to %d .info files\n", len(result.InfoFiles))
+	}
+}
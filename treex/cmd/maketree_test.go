@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+)
+
+func TestRunMakeTree(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/work", map[string]interface{}{"existing.txt": "kept"})
+
+	originalFs := appFs
+	appFs = fs
+	defer func() {
+		appFs = originalFs
+		makeTreeRoot, makeTreeTemplateDir, makeTreeForce, makeTreeDiff, makeTreeFormat = ".", "", false, false, "auto"
+	}()
+	makeTreeRoot, makeTreeTemplateDir = "/work", "/no-templates"
+
+	diagram := ".\n├─ existing.txt\n└─ cmd\n   └─ main.go   Entry point @template:go-main\n"
+	var out bytes.Buffer
+	require.NoError(t, runMakeTree(&out, strings.NewReader(diagram), "-"))
+	assert.Equal(t, "Created 1 directories and 1 files\n"+
+		"Templates applied:\n  cmd/main.go  (go-main)\n"+
+		"Skipped existing files (use --force to overwrite):\n  existing.txt\n"+
+		"Annotations written to 1 .info files\n", out.String())
+
+	content, err := afero.ReadFile(fs, "/work/cmd/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "main.go  Entry point\n", string(content))
+
+	assert.ErrorContains(t, runMakeTree(&out, strings.NewReader(".\n"), "-"), "no entries")
+}
+
+func TestRunMakeTreeStructureInput(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/work", map[string]interface{}{
+		"layout.yaml": "cmd:\n  main.go: \"package main\\n\"\n  __info:\n    main.go: Entry point\ndocs: null\n",
+	})
+
+	originalFs := appFs
+	appFs = fs
+	defer func() {
+		appFs = originalFs
+		makeTreeRoot, makeTreeFormat = ".", "auto"
+	}()
+	makeTreeRoot = "/work/out"
+
+	var out bytes.Buffer
+	require.NoError(t, runMakeTree(&out, nil, "/work/layout.yaml"))
+	assert.Equal(t, "Created 2 directories and 1 files\nAnnotations written to 1 .info files\n", out.String())
+
+	content, err := afero.ReadFile(fs, "/work/out/cmd/main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(content))
+
+	// Format flag overrides detection; JSON from stdin
+	makeTreeFormat = "json"
+	out.Reset()
+	require.NoError(t, runMakeTree(&out, strings.NewReader(`{"extra.txt": "x"}`), "-"))
+	exists, _ := afero.Exists(fs, "/work/out/extra.txt")
+	assert.True(t, exists)
+
+	makeTreeFormat = "toml"
+	assert.ErrorContains(t, runMakeTree(&out, strings.NewReader(""), "-"), `unknown format "toml"`)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"treex/treex/mcp"
+	"treex/treex/plugins/infofile"
+)
+
+// mcpCmd runs a Model Context Protocol server on stdio for AI coding assistants
+var mcpCmd = &cobra.Command{
+	Use:   "mcp [path]",
+	Short: "Run a Model Context Protocol server on stdio",
+	Long: `Run a Model Context Protocol (MCP) server on stdin/stdout so AI coding
+assistants can explore the project and maintain its annotations.
+
+Tools:
+  get_tree        Directory tree with annotations (path, depth)
+  get_annotation  Annotation of a single path (path)
+  add_annotation  Add or replace an annotation in the parent's .info file (path, notes)
+  validate        Problems found in .info files
+
+Paths are relative to the project directory. Filters given to treex
+(--exclude, --no-builtin-ignores, ...) apply to get_tree.`,
+	Example: `  treex mcp              # Serve the current directory
+  treex mcp ~/src/app    # Serve another project`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootPath := "."
+		if len(args) > 0 {
+			rootPath = args[0]
+		}
+
+		absRoot, err := filepath.Abs(rootPath)
+		if err != nil {
+			return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+		}
+		if info, err := appFs.Stat(absRoot); err != nil || !info.IsDir() {
+			return fmt.Errorf("cannot serve %q: not an accessible directory", rootPath)
+		}
+
+		// Requests re-gather annotations; only changed .info files are parsed again
+		infofile.UseCache(infofile.NewParseCache())
+
+		srv := mcp.NewServer(mcp.Config{
+			Root:       absRoot,
+			Filesystem: appFs,
+			Tree:       buildTreeConfig(absRoot),
+			Version:    Version,
+		})
+		return srv.Serve(cmd.InOrStdin(), cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/spf13/cobra"
+	"treex/treex/plugins/infofile"
+)
+
+// metaCmd groups commands describing treex itself for tools and editor integrations
+var metaCmd = &cobra.Command{
+	Use:   "meta",
+	Short: "Describe treex's formats for tools and editor integrations",
+}
+
+// metaGrammarCmd prints the .info syntax as a TextMate grammar
+var metaGrammarCmd = &cobra.Command{
+	Use:   "grammar",
+	Short: "Print a TextMate grammar for .info files",
+	Long: `Print a TextMate grammar (JSON) describing the .info syntax: comments, the
+treex:max-depth and treex:generated directive comments, and entries in the
+space and colon syntaxes, with backslash escapes inside paths.
+
+Editor plugins can bundle or generate their highlighting from it instead of
+hardcoding the format; it is derived from the parser, so it changes with it.
+The rules are single-line regular expressions that also port directly to
+tree-sitter or other highlighters. Scopes end in ".treex-info".`,
+	Example:      `  treex meta grammar > syntaxes/treex-info.tmLanguage.json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMetaGrammar(cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	metaCmd.AddCommand(metaGrammarCmd)
+	rootCmd.AddCommand(metaCmd)
+}
+
+// runMetaGrammar writes the .info TextMate grammar as indented JSON
+func runMetaGrammar(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(infofile.TextMateGrammar())
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaGrammar(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, runMetaGrammar(&out))
+
+	var grammar struct {
+		ScopeName string                   `json:"scopeName"`
+		FileTypes []string                 `json:"fileTypes"`
+		Patterns  []map[string]interface{} `json:"patterns"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &grammar))
+	assert.Equal(t, "source.treex-info", grammar.ScopeName)
+	assert.Equal(t, []string{"info"}, grammar.FileTypes)
+	assert.NotEmpty(t, grammar.Patterns)
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+// noPager disables showing long output through a pager
+var noPager bool
+
+// defaultPager is used when neither TREEX_PAGER nor PAGER is set
+const defaultPager = "less -R"
+
+// pagerEnv is what a pager reads from the process it runs in
+type pagerEnv struct {
+	getenv   func(string) string
+	terminal func(out io.Writer) bool         // Whether out is a terminal
+	height   func(out io.Writer) (int, error) // Rows of the terminal out is
+	// run starts the pager command with input and waits for it; an error means it did not start
+	run func(command []string, input io.Reader, out io.Writer) error
+}
+
+// systemPagerEnv reads the pager environment of the running process
+func systemPagerEnv() pagerEnv {
+	return pagerEnv{getenv: os.Getenv, terminal: writerIsTerminal, height: terminalHeight, run: runPagerCommand}
+}
+
+// pager collects the output of a command and, when it is taller than the terminal,
+// shows it through the pager like git does. Without a terminal, or when paging is
+// disabled, writes go straight to the output.
+type pager struct {
+	out     io.Writer
+	command []string // Pager command and arguments; nil when not paging
+	height  int
+	run     func(command []string, input io.Reader, out io.Writer) error
+	buffer  bytes.Buffer
+}
+
+// newPager returns the pager of out: TREEX_PAGER, else PAGER, else less -R, unless
+// --no-pager is given, out is not a terminal, or the pager is empty or cat
+func newPager(out io.Writer, env pagerEnv) *pager {
+	p := &pager{out: out, run: env.run}
+	if noPager || !env.terminal(out) {
+		return p
+	}
+	height, err := env.height(out)
+	if err != nil || height <= 0 {
+		return p
+	}
+
+	command := env.getenv("TREEX_PAGER")
+	if command == "" {
+		command = env.getenv("PAGER")
+	}
+	if command == "" {
+		command = defaultPager
+	}
+	if fields := strings.Fields(command); len(fields) > 0 && fields[0] != "cat" {
+		p.command, p.height = fields, height
+	}
+	return p
+}
+
+// paged runs a command writing its output to the pager of the command's output
+// Output written before run fails is still shown.
+func paged(cmd *cobra.Command, run func(out io.Writer) error) error {
+	out := newPager(cmd.OutOrStdout(), systemPagerEnv())
+	err := run(out)
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+func (p *pager) Write(b []byte) (int, error) {
+	if p.command == nil {
+		return p.out.Write(b)
+	}
+	return p.buffer.Write(b)
+}
+
+// Flush shows the collected output: through the pager when it does not fit the terminal,
+// directly otherwise. A pager that cannot be started is skipped.
+func (p *pager) Flush() error {
+	if p.command == nil || p.buffer.Len() == 0 {
+		return nil
+	}
+	defer p.buffer.Reset()
+
+	// The last line is left for the shell prompt, as less does
+	if bytes.Count(p.buffer.Bytes(), []byte("\n")) < p.height {
+		_, err := p.out.Write(p.buffer.Bytes())
+		return err
+	}
+
+	if err := p.run(p.command, bytes.NewReader(p.buffer.Bytes()), p.out); err != nil {
+		_, err := p.out.Write(p.buffer.Bytes())
+		return err
+	}
+	return nil
+}
+
+// writerIsTerminal reports whether out is a file attached to a terminal
+func writerIsTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	return ok && isTerminal(f)
+}
+
+// terminalHeight returns the rows of the terminal out is
+func terminalHeight(out io.Writer) (int, error) {
+	_, height, err := term.GetSize(out.(*os.File).Fd())
+	return height, err
+}
+
+// runPagerCommand runs the pager with input on out
+func runPagerCommand(command []string, input io.Reader, out io.Writer) error {
+	pagerCmd := exec.Command(command[0], command[1:]...)
+	pagerCmd.Stdin = input
+	pagerCmd.Stdout = out
+	pagerCmd.Stderr = os.Stderr
+	if err := pagerCmd.Start(); err != nil {
+		return err
+	}
+	// Quitting the pager early is not an error
+	_ = pagerCmd.Wait()
+	return nil
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upperPager is a pager run that shows its input in upper case
+func upperPager(command []string, input io.Reader, out io.Writer) error {
+	content, err := io.ReadAll(input)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(bytes.ToUpper(content))
+	return err
+}
+
+// terminalEnv is a pager environment for a terminal of the given height
+func terminalEnv(height int, pagerVar string, run func([]string, io.Reader, io.Writer) error) pagerEnv {
+	return pagerEnv{
+		getenv:   func(name string) string { return map[string]string{"PAGER": pagerVar}[name] },
+		terminal: func(io.Writer) bool { return true },
+		height:   func(io.Writer) (int, error) { return height, nil },
+		run:      run,
+	}
+}
+
+// pagerOutput flushes content written to a pager on a terminal of the given height and
+// returns what reached the output
+func pagerOutput(t *testing.T, env pagerEnv, content string) string {
+	t.Helper()
+	var out bytes.Buffer
+	p := newPager(&out, env)
+	_, err := p.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, p.Flush())
+	return out.String()
+}
+
+func TestPagerShowsShortOutputDirectly(t *testing.T) {
+	assert.Equal(t, "a\nb\n", pagerOutput(t, terminalEnv(3, "upper", upperPager), "a\nb\n"))
+}
+
+func TestPagerPagesLongOutput(t *testing.T) {
+	var command []string
+	run := func(c []string, input io.Reader, out io.Writer) error {
+		command = c
+		return upperPager(c, input, out)
+	}
+	assert.Equal(t, "A\nB\nC\n", pagerOutput(t, terminalEnv(3, "upper -r", run), "a\nb\nc\n"))
+	assert.Equal(t, []string{"upper", "-r"}, command)
+
+	command = nil
+	pagerOutput(t, terminalEnv(3, "", run), "a\nb\nc\n")
+	assert.Equal(t, strings.Fields(defaultPager), command, "less -R without PAGER")
+}
+
+func TestPagerFallsBackWhenPagerIsMissing(t *testing.T) {
+	missing := func([]string, io.Reader, io.Writer) error { return errors.New("not found") }
+	assert.Equal(t, "a\nb\nc\n", pagerOutput(t, terminalEnv(2, "treex-no-such-pager", missing), "a\nb\nc\n"))
+}
+
+func TestNewPagerWithoutPaging(t *testing.T) {
+	t.Cleanup(func() { noPager = false })
+
+	env := terminalEnv(2, "upper", upperPager)
+	env.terminal = func(io.Writer) bool { return false }
+	assert.Equal(t, "a\nb\nc\n", pagerOutput(t, env, "a\nb\nc\n"), "output that is not a terminal is never paged")
+
+	assert.Equal(t, "a\nb\nc\n", pagerOutput(t, terminalEnv(2, "cat", upperPager), "a\nb\nc\n"), "cat pages nothing")
+
+	noPager = true
+	assert.Equal(t, "a\nb\nc\n", pagerOutput(t, terminalEnv(2, "upper", upperPager), "a\nb\nc\n"))
+}
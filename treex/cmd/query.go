@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"treex/treex"
+	"treex/treex/query"
+)
+
+// queryJSON selects the JSON output of the query command
+var queryJSON bool
+
+// queryCmd prints the paths of the entries a selector matches
+var queryCmd = &cobra.Command{
+	Use:   "query <selector> [path]",
+	Short: "List the entries matching a selector",
+	Long: `List the entries of the tree matching a selector, one path per line in tree
+order, directories ending in "/".
+
+A selector chains compounds like CSS: a type (dir, file or *) followed by
+conditions in brackets. "a > b" matches b directly inside a, "a b" matches b
+anywhere below a, and "s1, s2" matches either selector.
+
+Conditions are [attribute op value], or [attribute] for [attribute=true]:
+
+  name, path, ext, annotation, kind    text: = != ^= $= *= ~= (regexp)
+  size, depth                          numbers: = != < <= > >= (size takes 10KB, 1MB)
+  annotated, hidden                    true or false
+  staged, modified, untracked          true or false, from git status
+
+Values with spaces or brackets can be quoted. The same selectors prune the tree
+with treex --select. The tree is built with the same filters as "treex"
+(--exclude, --level, ...).`,
+	Example: `  treex query 'dir[name=internal] > file[ext=go][annotated=false]'
+  treex query 'file[size>=1MB]' assets   # Large files under assets
+  treex query '[annotation~="(?i)todo"], file[modified]' --json`,
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootPath := "."
+		if len(args) > 1 {
+			rootPath = args[1]
+		}
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+		return paged(cmd, func(out io.Writer) error { return runQuery(ctx, out, args[0], rootPath) })
+	},
+}
+
+func init() {
+	queryCmd.Flags().BoolVar(&queryJSON, "json", false, "Output the matching entries as JSON")
+	rootCmd.AddCommand(queryCmd)
+}
+
+// queryMatch is one entry of the JSON output of the query command
+type queryMatch struct {
+	Path       string `json:"path"`
+	IsDir      bool   `json:"isDir"`
+	Size       int64  `json:"size"`
+	Annotation string `json:"annotation,omitempty"`
+}
+
+// runQuery builds the tree for rootPath and prints the entries matching selector
+func runQuery(ctx context.Context, out io.Writer, selector, rootPath string) error {
+	q, err := query.ParseSelector(selector)
+	if err != nil {
+		return err
+	}
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+	if _, err := appFs.Stat(absRoot); err != nil {
+		return fmt.Errorf("cannot access path %q: %w", rootPath, err)
+	}
+
+	result, err := treex.BuildTreeContext(ctx, buildTreeConfig(absRoot))
+	if err != nil {
+		return fmt.Errorf("failed to build tree: %w", err)
+	}
+	nodes := query.Find(result.Root, q)
+
+	if queryJSON {
+		matches := make([]queryMatch, 0, len(nodes))
+		for _, node := range nodes {
+			match := queryMatch{Path: filepath.ToSlash(node.Path), IsDir: node.IsDir, Size: node.Size}
+			if annotation := node.GetAnnotation(); annotation != nil {
+				match.Annotation = annotation.Notes
+			}
+			matches = append(matches, match)
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(matches)
+	}
+
+	for _, node := range nodes {
+		name := filepath.ToSlash(node.Path)
+		if node.IsDir {
+			name += "/"
+		}
+		fmt.Fprintln(out, name)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/query"
+	"treex/treex/types"
+)
+
+func queryTestFS(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"internal": map[string]interface{}{
+			".info":   "api.go  Public handlers\n",
+			"api.go":  "package internal",
+			"util.go": "package internal",
+		},
+		"cmd": map[string]interface{}{"main.go": "package main"},
+	})
+	originalFs := appFs
+	appFs = fs
+	t.Cleanup(func() {
+		appFs = originalFs
+		queryJSON = false
+	})
+}
+
+func TestRunQuery(t *testing.T) {
+	queryTestFS(t)
+
+	var out bytes.Buffer
+	require.NoError(t, runQuery(context.Background(), &out, "dir[name=internal] > file[ext=go][annotated=false], dir", "/project"))
+	assert.Equal(t, "cmd/\ninternal/\ninternal/util.go\n", out.String())
+
+	out.Reset()
+	queryJSON = true
+	require.NoError(t, runQuery(context.Background(), &out, "file[annotated]", "/project"))
+	var matches []queryMatch
+	require.NoError(t, json.Unmarshal(out.Bytes(), &matches))
+	assert.Equal(t, []queryMatch{{Path: "internal/api.go", Size: 16, Annotation: "Public handlers"}}, matches)
+
+	out.Reset()
+	require.NoError(t, runQuery(context.Background(), &out, "file[size>1MB]", "/project"))
+	assert.Equal(t, "[]\n", out.String())
+
+	assert.ErrorContains(t, runQuery(context.Background(), &out, "file[owner=me]", "/project"), `unknown attribute "owner"`)
+}
+
+func TestBuildRootTreeWithSelect(t *testing.T) {
+	queryTestFS(t)
+
+	selector, err := query.ParseSelector("file[name=main.go]")
+	require.NoError(t, err)
+	result, err := buildRootTree(context.Background(), "/project", nil, nil, selector, make(map[*types.Node]linkBase))
+	require.NoError(t, err)
+
+	var paths []string
+	_ = types.WalkTree(result.Root, func(node *types.Node, depth int) error {
+		paths = append(paths, node.Path)
+		return nil
+	})
+	assert.Equal(t, []string{".", "cmd", "cmd/main.go"}, paths)
+}
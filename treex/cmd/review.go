@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	gitplugin "treex/treex/plugins/git"
+	"treex/treex/plugins/infofile"
+)
+
+var (
+	// reviewDays lists annotations last reviewed more than this many days ago (0 = any age)
+	reviewDays int
+	// reviewCommits lists annotations whose path changed in this many commits since the review (0 = off)
+	reviewCommits int
+	// reviewMark records a review of every annotation listed
+	reviewMark bool
+	// reviewBy names the reviewer recorded by --mark
+	reviewBy string
+	// reviewJSON selects JSON output
+	reviewJSON bool
+)
+
+// reviewCmd lists annotations due for review and marks them reviewed
+var reviewCmd = &cobra.Command{
+	Use:   "review [path]",
+	Short: "List annotations due for review and mark them reviewed",
+	Long: `List the annotations of the .info files below a path that are due for review,
+so documentation stays fresh in long-lived repositories.
+
+The last review of an entry is the comment line directly above it:
+
+  # treex:reviewed Ada Lovelace 2026-03-14
+  src/api  HTTP handlers
+
+An annotation is due when it was never reviewed, was last reviewed more than
+--days ago, or, with --commits, when its path changed in at least that many
+commits (following first parents from HEAD) after the day of its review.
+
+With --mark, every annotation listed is marked reviewed today by --by
+(default: git user.name, then $USER), replacing its previous review line.
+Marks can be reverted with treex undo.`,
+	Example: `  treex review                       # Annotations unreviewed for 180 days
+  treex review --days 30 --commits 5  # Stricter, for fast-moving code
+  treex review --mark --by "Ada"      # Record that the listed ones were reviewed`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return paged(cmd, func(out io.Writer) error { return runReview(out, rootArg(args), time.Now()) })
+	},
+}
+
+func init() {
+	reviewCmd.Flags().IntVar(&reviewDays, "days", 180, "List annotations last reviewed more than this many days ago (0 = any age)")
+	reviewCmd.Flags().IntVar(&reviewCommits, "commits", 0, "Also list annotations whose path changed in this many commits since their review")
+	reviewCmd.Flags().BoolVar(&reviewMark, "mark", false, "Mark the annotations listed as reviewed today")
+	reviewCmd.Flags().StringVar(&reviewBy, "by", "", "Reviewer recorded by --mark (default: git user.name)")
+	reviewCmd.Flags().BoolVar(&reviewJSON, "json", false, "Output the annotations due as JSON")
+	rootCmd.AddCommand(reviewCmd)
+}
+
+// dueReview is an annotation due for review
+type dueReview struct {
+	infofile.ReviewEntry
+	Commits int `json:"commitsSinceReview,omitempty"` // Commits changing the path since the review (with --commits)
+}
+
+// runReview lists (and with --mark, marks) the annotations below rootPath due for review on now
+func runReview(out io.Writer, rootPath string, now time.Time) error {
+	if reviewDays < 0 || reviewCommits < 0 {
+		return fmt.Errorf("--days and --commits cannot be negative")
+	}
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+	if info, err := appFs.Stat(absRoot); err != nil || !info.IsDir() {
+		return ioError(fmt.Errorf("cannot review %q: not an accessible directory", rootPath))
+	}
+
+	entries, err := infofile.Reviews(appFs, absRoot)
+	if err != nil {
+		return ioError(err)
+	}
+	due, err := dueReviews(absRoot, entries, now)
+	if err != nil {
+		return err
+	}
+
+	if reviewMark && len(due) > 0 {
+		reviewer := reviewBy
+		if reviewer == "" {
+			reviewer = defaultReviewer(absRoot)
+		}
+		if reviewer == "" {
+			return fmt.Errorf("cannot tell who is reviewing: pass --by")
+		}
+		marked := make([]infofile.ReviewEntry, len(due))
+		for i, review := range due {
+			marked[i] = review.ReviewEntry
+		}
+		err := withUndo(absRoot, []string{"review", "--mark"}, func(fs afero.Fs) error {
+			_, err := infofile.MarkReviewed(fs, absRoot, marked, reviewer, now)
+			return err
+		})
+		if err != nil {
+			return ioError(err)
+		}
+	}
+
+	if reviewJSON {
+		if due == nil {
+			due = []dueReview{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(due)
+	}
+
+	if len(due) == 0 {
+		fmt.Fprintln(out, "No annotations due for review")
+		return nil
+	}
+	if reviewMark {
+		// Marks shifted the lines below them, so entries are named by path only
+		fmt.Fprintf(out, "Marked %d annotations reviewed\n", len(due))
+		for _, review := range due {
+			fmt.Fprintf(out, "  %s\n", review.Path)
+		}
+		return nil
+	}
+	for _, review := range due {
+		status := "never reviewed"
+		if review.Review != nil {
+			status = fmt.Sprintf("reviewed %s by %s", review.Review.At.Format(time.DateOnly), review.Review.By)
+			if review.Commits > 0 {
+				status += fmt.Sprintf(", %d commits since", review.Commits)
+			}
+		}
+		fmt.Fprintf(out, "%s:%d  %s  (%s)\n", review.InfoFile, review.Line, review.Path, status)
+	}
+	fmt.Fprintf(out, "%d annotations due for review\n", len(due))
+	return nil
+}
+
+// dueReviews selects the entries due for review on now according to --days and --commits
+func dueReviews(absRoot string, entries []infofile.ReviewEntry, now time.Time) ([]dueReview, error) {
+	var due []dueReview
+	var reviewed []infofile.ReviewEntry // Entries still to check against the history
+	for _, entry := range entries {
+		review := entry.Review
+		if review == nil || (reviewDays > 0 && now.Sub(review.At) > time.Duration(reviewDays)*24*time.Hour) {
+			due = append(due, dueReview{ReviewEntry: entry})
+		} else if reviewCommits > 0 {
+			reviewed = append(reviewed, entry)
+		}
+	}
+	if len(reviewed) == 0 {
+		return due, nil
+	}
+
+	repoRoot := gitplugin.RepositoryRoot(appFs, absRoot)
+	if repoRoot == "" {
+		return nil, fmt.Errorf("--commits needs %s to be inside a git repository", absRoot)
+	}
+	prefix, err := filepath.Rel(repoRoot, absRoot)
+	if err != nil {
+		return nil, err
+	}
+	target := func(entry infofile.ReviewEntry) string {
+		return path.Join(filepath.ToSlash(prefix), entry.Path)
+	}
+	since := make(map[string]time.Time, len(reviewed))
+	for _, entry := range reviewed {
+		when := entry.Review.At.AddDate(0, 0, 1) // Commits on the review day were reviewed
+		if previous, seen := since[target(entry)]; !seen || when.Before(previous) {
+			since[target(entry)] = when
+		}
+	}
+	counts, err := gitplugin.CommitsSince(appFs, repoRoot, since)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range reviewed {
+		if commits := counts[target(entry)]; commits >= reviewCommits {
+			due = append(due, dueReview{ReviewEntry: entry, Commits: commits})
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		if due[i].InfoFile != due[j].InfoFile {
+			return due[i].InfoFile < due[j].InfoFile
+		}
+		return due[i].Line < due[j].Line
+	})
+	return due, nil
+}
+
+// defaultReviewer is the git user.name of the repository holding absRoot, else $USER
+func defaultReviewer(absRoot string) string {
+	if repoRoot := gitplugin.RepositoryRoot(appFs, absRoot); repoRoot != "" {
+		if name := gitplugin.UserName(appFs, repoRoot); name != "" {
+			return name
+		}
+	}
+	return os.Getenv("USER")
+}
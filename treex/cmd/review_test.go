@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withReviewProject points appFs at a project with a fresh, a stale and an unreviewed annotation
+func withReviewProject(t *testing.T) afero.Fs {
+	t.Helper()
+
+	return withAppFs(t, "/project", map[string]interface{}{
+		".info": "# treex:reviewed ada 2026-10-01\nREADME.md  Overview\n" +
+			"# treex:reviewed bob 2025-01-02\nmain.go  Entry point\nMakefile  Build\n",
+		"README.md": "# project",
+		"main.go":   "package main",
+		"Makefile":  "all:",
+	}, func() {
+		reviewDays, reviewCommits = 180, 0
+		reviewMark, reviewBy, reviewJSON = false, "", false
+	})
+}
+
+func TestReviewLists(t *testing.T) {
+	withReviewProject(t)
+	now := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	var out bytes.Buffer
+	require.NoError(t, runReview(&out, "/project", now))
+	assert.Equal(t, ".info:4  main.go  (reviewed 2025-01-02 by bob)\n.info:5  Makefile  (never reviewed)\n2 annotations due for review\n", out.String())
+
+	out.Reset()
+	reviewDays = 0
+	require.NoError(t, runReview(&out, "/project", now))
+	assert.Equal(t, ".info:5  Makefile  (never reviewed)\n1 annotations due for review\n", out.String())
+
+	reviewCommits = 1
+	assert.Error(t, runReview(&out, "/project", now), "--commits needs a repository")
+}
+
+func TestReviewMark(t *testing.T) {
+	fs := withReviewProject(t)
+	reviewMark, reviewBy = true, "carol"
+
+	var out bytes.Buffer
+	require.NoError(t, runReview(&out, "/project", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "Marked 2 annotations reviewed\n  main.go\n  Makefile\n", out.String())
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "# treex:reviewed ada 2026-10-01\nREADME.md  Overview\n"+
+		"# treex:reviewed carol 2026-10-15\nmain.go  Entry point\n# treex:reviewed carol 2026-10-15\nMakefile  Build\n", string(content))
+
+	out.Reset()
+	require.NoError(t, runReview(&out, "/project", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "No annotations due for review\n", out.String())
+}
@@ -3,28 +3,57 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"treex/treex"
+	"treex/treex/archive"
+	projectconfig "treex/treex/config"
+	"treex/treex/display"
+	"treex/treex/expand"
+	"treex/treex/i18n"
 	"treex/treex/logging"
+	"treex/treex/pathutil"
+	"treex/treex/pattern"
 	"treex/treex/plugins"
+	gitplugin "treex/treex/plugins/git" // Also registers the git plugin
+	"treex/treex/plugins/infofile"      // Also registers the info plugin
+	_ "treex/treex/plugins/kind"        // Registers the kind plugin
+	"treex/treex/query"
 	"treex/treex/rendering"
+	"treex/treex/secrets"
+	"treex/treex/treeconstruction"
 	"treex/treex/types"
-
-	// Import plugins to trigger registration
-	_ "treex/treex/plugins/git"
-	_ "treex/treex/plugins/infofile"
 )
 
 var (
 	// Basic options
 	maxLevel    int
-	showVersion bool // Show version and exit
-	verbosity   int  // Verbosity level for logging
+	showVersion bool   // Show version and exit
+	verbosity   int    // Verbosity level for logging
+	quiet       bool   // Report errors only (-q)
+	logLevel    string // --log-level: overrides -q and -v when set
+	logFormat   string // --log-format: text or json
+	lang        string // --lang: language of help, summaries and warnings (empty = environment)
 
 	// Path filtering options (added incrementally)
 	// Multiple exclusion mechanisms work together:
@@ -34,18 +63,69 @@ var (
 	// 4. Hidden files (--hidden flag control)
 	// 5. Plugin filters (--<plugin>-<category> flags, dynamically generated)
 	noBuiltinIgnores bool     // Disable built-in ignore patterns
+	presetNames      []string // --preset: language ignore sets, "auto" (detected from lockfiles) or "none"
 	excludeGlobs     []string // User-specified exclude patterns
 	includeHidden    bool     // Include hidden files
+	hiddenOnly       bool     // Show only hidden files
 	directoriesOnly  bool     // Show directories only
+	filesOnly        bool     // Show files and the directories leading to them
+	projectsOnly     bool     // --projects-only: show only subproject roots and the directories leading to them
+	pathsFromStdin   bool     // --stdin: show only the paths listed on stdin
+	annotationQuery  string   // --filter-annotation: regular expression annotations must match
+	selectQuery      string   // --select: selector entries must match (see query.ParseSelector)
+	noExpand         bool     // --no-expand: show ${NAME} and {{.Field}} placeholders as written
+	showSecrets      bool     // --secrets: decrypt annotations marked @secret
+	workspaceFile    string   // --workspace: file listing the repositories to show together
+
+	// Output options
+	themeSelection  string // --theme value: auto, dark, light or a theme name
+	largeFiles      string // --large-files value: a size, or "0" (empty = the theme's)
+	colorScheme     string // --color-scheme value: theme or ls-colors
+	heatMode        string // --heat value: churn or age (empty = no heat colors)
+	charsetName     string // --charset value: auto, unicode, ascii, rounded or double
+	annotationWidth int    // Truncate annotations to this width (0 = wrap at terminal width)
+	notesLayout     string // --annotation-layout value: aligned, inline or below (empty = .treex.toml or aligned)
+	minNameWidth    int    // Cut names wider than the terminal down to this width (0 = never)
+	flattenDirs     bool   // Show single-directory chains as one entry when the tree is too wide
+	iconSetName     string // --icons value: none, nerd or emoji
+	longListing     bool   // Show permissions, owner and group columns
+	showErrors      bool   // Mark directories that could not be read
+	noSummary       bool   // --no-summary: omit the directory and file counts after the tree
+	noWarnings      bool   // --no-warnings: omit the annotations not shown from terminal output
+	showStats       bool   // --stats: print build statistics and timing after the tree
+	collapseDepth   int    // Summarize directory contents below this depth (0 = off)
+	maxFiles        string // --max-files value: a number or "all"
+	sortOrder       string // --sort value: name, natural or locale (empty = .treex.toml or name)
+	outputFormat    string // --format value: term, plain, json, flat, markdown, dot or plantuml
+	rankDir         string // Graphviz rankdir for --format dot
+	plantUMLStyle   string // Diagram style for --format plantuml: component or salt
+	flatOrder       string // Line order for --format flat: tree, path or annotated
+	traversalOrder  string // --traversal: dfs or bfs entry order of --format json and flat
+	hyperlinkMode   string // --hyperlinks value: auto, always or never
+	pageLimit       int    // --limit: entries per page of data formats (0 = all)
+	pageOffset      int    // --offset: first entry of the page (a previous next_cursor)
+	remoteRef       string // --ref: branch, tag or commit of a repository URL
+	refreshRemote   bool   // --refresh: clone a repository URL again instead of using the cache
+
+	// tree(1) compatibility flags; -L, -a and -d share the variables of treex's own flags
+	ignorePatterns []string // -I patterns, each a "|"-separated list of globs
+	fullPaths      bool     // -f: print each entry with its full path
+	pruneEmpty     bool     // --prune: drop directories left without files
 
 	// Plugin filters (dynamically populated from registered plugins)
 	pluginFlags map[string]*bool // Map of flag name to flag value pointer
+
+	// appFs is the filesystem trees and user configuration (themes, icons) are read from (replaced in tests)
+	appFs afero.Fs = afero.NewOsFs()
+
+	// checkoutRemote fetches repository URLs given as the path (replaced in tests)
+	checkoutRemote = gitplugin.Checkout
 )
 
 // rootCmd represents the base command when called without any subcommands
 // According to cli-architecture.txt, "treex" should default to tree rendering
 var rootCmd = &cobra.Command{
-	Use:   "treex [path]",
+	Use:   "treex [path...]",
 	Short: "A modern tree command for displaying file hierarchies",
 	Long: `treex is a modernized version of the classic tree command that displays
 directory structures in a tree format.
@@ -55,23 +135,36 @@ You can specify a different path as an argument.`,
 	Example: `  treex                    # Show current directory tree
   treex /home/user/project # Show specific directory tree
   treex -l 2               # Limit depth to 2 levels
-  treex -d                 # Show directories only`,
-	Args: cobra.MaximumNArgs(1),
-	RunE: runTreeCommand,
+  treex -d                 # Show directories only
+  treex src docs           # Show several trees under one root`,
+	Args:              cobra.ArbitraryArgs,
+	PersistentPreRunE: initCommand,
+	RunE:              runTreeCommand,
 }
 
 // treeCmd represents the explicit tree command
 // This provides "treex tree" as an explicit alternative to naked "treex"
 var treeCmd = &cobra.Command{
-	Use:   "tree [path]",
-	Short: "Display directory tree structure",
+	Use:     "tree [path...]",
+	Aliases: []string{"show"},
+	Short:   "Display directory tree structure",
 	Long: `Display directory tree structure in a hierarchical format.
 
 This is the explicit form of the default treex command.`,
 	Example: `  treex tree                    # Show current directory tree
   treex tree /path          # Show specific directory tree
-  treex tree -l 2           # Limit depth to 2 levels`,
-	Args: cobra.MaximumNArgs(1),
+  treex tree -l 2           # Limit depth to 2 levels
+  treex show https://github.com/org/repo@v1.2.0 # Show a remote repository
+  treex show ./release.tar.gz   # Show the contents of an archive
+  treex show --format json src docs # One JSON document listing both trees
+  git ls-files | treex show --stdin # Show only the files git tracks
+  fd -0 -e go | treex show --stdin  # NUL-separated lists work too
+  treex show --filter-annotation '(?i)deprecated' # Where are the deprecated areas?
+  treex show --select 'dir[name=internal] > file[annotated=false]' # What is left to annotate?
+  treex show --format json --limit 1000 --offset 2000 # Third page of a large tree
+  treex show --sort natural     # file2 before file10, like file managers
+  treex show --workspace        # The repositories of treex.workspace.yaml as one tree`,
+	Args: cobra.ArbitraryArgs,
 	RunE: runTreeCommand,
 }
 
@@ -84,12 +177,92 @@ func NewRootCommand() *cobra.Command {
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	err := rootCmd.Execute()
-	if err != nil {
-		os.Exit(1)
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(exitCode(err))
+	}
+}
+
+// Exit codes shared by every command, so scripts and CI can tell failures apart
+const (
+	exitUsage  = 1 // Invalid flags, arguments or input, and any failure not classified below
+	exitIssues = 2 // The command ran and found problems (check, lint, verify)
+	exitIO     = 3 // A file or directory could not be read or written
+)
+
+// exitError makes the process exit with code instead of exitUsage
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// issuesError marks err as problems found by the command (exitIssues)
+func issuesError(err error) error {
+	return &exitError{code: exitIssues, err: err}
+}
+
+// ioError marks err as a failure to read or write files (exitIO)
+func ioError(err error) error {
+	return &exitError{code: exitIO, err: err}
+}
+
+// exitCode returns the exit code for an error returned by a command: the code of an
+// exitError, exitIO for filesystem errors, else exitUsage
+func exitCode(err error) int {
+	var exit *exitError
+	var pathErr *fs.PathError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exit):
+		return exit.code
+	case errors.As(err, &pathErr), errors.Is(err, fs.ErrNotExist), errors.Is(err, fs.ErrPermission):
+		return exitIO
+	default:
+		return exitUsage
 	}
 }
 
+// initCommand selects the language and sets up logging before any command runs
+func initCommand(cmd *cobra.Command, args []string) error {
+	if err := initLanguage(); err != nil {
+		return err
+	}
+	return initLogging(cmd, args)
+}
+
+// initLogging makes slog log to stderr at the level set by -q, -v or --log-level, in the
+// --log-format format, before any command runs
+// Warnings are shown by default, -q leaves only errors and each -v shows more.
+func initLogging(cmd *cobra.Command, args []string) error {
+	level := verbosity
+	if quiet {
+		if verbosity > 0 {
+			return fmt.Errorf("--quiet and --verbose cannot be used together")
+		}
+		level = -1
+	}
+	config := logging.DefaultConfig()
+	config.ConsoleLevel = logging.VerbosityLevel(level)
+	config.Console = os.Stderr
+
+	var err error
+	if logLevel != "" {
+		if config.ConsoleLevel, err = logging.ParseLevel(logLevel); err != nil {
+			return err
+		}
+	}
+	if config.Format, err = logging.ParseFormat(logFormat); err != nil {
+		return err
+	}
+	if err := logging.InitDefault(config); err != nil {
+		return fmt.Errorf("failed to initialize logging: %w", err)
+	}
+	return nil
+}
+
 func init() {
 	// Initialize plugin flags map
 	pluginFlags = make(map[string]*bool)
@@ -111,21 +284,143 @@ func setupTreeFlags(cmd *cobra.Command) {
 		"Show version information")
 	cmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v",
 		"Increase verbosity (-v info, -vv debug, -vvv trace)")
+	cmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false,
+		"Suppress warnings; only errors are reported")
+	cmd.PersistentFlags().StringVar(&logLevel, "log-level", "",
+		"Log level on stderr: trace, debug, info, warn or error (overrides -q and -v)")
+	cmd.PersistentFlags().StringVar(&logFormat, "log-format", "text",
+		"Log format on stderr: text or json (one object per line, for automation)")
+	cmd.PersistentFlags().StringVar(&lang, "lang", "",
+		"Language of help, summaries and warnings, e.g. pt-BR (default from LC_ALL, LC_MESSAGES or LANG)")
 
 	// Path filtering options (added incrementally)
 	// Multiple exclusion mechanisms work together for comprehensive filtering
 	cmd.PersistentFlags().BoolVar(&noBuiltinIgnores, "no-builtin-ignores", false,
 		"Disable built-in ignore patterns (.git, node_modules, __pycache__, etc.)")
+	cmd.PersistentFlags().StringSliceVar(&presetNames, "preset", nil,
+		"Hide the build artifacts and dependencies of these languages: node, python, go, rust, jvm, auto (from lockfiles) or none (default auto)")
 	cmd.PersistentFlags().StringSliceVarP(&excludeGlobs, "exclude", "e", []string{},
 		"Exclude paths matching these glob patterns (can be used multiple times)")
 	cmd.PersistentFlags().BoolVarP(&includeHidden, "hidden", "h", true,
-		"Include hidden files and directories (default: true)")
+		"Include hidden files and directories; --hidden=false still shows annotated ones (default: true)")
+	cmd.PersistentFlags().BoolVar(&hiddenOnly, "hidden-only", false,
+		"Show only hidden files and the files inside hidden directories, to audit configuration files")
 	cmd.PersistentFlags().BoolVarP(&directoriesOnly, "directory", "d", false,
 		"Show directories only")
+	cmd.PersistentFlags().BoolVar(&directoriesOnly, "dirs-only", false,
+		"Show the directory skeleton only, with its annotations (same as -d)")
+	cmd.PersistentFlags().BoolVar(&filesOnly, "files-only", false,
+		"Show files only, with just the directories that lead to them")
+	cmd.PersistentFlags().BoolVar(&projectsOnly, "projects-only", false,
+		"Show only subproject roots (directories with go.mod, package.json, Cargo.toml or pom.xml), with their annotations")
+
+	// tree(1) compatibility: the flags its users type from muscle memory
+	// Local flags, so subcommands keep these shorthands for their own options
+	cmd.Flags().IntVarP(&maxLevel, "max-depth", "L", 0,
+		"Same as --level (tree -L)")
+	cmd.Flags().BoolVarP(&includeHidden, "all", "a", true,
+		"Include hidden files, same as --hidden (tree -a)")
+	cmd.Flags().StringArrayVarP(&ignorePatterns, "ignore-pattern", "I", nil,
+		"Exclude paths matching these globs, separated by | (tree -I)")
+	cmd.Flags().BoolVarP(&fullPaths, "full-path", "f", false,
+		"Print each entry with its full path, starting with the root as given (tree -f)")
+	cmd.Flags().BoolVar(&pruneEmpty, "prune", false,
+		"Remove directories that are empty once filters apply (tree --prune)")
+
+	// Output options
+	cmd.PersistentFlags().StringVar(&themeSelection, "theme", "",
+		"Color theme: auto, dark, light or a theme name (env: "+rendering.ThemeEnvVar+")")
+	cmd.PersistentFlags().StringVar(&largeFiles, "large-files", "",
+		"Highlight files at least this size, e.g. 10MB (0 = off; default from the theme)")
+	cmd.PersistentFlags().StringVar(&colorScheme, "color-scheme", "theme",
+		"Where file name colors come from: theme, or ls-colors to match ls ($LS_COLORS)")
+	cmd.PersistentFlags().StringVar(&heatMode, "heat", "",
+		"Color names by their git history: churn (most changed are hottest) or age (most recent are hottest)")
+	cmd.PersistentFlags().BoolVar(&noPager, "no-pager", false,
+		"Print long output directly instead of through $TREEX_PAGER or $PAGER (default less -R)")
+	cmd.PersistentFlags().BoolVar(&noSummary, "no-summary", false,
+		"Omit the footer counting directories, files and annotated entries after the tree")
+	cmd.PersistentFlags().BoolVar(&noWarnings, "no-warnings", false,
+		"Omit the list of .info entries whose annotation is not shown (printed on terminals)")
+	// Options only the tree output reads are local flags, rejected by subcommands
+	cmd.Flags().BoolVar(&showStats, "stats", false,
+		"Print build statistics and time after the tree (a \"timing\" object with --format json)")
+	cmd.Flags().StringVar(&hyperlinkMode, "hyperlinks", "auto",
+		"Link entry names in the terminal (OSC 8): auto (terminals known to support it), always or never; .treex.toml link-template sets the URL")
+	cmd.PersistentFlags().StringVar(&charsetName, "charset", "auto",
+		"Tree connector glyphs: auto, unicode, ascii, rounded or double (auto uses ASCII without a UTF-8 locale)")
+	cmd.PersistentFlags().IntVar(&annotationWidth, "annotation-width", 0,
+		"Truncate annotations to this many columns with an ellipsis (0 = wrap at terminal width)")
+	cmd.PersistentFlags().StringVar(&notesLayout, "annotation-layout", "",
+		"Where annotations go: aligned (a shared column), inline (after each entry) or below (on their own line) (default from .treex.toml, else aligned)")
+	cmd.PersistentFlags().IntVar(&minNameWidth, "min-name-width", 12,
+		"Cut names that overflow the terminal with an ellipsis, keeping at least this many columns (0 = never cut)")
+	cmd.PersistentFlags().BoolVar(&flattenDirs, "flatten-dirs", false,
+		"When the tree is wider than the terminal, show directories holding a single directory as one entry (a/b/c)")
+	cmd.PersistentFlags().StringVar(&iconSetName, "icons", "none",
+		"File-type icons: none, nerd or emoji (overrides in ~/.config/treex/icons.yaml)")
+	cmd.PersistentFlags().BoolVar(&longListing, "long", false,
+		"Show permissions, owner and group before each entry (-l is --level)")
+	cmd.PersistentFlags().BoolVar(&showErrors, "show-errors", false,
+		"Mark directories that could not be read (e.g. [permission denied]) and list them under \"warnings\" in JSON")
+	cmd.PersistentFlags().IntVar(&collapseDepth, "collapse-dirs", 0,
+		"Show entries down to depth n; deeper directories become one line with file and annotation counts")
+	cmd.PersistentFlags().StringVar(&maxFiles, "max-files", "all",
+		"Maximum files listed per directory, or \"all\" (hidden files are counted on an indicator line)")
+
+	cmd.PersistentFlags().StringVar(&sortOrder, "sort", "",
+		"Order of entries: name (byte-wise), natural (file2 before file10, ignoring case) or locale (default from .treex.toml, else name)")
+	cmd.PersistentFlags().StringVar(&outputFormat, "format", string(rendering.FormatTerm),
+		"Output format: term, plain, json, flat (one path per line), markdown, dot (Graphviz) or plantuml; $TREEX_FORMAT changes the default")
+	cmd.PersistentFlags().BoolVar(&porcelainOutput, "porcelain", false,
+		"Output for scripts: json when piped, plain text on a terminal (--format takes precedence)")
+	// Format options, local for the same reason
+	cmd.Flags().StringVar(&rankDir, "rankdir", "LR",
+		"With --format dot, the direction the tree grows in: LR, TB, RL or BT")
+	cmd.Flags().StringVar(&plantUMLStyle, "plantuml", "component",
+		"With --format plantuml, the diagram: component (annotated top-level directories) or salt (the whole tree)")
+	cmd.Flags().StringVar(&flatOrder, "flat-order", "tree",
+		"With --format flat, the line order: tree, path or annotated (annotated paths first)")
+	cmd.Flags().StringVar(&traversalOrder, "traversal", "dfs",
+		"With --format json or flat, the entry order: dfs (as the tree is printed) or bfs (level by level; JSON is then listed flat)")
+	cmd.Flags().IntVar(&pageLimit, "limit", 0,
+		"With --format json, list at most this many entries, flat, with a next_cursor for the next page (0 = all)")
+	cmd.Flags().IntVar(&pageOffset, "offset", 0,
+		"With --format json, start the page at this entry (the next_cursor of the previous page)")
+
+	cmd.PersistentFlags().BoolVar(&pathsFromStdin, "stdin", false,
+		"Show only the paths listed on stdin (newline or NUL separated) and their parent directories")
+
+	cmd.PersistentFlags().StringVar(&annotationQuery, "filter-annotation", "",
+		"Show only entries whose annotation matches this regular expression, and their parent directories")
+	cmd.PersistentFlags().StringVar(&selectQuery, "select", "",
+		"Show only entries matching this selector, e.g. 'dir[name=src] > file[ext=go]' (see treex query --help), and their parent directories")
+
+	cmd.PersistentFlags().BoolVar(&noExpand, "no-expand", false,
+		"Show placeholders in annotations as written instead of expanding them")
+	cmd.PersistentFlags().BoolVar(&showSecrets, "secrets", false,
+		"Decrypt annotations marked @secret with the age key in TREEX_AGE_KEY_FILE or SOPS_AGE_KEY_FILE")
+
+	cmd.PersistentFlags().StringVar(&workspaceFile, "workspace", "",
+		"Show, check or count the repositories a workspace file lists (--workspace=<file>; default treex.workspace.yaml)")
+	cmd.PersistentFlags().Lookup("workspace").NoOptDefVal = projectconfig.WorkspaceFileName
+
+	// Remote repositories (path given as a git URL)
+	cmd.PersistentFlags().StringVar(&remoteRef, "ref", "",
+		"Branch, tag or commit to show when the path is a repository URL (or append @ref to the URL)")
+	cmd.PersistentFlags().BoolVar(&refreshRemote, "refresh", false,
+		"Clone a repository URL again instead of reusing the cached clone")
 
 	// Override default help flag to avoid conflict with our -h flag
 	cmd.PersistentFlags().Bool("help", false, "help for treex")
+	cmd.SetUsageTemplate(usageTemplate)
 	cmd.SetHelpFunc(func(command *cobra.Command, strings []string) {
+		// Help runs without PersistentPreRunE, so the language is chosen here
+		if err := initLanguage(); err != nil {
+			command.PrintErrln("Error:", err)
+		}
+		defer localizeHelp(command)()
+
 		// Use the default help template but with long-form help flag only
 		command.Print(command.UsageString())
 	})
@@ -157,63 +452,254 @@ func setupPluginFlags(cmd *cobra.Command) {
 // runTreeCommand executes the tree command with the provided arguments and flags
 // This is the core CLI logic that both "treex" and "treex tree" use
 func runTreeCommand(cmd *cobra.Command, args []string) error {
-	// Initialize logging based on verbosity level
-	if err := logging.InitGlobalFromVerbosity(verbosity); err != nil {
-		return fmt.Errorf("failed to initialize logging: %w", err)
-	}
-
 	// Check for version flag first - print and exit if requested
 	if showVersion {
 		fmt.Printf("treex version %s (commit %s, built %s)\n", Version, Commit, BuildDate)
 		return nil
 	}
 
-	// Determine root path
-	rootPath := "."
-	if len(args) > 0 {
-		rootPath = args[0]
+	// Determine root paths; several are shown under one synthetic root
+	rootPaths := args
+	if len(rootPaths) == 0 {
+		rootPaths = []string{"."}
 	}
+	rootNames, label := rootPaths, fmt.Sprintf("%d roots", len(rootPaths))
 
-	// Convert to absolute path for consistent handling
-	absRoot, err := filepath.Abs(rootPath)
+	format, noColor, err := selectFormat(outputFormat, cmd.Flags().Changed("format"), porcelainOutput, formatContext{
+		getenv: os.Getenv,
+		piped:  !isTerminal(os.Stdout),
+		parent: parentProcessName(),
+	})
 	if err != nil {
-		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+		return err
 	}
 
-	// Verify the root path exists
-	if _, err := os.Stat(absRoot); err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("path does not exist: %s", rootPath)
+	// With --workspace, the roots are the repositories the workspace file lists
+	if workspaceFile != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("--workspace cannot be combined with paths")
+		}
+		if label, rootPaths, rootNames, err = loadWorkspace(); err != nil {
+			return err
 		}
-		return fmt.Errorf("cannot access path %q: %w", rootPath, err)
 	}
 
-	// Build tree configuration from command-line flags
-	config := buildTreeConfig(absRoot)
-
-	// Call core API to build the tree
-	result, err := treex.BuildTree(config)
+	if directoriesOnly && filesOnly {
+		return fmt.Errorf("--dirs-only and --files-only cannot be used together")
+	}
+	if heatMode != "" {
+		if _, err := treex.ParseHeatMode(heatMode); err != nil {
+			return fmt.Errorf("invalid --heat: %w", err)
+		}
+	}
+	if err := checkPresetFlag(); err != nil {
+		return err
+	}
+	if projectsOnly && filesOnly {
+		return fmt.Errorf("--projects-only and --files-only cannot be used together")
+	}
+	if err := validatePaging(format, pageLimit, pageOffset); err != nil {
+		return err
+	}
+	if _, err := treeconstruction.ParseSortOrder(sortOrder); err != nil {
+		return err
+	}
+	if _, err := rendering.ParseRankDir(rankDir); err != nil {
+		return err
+	}
+	if _, err := rendering.ParsePlantUMLStyle(plantUMLStyle); err != nil {
+		return err
+	}
+	if _, err := rendering.ParseFlatOrder(flatOrder); err != nil {
+		return err
+	}
+	traversal, err := parseTraversal(format, traversalOrder)
 	if err != nil {
-		return fmt.Errorf("failed to build tree: %w", err)
+		return err
+	}
+	hyperlinks, err := rendering.ParseHyperlinkMode(hyperlinkMode)
+	if err != nil {
+		return err
+	}
+
+	// With --stdin, the tree only holds the listed paths
+	var listedPaths []string
+	if pathsFromStdin {
+		if len(rootPaths) > 1 {
+			return fmt.Errorf("--stdin takes a single root path")
+		}
+		if listedPaths, err = readPathList(cmd.InOrStdin()); err != nil {
+			return err
+		}
+	}
+
+	// With --filter-annotation, the tree is pruned to matching annotations
+	var annotationFilter *regexp.Regexp
+	if annotationQuery != "" {
+		if annotationFilter, err = regexp.Compile(annotationQuery); err != nil {
+			return fmt.Errorf("invalid --filter-annotation pattern: %w", err)
+		}
+	}
+
+	// With --select, the tree is pruned to the entries the selector matches
+	var selector query.Query
+	if selectQuery != "" {
+		if selector, err = query.ParseSelector(selectQuery); err != nil {
+			return fmt.Errorf("invalid --select: %w", err)
+		}
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// Unchanged .info files are not parsed again (see infofile.ParseCache and cacheInfoFiles)
+	saveInfoCache := useInfoCache(rootPaths)
+	defer saveInfoCache()
+
+	// Build each tree; Ctrl-C stops the walk and shows what was collected
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	var results []*treex.TreeResult
+	var buildErr error
+	links := make(map[*types.Node]linkBase)
+	for _, rootPath := range rootPaths {
+		result, err := buildRootTree(ctx, rootPath, listedPaths, annotationFilter, selector, links)
+		if err != nil && (result == nil || !result.Partial) {
+			stop()
+			return err
+		}
+		results = append(results, result)
+		if err != nil {
+			buildErr = err
+			break
+		}
+	}
+	stop() // A second Ctrl-C while rendering exits immediately
+
+	result := results[0]
+	if len(rootPaths) > 1 || workspaceFile != "" {
+		result = treex.CombineResults(label, rootNames[:len(results)], results)
+	} else if fullPaths && result.Root != nil {
+		// Full paths start with the root as given, as tree -f prints them
+		result.Root.Name = rootPaths[0]
 	}
 
 	// Handle empty results
 	if result.Root == nil {
-		fmt.Fprintf(os.Stderr, "No files found\n")
+		fmt.Fprintln(os.Stderr, i18n.T("No files found"))
 		return nil
 	}
 
 	// Auto-detect if any .info files are found and enable ShowNotes
 	showNotes := hasInfoFiles(result)
 
+	// Resolve theme and background palette (--theme overrides TREEX_THEME)
+	theme, background, err := resolveThemeSelection(themeSelection, os.Getenv(rendering.ThemeEnvVar))
+	if err != nil {
+		return err
+	}
+
+	// File classes color names by extension group; --large-files overrides the theme
+	fileClasses, err := resolveColorRules(theme, largeFiles)
+	if err != nil {
+		return err
+	}
+
+	// --color-scheme ls-colors colors names from LS_COLORS instead
+	lsColors, err := resolveLSColors(colorScheme, os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	// --heat colors names by the git history of the repositories holding the roots
+	heat, err := resolveHeat(context.Background(), treex.HeatMode(heatMode), results, links, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// Resolve connector glyphs (auto picks ASCII when the locale lacks UTF-8)
+	charset, err := rendering.ParseCharset(charsetName)
+	if err != nil {
+		return err
+	}
+
+	// Resolve the per-directory file limit
+	maxFilesPerDir, err := parseMaxFiles(maxFiles)
+	if err != nil {
+		return err
+	}
+
+	// Resolve file-type icons including user overrides
+	icons, err := resolveIcons(iconSetName)
+	if err != nil {
+		return err
+	}
+
+	// Plugin badges and the annotation layout default to the first root's .treex.toml
+	project, err := firstProjectConfig(results, links)
+	if err != nil {
+		return err
+	}
+	badges, err := resolveBadges(project.Badges)
+	if err != nil {
+		return err
+	}
+	layoutValue := notesLayout
+	if layoutValue == "" {
+		layoutValue = project.AnnotationLayout
+	}
+	placement, err := rendering.ParseAnnotationLayout(layoutValue)
+	if err != nil {
+		return err
+	}
+
+	// On a terminal, .info entries the tree cannot show are listed after it
+	var dropped []infofile.Issue
+	if !noWarnings && !result.Partial && isTerminal(os.Stdout) {
+		dropped = droppedAnnotations(rootNames, results, links)
+	}
+
+	// Output taller than the terminal is shown through the pager
+	out := newPager(cmd.OutOrStdout(), systemPagerEnv())
+
 	// Configure renderer with basic terminal output (no fancy formats for now)
 	renderer := rendering.NewRenderer(rendering.RenderConfig{
-		Format:     rendering.FormatTerm,
-		Writer:     os.Stdout,
-		AutoDetect: false,
-		NoColor:    false,
-		ShowStats:  false,
-		ShowNotes:  showNotes,
+		Format:          format,
+		Writer:          out,
+		Terminal:        os.Stdout,
+		AutoDetect:      false,
+		NoColor:         noColor,
+		ShowStats:       showStats,
+		Summary:         !noSummary,
+		ShowNotes:       showNotes,
+		Theme:           theme,
+		Background:      background,
+		Charset:         charset,
+		AnnotationWidth: annotationWidth,
+		MinNameWidth:    minNameWidth,
+		FlattenDirs:     flattenDirs,
+		Icons:           icons,
+		Long:            longListing,
+		ShowErrors:      showErrors,
+		CollapseDepth:   effectiveCollapseDepth(),
+		MaxFilesPerDir:  maxFilesPerDir,
+		Limit:           pageLimit,
+		Offset:          pageOffset,
+		RankDir:         rankDir,
+		PlantUMLStyle:   plantUMLStyle,
+		FlatOrder:       flatOrder,
+		Traversal:       traversal,
+		Hyperlinks:      hyperlinks,
+		LinkTarget:      linkTarget(links),
+		FullPaths:       fullPaths,
+		FileClasses:     fileClasses,
+		LSColors:        lsColors,
+		Heat:            heat,
+		Badges:          badges,
+
+		AnnotationLayout:   placement,
+		DroppedAnnotations: dropped,
 	})
 
 	// Render the tree
@@ -221,26 +707,454 @@ func runTreeCommand(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to render tree: %w", err)
 	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+
+	if result.Partial {
+		return fmt.Errorf("interrupted, the tree above is incomplete: %w", buildErr)
+	}
+	return nil
+}
+
+// buildRootTree builds the tree of one root path: a directory, an archive or a repository URL
+// When listedPaths is not nil, only those paths (relative to the root or absolute) are shown;
+// when annotationFilter is not nil, only the entries whose annotation matches it, and when
+// selector is not nil, only the entries it matches.
+// The hyperlink base of the root is recorded in links, except for archives.
+// A canceled build returns the partial result together with the error.
+func buildRootTree(ctx context.Context, rootPath string, listedPaths []string, annotationFilter *regexp.Regexp, selector query.Query, links map[*types.Node]linkBase) (*treex.TreeResult, error) {
+	// Repository URLs are cloned into the cache and rendered from there
+	_, remote := gitplugin.ParseRemote(rootPath)
+	rootPath, err := resolveRemoteRoot(ctx, rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert to absolute path for consistent handling
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+
+	// Verify the root path exists
+	rootInfo, err := appFs.Stat(absRoot)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ioError(fmt.Errorf("path does not exist: %s", rootPath))
+		}
+		return nil, ioError(fmt.Errorf("cannot access path %q: %w", rootPath, err))
+	}
+
+	// Build tree configuration from command-line flags
+	config := buildTreeConfig(absRoot)
+	config.AnnotationFilter = annotationFilter
+	config.Select = selector
+	if listedPaths != nil {
+		if config.Paths, err = relativePathList(absRoot, listedPaths); err != nil {
+			return nil, err
+		}
+	}
+
+	// Archives are loaded into memory and shown from their root
+	isArchive := !rootInfo.IsDir() && archive.IsArchive(absRoot)
+	if isArchive {
+		archiveFs, err := archive.Open(appFs, absRoot)
+		if err != nil {
+			return nil, err
+		}
+		config.Root, config.Filesystem = archive.Root, archiveFs
+	}
+
+	// Per-directory depth limits from .treex.toml
+	project, err := projectconfig.Load(config.Filesystem, config.Root)
+	if err != nil {
+		return nil, err
+	}
+	config.DepthOverrides = project.Depth
+
+	// Entry order: --sort, else the project's default
+	sortValue := sortOrder
+	if sortValue == "" {
+		sortValue = project.Sort
+	}
+	if config.Sort, err = treeconstruction.ParseSortOrder(sortValue); err != nil {
+		return nil, err
+	}
+	config.Locale = treeconstruction.LocaleFromEnv(os.Getenv)
+
+	// Annotation placeholders: ${NAME} from TREEX_VAR_ variables or [variables], {{.Field}}
+	// Trees from elsewhere are shown as written, whatever their .info files ask for
+	if !noExpand && !remote && !isArchive {
+		config.Expand = annotationValues(config.Filesystem, config.Root, project.Variables)
+	}
+	if showSecrets {
+		if identity := secrets.IdentityFile(os.Getenv); identity != "" {
+			config.Secrets = secrets.AgeDecrypter(identity)
+		} else {
+			slog.Warn("--secrets needs an age key file in TREEX_AGE_KEY_FILE or SOPS_AGE_KEY_FILE; secret annotations stay redacted")
+		}
+	}
+
+	// Show a spinner on long scans, only when both output streams are terminals
+	if isTerminal(os.Stdout) && isTerminal(os.Stderr) {
+		config.Progress = display.NewSpinner(os.Stderr, display.ProgressDelay)
+	}
+
+	stopCaching := cacheInfoFiles(config.Filesystem)
+	result, err := treex.BuildTreeContext(ctx, config)
+	stopCaching()
+	if err != nil && (result == nil || !result.Partial) {
+		return nil, ioError(fmt.Errorf("failed to build tree: %w", err))
+	}
+	if result.Root != nil && config.Root == absRoot {
+		links[result.Root] = linkBase{absRoot: absRoot, template: project.LinkTemplate}
+	}
+	return result, err
+}
+
+// annotationValues returns what annotation placeholders of the tree at root expand to
+// The repository is only read when an annotation names one of its fields.
+func annotationValues(fs afero.Fs, root string, variables map[string]string) *expand.Values {
+	return &expand.Values{
+		Getenv:    os.Getenv,
+		Variables: variables,
+		Now:       time.Now(),
+		Git: func() (string, string) {
+			repoRoot := gitplugin.RepositoryRoot(fs, root)
+			if repoRoot == "" {
+				return "", ""
+			}
+			branch, commit, err := gitplugin.Head(fs, repoRoot)
+			if err != nil {
+				slog.Debug("leaving git placeholders unexpanded", "root", root, "error", err)
+			}
+			return branch, commit
+		},
+	}
+}
 
+// droppedAnnotations returns the .info entries of each directory root that the tree
+// cannot show (see infofile.Dropped); with several roots, paths start with the root's name
+// Roots whose .info files cannot be validated are left out.
+func droppedAnnotations(rootNames []string, results []*treex.TreeResult, links map[*types.Node]linkBase) []infofile.Issue {
+	var dropped []infofile.Issue
+	for i, result := range results {
+		base, ok := links[result.Root]
+		if !ok {
+			continue // Archives have no .info files on disk
+		}
+		issues, err := infofile.Dropped(appFs, base.absRoot)
+		if err != nil {
+			slog.Debug("skipping dropped annotations", "root", rootNames[i], "error", err)
+			continue
+		}
+		for _, issue := range issues {
+			if len(results) > 1 {
+				issue.InfoFile = rootNames[i] + "/" + issue.InfoFile
+				issue.Path = rootNames[i] + "/" + issue.Path
+			}
+			dropped = append(dropped, issue)
+		}
+	}
+	return dropped
+}
+
+// firstProjectConfig returns the .treex.toml of the first directory root, which sets
+// the output options shared by all roots (archives have none: the zero Config)
+func firstProjectConfig(results []*treex.TreeResult, links map[*types.Node]linkBase) (*projectconfig.Config, error) {
+	for _, result := range results {
+		if base, ok := links[result.Root]; ok {
+			return projectconfig.Load(appFs, base.absRoot)
+		}
+	}
+	return &projectconfig.Config{}, nil
+}
+
+// resolveBadges returns the badge plugins to show, as the [badges] table selects them
+func resolveBadges(settings projectconfig.Badges) ([]rendering.BadgeSource, error) {
+	badgePlugins, err := plugins.GetDefaultRegistry().BadgePlugins(settings.Order, settings.Disabled)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s badges: %w", projectconfig.FileName, err)
+	}
+	badges := make([]rendering.BadgeSource, len(badgePlugins))
+	for i, plugin := range badgePlugins {
+		badges[i] = plugin
+	}
+	return badges, nil
+}
+
+// linkBase is where the entries of one root link to
+type linkBase struct {
+	absRoot  string
+	template string // .treex.toml link-template ("" = file:// URLs)
+}
+
+// linkTarget returns the hyperlink target of an entry, built from the root it belongs to
+func linkTarget(links map[*types.Node]linkBase) func(node *types.Node) string {
+	return func(node *types.Node) string {
+		for root := node; root != nil; root = root.Parent {
+			if base, ok := links[root]; ok {
+				return rendering.LinkURL(base.template, base.absRoot, filepath.ToSlash(node.Path))
+			}
+		}
+		return ""
+	}
+}
+
+// readPathList reads the paths given to --stdin: NUL-separated when the input contains a
+// NUL byte (find -print0, fd -0), one per line otherwise
+func readPathList(in io.Reader) ([]string, error) {
+	content, err := io.ReadAll(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read paths from stdin: %w", err)
+	}
+
+	separator := "\n"
+	if bytes.IndexByte(content, 0) >= 0 {
+		separator = "\x00"
+	}
+
+	var paths []string
+	for _, entry := range strings.Split(string(content), separator) {
+		if separator == "\n" {
+			entry = strings.TrimSpace(entry)
+		}
+		if entry != "" {
+			paths = append(paths, entry)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no paths read from stdin")
+	}
+	return paths, nil
+}
+
+// relativePathList converts listed paths to slash-separated paths relative to absRoot,
+// rejecting paths outside it
+func relativePathList(absRoot string, paths []string) ([]string, error) {
+	relative := make([]string, 0, len(paths))
+	for _, listed := range paths {
+		rel := listed
+		if filepath.IsAbs(listed) {
+			var err error
+			if rel, err = filepath.Rel(absRoot, listed); err != nil {
+				return nil, fmt.Errorf("path %q is outside %s", listed, absRoot)
+			}
+		}
+		rel = pathutil.Normalize(rel)
+		if rel == ".." || strings.HasPrefix(rel, "../") {
+			return nil, fmt.Errorf("path %q is outside %s", listed, absRoot)
+		}
+		if rel != "." {
+			relative = append(relative, rel)
+		}
+	}
+	return relative, nil
+}
+
+// parseOutputFormat validates the --format value: a built-in format or one registered
+// in renderers by a program embedding treex
+func parseOutputFormat(value string, renderers *rendering.RendererRegistry) (rendering.OutputFormat, error) {
+	for _, format := range builtinFormats {
+		if string(format) == value {
+			return format, nil
+		}
+	}
+	if renderers.Get(rendering.OutputFormat(value)) != nil {
+		return rendering.OutputFormat(value), nil
+	}
+	return "", fmt.Errorf("unknown format %q (valid: %s)", value, strings.Join(formatNames(renderers), ", "))
+}
+
+// builtinFormats are the formats --format accepts without a registered renderer
+var builtinFormats = []rendering.OutputFormat{
+	rendering.FormatTerm, rendering.FormatPlain, rendering.FormatJSON, rendering.FormatFlat,
+	rendering.FormatMarkdown, rendering.FormatDot, rendering.FormatPlantUML,
+}
+
+// formatNames lists the built-in formats, then the registered ones by name
+func formatNames(renderers *rendering.RendererRegistry) []string {
+	names := make([]string, 0, len(builtinFormats))
+	for _, format := range builtinFormats {
+		names = append(names, string(format))
+	}
+	for _, renderer := range renderers.Renderers() {
+		names = append(names, string(renderer.Format()))
+	}
+	return names
+}
+
+// validatePaging checks --limit and --offset, which only apply to data formats
+func validatePaging(format rendering.OutputFormat, limit, offset int) error {
+	if limit < 0 || offset < 0 {
+		return fmt.Errorf("--limit and --offset must not be negative")
+	}
+	if (limit > 0 || offset > 0) && format != rendering.FormatJSON {
+		return fmt.Errorf("--limit and --offset need --format json")
+	}
 	return nil
 }
 
+// parseTraversal validates --traversal: breadth-first order needs a data format
+func parseTraversal(format rendering.OutputFormat, value string) (rendering.Traversal, error) {
+	traversal, err := rendering.ParseTraversal(value)
+	if err != nil {
+		return "", err
+	}
+	if traversal == rendering.TraversalBFS && format != rendering.FormatJSON && format != rendering.FormatFlat {
+		return "", fmt.Errorf("--traversal bfs needs --format json or flat")
+	}
+	return traversal, nil
+}
+
+// isTerminal reports whether f is attached to a terminal
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(f.Fd())
+}
+
+// walkDepth returns the traversal depth limit
+// With --collapse-dirs the walk is always complete so that collapsed summaries count
+// everything below them; --level then only limits what is displayed
+func walkDepth() int {
+	if collapseDepth > 0 {
+		return 0
+	}
+	return maxLevel
+}
+
+// effectiveCollapseDepth combines --collapse-dirs with a display limit from --level
+func effectiveCollapseDepth() int {
+	if collapseDepth > 0 && maxLevel > 0 {
+		return min(collapseDepth, maxLevel)
+	}
+	return collapseDepth
+}
+
+// resolveColorRules returns the theme's file classes, with the --large-files threshold
+// when one is given
+func resolveColorRules(theme *rendering.Theme, largeFiles string) (*rendering.ColorRules, error) {
+	rules, err := theme.ColorRules()
+	if err != nil {
+		return nil, err
+	}
+	if largeFiles != "" {
+		size, err := rendering.ParseSize(largeFiles)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --large-files value: %w", err)
+		}
+		rules.LargeSize = size
+	}
+	return &rules, nil
+}
+
+// resolveLSColors returns the LS_COLORS database for the ls-colors scheme, or nil for
+// theme colors
+func resolveLSColors(value string, getenv func(string) string) (*rendering.LSColors, error) {
+	scheme, err := rendering.ParseColorScheme(value)
+	if err != nil || scheme != rendering.SchemeLSColors {
+		return nil, err
+	}
+	return rendering.ParseLSColors(getenv(rendering.LSColorsEnvVar)), nil
+}
+
+// resolveHeat returns the heat gradient steps of the entries of results for mode (empty =
+// no heat colors), each root scaled against its own entries with the history of the
+// repository holding it; archives and roots outside a repository stay uncolored
+func resolveHeat(ctx context.Context, mode treex.HeatMode, results []*treex.TreeResult, links map[*types.Node]linkBase, now time.Time) (map[*types.Node]int, error) {
+	if mode == "" {
+		return nil, nil
+	}
+	heat := make(map[*types.Node]int)
+	for _, result := range results {
+		base, ok := links[result.Root]
+		if !ok {
+			continue
+		}
+		entries, err := heatEntries(ctx, base.absRoot, result.Root, now)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(heat, treex.HeatScale(entries, mode))
+	}
+	return heat, nil
+}
+
+// heatEntries returns the git history of the entries of the tree below root, built from
+// absRoot; nil when absRoot is not inside a repository
+func heatEntries(ctx context.Context, absRoot string, root *types.Node, now time.Time) ([]treex.PathHeat, error) {
+	repoRoot := gitplugin.RepositoryRoot(appFs, absRoot)
+	if repoRoot == "" {
+		return nil, nil
+	}
+	churn, err := gitplugin.Churn(ctx, appFs, repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := filepath.Rel(repoRoot, absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s in %s: %w", absRoot, repoRoot, err)
+	}
+	return treex.AnalyzeHeat(root, churn, filepath.ToSlash(prefix), now), nil
+}
+
+// parseMaxFiles converts a --max-files value to a limit; "all" (or empty) means no limit (0)
+func parseMaxFiles(value string) (int, error) {
+	if value == "" || strings.EqualFold(value, "all") {
+		return 0, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("invalid --max-files value %q: expected a positive number or \"all\"", value)
+	}
+	return limit, nil
+}
+
+// resolveIcons builds the icon map for the --icons value, applying user overrides
+// Returns nil when icons are disabled
+func resolveIcons(value string) (*display.IconMap, error) {
+	set, err := display.ParseIconSet(value)
+	if err != nil {
+		return nil, err
+	}
+	if set == display.IconsNone {
+		return nil, nil
+	}
+
+	overrides, err := display.LoadIconOverrides(appFs, display.DefaultIconsFile())
+	if err != nil {
+		return nil, err
+	}
+	return display.NewIconMap(set, overrides), nil
+}
+
 // buildTreeConfig creates a TreeConfig from command-line flags using OptionsBuilder pattern
 // This bridges CLI flags to treex.TreeConfig via the platform-agnostic options system
 func buildTreeConfig(rootPath string) treex.TreeConfig {
 	// Use OptionsBuilder pattern for clean, platform-agnostic configuration
 	builder := types.NewOptionsBuilder().
 		WithRoot(rootPath).
-		WithMaxDepth(maxLevel).
-		WithExcludes(excludeGlobs...)
+		WithMaxDepth(walkDepth()).
+		WithExcludes(excludeGlobs...).
+		WithExcludes(splitIgnorePatterns(ignorePatterns)...)
 
-	// Apply boolean flags
+	// Apply boolean flags; hidden file visibility is always set explicitly, as the
+	// options default (hidden) differs from the CLI default (shown)
 	if includeHidden {
 		builder = builder.WithHidden()
+	} else {
+		builder = builder.WithoutHidden()
+	}
+	if hiddenOnly {
+		builder = builder.WithHiddenOnly()
 	}
 	if directoriesOnly {
 		builder = builder.WithDirsOnly()
 	}
+	if filesOnly {
+		builder = builder.WithFilesOnly()
+	}
 	if !noBuiltinIgnores {
 		builder = builder.WithBuiltinIgnores()
 	} else {
@@ -259,14 +1173,74 @@ func buildTreeConfig(rootPath string) treex.TreeConfig {
 	// Convert TreeOptions to treex.TreeConfig (avoiding circular imports)
 	return treex.TreeConfig{
 		Root:            options.Root,
-		Filesystem:      nil, // Will be set by caller if needed
+		Filesystem:      appFs,
 		MaxDepth:        options.Tree.MaxDepth,
 		BuiltinIgnores:  options.Patterns.UseBuiltinIgnores,
 		ExcludeGlobs:    options.Patterns.Excludes,
 		IncludeHidden:   options.Tree.ShowHidden,
+		HiddenOnly:      options.Tree.HiddenOnly,
 		DirectoriesOnly: options.Tree.DirsOnly,
+		FilesOnly:       options.Tree.FilesOnly,
 		PluginFilters:   options.Plugins.Filters,
+		Presets:         resolvePresets(rootPath),
+		PruneEmpty:      pruneEmpty,
+		ProjectsOnly:    projectsOnly,
+		CaseInsensitive: pathutil.DefaultCaseInsensitive(),
+	}
+}
+
+// checkPresetFlag rejects --preset values that name no preset
+func checkPresetFlag() error {
+	for _, name := range presetNames {
+		switch {
+		case name == "none" && len(presetNames) > 1:
+			return fmt.Errorf("--preset none cannot be combined with other presets")
+		case name == "none" || name == "auto":
+		default:
+			if err := pattern.CheckPresets([]string{name}); err != nil {
+				return fmt.Errorf("invalid --preset: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// resolvePresets returns the presets --preset selects for rootPath: "auto", the default
+// unless --no-builtin-ignores is set, adds the ones whose lockfiles are in rootPath
+func resolvePresets(rootPath string) []string {
+	names := presetNames
+	if len(names) == 0 {
+		if noBuiltinIgnores {
+			return nil
+		}
+		names = []string{"auto"}
+	}
+
+	var presets []string
+	for _, name := range names {
+		switch name {
+		case "none":
+			return nil
+		case "auto":
+			presets = append(presets, pattern.DetectPresets(appFs, rootPath)...)
+		default:
+			presets = append(presets, name)
+		}
+	}
+	return presets
+}
+
+// splitIgnorePatterns splits tree -I values, which list several globs separated by |
+func splitIgnorePatterns(values []string) []string {
+	var patterns []string
+	for _, value := range values {
+		for _, glob := range strings.Split(value, "|") {
+			if glob = strings.TrimSpace(glob); glob != "" {
+				patterns = append(patterns, glob)
+			}
+		}
 	}
+	return patterns
 }
 
 // parsePluginFlags converts plugin flag values to PluginFilters configuration
@@ -296,6 +1270,21 @@ func parsePluginFlags() map[string]map[string]bool {
 	return pluginFilters
 }
 
+// resolveRemoteRoot returns the cached clone for a repository URL, or rootPath unchanged
+func resolveRemoteRoot(ctx context.Context, rootPath string) (string, error) {
+	remote, isRemote := gitplugin.ParseRemote(rootPath)
+	if !isRemote {
+		if remoteRef != "" || refreshRemote {
+			return "", fmt.Errorf("--ref and --refresh need a repository URL, got %q", rootPath)
+		}
+		return rootPath, nil
+	}
+	if remoteRef != "" {
+		remote.Ref = remoteRef
+	}
+	return checkoutRemote(ctx, remote, gitplugin.DefaultCacheDir(), refreshRemote)
+}
+
 // hasInfoFiles checks if any .info files were found in the tree result
 // by looking for infofile plugin results or checking for nodes with annotations
 func hasInfoFiles(result *treex.TreeResult) bool {
@@ -333,23 +1322,67 @@ func hasAnnotatedNodes(node *types.Node) bool {
 	return false
 }
 
-// Version information (would be set at build time)
+// Version information, set at build time with -X treex/treex/cmd.Version=... (see .goreleaser.yml)
 var (
 	Version   = "dev"
 	Commit    = "unknown"
 	BuildDate = "unknown"
 )
 
+// versionJSON prints the build information as JSON
+var versionJSON bool
+
 // versionCmd represents the version command
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number of treex",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("treex version %s (commit %s, built %s)\n", Version, Commit, BuildDate)
+	Long: `Print the version number of treex. --json prints the build information for
+tools that manage installations: version, commit, build date, the Go version
+and platform it was built with, the plugins compiled in and the output formats
+--format accepts, including the x- formats an embedding program registered.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runVersion(cmd.OutOrStdout())
 	},
 }
 
+// versionInfo is what treex version --json prints
+type versionInfo struct {
+	Version   string   `json:"version"`
+	Commit    string   `json:"commit"`
+	BuildDate string   `json:"build_date"`
+	GoVersion string   `json:"go_version"`
+	Platform  string   `json:"platform"` // GOOS/GOARCH
+	Plugins   []string `json:"plugins"`  // Registered plugins, by name
+	Formats   []string `json:"formats"`  // Built-in formats, then registered ones by name
+}
+
+// runVersion prints the version line, or the build information as JSON
+func runVersion(out io.Writer) error {
+	if !versionJSON {
+		fmt.Fprintf(out, "treex version %s (commit %s, built %s)\n", Version, Commit, BuildDate)
+		return nil
+	}
+
+	pluginNames := plugins.GetDefaultRegistry().ListPlugins()
+	sort.Strings(pluginNames)
+	info := versionInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Plugins:   pluginNames,
+		Formats:   formatNames(rendering.DefaultRenderers),
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(info)
+}
+
 func init() {
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false,
+		"Print version, commit, build date, Go version, plugins and output formats as JSON")
 	rootCmd.AddCommand(versionCmd)
 
 	// Add completion commands
@@ -1,26 +1,64 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
 	"testing"
 
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
 	"treex/treex"
+	"treex/treex/expand"
+	"treex/treex/pathutil"
 	"treex/treex/plugins"
+	gitplugin "treex/treex/plugins/git"
+	"treex/treex/plugins/infofile"
+	"treex/treex/rendering"
 	"treex/treex/types"
 )
 
+// withAppFs points the commands at an in-memory filesystem holding tree below root for
+// the test, and runs reset (when set) at cleanup to restore the flags the test changed
+func withAppFs(t *testing.T, root string, tree map[string]interface{}, reset func()) *testutil.TestFS {
+	t.Helper()
+
+	fs := testutil.NewTestFS()
+	if tree != nil {
+		fs.MustCreateTree(root, tree)
+	}
+
+	originalFs := appFs
+	appFs = fs
+	t.Cleanup(func() {
+		appFs = originalFs
+		if reset != nil {
+			reset()
+		}
+	})
+	return fs
+}
+
 // defaultExpectedConfig returns a base TreeConfig with default values for testing
 func defaultExpectedConfig() treex.TreeConfig {
 	return treex.TreeConfig{
 		Root:            "/test/path",
-		Filesystem:      nil,
+		Filesystem:      appFs,
 		MaxDepth:        0,
 		BuiltinIgnores:  true, // Built-in ignores enabled by default
 		ExcludeGlobs:    []string{},
 		IncludeHidden:   true,
 		DirectoriesOnly: false,
 		PluginFilters:   make(map[string]map[string]bool), // Empty plugin filters by default
+		CaseInsensitive: pathutil.DefaultCaseInsensitive(),
 	}
 }
 
@@ -96,6 +134,18 @@ func TestBuildTreeConfigFromFlags(t *testing.T) {
 			args:   []string{"--hidden=true"},
 			modify: nil, // True is already the default
 		},
+		{
+			name:   "all flag after hidden disabled",
+			args:   []string{"-h=false", "-a"},
+			modify: nil, // -a is --hidden
+		},
+		{
+			name: "hidden only",
+			args: []string{"--hidden-only"},
+			modify: func(cfg *treex.TreeConfig) {
+				cfg.HiddenOnly = true
+			},
+		},
 		{
 			name: "directories only",
 			args: []string{"-d"},
@@ -110,6 +160,20 @@ func TestBuildTreeConfigFromFlags(t *testing.T) {
 				cfg.DirectoriesOnly = true
 			},
 		},
+		{
+			name: "dirs only",
+			args: []string{"--dirs-only"},
+			modify: func(cfg *treex.TreeConfig) {
+				cfg.DirectoriesOnly = true
+			},
+		},
+		{
+			name: "files only",
+			args: []string{"--files-only"},
+			modify: func(cfg *treex.TreeConfig) {
+				cfg.FilesOnly = true
+			},
+		},
 		{
 			name: "hidden files with level and exclude",
 			args: []string{"-l", "3", "-e", "*.tmp", "-h=false"},
@@ -165,7 +229,9 @@ func TestBuildTreeConfigFromFlags(t *testing.T) {
 			noBuiltinIgnores = false
 			excludeGlobs = []string{}
 			includeHidden = true
+			hiddenOnly = false
 			directoriesOnly = false
+			filesOnly = false
 
 			// Create a test command to parse flags
 			testCmd := &cobra.Command{
@@ -177,7 +243,11 @@ func TestBuildTreeConfigFromFlags(t *testing.T) {
 			testCmd.Flags().BoolVar(&noBuiltinIgnores, "no-builtin-ignores", false, "Disable built-in ignores")
 			testCmd.Flags().StringSliceVarP(&excludeGlobs, "exclude", "e", []string{}, "Exclude patterns")
 			testCmd.Flags().BoolVarP(&includeHidden, "hidden", "h", true, "Include hidden files")
+			testCmd.Flags().BoolVarP(&includeHidden, "all", "a", true, "Include hidden files")
+			testCmd.Flags().BoolVar(&hiddenOnly, "hidden-only", false, "Show only hidden files")
 			testCmd.Flags().BoolVarP(&directoriesOnly, "directory", "d", false, "Show directories only")
+			testCmd.Flags().BoolVar(&directoriesOnly, "dirs-only", false, "Show directories only")
+			testCmd.Flags().BoolVar(&filesOnly, "files-only", false, "Show files only")
 
 			// Override help flag without shorthand to avoid conflict
 			testCmd.Flags().Bool("help", false, "help for test")
@@ -341,7 +411,7 @@ func TestCommandLineToAPIMapping(t *testing.T) {
 			cmdLine: []string{},
 			expectedConfig: treex.TreeConfig{
 				Root:            ".", // Would be converted to absolute path in real execution
-				Filesystem:      nil,
+				Filesystem:      appFs,
 				MaxDepth:        0,
 				ExcludeGlobs:    []string{},
 				IncludeHidden:   true,
@@ -354,7 +424,7 @@ func TestCommandLineToAPIMapping(t *testing.T) {
 			cmdLine: []string{"-l", "2"},
 			expectedConfig: treex.TreeConfig{
 				Root:            ".",
-				Filesystem:      nil,
+				Filesystem:      appFs,
 				MaxDepth:        2,
 				ExcludeGlobs:    []string{},
 				IncludeHidden:   true,
@@ -367,7 +437,7 @@ func TestCommandLineToAPIMapping(t *testing.T) {
 			cmdLine: []string{"/usr/local", "-l", "1"},
 			expectedConfig: treex.TreeConfig{
 				Root:            "/usr/local",
-				Filesystem:      nil,
+				Filesystem:      appFs,
 				MaxDepth:        1,
 				ExcludeGlobs:    []string{},
 				IncludeHidden:   true,
@@ -380,7 +450,7 @@ func TestCommandLineToAPIMapping(t *testing.T) {
 			cmdLine: []string{"-e", "*.tmp", "-e", "node_modules"},
 			expectedConfig: treex.TreeConfig{
 				Root:            ".",
-				Filesystem:      nil,
+				Filesystem:      appFs,
 				MaxDepth:        0,
 				ExcludeGlobs:    []string{"*.tmp", "node_modules"},
 				IncludeHidden:   true,
@@ -393,7 +463,7 @@ func TestCommandLineToAPIMapping(t *testing.T) {
 			cmdLine: []string{"/home/user", "-l", "3", "-e", "*.log", "-e", ".git"},
 			expectedConfig: treex.TreeConfig{
 				Root:            "/home/user",
-				Filesystem:      nil,
+				Filesystem:      appFs,
 				MaxDepth:        3,
 				ExcludeGlobs:    []string{"*.log", ".git"},
 				IncludeHidden:   true,
@@ -561,3 +631,446 @@ func TestHasInfoFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestCollapseDirsWalksFullTree(t *testing.T) {
+	defer func() {
+		maxLevel = 0
+		collapseDepth = 0
+	}()
+
+	tests := []struct {
+		name             string
+		level            int
+		collapse         int
+		expectedMaxDepth int
+		expectedCollapse int
+	}{
+		{"level only limits the walk", 2, 0, 2, 0},
+		{"collapse walks everything", 0, 2, 0, 2},
+		{"level becomes a display limit with collapse", 1, 3, 0, 1},
+		{"collapse shallower than level", 4, 2, 0, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxLevel = tt.level
+			collapseDepth = tt.collapse
+
+			config := buildTreeConfig("/test/path")
+			assert.Equal(t, tt.expectedMaxDepth, config.MaxDepth)
+			assert.Equal(t, tt.expectedCollapse, effectiveCollapseDepth())
+		})
+	}
+}
+
+func TestResolveColorRules(t *testing.T) {
+	colorful := rendering.BuiltinThemes()[1]
+
+	rules, err := resolveColorRules(colorful, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10<<20), rules.LargeSize, "the theme's threshold applies by default")
+
+	rules, err = resolveColorRules(colorful, "0")
+	require.NoError(t, err)
+	assert.Zero(t, rules.LargeSize)
+
+	rules, err = resolveColorRules(colorful, "512K")
+	require.NoError(t, err)
+	assert.Equal(t, int64(512<<10), rules.LargeSize)
+
+	_, err = resolveColorRules(colorful, "lots")
+	assert.ErrorContains(t, err, "--large-files")
+}
+
+func TestResolveLSColors(t *testing.T) {
+	getenv := func(key string) string {
+		if key == rendering.LSColorsEnvVar {
+			return "*.go=01;32"
+		}
+		return ""
+	}
+
+	colors, err := resolveLSColors("theme", getenv)
+	require.NoError(t, err)
+	assert.Nil(t, colors, "the theme scheme keeps theme colors")
+
+	colors, err = resolveLSColors("ls-colors", getenv)
+	require.NoError(t, err)
+	require.NotNil(t, colors)
+	assert.Equal(t, "01;32", colors.Code(&types.Node{Name: "main.go"}))
+
+	_, err = resolveLSColors("vivid", getenv)
+	assert.ErrorContains(t, err, "unknown color scheme")
+}
+
+func TestParseMaxFiles(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    int
+		expectError bool
+	}{
+		{"all", 0, false},
+		{"ALL", 0, false},
+		{"", 0, false},
+		{"10", 10, false},
+		{"0", 0, true},
+		{"-3", 0, true},
+		{"many", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			limit, err := parseMaxFiles(tt.value)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, limit)
+		})
+	}
+}
+
+func TestCommandsReadThroughAppFs(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"README.md": "# project",
+		"src":       map[string]interface{}{"main.go": "package main"},
+	})
+
+	originalFs := appFs
+	appFs = fs
+	defer func() {
+		appFs = originalFs
+		statsJSON = false
+	}()
+	statsJSON = true
+
+	var out bytes.Buffer
+	require.NoError(t, runStats(&out, "/project"))
+
+	var stats treex.StructureStats
+	require.NoError(t, json.Unmarshal(out.Bytes(), &stats))
+	assert.Equal(t, 2, stats.Files)
+	assert.Equal(t, 2, stats.Directories)
+
+	assert.Error(t, runStats(&out, "/missing"))
+}
+
+func TestResolveRemoteRoot(t *testing.T) {
+	var got gitplugin.Remote
+	var refreshed bool
+	originalCheckout := checkoutRemote
+	checkoutRemote = func(ctx context.Context, remote gitplugin.Remote, cacheDir string, refresh bool) (string, error) {
+		got, refreshed = remote, refresh
+		return "/cache/repo", nil
+	}
+	defer func() {
+		checkoutRemote = originalCheckout
+		remoteRef, refreshRemote = "", false
+	}()
+
+	dir, err := resolveRemoteRoot(context.Background(), "https://github.com/org/repo@v1")
+	require.NoError(t, err)
+	assert.Equal(t, "/cache/repo", dir)
+	assert.Equal(t, gitplugin.Remote{URL: "https://github.com/org/repo", Ref: "v1"}, got)
+
+	remoteRef, refreshRemote = "main", true
+	_, err = resolveRemoteRoot(context.Background(), "git@github.com:org/repo.git")
+	require.NoError(t, err)
+	assert.Equal(t, gitplugin.Remote{URL: "git@github.com:org/repo.git", Ref: "main"}, got)
+	assert.True(t, refreshed)
+
+	_, err = resolveRemoteRoot(context.Background(), "./local")
+	assert.Error(t, err, "--ref needs a repository URL")
+
+	remoteRef, refreshRemote = "", false
+	dir, err = resolveRemoteRoot(context.Background(), "./local")
+	require.NoError(t, err)
+	assert.Equal(t, "./local", dir)
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	renderers := rendering.NewRendererRegistry()
+	for _, value := range []string{"term", "plain", "json", "flat", "markdown", "dot", "plantuml"} {
+		format, err := parseOutputFormat(value, renderers)
+		require.NoError(t, err)
+		assert.Equal(t, rendering.OutputFormat(value), format)
+	}
+	_, err := parseOutputFormat("yaml", renderers)
+	assert.ErrorContains(t, err, `unknown format "yaml"`)
+
+	require.NoError(t, renderers.Register(outlineFormat{}))
+	format, err := parseOutputFormat("x-outline", renderers)
+	require.NoError(t, err)
+	assert.Equal(t, rendering.OutputFormat("x-outline"), format)
+	_, err = parseOutputFormat("yaml", renderers)
+	assert.ErrorContains(t, err, "plantuml, x-outline)", "registered formats are listed as valid")
+}
+
+// outlineFormat is a custom format as a program embedding treex would register it
+type outlineFormat struct{}
+
+func (outlineFormat) Format() rendering.OutputFormat { return "x-outline" }
+
+func (outlineFormat) Description() string { return "Entry names only" }
+
+func (outlineFormat) Render(ctx context.Context, config rendering.RenderConfig, result *treex.TreeResult) error {
+	return nil
+}
+
+func TestRunVersion(t *testing.T) {
+	originalVersion, originalCommit := Version, Commit
+	Version, Commit = "1.4.0", "abc123"
+	t.Cleanup(func() { Version, Commit, versionJSON = originalVersion, originalCommit, false })
+
+	var out bytes.Buffer
+	require.NoError(t, runVersion(&out))
+	assert.Equal(t, "treex version 1.4.0 (commit abc123, built unknown)\n", out.String())
+
+	versionJSON = true
+	out.Reset()
+	require.NoError(t, runVersion(&out))
+	var info versionInfo
+	require.NoError(t, json.Unmarshal(out.Bytes(), &info))
+	assert.Equal(t, "1.4.0", info.Version)
+	assert.Equal(t, "abc123", info.Commit)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.Equal(t, runtime.GOOS+"/"+runtime.GOARCH, info.Platform)
+	assert.Equal(t, []string{"git", "info", "kind", "project"}, info.Plugins)
+	assert.Equal(t, []string{"term", "plain", "json", "flat", "markdown", "dot", "plantuml"}, info.Formats)
+}
+
+func TestReadPathList(t *testing.T) {
+	paths, err := readPathList(strings.NewReader("src/main.go\r\n\n  docs/guide.md\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"src/main.go", "docs/guide.md"}, paths)
+
+	paths, err = readPathList(strings.NewReader("with space.txt\x00line\nbreak.txt\x00"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"with space.txt", "line\nbreak.txt"}, paths, "NUL-separated input keeps names verbatim")
+
+	_, err = readPathList(strings.NewReader("\n\n"))
+	assert.ErrorContains(t, err, "no paths")
+}
+
+func TestRelativePathList(t *testing.T) {
+	paths, err := relativePathList("/project", []string{"./src/main.go", "/project/docs/guide.md", "."})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"src/main.go", "docs/guide.md"}, paths)
+
+	_, err = relativePathList("/project", []string{"../other/file.go"})
+	assert.ErrorContains(t, err, "outside")
+	_, err = relativePathList("/project", []string{"/elsewhere/file.go"})
+	assert.ErrorContains(t, err, "outside")
+}
+
+func TestValidatePaging(t *testing.T) {
+	assert.NoError(t, validatePaging(rendering.FormatTerm, 0, 0))
+	assert.NoError(t, validatePaging(rendering.FormatJSON, 100, 200))
+	assert.ErrorContains(t, validatePaging(rendering.FormatPlain, 100, 0), "--format json")
+	assert.ErrorContains(t, validatePaging(rendering.FormatJSON, -1, 0), "negative")
+}
+
+func TestParseTraversal(t *testing.T) {
+	traversal, err := parseTraversal(rendering.FormatTerm, "dfs")
+	require.NoError(t, err)
+	assert.Equal(t, rendering.TraversalDFS, traversal)
+
+	traversal, err = parseTraversal(rendering.FormatFlat, "bfs")
+	require.NoError(t, err)
+	assert.Equal(t, rendering.TraversalBFS, traversal)
+
+	_, err = parseTraversal(rendering.FormatTerm, "bfs")
+	assert.ErrorContains(t, err, "--format json or flat")
+}
+
+func TestLinkTarget(t *testing.T) {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	src := &types.Node{Name: "src", Path: "src", IsDir: true, Parent: root}
+	main := &types.Node{Name: "main.go", Path: "src/main.go", Parent: src}
+	other := &types.Node{Name: "other", Path: ".", IsDir: true}
+	target := linkTarget(map[*types.Node]linkBase{
+		root:  {absRoot: "/work/project", template: "https://example.com/blob/main/{path}"},
+		other: {absRoot: "/work/other"},
+	})
+
+	assert.Equal(t, "https://example.com/blob/main/src/main.go", target(main))
+	assert.Equal(t, "file:///work/other", target(other))
+	assert.Equal(t, "", target(&types.Node{Name: "archive.zip", Path: "."}), "entries of unrecorded roots are not linked")
+}
+
+func TestCommandFlagsDoNotConflict(t *testing.T) {
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		// Merging the inherited persistent flags panics on a reused shorthand
+		assert.NotPanics(t, func() { cmd.LocalFlags() }, cmd.CommandPath())
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(rootCmd)
+}
+
+func TestRenderOnlyFlagsAreLocal(t *testing.T) {
+	for _, name := range []string{"rankdir", "plantuml", "flat-order", "traversal", "hyperlinks", "stats", "limit", "offset"} {
+		for _, cmd := range []*cobra.Command{rootCmd, treeCmd} {
+			assert.NotNil(t, cmd.Flags().Lookup(name), "%s --%s", cmd.Name(), name)
+		}
+		assert.Nil(t, checkCmd.InheritedFlags().Lookup(name), "check does not accept --%s", name)
+	}
+	assert.NotNil(t, checkCmd.InheritedFlags().Lookup("no-pager"), "report commands page their output too")
+}
+
+func TestSplitIgnorePatterns(t *testing.T) {
+	assert.Equal(t, []string{"node_modules", "*.log", "dist"}, splitIgnorePatterns([]string{"node_modules|*.log", " dist |"}))
+	assert.Nil(t, splitIgnorePatterns(nil))
+}
+
+func TestResolvePresets(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{"go.sum": "", "Cargo.lock": ""})
+	originalFs := appFs
+	appFs = fs
+	presetNames, noBuiltinIgnores = nil, false
+	t.Cleanup(func() {
+		appFs = originalFs
+		presetNames, noBuiltinIgnores = nil, false
+	})
+
+	assert.Equal(t, []string{"go", "rust"}, resolvePresets("/project"), "detected by default")
+	presetNames = []string{"node", "auto"}
+	assert.Equal(t, []string{"node", "go", "rust"}, resolvePresets("/project"))
+	presetNames = []string{"none"}
+	assert.Nil(t, resolvePresets("/project"))
+	presetNames, noBuiltinIgnores = nil, true
+	assert.Nil(t, resolvePresets("/project"), "--no-builtin-ignores turns detection off")
+	presetNames = []string{"jvm"}
+	assert.Equal(t, []string{"jvm"}, resolvePresets("/project"), "named presets still apply")
+
+	assert.NoError(t, checkPresetFlag())
+	presetNames = []string{"none", "go"}
+	assert.Error(t, checkPresetFlag())
+	presetNames = []string{"ruby"}
+	assert.Error(t, checkPresetFlag())
+}
+
+func TestExitCode(t *testing.T) {
+	_, statErr := afero.NewMemMapFs().Stat("/missing")
+
+	assert.Equal(t, 0, exitCode(nil))
+	assert.Equal(t, exitUsage, exitCode(errors.New("unknown flag: --nope")))
+	assert.Equal(t, exitIssues, exitCode(fmt.Errorf("check: %w", issuesError(errors.New("2 problems")))))
+	assert.Equal(t, exitIO, exitCode(ioError(errors.New("path does not exist: x"))))
+	assert.Equal(t, exitIO, exitCode(fmt.Errorf("cannot access path: %w", statErr)), "filesystem errors need no marking")
+}
+
+func TestQuietAndVerboseConflict(t *testing.T) {
+	defer func() { quiet, verbosity = false, 0 }()
+
+	quiet, verbosity = true, 1
+	assert.Error(t, initLogging(rootCmd, nil))
+
+	quiet, verbosity = true, 0
+	assert.NoError(t, initLogging(rootCmd, nil))
+}
+
+func TestLogLevelAndFormatFlags(t *testing.T) {
+	original := slog.Default()
+	defer func() {
+		logLevel, logFormat = "", "text"
+		slog.SetDefault(original)
+	}()
+
+	logLevel, logFormat = "debug", "json"
+	require.NoError(t, initLogging(rootCmd, nil))
+	assert.True(t, slog.Default().Enabled(context.Background(), slog.LevelDebug))
+
+	logLevel, logFormat = "loud", "text"
+	assert.ErrorContains(t, initLogging(rootCmd, nil), "unknown log level")
+
+	logLevel, logFormat = "", "xml"
+	assert.ErrorContains(t, initLogging(rootCmd, nil), "unknown log format")
+}
+
+func TestDroppedAnnotations(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":     "README.md  Overview\nold.go  Removed file\n",
+		"README.md": "# project",
+	})
+	fs.MustCreateTree("/docs", map[string]interface{}{
+		".info":    "index.md\n",
+		"index.md": "# docs",
+	})
+	originalFs := appFs
+	appFs = fs
+	defer func() { appFs = originalFs }()
+
+	project := &treex.TreeResult{Root: &types.Node{Name: "project", Path: ".", IsDir: true}}
+	docs := &treex.TreeResult{Root: &types.Node{Name: "docs", Path: ".", IsDir: true}}
+	archived := &treex.TreeResult{Root: &types.Node{Name: "release.tar.gz", Path: ".", IsDir: true}}
+	links := map[*types.Node]linkBase{project.Root: {absRoot: "/project"}, docs.Root: {absRoot: "/docs"}}
+
+	dropped := droppedAnnotations([]string{"project"}, []*treex.TreeResult{project}, links)
+	require.Len(t, dropped, 1)
+	assert.Equal(t, ".info", dropped[0].InfoFile)
+	assert.Equal(t, "old.go", dropped[0].Path)
+
+	dropped = droppedAnnotations([]string{"project", "release.tar.gz", "docs"}, []*treex.TreeResult{project, archived, docs}, links)
+	require.Len(t, dropped, 2, "archives are skipped")
+	assert.Equal(t, "project/.info", dropped[0].InfoFile)
+	assert.Equal(t, "docs/index.md", dropped[1].Path)
+	assert.Equal(t, infofile.IssueNoText, dropped[1].Type)
+}
+
+func TestAnnotationValues(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"README.md": "# project",
+	})
+	t.Setenv("TREEX_TEST_OWNER", "leaked") // Only TREEX_VAR_TREEX_TEST_OWNER is read
+	t.Setenv("TREEX_VAR_TREEX_TEST_OWNER", "")
+
+	values := annotationValues(fs, "/project", map[string]string{"TREEX_TEST_OWNER": "platform"})
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	readme := &types.Node{Name: "README.md", Path: "README.md"}
+	readme.SetAnnotation(&types.Annotation{Notes: "Owned by ${TREEX_TEST_OWNER} in {{.GitBranch}}"})
+	root.Children = []*types.Node{readme}
+
+	expand.Tree(root, *values)
+	assert.Equal(t, "Owned by platform in {{.GitBranch}}", readme.GetAnnotation().Notes, "outside a repository git fields stay as written")
+}
+
+func TestBuildRootTreeExpansion(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info": "a.go  Owner ${OWNER}, token=${TREEX_TEST_TOKEN}\n",
+		"a.go":  "package a",
+	})
+	fs.MustCreateTree("/cache/repo", map[string]interface{}{
+		".info": "a.go  Owner ${OWNER}\n",
+		"a.go":  "package a",
+	})
+	originalFs, originalCheckout := appFs, checkoutRemote
+	appFs = fs
+	checkoutRemote = func(ctx context.Context, remote gitplugin.Remote, cacheDir string, refresh bool) (string, error) {
+		return "/cache/repo", nil
+	}
+	t.Cleanup(func() { appFs, checkoutRemote = originalFs, originalCheckout })
+	t.Setenv("TREEX_VAR_OWNER", "alice")
+	t.Setenv("TREEX_TEST_TOKEN", "hunter2")
+
+	notes := func(rootPath string) string {
+		result, err := buildRootTree(context.Background(), rootPath, nil, nil, nil, make(map[*types.Node]linkBase))
+		require.NoError(t, err)
+		for _, child := range result.Root.Children {
+			if child.Name == "a.go" {
+				return child.GetAnnotation().Notes
+			}
+		}
+		return ""
+	}
+	assert.Equal(t, "Owner alice, token=${TREEX_TEST_TOKEN}", notes("/project"), "only TREEX_VAR_ variables are read")
+	assert.Equal(t, "Owner ${OWNER}", notes("https://github.com/org/repo"), "remote trees are shown as written")
+}
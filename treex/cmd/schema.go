@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"treex/treex/schema"
+)
+
+// schemaCmd prints the JSON Schema of a machine-readable output
+var schemaCmd = &cobra.Command{
+	Use:   "schema [tree|validation|stats]",
+	Short: "Print the JSON Schema of a JSON output",
+	Long: `Print the JSON Schema (draft 2020-12) describing one of treex's JSON outputs,
+the stable contract for tools that consume them:
+
+  tree        treex --format json, including several roots and --limit pages
+  validation  treex check --format json and GET /validate of treex serve
+  stats       treex stats --json
+
+Without an argument, lists the available schemas.`,
+	Example: `  treex schema tree > treex-tree.schema.json
+  treex schema validation`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return runSchemaList(cmd.OutOrStdout())
+		}
+		return runSchema(cmd.OutOrStdout(), args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+// runSchemaList prints the names of the published schemas
+func runSchemaList(out io.Writer) error {
+	for _, name := range schema.Names() {
+		fmt.Fprintln(out, name)
+	}
+	return nil
+}
+
+// runSchema prints the schema published as name
+func runSchema(out io.Writer, name string) error {
+	content, err := schema.Get(name)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(content)
+	return err
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+	"treex/treex/selfupdate"
+)
+
+// selfUpdateCheck only reports whether a newer release exists; selfUpdateForce installs
+// the latest release whatever the running version
+var (
+	selfUpdateCheck bool
+	selfUpdateForce bool
+)
+
+// selfUpdateCmd replaces the running binary with the latest release
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update treex to the latest release",
+	Long: `Check the GitHub releases of treex and, when a newer version than the running
+one is published, download the archive for this platform, check it against the
+release's checksums.txt and replace the treex binary with the one inside it.
+
+Releases are not signed: the SHA-256 checksum guards against corrupted or
+swapped downloads, not against a compromised release. Development builds,
+whose version cannot be compared with a release, are only replaced with
+--force, which installs the latest release whatever the running version. Set
+GITHUB_TOKEN to raise the GitHub API rate limit.
+
+--check only reports whether an update is available and exits with status 2
+when one is, so CI jobs can notify about new releases.`,
+	Example: `  treex self-update
+  treex self-update --check   # Exit 2 when a newer release is published
+  treex self-update --force   # Reinstall the latest release, e.g. over a dev build`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSelfUpdate(cmd.Context(), cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateCheck, "check", false,
+		"Only report whether a newer release exists (exit status 2 when one does)")
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateForce, "force", false,
+		"Install the latest release even when the running version is not older or is a development build")
+	rootCmd.AddCommand(selfUpdateCmd)
+}
+
+// newUpdater creates the release client for this platform (replaced in tests)
+var newUpdater = func() *selfupdate.Updater {
+	return &selfupdate.Updater{
+		HTTP:   &http.Client{Timeout: 5 * time.Minute},
+		Token:  os.Getenv("GITHUB_TOKEN"),
+		GOOS:   runtime.GOOS,
+		GOARCH: runtime.GOARCH,
+	}
+}
+
+// executablePath returns the binary to replace, with symlinks resolved (replaced in tests)
+var executablePath = func() (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(executable)
+}
+
+// runSelfUpdate compares Version with the latest release and installs it when newer, or
+// with --force in any case
+func runSelfUpdate(ctx context.Context, out io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if selfUpdateCheck && selfUpdateForce {
+		return fmt.Errorf("--check and --force cannot be used together")
+	}
+
+	updater := newUpdater()
+	release, err := updater.Latest(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot check for updates: %w", err)
+	}
+	if !selfUpdateForce && !selfupdate.Valid(Version) {
+		return fmt.Errorf("cannot compare development build %s with release %s; use --force to install it", Version, release.Tag)
+	}
+	if !selfUpdateForce && !selfupdate.Newer(Version, release.Tag) {
+		fmt.Fprintf(out, "treex %s is up to date (latest release %s)\n", Version, release.Tag)
+		return nil
+	}
+	if selfUpdateCheck {
+		fmt.Fprintf(out, "treex %s is available (running %s): %s\n", release.Tag, Version, release.URL)
+		return issuesError(fmt.Errorf("update available: %s", release.Tag))
+	}
+
+	target, err := executablePath()
+	if err != nil {
+		return fmt.Errorf("cannot locate the treex binary: %w", err)
+	}
+	binary, err := updater.Download(ctx, release)
+	if err != nil {
+		return err
+	}
+	if err := selfupdate.Replace(appFs, target, binary); err != nil {
+		return ioError(err)
+	}
+	fmt.Fprintf(out, "Updated %s from %s to %s\n", target, Version, release.Tag)
+	return nil
+}
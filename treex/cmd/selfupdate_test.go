@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/selfupdate"
+)
+
+// withRelease serves v2.0.0 with a linux/amd64 archive holding "new binary", installs
+// the running binary at /opt/bin/treex as version current, and fakes the release client
+func withRelease(t *testing.T, current string) afero.Fs {
+	t.Helper()
+
+	var archive bytes.Buffer
+	compressed := gzip.NewWriter(&archive)
+	writer := tar.NewWriter(compressed)
+	require.NoError(t, writer.WriteHeader(&tar.Header{Name: "treex", Mode: 0755, Size: 10, Typeflag: tar.TypeReg}))
+	_, err := writer.Write([]byte("new binary"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	require.NoError(t, compressed.Close())
+	sum := sha256.Sum256(archive.Bytes())
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/arthur-debert/treex/releases/latest":
+			fmt.Fprintf(w, `{"tag_name":"v2.0.0","html_url":"https://example.com/v2.0.0","assets":[
+				{"name":"treex_Linux_x86_64.tar.gz","browser_download_url":"%[1]s/archive"},
+				{"name":"checksums.txt","browser_download_url":"%[1]s/checksums"}]}`, server.URL)
+		case "/archive":
+			_, _ = w.Write(archive.Bytes())
+		case "/checksums":
+			fmt.Fprintf(w, "%s  treex_Linux_x86_64.tar.gz\n", hex.EncodeToString(sum[:]))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/opt/bin", map[string]interface{}{"treex": "old binary"})
+
+	originalFs, originalUpdater, originalExecutable, originalVersion := appFs, newUpdater, executablePath, Version
+	appFs, Version = fs, current
+	newUpdater = func() *selfupdate.Updater {
+		return &selfupdate.Updater{API: server.URL, GOOS: "linux", GOARCH: "amd64"}
+	}
+	executablePath = func() (string, error) { return "/opt/bin/treex", nil }
+	t.Cleanup(func() {
+		appFs, newUpdater, executablePath, Version = originalFs, originalUpdater, originalExecutable, originalVersion
+		selfUpdateCheck, selfUpdateForce = false, false
+	})
+	return fs
+}
+
+func TestSelfUpdateInstallsNewerRelease(t *testing.T) {
+	fs := withRelease(t, "1.0.0")
+
+	var out bytes.Buffer
+	require.NoError(t, runSelfUpdate(context.Background(), &out))
+
+	assert.Equal(t, "Updated /opt/bin/treex from 1.0.0 to v2.0.0\n", out.String())
+	content, err := afero.ReadFile(fs, "/opt/bin/treex")
+	require.NoError(t, err)
+	assert.Equal(t, "new binary", string(content))
+}
+
+func TestSelfUpdateUpToDate(t *testing.T) {
+	fs := withRelease(t, "2.0.0")
+
+	var out bytes.Buffer
+	require.NoError(t, runSelfUpdate(context.Background(), &out))
+
+	assert.Equal(t, "treex 2.0.0 is up to date (latest release v2.0.0)\n", out.String())
+	content, err := afero.ReadFile(fs, "/opt/bin/treex")
+	require.NoError(t, err)
+	assert.Equal(t, "old binary", string(content))
+}
+
+func TestSelfUpdateCheck(t *testing.T) {
+	fs := withRelease(t, "1.0.0")
+	selfUpdateCheck = true
+
+	var out bytes.Buffer
+	err := runSelfUpdate(context.Background(), &out)
+
+	require.Error(t, err)
+	assert.Equal(t, exitIssues, exitCode(err))
+	assert.Equal(t, "treex v2.0.0 is available (running 1.0.0): https://example.com/v2.0.0\n", out.String())
+	content, err := afero.ReadFile(fs, "/opt/bin/treex")
+	require.NoError(t, err)
+	assert.Equal(t, "old binary", string(content), "--check never replaces the binary")
+}
+
+func TestSelfUpdateDevelopmentBuild(t *testing.T) {
+	fs := withRelease(t, "dev")
+
+	var out bytes.Buffer
+	err := runSelfUpdate(context.Background(), &out)
+	assert.ErrorContains(t, err, "cannot compare development build dev with release v2.0.0; use --force")
+	content, err := afero.ReadFile(fs, "/opt/bin/treex")
+	require.NoError(t, err)
+	assert.Equal(t, "old binary", string(content))
+
+	selfUpdateForce = true
+	require.NoError(t, runSelfUpdate(context.Background(), &out))
+	content, err = afero.ReadFile(fs, "/opt/bin/treex")
+	require.NoError(t, err)
+	assert.Equal(t, "new binary", string(content))
+
+	selfUpdateCheck = true
+	assert.ErrorContains(t, runSelfUpdate(context.Background(), &out), "cannot be used together")
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"treex/treex/plugins/infofile"
+	"treex/treex/server"
+)
+
+var (
+	// Server options
+	servePort int    // Port to listen on
+	serveHost string // Interface to bind
+	serveHTML bool   // Serve the HTML tree view at "/"
+)
+
+// serveCmd exposes the tree and annotations over read-only HTTP endpoints
+var serveCmd = &cobra.Command{
+	Use:   "serve [path]",
+	Short: "Serve the tree and annotations over HTTP",
+	Long: `Serve the tree and annotations of a directory over read-only HTTP endpoints:
+
+  GET /tree?path=&depth=   Tree as JSON (path is relative to the served directory)
+  GET /annotations         Annotated paths and their notes
+  GET /validate            Problems found in .info files
+  GET /                    Plain HTML view of the tree (with --html)
+
+Filters given to treex (--exclude, --no-builtin-ignores, ...) apply to every request.`,
+	Example: `  treex serve                      # Serve the current directory on localhost:8080
+  treex serve --port 9000 --html   # Include the HTML view`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootPath := "."
+		if len(args) > 0 {
+			rootPath = args[0]
+		}
+
+		absRoot, err := filepath.Abs(rootPath)
+		if err != nil {
+			return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+		}
+		if info, err := appFs.Stat(absRoot); err != nil || !info.IsDir() {
+			return fmt.Errorf("cannot serve %q: not an accessible directory", rootPath)
+		}
+
+		// Requests re-gather annotations; only changed .info files are parsed again
+		infofile.UseCache(infofile.NewParseCache())
+
+		srv := server.NewServer(server.Config{
+			Root:       absRoot,
+			Filesystem: appFs,
+			Tree:       buildTreeConfig(absRoot),
+			EnableHTML: serveHTML,
+		})
+
+		addr := net.JoinHostPort(serveHost, strconv.Itoa(servePort))
+		fmt.Fprintf(cmd.ErrOrStderr(), "Serving %s on http://%s\n", absRoot, addr)
+		return http.ListenAndServe(addr, srv.Handler())
+	},
+}
+
+func init() {
+	serveCmd.Flags().IntVar(&servePort, "port", 8080, "Port to listen on")
+	serveCmd.Flags().StringVar(&serveHost, "host", "localhost", "Interface to bind (use 0.0.0.0 for all interfaces)")
+	serveCmd.Flags().BoolVar(&serveHTML, "html", false, "Serve a plain HTML view of the tree at /")
+	rootCmd.AddCommand(serveCmd)
+}
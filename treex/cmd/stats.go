@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+// statsJSON selects JSON output for the stats command
+var statsJSON bool
+
+// statsCmd reports structural analytics for a directory tree
+var statsCmd = &cobra.Command{
+	Use:   "stats [path]",
+	Short: "Show structural statistics for a directory tree",
+	Long: `Show structural statistics for a directory tree: file and directory counts
+by depth and extension, the largest directories, annotation coverage per
+top-level subtree and the number of .info files. Coverage leaves out binary,
+generated and vendored files, which nobody is expected to document.
+
+The tree is built with the same filters as "treex" (--exclude, --level, ...).
+With --workspace, the repositories the workspace file lists are counted as
+one tree, with annotation coverage per repository.`,
+	Example: `  treex stats              # Statistics for the current directory
+  treex stats --json src   # Machine-readable statistics for src`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if workspaceFile != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("--workspace cannot be combined with a path")
+			}
+			return paged(cmd, runWorkspaceStats)
+		}
+		rootPath := "."
+		if len(args) > 0 {
+			rootPath = args[0]
+		}
+		return paged(cmd, func(out io.Writer) error { return runStats(out, rootPath) })
+	},
+}
+
+func init() {
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Output statistics as JSON")
+	rootCmd.AddCommand(statsCmd)
+}
+
+// runStats builds the tree for rootPath and renders its structural statistics
+func runStats(out io.Writer, rootPath string) error {
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+	if _, err := appFs.Stat(absRoot); err != nil {
+		return fmt.Errorf("cannot access path %q: %w", rootPath, err)
+	}
+
+	result, err := treex.BuildTree(buildTreeConfig(absRoot))
+	if err != nil {
+		return fmt.Errorf("failed to build tree: %w", err)
+	}
+
+	return renderStats(out, result.Root)
+}
+
+// runWorkspaceStats renders the statistics of the repositories of the --workspace file
+// as one tree, so coverage is reported per repository
+func runWorkspaceStats(out io.Writer) error {
+	label, paths, names, err := loadWorkspace()
+	if err != nil {
+		return err
+	}
+	results := make([]*treex.TreeResult, len(paths))
+	for i, repoPath := range paths {
+		if results[i], err = treex.BuildTree(buildTreeConfig(repoPath)); err != nil {
+			return fmt.Errorf("failed to build tree of %s: %w", names[i], err)
+		}
+	}
+	combined := treex.CombineResults(label, names, results).Root
+	for i, repoRoot := range combined.Children {
+		prefixPaths(repoRoot, names[i]) // Directories are reported as <repo>/<path>
+	}
+	return renderStats(out, combined)
+}
+
+// renderStats renders the structural statistics of the tree below root
+func renderStats(out io.Writer, root *types.Node) error {
+	format := rendering.FormatTerm
+	if statsJSON {
+		format = rendering.FormatJSON
+	}
+
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format: format,
+		Writer: out,
+	})
+	return renderer.RenderStructureStats(treex.AnalyzeStructure(root, 0))
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"treex/treex"
+	"treex/treex/plugins/infofile"
+	"treex/treex/suggest"
+)
+
+var (
+	// suggestJSON prints the suggestions as JSON instead of reviewing and writing them
+	suggestJSON bool
+	// suggestLimit caps the number of paths sent to the model
+	suggestLimit int
+	// suggestConfigPath overrides the suggest configuration file
+	suggestConfigPath string
+)
+
+// suggestCmd proposes annotations for unannotated paths using a language model
+var suggestCmd = &cobra.Command{
+	Use:   "suggest [path]",
+	Short: "Suggest annotations for unannotated paths with a language model",
+	Long: `Suggest annotations for unannotated files and directories. Each path is sent
+to a language model with a sample of its content (or its directory listing),
+and the suggestions are reviewed one by one: accept, edit or reject. Accepted
+annotations are written to the .info file of each path's parent directory.
+
+The model is reached through an OpenAI or Anthropic compatible endpoint,
+configured in ~/.config/treex/suggest.yaml (provider, endpoint, model,
+api_key) or the environment:
+
+  TREEX_SUGGEST_PROVIDER   openai (default) or anthropic
+  TREEX_SUGGEST_ENDPOINT   Base URL (defaults to the provider's public API)
+  TREEX_SUGGEST_MODEL      Model name (required)
+  TREEX_SUGGEST_API_KEY    API key (falls back to OPENAI_API_KEY / ANTHROPIC_API_KEY)
+
+File contents are sent to the endpoint. The tree is built with the same
+filters as "treex" (--exclude, --level, ...).`,
+	Example: `  treex suggest                      # Review suggestions for the current directory
+  treex suggest --limit 5 src        # At most five paths below src
+  treex suggest --json > s.json      # JSON for review in a pull request`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootPath := "."
+		if len(args) > 0 {
+			rootPath = args[0]
+		}
+		return runSuggest(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr(), cmd.InOrStdin(), rootPath)
+	},
+}
+
+func init() {
+	suggestCmd.Flags().BoolVar(&suggestJSON, "json", false, "Print suggestions as JSON without writing .info files")
+	suggestCmd.Flags().IntVar(&suggestLimit, "limit", 20, "Maximum number of paths to suggest annotations for (0 = no limit)")
+	suggestCmd.Flags().StringVar(&suggestConfigPath, "config", "",
+		"Suggest configuration file (default: ~/.config/treex/suggest.yaml)")
+	rootCmd.AddCommand(suggestCmd)
+}
+
+// newSuggestClient loads the suggest configuration and creates the model client (replaced in tests)
+var newSuggestClient = func() (suggest.Client, error) {
+	configPath := suggestConfigPath
+	if configPath == "" {
+		configPath = suggest.DefaultConfigPath()
+	}
+	config, err := suggest.LoadConfig(appFs, configPath, os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	return suggest.NewClient(config, &http.Client{Timeout: time.Minute})
+}
+
+// runSuggest builds the tree for rootPath, asks for suggestions and reviews or prints them
+func runSuggest(ctx context.Context, out, errOut io.Writer, in io.Reader, rootPath string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+	if info, err := appFs.Stat(absRoot); err != nil || !info.IsDir() {
+		return fmt.Errorf("cannot suggest for %q: not an accessible directory", rootPath)
+	}
+
+	client, err := newSuggestClient()
+	if err != nil {
+		return err
+	}
+
+	// Root the tree at the directory so suggestion paths are relative to it
+	rooted := afero.NewBasePathFs(appFs, absRoot)
+	config := buildTreeConfig(absRoot)
+	config.Root = "/"
+	config.Filesystem = rooted
+	result, err := treex.BuildTreeContext(ctx, config)
+	if err != nil {
+		return fmt.Errorf("failed to build tree: %w", err)
+	}
+
+	candidates := suggest.Candidates(result.Root, suggestLimit)
+	generator := &suggest.Generator{Client: client, Filesystem: rooted}
+
+	if suggestJSON {
+		suggestions := []suggest.Suggestion{}
+		for i, node := range candidates {
+			fmt.Fprintf(errOut, "[%d/%d] %s\n", i+1, len(candidates), node.Path)
+			suggestion, err := generator.Suggest(ctx, node)
+			if err != nil {
+				return err
+			}
+			suggestions = append(suggestions, suggestion)
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(suggestions)
+	}
+
+	if len(candidates) == 0 {
+		fmt.Fprintln(out, "Every path is annotated")
+		return nil
+	}
+
+	// A failed suggestion skips its path; what was accepted is written however the
+	// session ends
+	reader := bufio.NewReader(in)
+	var accepted []infofile.Annotation
+	var stopErr error
+	for i, node := range candidates {
+		suggestion, err := generator.Suggest(ctx, node)
+		if err != nil {
+			if ctx.Err() != nil {
+				stopErr = err
+				break
+			}
+			fmt.Fprintf(errOut, "[%d/%d] %s: skipped: %v\n", i+1, len(candidates), node.Path, err)
+			continue
+		}
+
+		fmt.Fprintf(out, "\n[%d/%d] %s\n  %s\n", i+1, len(candidates), suggestion.Path, suggestion.Annotation)
+		annotation, quit, err := reviewSuggestion(reader, out, suggestion)
+		if annotation != "" {
+			accepted = append(accepted, infofile.Annotation{Path: suggestion.Path, Notes: annotation})
+		}
+		if err != nil {
+			stopErr = err
+			break
+		}
+		if quit {
+			break
+		}
+	}
+
+	if len(accepted) == 0 {
+		fmt.Fprintln(out, "No annotations written")
+		return stopErr
+	}
+	var written []string
+	err = withUndo(absRoot, []string{"suggest"}, func(fs afero.Fs) error {
+		written, err = infofile.AddAnnotations(fs, absRoot, accepted)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Annotated %d paths in %d .info files\n", len(accepted), len(written))
+	for _, infoPath := range written {
+		fmt.Fprintf(out, "  %s\n", infoPath)
+	}
+	return stopErr
+}
+
+// reviewSuggestion asks whether to accept, edit or reject a suggestion
+// Returns the annotation to write (empty when rejected) and whether to stop reviewing;
+// the end of input stops without writing the current suggestion
+func reviewSuggestion(reader *bufio.Reader, out io.Writer, suggestion suggest.Suggestion) (string, bool, error) {
+	for {
+		fmt.Fprint(out, "[a]ccept, [e]dit, [r]eject, [q]uit? ")
+		answer, ok, err := readAnswer(reader)
+		if err != nil || !ok {
+			return "", true, err
+		}
+
+		switch strings.ToLower(answer) {
+		case "a", "accept", "y", "yes":
+			return suggestion.Annotation, false, nil
+		case "e", "edit":
+			fmt.Fprint(out, "Annotation: ")
+			edited, ok, err := readAnswer(reader)
+			if err != nil || !ok {
+				return "", true, err
+			}
+			return edited, false, nil
+		case "r", "reject", "n", "no", "":
+			return "", false, nil
+		case "q", "quit":
+			return "", true, nil
+		}
+	}
+}
+
+// readAnswer reads one trimmed line, reporting false at the end of input
+func readAnswer(reader *bufio.Reader) (string, bool, error) {
+	line, err := reader.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", false, nil
+	}
+	if err != nil && err != io.EOF {
+		return "", false, fmt.Errorf("failed to read answer: %w", err)
+	}
+	return strings.TrimSpace(line), true, nil
+}
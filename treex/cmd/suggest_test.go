@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex/suggest"
+)
+
+// pathClient suggests "About <last word of the quoted path>" for every prompt
+type pathClient struct{}
+
+func (pathClient) Complete(_ context.Context, prompt string) (string, error) {
+	start := strings.Index(prompt, `"`) + 1
+	end := start + strings.Index(prompt[start:], `"`)
+	return "About " + prompt[start:end] + ".", nil
+}
+
+// failingClient fails for the prompt of one quoted path and answers like pathClient otherwise
+type failingClient struct{ path string }
+
+func (c failingClient) Complete(ctx context.Context, prompt string) (string, error) {
+	if strings.Contains(prompt, `"`+c.path+`"`) {
+		return "", errors.New("model overloaded")
+	}
+	return pathClient{}.Complete(ctx, prompt)
+}
+
+// withSuggestProject points appFs at an in-memory project and fakes the model client
+func withSuggestProject(t *testing.T) afero.Fs {
+	t.Helper()
+
+	originalClient := newSuggestClient
+	newSuggestClient = func() (suggest.Client, error) { return pathClient{}, nil }
+	return withAppFs(t, "/project", map[string]interface{}{
+		".info":     "README.md  Project overview\n",
+		"README.md": "# project",
+		"go.mod":    "module example",
+		"src":       map[string]interface{}{"main.go": "package main"},
+	}, func() {
+		newSuggestClient = originalClient
+		suggestJSON, suggestLimit = false, 20
+	})
+}
+
+func TestSuggestJSON(t *testing.T) {
+	fs := withSuggestProject(t)
+	suggestJSON = true
+
+	var out, errOut bytes.Buffer
+	require.NoError(t, runSuggest(context.Background(), &out, &errOut, nil, "/project"))
+
+	var suggestions []suggest.Suggestion
+	require.NoError(t, json.Unmarshal(out.Bytes(), &suggestions))
+	assert.Equal(t, []suggest.Suggestion{
+		{Path: "go.mod", Annotation: "About go.mod"},
+		{Path: "src", IsDir: true, Annotation: "About src"},
+		{Path: "src/main.go", Annotation: "About src/main.go"},
+	}, suggestions)
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "README.md  Project overview\n", string(content), "--json writes nothing")
+}
+
+func TestSuggestReview(t *testing.T) {
+	fs := withSuggestProject(t)
+
+	// Accept go.mod, edit src, reject src/main.go
+	in := strings.NewReader("a\ne\nSource code\nr\n")
+	var out bytes.Buffer
+	require.NoError(t, runSuggest(context.Background(), &out, &bytes.Buffer{}, in, "/project"))
+	assert.Contains(t, out.String(), "[1/3] go.mod\n  About go.mod\n")
+	assert.Contains(t, out.String(), "Annotated 2 paths in 1 .info files")
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "README.md  Project overview\ngo.mod  About go.mod\nsrc  Source code\n", string(content))
+
+	exists, _ := afero.Exists(fs, "/project/src/.info")
+	assert.False(t, exists)
+}
+
+func TestSuggestQuitAndEndOfInput(t *testing.T) {
+	for _, input := range []string{"a\nq\n", "a\n"} {
+		fs := withSuggestProject(t)
+		suggestLimit = 0
+
+		require.NoError(t, runSuggest(context.Background(), &bytes.Buffer{}, &bytes.Buffer{}, strings.NewReader(input), "/project"))
+
+		content, err := afero.ReadFile(fs, "/project/.info")
+		require.NoError(t, err)
+		assert.Equal(t, "README.md  Project overview\ngo.mod  About go.mod\n", string(content), "input %q", input)
+	}
+}
+
+func TestSuggestRequiresDirectory(t *testing.T) {
+	withSuggestProject(t)
+	err := runSuggest(context.Background(), &bytes.Buffer{}, &bytes.Buffer{}, nil, "/project/go.mod")
+	assert.ErrorContains(t, err, "not an accessible directory")
+}
+
+func TestSuggestSkipsFailedPaths(t *testing.T) {
+	fs := withSuggestProject(t)
+	newSuggestClient = func() (suggest.Client, error) { return failingClient{path: "src"}, nil }
+
+	// Accept go.mod and src/main.go; src fails and is skipped
+	in := strings.NewReader("a\na\n")
+	var out, errOut bytes.Buffer
+	require.NoError(t, runSuggest(context.Background(), &out, &errOut, in, "/project"))
+	assert.Contains(t, errOut.String(), "[2/3] src: skipped: ")
+	assert.Contains(t, errOut.String(), "model overloaded")
+	assert.Contains(t, out.String(), "Annotated 2 paths in 2 .info files")
+
+	content, err := afero.ReadFile(fs, "/project/src/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "main.go  About src/main.go\n", string(content))
+}
+
+func TestSuggestWritesAcceptedWhenCanceled(t *testing.T) {
+	fs := withSuggestProject(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	newSuggestClient = func() (suggest.Client, error) { return cancelingClient{path: "src", cancel: cancel}, nil }
+
+	in := strings.NewReader("a\n")
+	var out bytes.Buffer
+	err := runSuggest(ctx, &out, &bytes.Buffer{}, in, "/project")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	content, readErr := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, readErr)
+	assert.Equal(t, "README.md  Project overview\ngo.mod  About go.mod\n", string(content), "accepted annotations are kept")
+}
+
+// cancelingClient cancels the session when asked about one quoted path
+type cancelingClient struct {
+	path   string
+	cancel context.CancelFunc
+}
+
+func (c cancelingClient) Complete(ctx context.Context, prompt string) (string, error) {
+	if strings.Contains(prompt, `"`+c.path+`"`) {
+		c.cancel()
+		return "", ctx.Err()
+	}
+	return pathClient{}.Complete(ctx, prompt)
+}
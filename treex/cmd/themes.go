@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+var (
+	// themesDir overrides the user themes directory (empty uses the XDG default)
+	themesDir string
+)
+
+// themesCmd groups theme related subcommands
+var themesCmd = &cobra.Command{
+	Use:   "themes",
+	Short: "List and preview color themes",
+	Long: `List and preview color themes.
+
+Themes map treex's presentation styles (strong, weak, info, ...) to colors.
+User themes are YAML files in ~/.config/treex/themes (or $XDG_CONFIG_HOME/treex/themes):
+
+  name: ocean
+  description: Blue tones
+  styles:
+    strong: { foreground: "#268bd2", bold: true }
+    weak:   { foreground: { light: "#93a1a1", dark: "#586e75" } }
+
+Valid styles: strong, normal, weak, active, inactive, success, error, warning, info, header, subtle.`,
+}
+
+// themesListCmd lists builtin and user themes
+var themesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available themes",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runThemesList(cmd.OutOrStdout(), cmd.ErrOrStderr())
+	},
+}
+
+// themesPreviewCmd renders a sample tree with a theme
+var themesPreviewCmd = &cobra.Command{
+	Use:   "preview <name>",
+	Short: "Preview a theme on a sample tree",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runThemesPreview(cmd.OutOrStdout(), args[0])
+	},
+}
+
+func init() {
+	themesCmd.PersistentFlags().StringVar(&themesDir, "themes-dir", "",
+		"Directory containing user theme files (default: ~/.config/treex/themes)")
+	themesCmd.AddCommand(themesListCmd)
+	themesCmd.AddCommand(themesPreviewCmd)
+	rootCmd.AddCommand(themesCmd)
+}
+
+// userThemesDir returns the configured user themes directory
+func userThemesDir() string {
+	if themesDir != "" {
+		return themesDir
+	}
+	return rendering.DefaultThemesDir()
+}
+
+// resolveThemeSelection turns the --theme flag (or the TREEX_THEME fallback) into a theme
+// and background mode: "auto", "dark" and "light" keep the default theme and set the
+// palette; other values name a theme to load
+func resolveThemeSelection(flagValue, envValue string) (*rendering.Theme, rendering.BackgroundMode, error) {
+	selection := flagValue
+	if selection == "" {
+		selection = envValue
+	}
+
+	background, name := rendering.ParseThemeSelection(selection)
+	theme, err := rendering.FindTheme(appFs, userThemesDir(), name)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid theme selection %q: %w", selection, err)
+	}
+	return theme, background, nil
+}
+
+// runThemesList prints one line per theme; invalid theme files are reported on errOut
+func runThemesList(out, errOut io.Writer) error {
+	themes, err := rendering.AvailableThemes(appFs, userThemesDir())
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: %v\n", err)
+	}
+
+	for _, theme := range themes {
+		fmt.Fprintf(out, "%s %s %s\n",
+			rendering.PadRight(theme.Name, 16), rendering.PadRight(theme.Description, 40), theme.Source)
+	}
+	return nil
+}
+
+// runThemesPreview renders the style palette and a sample tree using the named theme
+func runThemesPreview(out io.Writer, name string) error {
+	theme, err := rendering.FindTheme(appFs, userThemesDir(), name)
+	if err != nil {
+		return err
+	}
+
+	// Previews follow the same NO_COLOR / CLICOLOR_FORCE policy as tree output
+	enableColors, forceColors := rendering.ColorSettings(rendering.FormatTerm, false, os.Getenv)
+	styles := rendering.NewStyleManagerWithConfig(rendering.StyleConfig{
+		EnableColors: enableColors,
+		ForceColors:  forceColors,
+		Theme:        theme,
+		Output:       out,
+	})
+	fmt.Fprintf(out, "%s\n\n", styles.StatsHeader(fmt.Sprintf("Theme: %s (%s)", theme.Name, theme.Source)))
+	fmt.Fprintf(out, "  %s  %s  %s\n", styles.FileName("file.go"), styles.DirectoryName("directory"), styles.HiddenFile(".hidden"))
+	fmt.Fprintf(out, "  %s  %s  %s\n", styles.SuccessMessage("success"), styles.WarningMessage("warning"), styles.ErrorMessage("error"))
+	fmt.Fprintf(out, "  %s %s\n", styles.StatsItem("Files:"), styles.StatsValue("42"))
+	fmt.Fprintf(out, "  %s  %s  %s  %s  %s\n\n",
+		styles.ClassifiedFileName(rendering.ClassCode, "code.go"), styles.ClassifiedFileName(rendering.ClassDocs, "docs.md"),
+		styles.ClassifiedFileName(rendering.ClassConfig, "config.yaml"), styles.ClassifiedFileName(rendering.ClassBinary, "binary.zip"),
+		styles.ClassifiedFileName(rendering.ClassLarge, "large.iso"))
+
+	rules, err := theme.ColorRules()
+	if err != nil {
+		return err
+	}
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format:      rendering.FormatTerm,
+		Writer:      out,
+		ShowNotes:   true,
+		Theme:       theme,
+		FileClasses: &rules,
+	})
+	return renderer.RenderTree(&treex.TreeResult{Root: sampleThemeTree()})
+}
+
+// sampleThemeTree builds a small annotated tree used for theme previews
+func sampleThemeTree() *types.Node {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	src := &types.Node{Name: "src", Path: "src", IsDir: true}
+	docs := &types.Node{Name: "docs", Path: "docs", IsDir: true}
+	mainFile := &types.Node{Name: "main.go", Path: "src/main.go"}
+	readme := &types.Node{Name: "README.txt", Path: "docs/README.txt"}
+
+	src.SetAnnotation(&types.Annotation{Path: "src", Notes: "Application source code"})
+	readme.SetAnnotation(&types.Annotation{Path: "docs/README.txt", Notes: "Start here"})
+
+	addChildren(root, src, docs)
+	addChildren(src, mainFile)
+	addChildren(docs, readme)
+	return root
+}
+
+// addChildren attaches children to a parent node
+func addChildren(parent *types.Node, children ...*types.Node) {
+	for _, child := range children {
+		child.Parent = parent
+		parent.Children = append(parent.Children, child)
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex/rendering"
+)
+
+// withThemes points the themes commands at an in-memory themes directory for the test
+func withThemes(t *testing.T, tree map[string]interface{}) {
+	t.Helper()
+
+	originalDir := themesDir
+	themesDir = "/themes"
+	withAppFs(t, "/themes", tree, func() { themesDir = originalDir })
+}
+
+func TestThemesList(t *testing.T) {
+	withThemes(t, map[string]interface{}{
+		"ocean.yaml":  "description: Blue tones\n",
+		"broken.yaml": "styles:\n  nope: {}\n",
+	})
+
+	var out, errOut bytes.Buffer
+	require.NoError(t, runThemesList(&out, &errOut))
+
+	assert.Contains(t, out.String(), "ocean")
+	assert.Contains(t, out.String(), "/themes/ocean.yaml")
+	assert.Contains(t, out.String(), "default")
+	assert.Contains(t, out.String(), "colorful")
+	assert.Contains(t, errOut.String(), "broken.yaml")
+}
+
+func TestThemesPreview(t *testing.T) {
+	withThemes(t, map[string]interface{}{
+		"ocean.yaml": "styles:\n  info: { foreground: \"33\" }\n",
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, runThemesPreview(&out, "ocean"))
+
+	assert.Contains(t, out.String(), "Theme: ocean")
+	assert.Contains(t, out.String(), "main.go")
+	assert.Contains(t, out.String(), "Application source code")
+
+	err := runThemesPreview(&out, "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestResolveThemeSelection(t *testing.T) {
+	withThemes(t, map[string]interface{}{
+		"ocean.yaml": "description: Blue tones\n",
+	})
+
+	tests := []struct {
+		name               string
+		flagValue          string
+		envValue           string
+		expectedTheme      string
+		expectedBackground rendering.BackgroundMode
+		expectError        bool
+	}{
+		{"defaults to auto", "", "", rendering.DefaultThemeName, rendering.BackgroundAuto, false},
+		{"flag forces dark", "dark", "", rendering.DefaultThemeName, rendering.BackgroundDark, false},
+		{"env used without flag", "", "light", rendering.DefaultThemeName, rendering.BackgroundLight, false},
+		{"flag overrides env", "ocean", "light", "ocean", rendering.BackgroundAuto, false},
+		{"unknown theme errors", "missing", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theme, background, err := resolveThemeSelection(tt.flagValue, tt.envValue)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTheme, theme.Name)
+			assert.Equal(t, tt.expectedBackground, background)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"treex/treex/preview"
+	"treex/treex/rendering"
+	"treex/treex/undo"
+)
+
+var (
+	// undoList lists the recorded operations instead of reverting one
+	undoList bool
+	// undoForce reverts even when files changed after the operation
+	undoForce bool
+)
+
+// undoCmd reverts the last operation that edited .info files or created files
+var undoCmd = &cobra.Command{
+	Use:   "undo [path]",
+	Short: "Revert the last treex edit",
+	Long: `Revert the last operation that changed files in the project: add, suggest,
+harvest, gen-info, make-tree, gather, distribute, fmt, review --mark and
+check --fix journal the prior state of everything they write under .treex/undo in the project root.
+
+Files are restored to their earlier content, files the operation created are
+removed, and directories it created are removed when empty. If a file was
+edited after the operation, undo stops and names it; --force reverts anyway.
+The last ` + fmt.Sprint(undo.MaxJournals) + ` operations are kept.`,
+	Example: `  treex add 'cmd/*' -a "CLI entry points"
+  treex undo
+  treex undo --list`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootPath := "."
+		if len(args) > 0 {
+			rootPath = args[0]
+		}
+		return runUndo(cmd.OutOrStdout(), rootPath)
+	},
+}
+
+func init() {
+	undoCmd.Flags().BoolVar(&undoList, "list", false, "List recorded operations, newest first")
+	undoCmd.Flags().BoolVar(&undoForce, "force", false, "Revert even if files changed since the operation")
+	rootCmd.AddCommand(undoCmd)
+}
+
+// runUndo lists or reverts the journals of the project at rootPath
+func runUndo(out io.Writer, rootPath string) error {
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", rootPath, err)
+	}
+
+	if undoList {
+		journals, err := undo.List(appFs, absRoot)
+		if err != nil {
+			return err
+		}
+		if len(journals) == 0 {
+			fmt.Fprintln(out, "Nothing to undo")
+			return nil
+		}
+		for _, journal := range journals {
+			fmt.Fprintf(out, "%s  %s %d changes\n", journal.Time.Local().Format("2006-01-02 15:04:05"),
+				rendering.PadRight(journal.Command, 40), len(journal.Changes))
+		}
+		return nil
+	}
+
+	result, err := undo.Revert(appFs, absRoot, undoForce)
+	if errors.Is(err, undo.ErrNothingToUndo) {
+		fmt.Fprintln(out, "Nothing to undo")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Reverted %q (%d files)\n", result.Journal.Command, len(result.Restored))
+	for _, path := range result.Restored {
+		fmt.Fprintf(out, "  %s\n", path)
+	}
+	for _, path := range result.Kept {
+		fmt.Fprintf(out, "Kept non-empty directory %s\n", path)
+	}
+	return nil
+}
+
+// withUndo runs edit against a journaling view of appFs and saves the journal for the
+// project at absRoot, also when edit fails part way through
+func withUndo(absRoot string, command []string, edit func(fs afero.Fs) error) error {
+	recorder := undo.NewRecorder(appFs, absRoot)
+	editErr := edit(recorder)
+	if _, err := recorder.Save("treex " + strings.Join(command, " ")); err != nil && editErr == nil {
+		return err
+	}
+	return editErr
+}
+
+// diffUsage describes the --diff and --dry-run flags of the writing commands
+const diffUsage = "Print unified diffs of the files that would change without writing them"
+
+// addDiffFlags registers --diff and --dry-run on flags, both setting diff: every writing
+// command previews its changes the same way
+func addDiffFlags(flags *pflag.FlagSet, diff *bool) {
+	flags.BoolVar(diff, "diff", false, diffUsage)
+	flags.BoolVar(diff, "dry-run", false, diffUsage)
+}
+
+// previewEdit runs edit as withUndo would, against an in-memory overlay of appFs, and
+// prints the files it would change as unified diffs instead of writing them
+func previewEdit(out io.Writer, absRoot string, edit func(fs afero.Fs) error) error {
+	changes, err := preview.Run(appFs, absRoot, edit)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "No changes")
+		return nil
+	}
+	return preview.WriteDiff(out, changes)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex/undo"
+)
+
+func TestUndoRevertsAdd(t *testing.T) {
+	fs := withAddProject(t)
+	t.Cleanup(func() { undoList, undoForce = false, false })
+	addAnnotation = "CLI entry points"
+
+	var out bytes.Buffer
+	require.NoError(t, runAdd(&out, nil, "/project", []string{"cmd/*.go", "README.md"}))
+
+	out.Reset()
+	undoList = true
+	require.NoError(t, runUndo(&out, "/project"))
+	assert.Contains(t, out.String(), "treex add cmd/*.go README.md")
+
+	out.Reset()
+	undoList = false
+	require.NoError(t, runUndo(&out, "/project"))
+	assert.Equal(t, "Reverted \"treex add cmd/*.go README.md\" (2 files)\n  cmd/.info\n  .info\n", out.String())
+
+	content, err := afero.ReadFile(fs, "/project/cmd/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "root.go  Old text\n", string(content))
+	exists, _ := afero.Exists(fs, "/project/.info")
+	assert.False(t, exists)
+
+	out.Reset()
+	require.NoError(t, runUndo(&out, "/project"))
+	assert.Equal(t, "Nothing to undo\n", out.String())
+}
+
+func TestUndoJournalsOnlyEditedFiles(t *testing.T) {
+	withAddProject(t)
+	t.Cleanup(func() { undoList = false })
+	addAnnotation = "Root command"
+
+	var out bytes.Buffer
+	require.NoError(t, runAdd(&out, nil, "/project", []string{"cmd/root.go"}))
+
+	// The .info lock and temporary file are not changes of their own
+	journals, err := undo.List(appFs, "/project")
+	require.NoError(t, err)
+	require.Len(t, journals, 1)
+	require.Len(t, journals[0].Changes, 1)
+	assert.Equal(t, "cmd/.info", journals[0].Changes[0].Path)
+
+	out.Reset()
+	undoList = true
+	require.NoError(t, runUndo(&out, "/project"))
+	assert.Regexp(t, `treex add cmd/root.go +1 changes`, out.String())
+}
+
+func TestWritingCommandsShareDryRun(t *testing.T) {
+	for _, command := range []*cobra.Command{addCmd, gatherCmd, distributeCmd, genInfoCmd, harvestCmd, makeTreeCmd, fmtCmd} {
+		dryRun, diff := command.Flags().Lookup("dry-run"), command.Flags().Lookup("diff")
+		require.NotNil(t, dryRun, command.Name())
+		require.NotNil(t, diff, command.Name())
+		assert.Equal(t, diffUsage, dryRun.Usage, command.Name())
+		assert.Equal(t, diffUsage, diff.Usage, command.Name())
+
+		require.NoError(t, dryRun.Value.Set("true"))
+		assert.Equal(t, "true", diff.Value.String(), "%s: --dry-run is --diff", command.Name())
+		require.NoError(t, dryRun.Value.Set("false"))
+	}
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"treex/treex"
+	"treex/treex/verify"
+)
+
+var (
+	// verifySpec is the file holding the expected structure ("-" for stdin)
+	verifySpec string
+	// verifySpecFormat selects the spec format: auto, text, json or yaml
+	verifySpecFormat string
+	// verifyJSON prints the findings as JSON
+	verifyJSON bool
+)
+
+// verifyCmd checks a directory against an expected structure
+var verifyCmd = &cobra.Command{
+	Use:   "verify --spec <file> [path]",
+	Short: "Check a directory against an expected structure",
+	Long: `Compare a directory with the structure declared in a spec file and report
+missing, extra and misplaced paths.
+
+The spec uses the formats of "treex make-tree": a tree diagram, or JSON/YAML
+structure input (--spec-format, detected from the extension by default).
+Annotations and file contents in the spec are ignored.
+
+Every directory the spec lists children for is closed: other entries found
+in it are extra. A directory listed without children ("vendor/") may contain
+anything. A required path found elsewhere under the same name is misplaced.
+.info files and built-in ignores (.git, node_modules, ...) are never extra.
+
+Exit codes: 0 when the directory matches, 1 when the spec is invalid, 2 when
+the directory differs, 3 when the spec or the directory cannot be read.`,
+	Example: `  treex verify --spec structure.txt
+  treex verify --spec layout.json --json services/api`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runVerify(cmd.OutOrStdout(), cmd.InOrStdin(), rootArg(args))
+	},
+}
+
+func init() {
+	verifyCmd.Flags().StringVar(&verifySpec, "spec", "", "File declaring the expected structure (\"-\" for stdin)")
+	verifyCmd.Flags().StringVar(&verifySpecFormat, "spec-format", "auto", "Spec format: auto, text, json or yaml")
+	verifyCmd.Flags().BoolVar(&verifyJSON, "json", false, "Output findings as JSON")
+	_ = verifyCmd.MarkFlagRequired("spec")
+	rootCmd.AddCommand(verifyCmd)
+}
+
+// runVerify compares the tree below rootPath with the spec and reports the differences
+func runVerify(out io.Writer, in io.Reader, rootPath string) error {
+	if verifySpec != "-" {
+		file, err := appFs.Open(verifySpec)
+		if err != nil {
+			return ioError(fmt.Errorf("cannot read spec %q: %w", verifySpec, err))
+		}
+		defer file.Close()
+		in = file
+	}
+	spec, err := readTreeSpec(in, verifySpec, verifySpecFormat)
+	if err != nil {
+		return err
+	}
+	if len(spec) == 0 {
+		return fmt.Errorf("the spec has no entries")
+	}
+
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return ioError(fmt.Errorf("failed to resolve path %q: %w", rootPath, err))
+	}
+	if info, err := appFs.Stat(absRoot); err != nil || !info.IsDir() {
+		return ioError(fmt.Errorf("cannot verify %q: not an accessible directory", rootPath))
+	}
+
+	config := treex.DefaultTreeConfig(absRoot)
+	config.Filesystem = appFs
+	result, err := treex.BuildTree(config)
+	if err != nil {
+		return ioError(fmt.Errorf("failed to build tree: %w", err))
+	}
+
+	findings := verify.Compare(spec, result.Root)
+	if verifyJSON {
+		if findings == nil {
+			findings = []verify.Finding{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(findings); err != nil {
+			return err
+		}
+	} else {
+		for _, finding := range findings {
+			fmt.Fprintf(out, "%-10s  %s\n", finding.Kind, describeFinding(finding))
+		}
+	}
+
+	if len(findings) > 0 {
+		return issuesError(fmt.Errorf("%d differences from the spec", len(findings)))
+	}
+	return nil
+}
+
+// describeFinding renders the path of a finding with its details
+func describeFinding(finding verify.Finding) string {
+	name := finding.Path
+	if finding.IsDir {
+		name += "/"
+	}
+	switch finding.Kind {
+	case verify.KindMisplaced:
+		found := finding.Found
+		if finding.IsDir {
+			found += "/"
+		}
+		return fmt.Sprintf("%s (found at %s)", name, found)
+	case verify.KindWrongType:
+		if finding.IsDir {
+			return fmt.Sprintf("%s (expected a directory, found a file)", name)
+		}
+		return fmt.Sprintf("%s (expected a file, found a directory)", name)
+	}
+	return name
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+)
+
+func TestRunVerify(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"README.md": "# project",
+		"main.go":   "package main",
+		"cmd":       map[string]interface{}{},
+	})
+	fs.MustCreateTree("/specs", map[string]interface{}{
+		"layout.json": `{"README.md": "", "cmd": {"main.go": ""}}`,
+	})
+
+	originalFs := appFs
+	appFs = fs
+	defer func() {
+		appFs = originalFs
+		verifySpec, verifySpecFormat, verifyJSON = "", "auto", false
+	}()
+
+	var out bytes.Buffer
+
+	verifySpec = "/specs/layout.json"
+	err := runVerify(&out, nil, "/project")
+	assert.Equal(t, exitIssues, exitCode(err))
+	assert.Equal(t, "misplaced   cmd/main.go (found at main.go)\n", out.String())
+
+	out.Reset()
+	verifyJSON = true
+	require.Error(t, runVerify(&out, nil, "/project"))
+	assert.JSONEq(t, `[{"kind": "misplaced", "path": "cmd/main.go", "found": "main.go", "is_dir": false}]`, out.String())
+
+	out.Reset()
+	verifySpec, verifyJSON = "-", false
+	require.NoError(t, runVerify(&out, strings.NewReader("project\n├─ cmd/\n├─ main.go\n└─ README.md\n"), "/project"))
+	assert.Empty(t, out.String())
+
+	err = runVerify(&out, strings.NewReader("project\n"), "/project")
+	assert.Equal(t, exitUsage, exitCode(err))
+
+	err = runVerify(&out, strings.NewReader("project\n└─ a\n"), "/missing")
+	assert.Equal(t, exitIO, exitCode(err))
+
+	verifySpec = "/specs/missing.txt"
+	err = runVerify(&out, nil, "/project")
+	assert.Equal(t, exitIO, exitCode(err))
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+
+	projectconfig "treex/treex/config"
+	"treex/treex/types"
+)
+
+// loadWorkspace reads the --workspace file and returns its name and the paths and names
+// of its repositories. Repositories not checked out are skipped with a warning, so a
+// partial checkout of the workspace still works.
+func loadWorkspace() (label string, paths, names []string, err error) {
+	workspace, err := projectconfig.LoadWorkspace(appFs, workspaceFile)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	for _, repo := range workspace.Repos {
+		if info, err := appFs.Stat(repo.Path); err != nil || !info.IsDir() {
+			slog.Warn("skipping workspace repository that is not checked out", "repo", repo.Name, "path", repo.Path)
+			continue
+		}
+		paths = append(paths, repo.Path)
+		names = append(names, repo.Name)
+	}
+	if len(paths) == 0 {
+		return "", nil, nil, ioError(fmt.Errorf("none of the repositories of workspace %s is checked out", workspaceFile))
+	}
+	return workspace.Name, paths, names, nil
+}
+
+// prefixPaths puts prefix before the path of node and of every node below it
+func prefixPaths(node *types.Node, prefix string) {
+	node.Path = path.Join(prefix, node.Path)
+	for _, child := range node.Children {
+		prefixPaths(child, prefix)
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex"
+)
+
+// withWorkspace points appFs at a workspace of two checked-out repositories and a
+// missing one, and selects its file with --workspace
+func withWorkspace(t *testing.T) {
+	t.Helper()
+
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/src", map[string]interface{}{
+		"platform": map[string]interface{}{
+			"treex.workspace.yaml": "name: platform\nrepos:\n  - ../api\n  - path: ../web\n    name: frontend\n  - ../billing\n",
+		},
+		"api": map[string]interface{}{
+			".info": "src  API sources\n",
+			"src":   map[string]interface{}{"main.go": "package main"},
+		},
+		"web": map[string]interface{}{
+			".info":      "index.html  Page\ngone.txt  Missing\n",
+			"index.html": "<html>",
+		},
+	})
+
+	originalFs := appFs
+	appFs = fs
+	workspaceFile = "/src/platform/treex.workspace.yaml"
+	t.Cleanup(func() {
+		appFs = originalFs
+		workspaceFile = ""
+		statsJSON = false
+		checkFormat = "text"
+	})
+}
+
+func TestLoadWorkspaceSkipsMissingRepos(t *testing.T) {
+	withWorkspace(t)
+
+	label, paths, names, err := loadWorkspace()
+	require.NoError(t, err)
+	assert.Equal(t, "platform", label)
+	assert.Equal(t, []string{"/src/api", "/src/web"}, paths)
+	assert.Equal(t, []string{"api", "frontend"}, names)
+}
+
+func TestWorkspaceCheck(t *testing.T) {
+	withWorkspace(t)
+
+	var out bytes.Buffer
+	err := runCheck(&out, nil, ".")
+	require.Error(t, err)
+	assert.Equal(t, exitIssues, exitCode(err))
+	assert.Equal(t, "frontend/.info:2: frontend/gone.txt: annotated path does not exist\n", out.String())
+}
+
+func TestWorkspaceStats(t *testing.T) {
+	withWorkspace(t)
+	statsJSON = true
+
+	var out bytes.Buffer
+	require.NoError(t, runWorkspaceStats(&out))
+
+	var stats treex.StructureStats
+	require.NoError(t, json.Unmarshal(out.Bytes(), &stats))
+	assert.Equal(t, 2, stats.InfoFiles)
+	require.Len(t, stats.Coverage, 2)
+	assert.Equal(t, "api", stats.Coverage[0].Path)
+	assert.Equal(t, "frontend", stats.Coverage[1].Path)
+}
@@ -0,0 +1,162 @@
+// Package config loads the project configuration file, .treex.toml, from the root of a
+// tree. The file is optional; a missing file yields the zero Config.
+//
+//	sort              = "natural"
+//	link-template     = "https://github.com/owner/repo/blob/main/{path}"
+//	annotation-layout = "below"
+//
+//	[depth]
+//	vendor = 1
+//
+//	[badges]
+//	order    = ["git"]
+//	disabled = []
+//
+//	[[lint.rule]]
+//	id    = "services-annotated"
+//	kind  = "require-annotation"
+//	paths = ["services/*"]
+//
+//	[prose]
+//	max-length    = 80
+//	sentence-case = true
+//
+//	[variables]
+//	OWNER = "platform-team"
+//
+// LoadWorkspace reads the other file of the package, treex.workspace.yaml, which groups
+// several repositories into one workspace.
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/afero"
+)
+
+// FileName is the project configuration file, read from the tree root
+const FileName = ".treex.toml"
+
+// Config is the content of .treex.toml
+type Config struct {
+	// Sort is the default order of directory children: name, natural or locale
+	Sort string `toml:"sort"`
+
+	// LinkTemplate is the URL terminal hyperlinks of entries point to, with {path} (relative
+	// to the root) or {abspath} placeholders; empty links to the local file
+	LinkTemplate string `toml:"link-template"`
+
+	// AnnotationLayout is the default placement of annotations: aligned, inline or below
+	AnnotationLayout string `toml:"annotation-layout"`
+
+	// Depth limits how deep the tree is shown below directories (paths relative to the root)
+	Depth map[string]int `toml:"depth"`
+
+	Lint Lint `toml:"lint"`
+
+	Prose Prose `toml:"prose"`
+
+	Badges Badges `toml:"badges"`
+
+	// Variables are the values of ${NAME} annotation placeholders the environment does not set
+	Variables map[string]string `toml:"variables"`
+}
+
+// Badges selects the plugin badges shown between the tree connector and entry names
+type Badges struct {
+	Order    []string `toml:"order"`    // Plugins whose badges come first, in this order
+	Disabled []string `toml:"disabled"` // Plugins whose badges are not shown
+}
+
+// Lint configures the structure rules checked by treex lint
+type Lint struct {
+	Rules []LintRule `toml:"rule"`
+}
+
+// Prose is the annotation writing standard checked by treex check --prose
+type Prose struct {
+	MinLength    int      `toml:"min-length"`    // Fewest characters per annotation (0 = any)
+	MaxLength    int      `toml:"max-length"`    // Most characters per annotation (0 = any)
+	SentenceCase bool     `toml:"sentence-case"` // Annotations start with a capital letter
+	BannedWords  []string `toml:"banned-words"`  // Words annotations must not use
+}
+
+// LintRule is one structure rule; which fields apply depends on Kind (see package lint)
+type LintRule struct {
+	ID      string   `toml:"id"`      // Name reported with each finding
+	Kind    string   `toml:"kind"`    // Rule type
+	Paths   []string `toml:"paths"`   // Globs of the paths the rule applies to, relative to the root
+	Files   []string `toml:"files"`   // File name globs (file-location)
+	Dirs    []string `toml:"dirs"`    // Allowed parent directory name globs (file-location)
+	Message string   `toml:"message"` // Replaces the default finding message
+
+	// Line is the 1-based line of the rule's [[lint.rule]] header (0 when unknown)
+	Line int `toml:"-"`
+}
+
+// Load reads root/.treex.toml from fs
+func Load(fs afero.Fs, root string) (*Config, error) {
+	content, err := afero.ReadFile(fs, filepath.Join(root, FileName))
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+	return Parse(content)
+}
+
+// Parse decodes the content of a .treex.toml file
+func Parse(content []byte) (*Config, error) {
+	var cfg Config
+	metadata, err := toml.Decode(string(content), &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", FileName, err)
+	}
+	if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("invalid %s: unknown key %q", FileName, undecoded[0].String())
+	}
+
+	for dir, depth := range cfg.Depth {
+		if depth < 0 {
+			return nil, fmt.Errorf("invalid %s: depth of %q must not be negative", FileName, dir)
+		}
+	}
+
+	if cfg.Prose.MinLength < 0 || cfg.Prose.MaxLength < 0 {
+		return nil, fmt.Errorf("invalid %s: prose lengths must not be negative", FileName)
+	}
+	if cfg.Prose.MaxLength > 0 && cfg.Prose.MinLength > cfg.Prose.MaxLength {
+		return nil, fmt.Errorf("invalid %s: prose min-length exceeds max-length", FileName)
+	}
+
+	headers := tableHeaderLines(content, "[[lint.rule]]")
+	if len(headers) == len(cfg.Lint.Rules) {
+		for i := range cfg.Lint.Rules {
+			cfg.Lint.Rules[i].Line = headers[i]
+		}
+	}
+	return &cfg, nil
+}
+
+// tableHeaderLines returns the lines on which header appears, in order
+func tableHeaderLines(content []byte, header string) []int {
+	var lines []int
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if comment := strings.Index(text, "#"); comment >= 0 {
+			text = strings.TrimSpace(text[:comment])
+		}
+		if strings.Join(strings.Fields(text), "") == header {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
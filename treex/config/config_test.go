@@ -0,0 +1,98 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/config"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{"README.md": "# project"})
+
+	cfg, err := config.Load(fs, "/project")
+	require.NoError(t, err)
+	assert.Equal(t, &config.Config{}, cfg)
+}
+
+func TestLoadLintRules(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".treex.toml": `# Structure rules
+[[lint.rule]]
+id = "no-loose-src-files"
+kind = "no-files"
+paths = ["src"]
+
+[[ lint.rule ]] # spacing is allowed
+id = "tests-in-test-dirs"
+kind = "file-location"
+files = ["*_test.py"]
+dirs = ["tests"]
+message = "Keep tests together"
+`,
+	})
+
+	cfg, err := config.Load(fs, "/project")
+	require.NoError(t, err)
+	assert.Equal(t, []config.LintRule{
+		{ID: "no-loose-src-files", Kind: "no-files", Paths: []string{"src"}, Line: 2},
+		{ID: "tests-in-test-dirs", Kind: "file-location", Files: []string{"*_test.py"}, Dirs: []string{"tests"},
+			Message: "Keep tests together", Line: 7},
+	}, cfg.Lint.Rules)
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := config.Parse([]byte("[lint\n"))
+	assert.ErrorContains(t, err, "invalid .treex.toml")
+
+	_, err = config.Parse([]byte("[[lint.rule]]\nid = \"x\"\npath = [\"src\"]\n"))
+	assert.ErrorContains(t, err, `unknown key "lint.rule.path"`)
+}
+
+func TestParseDepth(t *testing.T) {
+	cfg, err := config.Parse([]byte("[depth]\nvendor = 1\n\"third_party/go\" = 0\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"vendor": 1, "third_party/go": 0}, cfg.Depth)
+
+	_, err = config.Parse([]byte("[depth]\nvendor = -1\n"))
+	assert.ErrorContains(t, err, `depth of "vendor" must not be negative`)
+}
+
+func TestParseSort(t *testing.T) {
+	cfg, err := config.Parse([]byte("sort = \"natural\"\nlink-template = \"https://example.com/{path}\"\n\n[depth]\nvendor = 1\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "natural", cfg.Sort)
+	assert.Equal(t, "https://example.com/{path}", cfg.LinkTemplate)
+	assert.Equal(t, map[string]int{"vendor": 1}, cfg.Depth)
+}
+
+func TestParseProse(t *testing.T) {
+	cfg, err := config.Parse([]byte("[prose]\nmin-length = 10\nmax-length = 80\nsentence-case = true\nbanned-words = [\"simply\", \"just\"]\n"))
+	require.NoError(t, err)
+	assert.Equal(t, config.Prose{MinLength: 10, MaxLength: 80, SentenceCase: true, BannedWords: []string{"simply", "just"}}, cfg.Prose)
+
+	_, err = config.Parse([]byte("[prose]\nmin-length = 90\nmax-length = 80\n"))
+	assert.ErrorContains(t, err, "min-length exceeds max-length")
+}
+
+func TestParseBadges(t *testing.T) {
+	cfg, err := config.Parse([]byte("[badges]\norder = [\"git\"]\ndisabled = [\"owners\"]\n"))
+	require.NoError(t, err)
+	assert.Equal(t, config.Badges{Order: []string{"git"}, Disabled: []string{"owners"}}, cfg.Badges)
+}
+
+func TestParseAnnotationLayout(t *testing.T) {
+	cfg, err := config.Parse([]byte("annotation-layout = \"below\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "below", cfg.AnnotationLayout)
+}
+
+func TestParseVariables(t *testing.T) {
+	cfg, err := config.Parse([]byte("[variables]\nOWNER = \"platform-team\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"OWNER": "platform-team"}, cfg.Variables)
+}
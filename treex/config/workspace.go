@@ -0,0 +1,90 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// WorkspaceFileName is the workspace file treex --workspace reads when given no file
+const WorkspaceFileName = "treex.workspace.yaml"
+
+// Workspace lists repositories documented as one system, shown and checked together:
+//
+//	name: platform
+//	repos:
+//	  - ../api
+//	  - path: ../web-frontend
+//	    name: web
+type Workspace struct {
+	Name  string          `yaml:"name"`  // Label of the combined tree (default: the file's directory name)
+	Repos []WorkspaceRepo `yaml:"repos"` // Repository roots, in display order
+}
+
+// WorkspaceRepo is one repository of a workspace, written as its path or as a mapping
+type WorkspaceRepo struct {
+	Path string `yaml:"path"` // Root directory, relative to the workspace file
+	Name string `yaml:"name"` // Name shown in the combined tree (default: the directory name)
+}
+
+// UnmarshalYAML accepts a bare path as well as a path and name mapping
+func (r *WorkspaceRepo) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&r.Path)
+	}
+	type plain WorkspaceRepo // Without this method, so the mapping decodes normally
+	return value.Decode((*plain)(r))
+}
+
+// LoadWorkspace reads the workspace file at path from fs. Repository paths are made
+// absolute, relative to the file's directory, and names default to directory names.
+func LoadWorkspace(fs afero.Fs, path string) (*Workspace, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workspace %q: %w", path, err)
+	}
+	content, err := afero.ReadFile(fs, absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace %s: %w", path, err)
+	}
+
+	var workspace Workspace
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&workspace); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid workspace %s: %w", path, err)
+	}
+	if len(workspace.Repos) == 0 {
+		return nil, fmt.Errorf("invalid workspace %s: no repos listed", path)
+	}
+
+	dir := filepath.Dir(absPath)
+	if workspace.Name == "" {
+		workspace.Name = filepath.Base(dir)
+	}
+	names := make(map[string]bool, len(workspace.Repos))
+	for i := range workspace.Repos {
+		repo := &workspace.Repos[i]
+		if strings.TrimSpace(repo.Path) == "" {
+			return nil, fmt.Errorf("invalid workspace %s: repo %d has no path", path, i+1)
+		}
+		if !filepath.IsAbs(repo.Path) {
+			repo.Path = filepath.Join(dir, filepath.FromSlash(repo.Path))
+		}
+		repo.Path = filepath.Clean(repo.Path)
+		if repo.Name == "" {
+			repo.Name = filepath.Base(repo.Path)
+		}
+		if names[repo.Name] {
+			return nil, fmt.Errorf("invalid workspace %s: two repos are named %q (set name:)", path, repo.Name)
+		}
+		names[repo.Name] = true
+	}
+	return &workspace, nil
+}
@@ -0,0 +1,49 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/config"
+)
+
+func TestLoadWorkspace(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/src/platform", map[string]interface{}{
+		"treex.workspace.yaml": "repos:\n  - ../api\n  - path: ../web-frontend\n    name: web\n  - /opt/infra\n",
+	})
+
+	workspace, err := config.LoadWorkspace(fs, "/src/platform/treex.workspace.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, &config.Workspace{
+		Name: "platform",
+		Repos: []config.WorkspaceRepo{
+			{Path: "/src/api", Name: "api"},
+			{Path: "/src/web-frontend", Name: "web"},
+			{Path: "/opt/infra", Name: "infra"},
+		},
+	}, workspace)
+}
+
+func TestLoadWorkspaceErrors(t *testing.T) {
+	tests := map[string]string{
+		"empty":          "",
+		"no repos":       "name: platform\n",
+		"unknown key":    "repos: [api]\nowner: me\n",
+		"missing path":   "repos:\n  - name: api\n",
+		"duplicate name": "repos:\n  - one/api\n  - two/api\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			fs := testutil.NewTestFS()
+			fs.MustCreateTree("/ws", map[string]interface{}{"treex.workspace.yaml": content})
+			_, err := config.LoadWorkspace(fs, "/ws/treex.workspace.yaml")
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := config.LoadWorkspace(testutil.NewTestFS(), "/missing.yaml")
+	assert.Error(t, err)
+}
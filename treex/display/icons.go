@@ -0,0 +1,217 @@
+// Package display provides presentation helpers independent of the output format:
+// file-type icons drawn before node names, and the scan progress spinner.
+// Icons are chosen by exact file name first, then by extension, then by node kind.
+package display
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+	"treex/treex/pathutil"
+)
+
+// IconSet selects which icon glyphs are drawn in front of node names
+type IconSet string
+
+const (
+	IconsNone  IconSet = "none"  // No icons
+	IconsNerd  IconSet = "nerd"  // Nerd Font glyphs (requires a patched font)
+	IconsEmoji IconSet = "emoji" // Emoji, available in most terminal fonts
+)
+
+// ParseIconSet validates an --icons value; an empty value means none
+func ParseIconSet(value string) (IconSet, error) {
+	switch set := IconSet(strings.ToLower(strings.TrimSpace(value))); set {
+	case "", IconsNone:
+		return IconsNone, nil
+	case IconsNerd, IconsEmoji:
+		return set, nil
+	default:
+		return "", fmt.Errorf("unknown icon set %q (valid: none, nerd, emoji)", value)
+	}
+}
+
+// IconMap maps node names and extensions to icons
+// Names and extensions are matched case-insensitively; extensions have no leading dot
+type IconMap struct {
+	Directory  string            `yaml:"directory"`
+	File       string            `yaml:"file"`
+	Names      map[string]string `yaml:"names"`
+	Extensions map[string]string `yaml:"extensions"`
+}
+
+// Icon returns the icon for a node
+func (m *IconMap) Icon(name string, isDir bool) string {
+	lower := strings.ToLower(name)
+	if icon, ok := m.Names[lower]; ok {
+		return icon
+	}
+	if isDir {
+		return m.Directory
+	}
+
+	if ext := strings.TrimPrefix(path.Ext(lower), "."); ext != "" {
+		if icon, ok := m.Extensions[ext]; ok {
+			return icon
+		}
+	}
+	return m.File
+}
+
+// Merge applies overrides on top of this map; empty override values are ignored
+func (m *IconMap) Merge(overrides IconMap) {
+	if overrides.Directory != "" {
+		m.Directory = overrides.Directory
+	}
+	if overrides.File != "" {
+		m.File = overrides.File
+	}
+	for name, icon := range overrides.Names {
+		m.Names[strings.ToLower(name)] = icon
+	}
+	for ext, icon := range overrides.Extensions {
+		m.Extensions[strings.ToLower(strings.TrimPrefix(ext, "."))] = icon
+	}
+}
+
+// BuiltinIcons returns a fresh copy of the builtin map for an icon set
+// IconsNone (and unknown sets) return nil
+func BuiltinIcons(set IconSet) *IconMap {
+	switch set {
+	case IconsNerd:
+		return &IconMap{
+			Directory: "\uf07b",
+			File:      "\uf15b",
+			Names: map[string]string{
+				".git":       "\ue5fb",
+				".gitignore": "\ue702",
+				"dockerfile": "\uf308",
+				"makefile":   "\ue779",
+				"license":    "\ue60a",
+				"go.mod":     "\ue627",
+				"go.sum":     "\ue627",
+			},
+			Extensions: map[string]string{
+				"go":   "\ue627",
+				"py":   "\ue606",
+				"js":   "\ue74e",
+				"ts":   "\ue628",
+				"rs":   "\ue7a8",
+				"rb":   "\ue739",
+				"java": "\ue738",
+				"c":    "\ue61e",
+				"h":    "\uf0fd",
+				"sh":   "\uf489",
+				"html": "\ue736",
+				"css":  "\ue749",
+				"md":   "\uf48a",
+				"txt":  "\uf15c",
+				"info": "\uf05a",
+				"json": "\ue60b",
+				"yaml": "\uf481",
+				"yml":  "\uf481",
+				"toml": "\ue615",
+				"lock": "\uf023",
+				"png":  "\uf1c5",
+				"jpg":  "\uf1c5",
+				"gif":  "\uf1c5",
+				"svg":  "\uf1c5",
+				"zip":  "\uf410",
+				"tar":  "\uf410",
+				"gz":   "\uf410",
+			},
+		}
+	case IconsEmoji:
+		return &IconMap{
+			Directory: "📁",
+			File:      "📄",
+			Names: map[string]string{
+				".git":       "🌱",
+				"dockerfile": "🐳",
+				"makefile":   "🔨",
+				"license":    "📜",
+			},
+			Extensions: map[string]string{
+				"go":   "🐹",
+				"py":   "🐍",
+				"js":   "📜",
+				"ts":   "📜",
+				"rs":   "🦀",
+				"rb":   "💎",
+				"sh":   "🐚",
+				"html": "🌐",
+				"css":  "🎨",
+				"md":   "📝",
+				"txt":  "📄",
+				"info": "💡",
+				"json": "🔧",
+				"yaml": "🔧",
+				"yml":  "🔧",
+				"toml": "🔧",
+				"lock": "🔒",
+				"png":  "🎨",
+				"jpg":  "🎨",
+				"gif":  "🎨",
+				"svg":  "🎨",
+				"zip":  "📦",
+				"tar":  "📦",
+				"gz":   "📦",
+			},
+		}
+	default:
+		return nil
+	}
+}
+
+// IconOverrides holds user overrides per icon set, as read from the icons file:
+//
+//	nerd:
+//	  extensions: { proto: "\ue60c" }
+//	emoji:
+//	  directory: "🗂"
+//	  names: { justfile: "🤖" }
+type IconOverrides map[IconSet]IconMap
+
+// LoadIconOverrides reads the user icons file; a missing file yields no overrides
+func LoadIconOverrides(fs afero.Fs, path string) (IconOverrides, error) {
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read icons file %s: %w", path, err)
+	}
+
+	var overrides IconOverrides
+	if err := yaml.Unmarshal(content, &overrides); err != nil {
+		return nil, fmt.Errorf("%s: invalid icons definition: %w", path, err)
+	}
+	for set := range overrides {
+		if set != IconsNerd && set != IconsEmoji {
+			return nil, fmt.Errorf("%s: unknown icon set %q (valid: nerd, emoji)", path, set)
+		}
+	}
+	return overrides, nil
+}
+
+// NewIconMap returns the builtin map for set with user overrides applied
+// It returns nil for IconsNone
+func NewIconMap(set IconSet, overrides IconOverrides) *IconMap {
+	icons := BuiltinIcons(set)
+	if icons == nil {
+		return nil
+	}
+	if override, ok := overrides[set]; ok {
+		icons.Merge(override)
+	}
+	return icons
+}
+
+// DefaultIconsFile returns the user icons file using the XDG config directory
+func DefaultIconsFile() string {
+	return pathutil.ConfigPath("icons.yaml")
+}
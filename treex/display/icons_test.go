@@ -0,0 +1,97 @@
+package display_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex/display"
+)
+
+func TestParseIconSet(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    display.IconSet
+		expectError bool
+	}{
+		{"", display.IconsNone, false},
+		{"none", display.IconsNone, false},
+		{"nerd", display.IconsNerd, false},
+		{"Emoji", display.IconsEmoji, false},
+		{"ascii", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			set, err := display.ParseIconSet(tt.value)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, set)
+		})
+	}
+}
+
+func TestIconMapLookup(t *testing.T) {
+	icons := display.BuiltinIcons(display.IconsEmoji)
+	require.NotNil(t, icons)
+
+	tests := []struct {
+		name     string
+		isDir    bool
+		expected string
+	}{
+		{"src", true, "📁"},
+		{"main.go", false, "🐹"},
+		{"MAIN.GO", false, "🐹"},
+		{"Dockerfile", false, "🐳"},
+		{"unknown.xyz", false, "📄"},
+		{"noext", false, "📄"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, icons.Icon(tt.name, tt.isDir))
+		})
+	}
+
+	assert.Nil(t, display.BuiltinIcons(display.IconsNone))
+}
+
+func TestLoadIconOverrides(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/config/icons.yaml", []byte(`
+emoji:
+  directory: "🗂"
+  names: { Justfile: "🤖" }
+  extensions: { .go: "G" }
+`), 0644))
+
+	overrides, err := display.LoadIconOverrides(fs, "/config/icons.yaml")
+	require.NoError(t, err)
+
+	icons := display.NewIconMap(display.IconsEmoji, overrides)
+	assert.Equal(t, "🗂", icons.Icon("src", true))
+	assert.Equal(t, "🤖", icons.Icon("justfile", false))
+	assert.Equal(t, "G", icons.Icon("main.go", false))
+	assert.Equal(t, "🐍", icons.Icon("app.py", false), "builtin entries are kept")
+
+	// Overrides for other sets do not leak
+	nerd := display.NewIconMap(display.IconsNerd, overrides)
+	assert.NotEqual(t, "G", nerd.Icon("main.go", false))
+
+	t.Run("missing file", func(t *testing.T) {
+		overrides, err := display.LoadIconOverrides(fs, "/config/missing.yaml")
+		assert.NoError(t, err)
+		assert.Nil(t, overrides)
+	})
+
+	t.Run("unknown set", func(t *testing.T) {
+		require.NoError(t, afero.WriteFile(fs, "/config/bad.yaml", []byte("ascii:\n  file: x\n"), 0644))
+		_, err := display.LoadIconOverrides(fs, "/config/bad.yaml")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,73 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Progress timing: scans finishing within ProgressDelay never show a spinner,
+// and the line is redrawn at most once per progressInterval
+const (
+	ProgressDelay    = time.Second
+	progressInterval = 100 * time.Millisecond
+)
+
+// spinnerFrames are drawn in turn on each redraw
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Spinner draws "⠋ scanned 124,000 files" on a single terminal line while a walk runs
+// It implements the tree builder's ProgressReporter and erases its line when done
+type Spinner struct {
+	out     io.Writer
+	delay   time.Duration
+	start   time.Time
+	drawn   time.Time // Last redraw; zero until the spinner first appears
+	frame   int
+	visible bool
+}
+
+// NewSpinner creates a spinner writing to out (normally stderr) that appears after delay
+func NewSpinner(out io.Writer, delay time.Duration) *Spinner {
+	return &Spinner{out: out, delay: delay, start: time.Now()}
+}
+
+// Scanned redraws the progress line once the delay has passed, rate limited
+func (s *Spinner) Scanned(count int) {
+	now := time.Now()
+	if now.Sub(s.start) < s.delay || now.Sub(s.drawn) < progressInterval {
+		return
+	}
+
+	s.drawn = now
+	s.visible = true
+	fmt.Fprintf(s.out, "\r\033[K%s scanned %s files", spinnerFrames[s.frame], FormatCount(count))
+	s.frame = (s.frame + 1) % len(spinnerFrames)
+}
+
+// Done erases the progress line if it was drawn, leaving the terminal clean for output
+func (s *Spinner) Done(count int) {
+	if s.visible {
+		fmt.Fprint(s.out, "\r\033[K")
+		s.visible = false
+	}
+}
+
+// FormatCount formats n with thousands separators (124000 -> "124,000")
+func FormatCount(n int) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var out []byte
+	for i := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out = append(out, ',')
+		}
+		out = append(out, digits[i])
+	}
+	return sign + string(out)
+}
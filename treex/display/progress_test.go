@@ -0,0 +1,52 @@
+package display_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"treex/treex/display"
+)
+
+func TestFormatCount(t *testing.T) {
+	tests := map[int]string{
+		0:         "0",
+		999:       "999",
+		1000:      "1,000",
+		124000:    "124,000",
+		1234567:   "1,234,567",
+		-1234:     "-1,234",
+		100000000: "100,000,000",
+	}
+	for n, expected := range tests {
+		assert.Equal(t, expected, display.FormatCount(n))
+	}
+}
+
+func TestSpinnerDrawsAfterDelayAndClears(t *testing.T) {
+	var out bytes.Buffer
+	spinner := display.NewSpinner(&out, 0)
+
+	spinner.Scanned(124000)
+	assert.Equal(t, "\r\033[K⠋ scanned 124,000 files", out.String())
+
+	// Redraws are rate limited
+	spinner.Scanned(124001)
+	assert.Equal(t, "\r\033[K⠋ scanned 124,000 files", out.String())
+
+	out.Reset()
+	spinner.Done(124001)
+	assert.Equal(t, "\r\033[K", out.String())
+}
+
+func TestSpinnerSilentForQuickScans(t *testing.T) {
+	var out bytes.Buffer
+	spinner := display.NewSpinner(&out, time.Hour)
+
+	for i := 1; i <= 1000; i++ {
+		spinner.Scanned(i)
+	}
+	spinner.Done(1000)
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,159 @@
+package treex
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/spf13/afero"
+	"treex/treex/types"
+)
+
+// DuplicateGroup is a set of files with identical contents
+type DuplicateGroup struct {
+	Hash  string   `json:"hash"`  // xxhash64 of the contents, in hex
+	Size  int64    `json:"size"`  // Size of each copy in bytes
+	Paths []string `json:"paths"` // Slash-separated paths relative to the root, sorted
+}
+
+// Wasted returns the bytes the extra copies of the group take
+func (g DuplicateGroup) Wasted() int64 {
+	return g.Size * int64(len(g.Paths)-1)
+}
+
+// DuplicateReport holds the duplicate files of a tree (treex dupes)
+type DuplicateReport struct {
+	Groups      []DuplicateGroup `json:"groups"`      // Largest waste first
+	Files       int              `json:"files"`       // Files in all groups
+	Reclaimable int64            `json:"reclaimable"` // Bytes freed by keeping one copy per group
+}
+
+// FindDuplicates groups the files of the tree rooted at root whose contents are identical
+// root was built from absRoot on fs, so it reflects the filters the tree was built with.
+// Only files of at least minSize bytes are compared (empty files never are); files are
+// first grouped by size, and only sizes shared by several files are hashed, concurrently.
+// Groups are ordered by the space their extra copies waste, largest first.
+func FindDuplicates(ctx context.Context, fs afero.Fs, absRoot string, root *types.Node, minSize int64) (*DuplicateReport, error) {
+	bySize := make(map[int64][]string)
+	_ = types.WalkTree(root, func(node *types.Node, depth int) error {
+		if !node.IsDir && node.Size > 0 && node.Size >= minSize && node.Mode.IsRegular() {
+			bySize[node.Size] = append(bySize[node.Size], filepath.ToSlash(node.Path))
+		}
+		return nil
+	})
+
+	type candidate struct {
+		path string
+		size int64
+		hash string
+	}
+	var candidates []*candidate
+	for size, paths := range bySize {
+		if len(paths) > 1 {
+			for _, candidatePath := range paths {
+				candidates = append(candidates, &candidate{path: candidatePath, size: size})
+			}
+		}
+	}
+
+	// Hash the candidates on a worker per CPU; each worker fills in its own candidates
+	jobs := make(chan *candidate)
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+	for range min(runtime.NumCPU(), max(len(candidates), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				hash, err := hashFile(fs, filepath.Join(absRoot, filepath.FromSlash(job.path)))
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					continue
+				}
+				job.hash = hash
+			}
+		}()
+	}
+	for _, job := range candidates {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- job
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	select {
+	case err := <-errs:
+		return nil, err
+	default:
+	}
+
+	type key struct {
+		size int64
+		hash string
+	}
+	byContent := make(map[key]*DuplicateGroup)
+	for _, c := range candidates {
+		k := key{c.size, c.hash}
+		if byContent[k] == nil {
+			byContent[k] = &DuplicateGroup{Hash: c.hash, Size: c.size}
+		}
+		byContent[k].Paths = append(byContent[k].Paths, c.path)
+	}
+
+	report := &DuplicateReport{Groups: []DuplicateGroup{}}
+	for _, group := range byContent {
+		if len(group.Paths) > 1 {
+			sort.Strings(group.Paths)
+			report.Groups = append(report.Groups, *group)
+			report.Files += len(group.Paths)
+			report.Reclaimable += group.Wasted()
+		}
+	}
+	groups := report.Groups
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Wasted() != groups[j].Wasted() {
+			return groups[i].Wasted() > groups[j].Wasted()
+		}
+		return groups[i].Paths[0] < groups[j].Paths[0]
+	})
+	return report, nil
+}
+
+// hashFile returns the xxhash64 of the contents of the file at path, in hex
+func hashFile(fs afero.Fs, path string) (string, error) {
+	file, err := fs.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	digest := xxhash.New()
+	if _, err := io.Copy(digest, file); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return fmt.Sprintf("%016x", digest.Sum64()), nil
+}
+
+// PruneToDuplicates removes the entries of the tree rooted at root that are neither in a
+// group of the report nor lead to one
+func PruneToDuplicates(root *types.Node, report *DuplicateReport) {
+	duplicates := make(map[string]bool)
+	for _, group := range report.Groups {
+		for _, groupPath := range group.Paths {
+			duplicates[groupPath] = true
+		}
+	}
+	pruneTree(root, func(node *types.Node) bool { return duplicates[filepath.ToSlash(node.Path)] }, make(map[string]bool))
+}
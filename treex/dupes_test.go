@@ -0,0 +1,64 @@
+package treex
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/types"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/test", map[string]interface{}{
+		"a.txt":     "hello world",
+		"b.txt":     "hello world",
+		"other.txt": "hello there", // Same size, other contents
+		"empty.txt": "",
+		"copy.txt":  "",
+		"src": map[string]interface{}{
+			"logo.png": "binary-contents-binary",
+			"main.go":  "package main",
+		},
+		"assets": map[string]interface{}{
+			"logo.png":  "binary-contents-binary",
+			"logo2.png": "binary-contents-binary",
+		},
+	})
+
+	result, err := BuildTree(TreeConfig{Root: "/test", Filesystem: fs})
+	require.NoError(t, err)
+
+	report, err := FindDuplicates(context.Background(), fs, "/test", result.Root, 0)
+	require.NoError(t, err)
+	require.Len(t, report.Groups, 2, "empty files are never duplicates")
+	assert.Equal(t, []string{"assets/logo.png", "assets/logo2.png", "src/logo.png"}, report.Groups[0].Paths, "largest waste first")
+	assert.Equal(t, int64(22), report.Groups[0].Size)
+	assert.Len(t, report.Groups[0].Hash, 16)
+	assert.Equal(t, []string{"a.txt", "b.txt"}, report.Groups[1].Paths)
+	assert.Equal(t, 5, report.Files)
+	assert.Equal(t, int64(2*22+11), report.Reclaimable)
+
+	PruneToDuplicates(result.Root, report)
+	var paths []string
+	var walk func(node *types.Node)
+	walk = func(node *types.Node) {
+		paths = append(paths, node.Path)
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(result.Root)
+	assert.ElementsMatch(t, []string{".", "a.txt", "b.txt", "assets", "assets/logo.png", "assets/logo2.png", "src", "src/logo.png"}, paths)
+
+	report, err = FindDuplicates(context.Background(), fs, "/test", result.Root, 20)
+	require.NoError(t, err)
+	require.Len(t, report.Groups, 1, "--min-size skips smaller files")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = FindDuplicates(ctx, fs, "/test", result.Root, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,127 @@
+// Package expand fills placeholders in annotation text when a tree is shown, so
+// annotations can name details that differ between environments:
+//
+//	deploy/   Deploys to ${DEPLOY_ENV} from {{.GitBranch}}
+//
+// ${NAME} is replaced by the environment variable TREEX_VAR_NAME or, when it is unset, by
+// the [variables] table of .treex.toml; $${NAME} is written as ${NAME}. Only variables
+// with the EnvPrefix are read, so an annotation cannot print a token from the environment. {{.Date}} and
+// {{.Year}} give the current date, {{.GitBranch}} and {{.GitCommit}} the repository's
+// HEAD, {{.Name}} and {{.Path}} the annotated entry. Placeholders without a value are
+// left as written, and .info files are never changed: only the text shown is.
+package expand
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"treex/treex/types"
+)
+
+var (
+	// variablePattern matches ${NAME} and its escaped form $${NAME}
+	variablePattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	// fieldPattern matches {{.Field}}, with optional spaces inside the braces
+	fieldPattern = regexp.MustCompile(`\{\{\s*\.([A-Za-z]+)\s*\}\}`)
+)
+
+// EnvPrefix starts the names of the environment variables ${NAME} can read
+const EnvPrefix = "TREEX_VAR_"
+
+// Values are what placeholders expand to
+type Values struct {
+	Getenv    func(string) string // Environment lookup, of EnvPrefix names only (nil = no environment)
+	Variables map[string]string   // [variables] from .treex.toml, for names the environment lacks
+	Now       time.Time           // Date and Year
+
+	// Git returns the checked-out branch ("" when HEAD is detached) and abbreviated commit
+	// of the tree's repository; called once, when a placeholder first needs it (nil = none)
+	Git func() (branch, commit string)
+}
+
+// Tree expands the annotation of every node below and including root
+func Tree(root *types.Node, values Values) {
+	expander := newExpander(values)
+	var walk func(node *types.Node)
+	walk = func(node *types.Node) {
+		if annotation := node.GetAnnotation(); annotation != nil {
+			annotation.Notes = expander.expand(annotation.Notes, node)
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	if root != nil {
+		walk(root)
+	}
+}
+
+// expander expands text with values, reading the repository at most once
+type expander struct {
+	values Values
+	git    func() (string, string)
+}
+
+func newExpander(values Values) *expander {
+	e := &expander{values: values}
+	if values.Git != nil {
+		e.git = sync.OnceValues(values.Git)
+	}
+	return e
+}
+
+// expand replaces the placeholders of the annotation of node
+func (e *expander) expand(text string, node *types.Node) string {
+	if strings.Contains(text, "${") {
+		text = variablePattern.ReplaceAllStringFunc(text, func(match string) string {
+			if strings.HasPrefix(match, "$$") {
+				return match[1:]
+			}
+			name := match[2 : len(match)-1]
+			if e.values.Getenv != nil {
+				if value := e.values.Getenv(EnvPrefix + name); value != "" {
+					return value
+				}
+			}
+			if value, ok := e.values.Variables[name]; ok {
+				return value
+			}
+			return match
+		})
+	}
+	if strings.Contains(text, "{{") {
+		text = fieldPattern.ReplaceAllStringFunc(text, func(match string) string {
+			if value, ok := e.field(fieldPattern.FindStringSubmatch(match)[1], node); ok {
+				return value
+			}
+			return match
+		})
+	}
+	return text
+}
+
+// field returns the value of a {{.Field}} placeholder, reporting false when it has none
+func (e *expander) field(name string, node *types.Node) (string, bool) {
+	switch name {
+	case "Date":
+		return e.values.Now.Format(time.DateOnly), !e.values.Now.IsZero()
+	case "Year":
+		return e.values.Now.Format("2006"), !e.values.Now.IsZero()
+	case "GitBranch", "GitCommit":
+		if e.git == nil {
+			return "", false
+		}
+		branch, commit := e.git()
+		if name == "GitBranch" {
+			return branch, branch != ""
+		}
+		return commit, commit != ""
+	case "Name":
+		return node.Name, true
+	case "Path":
+		return node.Path, true
+	}
+	return "", false
+}
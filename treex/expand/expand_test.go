@@ -0,0 +1,62 @@
+package expand_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"treex/treex/expand"
+	"treex/treex/types"
+)
+
+// annotated returns a node with an annotation
+func annotated(name, path, notes string) *types.Node {
+	node := &types.Node{Name: name, Path: path}
+	node.SetAnnotation(&types.Annotation{Path: path, Notes: notes})
+	return node
+}
+
+func TestTree(t *testing.T) {
+	env := map[string]string{"TREEX_VAR_DEPLOY_ENV": "staging", "TREEX_VAR_OWNER": "alice", "REGION": "us-east-1", "TOKEN": "hunter2"}
+	gitReads := 0
+	values := expand.Values{
+		Getenv:    func(name string) string { return env[name] },
+		Variables: map[string]string{"OWNER": "platform-team", "REGION": "eu-west-1"},
+		Now:       time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC),
+		Git: func() (string, string) {
+			gitReads++
+			return "main", "abc1234"
+		},
+	}
+
+	tests := []struct {
+		notes    string
+		expected string
+	}{
+		{"Deploys to ${DEPLOY_ENV} in ${REGION}", "Deploys to staging in eu-west-1"},
+		{"Owned by ${OWNER}", "Owned by alice"},
+		{"Built from {{.GitBranch}} at {{ .GitCommit }}", "Built from main at abc1234"},
+		{"Reviewed {{.Date}} ({{.Year}})", "Reviewed 2026-03-14 (2026)"},
+		{"{{.Name}} lives at {{.Path}}", "deploy.sh lives at scripts/deploy.sh"},
+		{"Reads $${HOME} and ${UNSET} and {{.Unknown}}", "Reads ${HOME} and ${UNSET} and {{.Unknown}}"},
+		{"token=${TOKEN}", "token=${TOKEN}"}, // Only TREEX_VAR_ variables are read
+		{"No placeholders", "No placeholders"},
+	}
+
+	root := &types.Node{Name: "project", IsDir: true}
+	for _, tt := range tests {
+		root.Children = append(root.Children, annotated("deploy.sh", "scripts/deploy.sh", tt.notes))
+	}
+	expand.Tree(root, values)
+
+	for i, tt := range tests {
+		assert.Equal(t, tt.expected, root.Children[i].GetAnnotation().Notes, tt.notes)
+	}
+	assert.Equal(t, 1, gitReads, "the repository is read once")
+}
+
+func TestTreeWithoutValues(t *testing.T) {
+	node := annotated("main.go", "main.go", "{{.GitBranch}} on {{.Date}}")
+	expand.Tree(node, expand.Values{})
+	assert.Equal(t, "{{.GitBranch}} on {{.Date}}", node.GetAnnotation().Notes)
+}
@@ -0,0 +1,198 @@
+package harvest
+
+import (
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// readmeNames are the README files looked up in a directory, in order of preference
+var readmeNames = []string{"README.md", "README.markdown", "README", "README.txt", "readme.md", "Readme.md"}
+
+// markdownLink matches [text](target) and ![alt](image) so only the text is kept
+var markdownLink = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+
+// readmeSummary returns the first sentence of the first paragraph of dir's README,
+// falling back to its first heading when the README has no prose
+func readmeSummary(fs afero.Fs, dir string) string {
+	for _, name := range readmeNames {
+		content, err := afero.ReadFile(fs, filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		return markdownSummary(string(content))
+	}
+	return ""
+}
+
+// markdownSummary extracts the summary sentence of a markdown document
+func markdownSummary(content string) string {
+	var heading string
+	var paragraph []string
+	inFence, inFrontMatter := false, false
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case i == 0 && trimmed == "---":
+			inFrontMatter = true
+			continue
+		case inFrontMatter:
+			inFrontMatter = trimmed != "---"
+			continue
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			inFence = !inFence
+			continue
+		case inFence:
+			continue
+		}
+
+		if trimmed == "" {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+
+		// Headings: ATX ("# Title") and setext (a line underlined with === or ---)
+		if strings.HasPrefix(trimmed, "#") {
+			if len(paragraph) > 0 {
+				break
+			}
+			if heading == "" {
+				heading = strings.TrimSpace(strings.Trim(trimmed, "#"))
+			}
+			continue
+		}
+		if i+1 < len(lines) && len(paragraph) == 0 && isSetextUnderline(lines[i+1]) {
+			if heading == "" {
+				heading = trimmed
+			}
+			continue
+		}
+		if isSetextUnderline(line) {
+			continue
+		}
+
+		// Badges, images, HTML and list items are not prose
+		if strings.HasPrefix(trimmed, "[![") || strings.HasPrefix(trimmed, "![") || strings.HasPrefix(trimmed, "<") ||
+			strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "|") {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, trimmed)
+	}
+
+	if sentence := firstSentence(cleanMarkdown(strings.Join(paragraph, " "))); sentence != "" {
+		return sentence
+	}
+	return cleanMarkdown(heading)
+}
+
+// isSetextUnderline reports whether line underlines a setext heading
+func isSetextUnderline(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return len(trimmed) >= 3 && (strings.Trim(trimmed, "=") == "" || strings.Trim(trimmed, "-") == "")
+}
+
+// cleanMarkdown removes inline markup: links keep their text, emphasis and code marks are dropped
+func cleanMarkdown(text string) string {
+	text = markdownLink.ReplaceAllString(text, "$1")
+	return strings.NewReplacer("**", "", "__", "", "`", "").Replace(text)
+}
+
+// firstSentence returns text up to the first sentence end (". ", "! " or "? ")
+func firstSentence(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	for i := 0; i < len(text)-1; i++ {
+		if (text[i] == '.' || text[i] == '!' || text[i] == '?') && text[i+1] == ' ' {
+			return text[:i+1]
+		}
+	}
+	return text
+}
+
+// goPackageDoc returns the synopsis of the package doc comment in dir, preferring doc.go
+func goPackageDoc(fs afero.Fs, dir string) string {
+	entries, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return ""
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			files = append(files, name)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i] == "doc.go" && files[j] != "doc.go" })
+
+	for _, name := range files {
+		content, err := afero.ReadFile(fs, filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), name, content, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil || file.Doc == nil {
+			continue
+		}
+		if synopsis := new(doc.Package).Synopsis(file.Doc.Text()); synopsis != "" {
+			return synopsis
+		}
+	}
+	return ""
+}
+
+// pythonDocstring returns the first sentence of a Python module docstring
+func pythonDocstring(fs afero.Fs, file string) string {
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return ""
+	}
+
+	// The docstring is the first statement: skip blank lines and comments (shebang, encoding)
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	for {
+		text = strings.TrimLeft(text, " \t\n")
+		if !strings.HasPrefix(text, "#") {
+			break
+		}
+		newline := strings.IndexByte(text, '\n')
+		if newline < 0 {
+			return ""
+		}
+		text = text[newline+1:]
+	}
+
+	if len(text) > 0 && strings.ContainsRune("rRuU", rune(text[0])) {
+		text = text[1:]
+	}
+	for _, quote := range []string{`"""`, `'''`, `"`, `'`} {
+		if !strings.HasPrefix(text, quote) {
+			continue
+		}
+		body := text[len(quote):]
+		end := strings.Index(body, quote)
+		if end < 0 {
+			return ""
+		}
+		// The summary is the first paragraph's first sentence
+		summary := body[:end]
+		if paragraph := strings.Index(strings.TrimSpace(summary), "\n\n"); paragraph >= 0 {
+			summary = strings.TrimSpace(summary)[:paragraph]
+		}
+		return firstSentence(summary)
+	}
+	return ""
+}
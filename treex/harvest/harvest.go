@@ -0,0 +1,106 @@
+// Package harvest derives candidate annotations from documentation that already exists
+// in a project: the opening of a directory's README, Go package doc comments and Python
+// module docstrings.
+package harvest
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"treex/treex/pattern"
+	"treex/treex/plugins/infofile"
+)
+
+// Sources recorded in the generated marker of each annotation
+const (
+	SourceReadme    = "readme"
+	SourceGoDoc     = "go-doc"
+	SourceDocstring = "docstring"
+)
+
+// maxAnnotationRunes bounds harvested text; longer sentences are cut at a word boundary
+const maxAnnotationRunes = 120
+
+// Harvest walks root and returns one candidate annotation per documented path, in path order
+//
+// A directory takes its Go package doc comment, else the first sentence of its README, else
+// the docstring of its __init__.py; Python files take their module docstring. The root
+// itself, hidden entries and built-in ignores (VCS, dependencies, build output) are skipped.
+func Harvest(fs afero.Fs, root string) ([]infofile.Generated, error) {
+	filter := pattern.NewFilterBuilder(fs).AddBuiltinIgnores(true).AddHiddenFilter(false).Build()
+
+	byPath := make(map[string]infofile.Generated)
+	add := func(target, notes, source string) {
+		notes = shorten(notes)
+		if notes == "" || target == "." {
+			return
+		}
+		if _, exists := byPath[target]; !exists {
+			byPath[target] = infofile.Generated{Path: target, Notes: notes, Source: source}
+		}
+	}
+
+	err := afero.Walk(fs, root, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			if filePath == root {
+				return err
+			}
+			return nil
+		}
+
+		relative, err := filepath.Rel(root, filePath)
+		if err != nil || relative == "." {
+			return nil
+		}
+		relative = filepath.ToSlash(relative)
+		if filter.ShouldExclude(relative, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.IsDir() {
+			if notes := goPackageDoc(fs, filePath); notes != "" {
+				add(relative, notes, SourceGoDoc)
+			} else if notes := readmeSummary(fs, filePath); notes != "" {
+				add(relative, notes, SourceReadme)
+			} else if notes := pythonDocstring(fs, filepath.Join(filePath, "__init__.py")); notes != "" {
+				add(relative, notes, SourceDocstring)
+			}
+			return nil
+		}
+
+		if path.Ext(relative) == ".py" && info.Name() != "__init__.py" {
+			add(relative, pythonDocstring(fs, filePath), SourceDocstring)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]infofile.Generated, 0, len(byPath))
+	for _, candidate := range byPath {
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Path < candidates[j].Path })
+	return candidates, nil
+}
+
+// shorten collapses whitespace, drops a trailing period and bounds the length
+func shorten(text string) string {
+	text = strings.TrimSuffix(strings.Join(strings.Fields(text), " "), ".")
+	if runes := []rune(text); len(runes) > maxAnnotationRunes {
+		cut := string(runes[:maxAnnotationRunes])
+		if space := strings.LastIndex(cut, " "); space > 0 {
+			cut = cut[:space]
+		}
+		text = cut
+	}
+	return text
+}
@@ -0,0 +1,58 @@
+package harvest_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/harvest"
+	"treex/treex/plugins/infofile"
+)
+
+func TestHarvest(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"README.md": "# Project\n\nRoot READMEs are not harvested.\n",
+		"docs": map[string]interface{}{
+			"README.md": "---\ntitle: Docs\n---\n# Documentation\n\n[![CI](https://ci/badge.svg)](https://ci)\n\n" +
+				"User **guides** and [API](api.md) reference. Built with `mkdocs`.\n",
+		},
+		"schema": map[string]interface{}{
+			"README.md": "Database schema\n===============\n\n```sql\nselect 1;\n```\n",
+		},
+		"internal": map[string]interface{}{
+			"README.md": "# Internal\n\nIgnored: the package doc wins.\n",
+			"doc.go":    "// Package internal holds shared helpers. Not for import.\npackage internal\n",
+			"util.go":   "// Package internal is documented twice\npackage internal\n",
+		},
+		"cli": map[string]interface{}{
+			"main.go":      "package main\n",
+			"main_test.go": "// Package main tests\npackage main\n",
+		},
+		"tools": map[string]interface{}{
+			"__init__.py": "\"\"\"Developer tooling.\"\"\"\n",
+			"lint.py":     "#!/usr/bin/env python\n# -*- coding: utf-8 -*-\n\n'''Run the linters.\n\nLonger description.\n'''\n",
+			"plain.py":    "import os\n",
+			"raw.py":      "r\"\"\"Raw docstring. More text.\"\"\"\n",
+		},
+		".hidden":      map[string]interface{}{"README.md": "Hidden."},
+		"node_modules": map[string]interface{}{"dep": map[string]interface{}{"README.md": "Dependency."}},
+	})
+
+	candidates, err := harvest.Harvest(fs, "/project")
+	require.NoError(t, err)
+	assert.Equal(t, []infofile.Generated{
+		{Path: "docs", Notes: "User guides and API reference", Source: harvest.SourceReadme},
+		{Path: "internal", Notes: "Package internal holds shared helpers", Source: harvest.SourceGoDoc},
+		{Path: "schema", Notes: "Database schema", Source: harvest.SourceReadme},
+		{Path: "tools", Notes: "Developer tooling", Source: harvest.SourceDocstring},
+		{Path: "tools/lint.py", Notes: "Run the linters", Source: harvest.SourceDocstring},
+		{Path: "tools/raw.py", Notes: "Raw docstring", Source: harvest.SourceDocstring},
+	}, candidates)
+}
+
+func TestHarvestMissingRoot(t *testing.T) {
+	_, err := harvest.Harvest(testutil.NewTestFS(), "/missing")
+	assert.Error(t, err)
+}
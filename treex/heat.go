@@ -0,0 +1,100 @@
+package treex
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"path/filepath"
+	"time"
+
+	gitplugin "treex/treex/plugins/git"
+	"treex/treex/types"
+)
+
+// HeatMode selects what makes an entry hot in a heat-colored tree
+type HeatMode string
+
+const (
+	HeatChurn HeatMode = "churn" // Entries changed by more commits are hotter
+	HeatAge   HeatMode = "age"   // Entries changed more recently are hotter
+)
+
+// HeatLevels is the number of steps of the heat gradient, 0 the coldest
+const HeatLevels = 5
+
+// ParseHeatMode parses a --heat value
+func ParseHeatMode(value string) (HeatMode, error) {
+	switch mode := HeatMode(value); mode {
+	case HeatChurn, HeatAge:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown heat mode %q (valid: churn, age)", value)
+}
+
+// PathHeat is the git history of one entry of a tree
+type PathHeat struct {
+	Path         string      `json:"path"`                   // Relative to the tree root
+	IsDir        bool        `json:"isDir"`                  // Whether the entry is a directory
+	Commits      int         `json:"commits"`                // Commits that changed it (below it, for directories)
+	LastModified *time.Time  `json:"lastModified,omitempty"` // Author time of the newest of them; nil if never committed
+	AgeDays      int         `json:"ageDays"`                // Whole days since LastModified (0 if never committed)
+	Node         *types.Node `json:"-"`
+}
+
+// AnalyzeHeat returns the history of every entry of the tree below root, in tree order
+// churn is keyed by path relative to the repository root (see gitplugin.Churn), and
+// prefix is the tree root's path in the repository ("." when they are the same).
+func AnalyzeHeat(root *types.Node, churn map[string]gitplugin.PathChurn, prefix string, now time.Time) []PathHeat {
+	var entries []PathHeat
+	_ = types.WalkTree(root, func(node *types.Node, depth int) error {
+		entry := PathHeat{Path: filepath.ToSlash(node.Path), IsDir: node.IsDir, Node: node}
+		if history, ok := churn[path.Join(prefix, entry.Path)]; ok {
+			lastModified := history.LastModified
+			entry.Commits = history.Commits
+			entry.LastModified = &lastModified
+			entry.AgeDays = max(int(now.Sub(lastModified).Hours()/24), 0)
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries
+}
+
+// HeatScale returns the step of the heat gradient (0 to HeatLevels-1) of each entry with
+// history, the root (the first of entries, see AnalyzeHeat) aside. Files are scaled
+// against files and directories against directories, on a logarithmic scale so a few
+// hot spots do not wash out the rest.
+func HeatScale(entries []PathHeat, mode HeatMode) map[*types.Node]int {
+	value := func(entry PathHeat) float64 {
+		if mode == HeatAge {
+			return float64(entry.AgeDays)
+		}
+		return float64(entry.Commits)
+	}
+
+	if len(entries) > 0 {
+		entries = entries[1:]
+	}
+	highest := make(map[bool]float64) // By IsDir
+	for _, entry := range entries {
+		if entry.LastModified != nil {
+			highest[entry.IsDir] = max(highest[entry.IsDir], value(entry))
+		}
+	}
+
+	levels := make(map[*types.Node]int)
+	for _, entry := range entries {
+		if entry.LastModified == nil {
+			continue
+		}
+		level := 0
+		if top := highest[entry.IsDir]; top > 0 {
+			level = int(math.Round(math.Log1p(value(entry)) / math.Log1p(top) * (HeatLevels - 1)))
+		}
+		if mode == HeatAge {
+			level = HeatLevels - 1 - level // The oldest entries are the coldest
+		}
+		levels[entry.Node] = level
+	}
+	return levels
+}
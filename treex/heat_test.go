@@ -0,0 +1,45 @@
+package treex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gitplugin "treex/treex/plugins/git"
+	"treex/treex/types"
+)
+
+func TestAnalyzeHeat(t *testing.T) {
+	root := &types.Node{Name: "app", Path: ".", IsDir: true}
+	src := &types.Node{Name: "src", Path: "src", IsDir: true, Parent: root}
+	hot := &types.Node{Name: "hot.go", Path: "src/hot.go", Parent: src}
+	cold := &types.Node{Name: "cold.go", Path: "src/cold.go", Parent: src}
+	fresh := &types.Node{Name: "fresh.go", Path: "src/fresh.go", Parent: src}
+	untracked := &types.Node{Name: "new.go", Path: "src/new.go", Parent: src}
+	root.Children = []*types.Node{src}
+	src.Children = []*types.Node{hot, cold, fresh, untracked}
+
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	churn := map[string]gitplugin.PathChurn{
+		"app":              {Commits: 60, LastModified: now.AddDate(0, 0, -1)},
+		"app/src":          {Commits: 60, LastModified: now.AddDate(0, 0, -1)},
+		"app/src/hot.go":   {Commits: 50, LastModified: now.AddDate(0, 0, -30)},
+		"app/src/cold.go":  {Commits: 1, LastModified: now.AddDate(-2, 0, 0)},
+		"app/src/fresh.go": {Commits: 9, LastModified: now.AddDate(0, 0, -1)},
+		"other/main.go":    {Commits: 99, LastModified: now},
+	}
+
+	entries := AnalyzeHeat(root, churn, "app", now)
+	require.Len(t, entries, 6)
+	assert.Equal(t, "src/hot.go", entries[2].Path, "tree order")
+	assert.Equal(t, 50, entries[2].Commits)
+	assert.Equal(t, 30, entries[2].AgeDays)
+	assert.Nil(t, entries[5].LastModified, "never committed")
+
+	assert.Equal(t, map[*types.Node]int{src: 4, hot: 4, cold: 1, fresh: 2}, HeatScale(entries, HeatChurn))
+	assert.Equal(t, map[*types.Node]int{src: 0, hot: 2, cold: 0, fresh: 4}, HeatScale(entries, HeatAge))
+
+	_, err := ParseHeatMode("size")
+	assert.Error(t, err)
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+	"treex/treex/i18n"
+)
+
+// flagDefiners are the pflag methods whose last argument is the flag's usage
+var flagDefiners = map[string]bool{}
+
+func init() {
+	for _, kind := range []string{"Bool", "String", "Int", "Count", "StringArray", "StringSlice", "Duration", "Float64"} {
+		for _, suffix := range []string{"", "P", "Var", "VarP"} {
+			flagDefiners[kind+suffix] = true
+		}
+	}
+}
+
+// templateCall matches {{T "..."}} and {{Tf "..." args}} in help templates
+var templateCall = regexp.MustCompile(`\{\{Tf? ("(?:[^"\\]|\\.)*")[^}]*\}\}`)
+
+// Extract returns the messages of the Go files below dir on fs, in file and source
+// order, each once
+func Extract(fs afero.Fs, dir string) ([]string, error) {
+	var files []string
+	err := afero.Walk(fs, dir, func(p string, entry os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && entry.Name() == "testdata" {
+			return filepath.SkipDir
+		}
+		if !entry.IsDir() && strings.HasSuffix(p, ".go") && !strings.HasSuffix(p, "_test.go") {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var messages []string
+	seen := make(map[string]bool)
+	add := func(msg string) {
+		if msg != "" && !seen[msg] {
+			seen[msg] = true
+			messages = append(messages, msg)
+		}
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		src, err := afero.ReadFile(fs, file)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := parser.ParseFile(fset, file, src, 0)
+		if err != nil {
+			return nil, err
+		}
+		ast.Inspect(parsed, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.CallExpr:
+				for _, msg := range callMessages(node) {
+					add(msg)
+				}
+			case *ast.CompositeLit:
+				if isSelector(node.Type, "cobra", "Command") {
+					for _, elt := range node.Elts {
+						if kv, ok := elt.(*ast.KeyValueExpr); ok && isIdent(kv.Key, "Short") {
+							if msg, ok := constantString(kv.Value); ok {
+								add(msg)
+							}
+						}
+					}
+				}
+			case *ast.BasicLit:
+				if node.Kind == token.STRING {
+					value, _ := strconv.Unquote(node.Value)
+					for _, match := range templateCall.FindAllStringSubmatch(value, -1) {
+						if msg, err := strconv.Unquote(match[1]); err == nil {
+							add(msg)
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+	return messages, nil
+}
+
+// callMessages returns the messages of an i18n.T, i18n.Translate or i18n.Plural call, or
+// the usage of a flag definition
+func callMessages(call *ast.CallExpr) []string {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) == 0 {
+		return nil
+	}
+	var args []ast.Expr
+	switch {
+	case isSelector(selector, "i18n", "T"), isSelector(selector, "i18n", "Translate"):
+		args = call.Args[:1]
+	case isSelector(selector, "i18n", "Plural") && len(call.Args) == 3:
+		args = call.Args[1:]
+	case flagDefiners[selector.Sel.Name]:
+		if flags, ok := selector.X.(*ast.CallExpr); ok {
+			if method, ok := flags.Fun.(*ast.SelectorExpr); ok && (method.Sel.Name == "Flags" || method.Sel.Name == "PersistentFlags") {
+				args = call.Args[len(call.Args)-1:]
+			}
+		}
+	}
+
+	var messages []string
+	for _, arg := range args {
+		if msg, ok := constantString(arg); ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// constantString evaluates string literals and their concatenations
+func constantString(expr ast.Expr) (string, bool) {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		if expr.Kind != token.STRING {
+			return "", false
+		}
+		value, err := strconv.Unquote(expr.Value)
+		return value, err == nil
+	case *ast.BinaryExpr:
+		if expr.Op != token.ADD {
+			return "", false
+		}
+		left, ok := constantString(expr.X)
+		if !ok {
+			return "", false
+		}
+		right, ok := constantString(expr.Y)
+		return left + right, ok
+	case *ast.ParenExpr:
+		return constantString(expr.X)
+	}
+	return "", false
+}
+
+// isSelector reports whether expr is pkg.name
+func isSelector(expr ast.Expr, pkg, name string) bool {
+	selector, ok := expr.(*ast.SelectorExpr)
+	return ok && selector.Sel.Name == name && isIdent(selector.X, pkg)
+}
+
+// isIdent reports whether expr is the identifier name
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+// UpdateLocales gives every locale file in dir on fs exactly messages, keeping the existing
+// translations, and reports the files that were out of date; they are rewritten only
+// when write is set. A line per file counting untranslated messages goes to report.
+func UpdateLocales(fs afero.Fs, dir string, messages []string, write bool, report io.Writer) ([]string, error) {
+	paths, err := afero.Glob(fs, filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []string
+	for _, p := range paths {
+		content, err := afero.ReadFile(fs, p)
+		if err != nil {
+			return nil, err
+		}
+		var locale i18n.Catalog
+		if err := json.Unmarshal(content, &locale); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+
+		translated := make(map[string]string, len(locale.Messages))
+		for _, msg := range locale.Messages {
+			translated[msg.ID] = msg.Translation
+		}
+		locale.Messages = make([]i18n.Message, len(messages))
+		untranslated := 0
+		for i, id := range messages {
+			locale.Messages[i] = i18n.Message{ID: id, Translation: translated[id]}
+			if translated[id] == "" {
+				untranslated++
+			}
+		}
+
+		var updated bytes.Buffer
+		encoder := json.NewEncoder(&updated)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(locale); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(report, "%s: %d messages, %d untranslated\n", locale.Language, len(messages), untranslated)
+		if bytes.Equal(updated.Bytes(), content) {
+			continue
+		}
+		stale = append(stale, filepath.Base(p))
+		if write {
+			if err := afero.WriteFile(fs, p, updated.Bytes(), 0644); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return stale, nil
+}
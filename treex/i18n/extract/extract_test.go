@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+)
+
+func TestExtract(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/src", map[string]interface{}{
+		"cmd": map[string]interface{}{"a.go": `package cmd
+
+var exampleCmd = &cobra.Command{
+	Use:   "example",
+	Short: "Show an " + "example",
+	Long:  "Long descriptions are not shown in help",
+}
+
+const usage = ` + "`" + `{{T "Usage:"}} {{Tf "See %s" .CommandPath}}` + "`" + `
+
+func init() {
+	exampleCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Say more")
+	exampleCmd.PersistentFlags().String("name", "", "Name to use")
+	exampleCmd.PersistentFlags().String("theme", "", "Theme (env: "+themeEnv+")")
+	other.BoolVar(&x, "x", false, "Not a flag set")
+}
+`},
+		"rendering": map[string]interface{}{
+			"b.go": `package rendering
+
+func summary(n int) string {
+	return i18n.T("%s, %d annotated", i18n.Plural(n, "%d file", "%d files"), n) + i18n.Translate("Show an example")
+}
+`,
+			"b_test.go":  `package rendering; var _ = i18n.T("test only")`,
+			"README.txt": `i18n.T("not go")`,
+		},
+		"testdata": map[string]interface{}{"fixture.go": `package testdata; var _ = i18n.T("fixture")`},
+	})
+
+	messages, err := Extract(fs, "/src")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Show an example", "Usage:", "See %s", "Say more", "Name to use",
+		"%s, %d annotated", "%d file", "%d files",
+	}, messages)
+}
+
+func TestUpdateLocales(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/locales", map[string]interface{}{
+		"pt-BR.json": `{"language": "pt-BR", "messages": [{"id": "Gone", "translation": "Sumiu"}, {"id": "Usage:", "translation": "Uso:"}]}`,
+	})
+	messages := []string{"Usage:", "Flags:"}
+
+	var report bytes.Buffer
+	stale, err := UpdateLocales(fs, "/locales", messages, false, &report)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pt-BR.json"}, stale)
+	assert.Equal(t, "pt-BR: 2 messages, 1 untranslated\n", report.String())
+
+	stale, err = UpdateLocales(fs, "/locales", messages, true, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pt-BR.json"}, stale)
+	content, err := afero.ReadFile(fs, "/locales/pt-BR.json")
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "language": "pt-BR",
+  "messages": [
+    {
+      "id": "Usage:",
+      "translation": "Uso:"
+    },
+    {
+      "id": "Flags:",
+      "translation": ""
+    }
+  ]
+}
+`, string(content), "obsolete messages are dropped, new ones left to translate")
+
+	stale, err = UpdateLocales(fs, "/locales", messages, false, io.Discard)
+	require.NoError(t, err)
+	assert.Empty(t, stale)
+}
+
+// TestLocalesInSync fails when a user-facing message was added or changed without
+// updating the locale files
+func TestLocalesInSync(t *testing.T) {
+	source := afero.NewReadOnlyFs(afero.NewOsFs()) // The repository's own sources, never written
+	messages, err := Extract(source, filepath.Join("..", ".."))
+	require.NoError(t, err)
+	stale, err := UpdateLocales(source, filepath.Join("..", "locales"), messages, false, io.Discard)
+	require.NoError(t, err)
+	assert.Empty(t, stale, "run go generate ./treex/i18n and translate the new messages")
+}
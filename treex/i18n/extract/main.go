@@ -0,0 +1,46 @@
+// Command extract keeps the locale files of package i18n in sync with the source: it
+// collects the user-facing messages of the Go files below -dir and rewrites each
+// locales/*.json with exactly those messages, keeping existing translations.
+//
+// Messages are the string constants passed to i18n.T, i18n.Translate and i18n.Plural,
+// the Short descriptions of cobra.Command literals, the usage of flags defined on Flags()
+// or PersistentFlags(), and the {{T "..."}} and {{Tf "..."}} calls of help templates.
+// Test files are skipped.
+//
+// Usage (from treex/i18n, as go generate runs it):
+//
+//	go run ./extract -dir .. -locales locales [-check]
+//
+// With -check, nothing is written and the command fails when a locale file is stale.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/spf13/afero"
+)
+
+func main() {
+	dir := flag.String("dir", "..", "Source directory to extract messages from")
+	localesDir := flag.String("locales", "locales", "Directory of the locale files to update")
+	check := flag.Bool("check", false, "Fail when a locale file is out of date instead of writing it")
+	flag.Parse()
+
+	fs := afero.NewOsFs()
+	messages, err := Extract(fs, *dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "extract:", err)
+		os.Exit(1)
+	}
+	stale, err := UpdateLocales(fs, *localesDir, messages, !*check, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "extract:", err)
+		os.Exit(1)
+	}
+	if *check && len(stale) > 0 {
+		fmt.Fprintf(os.Stderr, "extract: out of date: %v (run go generate ./treex/i18n)\n", stale)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,189 @@
+// Package i18n translates treex's user-facing text: help, the summary line and the
+// warnings printed after the tree. Log messages and machine-readable output stay in
+// English.
+//
+// Messages are keyed by their English text. Each file in locales/ maps the messages of
+// one language to their translations; an empty translation falls back to English. The
+// extractor in extract/ keeps the files in sync with the source (go generate
+// ./treex/i18n), so translators only fill in the blanks.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+//go:generate go run ./extract -dir .. -locales locales
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Catalog is the content of a locale file
+type Catalog struct {
+	Language string    `json:"language"` // BCP 47 tag
+	Messages []Message `json:"messages"` // In the order the extractor found them
+}
+
+// Message is one catalog entry
+type Message struct {
+	ID          string `json:"id"`          // English text, as written in the source
+	Translation string `json:"translation"` // Translated text ("" = not translated yet)
+}
+
+var (
+	loadOnce     sync.Once
+	languages    []language.Tag                     // English, then the languages of locales/
+	translations map[language.Tag]map[string]string // Non-empty translations by language
+	builder      *catalog.Builder
+
+	mu          sync.RWMutex
+	current     = language.English
+	printer     *message.Printer  // Formats messages in current (nil for English)
+	currentText map[string]string // Translations of current (nil for English)
+)
+
+// load reads the embedded locale files
+// A malformed file is a build mistake caught by the tests, so it panics.
+func load() {
+	loadOnce.Do(func() {
+		languages = []language.Tag{language.English}
+		translations = make(map[language.Tag]map[string]string)
+		builder = catalog.NewBuilder(catalog.Fallback(language.English))
+
+		files, err := locales.ReadDir("locales")
+		if err != nil {
+			panic(err)
+		}
+		for _, file := range files {
+			content, err := locales.ReadFile(path.Join("locales", file.Name()))
+			if err != nil {
+				panic(err)
+			}
+			var locale Catalog
+			if err := json.Unmarshal(content, &locale); err != nil {
+				panic(fmt.Sprintf("i18n: locales/%s: %v", file.Name(), err))
+			}
+			tag := language.MustParse(locale.Language)
+			text := make(map[string]string, len(locale.Messages))
+			for _, msg := range locale.Messages {
+				if msg.Translation == "" {
+					continue
+				}
+				text[msg.ID] = msg.Translation
+				if err := builder.SetString(tag, msg.ID, msg.Translation); err != nil {
+					panic(err)
+				}
+			}
+			languages = append(languages, tag)
+			translations[tag] = text
+		}
+	})
+}
+
+// Languages returns the languages treex is translated to, English first
+func Languages() []language.Tag {
+	load()
+	return append([]language.Tag(nil), languages...)
+}
+
+// LanguageFromEnv returns the message language of the environment, from LC_ALL,
+// LC_MESSAGES or LANG ("pt_BR.UTF-8" becomes "pt-BR"); empty when unset, "C" or "POSIX"
+func LanguageFromEnv(getenv func(string) string) string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := getenv(name); value != "" {
+			return normalizeLocale(value)
+		}
+	}
+	return ""
+}
+
+// normalizeLocale turns a POSIX locale name into a BCP 47 tag
+func normalizeLocale(value string) string {
+	if cut := strings.IndexAny(value, ".@"); cut >= 0 {
+		value = value[:cut]
+	}
+	if value == "C" || value == "POSIX" {
+		return ""
+	}
+	return strings.ReplaceAll(value, "_", "-")
+}
+
+// Match returns the supported language closest to value, a BCP 47 tag or locale name
+// ("pt", "pt_PT.UTF-8"); false when no supported language is close. An empty value
+// matches English.
+func Match(value string) (language.Tag, bool) {
+	value = normalizeLocale(value)
+	if value == "" {
+		return language.English, true
+	}
+	tag, err := language.Parse(value)
+	if err != nil {
+		return language.English, false
+	}
+	supported := Languages()
+	_, index, confidence := language.NewMatcher(supported).Match(tag)
+	if confidence < language.High {
+		return language.English, false
+	}
+	return supported[index], true
+}
+
+// SetLanguage makes tag, one of Languages, the language of T and Translate
+func SetLanguage(tag language.Tag) {
+	load()
+	mu.Lock()
+	defer mu.Unlock()
+	current = tag
+	if text, ok := translations[tag]; ok {
+		printer = message.NewPrinter(tag, message.Catalog(builder))
+		currentText = text
+	} else {
+		current, printer, currentText = language.English, nil, nil
+	}
+}
+
+// Language returns the language of T and Translate
+func Language() language.Tag {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// T formats the translation of the format string key with args, as fmt.Sprintf does
+// Numbers follow the language's conventions ("1.234" in Portuguese); English output is
+// exactly fmt.Sprintf's.
+func T(key string, args ...interface{}) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if printer == nil {
+		return fmt.Sprintf(key, args...)
+	}
+	return printer.Sprintf(key, args...)
+}
+
+// Plural formats n with the translation of singular when n is 1, of plural otherwise
+func Plural(n int, singular, plural string) string {
+	if n == 1 {
+		return T(singular, n)
+	}
+	return T(plural, n)
+}
+
+// Translate returns the translation of text, or text when it has none
+// Unlike T, text is not a format string: help and flag descriptions may contain "%".
+func Translate(text string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if translated, ok := currentText[text]; ok {
+		return translated
+	}
+	return text
+}
@@ -0,0 +1,83 @@
+package i18n_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+	"treex/treex/i18n"
+)
+
+// inPortuguese runs fn with Brazilian Portuguese selected
+func inPortuguese(t *testing.T, fn func()) {
+	t.Helper()
+	i18n.SetLanguage(language.BrazilianPortuguese)
+	defer i18n.SetLanguage(language.English)
+	fn()
+}
+
+func TestLanguageFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{"unset", map[string]string{}, ""},
+		{"LANG", map[string]string{"LANG": "pt_BR.UTF-8"}, "pt-BR"},
+		{"LC_MESSAGES over LANG", map[string]string{"LANG": "en_US.UTF-8", "LC_MESSAGES": "pt_BR"}, "pt-BR"},
+		{"LC_ALL over everything", map[string]string{"LC_ALL": "C", "LC_MESSAGES": "pt_BR"}, ""},
+		{"modifier", map[string]string{"LANG": "de_DE@euro"}, "de-DE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, i18n.LanguageFromEnv(func(name string) string { return tt.env[name] }))
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	for value, expected := range map[string]language.Tag{
+		"":            language.English,
+		"C":           language.English,
+		"en_GB.UTF-8": language.English,
+		"pt-BR":       language.BrazilianPortuguese,
+		"pt":          language.BrazilianPortuguese,
+		"pt_BR.UTF-8": language.BrazilianPortuguese,
+	} {
+		tag, ok := i18n.Match(value)
+		assert.True(t, ok, value)
+		assert.Equal(t, expected, tag, value)
+	}
+
+	for _, value := range []string{"de", "ja-JP", "not a language"} {
+		tag, ok := i18n.Match(value)
+		assert.False(t, ok, value)
+		assert.Equal(t, language.English, tag, value)
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	assert.Equal(t, []language.Tag{language.English, language.BrazilianPortuguese}, i18n.Languages())
+}
+
+func TestEnglishIsFmt(t *testing.T) {
+	assert.Equal(t, language.English, i18n.Language())
+	assert.Equal(t, fmt.Sprintf("%d files", 12345), i18n.T("%d files", 12345), "no digit grouping")
+	assert.Equal(t, "1 file", i18n.Plural(1, "%d file", "%d files"))
+	assert.Equal(t, "Usage:", i18n.Translate("Usage:"))
+}
+
+func TestPortuguese(t *testing.T) {
+	inPortuguese(t, func() {
+		assert.Equal(t, language.BrazilianPortuguese, i18n.Language())
+		assert.Equal(t, "1 diretório", i18n.Plural(1, "%d directory", "%d directories"))
+		assert.Equal(t, "0 diretórios", i18n.Plural(0, "%d directory", "%d directories"))
+		assert.Equal(t, "12.345 arquivos", i18n.Plural(12345, "%d file", "%d files"), "Portuguese digit grouping")
+		assert.Equal(t, "Uso:", i18n.Translate("Uso:"), "translations are not translated again")
+		assert.Equal(t, "Uso:", i18n.Translate("Usage:"))
+		assert.Equal(t, "no such message", i18n.Translate("no such message"))
+		assert.Equal(t, "no such 3", i18n.T("no such %d", 3), "untranslated formats fall back to English")
+	})
+	assert.Equal(t, "Usage:", i18n.Translate("Usage:"))
+}
@@ -0,0 +1,166 @@
+// Package lint checks a built tree against the structure rules of .treex.toml
+// (see package config). Findings are reported as infofile.Issue values, like .info
+// validation problems, with InfoFile naming the configuration file and Line the rule.
+//
+// Rule kinds:
+//
+//	no-files            directories matching paths may only contain directories
+//	require-annotation  paths matching paths must be annotated
+//	file-location       files whose name matches files must sit in a directory whose
+//	                    name matches dirs (optionally only below paths)
+//
+// Path globs are doublestar patterns relative to the root ("services/*", "src/**");
+// name globs match a single name ("*_test.go").
+package lint
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"treex/treex/config"
+	"treex/treex/plugins/infofile"
+	"treex/treex/types"
+)
+
+// Rule kinds
+const (
+	KindNoFiles           = "no-files"
+	KindRequireAnnotation = "require-annotation"
+	KindFileLocation      = "file-location"
+)
+
+// Check validates rules and returns the findings for the tree below root, sorted by rule
+// and path
+func Check(root *types.Node, rules []config.LintRule) ([]infofile.Issue, error) {
+	if err := validateRules(rules); err != nil {
+		return nil, err
+	}
+
+	var issues []infofile.Issue
+	for _, rule := range rules {
+		report := func(nodePath, message string) {
+			if rule.Message != "" {
+				message = rule.Message
+			}
+			issues = append(issues, infofile.Issue{
+				InfoFile: config.FileName,
+				Line:     rule.Line,
+				Path:     nodePath,
+				Message:  rule.ID + ": " + message,
+			})
+		}
+
+		walk(root, func(node *types.Node) {
+			switch rule.Kind {
+			case KindNoFiles:
+				if node.IsDir || node.Parent == nil || !matchesPath(rule.Paths, node.Parent.Path) || node.Name == ".info" {
+					return
+				}
+				report(node.Path, fmt.Sprintf("files are not allowed directly in %s", node.Parent.Path))
+			case KindRequireAnnotation:
+				if matchesPath(rule.Paths, node.Path) && !hasNotes(node) {
+					report(node.Path, "must be annotated")
+				}
+			case KindFileLocation:
+				if node.IsDir || node.Parent == nil || !matchesName(rule.Files, node.Name) {
+					return
+				}
+				if len(rule.Paths) > 0 && !matchesPath(rule.Paths, node.Path) {
+					return
+				}
+				if !matchesName(rule.Dirs, path.Base(node.Parent.Path)) {
+					report(node.Path, fmt.Sprintf("must live in a directory matching %s", strings.Join(rule.Dirs, ", ")))
+				}
+			}
+		})
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Path < issues[j].Path
+	})
+	return issues, nil
+}
+
+// validateRules rejects rules with unknown kinds, missing fields or invalid globs
+func validateRules(rules []config.LintRule) error {
+	seen := make(map[string]bool)
+	for i, rule := range rules {
+		where := fmt.Sprintf("%s: lint rule %d", config.FileName, i+1)
+		if rule.Line > 0 {
+			where = fmt.Sprintf("%s:%d: lint rule", config.FileName, rule.Line)
+		}
+		if rule.ID == "" {
+			return fmt.Errorf("%s has no id", where)
+		}
+		if seen[rule.ID] {
+			return fmt.Errorf("%s: duplicate id %q", where, rule.ID)
+		}
+		seen[rule.ID] = true
+
+		switch rule.Kind {
+		case KindNoFiles, KindRequireAnnotation:
+			if len(rule.Paths) == 0 {
+				return fmt.Errorf("%s %q: %s needs paths", where, rule.ID, rule.Kind)
+			}
+		case KindFileLocation:
+			if len(rule.Files) == 0 || len(rule.Dirs) == 0 {
+				return fmt.Errorf("%s %q: %s needs files and dirs", where, rule.ID, rule.Kind)
+			}
+		default:
+			return fmt.Errorf("%s %q: unknown kind %q (valid: %s, %s, %s)", where, rule.ID, rule.Kind,
+				KindNoFiles, KindRequireAnnotation, KindFileLocation)
+		}
+
+		for _, patterns := range [][]string{rule.Paths, rule.Files, rule.Dirs} {
+			for _, pattern := range patterns {
+				if !doublestar.ValidatePattern(pattern) {
+					return fmt.Errorf("%s %q: invalid pattern %q", where, rule.ID, pattern)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// walk visits every node below root in tree order
+func walk(node *types.Node, visit func(*types.Node)) {
+	if node == nil {
+		return
+	}
+	visit(node)
+	for _, child := range node.Children {
+		walk(child, visit)
+	}
+}
+
+// matchesPath reports whether a root-relative path matches any of the globs
+func matchesPath(patterns []string, nodePath string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := doublestar.Match(pattern, nodePath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesName reports whether a single name matches any of the globs
+func matchesName(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// hasNotes reports whether a node carries annotation text
+func hasNotes(node *types.Node) bool {
+	annotation := node.GetAnnotation()
+	return annotation != nil && strings.TrimSpace(annotation.Notes) != ""
+}
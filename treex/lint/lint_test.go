@@ -0,0 +1,92 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex"
+	"treex/treex/config"
+	"treex/treex/lint"
+	"treex/treex/plugins/infofile"
+	"treex/treex/types"
+)
+
+// buildTree builds the annotated tree of structure rooted at /project
+func buildTree(t *testing.T, structure map[string]interface{}) *types.Node {
+	t.Helper()
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", structure)
+	cfg := treex.DefaultTreeConfig("/project")
+	cfg.Filesystem = fs
+	result, err := treex.BuildTree(cfg)
+	require.NoError(t, err)
+	return result.Root
+}
+
+func TestCheckRules(t *testing.T) {
+	root := buildTree(t, map[string]interface{}{
+		".info": "services/api  Public API\n",
+		"src": map[string]interface{}{
+			".info":   "lib  Shared code\n",
+			"main.go": "package main",
+			"lib":     map[string]interface{}{"util.go": "package lib"},
+		},
+		"services": map[string]interface{}{
+			"api":     map[string]interface{}{"server.py": "", "server_test.py": ""},
+			"billing": map[string]interface{}{"tests": map[string]interface{}{"invoice_test.py": ""}},
+		},
+	})
+
+	issues, err := lint.Check(root, []config.LintRule{
+		{ID: "no-loose-src-files", Kind: lint.KindNoFiles, Paths: []string{"src"}, Line: 1},
+		{ID: "services-annotated", Kind: lint.KindRequireAnnotation, Paths: []string{"services/*"}, Line: 6},
+		{ID: "tests-in-test-dirs", Kind: lint.KindFileLocation, Files: []string{"*_test.py"}, Dirs: []string{"tests"},
+			Message: "move it to tests/", Line: 11},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []infofile.Issue{
+		{InfoFile: ".treex.toml", Line: 1, Path: "src/main.go", Message: "no-loose-src-files: files are not allowed directly in src"},
+		{InfoFile: ".treex.toml", Line: 6, Path: "services/billing", Message: "services-annotated: must be annotated"},
+		{InfoFile: ".treex.toml", Line: 11, Path: "services/api/server_test.py", Message: "tests-in-test-dirs: move it to tests/"},
+	}, issues)
+}
+
+func TestCheckFileLocationLimitedToPaths(t *testing.T) {
+	root := buildTree(t, map[string]interface{}{
+		"tools":    map[string]interface{}{"gen_test.go": ""},
+		"services": map[string]interface{}{"api_test.go": ""},
+	})
+
+	issues, err := lint.Check(root, []config.LintRule{
+		{ID: "go-tests", Kind: lint.KindFileLocation, Files: []string{"*_test.go"}, Dirs: []string{"*_test"}, Paths: []string{"services/**"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "services/api_test.go", issues[0].Path)
+	assert.Equal(t, "go-tests: must live in a directory matching *_test", issues[0].Message)
+}
+
+func TestCheckRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		rule config.LintRule
+		want string
+	}{
+		{config.LintRule{Kind: lint.KindNoFiles, Paths: []string{"src"}}, "has no id"},
+		{config.LintRule{ID: "x", Kind: "forbid"}, `unknown kind "forbid"`},
+		{config.LintRule{ID: "x", Kind: lint.KindRequireAnnotation}, "needs paths"},
+		{config.LintRule{ID: "x", Kind: lint.KindFileLocation, Files: []string{"*.go"}}, "needs files and dirs"},
+		{config.LintRule{ID: "x", Kind: lint.KindNoFiles, Paths: []string{"src/[a"}, Line: 4}, `.treex.toml:4: lint rule "x": invalid pattern`},
+	}
+	for _, tt := range tests {
+		_, err := lint.Check(nil, []config.LintRule{tt.rule})
+		assert.ErrorContains(t, err, tt.want)
+	}
+
+	_, err := lint.Check(nil, []config.LintRule{
+		{ID: "x", Kind: lint.KindNoFiles, Paths: []string{"a"}},
+		{ID: "x", Kind: lint.KindNoFiles, Paths: []string{"b"}},
+	})
+	assert.ErrorContains(t, err, `duplicate id "x"`)
+}
@@ -0,0 +1,169 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// Format selects how console log messages are written
+type Format string
+
+const (
+	FormatText Format = "text" // key=value lines (the default)
+	FormatJSON Format = "json" // One JSON object per line, for automation
+)
+
+// slogTraceLevel is below slog's debug level, matching TraceLevel
+const slogTraceLevel = slog.LevelDebug - 4
+
+// ParseLevel parses a --log-level value: trace, debug, info, warn or error
+func ParseLevel(value string) (Level, error) {
+	for _, level := range []Level{TraceLevel, DebugLevel, InfoLevel, WarnLevel, ErrorLevel} {
+		if strings.EqualFold(value, level.String()) {
+			return level, nil
+		}
+	}
+	return WarnLevel, fmt.Errorf("unknown log level %q (use trace, debug, info, warn or error)", value)
+}
+
+// ParseFormat parses a --log-format value: text or json
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(value)); format {
+	case FormatText, FormatJSON:
+		return format, nil
+	}
+	return FormatText, fmt.Errorf("unknown log format %q (use text or json)", value)
+}
+
+// slogLevel converts a Level to the slog level it filters at
+func (l Level) slogLevel() slog.Level {
+	switch l {
+	case TraceLevel:
+		return slogTraceLevel
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel:
+		return slog.LevelInfo
+	case ErrorLevel:
+		return slog.LevelError
+	default:
+		return slog.LevelWarn
+	}
+}
+
+// NewHandler builds the slog handler for config: console messages at ConsoleLevel in
+// config.Format, and file messages at FileLevel as JSON lines
+func NewHandler(config Config) (slog.Handler, error) {
+	var handlers []slog.Handler
+
+	if config.ConsoleLevel != DisabledLevel {
+		console := config.Console
+		if console == nil {
+			console = os.Stdout
+		}
+		options := &slog.HandlerOptions{Level: config.ConsoleLevel.slogLevel(), ReplaceAttr: replaceLevelNames}
+		if config.Format == FormatJSON {
+			handlers = append(handlers, slog.NewJSONHandler(console, options))
+		} else {
+			// Console text is read by people: the time adds nothing to a command's output
+			options.ReplaceAttr = func(groups []string, attr slog.Attr) slog.Attr {
+				if len(groups) == 0 && attr.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return replaceLevelNames(groups, attr)
+			}
+			handlers = append(handlers, slog.NewTextHandler(console, options))
+		}
+	}
+
+	if config.FileLevel != DisabledLevel && config.LogFile != "" {
+		fs := config.Filesystem
+		if fs == nil {
+			fs = afero.NewOsFs()
+		}
+		logDir := filepath.Dir(config.LogFile)
+		if err := fs.MkdirAll(logDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create log directory %s: %w", logDir, err)
+		}
+		file, err := fs.OpenFile(config.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file %s: %w", config.LogFile, err)
+		}
+		handlers = append(handlers, slog.NewJSONHandler(file, &slog.HandlerOptions{Level: config.FileLevel.slogLevel(), ReplaceAttr: replaceLevelNames}))
+	}
+
+	if len(handlers) == 0 {
+		return slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}), nil
+	}
+	if len(handlers) == 1 {
+		return handlers[0], nil
+	}
+	return fanout(handlers), nil
+}
+
+// InitDefault makes a logger built from config slog's default, the logger the path
+// collector and plugins write their warnings to
+func InitDefault(config Config) error {
+	handler, err := NewHandler(config)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// replaceLevelNames writes the trace level as "TRACE" rather than "DEBUG-4"
+func replaceLevelNames(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) == 0 && attr.Key == slog.LevelKey {
+		if level, ok := attr.Value.Any().(slog.Level); ok && level <= slogTraceLevel {
+			attr.Value = slog.StringValue("TRACE")
+		}
+	}
+	return attr
+}
+
+// fanout sends each record to every handler enabled for its level
+type fanout []slog.Handler
+
+func (f fanout) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range f {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanout) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range f {
+		if handler.Enabled(ctx, record.Level) {
+			errs = append(errs, handler.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanout, len(f))
+	for i, handler := range f {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (f fanout) WithGroup(name string) slog.Handler {
+	handlers := make(fanout, len(f))
+	for i, handler := range f {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return handlers
+}
@@ -0,0 +1,102 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/logging"
+)
+
+func TestParseLevel(t *testing.T) {
+	for value, expected := range map[string]logging.Level{
+		"trace": logging.TraceLevel,
+		"DEBUG": logging.DebugLevel,
+		"info":  logging.InfoLevel,
+		"warn":  logging.WarnLevel,
+		"error": logging.ErrorLevel,
+	} {
+		level, err := logging.ParseLevel(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, level, value)
+	}
+
+	_, err := logging.ParseLevel("loud")
+	assert.ErrorContains(t, err, `unknown log level "loud"`)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := logging.ParseFormat("JSON")
+	require.NoError(t, err)
+	assert.Equal(t, logging.FormatJSON, format)
+
+	_, err = logging.ParseFormat("xml")
+	assert.ErrorContains(t, err, `unknown log format "xml"`)
+}
+
+func TestNewHandlerText(t *testing.T) {
+	var console bytes.Buffer
+	handler, err := logging.NewHandler(logging.Config{ConsoleLevel: logging.WarnLevel, FileLevel: logging.DisabledLevel, Console: &console})
+	require.NoError(t, err)
+
+	logger := slog.New(handler)
+	logger.Info("hidden")
+	logger.Warn("skipping unreadable path", "path", "/project/secrets")
+	assert.Equal(t, "level=WARN msg=\"skipping unreadable path\" path=/project/secrets\n", console.String(),
+		"console text has no timestamps")
+}
+
+func TestNewHandlerJSON(t *testing.T) {
+	var console bytes.Buffer
+	handler, err := logging.NewHandler(logging.Config{ConsoleLevel: logging.TraceLevel, FileLevel: logging.DisabledLevel, Console: &console, Format: logging.FormatJSON})
+	require.NoError(t, err)
+
+	slog.New(handler).Log(context.Background(), slog.LevelDebug-4, "walking", "entries", 3)
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(console.Bytes(), &record))
+	assert.Equal(t, "TRACE", record["level"])
+	assert.Equal(t, "walking", record["msg"])
+	assert.Equal(t, float64(3), record["entries"])
+	assert.Contains(t, record, "time")
+}
+
+func TestNewHandlerWritesFile(t *testing.T) {
+	fs := testutil.NewTestFS()
+	var console bytes.Buffer
+	handler, err := logging.NewHandler(logging.Config{
+		ConsoleLevel: logging.ErrorLevel,
+		FileLevel:    logging.DebugLevel,
+		LogFile:      "/logs/treex.log",
+		Console:      &console,
+		Filesystem:   fs,
+	})
+	require.NoError(t, err)
+
+	logger := slog.New(handler).With("component", "test")
+	logger.Debug("to the file only")
+	logger.Error("to both")
+
+	content, err := afero.ReadFile(fs, "/logs/treex.log")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"msg":"to the file only","component":"test"`)
+	assert.Equal(t, "level=ERROR msg=\"to both\" component=test\n", console.String())
+}
+
+func TestInitDefault(t *testing.T) {
+	original := slog.Default()
+	defer slog.SetDefault(original)
+
+	var console bytes.Buffer
+	require.NoError(t, logging.InitDefault(logging.Config{ConsoleLevel: logging.InfoLevel, FileLevel: logging.DisabledLevel, Console: &console}))
+	slog.Info("through the default logger")
+	assert.Contains(t, console.String(), "through the default logger")
+}
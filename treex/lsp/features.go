@@ -0,0 +1,261 @@
+package lsp
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/spf13/afero"
+	"treex/treex/plugins/infofile"
+)
+
+// LSP diagnostic severities and completion item kinds
+const (
+	severityError   = 1
+	severityWarning = 2
+
+	kindFile   = 17
+	kindFolder = 19
+)
+
+// documentID identifies a document by URI
+type documentID struct {
+	URI string `json:"uri"`
+}
+
+// position is a zero-based line and UTF-16 character offset
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// textRange is a span between two positions
+type textRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+// positionParams are the params of requests about a position in a document
+type positionParams struct {
+	TextDocument documentID `json:"textDocument"`
+	Position     position   `json:"position"`
+}
+
+// diagnostic is a problem reported on a range of a document
+type diagnostic struct {
+	Range    textRange `json:"range"`
+	Severity int       `json:"severity"`
+	Code     string    `json:"code,omitempty"`
+	Source   string    `json:"source"`
+	Message  string    `json:"message"`
+}
+
+// publishDiagnostics builds the notification replacing the diagnostics of a document
+func publishDiagnostics(uri string, diagnostics []diagnostic) notification {
+	return notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  map[string]interface{}{"uri": uri, "diagnostics": diagnostics},
+	}
+}
+
+// diagnostics validates an open document against the directory holding it
+// Documents outside the filesystem (untitled buffers) get no diagnostics.
+func (s *Server) diagnostics(uri string) notification {
+	diagnostics := []diagnostic{}
+	filePath, ok := uriToPath(uri)
+	if !ok {
+		return publishDiagnostics(uri, diagnostics)
+	}
+
+	lines := strings.Split(s.documents[uri], "\n")
+	content := []byte(s.documents[uri])
+	for _, issue := range infofile.ValidateContent(s.config.Filesystem, filepath.Dir(filePath), filepath.Base(filePath), content) {
+		if issue.Line < 1 || issue.Line > len(lines) {
+			continue
+		}
+		severity := severityError
+		if issue.Type == infofile.IssueNoText || issue.Type == infofile.IssueBrokenReference {
+			severity = severityWarning
+		}
+		diagnostics = append(diagnostics, diagnostic{
+			Range:    lineRange(issue.Line-1, lines[issue.Line-1]),
+			Severity: severity,
+			Code:     string(issue.Type),
+			Source:   "treex",
+			Message:  issue.Message,
+		})
+	}
+	return publishDiagnostics(uri, diagnostics)
+}
+
+// completion lists the entries of the directory being typed in an entry's path, relative
+// to the .info file's directory
+func (s *Server) completion(uri, text string, pos position) interface{} {
+	empty := map[string]interface{}{"isIncomplete": false, "items": []interface{}{}}
+	filePath, ok := uriToPath(uri)
+	line, found := lineAt(text, pos.Line)
+	if !ok || !found {
+		return empty
+	}
+
+	// Only the path token completes: the line before the cursor must not contain its end
+	before := line[:byteOffset(line, pos.Character)]
+	token := strings.TrimLeft(before, " \t")
+	if strings.HasPrefix(token, "#") || endsPathToken(token) {
+		return empty
+	}
+
+	// Complete the last segment of the path typed so far
+	segmentStart := strings.LastIndex(token, "/") + 1
+	dir := ""
+	if segmentStart > 0 {
+		entry, _ := infofile.ParseLine(token[:segmentStart])
+		dir = entry.Path
+	}
+	entries, err := afero.ReadDir(s.config.Filesystem, filepath.Join(filepath.Dir(filePath), filepath.FromSlash(dir)))
+	if err != nil {
+		return empty
+	}
+
+	editStart := position{Line: pos.Line, Character: utf16Len(before[:len(before)-len(token)+segmentStart])}
+	items := []interface{}{}
+	for _, entry := range entries {
+		if entry.Name() == ".info" {
+			continue
+		}
+		label, kind := entry.Name(), kindFile
+		if entry.IsDir() {
+			label, kind = label+"/", kindFolder
+		}
+		items = append(items, map[string]interface{}{
+			"label":    label,
+			"kind":     kind,
+			"textEdit": map[string]interface{}{"range": textRange{Start: editStart, End: pos}, "newText": infofile.EscapePath(label)},
+		})
+	}
+	return map[string]interface{}{"isIncomplete": false, "items": items}
+}
+
+// definition locates the file or directory annotated on the line at pos
+func (s *Server) definition(uri, text string, pos position) interface{} {
+	target, _, ok := s.entryTarget(uri, text, pos.Line)
+	if !ok {
+		return nil
+	}
+	if exists, _ := afero.Exists(s.config.Filesystem, target); !exists {
+		return nil
+	}
+	return map[string]interface{}{"uri": pathToURI(target), "range": textRange{}}
+}
+
+// hover shows where the entry on the line at pos resolves to
+func (s *Server) hover(uri, text string, pos position) interface{} {
+	target, entry, ok := s.entryTarget(uri, text, pos.Line)
+	if !ok {
+		return nil
+	}
+
+	kind := "does not exist"
+	if info, err := s.config.Filesystem.Stat(target); err == nil {
+		kind = "file"
+		if info.IsDir() {
+			kind = "directory"
+		}
+	}
+	value := fmt.Sprintf("**%s** (%s)\n\n`%s`", entry.Path, kind, target)
+	line, _ := lineAt(text, pos.Line)
+	return map[string]interface{}{
+		"contents": map[string]string{"kind": "markdown", "value": value},
+		"range":    lineRange(pos.Line, line),
+	}
+}
+
+// entryTarget resolves the entry on a line of a document to a filesystem path
+func (s *Server) entryTarget(uri, text string, lineNum int) (string, infofile.Entry, bool) {
+	filePath, ok := uriToPath(uri)
+	line, found := lineAt(text, lineNum)
+	if !ok || !found {
+		return "", infofile.Entry{}, false
+	}
+	entry, ok := infofile.ParseLine(line)
+	if !ok {
+		return "", infofile.Entry{}, false
+	}
+	return filepath.Join(filepath.Dir(filePath), filepath.FromSlash(path.Clean(entry.Path))), entry, true
+}
+
+// endsPathToken reports whether token contains an unescaped space or tab, so the cursor
+// is past the path and in the annotation
+func endsPathToken(token string) bool {
+	for i := 0; i < len(token); i++ {
+		switch token[i] {
+		case '\\':
+			i++
+		case ' ', '\t':
+			return true
+		}
+	}
+	return false
+}
+
+// lineAt returns line n of text without its line ending
+func lineAt(text string, n int) (string, bool) {
+	lines := strings.Split(text, "\n")
+	if n < 0 || n >= len(lines) {
+		return "", false
+	}
+	return strings.TrimSuffix(lines[n], "\r"), true
+}
+
+// lineRange spans the text of a line, leading and trailing whitespace excluded
+func lineRange(lineNum int, line string) textRange {
+	line = strings.TrimRight(line, " \t\r")
+	start := len(line) - len(strings.TrimLeft(line, " \t"))
+	return textRange{
+		Start: position{Line: lineNum, Character: utf16Len(line[:start])},
+		End:   position{Line: lineNum, Character: utf16Len(line)},
+	}
+}
+
+// utf16Len counts the UTF-16 code units of s, the unit LSP character offsets use
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+// byteOffset converts a UTF-16 character offset in line to a byte offset
+func byteOffset(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return len(line)
+}
+
+// uriToPath converts a file:// URI to a filesystem path
+func uriToPath(uri string) (string, bool) {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" {
+		return "", false
+	}
+	p := parsed.Path
+	if len(p) >= 3 && p[0] == '/' && p[2] == ':' {
+		p = p[1:] // Windows drive letter: /C:/src
+	}
+	return filepath.FromSlash(p), true
+}
+
+// pathToURI converts an absolute filesystem path to a file:// URI
+func pathToURI(p string) string {
+	slashed := filepath.ToSlash(p)
+	if !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed
+	}
+	return (&url.URL{Scheme: "file", Path: slashed}).String()
+}
@@ -0,0 +1,288 @@
+// Package lsp implements a minimal Language Server for .info files over stdio.
+// It speaks JSON-RPC 2.0 with Content-Length framing, as editors expect, and offers:
+//
+//	Diagnostics   infofile.ValidateContent on open and every change (missing paths,
+//	              paths above the directory, duplicates, empty and broken annotations)
+//	Completion    Paths relative to the .info file's directory
+//	Definition    Jumps from an entry to the annotated file or directory
+//	Hover         The resolved target of an entry and whether it exists
+//
+// Documents are synchronized in full; each .info file is validated against the
+// directory holding it, so no workspace configuration is needed.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+// JSON-RPC 2.0 and LSP error codes
+const (
+	codeParseError           = -32700
+	codeInvalidRequest       = -32600
+	codeMethodNotFound       = -32601
+	codeInvalidParams        = -32602
+	codeServerNotInitialized = -32002
+)
+
+// MaxMessageSize bounds the Content-Length of a message, so a bad header cannot make the
+// server allocate without limit
+const MaxMessageSize = 8 << 20
+
+// Config configures a language server
+type Config struct {
+	// Filesystem .info files and their targets are read from (nil uses the OS filesystem)
+	Filesystem afero.Fs
+
+	// Version is reported to clients in serverInfo
+	Version string
+}
+
+// Server answers LSP requests for the .info files an editor opens
+type Server struct {
+	config Config
+
+	mu          sync.Mutex
+	documents   map[string]string // Open documents by URI, with their current text
+	initialized bool
+	shutdown    bool
+}
+
+// message is an incoming JSON-RPC request or notification; notifications have no ID
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is an outgoing JSON-RPC response: Result on success (null included), Error
+// otherwise, never both
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// notification is an outgoing JSON-RPC notification, such as published diagnostics
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// rpcError is a JSON-RPC error object
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewServer creates a language server
+func NewServer(config Config) *Server {
+	if config.Filesystem == nil {
+		config.Filesystem = afero.NewOsFs()
+	}
+	if config.Version == "" {
+		config.Version = "dev"
+	}
+	return &Server{config: config, documents: make(map[string]string)}
+}
+
+// Serve reads messages from in and writes responses and diagnostics to out until in is
+// closed or the client sends exit
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	for {
+		content, err := readMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(content, &msg); err != nil {
+			if err := writeMessage(out, response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: "invalid JSON: " + err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		result, notifications, rpcErr := s.dispatch(msg)
+		for _, n := range notifications {
+			if err := writeMessage(out, n); err != nil {
+				return err
+			}
+		}
+		if len(msg.ID) == 0 {
+			continue // Notifications get no response
+		}
+		resp := response{JSONRPC: "2.0", ID: msg.ID, Error: rpcErr}
+		if rpcErr == nil {
+			if resp.Result, err = json.Marshal(result); err != nil {
+				return err
+			}
+		}
+		if err := writeMessage(out, resp); err != nil {
+			return err
+		}
+	}
+}
+
+// dispatch runs a method and returns its result, the notifications it triggers and any
+// protocol error
+func (s *Server) dispatch(msg message) (interface{}, []notification, *rpcError) {
+	if msg.JSONRPC != "2.0" || msg.Method == "" {
+		return nil, nil, &rpcError{Code: codeInvalidRequest, Message: "expected a JSON-RPC 2.0 message"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if msg.Method == "initialize" {
+		s.initialized = true
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   map[string]interface{}{"openClose": true, "change": 1, "save": true},
+				"completionProvider": map[string]interface{}{"triggerCharacters": []string{"/"}},
+				"definitionProvider": true,
+				"hoverProvider":      true,
+			},
+			"serverInfo": map[string]string{"name": "treex", "version": s.config.Version},
+		}, nil, nil
+	}
+	if !s.initialized {
+		return nil, nil, &rpcError{Code: codeServerNotInitialized, Message: "initialize has not been called"}
+	}
+	if s.shutdown {
+		return nil, nil, &rpcError{Code: codeInvalidRequest, Message: "the server is shut down"}
+	}
+
+	switch msg.Method {
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil, nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil, nil
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, nil, invalidParams(msg.Method, err)
+		}
+		s.documents[params.TextDocument.URI] = params.TextDocument.Text
+		return nil, []notification{s.diagnostics(params.TextDocument.URI)}, nil
+	case "textDocument/didChange":
+		var params struct {
+			TextDocument   documentID `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, nil, invalidParams(msg.Method, err)
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil, nil
+		}
+		// Full synchronization: the last change holds the whole document
+		s.documents[params.TextDocument.URI] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		return nil, []notification{s.diagnostics(params.TextDocument.URI)}, nil
+	case "textDocument/didSave":
+		var params struct {
+			TextDocument documentID `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, nil, invalidParams(msg.Method, err)
+		}
+		// Files the entries point at may have been created or removed since the last change
+		return nil, []notification{s.diagnostics(params.TextDocument.URI)}, nil
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument documentID `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, nil, invalidParams(msg.Method, err)
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return nil, []notification{publishDiagnostics(params.TextDocument.URI, []diagnostic{})}, nil
+	case "textDocument/completion", "textDocument/definition", "textDocument/hover":
+		var params positionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, nil, invalidParams(msg.Method, err)
+		}
+		text, open := s.documents[params.TextDocument.URI]
+		if !open {
+			return nil, nil, nil
+		}
+		switch msg.Method {
+		case "textDocument/completion":
+			return s.completion(params.TextDocument.URI, text, params.Position), nil, nil
+		case "textDocument/definition":
+			return s.definition(params.TextDocument.URI, text, params.Position), nil, nil
+		default:
+			return s.hover(params.TextDocument.URI, text, params.Position), nil, nil
+		}
+	default:
+		if len(msg.ID) == 0 {
+			return nil, nil, nil // Unknown notifications are ignored
+		}
+		return nil, nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", msg.Method)}
+	}
+}
+
+// invalidParams builds the error for params that do not decode
+func invalidParams(method string, err error) *rpcError {
+	return &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid %s params: %v", method, err)}
+}
+
+// readMessage reads one Content-Length framed message
+func readMessage(reader *bufio.Reader) ([]byte, error) {
+	headers, err := textproto.NewReader(reader).ReadMIMEHeader()
+	if err != nil {
+		if len(headers) == 0 && (errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read message header: %w", err)
+	}
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", headers.Get("Content-Length"))
+	}
+	if length > MaxMessageSize {
+		return nil, fmt.Errorf("message length %d exceeds the %d byte limit", length, MaxMessageSize)
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(reader, content); err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	return content, nil
+}
+
+// writeMessage writes v as one Content-Length framed message
+func writeMessage(out io.Writer, v interface{}) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	if _, err := fmt.Fprintf(out, "Content-Length: %d\r\n\r\n%s", len(content), content); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package lsp_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/lsp"
+)
+
+const infoURI = "file:///project/.info"
+
+// rpcMessage mirrors a response or notification for assertions
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// publishedDiagnostics mirrors textDocument/publishDiagnostics params
+type publishedDiagnostics struct {
+	URI         string `json:"uri"`
+	Diagnostics []struct {
+		Range struct {
+			Start struct{ Line, Character int } `json:"start"`
+			End   struct{ Line, Character int } `json:"end"`
+		} `json:"range"`
+		Severity int    `json:"severity"`
+		Code     string `json:"code"`
+		Message  string `json:"message"`
+	} `json:"diagnostics"`
+}
+
+func newTestServer(t *testing.T) *lsp.Server {
+	t.Helper()
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"README.md": "# project",
+		"src": map[string]interface{}{
+			"main.go":  "package main",
+			"util.go":  "package main",
+			"internal": map[string]interface{}{},
+		},
+	})
+	return lsp.NewServer(lsp.Config{Filesystem: fs, Version: "test"})
+}
+
+// frame wraps a JSON message in a Content-Length header
+func frame(content string) string {
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(content), content)
+}
+
+// openDocument returns the messages initializing the server and opening .info with text
+func openDocument(text string) []string {
+	encoded, _ := json.Marshal(text)
+	return []string{
+		`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"` + infoURI + `","languageId":"info","version":1,"text":` + string(encoded) + `}}}`,
+	}
+}
+
+// exchange sends framed messages and returns the decoded output messages
+func exchange(t *testing.T, server *lsp.Server, messages ...string) []rpcMessage {
+	t.Helper()
+	var in strings.Builder
+	for _, msg := range messages {
+		in.WriteString(frame(msg))
+	}
+	var out strings.Builder
+	require.NoError(t, server.Serve(strings.NewReader(in.String()), &out))
+
+	var decoded []rpcMessage
+	reader := bufio.NewReader(strings.NewReader(out.String()))
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		var length int
+		_, err = fmt.Sscanf(header, "Content-Length: %d\r\n", &length)
+		require.NoError(t, err)
+		_, err = reader.ReadString('\n') // Blank line ending the headers
+		require.NoError(t, err)
+
+		content := make([]byte, length)
+		_, err = io.ReadFull(reader, content)
+		require.NoError(t, err)
+		var msg rpcMessage
+		require.NoError(t, json.Unmarshal(content, &msg))
+		decoded = append(decoded, msg)
+	}
+	return decoded
+}
+
+// request opens text and returns the result of a position request at line, character
+func request(t *testing.T, method, text string, line, character int) json.RawMessage {
+	t.Helper()
+	messages := append(openDocument(text), fmt.Sprintf(
+		`{"jsonrpc":"2.0","id":1,"method":"%s","params":{"textDocument":{"uri":"%s"},"position":{"line":%d,"character":%d}}}`,
+		method, infoURI, line, character))
+	responses := exchange(t, newTestServer(t), messages...)
+	require.Len(t, responses, 3)
+	require.Nil(t, responses[2].Error)
+	return responses[2].Result
+}
+
+func TestInitialize(t *testing.T) {
+	responses := exchange(t, newTestServer(t), `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	require.Len(t, responses, 1)
+
+	var result struct {
+		Capabilities struct {
+			DefinitionProvider bool `json:"definitionProvider"`
+			HoverProvider      bool `json:"hoverProvider"`
+			CompletionProvider struct {
+				TriggerCharacters []string `json:"triggerCharacters"`
+			} `json:"completionProvider"`
+		} `json:"capabilities"`
+		ServerInfo struct {
+			Version string `json:"version"`
+		} `json:"serverInfo"`
+	}
+	require.NoError(t, json.Unmarshal(responses[0].Result, &result))
+	assert.True(t, result.Capabilities.DefinitionProvider)
+	assert.True(t, result.Capabilities.HoverProvider)
+	assert.Equal(t, []string{"/"}, result.Capabilities.CompletionProvider.TriggerCharacters)
+	assert.Equal(t, "test", result.ServerInfo.Version)
+}
+
+func TestRequestBeforeInitialize(t *testing.T) {
+	responses := exchange(t, newTestServer(t),
+		`{"jsonrpc":"2.0","id":1,"method":"textDocument/hover","params":{"textDocument":{"uri":"`+infoURI+`"},"position":{"line":0,"character":0}}}`)
+	require.Len(t, responses, 1)
+	require.NotNil(t, responses[0].Error)
+	assert.Equal(t, -32002, responses[0].Error.Code)
+	assert.Nil(t, responses[0].Result, "error responses carry no result member")
+}
+
+func TestExitStopsServing(t *testing.T) {
+	responses := exchange(t, newTestServer(t),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"initialize","params":{}}`)
+	require.Len(t, responses, 2)
+	assert.Equal(t, "null", string(responses[1].Result))
+}
+
+func TestRejectsOversizeMessages(t *testing.T) {
+	in := fmt.Sprintf("Content-Length: %d\r\n\r\n{}", lsp.MaxMessageSize+1)
+	var out strings.Builder
+	err := newTestServer(t).Serve(strings.NewReader(in), &out)
+	assert.ErrorContains(t, err, "exceeds the")
+	assert.Empty(t, out.String())
+}
+
+func TestDiagnostics(t *testing.T) {
+	text := "README.md  Overview\n  missing.go  Gone\nsrc/main.go  Entry point\nsrc/main.go  Again\n../outside  Above\n"
+	responses := exchange(t, newTestServer(t), openDocument(text)...)
+	require.Len(t, responses, 2)
+	assert.Equal(t, "textDocument/publishDiagnostics", responses[1].Method)
+
+	var published publishedDiagnostics
+	require.NoError(t, json.Unmarshal(responses[1].Params, &published))
+	assert.Equal(t, infoURI, published.URI)
+
+	codes := map[string]int{}
+	for _, d := range published.Diagnostics {
+		codes[d.Code] = d.Range.Start.Line
+		assert.Equal(t, 1, d.Severity, d.Code)
+	}
+	assert.Equal(t, map[string]int{"missing-path": 1, "duplicate": 3, "outside-dir": 4}, codes)
+
+	// Ranges skip the indentation
+	for _, d := range published.Diagnostics {
+		if d.Code == "missing-path" {
+			assert.Equal(t, 2, d.Range.Start.Character)
+			assert.Equal(t, len("  missing.go  Gone"), d.Range.End.Character)
+		}
+	}
+}
+
+func TestDiagnosticsFollowChanges(t *testing.T) {
+	messages := append(openDocument("missing.go  Gone\n"),
+		`{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"`+infoURI+`","version":2},"contentChanges":[{"text":"README.md  Overview\n"}]}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didClose","params":{"textDocument":{"uri":"`+infoURI+`"}}}`)
+	responses := exchange(t, newTestServer(t), messages...)
+	require.Len(t, responses, 4)
+
+	var opened, changed, closed publishedDiagnostics
+	require.NoError(t, json.Unmarshal(responses[1].Params, &opened))
+	require.NoError(t, json.Unmarshal(responses[2].Params, &changed))
+	require.NoError(t, json.Unmarshal(responses[3].Params, &closed))
+	assert.Len(t, opened.Diagnostics, 1)
+	assert.Empty(t, changed.Diagnostics)
+	assert.Empty(t, closed.Diagnostics)
+}
+
+func TestCompletion(t *testing.T) {
+	type item struct {
+		Label    string `json:"label"`
+		Kind     int    `json:"kind"`
+		TextEdit struct {
+			Range struct {
+				Start struct{ Line, Character int } `json:"start"`
+			} `json:"range"`
+			NewText string `json:"newText"`
+		} `json:"textEdit"`
+	}
+	complete := func(t *testing.T, text string, line, character int) []item {
+		var result struct {
+			Items []item `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(request(t, "textDocument/completion", text, line, character), &result))
+		return result.Items
+	}
+
+	t.Run("top level", func(t *testing.T) {
+		items := complete(t, "READ", 0, 4)
+		labels := []string{}
+		for _, it := range items {
+			labels = append(labels, it.Label)
+		}
+		assert.ElementsMatch(t, []string{"README.md", "src/"}, labels)
+	})
+
+	t.Run("subdirectory", func(t *testing.T) {
+		items := complete(t, "README.md  Overview\nsrc/ma", 1, 6)
+		require.Len(t, items, 3)
+		for _, it := range items {
+			assert.Equal(t, 4, it.TextEdit.Range.Start.Character, "only the last segment is replaced")
+			if it.Label == "internal/" {
+				assert.Equal(t, 19, it.Kind)
+			} else {
+				assert.Equal(t, 17, it.Kind)
+			}
+		}
+	})
+
+	t.Run("inside the annotation", func(t *testing.T) {
+		assert.Empty(t, complete(t, "README.md  Over", 0, 15))
+	})
+
+	t.Run("comment", func(t *testing.T) {
+		assert.Empty(t, complete(t, "# src/", 0, 6))
+	})
+}
+
+func TestDefinition(t *testing.T) {
+	var location struct {
+		URI string `json:"uri"`
+	}
+	require.NoError(t, json.Unmarshal(request(t, "textDocument/definition", "README.md  Overview\nsrc/main.go  Entry\n", 1, 3), &location))
+	assert.Equal(t, "file:///project/src/main.go", location.URI)
+
+	assert.Equal(t, "null", string(request(t, "textDocument/definition", "missing.go  Gone\n", 0, 3)))
+}
+
+func TestHover(t *testing.T) {
+	var hover struct {
+		Contents struct {
+			Kind  string `json:"kind"`
+			Value string `json:"value"`
+		} `json:"contents"`
+	}
+	require.NoError(t, json.Unmarshal(request(t, "textDocument/hover", "src  Sources\n", 0, 1), &hover))
+	assert.Equal(t, "markdown", hover.Contents.Kind)
+	assert.Contains(t, hover.Contents.Value, "directory")
+	assert.Contains(t, hover.Contents.Value, "/project/src")
+
+	require.NoError(t, json.Unmarshal(request(t, "textDocument/hover", "gone.txt  Removed\n", 0, 1), &hover))
+	assert.Contains(t, hover.Contents.Value, "does not exist")
+}
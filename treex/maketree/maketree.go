@@ -0,0 +1,162 @@
+// Package maketree creates files and directories from a tree diagram, optionally with
+// file contents and annotations, so a project can be scaffolded from a drawing.
+//
+// File contents come from a fenced block drawn below the entry (see treetext.Parse), the
+// string values of structure input (see ParseStructure) or from an "@template:<name>"
+// reference in the entry's annotation. Annotations left after
+// removing the reference are written to .info files.
+package maketree
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"text/template"
+
+	"github.com/spf13/afero"
+	"treex/treex/plugins/infofile"
+	"treex/treex/treetext"
+)
+
+// MakeTreeOptions configures MakeTree
+type MakeTreeOptions struct {
+	TemplateDir string // Directory searched for templates before the built-ins (empty = built-ins only)
+	Overwrite   bool   // Replace files that already exist instead of skipping them
+	DryRun      bool   // Report what would be created without writing anything
+}
+
+// MakeTreeResult reports what MakeTree created; paths are relative to the root
+type MakeTreeResult struct {
+	Directories []string          // Directories created
+	Files       []string          // Files created or overwritten
+	Skipped     []string          // Existing files left untouched
+	Templates   map[string]string // File path -> name of the template applied
+	InfoFiles   []string          // .info files written
+}
+
+// plannedFile is a file entry with its resolved content source
+type plannedFile struct {
+	entry    treetext.Entry
+	notes    string
+	template *template.Template
+}
+
+// MakeTree creates the entries below root on fs
+//
+// Every entry, template and content block is checked before anything is written. Existing
+// directories are reused and existing files are skipped unless Overwrite is set.
+func MakeTree(fs afero.Fs, root string, entries []treetext.Entry, opts MakeTreeOptions) (*MakeTreeResult, error) {
+	var dirs []treetext.Entry
+	var files []plannedFile
+	var annotations []infofile.Annotation
+
+	for _, entry := range entries {
+		notes, templateName := splitTemplateRef(entry.Notes)
+		if entry.IsDir {
+			if entry.HasContent || templateName != "" {
+				return nil, fmt.Errorf("%sdirectory %s cannot have content", location(entry), entry.Path)
+			}
+			dirs = append(dirs, entry)
+		} else {
+			planned := plannedFile{entry: entry, notes: notes}
+			if templateName != "" {
+				if entry.Content != "" {
+					return nil, fmt.Errorf("%s%s has both a content block and a template", location(entry), entry.Path)
+				}
+				tmpl, err := loadTemplate(fs, opts.TemplateDir, templateName)
+				if err != nil {
+					return nil, fmt.Errorf("%s%w", location(entry), err)
+				}
+				planned.template = tmpl
+			}
+			files = append(files, planned)
+		}
+		if notes != "" {
+			annotations = append(annotations, infofile.Annotation{Path: entry.Path, Notes: notes})
+		}
+	}
+
+	// A dry run writes into a throwaway layer over the real tree
+	if opts.DryRun {
+		fs = afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(fs), afero.NewMemMapFs())
+	}
+
+	result := &MakeTreeResult{Templates: make(map[string]string)}
+	for _, dir := range dirs {
+		created, err := makeDir(fs, root, dir.Path)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			result.Directories = append(result.Directories, dir.Path)
+		}
+	}
+
+	for _, file := range files {
+		fullPath := filepath.Join(root, filepath.FromSlash(file.entry.Path))
+		if exists, _ := afero.Exists(fs, fullPath); exists && !opts.Overwrite {
+			result.Skipped = append(result.Skipped, file.entry.Path)
+			continue
+		}
+
+		content := []byte(file.entry.Content)
+		if file.template != nil {
+			rendered, err := execute(file.template, templateData(root, file.entry.Path, file.notes))
+			if err != nil {
+				return nil, err
+			}
+			content = rendered
+			result.Templates[file.entry.Path] = file.template.Name()
+		}
+
+		created, err := makeDir(fs, root, path.Dir(file.entry.Path))
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			result.Directories = append(result.Directories, path.Dir(file.entry.Path))
+		}
+		if err := afero.WriteFile(fs, fullPath, content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", file.entry.Path, err)
+		}
+		result.Files = append(result.Files, file.entry.Path)
+	}
+
+	if len(annotations) > 0 {
+		infoFiles, err := infofile.AddAnnotations(fs, root, annotations)
+		if err != nil {
+			return nil, err
+		}
+		result.InfoFiles = infoFiles
+	}
+
+	sort.Strings(result.Directories)
+	return result, nil
+}
+
+// location prefixes errors with the entry's line in a diagram (structure input has none)
+func location(entry treetext.Entry) string {
+	if entry.Line == 0 {
+		return ""
+	}
+	return fmt.Sprintf("line %d: ", entry.Line)
+}
+
+// makeDir creates a directory (relative to root) and its parents, reporting whether it was new
+func makeDir(fs afero.Fs, root, dir string) (bool, error) {
+	if dir == "." || dir == "" {
+		return false, nil
+	}
+	fullPath := filepath.Join(root, filepath.FromSlash(dir))
+	if info, err := fs.Stat(fullPath); err == nil {
+		if !info.IsDir() {
+			return false, fmt.Errorf("cannot create directory %s: a file is in the way", dir)
+		}
+		return false, nil
+	}
+	if err := fs.MkdirAll(fullPath, 0755); err != nil {
+		return false, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	return true, nil
+}
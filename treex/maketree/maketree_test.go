@@ -0,0 +1,117 @@
+package maketree_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/maketree"
+	"treex/treex/treetext"
+)
+
+// parse reads a diagram, failing the test on errors
+func parse(t *testing.T, diagram string) []treetext.Entry {
+	t.Helper()
+	entries, err := treetext.Parse(strings.NewReader(diagram))
+	require.NoError(t, err)
+	return entries
+}
+
+// readFile returns a file's content, failing the test when it is missing
+func readFile(t *testing.T, fs afero.Fs, path string) string {
+	t.Helper()
+	content, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	return string(content)
+}
+
+const scaffold = `my-app
+├─ cmd
+│  └─ my-app
+│     └─ main.go   @template:go-main Program entry point
+├─ internal
+│  └─ store
+│     └─ store.go   Persistence layer @template:go-package
+├─ README.md   @template:readme
+├─ LICENSE   @template:license
+└─ config.yaml
+   ` + "```" + `
+   port: 8080
+   ` + "```"
+
+func TestMakeTree(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/templates", map[string]interface{}{
+		"license.tmpl": "Copyright {{.DirName}}\n",
+	})
+
+	result, err := maketree.MakeTree(fs, "/my-app", parse(t, scaffold), maketree.MakeTreeOptions{TemplateDir: "/templates"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"cmd", "cmd/my-app", "internal", "internal/store"}, result.Directories)
+	assert.Equal(t, []string{"cmd/my-app/main.go", "internal/store/store.go", "README.md", "LICENSE", "config.yaml"}, result.Files)
+	assert.Equal(t, map[string]string{
+		"cmd/my-app/main.go":      "go-main",
+		"internal/store/store.go": "go-package",
+		"README.md":               "readme",
+		"LICENSE":                 "license",
+	}, result.Templates)
+	assert.Equal(t, []string{"cmd/my-app/.info", "internal/store/.info"}, result.InfoFiles)
+
+	assert.Equal(t, "package main\n\nfunc main() {\n}\n", readFile(t, fs, "/my-app/cmd/my-app/main.go"))
+	assert.Equal(t, "// Package store Persistence layer\npackage store\n", readFile(t, fs, "/my-app/internal/store/store.go"))
+	assert.Equal(t, "# my-app\n", readFile(t, fs, "/my-app/README.md"))
+	assert.Equal(t, "Copyright my-app\n", readFile(t, fs, "/my-app/LICENSE"))
+	assert.Equal(t, "port: 8080\n", readFile(t, fs, "/my-app/config.yaml"))
+	assert.Equal(t, "main.go  Program entry point\n", readFile(t, fs, "/my-app/cmd/my-app/.info"))
+}
+
+func TestMakeTreeExistingFiles(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/app", map[string]interface{}{"README.md": "kept"})
+	diagram := ".\n└─ README.md   @template:readme\n"
+
+	result, err := maketree.MakeTree(fs, "/app", parse(t, diagram), maketree.MakeTreeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md"}, result.Skipped)
+	assert.Empty(t, result.Templates)
+	assert.Equal(t, "kept", readFile(t, fs, "/app/README.md"))
+
+	result, err = maketree.MakeTree(fs, "/app", parse(t, diagram), maketree.MakeTreeOptions{Overwrite: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md"}, result.Files)
+	assert.Equal(t, "# app\n", readFile(t, fs, "/app/README.md"))
+}
+
+func TestMakeTreeDryRun(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/app", map[string]interface{}{})
+
+	result, err := maketree.MakeTree(fs, "/app", parse(t, ".\n└─ src\n   └─ main.go   Entry point\n"), maketree.MakeTreeOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"src"}, result.Directories)
+	assert.Equal(t, []string{"src/main.go"}, result.Files)
+	assert.Equal(t, []string{"src/.info"}, result.InfoFiles)
+
+	exists, _ := afero.Exists(fs, "/app/src")
+	assert.False(t, exists, "dry run writes nothing")
+}
+
+func TestMakeTreeErrors(t *testing.T) {
+	tests := map[string]string{
+		".\n└─ main.go   @template:nope\n":                       `line 2: unknown template "nope"`,
+		".\n└─ src/   @template:go-main\n":                       "line 2: directory src cannot have content",
+		".\n└─ a.go   @template:go-main\n   ```\n   x\n   ```\n": "both a content block and a template",
+	}
+	for diagram, expected := range tests {
+		fs := testutil.NewTestFS()
+		_, err := maketree.MakeTree(fs, "/app", parse(t, diagram), maketree.MakeTreeOptions{})
+		assert.ErrorContains(t, err, expected)
+
+		exists, _ := afero.Exists(fs, "/app")
+		assert.False(t, exists, "nothing is written for %q", diagram)
+	}
+}
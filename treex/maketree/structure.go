@@ -0,0 +1,121 @@
+package maketree
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"treex/treex/treetext"
+)
+
+// InfoKey holds the annotations of a directory's entries in structure input
+const InfoKey = "__info"
+
+// ParseStructure decodes the nested map structure used by test fixtures and tools:
+// a string value is a file with that content, a map is a directory and null is an empty
+// directory. A directory's "__info" key maps entry names to annotations:
+//
+//	{"cmd": {"main.go": "package main\n", "__info": {"main.go": "Entry point"}}}
+//
+// format is "json" or "yaml"; entries are returned in name order, parents first.
+func ParseStructure(content []byte, format string) ([]treetext.Entry, error) {
+	var structure map[string]interface{}
+	var err error
+	switch format {
+	case "json":
+		err = json.Unmarshal(content, &structure)
+	case "yaml":
+		err = yaml.Unmarshal(content, &structure)
+	default:
+		return nil, fmt.Errorf("unknown structure format %q (expected json or yaml)", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s structure: %w", format, err)
+	}
+
+	var entries []treetext.Entry
+	if err := appendStructure(&entries, "", structure); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// appendStructure appends the entries of one directory level below dir
+func appendStructure(entries *[]treetext.Entry, dir string, structure map[string]interface{}) error {
+	notes, err := structureNotes(dir, structure)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(structure))
+	for name := range structure {
+		if name != InfoKey {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+			return fmt.Errorf("invalid entry name %q in %s", name, displayDir(dir))
+		}
+		entryPath := path.Join(dir, name)
+		entry := treetext.Entry{Path: entryPath, Notes: notes[name]}
+		delete(notes, name)
+
+		switch value := structure[name].(type) {
+		case string:
+			entry.Content, entry.HasContent = value, true
+			*entries = append(*entries, entry)
+		case nil:
+			entry.IsDir = true
+			*entries = append(*entries, entry)
+		case map[string]interface{}:
+			entry.IsDir = true
+			*entries = append(*entries, entry)
+			if err := appendStructure(entries, entryPath, value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s: unsupported value of type %T (use a string for files, a map for directories)", entryPath, value)
+		}
+	}
+
+	for name := range notes {
+		return fmt.Errorf("%s in %s annotates %q, which is not an entry", InfoKey, displayDir(dir), name)
+	}
+	return nil
+}
+
+// structureNotes reads the "__info" map of a directory level
+func structureNotes(dir string, structure map[string]interface{}) (map[string]string, error) {
+	notes := make(map[string]string)
+	raw, ok := structure[InfoKey]
+	if !ok || raw == nil {
+		return notes, nil
+	}
+
+	info, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s in %s must map entry names to annotations", InfoKey, displayDir(dir))
+	}
+	for name, value := range info {
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s in %s: annotation of %q must be a string", InfoKey, displayDir(dir), name)
+		}
+		notes[name] = text
+	}
+	return notes, nil
+}
+
+// displayDir names a directory level in errors
+func displayDir(dir string) string {
+	if dir == "" {
+		return "the root"
+	}
+	return dir
+}
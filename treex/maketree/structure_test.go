@@ -0,0 +1,71 @@
+package maketree_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/maketree"
+	"treex/treex/treetext"
+)
+
+func TestParseStructure(t *testing.T) {
+	json := `{
+		"README.md": "# app\n",
+		"cmd": {
+			"main.go": "",
+			"__info": {"main.go": "Entry point @template:go-main"}
+		},
+		"docs": null,
+		"__info": {"cmd": "Commands"}
+	}`
+
+	entries, err := maketree.ParseStructure([]byte(json), "json")
+	require.NoError(t, err)
+	assert.Equal(t, []treetext.Entry{
+		{Path: "README.md", Content: "# app\n", HasContent: true},
+		{Path: "cmd", IsDir: true, Notes: "Commands"},
+		{Path: "cmd/main.go", Notes: "Entry point @template:go-main", HasContent: true},
+		{Path: "docs", IsDir: true},
+	}, entries)
+
+	fs := testutil.NewTestFS()
+	result, err := maketree.MakeTree(fs, "/app", entries, maketree.MakeTreeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cmd/main.go": "go-main"}, result.Templates)
+	assert.Equal(t, "package main\n\nfunc main() {\n}\n", readFile(t, fs, "/app/cmd/main.go"))
+	assert.Equal(t, "cmd  Commands\n", readFile(t, fs, "/app/.info"))
+	assert.Equal(t, "main.go  Entry point\n", readFile(t, fs, "/app/cmd/.info"))
+}
+
+func TestParseStructureYAML(t *testing.T) {
+	yaml := "src:\n  lib.py: |\n    \"\"\"Library.\"\"\"\n  __info:\n    lib.py: Shared code\n"
+
+	entries, err := maketree.ParseStructure([]byte(yaml), "yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []treetext.Entry{
+		{Path: "src", IsDir: true},
+		{Path: "src/lib.py", Notes: "Shared code", Content: "\"\"\"Library.\"\"\"\n", HasContent: true},
+	}, entries)
+}
+
+func TestParseStructureErrors(t *testing.T) {
+	tests := []struct {
+		content  string
+		format   string
+		expected string
+	}{
+		{`{"a": 1}`, "json", "a: unsupported value of type float64"},
+		{`{"a/b": ""}`, "json", `invalid entry name "a/b" in the root`},
+		{`{"src": {"__info": {"gone.go": "x"}}}`, "json", `__info in src annotates "gone.go", which is not an entry`},
+		{`{"__info": "text"}`, "json", "__info in the root must map entry names"},
+		{`{"__info": {"a": 1}, "a": ""}`, "json", `annotation of "a" must be a string`},
+		{`{`, "json", "invalid json structure"},
+		{`a: b`, "toml", `unknown structure format "toml"`},
+	}
+	for _, tt := range tests {
+		_, err := maketree.ParseStructure([]byte(tt.content), tt.format)
+		assert.ErrorContains(t, err, tt.expected, tt.content)
+	}
+}
@@ -0,0 +1,131 @@
+package maketree
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/afero"
+	"treex/treex/pathutil"
+)
+
+// templateRef matches an "@template:<name>" reference in an annotation
+var templateRef = regexp.MustCompile(`(^|\s)@template:([A-Za-z0-9_.-]+)`)
+
+// builtinTemplates are available without a template directory
+// Templates are text/template sources executed with TemplateData
+var builtinTemplates = map[string]string{
+	"go-main":    "package main\n\nfunc main() {\n}\n",
+	"go-package": "{{if .Notes}}// Package {{.Package}} {{.Notes}}\n{{end}}package {{.Package}}\n",
+	"go-test":    "package {{.Package}}\n\nimport \"testing\"\n",
+	"python":     "\"\"\"{{if .Notes}}{{.Notes}}{{else}}{{.Name}}{{end}}\"\"\"\n",
+	"readme":     "# {{.DirName}}\n{{if .Notes}}\n{{.Notes}}\n{{end}}",
+	"gitignore":  "# Build output\n/bin/\n/dist/\n\n# Editors\n.idea/\n.vscode/\n*.swp\n",
+}
+
+// TemplateData is available to templates
+type TemplateData struct {
+	Name    string // File name ("main.go")
+	Path    string // File path relative to the tree root ("cmd/app/main.go")
+	Dir     string // Parent directory relative to the root ("cmd/app", "." at the root)
+	DirName string // Name of the parent directory ("app"; the root's base name at the root)
+	Package string // Go package name derived from DirName ("app")
+	Notes   string // Annotation of the file, without the template reference
+}
+
+// BuiltinTemplateNames lists the built-in templates
+func BuiltinTemplateNames() []string {
+	names := make([]string, 0, len(builtinTemplates))
+	for name := range builtinTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultTemplateDir returns the user templates directory using the XDG config directory
+func DefaultTemplateDir() string {
+	return pathutil.ConfigPath("templates")
+}
+
+// splitTemplateRef removes an "@template:<name>" reference from notes, returning the name
+func splitTemplateRef(notes string) (string, string) {
+	match := templateRef.FindStringSubmatchIndex(notes)
+	if match == nil {
+		return notes, ""
+	}
+	name := notes[match[4]:match[5]]
+	rest := notes[:match[0]] + " " + notes[match[1]:]
+	return strings.Join(strings.Fields(rest), " "), name
+}
+
+// loadTemplate finds a template in dir ("<name>" or "<name>.tmpl"), then among the built-ins
+func loadTemplate(fs afero.Fs, dir, name string) (*template.Template, error) {
+	if dir != "" {
+		for _, candidate := range []string{name, name + ".tmpl"} {
+			content, err := afero.ReadFile(fs, filepath.Join(dir, candidate))
+			if err != nil {
+				continue
+			}
+			parsed, err := template.New(name).Option("missingkey=error").Parse(string(content))
+			if err != nil {
+				return nil, fmt.Errorf("invalid template %q: %w", name, err)
+			}
+			return parsed, nil
+		}
+	}
+
+	source, ok := builtinTemplates[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown template %q (built-in: %s)", name, strings.Join(BuiltinTemplateNames(), ", "))
+	}
+	return template.Must(template.New(name).Parse(source)), nil
+}
+
+// templateData describes the file at filePath for a template
+func templateData(root, filePath, notes string) TemplateData {
+	dir := path.Dir(filePath)
+	dirName := path.Base(dir)
+	if dir == "." {
+		dirName = filepath.Base(root)
+	}
+	return TemplateData{
+		Name:    path.Base(filePath),
+		Path:    filePath,
+		Dir:     dir,
+		DirName: dirName,
+		Package: packageName(dirName),
+		Notes:   notes,
+	}
+}
+
+// packageName turns a directory name into a Go package name
+func packageName(dirName string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(dirName) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9' && b.Len() > 0:
+			b.WriteRune(r)
+		case r == '-' || r == '_' || r == '.':
+			// Separators are dropped, as in "go-pkg" -> "gopkg"
+		}
+	}
+	if b.Len() == 0 {
+		return "main"
+	}
+	return b.String()
+}
+
+// execute renders a template for data
+func execute(tmpl *template.Template, data TemplateData) ([]byte, error) {
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("template %q failed for %s: %w", tmpl.Name(), data.Path, err)
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,187 @@
+// Package manual writes the reference pages of a command tree: one man page (section 1)
+// and one markdown page per command, built from the cobra commands' own help text.
+package manual
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Options configures the pages
+type Options struct {
+	Version string    // Shown in the man page footer
+	Date    time.Time // Shown in the man page footer (set it for reproducible builds)
+	// Sections adds sections to the page of a command, keyed by command path
+	// ("treex make-tree"); they follow the examples
+	Sections map[string][]Section
+}
+
+// Section is an extra page section: a paragraph, then a list of terms and their texts
+type Section struct {
+	Title   string
+	Text    string
+	Entries []Entry
+}
+
+// Entry is a term described in a Section
+type Entry struct {
+	Term string
+	Text string
+}
+
+// Page is the reference page of one command
+type Page struct {
+	Name        string // File name without extension: the command path joined by "-"
+	CommandPath string // "treex make-tree"
+	Short       string
+	Description string // The long description, or the short one
+	UseLine     string
+	Example     string
+	Flags       []Flag // The command's own flags
+	GlobalFlags []Flag // Flags inherited from parent commands
+	Sections    []Section
+	SeeAlso     []Link // Parent and subcommands
+	Version     string
+	Date        string // "January 2006"
+}
+
+// Flag is a flag as listed on a page
+type Flag struct {
+	Names   string // "-l, --level"
+	Arg     string // Argument name, e.g. "int" ("" for boolean flags)
+	Usage   string
+	Default string // "" when the default is the zero value
+}
+
+// Link is a reference to the page of another command
+type Link struct {
+	Name        string
+	CommandPath string
+}
+
+// Pages returns the pages of root and its available subcommands, depth first
+// Hidden and deprecated commands and help are left out.
+func Pages(root *cobra.Command, options Options) []Page {
+	var pages []Page
+	var visit func(command *cobra.Command)
+	visit = func(command *cobra.Command) {
+		pages = append(pages, newPage(command, options))
+		for _, sub := range availableCommands(command) {
+			visit(sub)
+		}
+	}
+	visit(root)
+	return pages
+}
+
+// newPage describes command
+func newPage(command *cobra.Command, options Options) Page {
+	page := Page{
+		Name:        pageName(command),
+		CommandPath: command.CommandPath(),
+		Short:       command.Short,
+		Description: strings.TrimSpace(command.Long),
+		UseLine:     command.UseLine(),
+		Example:     strings.TrimRight(command.Example, "\n"),
+		Flags:       flagList(command.NonInheritedFlags()),
+		GlobalFlags: flagList(command.InheritedFlags()),
+		Sections:    options.Sections[command.CommandPath()],
+		Version:     options.Version,
+		Date:        options.Date.Format("January 2006"),
+	}
+	if page.Description == "" {
+		page.Description = command.Short
+	}
+	if parent := command.Parent(); parent != nil {
+		page.SeeAlso = append(page.SeeAlso, Link{Name: pageName(parent), CommandPath: parent.CommandPath()})
+	}
+	for _, sub := range availableCommands(command) {
+		page.SeeAlso = append(page.SeeAlso, Link{Name: pageName(sub), CommandPath: sub.CommandPath()})
+	}
+	return page
+}
+
+// availableCommands returns the subcommands that get pages
+func availableCommands(command *cobra.Command) []*cobra.Command {
+	var commands []*cobra.Command
+	for _, sub := range command.Commands() {
+		if sub.IsAvailableCommand() && sub.Name() != "help" {
+			commands = append(commands, sub)
+		}
+	}
+	return commands
+}
+
+// pageName is the file name of a command's page: "treex-make-tree"
+func pageName(command *cobra.Command) string {
+	return strings.ReplaceAll(command.CommandPath(), " ", "-")
+}
+
+// flagList lists the visible flags of a set in pflag's order (by name)
+func flagList(flags *pflag.FlagSet) []Flag {
+	var list []Flag
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden || flag.Deprecated != "" || flag.Name == "help" {
+			return
+		}
+		arg, usage := pflag.UnquoteUsage(flag)
+		names := "--" + flag.Name
+		if flag.Shorthand != "" && flag.ShorthandDeprecated == "" {
+			names = "-" + flag.Shorthand + ", " + names
+		}
+		list = append(list, Flag{Names: names, Arg: arg, Usage: usage, Default: flagDefault(flag)})
+	})
+	return list
+}
+
+// flagDefault returns the default a page shows, or "" for zero values
+func flagDefault(flag *pflag.Flag) string {
+	switch flag.DefValue {
+	case "", "false", "0", "[]":
+		return ""
+	}
+	if flag.Value.Type() == "string" {
+		return fmt.Sprintf("%q", flag.DefValue)
+	}
+	return flag.DefValue
+}
+
+// WriteMan writes one man page per page to dir as <name>.1, returning the files written
+func WriteMan(fs afero.Fs, dir string, pages []Page) ([]string, error) {
+	return writePages(fs, dir, ".1", manTemplate, pages)
+}
+
+// WriteMarkdown writes one markdown page per page to dir as <name>.md, returning the
+// files written
+func WriteMarkdown(fs afero.Fs, dir string, pages []Page) ([]string, error) {
+	return writePages(fs, dir, ".md", markdownTemplate, pages)
+}
+
+// writePages executes tmpl for each page into dir
+func writePages(fs afero.Fs, dir, extension string, tmpl *template.Template, pages []Page) ([]string, error) {
+	if err := fs.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	written := make([]string, 0, len(pages))
+	for _, page := range pages {
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, page); err != nil {
+			return written, fmt.Errorf("failed to render the page of %s: %w", page.CommandPath, err)
+		}
+		file := filepath.Join(dir, page.Name+extension)
+		if err := afero.WriteFile(fs, file, out.Bytes(), 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", file, err)
+		}
+		written = append(written, file)
+	}
+	return written, nil
+}
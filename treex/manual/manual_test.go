@@ -0,0 +1,92 @@
+package manual_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex/manual"
+)
+
+// commandTree builds "app" with a "sync" subcommand and a hidden "debug" one
+func commandTree() *cobra.Command {
+	root := &cobra.Command{Use: "app", Short: "Manage things"}
+	root.PersistentFlags().IntP("level", "l", 0, "Maximum depth")
+
+	sync := &cobra.Command{
+		Use:   "sync [path]",
+		Short: "Copy things",
+		Long: "Copy things from -source to the *target*.\n\n" +
+			"Layout:\n\n" +
+			"  a/\n" +
+			"  .hidden\\path\n",
+		Example: "  app sync --dry-run src",
+		Run:     func(cmd *cobra.Command, args []string) {},
+	}
+	sync.Flags().Bool("dry-run", false, "Show what would be copied")
+	sync.Flags().String("mode", "fast", "Copy `strategy`: fast or safe")
+
+	debug := &cobra.Command{Use: "debug", Hidden: true, Run: func(cmd *cobra.Command, args []string) {}}
+	root.AddCommand(sync, debug)
+	return root
+}
+
+func TestPages(t *testing.T) {
+	pages := manual.Pages(commandTree(), manual.Options{
+		Version:  "1.2.0",
+		Date:     time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		Sections: map[string][]manual.Section{"app sync": {{Title: "Notes", Text: "Extra"}}},
+	})
+	require.Len(t, pages, 2, "hidden commands get no page")
+
+	root, sync := pages[0], pages[1]
+	assert.Equal(t, "app", root.Name)
+	assert.Equal(t, "Manage things", root.Description, "the short description stands in for a missing long one")
+	assert.Equal(t, []manual.Link{{Name: "app-sync", CommandPath: "app sync"}}, root.SeeAlso)
+
+	assert.Equal(t, "app-sync", sync.Name)
+	assert.Equal(t, "March 2024", sync.Date)
+	assert.Equal(t, []manual.Flag{
+		{Names: "--dry-run", Usage: "Show what would be copied"},
+		{Names: "--mode", Arg: "strategy", Usage: "Copy strategy: fast or safe", Default: `"fast"`},
+	}, sync.Flags)
+	assert.Equal(t, []manual.Flag{{Names: "-l, --level", Arg: "int", Usage: "Maximum depth"}}, sync.GlobalFlags)
+	assert.Equal(t, []manual.Section{{Title: "Notes", Text: "Extra"}}, sync.Sections)
+	assert.Equal(t, []manual.Link{{Name: "app", CommandPath: "app"}}, sync.SeeAlso)
+}
+
+func TestWriteMan(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	files, err := manual.WriteMan(fs, "/out", manual.Pages(commandTree(), manual.Options{Version: "1.2.0"}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/out/app.1", "/out/app-sync.1"}, files)
+
+	content, err := afero.ReadFile(fs, "/out/app-sync.1")
+	require.NoError(t, err)
+	page := string(content)
+	assert.True(t, strings.HasPrefix(page, `.TH "APP-SYNC" "1"`), page)
+	assert.Contains(t, page, "app\\-sync \\- Copy things\n")
+	assert.Contains(t, page, "Copy things from \\-source to the *target*.\n.PP\nLayout:\n.PP\n.nf\n  a/\n  .hidden\\epath\n.fi\n")
+	assert.Contains(t, page, ".TP\n\\fB\\-\\-mode\\fR \\fIstrategy\\fR\nCopy strategy: fast or safe (default \"fast\")\n")
+	assert.Contains(t, page, ".SH EXAMPLES\n.nf\n  app sync \\-\\-dry\\-run src\n.fi\n")
+	assert.Contains(t, page, ".SH SEE ALSO\n.BR app (1)\n")
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_, err := manual.WriteMarkdown(fs, "/out", manual.Pages(commandTree(), manual.Options{}))
+	require.NoError(t, err)
+
+	content, err := afero.ReadFile(fs, "/out/app-sync.md")
+	require.NoError(t, err)
+	page := string(content)
+	assert.True(t, strings.HasPrefix(page, "# app sync\n\nCopy things\n\n## Synopsis\n\n```\napp sync [path] [flags]\n```\n"), page)
+	assert.Contains(t, page, "Copy things from -source to the \\*target\\*.\n\nLayout:\n\n```text\n  a/\n  .hidden\\path\n```\n")
+	assert.Contains(t, page, "- `--mode strategy`: Copy strategy: fast or safe (default `\"fast\"`)\n")
+	assert.Contains(t, page, "## Global options\n\n- `-l, --level int`: Maximum depth\n")
+	assert.Contains(t, page, "## See also\n\n- [app](app.md)\n")
+}
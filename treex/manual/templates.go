@@ -0,0 +1,218 @@
+package manual
+
+import (
+	"strings"
+	"text/template"
+)
+
+// templateFuncs escape help text for roff and markdown
+var templateFuncs = template.FuncMap{
+	"upper":      strings.ToUpper,
+	"roff":       roff,
+	"paragraphs": roffParagraphs,
+	"lines":      roffLines,
+	"md":         markdown,
+	"mdblocks":   markdownBlocks,
+}
+
+// manTemplate renders a page as a man(7) page
+var manTemplate = template.Must(template.New("man").Funcs(templateFuncs).Parse(`.TH "{{upper .Name}}" "1" "{{.Date}}" "treex {{roff .Version}}" "treex manual"
+.SH NAME
+{{roff .Name}} \- {{roff .Short}}
+.SH SYNOPSIS
+.nf
+\fB{{roff .UseLine}}\fR
+.fi
+.SH DESCRIPTION
+{{paragraphs .Description}}
+{{- if .Flags}}
+.SH OPTIONS
+{{- range .Flags}}
+.TP
+\fB{{roff .Names}}\fR{{with .Arg}} \fI{{roff .}}\fR{{end}}
+{{roff .Usage}}{{with .Default}} (default {{roff .}}){{end}}
+{{- end}}
+{{- end}}
+{{- if .GlobalFlags}}
+.SH GLOBAL OPTIONS
+{{- range .GlobalFlags}}
+.TP
+\fB{{roff .Names}}\fR{{with .Arg}} \fI{{roff .}}\fR{{end}}
+{{roff .Usage}}{{with .Default}} (default {{roff .}}){{end}}
+{{- end}}
+{{- end}}
+{{- if .Example}}
+.SH EXAMPLES
+.nf
+{{lines .Example}}
+.fi
+{{- end}}
+{{- range .Sections}}
+.SH {{upper .Title | roff}}
+{{- with .Text}}
+{{paragraphs .}}
+{{- end}}
+{{- range .Entries}}
+.TP
+\fB{{roff .Term}}\fR
+{{roff .Text}}
+{{- end}}
+{{- end}}
+{{- if .SeeAlso}}
+.SH SEE ALSO
+{{range $i, $link := .SeeAlso}}{{if $i}},
+{{end}}.BR {{roff $link.Name}} (1){{end}}
+{{- end}}
+`))
+
+// markdownTemplate renders a page as markdown
+var markdownTemplate = template.Must(template.New("markdown").Funcs(templateFuncs).Parse(`# {{.CommandPath}}
+
+{{md .Short}}
+
+## Synopsis
+
+` + "```" + `
+{{.UseLine}}
+` + "```" + `
+{{- if ne .Description .Short}}
+
+{{mdblocks .Description}}
+{{- end}}
+{{- if .Flags}}
+
+## Options
+{{range .Flags}}
+- ` + "`" + `{{.Names}}{{with .Arg}} {{.}}{{end}}` + "`" + `: {{md .Usage}}{{with .Default}} (default ` + "`" + `{{.}}` + "`" + `){{end}}
+{{- end}}
+{{- end}}
+{{- if .GlobalFlags}}
+
+## Global options
+{{range .GlobalFlags}}
+- ` + "`" + `{{.Names}}{{with .Arg}} {{.}}{{end}}` + "`" + `: {{md .Usage}}{{with .Default}} (default ` + "`" + `{{.}}` + "`" + `){{end}}
+{{- end}}
+{{- end}}
+{{- if .Example}}
+
+## Examples
+
+` + "```" + `sh
+{{.Example}}
+` + "```" + `
+{{- end}}
+{{- range .Sections}}
+
+## {{.Title}}
+{{- with .Text}}
+
+{{mdblocks .}}
+{{- end}}
+{{- if .Entries}}
+{{range .Entries}}
+- ` + "`" + `{{.Term}}` + "`" + `{{with .Text}}: {{md .}}{{end}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .SeeAlso}}
+
+## See also
+{{range .SeeAlso}}
+- [{{.CommandPath}}]({{.Name}}.md)
+{{- end}}
+{{- end}}
+`))
+
+// roff escapes text for a roff line: backslashes, hyphens (so options stay ASCII
+// minus signs) and a leading period or quote, which would start a request
+func roff(text string) string {
+	text = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(text)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// roffParagraphs escapes help text: paragraphs are filled and separated by blank lines
+// become paragraph breaks; indented blocks (diagrams, samples) are kept as they are
+func roffParagraphs(text string) string {
+	var parts []string
+	for _, block := range splitBlocks(text) {
+		if block.indented {
+			parts = append(parts, ".nf\n"+roffLines(block.text)+"\n.fi")
+			continue
+		}
+		parts = append(parts, roff(block.text))
+	}
+	return strings.Join(parts, "\n.PP\n")
+}
+
+// roffLines escapes preformatted text, keeping blank lines
+func roffLines(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = `\&`
+			continue
+		}
+		lines[i] = roff(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownBlocks writes help text as markdown: paragraphs escaped, indented blocks
+// (diagrams, samples) in fenced code blocks longer than any backtick run inside them
+func markdownBlocks(text string) string {
+	var parts []string
+	for _, block := range splitBlocks(text) {
+		if !block.indented {
+			parts = append(parts, markdown(block.text))
+			continue
+		}
+		longest := 0
+		for _, run := range strings.FieldsFunc(block.text, func(r rune) bool { return r != '`' }) {
+			longest = max(longest, len(run))
+		}
+		fence := strings.Repeat("`", max(longest+1, 3))
+		parts = append(parts, fence+"text\n"+block.text+"\n"+fence)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// textBlock is a paragraph of help text, or a run of indented lines
+type textBlock struct {
+	text     string
+	indented bool
+}
+
+// splitBlocks splits help text at blank lines; consecutive paragraphs whose lines are
+// all indented form one block, keeping the blank lines between them
+func splitBlocks(text string) []textBlock {
+	var blocks []textBlock
+	for _, paragraph := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		paragraph = strings.Trim(paragraph, "\n")
+		if paragraph == "" {
+			continue
+		}
+		indented := true
+		for _, line := range strings.Split(paragraph, "\n") {
+			if line != "" && !strings.HasPrefix(line, " ") {
+				indented = false
+			}
+		}
+		if last := len(blocks) - 1; indented && last >= 0 && blocks[last].indented {
+			blocks[last].text += "\n\n" + paragraph
+			continue
+		}
+		blocks = append(blocks, textBlock{text: paragraph, indented: indented})
+	}
+	return blocks
+}
+
+// markdown escapes the characters that would otherwise become emphasis, code or HTML
+func markdown(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,175 @@
+// Package mcp implements a Model Context Protocol server over stdio.
+// It speaks newline-delimited JSON-RPC 2.0 and exposes treex operations as tools,
+// so AI assistants can read trees and maintain .info annotations.
+// Only the subset of the protocol needed for tools is implemented:
+// initialize, ping, tools/list and tools/call.
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+	"treex/treex"
+)
+
+// ProtocolVersion is the MCP revision implemented by this server
+const ProtocolVersion = "2024-11-05"
+
+// JSON-RPC 2.0 error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Config configures an MCP server
+type Config struct {
+	// Root directory the tools operate on; tool paths are relative to it
+	Root string
+
+	// Filesystem the root lives on (nil uses the OS filesystem)
+	Filesystem afero.Fs
+
+	// Tree holds the filter options applied to get_tree (Root, Filesystem and
+	// MaxDepth are set per call)
+	Tree treex.TreeConfig
+
+	// Version is reported to clients in serverInfo
+	Version string
+}
+
+// Server answers MCP requests for a single root directory
+type Server struct {
+	config Config
+	fs     afero.Fs // Filesystem rooted at config.Root
+	tools  []tool
+}
+
+// request is an incoming JSON-RPC message; notifications have no ID
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is an outgoing JSON-RPC message
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC error object
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewServer creates an MCP server for the configured root
+func NewServer(config Config) *Server {
+	if config.Filesystem == nil {
+		config.Filesystem = afero.NewOsFs()
+	}
+	if config.Version == "" {
+		config.Version = "dev"
+	}
+
+	s := &Server{
+		config: config,
+		fs:     afero.NewBasePathFs(config.Filesystem, config.Root),
+	}
+	s.tools = s.registerTools()
+	return s
+}
+
+// Serve reads requests from in and writes responses to out until in is closed
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	encoder := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		resp := s.handleMessage(line)
+		if resp == nil {
+			continue // Notifications get no response
+		}
+		if err := encoder.Encode(resp); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+	return scanner.Err()
+}
+
+// handleMessage dispatches a single message; it returns nil for notifications
+func (s *Server) handleMessage(message []byte) *response {
+	var req request
+	if err := json.Unmarshal(message, &req); err != nil {
+		return errorResponse(json.RawMessage("null"), codeParseError, "invalid JSON: "+err.Error())
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(idOrNull(req.ID), codeInvalidRequest, "expected a JSON-RPC 2.0 request")
+	}
+
+	isNotification := len(req.ID) == 0
+	result, rpcErr := s.dispatch(req)
+	if isNotification {
+		return nil
+	}
+	if rpcErr != nil {
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+// dispatch runs a method and returns its result or a protocol error
+func (s *Server) dispatch(req request) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": "treex", "version": s.config.Version},
+		}, nil
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		return map[string]interface{}{"tools": s.toolDescriptions()}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "invalid tools/call params: " + err.Error()}
+		}
+		return s.callTool(params.Name, params.Arguments)
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+	}
+}
+
+// errorResponse builds an error response
+func errorResponse(id json.RawMessage, code int, message string) *response {
+	return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}}
+}
+
+// idOrNull returns the request ID, or a JSON null when it is missing
+func idOrNull(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
@@ -0,0 +1,173 @@
+package mcp_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex"
+	"treex/treex/mcp"
+)
+
+// rpcResponse mirrors a JSON-RPC response for assertions
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// toolCallResult mirrors a tools/call result
+type toolCallResult struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	IsError bool `json:"isError"`
+}
+
+func newTestServer(t *testing.T) (*mcp.Server, afero.Fs) {
+	t.Helper()
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":     "README.md  Project overview\nsrc  Source code\n",
+		"README.md": "# project",
+		"src": map[string]interface{}{
+			"main.go": "package main",
+		},
+	})
+
+	return mcp.NewServer(mcp.Config{
+		Root:       "/project",
+		Filesystem: fs,
+		Tree:       treex.DefaultTreeConfig("/project"),
+		Version:    "test",
+	}), fs
+}
+
+// exchange sends newline-delimited messages and returns the decoded responses
+func exchange(t *testing.T, server *mcp.Server, messages ...string) []rpcResponse {
+	t.Helper()
+	var out strings.Builder
+	require.NoError(t, server.Serve(strings.NewReader(strings.Join(messages, "\n")+"\n"), &out))
+
+	var responses []rpcResponse
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var resp rpcResponse
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &resp))
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+// callTool invokes a tool and returns its text output
+func callTool(t *testing.T, server *mcp.Server, name, arguments string) toolCallResult {
+	t.Helper()
+	responses := exchange(t, server,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"`+name+`","arguments":`+arguments+`}}`)
+	require.Len(t, responses, 1)
+	require.Nil(t, responses[0].Error)
+
+	var result toolCallResult
+	require.NoError(t, json.Unmarshal(responses[0].Result, &result))
+	require.Len(t, result.Content, 1)
+	return result
+}
+
+func TestInitializeAndListTools(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	responses := exchange(t, server,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+	)
+	require.Len(t, responses, 2, "notifications get no response")
+
+	var initialize struct {
+		ProtocolVersion string            `json:"protocolVersion"`
+		ServerInfo      map[string]string `json:"serverInfo"`
+	}
+	require.NoError(t, json.Unmarshal(responses[0].Result, &initialize))
+	assert.Equal(t, mcp.ProtocolVersion, initialize.ProtocolVersion)
+	assert.Equal(t, "treex", initialize.ServerInfo["name"])
+	assert.Equal(t, "test", initialize.ServerInfo["version"])
+
+	var list struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(responses[1].Result, &list))
+	var names []string
+	for _, tool := range list.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{"get_tree", "get_annotation", "add_annotation", "validate"}, names)
+}
+
+func TestProtocolErrors(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	responses := exchange(t, server,
+		`not json`,
+		`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"missing"}}`,
+	)
+	require.Len(t, responses, 3)
+	assert.Equal(t, -32700, responses[0].Error.Code)
+	assert.Equal(t, -32601, responses[1].Error.Code)
+	assert.Equal(t, -32602, responses[2].Error.Code)
+}
+
+func TestGetTreeTool(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	result := callTool(t, server, "get_tree", `{}`)
+	assert.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "README.md")
+	assert.Contains(t, result.Content[0].Text, "Project overview")
+	assert.Contains(t, result.Content[0].Text, "main.go")
+
+	result = callTool(t, server, "get_tree", `{"path":"../.."}`)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "outside the project root")
+}
+
+func TestAnnotationTools(t *testing.T) {
+	server, fs := newTestServer(t)
+
+	result := callTool(t, server, "get_annotation", `{"path":"src"}`)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "Source code", result.Content[0].Text)
+
+	result = callTool(t, server, "add_annotation", `{"path":"src/main.go","notes":"Entry point"}`)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "Annotated src/main.go in src/.info", result.Content[0].Text)
+
+	content, err := afero.ReadFile(fs, "/project/src/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "main.go  Entry point\n", string(content))
+
+	result = callTool(t, server, "add_annotation", `{"path":"missing.go","notes":"Gone"}`)
+	assert.True(t, result.IsError)
+}
+
+func TestValidateTool(t *testing.T) {
+	server, fs := newTestServer(t)
+
+	result := callTool(t, server, "validate", `{}`)
+	assert.Equal(t, "All .info files are valid", result.Content[0].Text)
+
+	require.NoError(t, afero.WriteFile(fs, "/project/src/.info", []byte("old.go  Removed\n"), 0644))
+	result = callTool(t, server, "validate", `{}`)
+	assert.Equal(t, "src/.info:1: src/old.go: annotated path does not exist\n", result.Content[0].Text)
+}
@@ -0,0 +1,269 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/spf13/afero"
+	"treex/treex"
+	"treex/treex/pathutil"
+	"treex/treex/plugins/infofile"
+	"treex/treex/rendering"
+)
+
+// tool is an MCP tool: its advertised schema and the handler that runs it
+// Handlers return the text shown to the assistant; errors are reported as tool
+// errors (isError) rather than protocol errors so the assistant can react to them
+type tool struct {
+	Name        string
+	Description string
+	InputSchema map[string]interface{}
+	handler     func(arguments json.RawMessage) (string, error)
+}
+
+// toolArguments are the arguments accepted by the treex tools
+type toolArguments struct {
+	Path  string `json:"path"`
+	Depth int    `json:"depth"`
+	Notes string `json:"notes"`
+}
+
+// registerTools returns the tools exposed by the server
+func (s *Server) registerTools() []tool {
+	pathProperty := map[string]interface{}{
+		"type":        "string",
+		"description": "Path relative to the project root",
+	}
+
+	return []tool{
+		{
+			Name:        "get_tree",
+			Description: "Show the directory tree with annotations from .info files",
+			InputSchema: objectSchema(map[string]interface{}{
+				"path": pathProperty,
+				"depth": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum depth to show (0 for unlimited)",
+				},
+			}),
+			handler: s.getTree,
+		},
+		{
+			Name:        "get_annotation",
+			Description: "Get the annotation of a file or directory",
+			InputSchema: objectSchema(map[string]interface{}{"path": pathProperty}, "path"),
+			handler:     s.getAnnotation,
+		},
+		{
+			Name:        "add_annotation",
+			Description: "Add or replace the annotation of a file or directory in its parent's .info file",
+			InputSchema: objectSchema(map[string]interface{}{
+				"path": pathProperty,
+				"notes": map[string]interface{}{
+					"type":        "string",
+					"description": "Annotation text",
+				},
+			}, "path", "notes"),
+			handler: s.addAnnotation,
+		},
+		{
+			Name:        "validate",
+			Description: "Report .info entries that annotate missing paths, have no text or are duplicated",
+			InputSchema: objectSchema(map[string]interface{}{}),
+			handler:     s.validate,
+		},
+	}
+}
+
+// toolDescriptions returns the tools in the shape expected by tools/list
+func (s *Server) toolDescriptions() []map[string]interface{} {
+	descriptions := make([]map[string]interface{}, 0, len(s.tools))
+	for _, t := range s.tools {
+		descriptions = append(descriptions, map[string]interface{}{
+			"name":        t.Name,
+			"description": t.Description,
+			"inputSchema": t.InputSchema,
+		})
+	}
+	return descriptions
+}
+
+// callTool runs a tool and wraps its output as tool call content
+func (s *Server) callTool(name string, arguments json.RawMessage) (interface{}, *rpcError) {
+	for _, t := range s.tools {
+		if t.Name != name {
+			continue
+		}
+
+		text, err := t.handler(arguments)
+		if err != nil {
+			return toolResult(err.Error(), true), nil
+		}
+		return toolResult(text, false), nil
+	}
+	return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool %q", name)}
+}
+
+// getTree renders the tree below path as plain text with annotations
+func (s *Server) getTree(arguments json.RawMessage) (string, error) {
+	args, err := parseArguments(arguments)
+	if err != nil {
+		return "", err
+	}
+	if args.Depth < 0 {
+		return "", fmt.Errorf("invalid depth %d", args.Depth)
+	}
+
+	root, err := resolvePath(args.Path)
+	if err != nil {
+		return "", err
+	}
+	info, err := s.fs.Stat(root)
+	if err != nil {
+		return "", fmt.Errorf("path %q not found", args.Path)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("path %q is not a directory", args.Path)
+	}
+
+	// Root the tree at the requested directory, as the HTTP server does
+	config := s.config.Tree
+	config.Root = "/"
+	config.Filesystem = afero.NewBasePathFs(s.fs, root)
+	config.MaxDepth = args.Depth
+
+	result, err := treex.BuildTree(config)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format:    rendering.FormatPlain,
+		Writer:    &buf,
+		ShowNotes: true,
+		Width:     -1,
+	})
+	if err := renderer.RenderTree(result); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// getAnnotation returns the notes attached to a single path
+func (s *Server) getAnnotation(arguments json.RawMessage) (string, error) {
+	args, err := parseArguments(arguments)
+	if err != nil {
+		return "", err
+	}
+
+	target, err := resolvePath(args.Path)
+	if err != nil {
+		return "", err
+	}
+	if target == "/" {
+		return "", fmt.Errorf("the project root cannot be annotated")
+	}
+	if exists, _ := afero.Exists(s.fs, target); !exists {
+		return "", fmt.Errorf("path %q not found", args.Path)
+	}
+
+	// Build the tree of the parent directory only, so just its .info file is read
+	parent := path.Dir(target)
+	config := s.config.Tree
+	config.Root = "/"
+	config.Filesystem = afero.NewBasePathFs(s.fs, parent)
+	config.MaxDepth = 1
+
+	result, err := treex.BuildTree(config)
+	if err != nil {
+		return "", err
+	}
+
+	name := path.Base(target)
+	for _, child := range result.Root.Children {
+		if child.Name != name {
+			continue
+		}
+		if annotation := child.GetAnnotation(); annotation != nil && annotation.Notes != "" {
+			return annotation.Notes, nil
+		}
+		break
+	}
+	return fmt.Sprintf("%s has no annotation", strings.TrimPrefix(target, "/")), nil
+}
+
+// addAnnotation writes an annotation into the .info file next to the path
+func (s *Server) addAnnotation(arguments json.RawMessage) (string, error) {
+	args, err := parseArguments(arguments)
+	if err != nil {
+		return "", err
+	}
+
+	infoPath, err := infofile.AddAnnotation(s.fs, "/", strings.TrimPrefix(args.Path, "/"), args.Notes)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Annotated %s in %s", pathutil.Normalize(strings.TrimPrefix(args.Path, "/")), infoPath), nil
+}
+
+// validate reports problems in the .info files under the root
+func (s *Server) validate(arguments json.RawMessage) (string, error) {
+	issues, err := infofile.Validate(s.fs, "/")
+	if err != nil {
+		return "", err
+	}
+	if len(issues) == 0 {
+		return "All .info files are valid", nil
+	}
+
+	var buf strings.Builder
+	for _, issue := range issues {
+		fmt.Fprintf(&buf, "%s:%d: %s: %s\n", issue.InfoFile, issue.Line, issue.Path, issue.Message)
+	}
+	return buf.String(), nil
+}
+
+// parseArguments decodes tool arguments; missing arguments are allowed
+func parseArguments(arguments json.RawMessage) (toolArguments, error) {
+	var args toolArguments
+	if len(arguments) == 0 || string(arguments) == "null" {
+		return args, nil
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return args, fmt.Errorf("invalid arguments: %w", err)
+	}
+	return args, nil
+}
+
+// resolvePath turns a root-relative path into an absolute path inside the server filesystem
+func resolvePath(requestPath string) (string, error) {
+	normalized := pathutil.Normalize(strings.TrimPrefix(requestPath, "/"))
+	if normalized == ".." || strings.HasPrefix(normalized, "../") {
+		return "", fmt.Errorf("path %q is outside the project root", requestPath)
+	}
+	return path.Join("/", normalized), nil
+}
+
+// objectSchema builds a JSON schema for an object with the given properties
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// toolResult wraps text as a tools/call result
+func toolResult(text string, isError bool) map[string]interface{} {
+	return map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
@@ -0,0 +1,80 @@
+// see docs/dev/architecture.txt - Phase 2: Path Collection
+package pathcollection_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/pathcollection"
+)
+
+func TestCollectCanceledReturnsPartialResults(t *testing.T) {
+	memFs := testutil.NewTestFS()
+	memFs.MustCreateTree("/project", map[string]interface{}{
+		"a": map[string]interface{}{"a.txt": "content"},
+		"b": map[string]interface{}{"b.txt": "content"},
+		"c": map[string]interface{}{"c.txt": "content"},
+	})
+
+	// Cancel as soon as the walk starts reading directory "b"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fs := &testutil.OpenHookFS{Fs: memFs, OnOpen: func(name string) {
+		if name == "/project/b" {
+			cancel()
+		}
+	}}
+
+	paths, err := pathcollection.NewConfigurator(fs).
+		WithRoot("/project").
+		WithContext(ctx).
+		Collect()
+
+	require.ErrorIs(t, err, context.Canceled)
+	var collected []string
+	for _, info := range paths {
+		collected = append(collected, info.Path)
+	}
+	assert.Equal(t, []string{".", "a", "a/a.txt", "b"}, collected)
+}
+
+func TestCollectWithoutContext(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{"a.txt": "content"})
+
+	paths, err := pathcollection.NewConfigurator(fs).WithRoot("/project").Collect()
+	require.NoError(t, err)
+	assert.Len(t, paths, 2)
+}
+
+// countingProgress records the reports of a walk
+type countingProgress struct {
+	scanned []int
+	done    []int
+}
+
+func (p *countingProgress) Scanned(count int) { p.scanned = append(p.scanned, count) }
+func (p *countingProgress) Done(count int)    { p.done = append(p.done, count) }
+
+func TestCollectReportsProgress(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"a.txt":        "content",
+		"node_modules": map[string]interface{}{"dep.js": "content"},
+	})
+
+	progress := &countingProgress{}
+	paths, err := pathcollection.NewConfigurator(fs).
+		WithRoot("/project").
+		WithProgress(progress).
+		Collect()
+	require.NoError(t, err)
+
+	// Every visited entry is reported once, then the total on completion
+	assert.Equal(t, []int{1, 2, 3, 4}, progress.scanned)
+	assert.Equal(t, []int{4}, progress.done)
+	assert.Len(t, paths, 4)
+}
@@ -2,28 +2,37 @@
 package pathcollection
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/fs"
+	"log/slog"
+	"path"
 	"path/filepath"
-	"strings"
 
 	"github.com/spf13/afero"
-	"treex/treex/logging"
+	"treex/treex/pathutil"
 	"treex/treex/pattern"
 )
 
 // PathInfo represents collected information about a file or directory
 type PathInfo struct {
-	Path         string // Relative path from root
-	AbsolutePath string // Absolute filesystem path
-	IsDir        bool   // True if this is a directory
-	Size         int64  // File size in bytes (0 for directories)
-	Depth        int    // Depth from collection root (root = 0)
+	Path         string      // Relative path from root, always with forward slashes
+	AbsolutePath string      // Absolute filesystem path
+	IsDir        bool        // True if this is a directory
+	Size         int64       // File size in bytes (0 for directories)
+	Depth        int         // Depth from collection root (root = 0)
+	Mode         fs.FileMode // Type and permission bits
+	Owner        string      // Owner name (empty when the filesystem has no ownership)
+	Group        string      // Group name (empty when the filesystem has no ownership)
+	Error        string      // Why a directory's contents could not be read, e.g. "permission denied"
 }
 
-// Logger interface for error reporting during path collection
-type Logger interface {
-	Printf(format string, v ...interface{})
+// ProgressReporter receives walk progress, for example to drive a spinner on long scans
+// Both methods are called from the walking goroutine; implementations should be cheap
+type ProgressReporter interface {
+	Scanned(count int) // Called after every visited entry with the running total
+	Done(count int)    // Called once when the walk ends, including on errors and cancellation
 }
 
 // CollectionOptions configures the path collection process
@@ -33,29 +42,58 @@ type CollectionOptions struct {
 	Filter    *pattern.CompositeFilter // Pattern filter for early pruning
 	DirsOnly  bool                     // If true, collect only directories
 	FilesOnly bool                     // If true, collect only files
-	Logger    Logger                   // Optional logger for error reporting (uses log.Printf if nil)
+	Logger    *slog.Logger             // Logger for paths skipped on errors (nil uses slog.Default())
+
+	// Context cancels the walk (nil never cancels)
+	Context context.Context
+
+	// Progress is told how many entries have been visited (nil reports nothing)
+	// Visited entries include excluded ones, so the count reflects the work done
+	Progress ProgressReporter
+
+	// CaseInsensitive treats paths differing only by case as the same entry
+	// Used on case-insensitive filesystems to avoid duplicate entries
+	CaseInsensitive bool
+
+	// SubtreeDepth returns the depth limit below a directory, relative to it, and whether
+	// the directory has one (nil = none). Called once per collected directory; limits
+	// nest, the tightest one applying, and MaxDepth still applies everywhere.
+	SubtreeDepth func(relativePath, absolutePath string) (int, bool)
 }
 
 // Collector handles filesystem traversal with early pruning
 type Collector struct {
-	fs      afero.Fs
-	options CollectionOptions
-	results []PathInfo
+	fs         afero.Fs
+	options    CollectionOptions
+	normalizer *pathutil.Normalizer
+	results    []PathInfo
+	seen       map[string]bool   // Normalized keys of collected paths
+	depthCaps  map[string]int    // Deepest depth allowed below each limited directory
+	owners     map[uint32]string // Resolved user names by uid
+	groups     map[uint32]string // Resolved group names by gid
 }
 
 // NewCollector creates a new path collector
 func NewCollector(fs afero.Fs, options CollectionOptions) *Collector {
 	return &Collector{
-		fs:      fs,
-		options: options,
-		results: make([]PathInfo, 0),
+		fs:         fs,
+		options:    options,
+		normalizer: pathutil.NewNormalizer(options.CaseInsensitive),
+		results:    make([]PathInfo, 0),
+		seen:       make(map[string]bool),
+		owners:     make(map[uint32]string),
+		groups:     make(map[uint32]string),
 	}
 }
 
 // Collect performs the filesystem walk and returns collected paths
+// If the context is canceled, the walk stops and the paths collected so far are
+// returned together with the context's error
 func (c *Collector) Collect() ([]PathInfo, error) {
 	// Reset results for fresh collection
 	c.results = make([]PathInfo, 0)
+	c.seen = make(map[string]bool)
+	c.depthCaps = make(map[string]int)
 
 	// Convert root to absolute path for consistent handling
 	absRoot, err := filepath.Abs(c.options.Root)
@@ -73,10 +111,28 @@ func (c *Collector) Collect() ([]PathInfo, error) {
 	}
 
 	// Start filesystem walk from root
+	ctx := c.options.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	scanned := 0
+	if c.options.Progress != nil {
+		defer func() { c.options.Progress.Done(scanned) }()
+	}
 	err = afero.Walk(c.fs, absRoot, func(path string, info fs.FileInfo, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		scanned++
+		if c.options.Progress != nil {
+			c.options.Progress.Scanned(scanned)
+		}
 		return c.walkFunc(absRoot, path, info, err)
 	})
 
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return c.results, ctxErr
+	}
 	if err != nil {
 		return nil, fmt.Errorf("filesystem walk failed: %w", err)
 	}
@@ -84,13 +140,12 @@ func (c *Collector) Collect() ([]PathInfo, error) {
 	return c.results, nil
 }
 
-// logf logs a message using the configured logger, or the global logger if no logger is set
-func (c *Collector) logf(format string, v ...interface{}) {
+// logger returns the configured logger, or slog's default
+func (c *Collector) logger() *slog.Logger {
 	if c.options.Logger != nil {
-		c.options.Logger.Printf(format, v...)
-	} else {
-		logging.Warn().Msgf(format, v...)
+		return c.options.Logger
 	}
+	return slog.Default()
 }
 
 // walkFunc is called for each file/directory during filesystem traversal
@@ -100,7 +155,12 @@ func (c *Collector) walkFunc(rootPath, currentPath string, info fs.FileInfo, err
 	if err != nil {
 		// Log the error but continue traversal for robustness
 		// This handles permission errors, broken symlinks, etc.
-		c.logf("pathcollection: skipping path %q due to error: %v", currentPath, err)
+		c.logger().Warn("skipping unreadable path", "path", currentPath, "error", err)
+
+		// A directory that could not be listed was collected just before: mark it
+		if last := len(c.results) - 1; last >= 0 && c.results[last].AbsolutePath == currentPath {
+			c.results[last].Error = describeError(err)
+		}
 		return nil
 	}
 
@@ -113,22 +173,14 @@ func (c *Collector) walkFunc(rootPath, currentPath string, info fs.FileInfo, err
 		return fmt.Errorf("failed to calculate relative path: %w", err)
 	}
 
-	// Handle root directory specially - use "." as canonical root path
-	// This avoids ambiguity between empty string and actual root directory
-	if relativePath == "." {
-		relativePath = "."
-	}
+	// Normalize to forward slashes so paths match plugin results and .info entries
+	// on every platform. The root directory becomes "." as canonical root path
+	relativePath = c.normalizer.Normalize(relativePath)
 
 	// Calculate depth from root
 	// Root directory has depth 0, immediate children have depth 1, etc.
-	depth := 0
-	if relativePath != "." && relativePath != "" {
-		// Count path separators to determine depth
-		// Examples: "file.txt" = 0 separators = depth 1
-		//          "src/main.go" = 1 separator = depth 2
-		//          "src/lib/helper.go" = 2 separators = depth 3
-		depth = strings.Count(relativePath, string(filepath.Separator)) + 1
-	}
+	// Examples: "file.txt" = depth 1, "src/main.go" = depth 2, "src/lib/helper.go" = depth 3
+	depth := c.normalizer.Depth(relativePath)
 
 	// Apply depth limiting BEFORE other checks for efficiency
 	// If we're beyond max depth and this is a directory, skip entire subtree
@@ -142,6 +194,15 @@ func (c *Collector) walkFunc(rootPath, currentPath string, info fs.FileInfo, err
 		return nil
 	}
 
+	// Apply the depth limit of the enclosing directory (see CollectionOptions.SubtreeDepth)
+	depthCap, capped := c.depthCaps[path.Dir(relativePath)]
+	if capped && relativePath != "." && depth > depthCap {
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	}
+
 	// Apply pattern filtering with early pruning
 	if c.options.Filter != nil && c.options.Filter.ShouldExclude(relativePath, info.IsDir()) {
 		if info.IsDir() {
@@ -171,6 +232,16 @@ func (c *Collector) walkFunc(rootPath, currentPath string, info fs.FileInfo, err
 		size = 0
 	}
 
+	// Skip entries already collected under a different case on case-insensitive filesystems
+	key := c.normalizer.Key(relativePath)
+	if c.seen[key] {
+		return nil
+	}
+	c.seen[key] = true
+
+	// Capture permissions and ownership for long listings and JSON output
+	owner, group := c.ownership(info)
+
 	// Create path info and add to results
 	pathInfo := PathInfo{
 		Path:         relativePath,
@@ -178,14 +249,41 @@ func (c *Collector) walkFunc(rootPath, currentPath string, info fs.FileInfo, err
 		IsDir:        info.IsDir(),
 		Size:         size,
 		Depth:        depth,
+		Mode:         info.Mode(),
+		Owner:        owner,
+		Group:        group,
 	}
 
 	c.results = append(c.results, pathInfo)
 
+	// Record the depth limit below this directory, inherited or its own
+	if info.IsDir() {
+		if c.options.SubtreeDepth != nil {
+			if limit, ok := c.options.SubtreeDepth(relativePath, currentPath); ok && (!capped || depth+limit < depthCap) {
+				depthCap, capped = depth+limit, true
+			}
+		}
+		if capped {
+			c.depthCaps[relativePath] = depthCap
+		}
+	}
+
 	// Continue traversal
 	return nil
 }
 
+// describeError gives a short reason for a walk error ("permission denied")
+func describeError(err error) string {
+	if errors.Is(err, fs.ErrPermission) {
+		return "permission denied"
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err.Error()
+	}
+	return err.Error()
+}
+
 // GetPaths returns just the relative paths from collected results
 // This is a convenience method for phases that only need path strings
 func (c *Collector) GetPaths() []string {
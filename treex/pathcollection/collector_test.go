@@ -5,7 +5,7 @@ import (
 	"sort"
 	"testing"
 
-	"treex/treex/internal/testutil"
+	"treex/internal/testutil"
 	"treex/treex/pathcollection"
 )
 
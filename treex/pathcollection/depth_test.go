@@ -4,7 +4,7 @@ package pathcollection_test
 import (
 	"testing"
 
-	"treex/treex/internal/testutil"
+	"treex/internal/testutil"
 	"treex/treex/pathcollection"
 )
 
@@ -82,3 +82,46 @@ func TestDepthLimiting(t *testing.T) {
 		}
 	}
 }
+
+func TestSubtreeDepthLimits(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"src": map[string]interface{}{"app": map[string]interface{}{"main.go": "package main"}},
+		"vendor": map[string]interface{}{
+			"lib": map[string]interface{}{
+				"inner":  map[string]interface{}{"deep.go": "package inner"},
+				"lib.go": "package lib",
+			},
+		},
+	})
+
+	limits := map[string]int{"vendor": 2, "vendor/lib": 0}
+	collector := pathcollection.NewCollector(fs, pathcollection.CollectionOptions{
+		Root: "/project",
+		SubtreeDepth: func(relativePath, absolutePath string) (int, bool) {
+			limit, ok := limits[relativePath]
+			return limit, ok
+		},
+	})
+
+	results, err := collector.Collect()
+	if err != nil {
+		t.Fatalf("Collection failed: %v", err)
+	}
+	var paths []string
+	for _, result := range results {
+		paths = append(paths, result.Path)
+	}
+
+	// vendor/lib's own limit of 0 is tighter than the 2 levels vendor allows
+	expected := []string{".", "src", "src/app", "src/app/main.go", "vendor", "vendor/lib"}
+	if len(paths) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, paths)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, paths)
+			break
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package pathcollection_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/pathcollection"
+)
+
+// deniedFs refuses to open one directory, like an unreadable directory on disk
+type deniedFs struct {
+	afero.Fs
+	denied string
+}
+
+func (d deniedFs) Open(name string) (afero.File, error) {
+	if filepath.Clean(name) == d.denied {
+		return nil, &os.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return d.Fs.Open(name)
+}
+
+func TestCollectMarksUnreadableDirectories(t *testing.T) {
+	testFS := testutil.NewTestFS()
+	testFS.MustCreateTree("/project", map[string]interface{}{
+		"secrets": map[string]interface{}{"key.pem": "secret"},
+		"src":     map[string]interface{}{"main.go": "package main"},
+	})
+
+	logger, logged := newTestLogger()
+	results, err := pathcollection.NewCollector(deniedFs{Fs: testFS, denied: "/project/secrets"}, pathcollection.CollectionOptions{
+		Root:   "/project",
+		Logger: logger,
+	}).Collect()
+	require.NoError(t, err)
+	assert.Contains(t, logged.String(), `"level":"WARN","msg":"skipping unreadable path","path":"/project/secrets"`)
+
+	errors := make(map[string]string)
+	var paths []string
+	for _, result := range results {
+		paths = append(paths, result.Path)
+		errors[result.Path] = result.Error
+	}
+	assert.ElementsMatch(t, []string{".", "secrets", "src", "src/main.go"}, paths, "unreadable directories are still collected")
+	assert.Equal(t, "permission denied", errors["secrets"])
+	assert.Empty(t, errors["src"])
+}
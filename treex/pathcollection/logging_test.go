@@ -2,49 +2,23 @@
 package pathcollection_test
 
 import (
-	"strings"
+	"bytes"
+	"log/slog"
 	"testing"
 
-	"treex/treex/internal/testutil"
+	"treex/internal/testutil"
 	"treex/treex/pathcollection"
 )
 
-// TestLogger captures log messages for testing
-type TestLogger struct {
-	messages []string
-}
-
-func (tl *TestLogger) Printf(format string, v ...interface{}) {
-	// Store formatted message for verification
-	tl.messages = append(tl.messages, strings.TrimSpace(sprintf(format, v...)))
-}
-
-func (tl *TestLogger) GetMessages() []string {
-	return tl.messages
-}
-
-// Simple sprintf implementation for testing
-func sprintf(format string, v ...interface{}) string {
-	// For testing purposes, just return format with basic substitution
-	result := format
-	for i, arg := range v {
-		if i < 2 { // Handle up to 2 args for our test cases
-			switch a := arg.(type) {
-			case string:
-				result = strings.Replace(result, "%q", `"`+a+`"`, 1)
-				result = strings.Replace(result, "%v", a, 1)
-				result = strings.Replace(result, "%s", a, 1)
-			default:
-				result = strings.Replace(result, "%v", "error", 1)
-			}
-		}
-	}
-	return result
+// newTestLogger returns a logger recording messages as JSON lines in the returned buffer
+func newTestLogger() (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})), &buf
 }
 
 func TestLoggingWithCustomLogger(t *testing.T) {
 	fs := testutil.NewTestFS()
-	logger := &TestLogger{}
+	logger, logged := newTestLogger()
 
 	// Create a simple structure that we can collect successfully
 	fs.MustCreateTree("/test", map[string]interface{}{
@@ -67,9 +41,8 @@ func TestLoggingWithCustomLogger(t *testing.T) {
 	}
 
 	// Since we didn't create any permission errors, there should be no log messages
-	messages := logger.GetMessages()
-	if len(messages) > 0 {
-		t.Errorf("Expected no log messages for successful collection, got: %v", messages)
+	if logged.Len() > 0 {
+		t.Errorf("Expected no log messages for successful collection, got: %s", logged)
 	}
 }
 
@@ -80,10 +53,10 @@ func TestLoggingWithDefaultLogger(t *testing.T) {
 		"file.txt": "content",
 	})
 
-	// Test that default logger doesn't crash (uses log.Printf)
+	// Test that default logger doesn't crash (uses slog.Default())
 	collector := pathcollection.NewCollector(fs, pathcollection.CollectionOptions{
 		Root: "/test",
-		// No logger specified, should use default log.Printf
+		// No logger specified, should use slog.Default()
 	})
 
 	results, err := collector.Collect()
@@ -98,7 +71,7 @@ func TestLoggingWithDefaultLogger(t *testing.T) {
 
 func TestOptionsConfiguratorWithLogger(t *testing.T) {
 	fs := testutil.NewTestFS()
-	logger := &TestLogger{}
+	logger, logged := newTestLogger()
 
 	fs.MustCreateTree("/test", map[string]interface{}{
 		"file.txt": "content",
@@ -119,8 +92,7 @@ func TestOptionsConfiguratorWithLogger(t *testing.T) {
 	}
 
 	// Verify logger was set (no messages expected for successful collection)
-	messages := logger.GetMessages()
-	if len(messages) > 0 {
-		t.Errorf("Expected no log messages for successful collection, got: %v", messages)
+	if logged.Len() > 0 {
+		t.Errorf("Expected no log messages for successful collection, got: %s", logged)
 	}
 }
@@ -4,7 +4,7 @@ package pathcollection_test
 import (
 	"testing"
 
-	"treex/treex/internal/testutil"
+	"treex/internal/testutil"
 	"treex/treex/pathcollection"
 	"treex/treex/pattern"
 )
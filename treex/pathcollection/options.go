@@ -2,6 +2,9 @@
 package pathcollection
 
 import (
+	"context"
+	"log/slog"
+
 	"github.com/spf13/afero"
 	"treex/treex/pattern"
 )
@@ -36,6 +39,12 @@ func (c *OptionsConfigurator) WithMaxDepth(maxDepth int) *OptionsConfigurator {
 	return c
 }
 
+// WithSubtreeDepth sets the function giving per-directory depth limits
+func (c *OptionsConfigurator) WithSubtreeDepth(subtreeDepth func(relativePath, absolutePath string) (int, bool)) *OptionsConfigurator {
+	c.options.SubtreeDepth = subtreeDepth
+	return c
+}
+
 // WithFilter sets the pattern filter for exclusion
 func (c *OptionsConfigurator) WithFilter(filter *pattern.CompositeFilter) *OptionsConfigurator {
 	c.options.Filter = filter
@@ -56,12 +65,30 @@ func (c *OptionsConfigurator) WithFilesOnly() *OptionsConfigurator {
 	return c
 }
 
-// WithLogger sets a custom logger for error reporting during collection
-func (c *OptionsConfigurator) WithLogger(logger Logger) *OptionsConfigurator {
+// WithCaseInsensitive configures collection to treat paths differing only by case as equal
+func (c *OptionsConfigurator) WithCaseInsensitive(caseInsensitive bool) *OptionsConfigurator {
+	c.options.CaseInsensitive = caseInsensitive
+	return c
+}
+
+// WithLogger sets the logger told about paths skipped on errors during collection
+func (c *OptionsConfigurator) WithLogger(logger *slog.Logger) *OptionsConfigurator {
 	c.options.Logger = logger
 	return c
 }
 
+// WithContext sets the context whose cancellation stops the walk
+func (c *OptionsConfigurator) WithContext(ctx context.Context) *OptionsConfigurator {
+	c.options.Context = ctx
+	return c
+}
+
+// WithProgress sets the reporter told how many entries the walk has visited
+func (c *OptionsConfigurator) WithProgress(progress ProgressReporter) *OptionsConfigurator {
+	c.options.Progress = progress
+	return c
+}
+
 // NewCollector creates and returns a configured collector
 func (c *OptionsConfigurator) NewCollector() *Collector {
 	return NewCollector(c.fs, c.options)
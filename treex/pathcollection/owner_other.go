@@ -0,0 +1,10 @@
+//go:build !unix
+
+package pathcollection
+
+import "io/fs"
+
+// ownership is not available on platforms without POSIX ownership
+func (c *Collector) ownership(info fs.FileInfo) (owner, group string) {
+	return "", ""
+}
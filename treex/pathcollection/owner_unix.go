@@ -0,0 +1,52 @@
+//go:build unix
+
+package pathcollection
+
+import (
+	"io/fs"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// ownership returns the owner and group names of a file
+// Names that cannot be resolved fall back to the numeric id; filesystems without
+// POSIX ownership (e.g. in-memory) yield empty strings
+func (c *Collector) ownership(info fs.FileInfo) (owner, group string) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", ""
+	}
+	return c.lookupName(c.owners, stat.Uid, lookupUser), c.lookupName(c.groups, stat.Gid, lookupGroup)
+}
+
+// lookupName resolves an id once per collection and caches the result
+func (c *Collector) lookupName(cache map[uint32]string, id uint32, lookup func(string) (string, error)) string {
+	if name, ok := cache[id]; ok {
+		return name
+	}
+
+	idString := strconv.FormatUint(uint64(id), 10)
+	name, err := lookup(idString)
+	if err != nil {
+		name = idString
+	}
+	cache[id] = name
+	return name
+}
+
+func lookupUser(uid string) (string, error) {
+	u, err := user.LookupId(uid)
+	if err != nil {
+		return "", err
+	}
+	return u.Username, nil
+}
+
+func lookupGroup(gid string) (string, error) {
+	g, err := user.LookupGroupId(gid)
+	if err != nil {
+		return "", err
+	}
+	return g.Name, nil
+}
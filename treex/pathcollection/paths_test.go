@@ -0,0 +1,111 @@
+// see docs/dev/architecture.txt - Phase 2: Path Collection
+package pathcollection_test
+
+import (
+	"strings"
+	"testing"
+
+	"treex/internal/testutil"
+	"treex/treex/pathcollection"
+)
+
+func TestCollectedPathsUseForwardSlashes(t *testing.T) {
+	fs := testutil.NewTestFS()
+
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"src": map[string]interface{}{
+			"lib": map[string]interface{}{
+				"utils.go": "package lib",
+			},
+		},
+	})
+
+	results, err := pathcollection.NewConfigurator(fs).
+		WithRoot("/project").
+		Collect()
+	if err != nil {
+		t.Fatalf("Collection failed: %v", err)
+	}
+
+	for _, result := range results {
+		if strings.Contains(result.Path, `\`) {
+			t.Errorf("Expected forward slashes in collected path, got %q", result.Path)
+		}
+		if result.Path == "src/lib/utils.go" && result.Depth != 3 {
+			t.Errorf("Expected depth 3 for %q, got %d", result.Path, result.Depth)
+		}
+	}
+}
+
+func TestCaseInsensitiveCollectionDeduplicates(t *testing.T) {
+	fs := testutil.NewTestFS()
+
+	// MemMapFs is case-sensitive, so both entries exist; a case-insensitive
+	// filesystem would report them as the same file
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"README.md": "upper",
+		"readme.md": "lower",
+		"main.go":   "package main",
+	})
+
+	tests := []struct {
+		caseInsensitive bool
+		expected        int
+		desc            string
+	}{
+		{false, 4, "case-sensitive keeps both entries"},
+		{true, 3, "case-insensitive keeps a single entry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			results, err := pathcollection.NewConfigurator(fs).
+				WithRoot("/project").
+				WithCaseInsensitive(tt.caseInsensitive).
+				Collect()
+			if err != nil {
+				t.Fatalf("Collection failed: %v", err)
+			}
+
+			if len(results) != tt.expected {
+				t.Errorf("Expected %d paths (including root), got %d", tt.expected, len(results))
+			}
+		})
+	}
+}
+
+func TestCollectedPathsCaptureMode(t *testing.T) {
+	fs := testutil.NewTestFS()
+
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"run.sh": "#!/bin/sh",
+	})
+	if err := fs.Chmod("/project/run.sh", 0755); err != nil {
+		t.Fatalf("Chmod failed: %v", err)
+	}
+
+	results, err := pathcollection.NewConfigurator(fs).
+		WithRoot("/project").
+		Collect()
+	if err != nil {
+		t.Fatalf("Collection failed: %v", err)
+	}
+
+	for _, result := range results {
+		switch result.Path {
+		case ".":
+			if !result.Mode.IsDir() {
+				t.Errorf("Expected directory mode for root, got %v", result.Mode)
+			}
+		case "run.sh":
+			if result.Mode.Perm() != 0755 {
+				t.Errorf("Expected permissions 0755 for %q, got %v", result.Path, result.Mode.Perm())
+			}
+		}
+
+		// The in-memory filesystem has no POSIX ownership
+		if result.Owner != "" || result.Group != "" {
+			t.Errorf("Expected no ownership for %q, got %q:%q", result.Path, result.Owner, result.Group)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package pathutil
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ConfigPath returns name in treex's user configuration directory:
+// $XDG_CONFIG_HOME/treex, else ~/.config/treex, else a treex directory under the
+// temporary directory when there is no home directory
+func ConfigPath(name string) string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return filepath.Join(os.TempDir(), "treex", name)
+		}
+		configDir = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configDir, "treex", name)
+}
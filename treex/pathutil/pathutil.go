@@ -0,0 +1,100 @@
+// Package pathutil provides the path normalization layer shared by every phase of the pipeline.
+// All paths that cross package boundaries (collected paths, plugin results, node paths,
+// annotation targets) use forward slashes; comparisons go through a Normalizer so that
+// case-insensitive filesystems (Windows, default macOS volumes) match consistently.
+// ConfigPath locates treex's files in the user configuration directory.
+package pathutil
+
+import (
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Normalizer converts OS-specific paths into treex's canonical form and builds
+// comparison keys that optionally ignore case
+type Normalizer struct {
+	separator       rune // OS path separator to convert to '/'
+	caseInsensitive bool // Whether keys are case-folded
+}
+
+// NewNormalizer creates a normalizer for the current OS path separator
+func NewNormalizer(caseInsensitive bool) *Normalizer {
+	return NewNormalizerWithSeparator(filepath.Separator, caseInsensitive)
+}
+
+// NewNormalizerWithSeparator creates a normalizer for a specific OS path separator
+// This allows Windows path handling ('\\') to be exercised on any platform
+func NewNormalizerWithSeparator(separator rune, caseInsensitive bool) *Normalizer {
+	return &Normalizer{
+		separator:       separator,
+		caseInsensitive: caseInsensitive,
+	}
+}
+
+// CaseInsensitive reports whether this normalizer folds case when building keys
+func (n *Normalizer) CaseInsensitive() bool {
+	return n.caseInsensitive
+}
+
+// Normalize returns the canonical form of a path:
+// forward slashes, cleaned, no leading "./", and "." for the root
+// Examples: "src\\lib\\util.go" -> "src/lib/util.go", "./docs/" -> "docs", "" -> "."
+func (n *Normalizer) Normalize(p string) string {
+	if p == "" {
+		return "."
+	}
+
+	if n.separator != '/' {
+		p = strings.ReplaceAll(p, string(n.separator), "/")
+	}
+
+	return path.Clean(p)
+}
+
+// Key returns the comparison key for a path
+// Keys are normalized paths, lower-cased when the normalizer is case-insensitive
+// Use keys for map lookups; use Normalize for anything that is displayed
+func (n *Normalizer) Key(p string) string {
+	normalized := n.Normalize(p)
+	if n.caseInsensitive {
+		return strings.ToLower(normalized)
+	}
+	return normalized
+}
+
+// Equal reports whether two paths refer to the same entry under this normalizer's rules
+func (n *Normalizer) Equal(a, b string) bool {
+	return n.Key(a) == n.Key(b)
+}
+
+// Depth returns the number of path components of a normalized path
+// The root (".") has depth 0, "src" has depth 1, "src/main.go" has depth 2
+func (n *Normalizer) Depth(p string) int {
+	normalized := n.Normalize(p)
+	if normalized == "." {
+		return 0
+	}
+	return strings.Count(strings.Trim(normalized, "/"), "/") + 1
+}
+
+// DefaultCaseInsensitive reports whether the current platform's default filesystem
+// is case-insensitive (Windows and macOS)
+func DefaultCaseInsensitive() bool {
+	return runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+}
+
+// defaultNormalizer uses the current OS separator and case-sensitive keys
+var defaultNormalizer = NewNormalizer(false)
+
+// Normalize converts a path to canonical form using the current OS separator
+func Normalize(p string) string {
+	return defaultNormalizer.Normalize(p)
+}
+
+// Default returns a normalizer configured for the current platform, including
+// case-folding on platforms whose default filesystem is case-insensitive
+func Default() *Normalizer {
+	return NewNormalizer(DefaultCaseInsensitive())
+}
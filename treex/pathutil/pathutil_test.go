@@ -0,0 +1,121 @@
+package pathutil_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"treex/treex/pathutil"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+		desc     string
+	}{
+		{"", ".", "empty path is root"},
+		{".", ".", "root stays root"},
+		{"./src/main.go", "src/main.go", "leading ./ removed"},
+		{"docs/", "docs", "trailing slash removed"},
+		{"src//lib/../main.go", "src/main.go", "path cleaned"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if result := pathutil.Normalize(tt.path); result != tt.expected {
+				t.Errorf("Normalize(%q): expected %q, got %q", tt.path, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestNormalizeWindowsSeparators(t *testing.T) {
+	normalizer := pathutil.NewNormalizerWithSeparator('\\', true)
+
+	tests := []struct {
+		path     string
+		expected string
+		desc     string
+	}{
+		{`src\lib\utils.go`, "src/lib/utils.go", "backslashes converted"},
+		{`src/lib\utils.go`, "src/lib/utils.go", "mixed separators converted"},
+		{`.\docs\`, "docs", "leading .\\ and trailing separator removed"},
+		{`C:\Users\dev\project`, "C:/Users/dev/project", "drive letter path keeps drive"},
+		{`src\Main.go`, "src/Main.go", "Normalize preserves case"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if result := normalizer.Normalize(tt.path); result != tt.expected {
+				t.Errorf("Normalize(%q): expected %q, got %q", tt.path, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestKeyCaseFolding(t *testing.T) {
+	tests := []struct {
+		caseInsensitive bool
+		a, b            string
+		expected        bool
+		desc            string
+	}{
+		{false, "README.md", "README.md", true, "identical paths match"},
+		{false, "README.md", "readme.md", false, "case-sensitive mode distinguishes case"},
+		{true, "README.md", "readme.md", true, "case-insensitive mode folds case"},
+		{true, "Src/Main.go", "src/main.go", true, "case folding applies to every component"},
+		{true, "src/main.go", "src/main.js", false, "different names still differ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			normalizer := pathutil.NewNormalizerWithSeparator('/', tt.caseInsensitive)
+			if result := normalizer.Equal(tt.a, tt.b); result != tt.expected {
+				t.Errorf("Equal(%q, %q) with caseInsensitive=%v: expected %v, got %v",
+					tt.a, tt.b, tt.caseInsensitive, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestWindowsPathsMatchForwardSlashAnnotations(t *testing.T) {
+	// Annotations are written with forward slashes; Windows walks produce backslashes
+	normalizer := pathutil.NewNormalizerWithSeparator('\\', true)
+
+	if !normalizer.Equal(`Docs\Guide.TXT`, "docs/guide.txt") {
+		t.Errorf("expected Windows path to match forward-slash annotation path")
+	}
+}
+
+func TestDepth(t *testing.T) {
+	normalizer := pathutil.NewNormalizerWithSeparator('\\', false)
+
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{".", 0},
+		{"file.txt", 1},
+		{`src\main.go`, 2},
+		{"src/lib/helper.go", 3},
+	}
+
+	for _, tt := range tests {
+		if result := normalizer.Depth(tt.path); result != tt.expected {
+			t.Errorf("Depth(%q): expected %d, got %d", tt.path, tt.expected, result)
+		}
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", filepath.FromSlash("/xdg"))
+	if got, want := pathutil.ConfigPath("themes"), filepath.FromSlash("/xdg/treex/themes"); got != want {
+		t.Errorf("ConfigPath with XDG_CONFIG_HOME = %q, want %q", got, want)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", filepath.FromSlash("/home/ada"))
+	if got, want := pathutil.ConfigPath("icons.yaml"), filepath.FromSlash("/home/ada/.config/treex/icons.yaml"); got != want {
+		t.Errorf("ConfigPath under HOME = %q, want %q", got, want)
+	}
+}
@@ -136,6 +136,7 @@ func TestBuiltinIgnorePatternsContent(t *testing.T) {
 		".DS_Store":    true,
 		"*.tmp":        true,
 		"*.log":        true,
+		".treex":       true,
 	}
 
 	// Check that all expected patterns are present
@@ -3,7 +3,7 @@ package pattern_test
 import (
 	"testing"
 
-	"treex/treex/internal/testutil"
+	"treex/internal/testutil"
 	"treex/treex/pattern"
 )
 
@@ -2,10 +2,12 @@
 package pattern
 
 import (
+	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/spf13/afero"
+	"treex/treex/pathutil"
 )
 
 // Pattern represents a single pattern that can match file paths
@@ -44,6 +46,13 @@ func (cf *CompositeFilter) AddPattern(pattern Pattern) {
 // HiddenPattern matches hidden files/directories (starting with .)
 type HiddenPattern struct {
 	exclude bool // if true, exclude hidden files; if false, include them
+	only    bool // if true, exclude files that are neither hidden nor below a hidden directory
+
+	// Hidden paths that stay visible when excluding (normalized keys): kept entries show
+	// with their contents, their parents only to lead to them
+	normalizer  *pathutil.Normalizer
+	kept        map[string]bool
+	keptParents map[string]bool
 }
 
 // NewHiddenPattern creates a hidden file pattern
@@ -51,8 +60,46 @@ func NewHiddenPattern(exclude bool) *HiddenPattern {
 	return &HiddenPattern{exclude: exclude}
 }
 
+// NewHiddenOnlyPattern creates a pattern keeping only hidden entries: files that are
+// hidden or below a hidden directory. Directories are never excluded, so the walk still
+// reaches hidden entries in regular directories; callers prune the ones left empty.
+func NewHiddenOnlyPattern() *HiddenPattern {
+	return &HiddenPattern{only: true}
+}
+
+// NewHiddenExceptPattern creates a pattern excluding hidden entries, and everything below
+// hidden directories, except keptPaths with their contents and the directories leading
+// to them (e.g. annotated dotfiles)
+// The normalizer controls separator and case handling; nil uses the OS defaults
+func NewHiddenExceptPattern(keptPaths map[string]bool, normalizer *pathutil.Normalizer) *HiddenPattern {
+	if normalizer == nil {
+		normalizer = pathutil.NewNormalizer(false)
+	}
+	kept := make(map[string]bool)
+	keptParents := make(map[string]bool)
+	for keptPath, keep := range keptPaths {
+		if !keep {
+			continue
+		}
+		key := normalizer.Key(keptPath)
+		kept[key] = true
+		for dir := path.Dir(key); dir != "." && dir != "/" && dir != ""; dir = path.Dir(dir) {
+			keptParents[dir] = true
+		}
+	}
+	return &HiddenPattern{exclude: true, normalizer: normalizer, kept: kept, keptParents: keptParents}
+}
+
 // Matches returns true if the path should be excluded according to hidden file rules
 func (hp *HiddenPattern) Matches(path string, isDir bool) bool {
+	if hp.only {
+		return !isDir && !IsHiddenPath(path)
+	}
+
+	if hp.kept != nil {
+		return hp.excludedExcept(path)
+	}
+
 	basename := filepath.Base(path)
 	isHidden := strings.HasPrefix(basename, ".") && basename != "." && basename != ".."
 
@@ -61,46 +108,92 @@ func (hp *HiddenPattern) Matches(path string, isDir bool) bool {
 	return hp.exclude && isHidden
 }
 
+// excludedExcept reports whether a hidden path is excluded despite the kept paths
+// Walks reach entries below a kept parent, so those need their own hidden check.
+func (hp *HiddenPattern) excludedExcept(p string) bool {
+	if !IsHiddenPath(p) {
+		return false
+	}
+	key := hp.normalizer.Key(p)
+	if hp.keptParents[key] {
+		return false
+	}
+	for ; key != "." && key != "/" && key != ""; key = path.Dir(key) {
+		if hp.kept[key] {
+			return false
+		}
+	}
+	return true
+}
+
 // String returns a description of the pattern for debugging
 func (hp *HiddenPattern) String() string {
-	if hp.exclude {
+	switch {
+	case hp.only:
+		return "hidden:only"
+	case hp.exclude:
 		return "hidden:exclude"
 	}
 	return "hidden:include"
 }
 
+// IsHiddenPath reports whether path, or one of the directories it is in, is hidden
+// (a name starting with "." other than "." and "..")
+func IsHiddenPath(path string) bool {
+	for _, name := range strings.FieldsFunc(filepath.ToSlash(path), func(r rune) bool { return r == '/' }) {
+		if strings.HasPrefix(name, ".") && name != "." && name != ".." {
+			return true
+		}
+	}
+	return false
+}
+
 // PluginIncludePattern implements include-only filtering for plugin results
 // It excludes everything that's NOT in the allowed paths (inverse logic)
 type PluginIncludePattern struct {
-	allowedPaths   map[string]bool
-	allowedParents map[string]bool // Cache of parent directories
+	normalizer     *pathutil.Normalizer
+	allowedPaths   map[string]bool // Normalized keys of allowed paths
+	allowedParents map[string]bool // Cache of parent directories (normalized keys)
 }
 
 // NewPluginIncludePattern creates a plugin include pattern
 // allowedPaths contains the exact file paths that should be included
-func NewPluginIncludePattern(allowedPaths map[string]bool) *PluginIncludePattern {
-	// Pre-compute parent directories that should also be included
+// The normalizer controls separator and case handling; nil uses the OS defaults
+func NewPluginIncludePattern(allowedPaths map[string]bool, normalizer *pathutil.Normalizer) *PluginIncludePattern {
+	if normalizer == nil {
+		normalizer = pathutil.NewNormalizer(false)
+	}
+
+	// Store normalized keys and pre-compute parent directories that should also be included
+	allowedKeys := make(map[string]bool, len(allowedPaths))
 	allowedParents := make(map[string]bool)
 
-	for path := range allowedPaths {
+	for allowedPath, allowed := range allowedPaths {
+		if !allowed {
+			continue
+		}
+		key := normalizer.Key(allowedPath)
+		allowedKeys[key] = true
+
 		// Add all parent directories to ensure they're included
-		dir := filepath.Dir(path)
+		dir := path.Dir(key)
 		for dir != "." && dir != "/" && dir != "" {
 			allowedParents[dir] = true
-			dir = filepath.Dir(dir)
+			dir = path.Dir(dir)
 		}
 	}
 
 	return &PluginIncludePattern{
-		allowedPaths:   allowedPaths,
+		normalizer:     normalizer,
+		allowedPaths:   allowedKeys,
 		allowedParents: allowedParents,
 	}
 }
 
 // Matches returns true if the path should be excluded (not in allowed paths or parent dirs)
 func (pip *PluginIncludePattern) Matches(path string, isDir bool) bool {
-	// Normalize path separators for consistent matching
-	path = filepath.ToSlash(path)
+	// Normalize path separators (and case, if configured) for consistent matching
+	path = pip.normalizer.Key(path)
 
 	// Include if path is explicitly allowed
 	if pip.allowedPaths[path] {
@@ -142,6 +235,7 @@ var BuiltinIgnorePatterns = []string{
 	".DS_Store",    // macOS directory metadata
 	"*.tmp",        // Temporary files
 	"*.log",        // Log files
+	".treex",       // treex state (undo journals)
 }
 
 // FilterBuilder helps construct composite filters from options
@@ -151,18 +245,27 @@ var BuiltinIgnorePatterns = []string{
 // 3. Gitignore files (.gitignore) - gitignore format patterns
 // 4. Hidden file filtering (--hidden flag) - files starting with '.'
 type FilterBuilder struct {
-	fs     afero.Fs
-	filter *CompositeFilter
+	fs         afero.Fs
+	filter     *CompositeFilter
+	normalizer *pathutil.Normalizer
 }
 
 // NewFilterBuilder creates a new filter builder
 func NewFilterBuilder(fs afero.Fs) *FilterBuilder {
 	return &FilterBuilder{
-		fs:     fs,
-		filter: NewCompositeFilter(),
+		fs:         fs,
+		filter:     NewCompositeFilter(),
+		normalizer: pathutil.NewNormalizer(false),
 	}
 }
 
+// WithCaseInsensitive makes path-set based patterns (plugin filters) ignore case
+// Glob and gitignore patterns keep their own semantics (see docs/dev/patterns.txt)
+func (fb *FilterBuilder) WithCaseInsensitive(caseInsensitive bool) *FilterBuilder {
+	fb.normalizer = pathutil.NewNormalizer(caseInsensitive)
+	return fb
+}
+
 // AddBuiltinIgnores adds default ignore patterns for common VCS and build artifacts
 // These patterns work alongside user excludes, gitignore, and hidden file filtering.
 // Can be disabled with --no-builtin-ignores flag in CLI.
@@ -178,6 +281,16 @@ func (fb *FilterBuilder) AddBuiltinIgnores(enabled bool) *FilterBuilder {
 	return fb
 }
 
+// AddPresets adds the ignore sets of the named presets (see Presets), which leave keptPaths
+// and the directories leading to them visible
+func (fb *FilterBuilder) AddPresets(names []string, keptPaths map[string]bool) *FilterBuilder {
+	if len(names) == 0 {
+		return fb
+	}
+	fb.filter.AddPattern(NewPresetPattern(names, keptPaths, fb.normalizer))
+	return fb
+}
+
 // AddUserExcludes adds user-specified exclude patterns using shell glob semantics
 // These patterns are specified via --exclude flags and work alongside built-in ignores,
 // gitignore files, and hidden file filtering.
@@ -197,6 +310,19 @@ func (fb *FilterBuilder) AddHiddenFilter(showHidden bool) *FilterBuilder {
 	return fb
 }
 
+// AddHiddenExceptFilter excludes hidden files like AddHiddenFilter(false), except
+// keptPaths and the directories leading to them
+func (fb *FilterBuilder) AddHiddenExceptFilter(keptPaths map[string]bool) *FilterBuilder {
+	fb.filter.AddPattern(NewHiddenExceptPattern(keptPaths, fb.normalizer))
+	return fb
+}
+
+// AddHiddenOnlyFilter keeps only hidden files and the files below hidden directories
+func (fb *FilterBuilder) AddHiddenOnlyFilter() *FilterBuilder {
+	fb.filter.AddPattern(NewHiddenOnlyPattern())
+	return fb
+}
+
 // AddGitignore adds patterns from .gitignore file using gitignore semantics
 // This works alongside built-in ignores, user excludes, and hidden file filtering.
 // Automatically looks for .gitignore files and applies their patterns.
@@ -223,7 +349,7 @@ func (fb *FilterBuilder) AddPluginFilter(allowedPaths map[string]bool) *FilterBu
 		return fb
 	}
 
-	fb.filter.AddPattern(NewPluginIncludePattern(allowedPaths))
+	fb.filter.AddPattern(NewPluginIncludePattern(allowedPaths, fb.normalizer))
 	return fb
 }
 
@@ -3,7 +3,8 @@ package pattern_test
 import (
 	"testing"
 
-	"treex/treex/internal/testutil"
+	"treex/internal/testutil"
+	"treex/treex/pathutil"
 	"treex/treex/pattern"
 )
 
@@ -141,3 +142,94 @@ func TestFilterBuilderDisabledGitignore(t *testing.T) {
 		t.Error("Disabled gitignore should not exclude anything")
 	}
 }
+
+func TestPluginIncludePatternPathNormalization(t *testing.T) {
+	allowed := map[string]bool{"Docs/Guide.txt": true}
+
+	tests := []struct {
+		normalizer *pathutil.Normalizer
+		path       string
+		isDir      bool
+		expected   bool
+		desc       string
+	}{
+		{pathutil.NewNormalizerWithSeparator('/', false), "Docs/Guide.txt", false, false, "exact path included"},
+		{pathutil.NewNormalizerWithSeparator('/', false), "docs/guide.txt", false, true, "case mismatch excluded when case-sensitive"},
+		{pathutil.NewNormalizerWithSeparator('/', true), "docs/guide.txt", false, false, "case mismatch included when case-insensitive"},
+		{pathutil.NewNormalizerWithSeparator('/', true), "docs", true, false, "parent directory included regardless of case"},
+		{pathutil.NewNormalizerWithSeparator('\\', true), `docs\guide.txt`, false, false, "windows separators resolve"},
+		{pathutil.NewNormalizerWithSeparator('\\', true), `docs\other.txt`, false, true, "other windows paths excluded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			includePattern := pattern.NewPluginIncludePattern(allowed, tt.normalizer)
+			if result := includePattern.Matches(tt.path, tt.isDir); result != tt.expected {
+				t.Errorf("PluginIncludePattern on %q: expected exclude=%v, got %v", tt.path, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHiddenExceptPattern(t *testing.T) {
+	hiddenPattern := pattern.NewHiddenExceptPattern(map[string]bool{".env.example": true, ".github/workflows/ci.yml": true, ".devcontainer": true}, nil)
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{".env", false, true},
+		{".env.example", false, false},
+		{".github", true, false},
+		{".github/workflows", true, false},
+		{".github/workflows/ci.yml", false, false},
+		{".github/workflows/.cache", true, true},
+		{".github/CODEOWNERS", false, true},
+		{".devcontainer", true, false},
+		{".devcontainer/devcontainer.json", false, false},
+		{".vscode", true, true},
+		{"src/main.go", false, false},
+	}
+	for _, tt := range tests {
+		if result := hiddenPattern.Matches(tt.path, tt.isDir); result != tt.expected {
+			t.Errorf("HiddenExceptPattern on %q: expected %v, got %v", tt.path, tt.expected, result)
+		}
+	}
+}
+
+func TestHiddenOnlyPattern(t *testing.T) {
+	hiddenPattern := pattern.NewHiddenOnlyPattern()
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{".env", false, false},
+		{"src", true, false},
+		{"src/main.go", false, true},
+		{"src/.eslintrc", false, false},
+		{".github/workflows/ci.yml", false, false},
+	}
+	for _, tt := range tests {
+		if result := hiddenPattern.Matches(tt.path, tt.isDir); result != tt.expected {
+			t.Errorf("HiddenOnlyPattern on %q: expected %v, got %v", tt.path, tt.expected, result)
+		}
+	}
+}
+
+func TestIsHiddenPath(t *testing.T) {
+	for path, expected := range map[string]bool{
+		".":               false,
+		"src/main.go":     false,
+		".env":            true,
+		".github/ci.yml":  true,
+		"web/.config/app": true,
+		"../src":          false,
+	} {
+		if result := pattern.IsHiddenPath(path); result != expected {
+			t.Errorf("IsHiddenPath(%q): expected %v, got %v", path, expected, result)
+		}
+	}
+}
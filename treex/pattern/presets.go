@@ -0,0 +1,156 @@
+// see docs/dev/patterns.txt
+package pattern
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/spf13/afero"
+	"treex/treex/pathutil"
+)
+
+// Presets are curated ignore sets for the build artifacts and dependency directories of
+// a language or package manager, matched against entry names like BuiltinIgnorePatterns
+// A trailing "/" matches directories only, so a file named build or bin stays visible.
+var Presets = map[string][]string{
+	"node":   {"node_modules/", "dist/", "build/", "coverage/", ".next/", ".nuxt/", ".turbo/", ".parcel-cache/", ".npm/", ".yarn/cache/"},
+	"python": {"__pycache__/", "*.pyc", "*.pyo", ".venv/", "venv/", ".tox/", ".pytest_cache/", ".mypy_cache/", ".ruff_cache/", "*.egg-info/", "build/", "dist/"},
+	"go":     {"vendor/", "bin/", "*.test", "*.out"},
+	"rust":   {"target/"},
+	"jvm":    {"target/", "build/", "out/", ".gradle/", "*.class"},
+}
+
+// presetLockfiles are the files at a project's root that enable a preset when presets
+// are detected
+var presetLockfiles = map[string][]string{
+	"node":   {"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml", "bun.lockb", "bun.lock"},
+	"python": {"poetry.lock", "Pipfile.lock", "uv.lock", "pdm.lock"},
+	"go":     {"go.sum"},
+	"rust":   {"Cargo.lock"},
+	"jvm":    {"gradle.lockfile", "pom.xml", "build.gradle", "build.gradle.kts"},
+}
+
+// PresetNames returns the names of the presets, sorted
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckPresets returns an error naming the first of names that is not a preset
+func CheckPresets(names []string) error {
+	for _, name := range names {
+		if _, ok := Presets[name]; !ok {
+			return fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(PresetNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// DetectPresets returns the presets whose lockfiles are in root, sorted
+func DetectPresets(fs afero.Fs, root string) []string {
+	var names []string
+	for _, name := range PresetNames() {
+		for _, lockfile := range presetLockfiles[name] {
+			if info, err := fs.Stat(filepath.Join(root, lockfile)); err == nil && !info.IsDir() {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// PresetPattern excludes the entries matched by a set of presets, except kept paths
+// (e.g. annotated ones) and the directories leading to them
+type PresetPattern struct {
+	names       []string
+	globs       []string // Globs matched against entry names
+	dirGlobs    []string // Globs matched against directory names only
+	normalizer  *pathutil.Normalizer
+	kept        map[string]bool
+	keptParents map[string]bool
+}
+
+// NewPresetPattern creates a pattern for the named presets (see CheckPresets)
+// The normalizer controls separator and case handling of keptPaths; nil uses the OS defaults
+func NewPresetPattern(names []string, keptPaths map[string]bool, normalizer *pathutil.Normalizer) *PresetPattern {
+	if normalizer == nil {
+		normalizer = pathutil.NewNormalizer(false)
+	}
+	pp := &PresetPattern{names: names, normalizer: normalizer, kept: make(map[string]bool), keptParents: make(map[string]bool)}
+
+	seen := make(map[string]bool)
+	for _, name := range names {
+		for _, glob := range Presets[name] {
+			if seen[glob] {
+				continue // Presets share entries such as build/
+			}
+			seen[glob] = true
+			if dirGlob, ok := strings.CutSuffix(glob, "/"); ok {
+				pp.dirGlobs = append(pp.dirGlobs, dirGlob)
+			} else {
+				pp.globs = append(pp.globs, glob)
+			}
+		}
+	}
+
+	for keptPath, keep := range keptPaths {
+		if !keep {
+			continue
+		}
+		key := normalizer.Key(keptPath)
+		pp.kept[key] = true
+		for dir := path.Dir(key); dir != "." && dir != "/" && dir != ""; dir = path.Dir(dir) {
+			pp.keptParents[dir] = true
+		}
+	}
+	return pp
+}
+
+// Matches returns true if the path should be excluded: its name matches a preset and no
+// kept path is at or below it
+func (pp *PresetPattern) Matches(p string, isDir bool) bool {
+	key := pp.normalizer.Key(p)
+	if pp.kept[key] || pp.keptParents[key] {
+		return false
+	}
+
+	// Multi-segment globs (.yarn/cache) match the end of the path, the others the name
+	for _, glob := range pp.globs {
+		if matchesTail(glob, key) {
+			return true
+		}
+	}
+	if isDir {
+		for _, glob := range pp.dirGlobs {
+			if matchesTail(glob, key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesTail reports whether glob matches the last segments of key, as many as it has
+func matchesTail(glob, key string) bool {
+	segments := strings.Count(glob, "/") + 1
+	parts := strings.Split(key, "/")
+	if len(parts) < segments {
+		return false
+	}
+	matched, err := doublestar.Match(glob, strings.Join(parts[len(parts)-segments:], "/"))
+	return err == nil && matched
+}
+
+// String returns a description of the pattern for debugging
+func (pp *PresetPattern) String() string {
+	return "preset:" + strings.Join(pp.names, ",")
+}
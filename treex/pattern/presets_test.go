@@ -0,0 +1,56 @@
+package pattern_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"treex/internal/testutil"
+	"treex/treex/pattern"
+)
+
+func TestPresetPattern(t *testing.T) {
+	kept := map[string]bool{"web/dist": true, "api/target/release/app": true}
+	preset := pattern.NewPresetPattern([]string{"node", "rust", "python"}, kept, nil)
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+		desc     string
+	}{
+		{"node_modules", true, true, "dependency directory"},
+		{"web/build", true, true, "build output at any depth"},
+		{"scripts/build", false, false, "directory-only entries leave files alone"},
+		{"src/cache.pyc", false, true, "file globs"},
+		{"pkg/app.egg-info", true, true, "directory globs"},
+		{"web/.yarn/cache", true, true, "multi-segment entries match the end of the path"},
+		{".yarn", true, false, "only the named part of a multi-segment entry"},
+		{"web/dist", true, false, "annotated paths stay visible"},
+		{"api/target", true, false, "directories leading to annotated paths stay visible"},
+		{"api/target/debug", true, false, "other directories are matched by name"},
+		{"src/main.rs", false, false, "sources"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, preset.Matches(tt.path, tt.isDir), tt.desc)
+	}
+
+	assert.False(t, pattern.NewPresetPattern([]string{"go"}, nil, nil).Matches("target", true), "only the named presets apply")
+}
+
+func TestDetectPresets(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"go.sum":     "",
+		"yarn.lock":  "",
+		"Cargo.lock": map[string]interface{}{}, // A directory, not a lockfile
+		"web":        map[string]interface{}{"poetry.lock": ""},
+	})
+
+	assert.Equal(t, []string{"go", "node"}, pattern.DetectPresets(fs, "/project"), "lockfiles at the root only")
+	assert.Empty(t, pattern.DetectPresets(fs, "/missing"))
+}
+
+func TestCheckPresets(t *testing.T) {
+	assert.NoError(t, pattern.CheckPresets([]string{"node", "jvm"}))
+	assert.EqualError(t, pattern.CheckPresets([]string{"go", "ruby"}), `unknown preset "ruby" (available: go, jvm, node, python, rust)`)
+}
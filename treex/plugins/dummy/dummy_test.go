@@ -3,7 +3,7 @@ package dummy_test
 import (
 	"testing"
 
-	"treex/treex/internal/testutil"
+	"treex/internal/testutil"
 	"treex/treex/plugins/dummy"
 )
 
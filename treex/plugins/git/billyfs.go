@@ -0,0 +1,142 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/helper/chroot"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/spf13/afero"
+)
+
+// openRepository opens the repository whose worktree is root on fs
+// The OS filesystem goes through go-git directly so .git files (linked worktrees,
+// submodules) keep working; any other afero filesystem is read through aferoBilly
+func openRepository(fs afero.Fs, root string) (*git.Repository, error) {
+	if _, isOS := fs.(*afero.OsFs); isOS {
+		return git.PlainOpenWithOptions(root, &git.PlainOpenOptions{DetectDotGit: true})
+	}
+
+	worktree := chroot.New(&aferoBilly{fs: fs}, root)
+	dotGit, err := worktree.Chroot(git.GitDirName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dotGit.Stat(""); err != nil {
+		return nil, git.ErrRepositoryNotExists
+	}
+
+	storage := filesystem.NewStorage(dotGit, cache.NewObjectLRUDefault())
+	return git.Open(storage, worktree)
+}
+
+// InitRepository creates an empty repository whose worktree is root on fs, read and
+// written through aferoBilly like the repositories openRepository opens
+func InitRepository(fs afero.Fs, root string) (*git.Repository, error) {
+	worktree := chroot.New(&aferoBilly{fs: fs}, root)
+	dotGit, err := worktree.Chroot(git.GitDirName)
+	if err != nil {
+		return nil, err
+	}
+	storage := filesystem.NewStorage(dotGit, cache.NewObjectLRUDefault())
+	return git.Init(storage, worktree)
+}
+
+// aferoBilly adapts an afero filesystem to the billy interface go-git works with
+type aferoBilly struct {
+	fs afero.Fs
+}
+
+// aferoFile adds the locking billy expects; afero files are not shared across processes here
+type aferoFile struct {
+	afero.File
+}
+
+func (f *aferoFile) Lock() error   { return nil }
+func (f *aferoFile) Unlock() error { return nil }
+
+func (b *aferoBilly) Create(filename string) (billy.File, error) {
+	return b.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+func (b *aferoBilly) Open(filename string) (billy.File, error) {
+	return b.OpenFile(filename, os.O_RDONLY, 0)
+}
+
+func (b *aferoBilly) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	if flag&os.O_CREATE != 0 {
+		if err := b.fs.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			return nil, err
+		}
+	}
+	file, err := b.fs.OpenFile(filename, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &aferoFile{File: file}, nil
+}
+
+func (b *aferoBilly) Stat(filename string) (os.FileInfo, error) {
+	return b.fs.Stat(filename)
+}
+
+func (b *aferoBilly) Rename(oldpath, newpath string) error {
+	return b.fs.Rename(oldpath, newpath)
+}
+
+func (b *aferoBilly) Remove(filename string) error {
+	return b.fs.Remove(filename)
+}
+
+func (b *aferoBilly) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (b *aferoBilly) TempFile(dir, prefix string) (billy.File, error) {
+	file, err := afero.TempFile(b.fs, dir, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return &aferoFile{File: file}, nil
+}
+
+func (b *aferoBilly) ReadDir(path string) ([]os.FileInfo, error) {
+	return afero.ReadDir(b.fs, path)
+}
+
+func (b *aferoBilly) MkdirAll(filename string, perm os.FileMode) error {
+	return b.fs.MkdirAll(filename, perm)
+}
+
+func (b *aferoBilly) Lstat(filename string) (os.FileInfo, error) {
+	if lstater, ok := b.fs.(afero.Lstater); ok {
+		info, _, err := lstater.LstatIfPossible(filename)
+		return info, err
+	}
+	return b.fs.Stat(filename)
+}
+
+func (b *aferoBilly) Symlink(target, link string) error {
+	if linker, ok := b.fs.(afero.Linker); ok {
+		return linker.SymlinkIfPossible(target, link)
+	}
+	return billy.ErrNotSupported
+}
+
+func (b *aferoBilly) Readlink(link string) (string, error) {
+	if reader, ok := b.fs.(afero.LinkReader); ok {
+		return reader.ReadlinkIfPossible(link)
+	}
+	return "", billy.ErrNotSupported
+}
+
+func (b *aferoBilly) Chroot(path string) (billy.Filesystem, error) {
+	return chroot.New(b, path), nil
+}
+
+func (b *aferoBilly) Root() string {
+	return string(filepath.Separator)
+}
@@ -0,0 +1,94 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/afero"
+)
+
+// PathChurn is how often and how recently a path changed in the git history
+type PathChurn struct {
+	Commits      int       // Commits that changed the path
+	LastModified time.Time // Author time of the newest of them
+}
+
+// Churn returns the churn of every path changed in the history of HEAD, following first
+// parents, keyed by path relative to the repository root (slash-separated, "." for the
+// root). A directory counts the commits changing anything below it, once per commit.
+func Churn(ctx context.Context, fs afero.Fs, repoRoot string) (map[string]PathChurn, error) {
+	repo, err := openRepository(fs, repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", repoRoot, err)
+	}
+	churn := make(map[string]PathChurn)
+	head, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return churn, nil // No commits yet
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", head.Hash(), err)
+	}
+
+	for commit != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var parent *object.Commit
+		var parentTree *object.Tree
+		if commit.NumParents() > 0 {
+			if parent, err = commit.Parent(0); err != nil {
+				return nil, fmt.Errorf("failed to read parent of %s: %w", commit.Hash, err)
+			}
+			if parentTree, err = parent.Tree(); err != nil {
+				return nil, fmt.Errorf("failed to read tree of %s: %w", parent.Hash, err)
+			}
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tree of %s: %w", commit.Hash, err)
+		}
+		changes, err := object.DiffTreeWithOptions(ctx, parentTree, tree, &object.DiffTreeOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff %s: %w", commit.Hash, err)
+		}
+
+		// Each path, and each directory above it, counts a commit once
+		changed := make(map[string]bool)
+		for _, change := range changes {
+			for _, name := range []string{change.From.Name, change.To.Name} {
+				for ; name != "" && !changed[name]; name = parentDir(name) {
+					changed[name] = true
+				}
+			}
+		}
+		for changedPath := range changed {
+			entry := churn[changedPath]
+			entry.Commits++
+			if entry.LastModified.IsZero() {
+				entry.LastModified = commit.Author.When // History is walked newest first
+			}
+			churn[changedPath] = entry
+		}
+		commit = parent
+	}
+	return churn, nil
+}
+
+// parentDir returns the directory holding the slash-separated p: "." for top-level paths,
+// "" for the root itself
+func parentDir(p string) string {
+	if p == "." {
+		return ""
+	}
+	return path.Dir(p)
+}
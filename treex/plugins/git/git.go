@@ -10,6 +10,7 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/spf13/afero"
+	"treex/treex/pathutil"
 	"treex/treex/plugins"
 	"treex/treex/types"
 )
@@ -82,10 +83,8 @@ func (p *GitPlugin) ProcessRoot(fs afero.Fs, rootPath string) (*plugins.Result,
 		Cache:      make(map[string]interface{}),
 	}
 
-	// Open the Git repository using go-git
-	repo, err := git.PlainOpenWithOptions(rootPath, &git.PlainOpenOptions{
-		DetectDotGit: true,
-	})
+	// Open the Git repository through the plugin filesystem
+	repo, err := openRepository(fs, rootPath)
 	if err != nil {
 		// If we can't open as Git repo, return empty result (not an error)
 		// This handles cases where .git exists but repo is corrupted/invalid
@@ -122,7 +121,7 @@ func (p *GitPlugin) ProcessRoot(fs afero.Fs, rootPath string) (*plugins.Result,
 
 	for filePath, fileStatus := range status {
 		// Normalize path separators for consistency
-		normalizedPath := filepath.ToSlash(filePath)
+		normalizedPath := pathutil.Normalize(filePath)
 
 		// Categorize based on Git status
 		// go-git status codes:
@@ -268,6 +267,35 @@ func (p *GitPlugin) GetCategories() []plugins.FilterPluginCategory {
 	}
 }
 
+// Badge returns the node's status in the two columns of git status --short: staged and
+// unstaged changes ("M " / " M" / "MM"), or "??" for untracked files
+// Implements BadgePlugin interface
+func (p *GitPlugin) Badge(node *types.Node) string {
+	data, ok := node.GetPluginData("git")
+	if !ok {
+		return ""
+	}
+	status, ok := data.(*types.GitStatus)
+	if !ok {
+		return ""
+	}
+
+	switch {
+	case status.Untracked:
+		return "??"
+	case !status.Staged && !status.Unstaged:
+		return ""
+	}
+	badge := []byte("  ")
+	if status.Staged {
+		badge[0] = 'M'
+	}
+	if status.Unstaged {
+		badge[1] = 'M'
+	}
+	return string(badge)
+}
+
 // EnrichNode attaches git status data to nodes
 // Implements DataPlugin interface
 func (p *GitPlugin) EnrichNode(fs afero.Fs, node *types.Node) error {
@@ -285,9 +313,7 @@ func (p *GitPlugin) EnrichNode(fs afero.Fs, node *types.Node) error {
 	}
 
 	// Open the Git repository
-	repo, err := git.PlainOpenWithOptions(gitRoot, &git.PlainOpenOptions{
-		DetectDotGit: true,
-	})
+	repo, err := openRepository(fs, gitRoot)
 	if err != nil {
 		// If we can't open git repo, skip enrichment (not an error)
 		return nil
@@ -312,7 +338,7 @@ func (p *GitPlugin) EnrichNode(fs afero.Fs, node *types.Node) error {
 			nodePath = rel
 		}
 	}
-	normalizedNodePath := filepath.ToSlash(nodePath)
+	normalizedNodePath := pathutil.Normalize(nodePath)
 
 	// Look for git status for this specific file
 	if fileStatus, exists := status[normalizedNodePath]; exists {
@@ -353,7 +379,7 @@ func (p *GitPlugin) EnrichData(fs afero.Fs, rootPath string, filePaths []string,
 		if gitStatusData, ok := cachedGitStatus.(map[string]types.GitStatus); ok {
 			// Use cached git status for efficient enrichment
 			for _, filePath := range filePaths {
-				normalizedFilePath := filepath.ToSlash(filePath)
+				normalizedFilePath := pathutil.Normalize(filePath)
 				if gitStatus, exists := gitStatusData[normalizedFilePath]; exists {
 					// Create a copy of the git status for this node
 					nodeGitStatus := &types.GitStatus{
@@ -377,9 +403,7 @@ func (p *GitPlugin) EnrichData(fs afero.Fs, rootPath string, filePaths []string,
 		}
 
 		// Open the Git repository
-		repo, err := git.PlainOpenWithOptions(gitRoot, &git.PlainOpenOptions{
-			DetectDotGit: true,
-		})
+		repo, err := openRepository(fs, gitRoot)
 		if err != nil {
 			// If we can't open git repo, return empty map (not an error)
 			return enrichmentMap, nil
@@ -402,11 +426,11 @@ func (p *GitPlugin) EnrichData(fs afero.Fs, rootPath string, filePaths []string,
 			// Convert file path to relative path from git root for status lookup
 			statusPath := filePath
 			if gitRoot != "." {
-				if rel, err := filepath.Rel(gitRoot, filePath); err == nil {
+				if rel, err := filepath.Rel(gitRoot, filepath.Join(rootPath, filePath)); err == nil {
 					statusPath = rel
 				}
 			}
-			normalizedStatusPath := filepath.ToSlash(statusPath)
+			normalizedStatusPath := pathutil.Normalize(statusPath)
 
 			// Look for git status for this specific file
 			if fileStatus, exists := status[normalizedStatusPath]; exists {
@@ -470,7 +494,7 @@ func (p *GitPlugin) EnrichNodeWithCache(fs afero.Fs, node *types.Node, pluginRes
 				nodePath = filepath.Base(node.Path)
 			}
 		}
-		normalizedNodePath := filepath.ToSlash(nodePath)
+		normalizedNodePath := pathutil.Normalize(nodePath)
 
 		// Look for git status for this specific file
 		if gitStatus, exists := gitStatusMap[normalizedNodePath]; exists {
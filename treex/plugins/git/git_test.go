@@ -1,14 +1,20 @@
 package git_test
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/spf13/afero"
-	"treex/treex/internal/testutil"
+	"treex/internal/testutil"
 	gitplugin "treex/treex/plugins/git"
 	"treex/treex/types"
 )
@@ -235,7 +241,7 @@ func TestGitPluginWithRealGitRepo(t *testing.T) {
 
 	// Now test the plugin
 	plugin := gitplugin.NewGitPlugin()
-	fs := testutil.NewTestFS() // We won't use this, but plugin interface requires it
+	fs := afero.NewOsFs() // The repository is read through the plugin filesystem
 
 	result, err := plugin.ProcessRoot(fs, tempDir)
 	if err != nil {
@@ -658,3 +664,401 @@ func TestGitPlugin_ErrorHandling(t *testing.T) {
 		}
 	})
 }
+
+func TestGitPluginReadsThroughFilesystem(t *testing.T) {
+	tempDir := t.TempDir()
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	for _, name := range []string{"committed.txt", "edited.txt"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte("original"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Add("."); err != nil {
+		t.Fatalf("Failed to stage files: %v", err)
+	}
+	if _, err := worktree.Commit("Initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com"},
+	}); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	// Changes made in a copy-on-write layer exist only in memory; the plugin must
+	// see them, which proves the repository is read through the given filesystem
+	base := afero.NewBasePathFs(afero.NewOsFs(), tempDir)
+	fs := afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(base), afero.NewMemMapFs())
+	if err := afero.WriteFile(fs, "/edited.txt", []byte("changed in memory"), 0644); err != nil {
+		t.Fatalf("Failed to write overlay file: %v", err)
+	}
+	if err := afero.WriteFile(fs, "/new.txt", []byte("new"), 0644); err != nil {
+		t.Fatalf("Failed to write overlay file: %v", err)
+	}
+
+	result, err := gitplugin.NewGitPlugin().ProcessRoot(fs, "/")
+	if err != nil {
+		t.Fatalf("ProcessRoot failed: %v", err)
+	}
+	if errMsg, ok := result.Metadata["error"]; ok {
+		t.Fatalf("Unexpected plugin error: %v", errMsg)
+	}
+
+	if got := result.Categories["unstaged"]; len(got) != 1 || got[0] != "edited.txt" {
+		t.Errorf("Expected unstaged [edited.txt], got %v", got)
+	}
+	if got := result.Categories["untracked"]; len(got) != 1 || got[0] != "new.txt" {
+		t.Errorf("Expected untracked [new.txt], got %v", got)
+	}
+
+	// The on-disk worktree is untouched
+	content, err := os.ReadFile(filepath.Join(tempDir, "edited.txt"))
+	if err != nil || string(content) != "original" {
+		t.Errorf("Expected on-disk file to be unchanged, got %q (%v)", content, err)
+	}
+}
+
+func TestStagedChangesAndStage(t *testing.T) {
+	tempDir := t.TempDir()
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+
+	for name, content := range map[string]string{"old.txt": "old", "keep.txt": "keep"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if _, err := worktree.Add("."); err != nil {
+		t.Fatalf("Failed to add files: %v", err)
+	}
+	signature := &object.Signature{Name: "Test Author", Email: "test@example.com"}
+	hash, err := worktree.Commit("Initial commit", &git.CommitOptions{Author: signature})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	if _, err := worktree.Remove("old.txt"); err != nil {
+		t.Fatalf("Failed to remove old.txt: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ".info"), []byte("keep.txt  Kept\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .info: %v", err)
+	}
+
+	fs := afero.NewOsFs()
+	if root := gitplugin.RepositoryRoot(fs, tempDir); root != tempDir {
+		t.Errorf("Expected repository root %s, got %q", tempDir, root)
+	}
+	if branch, commit, err := gitplugin.Head(fs, tempDir); err != nil || branch != "master" || commit != hash.String()[:7] {
+		t.Errorf("Expected HEAD master at %s, got %q at %q (%v)", hash.String()[:7], branch, commit, err)
+	}
+	if err := gitplugin.Stage(fs, tempDir, []string{".info"}); err != nil {
+		t.Fatalf("Stage failed: %v", err)
+	}
+
+	changes, err := gitplugin.StagedChanges(fs, tempDir)
+	if err != nil {
+		t.Fatalf("StagedChanges failed: %v", err)
+	}
+	expected := []gitplugin.StagedChange{{Path: ".info"}, {Path: "old.txt", Deleted: true}}
+	if len(changes) != len(expected) || changes[0] != expected[0] || changes[1] != expected[1] {
+		t.Errorf("Expected staged changes %v, got %v", expected, changes)
+	}
+}
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		arg    string
+		want   gitplugin.Remote
+		remote bool
+	}{
+		{"https://github.com/org/repo", gitplugin.Remote{URL: "https://github.com/org/repo"}, true},
+		{"https://github.com/org/repo@v1.2.0", gitplugin.Remote{URL: "https://github.com/org/repo", Ref: "v1.2.0"}, true},
+		{"https://user@host.com/org/repo.git", gitplugin.Remote{URL: "https://user@host.com/org/repo.git"}, true},
+		{"git@github.com:org/repo.git@main", gitplugin.Remote{URL: "git@github.com:org/repo.git", Ref: "main"}, true},
+		{"file:///srv/repo", gitplugin.Remote{URL: "file:///srv/repo"}, true},
+		{"./src", gitplugin.Remote{}, false},
+		{"/home/user@work/project", gitplugin.Remote{}, false},
+		{"C:/project", gitplugin.Remote{}, false},
+	}
+	for _, tt := range tests {
+		got, remote := gitplugin.ParseRemote(tt.arg)
+		if remote != tt.remote || got != tt.want {
+			t.Errorf("ParseRemote(%q) = %+v, %v; want %+v, %v", tt.arg, got, remote, tt.want, tt.remote)
+		}
+	}
+}
+
+func TestCheckout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("local clones need the git binary")
+	}
+
+	source := t.TempDir()
+	repo, err := git.PlainInit(source, false)
+	if err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+	commit := func(name, content string) plumbing.Hash {
+		if err := os.WriteFile(filepath.Join(source, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		hash, err := worktree.Commit("Add "+name, &git.CommitOptions{Author: &object.Signature{Name: "Test", Email: "test@example.com"}})
+		if err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+		return hash
+	}
+	first := commit(".info", "first.txt  First file\n")
+	commit("first.txt", "first")
+	if _, err := repo.CreateTag("v1", first, nil); err != nil {
+		t.Fatalf("Failed to tag: %v", err)
+	}
+	commit("second.txt", "second")
+
+	cacheDir := t.TempDir()
+	ctx := context.Background()
+	url := "file://" + filepath.ToSlash(source)
+
+	for _, tt := range []struct {
+		ref     string
+		present []string
+		absent  []string
+	}{
+		{"", []string{".info", "first.txt", "second.txt"}, nil},
+		{"v1", []string{".info"}, []string{"first.txt"}},
+		{first.String()[:8], []string{".info"}, []string{"second.txt"}},
+	} {
+		dir, err := gitplugin.Checkout(ctx, gitplugin.Remote{URL: url, Ref: tt.ref}, cacheDir, false)
+		if err != nil {
+			t.Fatalf("Checkout(%q) failed: %v", tt.ref, err)
+		}
+		for _, name := range tt.present {
+			if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+				t.Errorf("Checkout(%q): expected %s: %v", tt.ref, name, err)
+			}
+		}
+		for _, name := range tt.absent {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				t.Errorf("Checkout(%q): did not expect %s", tt.ref, name)
+			}
+		}
+	}
+
+	// The cached clone is reused without fetching
+	commit("third.txt", "third")
+	dir, err := gitplugin.Checkout(ctx, gitplugin.Remote{URL: url}, cacheDir, false)
+	if err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "third.txt")); err == nil {
+		t.Errorf("Expected the cached clone to be reused")
+	}
+	dir, err = gitplugin.Checkout(ctx, gitplugin.Remote{URL: url}, cacheDir, true)
+	if err != nil {
+		t.Fatalf("Checkout with refresh failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "third.txt")); err != nil {
+		t.Errorf("Expected a refreshed clone: %v", err)
+	}
+}
+
+func TestAnnotationHistory(t *testing.T) {
+	fs := testutil.NewTestFS()
+	repo := fs.MustInitRepo("/repo")
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+
+	// commit writes files (empty content removes them) and commits them as author
+	commit := func(author, message string, files map[string]string) {
+		t.Helper()
+		for name, content := range files {
+			if content == "" {
+				if _, err := worktree.Remove(name); err != nil {
+					t.Fatalf("Failed to remove %s: %v", name, err)
+				}
+				continue
+			}
+			fs.MustCreateTree("/repo", map[string]interface{}{name: content})
+			if _, err := worktree.Add(name); err != nil {
+				t.Fatalf("Failed to add %s: %v", name, err)
+			}
+		}
+		signature := &object.Signature{Name: author, Email: author + "@example.com"}
+		if _, err := worktree.Commit(message, &git.CommitOptions{Author: signature}); err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+	}
+
+	commit("alice", "Add the guide\n\nWith an annotation.", map[string]string{
+		"docs/guide.md": "# Guide", ".info": "docs/guide.md  User guide\n",
+	})
+	commit("bob", "Touch the readme", map[string]string{"README.md": "# Project"})
+	commit("carol", "Reword the guide annotation", map[string]string{"docs/.info": "guide.md  Guide for users\n"})
+	commit("dave", "Drop the root entry", map[string]string{".info": "README.md  Overview\n"})
+	commit("erin", "Remove the guide annotation", map[string]string{"docs/.info": "# nothing here\n"})
+
+	changes, err := gitplugin.AnnotationHistory(fs, "/repo", "docs/guide.md")
+	if err != nil {
+		t.Fatalf("AnnotationHistory failed: %v", err)
+	}
+
+	type summary struct {
+		author, summary, infoFile string
+		line                      int
+		before, after             string
+	}
+	var got []summary
+	for _, change := range changes {
+		got = append(got, summary{change.Author, change.Summary, change.InfoFile, change.Line, change.Before, change.After})
+	}
+	expected := []summary{
+		{"erin", "Remove the guide annotation", "docs/.info", 1, "Guide for users", ""},
+		{"carol", "Reword the guide annotation", "docs/.info", 1, "User guide", "Guide for users"},
+		{"alice", "Add the guide", ".info", 1, "", "User guide"},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d: %+v", len(expected), len(got), got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Change %d: expected %+v, got %+v", i, expected[i], got[i])
+		}
+	}
+	if len(changes[0].Commit) != 40 || changes[0].Email != "erin@example.com" {
+		t.Errorf("Expected full commit hash and author email, got %q and %q", changes[0].Commit, changes[0].Email)
+	}
+
+	changes, err = gitplugin.AnnotationHistory(fs, "/repo", "README.md")
+	if err != nil || len(changes) != 1 || changes[0].Author != "dave" {
+		t.Errorf("Expected README.md to be annotated once by dave, got %+v (%v)", changes, err)
+	}
+}
+
+func TestCommitsSince(t *testing.T) {
+	fs := testutil.NewTestFS()
+	repo := fs.MustInitRepo("/repo")
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for day, name := range []string{"src/main.go", "README.md", "src/util.go", "src/main.go"} {
+		fs.MustCreateTree("/repo", map[string]interface{}{name: fmt.Sprintf("version %d", day)})
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		signature := &object.Signature{Name: "Test", Email: "test@example.com", When: start.AddDate(0, 0, day)}
+		if _, err := worktree.Commit("Change "+name, &git.CommitOptions{Author: signature}); err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+	}
+
+	counts, err := gitplugin.CommitsSince(fs, "/repo", map[string]time.Time{
+		"src/main.go": start.Add(-time.Hour), // Before every commit
+		"src":         start.Add(time.Hour),  // After the first commit
+		"README.md":   start.AddDate(0, 0, 2),
+		"missing.go":  start,
+	})
+	if err != nil {
+		t.Fatalf("CommitsSince failed: %v", err)
+	}
+	expected := map[string]int{"src/main.go": 2, "src": 2}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Expected %v, got %v", expected, counts)
+	}
+}
+
+func TestChurn(t *testing.T) {
+	fs := testutil.NewTestFS()
+	repo := fs.MustInitRepo("/repo")
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Failed to get worktree: %v", err)
+	}
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for day, names := range [][]string{{"src/main.go", "README.md"}, {"src/main.go", "src/util.go"}, {"README.md"}} {
+		for _, name := range names {
+			fs.MustCreateTree("/repo", map[string]interface{}{name: fmt.Sprintf("version %d", day)})
+			if _, err := worktree.Add(name); err != nil {
+				t.Fatalf("Failed to add %s: %v", name, err)
+			}
+		}
+		signature := &object.Signature{Name: "Test", Email: "test@example.com", When: start.AddDate(0, 0, day)}
+		if _, err := worktree.Commit(fmt.Sprintf("Day %d", day), &git.CommitOptions{Author: signature}); err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+	}
+
+	churn, err := gitplugin.Churn(context.Background(), fs, "/repo")
+	if err != nil {
+		t.Fatalf("Churn failed: %v", err)
+	}
+	expected := map[string]gitplugin.PathChurn{
+		".":           {Commits: 3, LastModified: start.AddDate(0, 0, 2)},
+		"README.md":   {Commits: 2, LastModified: start.AddDate(0, 0, 2)},
+		"src":         {Commits: 2, LastModified: start.AddDate(0, 0, 1)}, // Once per commit
+		"src/main.go": {Commits: 2, LastModified: start.AddDate(0, 0, 1)},
+		"src/util.go": {Commits: 1, LastModified: start.AddDate(0, 0, 1)},
+	}
+	if len(churn) != len(expected) {
+		t.Errorf("Expected %d paths, got %v", len(expected), churn)
+	}
+	for changedPath, want := range expected {
+		got := churn[changedPath]
+		if got.Commits != want.Commits || !got.LastModified.Equal(want.LastModified) {
+			t.Errorf("Expected %s to have %+v, got %+v", changedPath, want, got)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := gitplugin.Churn(ctx, fs, "/repo"); err == nil {
+		t.Error("Expected a canceled walk to fail")
+	}
+}
+
+func TestGitPluginBadge(t *testing.T) {
+	plugin := gitplugin.NewGitPlugin()
+	tests := []struct {
+		status   *types.GitStatus
+		expected string
+	}{
+		{nil, ""},
+		{&types.GitStatus{Status: "clean"}, ""},
+		{&types.GitStatus{Untracked: true}, "??"},
+		{&types.GitStatus{Staged: true}, "M "},
+		{&types.GitStatus{Unstaged: true}, " M"},
+		{&types.GitStatus{Staged: true, Unstaged: true}, "MM"},
+	}
+	for _, tt := range tests {
+		node := &types.Node{Name: "main.go", Path: "main.go"}
+		if tt.status != nil {
+			node.SetPluginData("git", tt.status)
+		}
+		if badge := plugin.Badge(node); badge != tt.expected {
+			t.Errorf("Badge(%+v) = %q, want %q", tt.status, badge, tt.expected)
+		}
+	}
+}
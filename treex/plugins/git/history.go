@@ -0,0 +1,214 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/afero"
+	"treex/treex/pathutil"
+	"treex/treex/plugins/infofile"
+)
+
+// AnnotationChange is a commit that changed the annotation of a path
+type AnnotationChange struct {
+	Commit  string    `json:"commit"`  // Full commit hash
+	Author  string    `json:"author"`  // Author name
+	Email   string    `json:"email"`   // Author email
+	When    time.Time `json:"when"`    // Author date
+	Summary string    `json:"summary"` // First line of the commit message
+
+	InfoFile string `json:"infoFile"` // .info file holding the annotation after the commit (before it, when removed)
+	Line     int    `json:"line"`     // Line of the entry in InfoFile
+	Before   string `json:"before"`   // Annotation text before the commit ("" when added)
+	After    string `json:"after"`    // Annotation text after the commit ("" when removed)
+}
+
+// committedAnnotation is the annotation of a path in one commit
+type committedAnnotation struct {
+	infoFile string
+	line     int
+	notes    string
+}
+
+// AnnotationHistory lists the commits that changed the annotation of target (relative to
+// the repository root, slash-separated), newest first
+//
+// History follows first parents from HEAD. In each commit the annotation is read as
+// treex shows it: from the closest .info file above target that annotates it, so moves
+// between .info files (treex gather, distribute) only count when the text changes.
+func AnnotationHistory(fs afero.Fs, repoRoot, target string) ([]AnnotationChange, error) {
+	repo, err := openRepository(fs, repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", repoRoot, err)
+	}
+	head, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil // No commits yet
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", head.Hash(), err)
+	}
+
+	target = pathutil.Normalize(target)
+	current, err := annotationAt(commit, target)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []AnnotationChange
+	for commit != nil {
+		var parent *object.Commit
+		var previous committedAnnotation
+		if commit.NumParents() > 0 {
+			if parent, err = commit.Parent(0); err != nil {
+				return nil, fmt.Errorf("failed to read parent of %s: %w", commit.Hash, err)
+			}
+			if previous, err = annotationAt(parent, target); err != nil {
+				return nil, err
+			}
+		}
+
+		if current.notes != previous.notes {
+			change := AnnotationChange{
+				Commit:   commit.Hash.String(),
+				Author:   commit.Author.Name,
+				Email:    commit.Author.Email,
+				When:     commit.Author.When,
+				Summary:  strings.SplitN(strings.TrimSpace(commit.Message), "\n", 2)[0],
+				InfoFile: current.infoFile,
+				Line:     current.line,
+				Before:   previous.notes,
+				After:    current.notes,
+			}
+			if current.notes == "" {
+				change.InfoFile, change.Line = previous.infoFile, previous.line
+			}
+			changes = append(changes, change)
+		}
+		commit, current = parent, previous
+	}
+	return changes, nil
+}
+
+// annotationAt reads the annotation of target in commit from the closest .info file
+func annotationAt(commit *object.Commit, target string) (committedAnnotation, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return committedAnnotation{}, fmt.Errorf("failed to read tree of %s: %w", commit.Hash, err)
+	}
+
+	for dir := path.Dir(target); ; dir = path.Dir(dir) {
+		infoFile := path.Join(dir, ".info")
+		file, err := tree.File(infoFile)
+		if err == nil {
+			content, err := file.Contents()
+			if err != nil {
+				return committedAnnotation{}, fmt.Errorf("failed to read %s in %s: %w", infoFile, commit.Hash, err)
+			}
+			entries, err := infofile.Parse([]byte(content))
+			if err != nil {
+				return committedAnnotation{}, fmt.Errorf("failed to read %s in %s: %w", infoFile, commit.Hash, err)
+			}
+			for _, entry := range entries {
+				if entry.Notes != "" && path.Join(dir, entry.Path) == target {
+					return committedAnnotation{infoFile: infoFile, line: entry.Line, notes: entry.Notes}, nil
+				}
+			}
+		} else if !errors.Is(err, object.ErrFileNotFound) && !errors.Is(err, object.ErrDirectoryNotFound) {
+			return committedAnnotation{}, fmt.Errorf("failed to read %s in %s: %w", infoFile, commit.Hash, err)
+		}
+		if dir == "." {
+			return committedAnnotation{}, nil
+		}
+	}
+}
+
+// CommitsSince counts, for each target (relative to the repository root, slash-separated),
+// the commits authored after its time that changed it, following first parents from
+// HEAD. A directory counts the commits changing anything below it.
+func CommitsSince(fs afero.Fs, repoRoot string, since map[string]time.Time) (map[string]int, error) {
+	repo, err := openRepository(fs, repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", repoRoot, err)
+	}
+	counts := make(map[string]int, len(since))
+	head, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return counts, nil // No commits yet
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", head.Hash(), err)
+	}
+
+	oldest := time.Now()
+	for _, when := range since {
+		if when.Before(oldest) {
+			oldest = when
+		}
+	}
+	for commit != nil && commit.Author.When.After(oldest) {
+		var parent *object.Commit
+		if commit.NumParents() > 0 {
+			if parent, err = commit.Parent(0); err != nil {
+				return nil, fmt.Errorf("failed to read parent of %s: %w", commit.Hash, err)
+			}
+		}
+		for target, when := range since {
+			if !commit.Author.When.After(when) {
+				continue
+			}
+			changed, err := changedIn(commit, parent, pathutil.Normalize(target))
+			if err != nil {
+				return nil, err
+			}
+			if changed {
+				counts[target]++
+			}
+		}
+		commit = parent
+	}
+	return counts, nil
+}
+
+// changedIn reports whether target differs between commit and its parent (nil for the
+// first commit)
+func changedIn(commit, parent *object.Commit, target string) (bool, error) {
+	current, err := entryHash(commit, target)
+	if err != nil || parent == nil {
+		return !current.IsZero(), err
+	}
+	previous, err := entryHash(parent, target)
+	return current != previous, err
+}
+
+// entryHash returns the hash of the blob or tree at target in commit (zero when absent)
+func entryHash(commit *object.Commit, target string) (plumbing.Hash, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read tree of %s: %w", commit.Hash, err)
+	}
+	if target == "." {
+		return tree.Hash, nil
+	}
+	entry, err := tree.FindEntry(target)
+	if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+		return plumbing.ZeroHash, nil
+	}
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read %s in %s: %w", target, commit.Hash, err)
+	}
+	return entry.Hash, nil
+}
@@ -0,0 +1,161 @@
+package git
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Remote is a repository to fetch instead of a local path
+type Remote struct {
+	URL string // Clone URL
+	Ref string // Branch, tag or commit; "" for the default branch
+}
+
+// scpURL matches scp-like addresses such as git@github.com:org/repo
+var scpURL = regexp.MustCompile(`^[\w.-]+@[\w.-]+:[^/]`)
+
+// ParseRemote recognizes repository URLs (https, http, ssh, git, file and scp-like
+// user@host:path), with an optional "@ref" after the last path component. Anything else
+// is a local path and reports false.
+func ParseRemote(arg string) (Remote, bool) {
+	isURL := false
+	for _, scheme := range []string{"https://", "http://", "ssh://", "git://", "file://"} {
+		if strings.HasPrefix(arg, scheme) {
+			isURL = true
+			break
+		}
+	}
+	if !isURL && !scpURL.MatchString(arg) {
+		return Remote{}, false
+	}
+
+	remote := Remote{URL: arg}
+	lastSlash := strings.LastIndexAny(arg, "/:")
+	if at := strings.LastIndex(arg, "@"); at > lastSlash {
+		remote.URL, remote.Ref = arg[:at], arg[at+1:]
+	}
+	return remote, true
+}
+
+// DefaultCacheDir is where Checkout keeps clones (~/.cache/treex/repos on Linux)
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "treex", "repos")
+	}
+	return filepath.Join(dir, "treex", "repos")
+}
+
+// Checkout returns a local worktree of remote below cacheDir, cloning it on first use
+//
+// Branches and tags are fetched shallowly; other refs (commit hashes) need a full clone.
+// An existing clone is reused unless refresh is set. Clones are written to the OS
+// filesystem, which go-git transports require.
+func Checkout(ctx context.Context, remote Remote, cacheDir string, refresh bool) (string, error) {
+	sum := sha256.Sum256([]byte(remote.URL + "@" + remote.Ref))
+	name := strings.TrimSuffix(path.Base(strings.TrimRight(strings.ReplaceAll(remote.URL, ":", "/"), "/")), ".git")
+	target := filepath.Join(cacheDir, name+"-"+hex.EncodeToString(sum[:])[:12])
+
+	if _, err := os.Stat(target); err == nil {
+		if !refresh {
+			return target, nil
+		}
+		if err := os.RemoveAll(target); err != nil {
+			return "", fmt.Errorf("failed to remove cached clone: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(cacheDir, ".clone-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	if err := cloneRef(ctx, staging, remote); err != nil {
+		return "", err
+	}
+	if err := os.Rename(staging, target); err != nil {
+		return "", fmt.Errorf("failed to cache clone: %w", err)
+	}
+	return target, nil
+}
+
+// cloneRef clones remote into dir, trying the ref as a branch, then a tag, then any revision
+func cloneRef(ctx context.Context, dir string, remote Remote) error {
+	options := &git.CloneOptions{URL: remote.URL, Depth: 1, SingleBranch: true, Tags: git.NoTags}
+	if remote.Ref == "" {
+		if _, err := git.PlainCloneContext(ctx, dir, false, options); err != nil {
+			return fmt.Errorf("failed to clone %s: %w", remote.URL, err)
+		}
+		return nil
+	}
+
+	for _, refName := range []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(remote.Ref),
+		plumbing.NewTagReferenceName(remote.Ref),
+	} {
+		options.ReferenceName = refName
+		_, err := git.PlainCloneContext(ctx, dir, false, options)
+		if err == nil {
+			return nil
+		}
+		if !isMissingRef(err) {
+			return fmt.Errorf("failed to clone %s: %w", remote.URL, err)
+		}
+		if err := clearDir(dir); err != nil {
+			return err
+		}
+	}
+
+	repo, err := git.PlainCloneContext(ctx, dir, false, &git.CloneOptions{URL: remote.URL, NoCheckout: true})
+	if err != nil {
+		return fmt.Errorf("failed to clone %s: %w", remote.URL, err)
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(remote.Ref))
+	if err != nil {
+		return fmt.Errorf("unknown ref %q in %s", remote.Ref, remote.URL)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get git worktree: %w", err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Hash: *hash, Force: true}); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", remote.Ref, err)
+	}
+	return nil
+}
+
+// isMissingRef reports whether a clone failed because the requested reference does not exist
+func isMissingRef(err error) bool {
+	var noMatch git.NoMatchingRefSpecError
+	return errors.Is(err, plumbing.ErrReferenceNotFound) || errors.As(err, &noMatch) ||
+		strings.Contains(err.Error(), "couldn't find remote ref")
+}
+
+// clearDir empties dir after a failed clone attempt
+func clearDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/spf13/afero"
+	"treex/treex/pathutil"
+)
+
+// StagedChange is a path with changes in the index
+type StagedChange struct {
+	Path    string // Relative to the repository root, slash-separated
+	Deleted bool   // Staged for deletion
+}
+
+// RepositoryRoot returns the worktree root of the repository containing path, or ""
+func RepositoryRoot(fs afero.Fs, path string) string {
+	return NewGitPlugin().findGitRoot(fs, path)
+}
+
+// HooksDir returns the directory git runs the hooks of the repository whose worktree is
+// root from: core.hooksPath when the repository config sets it (relative paths are
+// resolved from root, as git does), else .git/hooks
+func HooksDir(fs afero.Fs, root string) (string, error) {
+	repo, err := openRepository(fs, root)
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository at %s: %w", root, err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("failed to read git config: %w", err)
+	}
+
+	hooksPath := cfg.Raw.Section("core").Option("hooksPath")
+	switch {
+	case hooksPath == "":
+		return filepath.Join(root, git.GitDirName, "hooks"), nil
+	case strings.HasPrefix(hooksPath, "~/"):
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve core.hooksPath %s: %w", hooksPath, err)
+		}
+		return filepath.Join(home, filepath.FromSlash(hooksPath[2:])), nil
+	case filepath.IsAbs(hooksPath):
+		return filepath.Clean(hooksPath), nil
+	}
+	return filepath.Join(root, filepath.FromSlash(hooksPath)), nil
+}
+
+// Head returns the branch checked out in the repository whose worktree is root ("" when
+// HEAD is detached) and the abbreviated hash of the commit HEAD points to
+func Head(fs afero.Fs, root string) (branch, commit string, err error) {
+	repo, err := openRepository(fs, root)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open git repository at %s: %w", root, err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read git HEAD: %w", err)
+	}
+	if head.Name().IsBranch() {
+		branch = head.Name().Short()
+	}
+	return branch, head.Hash().String()[:7], nil
+}
+
+// StagedChanges lists the paths staged in the repository whose worktree is root, sorted
+func StagedChanges(fs afero.Fs, root string) ([]StagedChange, error) {
+	repo, err := openRepository(fs, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", root, err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git worktree: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git status: %w", err)
+	}
+
+	var changes []StagedChange
+	for filePath, fileStatus := range status {
+		if fileStatus.Staging == git.Unmodified || fileStatus.Staging == git.Untracked {
+			continue
+		}
+		changes = append(changes, StagedChange{
+			Path:    pathutil.Normalize(filePath),
+			Deleted: fileStatus.Staging == git.Deleted,
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// Stage adds paths (relative to the repository root) to the index
+func Stage(fs afero.Fs, root string, paths []string) error {
+	repo, err := openRepository(fs, root)
+	if err != nil {
+		return fmt.Errorf("failed to open git repository at %s: %w", root, err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get git worktree: %w", err)
+	}
+	for _, path := range paths {
+		if _, err := worktree.Add(filepath.ToSlash(path)); err != nil {
+			return fmt.Errorf("failed to stage %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// UserName returns the user.name of the repository whose worktree is root, as set in its
+// configuration or the global one ("" when unset)
+func UserName(fs afero.Fs, root string) string {
+	repo, err := openRepository(fs, root)
+	if err != nil {
+		return ""
+	}
+	cfg, err := repo.ConfigScoped(config.GlobalScope)
+	if err != nil {
+		return ""
+	}
+	return cfg.User.Name
+}
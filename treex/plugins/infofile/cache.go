@@ -0,0 +1,156 @@
+package infofile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// ParseCache keeps the parsed entries of .info files between Gather calls, so repeated
+// gathers only read files that changed. An entry is reused while the file keeps its
+// modification time and size. Files are keyed by path, so one cache serves one
+// filesystem; on an afero.BasePathFs view the key is the path in the filesystem below
+// it, so views of different directories do not share keys. It is safe for concurrent use.
+type ParseCache struct {
+	mu      sync.Mutex
+	files   map[string]cachedFile
+	used    map[string]bool // Files looked up since the cache was created or loaded
+	changed bool            // Entries were added or replaced since then
+}
+
+// cachedFile is the parsed content of one .info file
+type cachedFile struct {
+	ModTime  time.Time `json:"modTime"`
+	Size     int64     `json:"size"`
+	Entries  []Entry   `json:"entries"`
+	Includes []Include `json:"includes,omitempty"`
+}
+
+// cacheVersion changes whenever parsing does, so caches saved by other versions are not
+// trusted
+const cacheVersion = 2
+
+// savedCache is the file a cache is saved to
+type savedCache struct {
+	Version int                   `json:"version"`
+	Files   map[string]cachedFile `json:"files"`
+}
+
+// activeCache is the cache Gather uses (nil = parse every file on every call)
+var activeCache atomic.Pointer[ParseCache]
+
+// NewParseCache returns an empty cache
+func NewParseCache() *ParseCache {
+	return &ParseCache{files: make(map[string]cachedFile), used: make(map[string]bool)}
+}
+
+// UseCache makes Gather, and everything built on it, reuse parsed files from cache
+// A nil cache turns caching off again.
+func UseCache(cache *ParseCache) {
+	activeCache.Store(cache)
+}
+
+// LoadParseCache reads a cache saved with Save; a missing or unreadable file yields an
+// empty cache, since the cache only ever saves work
+func LoadParseCache(fs afero.Fs, cachePath string) *ParseCache {
+	cache := NewParseCache()
+	content, err := afero.ReadFile(fs, cachePath)
+	if err != nil {
+		return cache
+	}
+	var saved savedCache
+	if json.Unmarshal(content, &saved) == nil && saved.Version == cacheVersion && saved.Files != nil {
+		cache.files = saved.Files
+	}
+	return cache
+}
+
+// Save writes the files looked up since the cache was loaded to cachePath, dropping the
+// rest (files that were removed or are no longer below the gathered root). Nothing is
+// written when no entry changed.
+func (c *ParseCache) Save(fs afero.Fs, cachePath string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.changed && len(c.used) == len(c.files) {
+		return nil
+	}
+
+	files := make(map[string]cachedFile, len(c.used))
+	for filePath := range c.used {
+		if file, ok := c.files[filePath]; ok {
+			files[filePath] = file
+		}
+	}
+	content, err := json.Marshal(savedCache{Version: cacheVersion, Files: files})
+	if err != nil {
+		return fmt.Errorf("failed to encode .info cache: %w", err)
+	}
+	if err := fs.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := writeAtomic(fs, cachePath, content); err != nil {
+		return fmt.Errorf("failed to write .info cache: %w", err)
+	}
+	return nil
+}
+
+// lookup returns the cached content of filePath if the file is unchanged
+func (c *ParseCache) lookup(filePath string, info os.FileInfo) (cachedFile, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.used[filePath] = true
+	file, ok := c.files[filePath]
+	if !ok || file.Size != info.Size() || !file.ModTime.Equal(info.ModTime()) {
+		return cachedFile{}, false
+	}
+	return file, true
+}
+
+// store records the entries and includes parsed from filePath
+func (c *ParseCache) store(filePath string, info os.FileInfo, entries []Entry, includes []Include) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files[filePath] = cachedFile{ModTime: info.ModTime(), Size: info.Size(), Entries: entries, Includes: includes}
+	c.changed = true
+}
+
+// parseFile returns the entries and @include directives of the .info file at filePath,
+// from the active cache when the file is unchanged
+func parseFile(fs afero.Fs, filePath string, info os.FileInfo) ([]Entry, []Include, error) {
+	cache := activeCache.Load()
+	key := cacheKey(fs, filePath)
+	if cache != nil {
+		if file, ok := cache.lookup(key, info); ok {
+			return file.Entries, file.Includes, nil
+		}
+	}
+	content, err := afero.ReadFile(fs, filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := Parse(content)
+	includes, _ := ParseIncludes(content) // Stops where Parse does
+	if err != nil {
+		return entries, includes, fmt.Errorf("%s: %w", filePath, err)
+	}
+	if cache != nil {
+		cache.store(key, info, entries, includes)
+	}
+	return entries, includes, nil
+}
+
+// cacheKey returns the key of filePath on fs in a ParseCache
+func cacheKey(fs afero.Fs, filePath string) string {
+	if view, ok := fs.(interface{ RealPath(string) (string, error) }); ok {
+		if realPath, err := view.RealPath(filePath); err == nil {
+			return realPath
+		}
+	}
+	return filePath
+}
@@ -0,0 +1,121 @@
+package infofile_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins/infofile"
+)
+
+// gatherNotes gathers root and returns the notes of each annotated path
+func gatherNotes(t *testing.T, fs afero.Fs, root string) map[string]string {
+	annotations, err := infofile.Gather(fs, root)
+	require.NoError(t, err)
+	notes := make(map[string]string, len(annotations))
+	for annotated, annotation := range annotations {
+		notes[annotated] = annotation.Notes
+	}
+	return notes
+}
+
+func TestGatherWithCache(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":   "main.go  Entry point\n",
+		"main.go": "package main",
+	})
+	stamp := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, fs.Chtimes("/project/.info", stamp, stamp))
+
+	cache := infofile.NewParseCache()
+	infofile.UseCache(cache)
+	t.Cleanup(func() { infofile.UseCache(nil) })
+	assert.Equal(t, map[string]string{"/project/main.go": "Entry point"}, gatherNotes(t, fs, "/project"))
+
+	// Same size and modification time: the cached parse is reused
+	require.NoError(t, afero.WriteFile(fs, "/project/.info", []byte("main.go  Entry POINT\n"), 0644))
+	require.NoError(t, fs.Chtimes("/project/.info", stamp, stamp))
+	assert.Equal(t, map[string]string{"/project/main.go": "Entry point"}, gatherNotes(t, fs, "/project"))
+
+	// A new modification time invalidates it
+	require.NoError(t, fs.Chtimes("/project/.info", stamp.Add(time.Second), stamp.Add(time.Second)))
+	assert.Equal(t, map[string]string{"/project/main.go": "Entry POINT"}, gatherNotes(t, fs, "/project"))
+
+	// So does a new size
+	require.NoError(t, afero.WriteFile(fs, "/project/.info", []byte("main.go  The entry point\n"), 0644))
+	require.NoError(t, fs.Chtimes("/project/.info", stamp.Add(time.Second), stamp.Add(time.Second)))
+	assert.Equal(t, map[string]string{"/project/main.go": "The entry point"}, gatherNotes(t, fs, "/project"))
+
+	// Entries point at paths checked on every gather, not cached
+	require.NoError(t, fs.Remove("/project/main.go"))
+	assert.Empty(t, gatherNotes(t, fs, "/project"))
+}
+
+func TestGatherWithCacheOnDirectoryViews(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info": "a.go  Root note\n",
+		"a.go":  "package main",
+		"src":   map[string]interface{}{".info": "a.go  Subs note\n", "a.go": "package src"},
+	})
+	stamp := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, fs.Chtimes("/project/.info", stamp, stamp))
+	require.NoError(t, fs.Chtimes("/project/src/.info", stamp, stamp)) // Same size and time
+
+	infofile.UseCache(infofile.NewParseCache())
+	t.Cleanup(func() { infofile.UseCache(nil) })
+
+	// Servers root each request at its directory, so both files are "/.info" in their view
+	assert.Equal(t, "Root note", gatherNotes(t, afero.NewBasePathFs(fs, "/project"), "/")["/a.go"])
+	assert.Equal(t, "Subs note", gatherNotes(t, afero.NewBasePathFs(fs, "/project/src"), "/")["/a.go"])
+	assert.Equal(t, "Root note", gatherNotes(t, afero.NewBasePathFs(fs, "/project"), "/")["/a.go"])
+}
+
+func TestParseCacheSaveAndLoad(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":   "main.go  Entry point\n",
+		"main.go": "package main",
+		"docs":    map[string]interface{}{".info": "guide.md  Guide\n", "guide.md": "# Guide"},
+	})
+
+	cache := infofile.NewParseCache()
+	infofile.UseCache(cache)
+	t.Cleanup(func() { infofile.UseCache(nil) })
+	gatherNotes(t, fs, "/project")
+	require.NoError(t, cache.Save(fs, "/cache/info.json"))
+
+	// A loaded cache serves unchanged files without reading them
+	loaded := infofile.LoadParseCache(fs, "/cache/info.json")
+	infofile.UseCache(loaded)
+	info, err := fs.Stat("/project/docs/.info")
+	require.NoError(t, err)
+	modTime := info.ModTime()
+	require.NoError(t, afero.WriteFile(fs, "/project/docs/.info", []byte("guide.md  GUIDE\n"), 0644))
+	require.NoError(t, fs.Chtimes("/project/docs/.info", modTime, modTime))
+	assert.Equal(t, "Guide", gatherNotes(t, fs, "/project/docs")["/project/docs/guide.md"])
+
+	// Saving keeps only the files looked up since loading
+	require.NoError(t, loaded.Save(fs, "/cache/info.json"))
+	content, err := afero.ReadFile(fs, "/cache/info.json")
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "/project/docs/.info")
+	assert.NotContains(t, string(content), `"/project/.info"`)
+}
+
+func TestLoadParseCacheMissingOrInvalid(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/cache", map[string]interface{}{"broken.json": "{not json"})
+	fs.MustCreateTree("/project", map[string]interface{}{".info": "a  A\n", "a": ""})
+
+	for _, cachePath := range []string{"/cache/missing.json", "/cache/broken.json"} {
+		cache := infofile.LoadParseCache(fs, cachePath)
+		infofile.UseCache(cache)
+		assert.Equal(t, map[string]string{"/project/a": "A"}, gatherNotes(t, fs, "/project"), cachePath)
+	}
+	infofile.UseCache(nil)
+}
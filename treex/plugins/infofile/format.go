@@ -0,0 +1,195 @@
+package infofile
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/spf13/afero"
+	"treex/treex/pathutil"
+)
+
+// formatBlock is an entry and the comment lines directly above it, which move with it
+type formatBlock struct {
+	comments []string
+	entry    Entry
+	token    string // Path token as written by Format, with the colon of SyntaxColon entries
+	dir      bool
+}
+
+// Format returns content in the canonical layout of treex fmt:
+//   - within each group of lines separated by blank lines, entries are sorted directories
+//     first, then byte-wise by path, with "." leading; comment lines directly above an
+//     entry move with it, the ones closing a group stay at its end
+//   - annotations start in one column, two spaces past the longest path in the file;
+//     colon entries keep their colon
+//   - indentation and trailing whitespace are removed, paths are escaped the one way
+//     EscapePath does, runs of blank lines become one and the file neither starts nor
+//     ends with blank lines
+//
+// The byte order mark and line ending are kept, and the file ends with a line ending.
+// isDir reports whether an entry path, relative to the .info file's directory, names a
+// directory. A line longer than MaxLineSize is reported as Parse does.
+func Format(content []byte, isDir func(path string) bool) ([]byte, error) {
+	if _, err := Parse(content); err != nil {
+		return nil, err
+	}
+	file := splitLines(content)
+
+	var groups [][]string
+	var group []string
+	for _, line := range file.lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if len(group) > 0 {
+				groups = append(groups, group)
+				group = nil
+			}
+			continue
+		}
+		group = append(group, line)
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	if len(groups) == 0 {
+		return nil, nil
+	}
+
+	// Align to the widest token of the file, so entries added later line up too
+	column := 0
+	blocksByGroup := make([][]formatBlock, len(groups))
+	trailing := make([][]string, len(groups))
+	for i, lines := range groups {
+		var comments []string
+		for _, line := range lines {
+			entry, ok := ParseLine(line)
+			if !ok {
+				comments = append(comments, line)
+				continue
+			}
+			block := formatBlock{comments: comments, entry: entry, token: EscapePath(entry.Path), dir: isDir(entry.Path)}
+			if entry.Syntax == SyntaxColon {
+				block.token += ":"
+			}
+			if entry.Notes != "" {
+				column = max(column, ansi.StringWidth(block.token)+2)
+			}
+			blocksByGroup[i] = append(blocksByGroup[i], block)
+			comments = nil
+		}
+		trailing[i] = comments
+	}
+
+	var output []string
+	for i, blocks := range blocksByGroup {
+		if i > 0 {
+			output = append(output, "")
+		}
+		sort.SliceStable(blocks, func(a, b int) bool { return formatLess(blocks[a], blocks[b]) })
+		for _, block := range blocks {
+			output = append(output, block.comments...)
+			line := block.token
+			if block.entry.Notes != "" {
+				line += strings.Repeat(" ", column-ansi.StringWidth(block.token)) + block.entry.Notes
+			}
+			output = append(output, line)
+		}
+		output = append(output, trailing[i]...)
+	}
+
+	file.final = true
+	return file.join(output), nil
+}
+
+// formatLess orders entries for Format: "." first, then directories, then byte-wise by
+// path (ignoring a trailing slash)
+func formatLess(a, b formatBlock) bool {
+	aPath := strings.TrimSuffix(a.entry.Path, "/")
+	bPath := strings.TrimSuffix(b.entry.Path, "/")
+	if (aPath == ".") != (bPath == ".") {
+		return aPath == "."
+	}
+	if a.dir != b.dir {
+		return a.dir
+	}
+	return aPath < bPath
+}
+
+// FormatTree formats every .info file below root (see Format), judging directories
+// from fs, and returns the files that were not formatted, relative to root and sorted.
+// With write false the files are only checked, not rewritten.
+func FormatTree(fs afero.Fs, root string, write bool) ([]string, error) {
+	infoFiles, err := listInfoFiles(fs, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format .info files in %s: %w", root, err)
+	}
+
+	var changed []string
+	for _, infoFile := range infoFiles {
+		fullPath := filepath.Join(root, filepath.FromSlash(infoFile))
+		dir := filepath.Dir(fullPath)
+		isDir := func(path string) bool {
+			info, err := fs.Stat(filepath.Join(dir, filepath.FromSlash(path)))
+			return err == nil && info.IsDir()
+		}
+
+		update := func() error {
+			content, err := afero.ReadFile(fs, fullPath)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", infoFile, err)
+			}
+			formatted, err := Format(content, isDir)
+			if err != nil {
+				return fmt.Errorf("%s: %w", infoFile, err)
+			}
+			if bytes.Equal(content, formatted) {
+				return nil
+			}
+			changed = append(changed, infoFile)
+			if !write {
+				return nil
+			}
+			if err := writeAtomic(fs, fullPath, formatted); err != nil {
+				return fmt.Errorf("failed to write %s: %w", infoFile, err)
+			}
+			return nil
+		}
+		if write {
+			err = withLock(fs, fullPath, infoFile, update)
+		} else {
+			err = update()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return changed, nil
+}
+
+// listInfoFiles returns the .info files below root, relative to it, in walk order
+// Unreadable directories below root are skipped.
+func listInfoFiles(fs afero.Fs, root string) ([]string, error) {
+	var infoFiles []string
+	err := afero.Walk(fs, root, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			if filePath == root {
+				return err
+			}
+			return nil
+		}
+		if !info.IsDir() && info.Name() == ".info" {
+			relative, err := filepath.Rel(root, filePath)
+			if err != nil {
+				return err
+			}
+			infoFiles = append(infoFiles, pathutil.Normalize(relative))
+		}
+		return nil
+	})
+	return infoFiles, err
+}
@@ -0,0 +1,113 @@
+package infofile_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins/infofile"
+)
+
+func TestFormat(t *testing.T) {
+	dirs := map[string]bool{"src": true, "docs/": true, ".": true}
+	isDir := func(path string) bool { return dirs[path] }
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "sorts directories first and aligns",
+			content:  "main.go Entry point\nsrc  Sources\nREADME.md    Overview\n.  The project\n",
+			expected: ".          The project\nsrc        Sources\nREADME.md  Overview\nmain.go    Entry point\n",
+		},
+		{
+			name:     "comments move with their entry",
+			content:  "# Heading\n\n# The tool\nzeta.go  Last\n# treex:generated go\nalpha.go  First\n# end\n",
+			expected: "# Heading\n\n# treex:generated go\nalpha.go  First\n# The tool\nzeta.go   Last\n# end\n",
+		},
+		{
+			name:     "sorts within groups",
+			content:  "b.go  B\na.go  A\n\n\n\nd.go  D\nc.go  C\n",
+			expected: "a.go  A\nb.go  B\n\nc.go  C\nd.go  D\n",
+		},
+		{
+			name:     "normalizes spacing and escapes",
+			content:  "\n  docs/\tGuides  \nmy\\ file.txt    Notes\ncmd:   Commands\nbare\n\n",
+			expected: "docs/         Guides\nbare\ncmd:          Commands\nmy\\ file.txt  Notes\n",
+		},
+		{
+			name:     "keeps BOM and CRLF",
+			content:  "\ufeffb.go  B\r\na.go  A",
+			expected: "\ufeffa.go  A\r\nb.go  B\r\n",
+		},
+		{
+			name:     "formatted file is unchanged",
+			content:  "src      Sources\nmain.go  Entry point\n",
+			expected: "src      Sources\nmain.go  Entry point\n",
+		},
+		{
+			name:     "blank file",
+			content:  "\n  \n",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatted, err := infofile.Format([]byte(tt.content), isDir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(formatted))
+
+			again, err := infofile.Format(formatted, isDir)
+			require.NoError(t, err)
+			assert.Equal(t, string(formatted), string(again), "formatting is idempotent")
+		})
+	}
+}
+
+func TestFormatLineTooLong(t *testing.T) {
+	original := infofile.MaxLineSize
+	infofile.MaxLineSize = 16
+	t.Cleanup(func() { infofile.MaxLineSize = original })
+
+	_, err := infofile.Format([]byte("a.go  A\nb.go  "+strings.Repeat("x", 20)+"\n"), func(string) bool { return false })
+	var tooLong *infofile.LineTooLongError
+	require.True(t, errors.As(err, &tooLong))
+	assert.Equal(t, 2, tooLong.Line)
+}
+
+func TestFormatTree(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":   "main.go  Entry point\nsrc  Sources\n",
+		"main.go": "package main",
+		"src": map[string]interface{}{
+			".info":   "util.go  Helpers\n",
+			"util.go": "package main",
+		},
+	})
+
+	unformatted, err := infofile.FormatTree(fs, "/project", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{".info"}, unformatted)
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "main.go  Entry point\nsrc  Sources\n", string(content), "checking writes nothing")
+
+	formatted, err := infofile.FormatTree(fs, "/project", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{".info"}, formatted)
+	content, err = afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "src      Sources\nmain.go  Entry point\n", string(content))
+
+	formatted, err = infofile.FormatTree(fs, "/project", true)
+	require.NoError(t, err)
+	assert.Empty(t, formatted)
+}
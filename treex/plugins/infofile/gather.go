@@ -0,0 +1,244 @@
+package infofile
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// LocalFileName is a directory's personal overlay of its .info file, written like one and
+// meant to stay out of version control: its entries win over shared ones (see Gather)
+const LocalFileName = ".info.local"
+
+// Annotation is the winning annotation for a path after merging every .info file
+type Annotation struct {
+	Path       string // Annotated path: the .info file's directory joined with the entry path
+	Notes      string // Annotation text
+	InfoFile   string // File that holds the entry: a .info file, or a file it includes
+	Line       int    // Line of the entry in InfoFile
+	IncludedBy string // .info file whose @include brought the entry in ("" for its own entries)
+	Pattern    string // Glob entry that matched Path, as written ("" for exact entries)
+	Local      bool   // Entry comes from a LocalFileName overlay, or a file it includes
+}
+
+// Gather parses every .info file below root and merges their entries
+//
+// Entries without annotation text or pointing at missing paths are skipped. When several
+// entries annotate the same path, the one from the .info file closest to the path wins;
+// at equal distance the lexicographically first directory wins, and within one file the
+// first entry wins, the file's own entries before those it includes (see
+// IncludeDirective). Entries whose path is a glob ("*_test.go") annotate every path it
+// matches in the .info file's directory, but only where no exact entry, from any .info
+// file, annotates the path. Entries of LocalFileName overlays outrank all of these: they
+// win over every shared entry, and compare with each other by the same rules. Paths are keyed as root joined with the entry path, so a relative
+// root yields relative keys. Files are parsed through the cache set with UseCache, if any.
+func Gather(fs afero.Fs, root string) (map[string]Annotation, error) {
+	return gather(fs, root, true, nil)
+}
+
+// GatherShared merges the .info files below root as Gather does, leaving out the
+// LocalFileName overlays, for output that is shared or written back to .info files
+func GatherShared(fs afero.Fs, root string) (map[string]Annotation, error) {
+	return gather(fs, root, false, nil)
+}
+
+// Sidecar names files kept next to .info files whose content must be turned into .info
+// content before parsing, such as encrypted annotations
+type Sidecar struct {
+	Name string
+	// Read returns the .info content of the sidecar at filePath; nil content skips it
+	Read func(filePath string, content []byte) ([]byte, error)
+}
+
+// GatherSidecars merges the sidecar files below root, instead of the .info files, by
+// the rules of Gather. Sidecars are never cached, as their content may be secret.
+func GatherSidecars(fs afero.Fs, root string, sidecar Sidecar) (map[string]Annotation, error) {
+	return gather(fs, root, false, &sidecar)
+}
+
+// gather merges the annotations of the .info files below root, and of their overlays
+// when local is set, or of the sidecar files when sidecar is set
+func gather(fs afero.Fs, root string, local bool, sidecar *Sidecar) (map[string]Annotation, error) {
+	annotations := make(map[string]Annotation)
+	distances := make(map[string]int)
+	winnerDirs := make(map[string]string) // Directory of the .info file each winner belongs to
+
+	err := afero.Walk(fs, root, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			if filePath == root {
+				return err
+			}
+			slog.Debug("skipping unreadable directory while gathering annotations", "path", filePath, "error", err)
+			return nil // Unreadable subtrees do not stop the merge
+		}
+		overlay := sidecar == nil && info.Name() == LocalFileName
+		var entries []Entry
+		var includes []Include
+		switch {
+		case info.IsDir():
+			return nil
+		case sidecar != nil:
+			if info.Name() != sidecar.Name {
+				return nil
+			}
+			entries, includes, err = parseSidecar(fs, filePath, sidecar.Read)
+		case info.Name() == ".info" || (overlay && local):
+			entries, includes, err = parseFile(fs, filePath, info)
+		default:
+			return nil
+		}
+		var tooLong *LineTooLongError
+		if errors.As(err, &tooLong) {
+			slog.Warn("reading .info file up to an oversize line", "file", filePath, "error", err)
+		} else if err != nil {
+			slog.Warn("skipping unreadable .info file", "file", filePath, "error", err)
+			return nil
+		}
+
+		infoFile := filepath.ToSlash(filePath)
+		infoDir := path.Dir(infoFile)
+		sourced := make([]Annotation, 0, len(entries))
+		for _, entry := range entries {
+			sourced = append(sourced, Annotation{Path: entry.Path, Notes: entry.Notes, InfoFile: infoFile, Line: entry.Line, Local: overlay})
+		}
+		if len(includes) > 0 {
+			relative, err := filepath.Rel(root, filePath)
+			if err != nil {
+				return err
+			}
+			included, problems := resolveIncludes(fs, root, filepath.ToSlash(relative), includes)
+			for _, problem := range problems {
+				slog.Warn("skipping "+IncludeDirective, "file", infoFile, "line", problem.Line, "error", problem.err)
+			}
+			for _, entry := range included {
+				source := filepath.ToSlash(filepath.Join(root, filepath.FromSlash(entry.file)))
+				sourced = append(sourced, Annotation{Path: entry.Path, Notes: entry.Notes, InfoFile: source, Line: entry.Line, IncludedBy: infoFile, Local: overlay})
+			}
+		}
+
+		for _, entry := range sourced {
+			if entry.Notes == "" {
+				slog.Debug("skipping entry without annotation text", "file", entry.InfoFile, "line", entry.Line, "path", entry.Path)
+				continue
+			}
+			targets := []string{path.Join(infoDir, entry.Path)}
+			if exists, _ := afero.Exists(fs, filepath.FromSlash(targets[0])); !exists {
+				if !isPattern(entry.Path) {
+					slog.Debug("skipping entry for a missing path", "file", entry.InfoFile, "line", entry.Line, "path", entry.Path)
+					continue
+				}
+				matches, err := expandPattern(fs, filepath.FromSlash(infoDir), entry.Path)
+				if err != nil || len(matches) == 0 {
+					slog.Debug("skipping pattern matching no path", "file", entry.InfoFile, "line", entry.Line, "pattern", entry.Path, "error", err)
+					continue
+				}
+				entry.Pattern = entry.Path
+				targets = targets[:0]
+				for _, match := range matches {
+					targets = append(targets, path.Join(infoDir, match))
+				}
+			}
+
+			for _, target := range targets {
+				distance := pathDepth(target) - pathDepth(infoDir)
+				if previous, seen := distances[target]; seen {
+					// Overlays outrank shared entries and exact entries patterns, wherever they
+					// are; entries of the same rank compare by distance
+					winner := annotations[target]
+					if outranks(winner, entry) {
+						continue
+					}
+					if !outranks(entry, winner) && (distance > previous || (distance == previous && infoDir >= winnerDirs[target])) {
+						continue
+					}
+				}
+
+				distances[target] = distance
+				winnerDirs[target] = infoDir
+				entry.Path = target
+				annotations[target] = entry
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return annotations, nil
+}
+
+// parseSidecar returns the entries and @include directives of the sidecar at filePath,
+// read through read
+func parseSidecar(fs afero.Fs, filePath string, read func(string, []byte) ([]byte, error)) ([]Entry, []Include, error) {
+	content, err := afero.ReadFile(fs, filePath)
+	if err == nil {
+		content, err = read(filePath, content)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := Parse(content)
+	includes, _ := ParseIncludes(content)
+	if err != nil {
+		return entries, includes, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return entries, includes, nil
+}
+
+// Dropped returns the entries of the .info files below root that Gather skips, so their
+// annotation never shows in the tree: entries without text, for missing paths or paths
+// above root, and repeats of an earlier entry in the same file. Each entry is reported
+// once, with the first problem Validate finds on its line.
+func Dropped(fs afero.Fs, root string) ([]Issue, error) {
+	issues, err := Validate(fs, root)
+	if err != nil {
+		return nil, err
+	}
+
+	var dropped []Issue
+	reported := make(map[string]bool)
+	for _, issue := range issues {
+		switch issue.Type {
+		case IssueNoText, IssueMissingPath, IssueDuplicate:
+		case IssueOutsideDir:
+			// Entries above their .info file but inside root still annotate the tree
+			if issue.Path != ".." && !strings.HasPrefix(issue.Path, "../") {
+				continue
+			}
+		default:
+			continue
+		}
+		key := fmt.Sprintf("%s:%d", issue.InfoFile, issue.Line)
+		if !reported[key] {
+			reported[key] = true
+			dropped = append(dropped, issue)
+		}
+	}
+	return dropped, nil
+}
+
+// outranks reports whether a wins over b whatever their .info files' distance to the
+// path: an overlay entry over a shared one, then an exact entry over a pattern
+func outranks(a, b Annotation) bool {
+	if a.Local != b.Local {
+		return a.Local
+	}
+	return a.Pattern == "" && b.Pattern != ""
+}
+
+// pathDepth counts the components of a slash-separated path ("." and "/" have depth 0)
+func pathDepth(p string) int {
+	depth := 0
+	for _, part := range strings.Split(p, "/") {
+		if part != "" && part != "." {
+			depth++
+		}
+	}
+	return depth
+}
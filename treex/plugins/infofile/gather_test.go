@@ -0,0 +1,144 @@
+package infofile_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins/infofile"
+)
+
+func TestGatherPrecedence(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/home", map[string]interface{}{
+		".info":     "kids/mike.txt  From home\nnotes.txt: Home notes\nmissing.txt  Gone\nempty.txt\n",
+		"notes.txt": "notes",
+		"empty.txt": "",
+		"kids": map[string]interface{}{
+			".info":    "mike.txt  From kids\nmike.txt  Duplicate\n",
+			"mike.txt": "mike",
+		},
+	})
+
+	annotations, err := infofile.Gather(fs, "/home")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]infofile.Annotation{
+		"/home/kids/mike.txt": {Path: "/home/kids/mike.txt", Notes: "From kids", InfoFile: "/home/kids/.info", Line: 1},
+		"/home/notes.txt":     {Path: "/home/notes.txt", Notes: "Home notes", InfoFile: "/home/.info", Line: 2},
+	}, annotations)
+}
+
+func TestGatherRelativeRoot(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree(".", map[string]interface{}{
+		".info":  ".  The project\nsrc  Sources\n",
+		"src":    map[string]interface{}{"main.go": "package main"},
+		"README": "readme",
+	})
+
+	annotations, err := infofile.Gather(fs, ".")
+	require.NoError(t, err)
+
+	require.Contains(t, annotations, ".")
+	require.Contains(t, annotations, "src")
+	assert.Equal(t, "The project", annotations["."].Notes)
+	assert.Equal(t, "Sources", annotations["src"].Notes)
+}
+
+func TestGatherMissingRoot(t *testing.T) {
+	_, err := infofile.Gather(testutil.NewTestFS(), "/missing")
+	assert.Error(t, err)
+}
+
+func TestDropped(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/home", map[string]interface{}{
+		".info":     "notes.txt: Home notes\nmissing.txt  Gone\nempty.txt\nnotes.txt  Again\n../outside.txt  Above the root\n",
+		"notes.txt": "notes",
+		"empty.txt": "",
+		"kids": map[string]interface{}{
+			".info":    "../notes.txt  From kids\ngone.txt\n",
+			"mike.txt": "mike",
+		},
+	})
+
+	dropped, err := infofile.Dropped(fs, "/home")
+	require.NoError(t, err)
+
+	assert.Equal(t, []infofile.Issue{
+		{InfoFile: ".info", Line: 2, Path: "missing.txt", Message: "annotated path does not exist", Type: infofile.IssueMissingPath},
+		{InfoFile: ".info", Line: 3, Path: "empty.txt", Message: "annotation has no text", Type: infofile.IssueNoText},
+		{InfoFile: ".info", Line: 4, Path: "notes.txt", Message: "path already annotated on line 1", Type: infofile.IssueDuplicate},
+		{InfoFile: ".info", Line: 5, Path: "../outside.txt", Message: "annotated path is outside the .info file's directory", Type: infofile.IssueOutsideDir},
+		{InfoFile: "kids/.info", Line: 2, Path: "kids/gone.txt", Message: "annotation has no text", Type: infofile.IssueNoText},
+	}, dropped, "kids/.info's ../notes.txt stays inside the root and is not dropped")
+}
+
+func TestGatherLocalOverlay(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":       "src/main.go  Entry point\nREADME.md  Overview\n",
+		".info.local": "src/main.go  TODO: split the flag parsing out\nMakefile  Run make dev first\n",
+		"README.md":   "# project",
+		"Makefile":    "all:",
+		"src": map[string]interface{}{
+			".info":   "main.go  Starts the server\n*.go  Sources\n",
+			"main.go": "package main",
+			"util.go": "package main",
+		},
+	})
+
+	annotations, err := infofile.Gather(fs, "/project")
+	require.NoError(t, err)
+	assert.Equal(t, infofile.Annotation{
+		Path: "/project/src/main.go", Notes: "TODO: split the flag parsing out", InfoFile: "/project/.info.local", Line: 1, Local: true,
+	}, annotations["/project/src/main.go"], "overlay entries win over closer shared ones")
+	assert.Equal(t, "Run make dev first", annotations["/project/Makefile"].Notes, "overlays supplement shared files")
+	assert.Equal(t, "Overview", annotations["/project/README.md"].Notes)
+	assert.Equal(t, "Sources", annotations["/project/src/util.go"].Notes)
+
+	shared, err := infofile.GatherShared(fs, "/project")
+	require.NoError(t, err)
+	assert.Equal(t, "Starts the server", shared["/project/src/main.go"].Notes)
+	assert.NotContains(t, shared, "/project/Makefile")
+}
+
+func TestGatherSidecars(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":    "docs/guide.md  Shared\n",
+		".info.x":  "docs/guide.md  From the root sidecar\n",
+		"notes.md": "",
+		"docs": map[string]interface{}{
+			".info.x":  "*.md  Any guide\nguide.md  Closest\n",
+			"guide.md": "",
+			"faq.md":   "",
+		},
+		"skipped": map[string]interface{}{".info.x": "broken\n"},
+	})
+
+	var read []string
+	annotations, err := infofile.GatherSidecars(fs, "/project", infofile.Sidecar{
+		Name: ".info.x",
+		Read: func(filePath string, content []byte) ([]byte, error) {
+			read = append(read, filePath)
+			if filePath == "/project/skipped/.info.x" {
+				return nil, nil
+			}
+			return content, nil
+		},
+	})
+	require.NoError(t, err)
+
+	notes := make(map[string]string)
+	for p, annotation := range annotations {
+		notes[p] = annotation.Notes
+	}
+	assert.Equal(t, map[string]string{
+		"/project/docs/guide.md": "Closest",
+		"/project/docs/faq.md":   "Any guide",
+	}, notes, ".info files are left out and sidecars merge like them")
+	assert.Len(t, read, 3)
+}
@@ -0,0 +1,46 @@
+package infofile
+
+import (
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// GeneratedMarker starts the comment line written above generated entries, followed by
+// the entry's source (e.g. "# treex:generated README.md"). Tools refresh marked entries
+// and leave every other entry alone.
+const GeneratedMarker = "# treex:generated"
+
+// Generated is an annotation derived from another source, such as a README or doc comment
+type Generated struct {
+	Path   string // Annotated path relative to root
+	Notes  string // Annotation text
+	Source string // Where the text came from, recorded in the marker comment
+}
+
+// WriteGenerated writes generated annotations into the .info file of each target's parent
+// directory, each below a marker comment. Entries already marked are refreshed; entries
+// without a marker were written by hand and are kept. Returns the .info paths written
+// (sorted) and the paths whose hand-written annotations were kept, relative to root.
+func WriteGenerated(fs afero.Fs, root string, annotations []Generated) ([]string, []string, error) {
+	entries := make([]pendingEntry, len(annotations))
+	for i, annotation := range annotations {
+		source := strings.Join(strings.Fields(annotation.Source), " ")
+		if source == "" {
+			source = "unknown"
+		}
+		entries[i] = pendingEntry{path: annotation.Path, notes: annotation.Notes, source: source}
+	}
+	return writeEntries(fs, root, entries)
+}
+
+// generatedMarker renders the marker comment for source
+func generatedMarker(source string) string {
+	return GeneratedMarker + " " + source
+}
+
+// isGeneratedMarker reports whether line is a marker comment
+func isGeneratedMarker(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == GeneratedMarker || strings.HasPrefix(line, GeneratedMarker+" ")
+}
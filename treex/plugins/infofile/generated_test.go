@@ -0,0 +1,69 @@
+package infofile_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins/infofile"
+)
+
+func TestWriteGenerated(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":    "docs  Hand-written docs note\n",
+		"docs":     map[string]interface{}{"guide.md": "# guide"},
+		"internal": map[string]interface{}{},
+		"tools":    map[string]interface{}{"lint.py": ""},
+	})
+
+	generated := []infofile.Generated{
+		{Path: "docs", Notes: "User guides", Source: "readme"},
+		{Path: "internal", Notes: "Shared helpers", Source: "go-doc"},
+		{Path: "tools/lint.py", Notes: "Run the linters", Source: "docstring"},
+	}
+	written, kept, err := infofile.WriteGenerated(fs, "/project", generated)
+	require.NoError(t, err)
+	assert.Equal(t, []string{".info", "tools/.info"}, written)
+	assert.Equal(t, []string{"docs"}, kept)
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "docs  Hand-written docs note\n# treex:generated go-doc\ninternal  Shared helpers\n", string(content))
+
+	t.Run("refreshes marked entries", func(t *testing.T) {
+		written, _, err := infofile.WriteGenerated(fs, "/project", []infofile.Generated{
+			{Path: "internal", Notes: "Shared helpers, updated", Source: "go-doc"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{".info"}, written)
+
+		content, err := afero.ReadFile(fs, "/project/.info")
+		require.NoError(t, err)
+		assert.Equal(t, "docs  Hand-written docs note\n# treex:generated go-doc\ninternal  Shared helpers, updated\n", string(content))
+	})
+
+	t.Run("hand edits drop the marker", func(t *testing.T) {
+		_, err := infofile.AddAnnotation(fs, "/project", "internal", "Curated description")
+		require.NoError(t, err)
+
+		content, err := afero.ReadFile(fs, "/project/.info")
+		require.NoError(t, err)
+		assert.Equal(t, "docs  Hand-written docs note\ninternal  Curated description\n", string(content))
+
+		written, kept, err := infofile.WriteGenerated(fs, "/project", []infofile.Generated{
+			{Path: "internal", Notes: "Shared helpers", Source: "go-doc"},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, written)
+		assert.Equal(t, []string{"internal"}, kept)
+	})
+
+	t.Run("annotations still parse", func(t *testing.T) {
+		annotations, err := infofile.Gather(fs, "/project")
+		require.NoError(t, err)
+		assert.Equal(t, "Run the linters", annotations["/project/tools/lint.py"].Notes)
+	})
+}
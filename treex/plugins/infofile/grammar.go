@@ -0,0 +1,110 @@
+package infofile
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Grammar is a TextMate grammar, the highlighting format read by VS Code, Sublime Text,
+// Atom-derived editors and GitHub's linguist
+type Grammar struct {
+	Schema    string        `json:"$schema,omitempty"`
+	Name      string        `json:"name"`
+	ScopeName string        `json:"scopeName"`
+	FileTypes []string      `json:"fileTypes"`
+	Patterns  []GrammarRule `json:"patterns"`
+}
+
+// GrammarRule is a single-line TextMate match rule
+// The first rule matching a line wins, so rules run from most to least specific.
+type GrammarRule struct {
+	Comment  string                    `json:"comment,omitempty"`
+	Name     string                    `json:"name,omitempty"`
+	Match    string                    `json:"match"`
+	Captures map[string]GrammarCapture `json:"captures,omitempty"`
+}
+
+// GrammarCapture scopes a capture group of a rule, optionally matching rules inside it
+type GrammarCapture struct {
+	Name     string        `json:"name,omitempty"`
+	Patterns []GrammarRule `json:"patterns,omitempty"`
+}
+
+// Regular expressions shared by the grammar rules; they use the syntax common to
+// Oniguruma (TextMate) and RE2, so the grammar is tested against ParseLine
+const (
+	// pathUnit is one character of a path token: an escape sequence or anything but
+	// whitespace, so a token ends at the first unescaped space or tab
+	pathUnit = `(?:\\.|[^\s\\])`
+	// escapeSequence is a backslash escape inside a path
+	escapeSequence = `\\.`
+)
+
+// TextMateGrammar describes the .info syntax as ParseLine reads it: comments, the
+// treex directive comments, and entries in the space and colon syntaxes with their
+// backslash escapes. Scopes end in ".treex-info".
+func TextMateGrammar() Grammar {
+	scope := func(name string) string { return name + ".treex-info" }
+	path := GrammarCapture{
+		Name:     scope("entity.name.filename"),
+		Patterns: []GrammarRule{{Name: scope("constant.character.escape"), Match: escapeSequence}},
+	}
+	annotation := GrammarCapture{Name: scope("string.unquoted.annotation")}
+	marker := strings.TrimSpace(strings.TrimPrefix(GeneratedMarker, "#"))
+
+	return Grammar{
+		Schema:    "https://raw.githubusercontent.com/martinring/tmlanguage/master/tmlanguage.json",
+		Name:      "treex info",
+		ScopeName: "source.treex-info",
+		FileTypes: []string{"info"},
+		Patterns: []GrammarRule{
+			{
+				Comment: "Depth directive: # treex:max-depth=N",
+				Name:    scope("comment.line.number-sign"),
+				Match:   `^\s*(#)\s*(` + regexp.QuoteMeta(MaxDepthDirective) + `)\s*(\d+)\s*$`,
+				Captures: map[string]GrammarCapture{
+					"1": {Name: scope("punctuation.definition.comment")},
+					"2": {Name: scope("keyword.other.directive")},
+					"3": {Name: scope("constant.numeric.integer")},
+				},
+			},
+			{
+				Comment: "Marker above generated entries: # treex:generated <source>",
+				Name:    scope("comment.line.number-sign"),
+				Match:   `^\s*(#) (` + regexp.QuoteMeta(marker) + `)(?:\s+(.*?))?\s*$`,
+				Captures: map[string]GrammarCapture{
+					"1": {Name: scope("punctuation.definition.comment")},
+					"2": {Name: scope("keyword.other.directive")},
+					"3": {Name: scope("string.unquoted.source")},
+				},
+			},
+			{
+				Comment: "Comment",
+				Name:    scope("comment.line.number-sign"),
+				Match:   `^\s*(#).*$`,
+				Captures: map[string]GrammarCapture{
+					"1": {Name: scope("punctuation.definition.comment")},
+				},
+			},
+			{
+				Comment: `Colon syntax: <path>: <annotation> (a path ending in "\:" keeps its colon)`,
+				Name:    scope("meta.entry.colon"),
+				Match:   `^\s*(` + pathUnit + `+?)(:)(?:\s+(.*?))?\s*$`,
+				Captures: map[string]GrammarCapture{
+					"1": path,
+					"2": {Name: scope("punctuation.separator.key-value")},
+					"3": annotation,
+				},
+			},
+			{
+				Comment: "Space syntax: <path> <annotation>, split at the first unescaped space or tab",
+				Name:    scope("meta.entry.space"),
+				Match:   `^\s*(` + pathUnit + `+)(?:\s+(.*?))?\s*$`,
+				Captures: map[string]GrammarCapture{
+					"1": path,
+					"2": annotation,
+				},
+			},
+		},
+	}
+}
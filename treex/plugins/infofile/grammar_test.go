@@ -0,0 +1,82 @@
+package infofile_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex/plugins/infofile"
+)
+
+// highlight returns the scope of the first grammar rule matching line and its captures
+func highlight(t *testing.T, grammar infofile.Grammar, line string) (string, []string) {
+	t.Helper()
+	for _, rule := range grammar.Patterns {
+		if groups := regexp.MustCompile(rule.Match).FindStringSubmatch(line); groups != nil {
+			return rule.Name, groups
+		}
+	}
+	return "", nil
+}
+
+func TestTextMateGrammarAgreesWithParseLine(t *testing.T) {
+	grammar := infofile.TextMateGrammar()
+	for _, line := range []string{
+		"main.go  Entry point",
+		"main.go\tEntry point",
+		"  src/main.go: Entry point  ",
+		`my\ file.txt Notes`,
+		`odd\:  Path ending in a colon`,
+		"a:b: Colon inside the path",
+		"a:b  Colon inside, space syntax",
+		"lonely",
+		"lonely:",
+		": A lone colon is a path",
+		". The directory itself",
+	} {
+		entry, ok := infofile.ParseLine(line)
+		require.True(t, ok, line)
+
+		name, groups := highlight(t, grammar, line)
+		require.NotNil(t, groups, line)
+		assert.Equal(t, "meta.entry."+string(entry.Syntax)+".treex-info", name, line)
+
+		notes := groups[len(groups)-1]
+		assert.Equal(t, entry.Notes, notes, line)
+		escaped := regexp.MustCompile(`\\(.)`).ReplaceAllString(groups[1], "$1")
+		assert.Equal(t, entry.Path, escaped, line)
+	}
+}
+
+func TestTextMateGrammarComments(t *testing.T) {
+	grammar := infofile.TextMateGrammar()
+	for line, captures := range map[string][]string{
+		"# treex:max-depth=2":             {"#", "treex:max-depth=", "2"},
+		"  #treex:max-depth= 0":           {"#", "treex:max-depth=", "0"},
+		"# treex:generated README.md":     {"#", "treex:generated", "README.md"},
+		"# treex:generated":               {"#", "treex:generated", ""},
+		"# plain comment":                 {"#"},
+		"# treex:max-depth=deep":          {"#"},
+		"#treex:generated no space first": {"#"},
+	} {
+		_, ok := infofile.ParseLine(line)
+		require.False(t, ok, line)
+
+		name, groups := highlight(t, grammar, line)
+		assert.Equal(t, "comment.line.number-sign.treex-info", name, line)
+		assert.Equal(t, captures, groups[1:], line)
+	}
+}
+
+func TestTextMateGrammarIsJSON(t *testing.T) {
+	content, err := json.Marshal(infofile.TextMateGrammar())
+	require.NoError(t, err)
+
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal(content, &document))
+	assert.Equal(t, "source.treex-info", document["scopeName"])
+	assert.True(t, strings.Contains(string(content), `"captures":{"1":`))
+}
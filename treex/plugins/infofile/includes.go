@@ -0,0 +1,98 @@
+package infofile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// includedEntry is an entry brought into a .info file by an @include directive
+type includedEntry struct {
+	Entry
+	file string // File holding the entry, relative to the root
+}
+
+// includeProblem is an @include directive that could not be followed
+type includeProblem struct {
+	Include
+	err error
+}
+
+// includeCycleError reports an @include chain leading back to a file it already includes
+type includeCycleError struct {
+	chain []string // Files from the including .info file to the repeated one
+}
+
+func (e *includeCycleError) Error() string {
+	return "include cycle: " + strings.Join(e.chain, " -> ")
+}
+
+// resolveIncludes returns the entries the directives of the .info file infoFile
+// (relative to root) bring in, in directive order. Included files may include others,
+// followed depth first and resolved from the file naming them; their entries annotate
+// paths relative to infoFile's directory all the same. A directive that cannot be
+// followed, including through the files it includes, is reported as a problem and
+// brings in nothing.
+func resolveIncludes(fs afero.Fs, root, infoFile string, includes []Include) ([]includedEntry, []includeProblem) {
+	var entries []includedEntry
+	var problems []includeProblem
+	for _, include := range includes {
+		var found []includedEntry
+		if err := followInclude(fs, root, infoFile, include.Path, []string{infoFile}, &found); err != nil {
+			problems = append(problems, includeProblem{Include: include, err: err})
+			continue
+		}
+		entries = append(entries, found...)
+	}
+	return entries, problems
+}
+
+// followInclude appends the entries of the file includePath names from the file from,
+// then those of the files it includes; chain holds the files being included
+func followInclude(fs afero.Fs, root, from, includePath string, chain []string, entries *[]includedEntry) error {
+	if includePath == "" {
+		return errors.New(IncludeDirective + " names no file")
+	}
+	file := resolveReference(path.Dir(from), includePath)
+	for _, including := range chain {
+		if including == file {
+			return &includeCycleError{chain: append(chain, file)}
+		}
+	}
+
+	fullPath := filepath.Join(root, filepath.FromSlash(file))
+	info, err := fs.Stat(fullPath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("included file %s does not exist", file)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot include %s: %w", file, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("cannot include %s: it is a directory", file)
+	}
+	fileEntries, includes, err := parseFile(fs, fullPath, info)
+	var tooLong *LineTooLongError
+	if errors.As(err, &tooLong) {
+		err = tooLong // parseFile names the file by its full path
+	}
+	if err != nil {
+		return fmt.Errorf("cannot include %s: %w", file, err)
+	}
+
+	for _, entry := range fileEntries {
+		*entries = append(*entries, includedEntry{Entry: entry, file: file})
+	}
+	chain = append(chain[:len(chain):len(chain)], file)
+	for _, include := range includes {
+		if err := followInclude(fs, root, file, include.Path, chain, entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package infofile_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins/infofile"
+)
+
+func TestGatherIncludes(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"shared": map[string]interface{}{
+			"service.info": "@include vendor.info\nMakefile  Build targets\nvendor  Service vendor tree\n",
+			"vendor.info":  "vendor  Third-party code, do not edit\nREADME.md  Service overview\n",
+		},
+		"services": map[string]interface{}{
+			"api": map[string]interface{}{
+				".info":     "@include /shared/service.info\nMakefile  API build and deploy\n",
+				"Makefile":  "all:",
+				"README.md": "# api",
+				"vendor":    map[string]interface{}{},
+			},
+			"web": map[string]interface{}{
+				".info":    "@include ../../shared/vendor.info\n",
+				"vendor":   map[string]interface{}{},
+				"index.js": "",
+			},
+		},
+	})
+
+	annotations, err := infofile.Gather(fs, "/project")
+	require.NoError(t, err)
+
+	assert.Equal(t, infofile.Annotation{
+		Path: "/project/services/api/Makefile", Notes: "API build and deploy", InfoFile: "/project/services/api/.info", Line: 2,
+	}, annotations["/project/services/api/Makefile"], "own entries win over included ones")
+	assert.Equal(t, infofile.Annotation{
+		Path: "/project/services/api/vendor", Notes: "Service vendor tree", InfoFile: "/project/shared/service.info", Line: 3,
+		IncludedBy: "/project/services/api/.info",
+	}, annotations["/project/services/api/vendor"], "the including file's entries come before the files it includes")
+	assert.Equal(t, "Service overview", annotations["/project/services/api/README.md"].Notes, "nested includes resolve from the file naming them")
+	assert.Equal(t, "Third-party code, do not edit", annotations["/project/services/web/vendor"].Notes)
+	assert.NotContains(t, annotations, "/project/services/web/README.md", "missing paths are skipped")
+	assert.NotContains(t, annotations, "/project/shared/vendor", "included files are not .info files of their own")
+}
+
+func TestValidateIncludes(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":  "@include a.info\n@include missing.info\n@include\nlib  Library\n",
+		"a.info": "@include b.info\nREADME.md  Overview\n",
+		"b.info": "@include a.info\n",
+		"lib":    map[string]interface{}{},
+	})
+
+	issues, err := infofile.Validate(fs, "/project")
+	require.NoError(t, err)
+	assert.Equal(t, []infofile.Issue{
+		{InfoFile: ".info", Line: 1, Path: "a.info", Message: "include cycle: .info -> a.info -> b.info -> a.info", Type: infofile.IssueIncludeCycle},
+		{InfoFile: ".info", Line: 2, Path: "missing.info", Message: "included file missing.info does not exist", Type: infofile.IssueBrokenInclude},
+		{InfoFile: ".info", Line: 3, Path: ".", Message: "@include names no file", Type: infofile.IssueBrokenInclude},
+	}, issues)
+	for _, issue := range issues {
+		assert.False(t, issue.Removable(), issue.Message)
+	}
+
+	// A cycle brings in nothing, not even the entries read before it was found
+	annotations, err := infofile.Gather(fs, "/project")
+	require.NoError(t, err)
+	assert.Len(t, annotations, 1)
+	assert.Contains(t, annotations, "/project/lib")
+}
@@ -7,19 +7,27 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/arthur-debert/infofile/infofile"
 	"github.com/spf13/afero"
+	"treex/treex/pathutil"
 	"treex/treex/plugins"
 	"treex/treex/types"
 )
 
 // InfoPlugin categorizes files based on whether they have annotations in .info files
 // It finds directories containing .info files and categorizes files as annotated or non-annotated
-type InfoPlugin struct{}
+type InfoPlugin struct {
+	normalizer *pathutil.Normalizer // Matches annotation paths against node paths
+}
 
 // NewInfoPlugin creates a new info plugin instance
+// Path matching follows the platform defaults (case-insensitive on Windows and macOS)
 func NewInfoPlugin() *InfoPlugin {
-	return &InfoPlugin{}
+	return NewInfoPluginWithNormalizer(pathutil.Default())
+}
+
+// NewInfoPluginWithNormalizer creates an info plugin with explicit path matching rules
+func NewInfoPluginWithNormalizer(normalizer *pathutil.Normalizer) *InfoPlugin {
+	return &InfoPlugin{normalizer: normalizer}
 }
 
 // Name returns the plugin identifier
@@ -76,7 +84,7 @@ func (p *InfoPlugin) FindRoots(fs afero.Fs, searchRoot string) ([]string, error)
 }
 
 // ProcessRoot analyzes .info files in a directory root and categorizes files
-// Uses the info collector to parse annotations and determine which files are annotated
+// Uses Gather to parse annotations and determine which files are annotated
 func (p *InfoPlugin) ProcessRoot(fs afero.Fs, rootPath string) (*plugins.Result, error) {
 	result := &plugins.Result{
 		PluginName: p.Name(),
@@ -89,17 +97,18 @@ func (p *InfoPlugin) ProcessRoot(fs afero.Fs, rootPath string) (*plugins.Result,
 	// Initialize categories
 	result.Categories["annotated"] = make([]string, 0)
 
-	// Create an InfoAPI to parse .info files in this root
-	api := infofile.NewInfoAPI(fs)
-	annotations, err := api.Gather(rootPath)
+	// Parse and merge the .info files in this root
+	annotations, err := Gather(fs, rootPath)
 	if err != nil {
 		// If we can't collect annotations, return empty result (not an error)
 		// This handles cases where .info files exist but are unreadable/invalid
 		return result, nil
 	}
 
-	// Store the raw annotations in cache for efficient data enrichment
+	// Store the raw annotations in cache for efficient data enrichment, with the index
+	// every node of the walk is looked up in (see EnrichNodeWithCache)
 	result.Cache["annotations"] = annotations
+	result.Cache["annotation_index"] = p.indexAnnotations(rootPath, annotations)
 
 	// Add only annotated files to the result
 	for annotationPath := range annotations {
@@ -116,7 +125,7 @@ func (p *InfoPlugin) ProcessRoot(fs afero.Fs, rootPath string) (*plugins.Result,
 		}
 
 		// Normalize path separators
-		relativePath = filepath.ToSlash(relativePath)
+		relativePath = p.normalizer.Normalize(relativePath)
 		result.Categories["annotated"] = append(result.Categories["annotated"], relativePath)
 	}
 
@@ -128,14 +137,13 @@ func (p *InfoPlugin) ProcessRoot(fs afero.Fs, rootPath string) (*plugins.Result,
 func (p *InfoPlugin) GetAnnotationDetails(fs afero.Fs, rootPath string) (map[string]interface{}, error) {
 	details := make(map[string]interface{})
 
-	api := infofile.NewInfoAPI(fs)
-	annotations, err := api.Gather(rootPath)
+	annotations, err := Gather(fs, rootPath)
 	if err != nil {
 		return details, err
 	}
 
 	// Group annotations by .info file (only winning annotations are included)
-	byInfoFile := make(map[string][]infofile.Annotation)
+	byInfoFile := make(map[string][]Annotation)
 	for _, annotation := range annotations {
 		infoFile := annotation.InfoFile
 		byInfoFile[infoFile] = append(byInfoFile[infoFile], annotation)
@@ -182,16 +190,13 @@ func (p *InfoPlugin) EnrichNode(fs afero.Fs, node *types.Node) error {
 		nodeDir = node.Path
 	}
 
-	// Use the InfoAPI to find annotation for this specific path
-	api := infofile.NewInfoAPI(fs)
-
 	// Try to find annotation starting from the node's directory
 	searchPath := "."
 	if nodeDir != "." && nodeDir != "" {
 		searchPath = nodeDir
 	}
 
-	annotations, err := api.Gather(searchPath)
+	annotations, err := Gather(fs, searchPath)
 	if err != nil {
 		// If we can't gather annotations, skip enrichment (not an error)
 		return nil
@@ -199,17 +204,9 @@ func (p *InfoPlugin) EnrichNode(fs afero.Fs, node *types.Node) error {
 
 	// Look for annotation for this specific file
 	for filePath, annotation := range annotations {
-		// Normalize paths for comparison
-		normalizedFilePath := filepath.ToSlash(filePath)
-		normalizedNodePath := filepath.ToSlash(node.Path)
-
-		if normalizedFilePath == normalizedNodePath {
+		if p.normalizer.Equal(filePath, node.Path) {
 			// Found annotation for this node - convert to types.Annotation and store
-			nodeAnnotation := &types.Annotation{
-				Path:  annotation.Path,
-				Notes: annotation.Annotation,
-			}
-			node.SetPluginData("info", nodeAnnotation)
+			node.SetPluginData("info", toNodeAnnotation(annotation))
 			break
 		}
 	}
@@ -222,67 +219,22 @@ func (p *InfoPlugin) EnrichNode(fs afero.Fs, node *types.Node) error {
 func (p *InfoPlugin) EnrichData(fs afero.Fs, rootPath string, filePaths []string, cache plugins.CacheMap) (plugins.DataEnrichmentMap, error) {
 	enrichmentMap := make(plugins.DataEnrichmentMap)
 
-	// Check if we have cached annotations from the filtering phase
-	if cachedAnnotations, exists := cache["annotations"]; exists {
-		if annotations, ok := cachedAnnotations.(map[string]infofile.Annotation); ok {
-			// Use cached annotations for efficient enrichment
-			for _, filePath := range filePaths {
-				// Look for annotation for this specific file
-				for annotationPath, annotation := range annotations {
-					// Handle both absolute and relative paths in cache
-					var normalizedAnnotationPath string
-					if filepath.IsAbs(annotationPath) {
-						// Try to make absolute path relative to match filePath
-						if rel, err := filepath.Rel(rootPath, annotationPath); err == nil && !strings.HasPrefix(rel, "..") {
-							normalizedAnnotationPath = filepath.ToSlash(rel)
-						} else {
-							// If we can't make it relative, use basename for comparison
-							normalizedAnnotationPath = filepath.ToSlash(filepath.Base(annotationPath))
-						}
-					} else {
-						normalizedAnnotationPath = filepath.ToSlash(annotationPath)
-					}
-
-					normalizedFilePath := filepath.ToSlash(filePath)
-
-					if normalizedAnnotationPath == normalizedFilePath {
-						// Found annotation for this file - convert to types.Annotation
-						nodeAnnotation := &types.Annotation{
-							Path:  annotation.Path,
-							Notes: annotation.Annotation,
-						}
-						enrichmentMap[filePath] = nodeAnnotation
-						break
-					}
-				}
-			}
-		}
-	} else {
-		// No cached data available, gather annotations fresh
-		api := infofile.NewInfoAPI(fs)
-		annotations, err := api.Gather(rootPath)
+	// Use cached annotations from the filtering phase when available, gather fresh otherwise
+	annotations, cached := cachedAnnotations(cache)
+	if !cached {
+		var err error
+		annotations, err = Gather(fs, rootPath)
 		if err != nil {
 			// If we can't gather annotations, return empty map (not an error)
 			return enrichmentMap, nil
 		}
+	}
 
-		// Look for annotations for the requested file paths
-		for _, filePath := range filePaths {
-			for annotationPath, annotation := range annotations {
-				// Normalize paths for comparison
-				normalizedAnnotationPath := filepath.ToSlash(annotationPath)
-				normalizedFilePath := filepath.ToSlash(filePath)
-
-				if normalizedAnnotationPath == normalizedFilePath {
-					// Found annotation for this file - convert to types.Annotation
-					nodeAnnotation := &types.Annotation{
-						Path:  annotation.Path,
-						Notes: annotation.Annotation,
-					}
-					enrichmentMap[filePath] = nodeAnnotation
-					break
-				}
-			}
+	// Index annotations by normalized key once, then look up each requested path
+	index := p.indexAnnotations(rootPath, annotations)
+	for _, filePath := range filePaths {
+		if annotation, exists := index[p.normalizer.Key(filePath)]; exists {
+			enrichmentMap[filePath] = toNodeAnnotation(annotation)
 		}
 	}
 
@@ -298,51 +250,65 @@ func (p *InfoPlugin) EnrichNodeWithCache(fs afero.Fs, node *types.Node, pluginRe
 			continue
 		}
 
-		// Check if we have cached annotations for this result
-		cachedAnnotations, exists := result.Cache["annotations"]
-		if !exists {
-			continue
-		}
-
-		// Type assert to get the annotations map
-		annotations, ok := cachedAnnotations.(map[string]infofile.Annotation)
+		// ProcessRoot indexes the annotations once per walk; results without the index
+		// (built elsewhere) are indexed here
+		index, ok := result.Cache["annotation_index"].(map[string]Annotation)
 		if !ok {
-			continue
+			annotations, ok := result.Cache["annotations"].(map[string]Annotation)
+			if !ok {
+				continue
+			}
+			index = p.indexAnnotations(result.RootPath, annotations)
 		}
 
 		// Look for annotation for this specific file
-		for filePath, annotation := range annotations {
-			// Handle both absolute and relative paths in cache
-			// Make filePath relative to match node.Path which is always relative
-			var normalizedFilePath string
-			if filepath.IsAbs(filePath) {
-				// Try to make absolute path relative to result root
-				if rel, err := filepath.Rel(result.RootPath, filePath); err == nil && !strings.HasPrefix(rel, "..") {
-					normalizedFilePath = filepath.ToSlash(rel)
-				} else {
-					// If we can't make it relative, use basename for comparison
-					normalizedFilePath = filepath.ToSlash(filepath.Base(filePath))
-				}
-			} else {
-				normalizedFilePath = filepath.ToSlash(filePath)
-			}
+		if annotation, exists := index[p.normalizer.Key(node.Path)]; exists {
+			// Found annotation for this node - convert to types.Annotation and store
+			node.SetPluginData("info", toNodeAnnotation(annotation))
+			return nil
+		}
+	}
 
-			normalizedNodePath := filepath.ToSlash(node.Path)
+	// No cached annotation found - this is normal for non-annotated files
+	return nil
+}
+
+// cachedAnnotations extracts the annotations gathered during the filtering phase
+// Reports whether a cache entry was present; a malformed entry yields no annotations
+func cachedAnnotations(cache plugins.CacheMap) (map[string]Annotation, bool) {
+	cachedValue, exists := cache["annotations"]
+	if !exists {
+		return nil, false
+	}
+	annotations, _ := cachedValue.(map[string]Annotation)
+	return annotations, true
+}
 
-			if normalizedFilePath == normalizedNodePath {
-				// Found annotation for this node - convert to types.Annotation and store
-				nodeAnnotation := &types.Annotation{
-					Path:  annotation.Path,
-					Notes: annotation.Annotation,
-				}
-				node.SetPluginData("info", nodeAnnotation)
-				return nil
+// indexAnnotations maps normalized annotation keys to annotations
+// Absolute annotation paths are made relative to rootPath so they compare with node paths
+func (p *InfoPlugin) indexAnnotations(rootPath string, annotations map[string]Annotation) map[string]Annotation {
+	index := make(map[string]Annotation, len(annotations))
+	for annotationPath, annotation := range annotations {
+		relativePath := annotationPath
+		if filepath.IsAbs(annotationPath) || rootPath != "." {
+			if rel, err := filepath.Rel(rootPath, annotationPath); err == nil && !strings.HasPrefix(rel, "..") {
+				relativePath = rel
+			} else {
+				// If we can't make it relative, use basename for comparison
+				relativePath = filepath.Base(annotationPath)
 			}
 		}
+		index[p.normalizer.Key(relativePath)] = annotation
 	}
+	return index
+}
 
-	// No cached annotation found - this is normal for non-annotated files
-	return nil
+// toNodeAnnotation converts a gathered annotation to the node annotation type
+func toNodeAnnotation(annotation Annotation) *types.Annotation {
+	return &types.Annotation{
+		Path:  annotation.Path,
+		Notes: annotation.Notes,
+	}
 }
 
 // init registers the info plugin with the default registry
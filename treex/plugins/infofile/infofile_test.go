@@ -7,7 +7,9 @@ import (
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"treex/treex/internal/testutil"
+	"treex/internal/testutil"
+	"treex/treex/pathutil"
+	"treex/treex/plugins"
 	"treex/treex/plugins/infofile"
 	"treex/treex/types"
 )
@@ -388,3 +390,68 @@ func TestInfoPlugin_FindRootsErrorHandling(t *testing.T) {
 		}
 	})
 }
+
+func TestInfoPlugin_EnrichDataPathNormalization(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree(".", map[string]interface{}{
+		".info": "docs/Guide.txt  User guide",
+		"docs": map[string]interface{}{
+			"Guide.txt": "guide",
+		},
+	})
+
+	tests := []struct {
+		name            string
+		caseInsensitive bool
+		filePath        string
+		expectFound     bool
+	}{
+		{"exact path matches", false, "docs/Guide.txt", true},
+		{"case mismatch ignored when case-sensitive", false, "docs/guide.txt", false},
+		{"case mismatch matches when case-insensitive", true, "docs/guide.txt", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := infofile.NewInfoPluginWithNormalizer(pathutil.NewNormalizerWithSeparator('/', tt.caseInsensitive))
+
+			enrichment, err := plugin.EnrichData(fs, ".", []string{tt.filePath}, plugins.CacheMap{})
+			require.NoError(t, err)
+
+			data, found := enrichment[tt.filePath]
+			assert.Equal(t, tt.expectFound, found)
+			if found {
+				assert.Equal(t, "User guide", data.(*types.Annotation).Notes)
+			}
+		})
+	}
+}
+
+func TestInfoPlugin_EnrichNodeWithCacheUsesRootIndex(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree(".", map[string]interface{}{
+		".info": "a.txt  First file",
+		"a.txt": "a",
+		"b.txt": "b",
+	})
+
+	plugin := infofile.NewInfoPlugin()
+	result, err := plugin.ProcessRoot(fs, ".")
+	require.NoError(t, err)
+	require.Contains(t, result.Cache, "annotation_index", "the index is built once per root")
+
+	// Nodes are looked up in the index alone, without reindexing the raw annotations
+	delete(result.Cache, "annotations")
+	results := []*plugins.Result{result}
+
+	annotated := &types.Node{Name: "a.txt", Path: "a.txt"}
+	require.NoError(t, plugin.EnrichNodeWithCache(fs, annotated, results))
+	data, found := annotated.GetPluginData("info")
+	require.True(t, found)
+	assert.Equal(t, "First file", data.(*types.Annotation).Notes)
+
+	plain := &types.Node{Name: "b.txt", Path: "b.txt"}
+	require.NoError(t, plugin.EnrichNodeWithCache(fs, plain, results))
+	_, found = plain.GetPluginData("info")
+	assert.False(t, found)
+}
@@ -0,0 +1,156 @@
+package infofile
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// byteOrderMark starts .info files saved as "UTF-8 with BOM" by some Windows editors
+const byteOrderMark = "\ufeff"
+
+// fileLines is the content of a .info file as lines, without line endings, along with
+// the byte order mark, line ending and final newline it was written with, so rewrites
+// reproduce every line they do not change byte for byte
+type fileLines struct {
+	lines  []string
+	bom    bool
+	ending string // "\r\n" when the first line ends with one, else "\n"
+	final  bool   // The last line ends with a line ending (true for empty files)
+}
+
+// splitLines splits content into lines, numbered like Parse numbers them
+func splitLines(content []byte) fileLines {
+	text := string(content)
+	file := fileLines{ending: "\n", final: true}
+	if strings.HasPrefix(text, byteOrderMark) {
+		file.bom = true
+		text = strings.TrimPrefix(text, byteOrderMark)
+	}
+	if end := strings.IndexByte(text, '\n'); end > 0 && text[end-1] == '\r' {
+		file.ending = "\r\n"
+	}
+	if text == "" {
+		return file
+	}
+
+	file.final = strings.HasSuffix(text, "\n")
+	file.lines = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i, line := range file.lines {
+		file.lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return file
+}
+
+// join renders lines as a file written like the one split
+func (f fileLines) join(lines []string) []byte {
+	var b strings.Builder
+	if f.bom {
+		b.WriteString(byteOrderMark)
+	}
+	for i, line := range lines {
+		b.WriteString(line)
+		if i < len(lines)-1 || f.final {
+			b.WriteString(f.ending)
+		}
+	}
+	return []byte(b.String())
+}
+
+// splitTrailingBlank separates the blank lines ending a file, which new entries are
+// written above so the file keeps its spacing
+func splitTrailingBlank(lines []string) (body, tail []string) {
+	end := len(lines)
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return lines[:end:end], lines[end:]
+}
+
+// entryStyle is how a file lays out its entries, for writing new ones alike
+type entryStyle struct {
+	column    int    // Column every annotation starts at (0 when they are not aligned)
+	separator string // Whitespace between path and annotation when not aligned
+}
+
+// defaultStyle separates paths from annotations with two spaces
+var defaultStyle = entryStyle{separator: "  "}
+
+// detectStyle finds the layout of the annotated entries in lines: aligned when two or
+// more annotations start at the same column and none elsewhere, and the separator the
+// space-syntax entries share (the default when they differ)
+func detectStyle(lines []string) entryStyle {
+	style := defaultStyle
+	columns := make(map[int]bool)
+	separators := make(map[string]bool)
+	count := 0
+	for _, line := range lines {
+		pathEnd, notesStart, ok := entryLayout(line)
+		if !ok {
+			continue
+		}
+		count++
+		separator := line[pathEnd:notesStart]
+		if entry, _ := ParseLine(line); entry.Syntax == SyntaxSpace {
+			separators[separator] = true // "path: notes" says nothing of the space syntax
+		}
+		if strings.Contains(separator, "\t") {
+			columns[-1] = true // Tab stops depend on the editor: never aligned
+			continue
+		}
+		columns[ansi.StringWidth(line[:notesStart])] = true
+	}
+
+	if len(separators) == 1 {
+		for separator := range separators {
+			style.separator = separator
+		}
+	}
+	if count >= 2 && len(columns) == 1 && !columns[-1] {
+		for column := range columns {
+			style.column = column
+		}
+	}
+	return style
+}
+
+// format renders a new entry in the style, falling back to the separator when the path
+// reaches past the aligned column
+func (s entryStyle) format(path, notes string, syntax Syntax) string {
+	token := EscapePath(path)
+	if syntax == SyntaxColon {
+		token += ":"
+	}
+	if width := ansi.StringWidth(token); s.column > width {
+		return token + strings.Repeat(" ", s.column-width) + notes
+	}
+	if syntax == SyntaxColon {
+		return token + " " + notes
+	}
+	return token + s.separator + notes
+}
+
+// replaceNotes rewrites the annotation of an entry line, keeping its path as written
+// and the whitespace after it; lines without an annotation are formatted in style
+func replaceNotes(line, path, notes string, syntax Syntax, style entryStyle) string {
+	if _, notesStart, ok := entryLayout(line); ok {
+		return line[:notesStart] + notes
+	}
+	return style.format(path, notes, syntax)
+}
+
+// entryLayout returns where the path token of an entry line ends and its annotation
+// starts, reporting false for blank lines, comments and entries without annotation
+func entryLayout(line string) (pathEnd, notesStart int, ok bool) {
+	start := len(line) - len(strings.TrimLeft(line, " \t"))
+	if start == len(line) || line[start] == '#' {
+		return 0, 0, false
+	}
+
+	pathEnd = pathTokenEnd(line, start)
+	notesStart = len(line) - len(strings.TrimLeft(line[pathEnd:], " \t"))
+	if strings.TrimSpace(line[notesStart:]) == "" {
+		return 0, 0, false
+	}
+	return pathEnd, notesStart, true
+}
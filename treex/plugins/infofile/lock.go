@@ -0,0 +1,115 @@
+package infofile
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// ErrLocked is returned when a .info file stays locked by another writer after every retry
+var ErrLocked = errors.New("locked by another process")
+
+// lockRetries and lockBackoff bound how long a writer waits for a .info lock: the delay
+// starts at lockBackoff and doubles after each failed attempt (about 1.3s in total)
+var (
+	lockRetries = 7
+	lockBackoff = 10 * time.Millisecond
+)
+
+// lockSuffix names the lock file created next to a .info file while it is rewritten
+const lockSuffix = ".lock"
+
+// staleLockAge is the age past which a lock is taken to be left behind; a rewrite holds
+// its lock for milliseconds
+const staleLockAge = time.Minute
+
+// withLock runs update while holding the advisory lock of the file at fullPath
+// The lock is a sibling file created exclusively, so it works on any afero.Fs and
+// between processes (CLI, editor plugins, the MCP server) that follow the convention.
+// A lock left behind by a crashed process is broken: its PID is not running, or it is
+// older than staleLockAge. Otherwise the error names the lock to remove by hand.
+func withLock(fs afero.Fs, fullPath, displayPath string, update func() error) error {
+	lockPath := fullPath + lockSuffix
+	delay := lockBackoff
+	for attempt := 0; ; attempt++ {
+		lock, err := fs.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, _ = fmt.Fprintf(lock, "%d\n", os.Getpid())
+			_ = lock.Close()
+			break
+		}
+		if exists, _ := afero.Exists(fs, lockPath); !exists {
+			return fmt.Errorf("failed to lock %s: %w", displayPath, err)
+		}
+		if breakStaleLock(fs, lockPath) {
+			continue
+		}
+		if attempt >= lockRetries {
+			return fmt.Errorf("cannot write %s: %w (remove %s if no treex process is running)", displayPath, ErrLocked, lockPath)
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+	defer func() { _ = fs.Remove(lockPath) }()
+	return update()
+}
+
+// breakStaleLock removes the lock at lockPath when it was left behind by a process that
+// is gone or is older than staleLockAge, reporting whether it did
+func breakStaleLock(fs afero.Fs, lockPath string) bool {
+	info, err := fs.Stat(lockPath)
+	if err != nil {
+		return false
+	}
+	content, err := afero.ReadFile(fs, lockPath)
+	if err != nil {
+		return false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	dead := err == nil && pid > 0 && !processAlive(pid)
+	if !dead && time.Since(info.ModTime()) < staleLockAge {
+		return false // A lock without a PID yet is being taken right now
+	}
+	slog.Warn("breaking stale lock", "lock", lockPath, "pid", pid, "age", time.Since(info.ModTime()).Round(time.Second))
+	return fs.Remove(lockPath) == nil
+}
+
+// writeAtomic replaces the file at fullPath with content by writing a temporary file in
+// the same directory and renaming it over the original, so readers never see a partial
+// file. An existing file keeps its permissions; new files get 0644.
+func writeAtomic(fs afero.Fs, fullPath string, content []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := fs.Stat(fullPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	temp, err := afero.TempFile(fs, filepath.Dir(fullPath), filepath.Base(fullPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := temp.Name()
+	_, err = temp.Write(content)
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = fs.Chmod(tempPath, mode)
+	}
+	if err == nil {
+		err = fs.Rename(tempPath, fullPath)
+	}
+	if err != nil {
+		_ = fs.Remove(tempPath)
+	}
+	return err
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package infofile
+
+// processAlive reports whether a process with pid exists; without a portable check every
+// process is assumed alive, and stale locks are only recognized by their age
+func processAlive(pid int) bool {
+	return true
+}
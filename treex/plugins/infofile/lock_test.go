@@ -0,0 +1,123 @@
+package infofile_test
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins/infofile"
+)
+
+func TestConcurrentWritersKeepEveryEntry(t *testing.T) {
+	files := make(map[string]interface{})
+	for i := 0; i < 20; i++ {
+		files[fmt.Sprintf("file%02d.go", i)] = "package main"
+	}
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", files)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := infofile.AddAnnotation(fs, "/project", fmt.Sprintf("file%02d.go", i), fmt.Sprintf("File %d", i))
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	parsed, err := infofile.Parse(content)
+	require.NoError(t, err)
+	assert.Len(t, parsed, 20)
+
+	entries, err := afero.ReadDir(fs, "/project")
+	require.NoError(t, err)
+	assert.Len(t, entries, 21, "no lock or temporary files are left behind")
+}
+
+func TestWriteFailsWhileLocked(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":      "README.md  Overview\n",
+		".info.lock": fmt.Sprintf("%d\n", os.Getpid()), // Held by a running process
+		"README.md":  "# project",
+	})
+
+	_, err := infofile.AddAnnotation(fs, "/project", "README.md", "Changed")
+	require.ErrorIs(t, err, infofile.ErrLocked)
+	assert.Contains(t, err.Error(), "/project/.info.lock")
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "README.md  Overview\n", string(content))
+}
+
+func TestWriteBreaksStaleLocks(t *testing.T) {
+	type staleLock struct {
+		name string
+		lock string
+		age  time.Duration
+	}
+	tests := []staleLock{
+		{"old lock of a running process", fmt.Sprintf("%d\n", os.Getpid()), 2 * time.Hour},
+		{"old lock without a PID", "", 2 * time.Hour},
+	}
+	if runtime.GOOS != "windows" { // Elsewhere only the age of a lock tells it is stale
+		tests = append(tests, staleLock{"fresh lock of a process that is gone", "99999999\n", 0}) // Above any pid_max
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := testutil.NewTestFS()
+			fs.MustCreateTree("/project", map[string]interface{}{
+				".info":      "README.md  Overview\n",
+				".info.lock": tt.lock,
+				"README.md":  "# project",
+			})
+			modified := time.Now().Add(-tt.age)
+			require.NoError(t, fs.Chtimes("/project/.info.lock", modified, modified))
+
+			_, err := infofile.AddAnnotation(fs, "/project", "README.md", "Changed")
+			require.NoError(t, err)
+
+			content, err := afero.ReadFile(fs, "/project/.info")
+			require.NoError(t, err)
+			assert.Equal(t, "README.md  Changed\n", string(content))
+			exists, _ := afero.Exists(fs, "/project/.info.lock")
+			assert.False(t, exists, "the lock is released")
+		})
+	}
+}
+
+func TestApplyRewritesRejectsChangedFiles(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info": "docs/guide.md  Guide\n",
+		"docs":  map[string]interface{}{"guide.md": "# Guide"},
+	})
+
+	rewrites, err := infofile.PlanDistribute(fs, "/project")
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, "/project/.info", []byte("docs/guide.md  Edited meanwhile\n"), 0644))
+
+	err = infofile.ApplyRewrites(fs, "/project", rewrites)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "changed since the rewrite was planned")
+	exists, _ := afero.Exists(fs, "/project/docs/.info")
+	assert.False(t, exists, "nothing is written from a stale plan")
+}
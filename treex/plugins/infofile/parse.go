@@ -0,0 +1,216 @@
+package infofile
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Syntax identifies how an entry separates its path from its annotation
+type Syntax string
+
+const (
+	// SyntaxSpace entries are "<path> <annotation>", split at the first unescaped space or tab
+	SyntaxSpace Syntax = "space"
+	// SyntaxColon entries are "<path>: <annotation>", the path token ending in a colon
+	SyntaxColon Syntax = "colon"
+)
+
+// Entry is one annotation line of a .info file
+// Path is unescaped and relative to the directory holding the .info file
+type Entry struct {
+	Line   int    // 1-based line number
+	Path   string // Annotated path as written ("." is the .info file's directory)
+	Notes  string // Annotation text; empty for malformed lines with only a path
+	Syntax Syntax // Syntax the line was written in
+}
+
+// MaxLineSize is the longest .info line read, in bytes. Raise it before parsing files
+// holding longer annotations, such as generated content.
+var MaxLineSize = 1024 * 1024
+
+// LineTooLongError reports a .info line longer than MaxLineSize; the lines from it on
+// are not read
+type LineTooLongError struct {
+	Line  int // 1-based line number
+	Limit int // MaxLineSize when the line was read
+}
+
+func (e *LineTooLongError) Error() string {
+	return fmt.Sprintf("line %d is longer than %d bytes", e.Line, e.Limit)
+}
+
+// Parse returns the entries of a .info file in line order
+// Blank lines and comments are skipped; both syntaxes may be mixed in one file. A line
+// longer than MaxLineSize stops parsing with a *LineTooLongError; the entries before
+// it are returned with the error.
+func Parse(content []byte) ([]Entry, error) {
+	var entries []Entry
+	scanner := newLineScanner(content)
+	lineNum := 1
+	for ; scanner.Scan(); lineNum++ {
+		if entry, ok := ParseLine(scanner.Text()); ok {
+			entry.Line = lineNum
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanError(scanner, lineNum)
+}
+
+// newLineScanner reads the lines of content, up to MaxLineSize bytes long
+// The buffer holds the longest line and its newline, and starts no larger than content.
+func newLineScanner(content []byte) *bufio.Scanner {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, min(len(content)+1, bufio.MaxScanTokenSize, MaxLineSize+1)), MaxLineSize+1)
+	return scanner
+}
+
+// scanError returns the error that stopped scanner on line lineNum, if any
+func scanError(scanner *bufio.Scanner, lineNum int) error {
+	err := scanner.Err()
+	if errors.Is(err, bufio.ErrTooLong) {
+		return &LineTooLongError{Line: lineNum, Limit: MaxLineSize}
+	}
+	return err
+}
+
+// ParseLine parses a single .info line, reporting false for blank lines, comments and
+// @include directives
+// A UTF-8 byte order mark and trailing carriage return are ignored
+func ParseLine(line string) (Entry, bool) {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "\ufeff"))
+	if line == "" || strings.HasPrefix(line, "#") {
+		return Entry{}, false
+	}
+	if _, ok := parseInclude(line); ok {
+		return Entry{}, false
+	}
+
+	end := pathTokenEnd(line, 0)
+	token := line[:end]
+	entry := Entry{Notes: strings.TrimSpace(line[end:]), Syntax: SyntaxSpace}
+	if strings.HasSuffix(token, ":") && !strings.HasSuffix(token, `\:`) && len(token) > 1 {
+		token = strings.TrimSuffix(token, ":")
+		entry.Syntax = SyntaxColon
+	}
+	entry.Path = unescapePath(token)
+	return entry, true
+}
+
+// pathTokenEnd returns where the path token starting at start ends: at the first space
+// or tab not escaped with a backslash, or at the end of line
+func pathTokenEnd(line string, start int) int {
+	for i := start; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if line[i] == ' ' || line[i] == '\t' {
+			return i
+		}
+	}
+	return len(line)
+}
+
+// IncludeDirective starts a line that includes the entries of another file, as if they
+// were written below the entries of the including .info file: "@include ../shared.info"
+const IncludeDirective = "@include"
+
+// Include is an @include directive of a .info file
+type Include struct {
+	Line int    // 1-based line number
+	Path string // Included file as written: relative to the .info file's directory, or to the root when it starts with "/"
+}
+
+// ParseIncludes returns the @include directives of a .info file in line order, stopping
+// at a line longer than MaxLineSize as Parse does
+func ParseIncludes(content []byte) ([]Include, error) {
+	var includes []Include
+	scanner := newLineScanner(content)
+	lineNum := 1
+	for ; scanner.Scan(); lineNum++ {
+		if includePath, ok := parseInclude(scanner.Text()); ok {
+			includes = append(includes, Include{Line: lineNum, Path: includePath})
+		}
+	}
+	return includes, scanError(scanner, lineNum)
+}
+
+// parseInclude returns the file named by an @include line ("" when it names none)
+func parseInclude(line string) (string, bool) {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "\ufeff"))
+	rest, found := strings.CutPrefix(line, IncludeDirective)
+	if !found || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// MaxDepthDirective starts the comment that limits how deep a directory's subtree is shown,
+// relative to the directory holding the .info file: "# treex:max-depth=1" lists only the
+// directory's own entries.
+const MaxDepthDirective = "treex:max-depth="
+
+// MaxDepth returns the value of the first max-depth directive in a .info file
+// Lines after one longer than MaxLineSize are not searched.
+func MaxDepth(content []byte) (int, bool) {
+	scanner := newLineScanner(content)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+		value, found := strings.CutPrefix(strings.TrimSpace(strings.TrimPrefix(line, "#")), MaxDepthDirective)
+		if !found {
+			continue
+		}
+		if depth, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && depth >= 0 {
+			return depth, true
+		}
+	}
+	return 0, false
+}
+
+// FormatEntry renders an entry line in the given syntax, escaping spaces in the path
+func FormatEntry(path, notes string, syntax Syntax) string {
+	escaped := EscapePath(path)
+	if syntax == SyntaxColon {
+		return escaped + ": " + notes
+	}
+	return escaped + "  " + notes
+}
+
+// EscapePath escapes the characters that would end the path token of an entry
+func EscapePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if r == ' ' || r == '\t' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	escaped := b.String()
+	if strings.HasSuffix(escaped, ":") {
+		escaped = strings.TrimSuffix(escaped, ":") + `\:`
+	}
+	return escaped
+}
+
+// unescapePath removes the backslash escapes from a path token
+func unescapePath(token string) string {
+	if !strings.Contains(token, `\`) {
+		return token
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(token); i++ {
+		if token[i] == '\\' && i+1 < len(token) {
+			i++
+		}
+		b.WriteByte(token[i])
+	}
+	return b.String()
+}
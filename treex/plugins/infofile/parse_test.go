@@ -0,0 +1,125 @@
+package infofile_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex/plugins/infofile"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		entry infofile.Entry
+		ok    bool
+	}{
+		{"space syntax", "main.go  Entry point", infofile.Entry{Path: "main.go", Notes: "Entry point", Syntax: infofile.SyntaxSpace}, true},
+		{"tab separator", "main.go\tEntry point", infofile.Entry{Path: "main.go", Notes: "Entry point", Syntax: infofile.SyntaxSpace}, true},
+		{"colon syntax", "src/main.go: Entry point", infofile.Entry{Path: "src/main.go", Notes: "Entry point", Syntax: infofile.SyntaxColon}, true},
+		{"escaped space", `my\ file.txt Notes`, infofile.Entry{Path: "my file.txt", Notes: "Notes", Syntax: infofile.SyntaxSpace}, true},
+		{"escaped colon", `ratio\: Notes`, infofile.Entry{Path: "ratio:", Notes: "Notes", Syntax: infofile.SyntaxSpace}, true},
+		{"path only", "main.go", infofile.Entry{Path: "main.go", Syntax: infofile.SyntaxSpace}, true},
+		{"crlf and bom", "\ufeffmain.go  Entry point\r", infofile.Entry{Path: "main.go", Notes: "Entry point", Syntax: infofile.SyntaxSpace}, true},
+		{"comment", "# main.go  Entry point", infofile.Entry{}, false},
+		{"blank", "   ", infofile.Entry{}, false},
+		{"include", "@include ../shared.info", infofile.Entry{}, false},
+		{"path starting like include", "@included.txt  Notes", infofile.Entry{Path: "@included.txt", Notes: "Notes", Syntax: infofile.SyntaxSpace}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := infofile.ParseLine(tt.line)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.entry, entry)
+		})
+	}
+}
+
+func TestParseNumbersLines(t *testing.T) {
+	entries, err := infofile.Parse([]byte("# header\n\na.go  First\nb.go: Second\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []infofile.Entry{
+		{Line: 3, Path: "a.go", Notes: "First", Syntax: infofile.SyntaxSpace},
+		{Line: 4, Path: "b.go", Notes: "Second", Syntax: infofile.SyntaxColon},
+	}, entries)
+}
+
+func TestParseIncludes(t *testing.T) {
+	includes, err := infofile.ParseIncludes([]byte("@include ../shared.info\nmain.go  Entry point\n  @include\t/common/my file.info  \n@include\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []infofile.Include{
+		{Line: 1, Path: "../shared.info"},
+		{Line: 3, Path: "/common/my file.info"},
+		{Line: 4, Path: ""},
+	}, includes)
+}
+
+func TestParseLongLines(t *testing.T) {
+	generated := "schema.json  " + strings.Repeat("x", 100*1024)
+	entries, err := infofile.Parse([]byte("a.go  First\n" + generated + "\nb.go  Last\n"))
+	require.NoError(t, err, "lines past bufio's 64KB default are read")
+	require.Len(t, entries, 3)
+	assert.Len(t, entries[1].Notes, 100*1024)
+
+	original := infofile.MaxLineSize
+	infofile.MaxLineSize = 16
+	t.Cleanup(func() { infofile.MaxLineSize = original })
+
+	entries, err = infofile.Parse([]byte("a.go  First\nexactly16bytes.x\nb.go  " + strings.Repeat("x", 20) + "\nc.go  Last\n"))
+	var tooLong *infofile.LineTooLongError
+	require.ErrorAs(t, err, &tooLong)
+	assert.Equal(t, 3, tooLong.Line)
+	assert.EqualError(t, err, "line 3 is longer than 16 bytes")
+	assert.Len(t, entries, 2, "entries above the oversize line are returned")
+
+	_, err = infofile.Parse([]byte("a.go  First\nb.go  " + strings.Repeat("x", 20)))
+	assert.ErrorAs(t, err, &tooLong, "a last line without newline is measured too")
+}
+
+func TestParseWindowsFile(t *testing.T) {
+	entries, err := infofile.Parse([]byte("\ufeffmain.go  Entry point\r\n# comment\r\nutil.go: Helpers\r\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []infofile.Entry{
+		{Line: 1, Path: "main.go", Notes: "Entry point", Syntax: infofile.SyntaxSpace},
+		{Line: 3, Path: "util.go", Notes: "Helpers", Syntax: infofile.SyntaxColon},
+	}, entries, "no byte order mark in the first path, no carriage returns in annotations")
+}
+
+func TestFormatEntryRoundTrip(t *testing.T) {
+	for _, syntax := range []infofile.Syntax{infofile.SyntaxSpace, infofile.SyntaxColon} {
+		for _, path := range []string{"main.go", "my file.txt", `back\slash`, "ratio:"} {
+			line := infofile.FormatEntry(path, "Some notes", syntax)
+			entry, ok := infofile.ParseLine(line)
+			assert.True(t, ok, line)
+			assert.Equal(t, path, entry.Path, line)
+			assert.Equal(t, "Some notes", entry.Notes, line)
+			assert.Equal(t, syntax, entry.Syntax, line)
+		}
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	tests := []struct {
+		content string
+		depth   int
+		ok      bool
+	}{
+		{"# treex:max-depth=1\nlib  Vendored code\n", 1, true},
+		{"lib  Vendored code\n#treex:max-depth= 0\n", 0, true},
+		{"# treex:max-depth=-1\n# treex:max-depth=2\n", 2, true},
+		{"# treex:max-depth=deep\n", 0, false},
+		{"lib  treex:max-depth=1\n", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		depth, ok := infofile.MaxDepth([]byte(tt.content))
+		assert.Equal(t, tt.ok, ok, tt.content)
+		assert.Equal(t, tt.depth, depth, tt.content)
+	}
+}
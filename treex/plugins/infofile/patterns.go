@@ -0,0 +1,75 @@
+package infofile
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// isPattern reports whether an entry path is a glob ("*_test.go", "cmd/*.go"): one
+// holding a path.Match metacharacter. Entries naming an existing path are exact all the
+// same, so files with '*' or '[' in their name can still be annotated.
+func isPattern(entryPath string) bool {
+	return strings.ContainsAny(entryPath, "*?[")
+}
+
+// missingPathMessage explains why an entry for a path that does not exist in dir
+// annotates nothing; "" for a glob entry matching some path
+func missingPathMessage(fs afero.Fs, dir, entryPath string) string {
+	if !isPattern(entryPath) {
+		return "annotated path does not exist"
+	}
+	matches, err := expandPattern(fs, dir, entryPath)
+	if err != nil {
+		return fmt.Sprintf("invalid pattern: %v", err)
+	}
+	if len(matches) == 0 {
+		return "pattern matches no path"
+	}
+	return ""
+}
+
+// expandPattern returns the paths below dir a glob entry matches, relative to dir,
+// slash-separated and sorted. "*" does not cross directories and, as in the shell, names
+// starting with "." are only matched by pattern components starting with "." too.
+func expandPattern(fs afero.Fs, dir, pattern string) ([]string, error) {
+	pattern = path.Clean(pattern)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err // afero.Glob only reports bad patterns it gets to match
+	}
+	matches, err := afero.Glob(fs, filepath.Join(dir, filepath.FromSlash(pattern)))
+	if err != nil {
+		return nil, err
+	}
+
+	patternParts := strings.Split(pattern, "/")
+	paths := make([]string, 0, len(matches))
+	for _, match := range matches {
+		relative, err := filepath.Rel(dir, match)
+		if err != nil {
+			return nil, err
+		}
+		relative = filepath.ToSlash(relative)
+		if !hiddenMatchAllowed(strings.Split(relative, "/"), patternParts) {
+			continue
+		}
+		paths = append(paths, relative)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// hiddenMatchAllowed reports whether every component of a match starting with "." was
+// matched by a pattern component starting with "."
+func hiddenMatchAllowed(parts, patternParts []string) bool {
+	for i, part := range parts {
+		if strings.HasPrefix(part, ".") && (i >= len(patternParts) || !strings.HasPrefix(patternParts[i], ".")) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package infofile_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins/infofile"
+)
+
+func TestGatherPatterns(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info": "src/main_test.go  Tests of the entry point\nsrc/*.go  Go sources\n",
+		"src": map[string]interface{}{
+			".info":        "*_test.go  Unit tests for the sibling file\n*.go  Go source\n[bad  Broken pattern\nstar*.txt  A file with a star\n",
+			".hidden.go":   "package main",
+			"main.go":      "package main",
+			"main_test.go": "package main",
+			"util_test.go": "package main",
+			"star*.txt":    "",
+		},
+	})
+
+	annotations, err := infofile.Gather(fs, "/project")
+	require.NoError(t, err)
+
+	assert.Equal(t, infofile.Annotation{
+		Path: "/project/src/util_test.go", Notes: "Unit tests for the sibling file", InfoFile: "/project/src/.info", Line: 1, Pattern: "*_test.go",
+	}, annotations["/project/src/util_test.go"])
+	assert.Equal(t, "Tests of the entry point", annotations["/project/src/main_test.go"].Notes, "exact entries outrank closer patterns")
+	assert.Equal(t, "Go source", annotations["/project/src/main.go"].Notes, "the closest pattern wins")
+	assert.Equal(t, "A file with a star", annotations["/project/src/star*.txt"].Notes)
+	assert.Empty(t, annotations["/project/src/star*.txt"].Pattern, "existing paths are exact")
+	assert.NotContains(t, annotations, "/project/src/.hidden.go", "* skips dot files")
+}
+
+func TestValidatePatterns(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":   "*.go  Go sources\n*.rs  Rust sources\n[bad  Broken\n",
+		"main.go": "package main",
+	})
+
+	issues, err := infofile.Validate(fs, "/project")
+	require.NoError(t, err)
+	assert.Equal(t, []infofile.Issue{
+		{InfoFile: ".info", Line: 2, Path: "*.rs", Message: "pattern matches no path", Type: infofile.IssueMissingPath},
+		{InfoFile: ".info", Line: 3, Path: "[bad", Message: "invalid pattern: syntax error in pattern", Type: infofile.IssueMissingPath},
+	}, issues)
+}
@@ -0,0 +1,98 @@
+package infofile
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/afero"
+)
+
+// ProseRules are the writing standards CheckProse holds annotations to
+// Zero values disable a rule; trailing whitespace is always reported.
+type ProseRules struct {
+	MinLength    int      // Fewest characters an annotation may have
+	MaxLength    int      // Most characters an annotation may have
+	SentenceCase bool     // Annotations starting with a letter must capitalize it
+	BannedWords  []string // Words or phrases annotations must not use (whole words, any case)
+}
+
+// CheckProse checks the annotation text of every .info file below root against rules
+// Issues have type IssueProse and are sorted by .info file and line
+func CheckProse(fs afero.Fs, root string, rules ProseRules) ([]Issue, error) {
+	infoFiles, err := listInfoFiles(fs, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check .info files in %s: %w", root, err)
+	}
+	return CheckProseFiles(fs, root, infoFiles, rules)
+}
+
+// CheckProseFiles checks only the given .info files (paths relative to root), as
+// CheckProse does for every file. Files that no longer exist are skipped.
+func CheckProseFiles(fs afero.Fs, root string, infoFiles []string, rules ProseRules) ([]Issue, error) {
+	banned := make(map[string]*regexp.Regexp, len(rules.BannedWords))
+	var bannedWords []string
+	for _, word := range rules.BannedWords {
+		if word = strings.TrimSpace(word); word != "" && banned[word] == nil {
+			banned[word] = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(word) + `\b`)
+			bannedWords = append(bannedWords, word)
+		}
+	}
+
+	var issues []Issue
+	for _, infoFile := range infoFiles {
+		content, err := afero.ReadFile(fs, filepath.Join(root, filepath.FromSlash(infoFile)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", infoFile, err)
+		}
+
+		// Validation reports a line longer than MaxLineSize; the lines above it are checked
+		scanner := newLineScanner(content)
+		for lineNum := 1; scanner.Scan(); lineNum++ {
+			line := strings.TrimSuffix(scanner.Text(), "\r")
+			entry, ok := ParseLine(line)
+			if !ok || entry.Notes == "" {
+				continue
+			}
+			report := func(message string) {
+				issues = append(issues, Issue{
+					InfoFile: infoFile,
+					Line:     lineNum,
+					Path:     path.Join(path.Dir(infoFile), entry.Path),
+					Message:  message,
+					Type:     IssueProse,
+				})
+			}
+
+			if strings.TrimRight(line, " \t") != line {
+				report("trailing whitespace")
+			}
+			length := utf8.RuneCountInString(entry.Notes)
+			if rules.MinLength > 0 && length < rules.MinLength {
+				report(fmt.Sprintf("annotation is shorter than %d characters", rules.MinLength))
+			}
+			if rules.MaxLength > 0 && length > rules.MaxLength {
+				report(fmt.Sprintf("annotation is longer than %d characters", rules.MaxLength))
+			}
+			if first, _ := utf8.DecodeRuneInString(entry.Notes); rules.SentenceCase && unicode.IsLower(first) {
+				report("annotation should start with a capital letter")
+			}
+			for _, word := range bannedWords {
+				if banned[word].MatchString(entry.Notes) {
+					report(fmt.Sprintf("annotation uses banned word %q", word))
+				}
+			}
+		}
+	}
+
+	sortIssues(issues)
+	return issues, nil
+}
@@ -0,0 +1,63 @@
+package infofile_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins/infofile"
+)
+
+func TestCheckProse(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info": "README.md  Project overview\n" +
+			"main.go  entry point \n" +
+			"util.go  Ok\n" +
+			"docs  Simply everything you need, obviously\r\n" +
+			"empty\n",
+		"src": map[string]interface{}{
+			".info": "lib.go  A library that has grown far too long to read at a glance\n",
+		},
+	})
+
+	rules := infofile.ProseRules{MinLength: 5, MaxLength: 40, SentenceCase: true, BannedWords: []string{"simply", " obviously", ""}}
+	issues, err := infofile.CheckProse(fs, "/project", rules)
+	require.NoError(t, err)
+
+	prose := func(infoFile string, line int, path, message string) infofile.Issue {
+		return infofile.Issue{InfoFile: infoFile, Line: line, Path: path, Message: message, Type: infofile.IssueProse}
+	}
+	assert.Equal(t, []infofile.Issue{
+		prose(".info", 2, "main.go", "trailing whitespace"),
+		prose(".info", 2, "main.go", "annotation should start with a capital letter"),
+		prose(".info", 3, "util.go", "annotation is shorter than 5 characters"),
+		prose(".info", 4, "docs", `annotation uses banned word "simply"`),
+		prose(".info", 4, "docs", `annotation uses banned word "obviously"`),
+		prose("src/.info", 1, "src/lib.go", "annotation is longer than 40 characters"),
+	}, issues)
+}
+
+func TestCheckProseDefaults(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info": "main.go  entry point\t\nutil.go  x\n",
+	})
+
+	issues, err := infofile.CheckProseFiles(fs, "/project", []string{".info", "gone/.info"}, infofile.ProseRules{})
+	require.NoError(t, err)
+	require.Len(t, issues, 1, "only trailing whitespace is checked without rules")
+	assert.Equal(t, "trailing whitespace", issues[0].Message)
+}
+
+func TestFixIssuesLeavesProse(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{".info": "main.go  entry point \n"})
+
+	fixed, err := infofile.FixIssues(fs, "/project", []infofile.Issue{
+		{InfoFile: ".info", Line: 1, Path: "main.go", Message: "trailing whitespace", Type: infofile.IssueProse},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, fixed)
+}
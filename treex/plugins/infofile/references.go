@@ -0,0 +1,74 @@
+package infofile
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	// markdownLink matches [text](target) and [text](target "title"), capturing the target
+	markdownLink = regexp.MustCompile(`\[[^\]]*\]\(<?([^)\s>]+)>?(?:\s+"[^"]*")?\)`)
+	// codeSpan matches single-backtick code spans without spaces
+	codeSpan = regexp.MustCompile("`([^`\\s]+)`")
+	// urlScheme matches targets that are URLs (https:, mailto:) rather than paths
+	urlScheme = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+)
+
+// References returns the paths an annotation refers to, in order of appearance: the
+// targets of relative markdown links, without #fragments or ?queries, and code spans
+// that look like paths ("docs/setup.md", "./run.sh"). Targets starting with "/" are
+// relative to the tree root, others to the directory holding the .info file.
+func References(notes string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	add := func(ref string) {
+		if ref != "" && !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	for _, match := range markdownLink.FindAllStringSubmatch(notes, -1) {
+		target := match[1]
+		if strings.HasPrefix(target, "#") || urlScheme.MatchString(target) {
+			continue
+		}
+		if cut := strings.IndexAny(target, "#?"); cut >= 0 {
+			target = target[:cut]
+		}
+		if unescaped, err := url.PathUnescape(target); err == nil {
+			target = unescaped
+		}
+		add(target)
+	}
+
+	for _, match := range codeSpan.FindAllStringSubmatch(notes, -1) {
+		if looksLikePath(match[1]) {
+			add(match[1])
+		}
+	}
+	return refs
+}
+
+// looksLikePath reports whether a code span names a path rather than code, a command or
+// a pattern: it must contain a slash and no glob, variable or URL syntax
+func looksLikePath(text string) bool {
+	if !strings.Contains(text, "/") || strings.Contains(text, "...") || strings.HasPrefix(text, "-") {
+		return false
+	}
+	if strings.ContainsAny(text, "*?[]{}$~<>=|&;()'\"") || urlScheme.MatchString(text) {
+		return false
+	}
+	return true
+}
+
+// resolveReference returns the root-relative path of a reference found in a .info file
+// in infoDir (see References)
+func resolveReference(infoDir, ref string) string {
+	if strings.HasPrefix(ref, "/") {
+		return path.Clean(strings.TrimPrefix(ref, "/"))
+	}
+	return path.Join(infoDir, ref)
+}
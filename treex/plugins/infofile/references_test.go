@@ -0,0 +1,55 @@
+package infofile_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins/infofile"
+)
+
+func TestReferences(t *testing.T) {
+	tests := []struct {
+		notes    string
+		expected []string
+	}{
+		{"Plain text", nil},
+		{"See [setup](docs/setup.md#install) and [api](<api/v1 spec.md>)", []string{"docs/setup.md"}},
+		{"Read [the guide](guide%20v2.md \"Guide\") first", []string{"guide v2.md"}},
+		{"Links to [site](https://example.com), [mail](mailto:a@b.c) and [top](#top) are skipped", nil},
+		{"Run `./scripts/build.sh`, not `go build` or `cmd/...` or `*.go/x`", []string{"./scripts/build.sh"}},
+		{"Root-relative [config](/config/app.toml) and `config/app.toml` twice [again](/config/app.toml)",
+			[]string{"/config/app.toml", "config/app.toml"}},
+		{"Uses `$HOME/.cache` and `https://x.io/y`", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.notes, func(t *testing.T) {
+			assert.Equal(t, tt.expected, infofile.References(tt.notes))
+		})
+	}
+}
+
+func TestValidateBrokenReferences(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"README.md": "# project",
+		"docs": map[string]interface{}{
+			".info":    "setup.md  Install steps; see [readme](/README.md) and `../scripts/gone.sh`\n",
+			"setup.md": "# setup",
+		},
+		".info": "docs  Guides, starting with [setup](docs/setup.md) and [faq](docs/faq.md)\n",
+	})
+
+	issues, err := infofile.Validate(fs, "/project")
+	require.NoError(t, err)
+	assert.Equal(t, []infofile.Issue{
+		{InfoFile: ".info", Line: 1, Path: "docs", Message: `annotation references missing path "docs/faq.md"`, Type: infofile.IssueBrokenReference},
+		{InfoFile: "docs/.info", Line: 1, Path: "docs/setup.md", Message: `annotation references missing path "../scripts/gone.sh"`, Type: infofile.IssueBrokenReference},
+	}, issues)
+
+	fixed, err := infofile.FixIssues(fs, "/project", issues)
+	require.NoError(t, err)
+	assert.Empty(t, fixed, "broken references are not removed by --fix")
+}
@@ -0,0 +1,269 @@
+package infofile
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// Rewrite is the planned new content of one .info file
+type Rewrite struct {
+	InfoFile string // Path relative to the root, slash-separated
+	Before   []byte // Current content; nil when the file does not exist yet
+	After    []byte // New content; nil when the file is removed
+}
+
+// PlanGather plans moving every annotation below root into the root .info file
+// Entries are keyed by their path relative to root.
+func PlanGather(fs afero.Fs, root string) ([]Rewrite, error) {
+	return planRelocation(fs, root, func(target string) (string, string) {
+		return ".info", target
+	})
+}
+
+// PlanDistribute plans moving every annotation into the .info file of its parent
+// directory, the file AddAnnotation writes to. The root's own annotation stays in the
+// root .info file.
+func PlanDistribute(fs afero.Fs, root string) ([]Rewrite, error) {
+	return planRelocation(fs, root, func(target string) (string, string) {
+		if target == "." {
+			return ".info", "."
+		}
+		return path.Join(path.Dir(target), ".info"), path.Base(target)
+	})
+}
+
+// ApplyRewrites writes planned rewrites below root, removing files whose After is nil
+// Every file is checked against its Before first, so a plan made stale by another
+// writer is reported before anything is written; each file is checked again under its lock.
+func ApplyRewrites(fs afero.Fs, root string, rewrites []Rewrite) error {
+	for _, rewrite := range rewrites {
+		if err := checkUnchanged(fs, filepath.Join(root, filepath.FromSlash(rewrite.InfoFile)), rewrite); err != nil {
+			return err
+		}
+	}
+	for _, rewrite := range rewrites {
+		fullPath := filepath.Join(root, filepath.FromSlash(rewrite.InfoFile))
+		err := withLock(fs, fullPath, rewrite.InfoFile, func() error {
+			return applyRewrite(fs, fullPath, rewrite)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkUnchanged fails when the file at fullPath no longer holds rewrite.Before
+func checkUnchanged(fs afero.Fs, fullPath string, rewrite Rewrite) error {
+	current, err := afero.ReadFile(fs, fullPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", rewrite.InfoFile, err)
+	}
+	if !bytes.Equal(current, rewrite.Before) {
+		return fmt.Errorf("%s changed since the rewrite was planned; run the command again", rewrite.InfoFile)
+	}
+	return nil
+}
+
+// applyRewrite writes or removes one planned file after checking it still holds Before
+func applyRewrite(fs afero.Fs, fullPath string, rewrite Rewrite) error {
+	if err := checkUnchanged(fs, fullPath, rewrite); err != nil {
+		return err
+	}
+	if rewrite.After == nil {
+		if err := fs.Remove(fullPath); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", rewrite.InfoFile, err)
+		}
+		return nil
+	}
+	if err := writeAtomic(fs, fullPath, rewrite.After); err != nil {
+		return fmt.Errorf("failed to write %s: %w", rewrite.InfoFile, err)
+	}
+	return nil
+}
+
+// relocatedEntry is an annotation on its way to another .info file
+type relocatedEntry struct {
+	name    string // Path relative to the destination .info directory
+	notes   string
+	syntax  Syntax
+	markers []string // Review and generated marker lines carried along, if any
+}
+
+// planRelocation moves the winning annotation of every path (see Gather) to the .info
+// file and entry name chosen by destination (given the path relative to root)
+//
+// Shadowed duplicates are dropped, entries already in place keep their line, and entries
+// without text or for missing paths are left alone, as are entries an @include would
+// shadow at their destination. Files left without content are removed.
+func planRelocation(fs afero.Fs, root string, destination func(target string) (string, string)) ([]Rewrite, error) {
+	annotations, err := GatherShared(fs, root)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make(map[string][]byte)
+	err = afero.Walk(fs, root, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || info.Name() != ".info" {
+			return nil
+		}
+		content, err := afero.ReadFile(fs, filePath)
+		if err != nil {
+			return nil
+		}
+		contents[relativeTo(root, filepath.ToSlash(filePath))] = content
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string][]string)
+	includers := make(map[string][]string)
+	for infoFile, content := range contents {
+		lines[infoFile] = splitLines(content).lines
+		for _, target := range includedTargets(fs, root, infoFile, content) {
+			includers[target] = append(includers[target], path.Dir(infoFile))
+		}
+	}
+
+	// Remove every annotated entry except the winner already in its destination
+	removed := make(map[string]map[int]bool)
+	incoming := make(map[string][]relocatedEntry)
+	for infoFile, content := range contents {
+		infoDir := path.Dir(infoFile)
+		entries, err := Parse(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", infoFile, err)
+		}
+		for _, entry := range entries {
+			if entry.Notes == "" {
+				continue
+			}
+			target := path.Join(infoDir, entry.Path)
+			winner, annotated := annotations[joinRoot(root, target)]
+			if !annotated {
+				continue
+			}
+
+			isWinner := winner.IncludedBy == "" && relativeTo(root, winner.InfoFile) == infoFile && winner.Line == entry.Line
+			destFile, destName := destination(target)
+			if isWinner && destFile == infoFile {
+				continue
+			}
+			if isWinner && shadowedByInclude(target, path.Dir(destFile), includers[target]) {
+				continue // Moving it would hand the path to an included entry
+			}
+
+			index := entry.Line - 1
+			if removed[infoFile] == nil {
+				removed[infoFile] = make(map[int]bool)
+			}
+			removed[infoFile][index] = true
+			start := entryMarkers(lines[infoFile], index)
+			for i := start; i < index; i++ {
+				removed[infoFile][i] = true
+			}
+			if isWinner {
+				markers := lines[infoFile][start:index]
+				incoming[destFile] = append(incoming[destFile], relocatedEntry{name: destName, notes: entry.Notes, syntax: entry.Syntax, markers: markers})
+			}
+		}
+	}
+
+	changedFiles := make(map[string]bool)
+	for infoFile := range removed {
+		changedFiles[infoFile] = true
+	}
+	for infoFile := range incoming {
+		changedFiles[infoFile] = true
+	}
+
+	var rewrites []Rewrite
+	for infoFile := range changedFiles {
+		var kept []string
+		for i, line := range lines[infoFile] {
+			if !removed[infoFile][i] {
+				kept = append(kept, line)
+			}
+		}
+		// Moved entries follow the destination's layout, above its closing blank lines
+		output, tail := splitTrailingBlank(kept)
+		style := detectStyle(output)
+		entries := incoming[infoFile]
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+		for _, entry := range entries {
+			output = append(output, entry.markers...)
+			output = append(output, style.format(entry.name, entry.notes, entry.syntax))
+		}
+		output = append(output, tail...)
+
+		rewrite := Rewrite{InfoFile: infoFile, Before: contents[infoFile]}
+		if strings.TrimSpace(strings.Join(output, "\n")) != "" {
+			// New files get "\n" endings; existing ones keep their BOM and endings
+			rewrite.After = splitLines(contents[infoFile]).join(output)
+		}
+		if rewrite.After != nil && bytes.Equal(rewrite.Before, rewrite.After) {
+			continue
+		}
+		rewrites = append(rewrites, rewrite)
+	}
+
+	sort.Slice(rewrites, func(i, j int) bool { return rewrites[i].InfoFile < rewrites[j].InfoFile })
+	return rewrites, nil
+}
+
+// includedTargets returns the paths, relative to root, annotated by exact entries the
+// @include directives of infoFile bring in
+func includedTargets(fs afero.Fs, root, infoFile string, content []byte) []string {
+	includes, err := ParseIncludes(content)
+	if err != nil || len(includes) == 0 {
+		return nil
+	}
+	included, _ := resolveIncludes(fs, root, infoFile, includes)
+	var targets []string
+	for _, entry := range included {
+		if entry.Notes != "" && !isPattern(entry.Path) {
+			targets = append(targets, path.Join(path.Dir(infoFile), entry.Path))
+		}
+	}
+	return targets
+}
+
+// shadowedByInclude reports whether an entry for target moved into a .info file in
+// destDir would lose to one brought in by includes in the directories includerDirs,
+// ranked as Gather ranks them
+func shadowedByInclude(target, destDir string, includerDirs []string) bool {
+	distance := pathDepth(target) - pathDepth(destDir)
+	for _, dir := range includerDirs {
+		if dir == destDir {
+			continue // A file's own entries outrank those it includes
+		}
+		includerDistance := pathDepth(target) - pathDepth(dir)
+		if includerDistance < distance || (includerDistance == distance && dir < destDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// relativeTo expresses a slash path produced by walking root relative to root
+func relativeTo(root, p string) string {
+	rel, err := filepath.Rel(root, filepath.FromSlash(p))
+	if err != nil {
+		return p
+	}
+	return filepath.ToSlash(rel)
+}
+
+// joinRoot turns a path relative to root into the key Gather uses
+func joinRoot(root, target string) string {
+	return path.Join(filepath.ToSlash(root), target)
+}
@@ -0,0 +1,154 @@
+package infofile_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins/infofile"
+)
+
+func newRelocateProject() *testutil.TestFS {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":     "# Project notes\nREADME.md  Overview\ncmd/root.go  Shadowed\nmissing.go  Gone\n",
+		"README.md": "# project",
+		"cmd": map[string]interface{}{
+			".info":   ".  CLI\nroot.go: Root command\n# treex:generated go-doc\nsub  Subcommands\n",
+			"root.go": "package cmd",
+			"sub":     map[string]interface{}{"run.go": "package sub"},
+		},
+	})
+	return fs
+}
+
+func TestPlanGather(t *testing.T) {
+	fs := newRelocateProject()
+
+	rewrites, err := infofile.PlanGather(fs, "/project")
+	require.NoError(t, err)
+	require.Len(t, rewrites, 2)
+
+	assert.Equal(t, ".info", rewrites[0].InfoFile)
+	assert.Equal(t, "# Project notes\nREADME.md  Overview\nmissing.go  Gone\ncmd  CLI\ncmd/root.go: Root command\n# treex:generated go-doc\ncmd/sub  Subcommands\n", string(rewrites[0].After))
+	assert.Equal(t, "cmd/.info", rewrites[1].InfoFile)
+	assert.Nil(t, rewrites[1].After, "emptied .info files are removed")
+
+	require.NoError(t, infofile.ApplyRewrites(fs, "/project", rewrites))
+	exists, _ := afero.Exists(fs, "/project/cmd/.info")
+	assert.False(t, exists)
+
+	again, err := infofile.PlanGather(fs, "/project")
+	require.NoError(t, err)
+	assert.Empty(t, again, "gathering twice changes nothing")
+}
+
+func TestPlanGatherIgnoresOverlays(t *testing.T) {
+	fs := newRelocateProject()
+	require.NoError(t, afero.WriteFile(fs, "/project/.info.local", []byte("cmd/root.go  My notes\n"), 0o644))
+
+	rewrites, err := infofile.PlanGather(fs, "/project")
+	require.NoError(t, err)
+	require.Len(t, rewrites, 2)
+	assert.Contains(t, string(rewrites[0].After), "cmd/root.go: Root command", "an overlay entry does not shadow shared ones")
+}
+
+func TestPlanGatherCarriesReviewMarkers(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info": "README.md  Overview\n",
+		"cmd": map[string]interface{}{
+			".info":   "# treex:reviewed ada 2026-03-14\n# treex:generated go-doc\nroot.go  Root command\n",
+			"root.go": "package cmd",
+		},
+	})
+
+	rewrites, err := infofile.PlanGather(fs, "/project")
+	require.NoError(t, err)
+	require.Len(t, rewrites, 2)
+	assert.Equal(t, "README.md  Overview\n# treex:reviewed ada 2026-03-14\n# treex:generated go-doc\ncmd/root.go  Root command\n", string(rewrites[0].After))
+}
+
+func TestPlanGatherKeepsWindowsLineEndings(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":     "\ufeffREADME.md  Overview\r\n",
+		"README.md": "# project",
+		"cmd": map[string]interface{}{
+			".info":   "root.go  Root command\r\n",
+			"root.go": "package cmd",
+		},
+	})
+
+	rewrites, err := infofile.PlanGather(fs, "/project")
+	require.NoError(t, err)
+	require.Len(t, rewrites, 2)
+	assert.Equal(t, "\ufeffREADME.md  Overview\r\ncmd/root.go  Root command\r\n", string(rewrites[0].After))
+}
+
+func TestPlanDistribute(t *testing.T) {
+	fs := newRelocateProject()
+	gathered, err := infofile.PlanGather(fs, "/project")
+	require.NoError(t, err)
+	require.NoError(t, infofile.ApplyRewrites(fs, "/project", gathered))
+
+	rewrites, err := infofile.PlanDistribute(fs, "/project")
+	require.NoError(t, err)
+	require.Len(t, rewrites, 2)
+
+	assert.Equal(t, ".info", rewrites[0].InfoFile)
+	assert.Equal(t, "# Project notes\nREADME.md  Overview\nmissing.go  Gone\ncmd  CLI\n", string(rewrites[0].After))
+	assert.Equal(t, "cmd/.info", rewrites[1].InfoFile)
+	assert.Nil(t, rewrites[1].Before)
+	assert.Equal(t, "root.go: Root command\n# treex:generated go-doc\nsub  Subcommands\n", string(rewrites[1].After))
+
+	require.NoError(t, infofile.ApplyRewrites(fs, "/project", rewrites))
+	annotations, err := infofile.Gather(fs, "/project")
+	require.NoError(t, err)
+	assert.Equal(t, "Subcommands", annotations["/project/cmd/sub"].Notes)
+	assert.Equal(t, "/project/cmd/.info", annotations["/project/cmd/sub"].InfoFile)
+}
+
+func TestPlanGatherFollowsDestinationLayout(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":     "# Overview\nREADME.md       Overview\nLICENSE         License\n\n",
+		"README.md": "# project",
+		"LICENSE":   "MIT",
+		"cmd": map[string]interface{}{
+			".info":   "root.go  Root command\n",
+			"root.go": "package cmd",
+		},
+	})
+
+	rewrites, err := infofile.PlanGather(fs, "/project")
+	require.NoError(t, err)
+	require.Len(t, rewrites, 2)
+	assert.Equal(t, "# Overview\nREADME.md       Overview\nLICENSE         License\ncmd/root.go     Root command\n\n", string(rewrites[0].After))
+}
+
+func TestPlanGatherLeavesEntriesIncludesWouldShadow(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"shared.info": "x  Shared x\n",
+		"sub": map[string]interface{}{
+			".info": "@include /shared.info\nx  own x\ny  own y\n",
+			"x":     "",
+			"y":     "",
+		},
+	})
+
+	rewrites, err := infofile.PlanGather(fs, "/project")
+	require.NoError(t, err)
+	require.Len(t, rewrites, 2)
+	assert.Equal(t, "sub/y  own y\n", string(rewrites[0].After))
+	assert.Equal(t, "@include /shared.info\nx  own x\n", string(rewrites[1].After))
+
+	require.NoError(t, infofile.ApplyRewrites(fs, "/project", rewrites))
+	annotations, err := infofile.Gather(fs, "/project")
+	require.NoError(t, err)
+	assert.Equal(t, "own x", annotations["/project/sub/x"].Notes)
+	assert.Equal(t, "own y", annotations["/project/sub/y"].Notes)
+}
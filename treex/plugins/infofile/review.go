@@ -0,0 +1,173 @@
+package infofile
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// ReviewMarker starts the comment line recording the last review of the entry below it,
+// followed by the reviewer and the date: "# treex:reviewed Ada Lovelace 2026-03-14". It
+// sits directly above the entry, or above the entry's GeneratedMarker.
+const ReviewMarker = "# treex:reviewed"
+
+// Review is the last review of an annotation
+type Review struct {
+	By string    `json:"reviewedBy"` // Reviewer, as recorded in the marker
+	At time.Time `json:"reviewedAt"` // Review date (UTC midnight)
+}
+
+// ReviewEntry is an entry of a .info file along with its last review
+type ReviewEntry struct {
+	InfoFile string  `json:"infoFile"`         // .info file path relative to root
+	Line     int     `json:"line"`             // Line of the entry in InfoFile
+	Path     string  `json:"path"`             // Annotated path relative to root
+	Notes    string  `json:"notes"`            // Annotation text
+	Review   *Review `json:"review,omitempty"` // Last review (nil when never reviewed)
+}
+
+// Reviews lists the entries with annotation text of the .info files below root, with
+// their last review, sorted by .info file and line. Entries brought in by @include are
+// reviewed in the file holding them.
+func Reviews(fs afero.Fs, root string) ([]ReviewEntry, error) {
+	infoFiles, err := listInfoFiles(fs, root)
+	if err != nil {
+		return nil, err
+	}
+
+	var reviews []ReviewEntry
+	for _, infoFile := range infoFiles {
+		content, err := afero.ReadFile(fs, filepath.Join(root, filepath.FromSlash(infoFile)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", infoFile, err)
+		}
+		entries, err := Parse(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", infoFile, err)
+		}
+		lines := splitLines(content).lines
+		for _, entry := range entries {
+			if entry.Notes == "" {
+				continue
+			}
+			review := ReviewEntry{InfoFile: infoFile, Line: entry.Line, Path: path.Join(path.Dir(infoFile), entry.Path), Notes: entry.Notes}
+			if index := reviewMarkerIndex(lines, entry.Line-1); index >= 0 {
+				review.Review = parseReviewMarker(lines[index])
+			}
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews, nil
+}
+
+// MarkReviewed records a review by reviewer on at above each entry (InfoFile and Line),
+// replacing the review recorded before. Lines that no longer hold an entry are skipped.
+// Returns the .info files rewritten, relative to root, sorted.
+func MarkReviewed(fs afero.Fs, root string, entries []ReviewEntry, reviewer string, at time.Time) ([]string, error) {
+	reviewer = strings.Join(strings.Fields(reviewer), " ")
+	if reviewer == "" {
+		return nil, fmt.Errorf("a review needs a reviewer")
+	}
+	marker := fmt.Sprintf("%s %s %s", ReviewMarker, reviewer, at.Format(time.DateOnly))
+
+	linesByFile := make(map[string][]int)
+	for _, entry := range entries {
+		linesByFile[entry.InfoFile] = append(linesByFile[entry.InfoFile], entry.Line)
+	}
+	infoFiles := make([]string, 0, len(linesByFile))
+	for infoFile := range linesByFile {
+		infoFiles = append(infoFiles, infoFile)
+	}
+	sort.Strings(infoFiles)
+
+	for _, infoFile := range infoFiles {
+		fullPath := filepath.Join(root, filepath.FromSlash(infoFile))
+		err := withLock(fs, fullPath, infoFile, func() error {
+			return markLines(fs, fullPath, infoFile, linesByFile[infoFile], marker)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return infoFiles, nil
+}
+
+// markLines writes marker above the entries on the given 1-based lines of a .info file
+func markLines(fs afero.Fs, fullPath, infoFile string, entryLines []int, marker string) error {
+	content, err := afero.ReadFile(fs, fullPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", infoFile, err)
+	}
+	file := splitLines(content)
+	lines := file.lines
+
+	// From the last line up, so inserting a marker does not shift the lines still to mark
+	sort.Sort(sort.Reverse(sort.IntSlice(entryLines)))
+	for _, line := range entryLines {
+		index := line - 1
+		if index >= len(lines) {
+			continue
+		}
+		if _, ok := ParseLine(lines[index]); !ok {
+			continue
+		}
+		if existing := reviewMarkerIndex(lines, index); existing >= 0 {
+			lines[existing] = marker
+			continue
+		}
+		if index > 0 && isGeneratedMarker(lines[index-1]) {
+			index-- // The generated marker stays directly above its entry
+		}
+		lines = append(lines[:index], append([]string{marker}, lines[index:]...)...)
+	}
+
+	if err := writeAtomic(fs, fullPath, file.join(lines)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", infoFile, err)
+	}
+	return nil
+}
+
+// reviewMarkerIndex returns the index of the review marker of the entry at index in
+// lines, or -1 when it has none
+func reviewMarkerIndex(lines []string, index int) int {
+	if start := entryMarkers(lines, index); start < index && isReviewMarker(lines[start]) {
+		return start
+	}
+	return -1
+}
+
+// isReviewMarker reports whether line is a review marker comment
+func isReviewMarker(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), ReviewMarker+" ")
+}
+
+// parseReviewMarker reads a review marker; nil when it lacks a reviewer or a valid date
+func parseReviewMarker(line string) *Review {
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), ReviewMarker))
+	if len(fields) < 2 {
+		return nil
+	}
+	at, err := time.Parse(time.DateOnly, fields[len(fields)-1])
+	if err != nil {
+		return nil
+	}
+	return &Review{By: strings.Join(fields[:len(fields)-1], " "), At: at}
+}
+
+// entryMarkers returns the index of the first of the marker comments (review, then
+// generated) directly above the entry at index in lines; index itself when it has none
+func entryMarkers(lines []string, index int) int {
+	start := index
+	if start > 0 && isGeneratedMarker(lines[start-1]) {
+		start--
+	}
+	if start > 0 && isReviewMarker(lines[start-1]) {
+		start--
+	}
+	return start
+}
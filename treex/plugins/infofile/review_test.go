@@ -0,0 +1,82 @@
+package infofile_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins/infofile"
+)
+
+func newReviewProject() *testutil.TestFS {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info": "# treex:reviewed Ada Lovelace 2026-03-14\nREADME.md  Overview\nmain.go  Entry point\n" +
+			"# treex:reviewed bob not-a-date\nMakefile  Build\nnotes.txt\n",
+		"README.md": "# project",
+		"main.go":   "package main",
+		"Makefile":  "all:",
+		"notes.txt": "",
+		"src": map[string]interface{}{
+			".info":   "# treex:reviewed carol 2025-01-02\n# treex:generated go-doc\nutil.go  Helpers\n",
+			"util.go": "package src",
+		},
+	})
+	return fs
+}
+
+func TestReviews(t *testing.T) {
+	fs := newReviewProject()
+
+	reviews, err := infofile.Reviews(fs, "/project")
+	require.NoError(t, err)
+	assert.Equal(t, []infofile.ReviewEntry{
+		{InfoFile: ".info", Line: 2, Path: "README.md", Notes: "Overview", Review: &infofile.Review{By: "Ada Lovelace", At: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)}},
+		{InfoFile: ".info", Line: 3, Path: "main.go", Notes: "Entry point"},
+		{InfoFile: ".info", Line: 5, Path: "Makefile", Notes: "Build"}, // Malformed review
+		{InfoFile: "src/.info", Line: 3, Path: "src/util.go", Notes: "Helpers", Review: &infofile.Review{By: "carol", At: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)}},
+	}, reviews)
+}
+
+func TestMarkReviewed(t *testing.T) {
+	fs := newReviewProject()
+	reviews, err := infofile.Reviews(fs, "/project")
+	require.NoError(t, err)
+
+	written, err := infofile.MarkReviewed(fs, "/project", reviews, " Dana  Scully ", time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []string{".info", "src/.info"}, written)
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "# treex:reviewed Dana Scully 2026-10-15\nREADME.md  Overview\n"+
+		"# treex:reviewed Dana Scully 2026-10-15\nmain.go  Entry point\n"+
+		"# treex:reviewed Dana Scully 2026-10-15\nMakefile  Build\nnotes.txt\n", string(content))
+	content, err = afero.ReadFile(fs, "/project/src/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "# treex:reviewed Dana Scully 2026-10-15\n# treex:generated go-doc\nutil.go  Helpers\n", string(content),
+		"the generated marker stays above its entry")
+
+	_, err = infofile.MarkReviewed(fs, "/project", reviews, " ", time.Now())
+	assert.Error(t, err, "a reviewer is required")
+}
+
+func TestFixIssuesRemovesMarkers(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":     "# treex:reviewed ada 2026-03-14\n# treex:generated go-doc\ngone.go  Removed\nREADME.md  Overview\n",
+		"README.md": "# project",
+	})
+
+	issues, err := infofile.Validate(fs, "/project")
+	require.NoError(t, err)
+	_, err = infofile.FixIssues(fs, "/project", issues)
+	require.NoError(t, err)
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "README.md  Overview\n", string(content), "markers do not outlive their entry")
+}
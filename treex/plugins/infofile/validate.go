@@ -0,0 +1,274 @@
+package infofile
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"treex/treex/pathutil"
+)
+
+// IssueType classifies the problems found in .info files
+type IssueType string
+
+const (
+	IssueMissingPath IssueType = "missing-path"  // Entry annotates a path that does not exist
+	IssueNoText      IssueType = "no-text"       // Entry has no annotation text
+	IssueDuplicate   IssueType = "duplicate"     // Entry repeats a path annotated earlier in the file
+	IssueOutsideDir  IssueType = "outside-dir"   // Entry annotates a path above the .info file's directory
+	IssueProse       IssueType = "prose"         // Annotation breaks a writing rule (see CheckProse)
+	IssueLineTooLong IssueType = "line-too-long" // Line longer than MaxLineSize; later lines are not read
+
+	// IssueBrokenReference is an annotation linking to a path that does not exist (see References)
+	IssueBrokenReference IssueType = "broken-reference"
+	// IssueBrokenInclude is an @include of a file that cannot be read, directly or through
+	// the files it includes
+	IssueBrokenInclude IssueType = "broken-include"
+	// IssueIncludeCycle is an @include leading back to a file it is included from
+	IssueIncludeCycle IssueType = "include-cycle"
+)
+
+// Issue is a problem found in a .info file
+type Issue struct {
+	InfoFile string    `json:"infoFile"` // .info file path relative to the validated root
+	Line     int       `json:"line"`     // 1-based line number
+	Path     string    `json:"path"`     // Annotated path relative to the validated root
+	Message  string    `json:"message"`
+	Type     IssueType `json:"type,omitempty"` // Kind of problem (empty for issues found outside .info validation)
+}
+
+// Removable reports whether FixIssues repairs the issue by removing its line; broken
+// references, includes and prose problems need the line edited instead
+func (i Issue) Removable() bool {
+	switch i.Type {
+	case IssueProse, IssueBrokenReference, IssueLineTooLong, IssueBrokenInclude, IssueIncludeCycle:
+		return false
+	}
+	return true
+}
+
+// Validate checks every .info file below root line by line and reports entries that
+// annotate missing paths or paths above their directory, have no annotation text,
+// repeat a path in the same file, or link to paths that do not exist (glob entries:
+// match no path), and @include directives that cannot be followed. LocalFileName
+// overlays are checked the same way. Included entries are not checked: a shared file
+// may annotate paths only some of the directories including it have.
+// Issues are sorted by .info file and line
+func Validate(fs afero.Fs, root string) ([]Issue, error) {
+	var issues []Issue
+
+	err := afero.Walk(fs, root, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			if filePath == root {
+				return err
+			}
+			slog.Warn("skipping unreadable directory while validating", "path", filePath, "error", err)
+			return nil
+		}
+		if info.IsDir() || (info.Name() != ".info" && info.Name() != LocalFileName) {
+			return nil
+		}
+
+		fileIssues, err := validateInfoFile(fs, root, filePath)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, fileIssues...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate .info files in %s: %w", root, err)
+	}
+
+	sortIssues(issues)
+	return issues, nil
+}
+
+// ValidateFiles checks only the given .info files (paths relative to root), as Validate
+// does for every file. Files that no longer exist are skipped.
+func ValidateFiles(fs afero.Fs, root string, infoFiles []string) ([]Issue, error) {
+	var issues []Issue
+	for _, infoFile := range infoFiles {
+		fullPath := filepath.Join(root, filepath.FromSlash(infoFile))
+		if exists, _ := afero.Exists(fs, fullPath); !exists {
+			continue
+		}
+		fileIssues, err := validateInfoFile(fs, root, fullPath)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, fileIssues...)
+	}
+
+	sortIssues(issues)
+	return issues, nil
+}
+
+// FixIssues removes the lines reported by issues, the repair for every kind Validate
+// finds: entries without text, for missing paths or paths above their directory, or
+// repeating an earlier entry.
+// Issues that are not Removable are ignored.
+// Returns the .info files rewritten, relative to root, sorted.
+func FixIssues(fs afero.Fs, root string, issues []Issue) ([]string, error) {
+	linesByFile := make(map[string]map[int]bool)
+	for _, issue := range issues {
+		if !issue.Removable() {
+			continue
+		}
+		if linesByFile[issue.InfoFile] == nil {
+			linesByFile[issue.InfoFile] = make(map[int]bool)
+		}
+		linesByFile[issue.InfoFile][issue.Line] = true
+	}
+
+	infoFiles := make([]string, 0, len(linesByFile))
+	for infoFile := range linesByFile {
+		infoFiles = append(infoFiles, infoFile)
+	}
+	sort.Strings(infoFiles)
+
+	for _, infoFile := range infoFiles {
+		fullPath := filepath.Join(root, filepath.FromSlash(infoFile))
+		err := withLock(fs, fullPath, infoFile, func() error {
+			return removeLines(fs, fullPath, infoFile, linesByFile[infoFile])
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return infoFiles, nil
+}
+
+// removeLines rewrites a .info file without the given 1-based lines and the marker
+// comments directly above them
+func removeLines(fs afero.Fs, fullPath, infoFile string, remove map[int]bool) error {
+	content, err := afero.ReadFile(fs, fullPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", infoFile, err)
+	}
+	file := splitLines(content)
+	removed := make(map[int]bool, len(remove))
+	for line := range remove {
+		if index := line - 1; index < len(file.lines) {
+			for i := entryMarkers(file.lines, index); i <= index; i++ {
+				removed[i] = true
+			}
+		}
+	}
+	kept := make([]string, 0, len(file.lines))
+	for i, line := range file.lines {
+		if !removed[i] {
+			kept = append(kept, line)
+		}
+	}
+	var output []byte
+	if strings.TrimSpace(strings.Join(kept, "")) != "" {
+		output = file.join(kept)
+	}
+	if err := writeAtomic(fs, fullPath, output); err != nil {
+		return fmt.Errorf("failed to write %s: %w", infoFile, err)
+	}
+	return nil
+}
+
+// validateInfoFile checks the entries of a single .info file
+func validateInfoFile(fs afero.Fs, root, infoPath string) ([]Issue, error) {
+	content, err := afero.ReadFile(fs, infoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", infoPath, err)
+	}
+
+	relativeInfo, err := filepath.Rel(root, infoPath)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateContent(fs, root, pathutil.Normalize(relativeInfo), content), nil
+}
+
+// ValidateContent checks content as the .info file infoFile (relative to root) the way
+// Validate checks files on disk, for editors validating unsaved changes
+// Issues are in line order.
+func ValidateContent(fs afero.Fs, root, infoFile string, content []byte) []Issue {
+	infoDir := path.Dir(infoFile)
+
+	var issues []Issue
+	seen := make(map[string]int)
+	entries, err := Parse(content)
+	for _, entry := range entries {
+		targetPath := path.Join(infoDir, entry.Path)
+		issue := Issue{InfoFile: infoFile, Line: entry.Line, Path: targetPath}
+
+		if entry.Notes == "" {
+			issue.Message, issue.Type = "annotation has no text", IssueNoText
+			issues = append(issues, issue)
+		}
+
+		if entry.Path == ".." || strings.HasPrefix(path.Clean(entry.Path), "../") {
+			issue.Message, issue.Type = "annotated path is outside the .info file's directory", IssueOutsideDir
+			issues = append(issues, issue)
+		} else if exists, _ := afero.Exists(fs, filepath.Join(root, filepath.FromSlash(targetPath))); !exists {
+			if message := missingPathMessage(fs, filepath.Join(root, filepath.FromSlash(infoDir)), entry.Path); message != "" {
+				issue.Message, issue.Type = message, IssueMissingPath
+				issues = append(issues, issue)
+			}
+		}
+
+		if first, duplicate := seen[targetPath]; duplicate {
+			issue.Message, issue.Type = fmt.Sprintf("path already annotated on line %d", first), IssueDuplicate
+			issues = append(issues, issue)
+		} else {
+			seen[targetPath] = entry.Line
+		}
+
+		for _, ref := range References(entry.Notes) {
+			refPath := resolveReference(infoDir, ref)
+			if exists, _ := afero.Exists(fs, filepath.Join(root, filepath.FromSlash(refPath))); !exists {
+				issue.Message, issue.Type = fmt.Sprintf("annotation references missing path %q", ref), IssueBrokenReference
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	includes, _ := ParseIncludes(content) // Stops where Parse does
+	_, problems := resolveIncludes(fs, root, infoFile, includes)
+	for _, problem := range problems {
+		issue := Issue{InfoFile: infoFile, Line: problem.Line, Path: infoDir, Message: problem.err.Error(), Type: IssueBrokenInclude}
+		if problem.Path != "" {
+			issue.Path = resolveReference(infoDir, problem.Path)
+		}
+		var cycle *includeCycleError
+		if errors.As(problem.err, &cycle) {
+			issue.Type = IssueIncludeCycle
+		}
+		issues = append(issues, issue)
+	}
+	sortIssues(issues)
+
+	var tooLong *LineTooLongError
+	if errors.As(err, &tooLong) {
+		issues = append(issues, Issue{
+			InfoFile: infoFile,
+			Line:     tooLong.Line,
+			Path:     infoDir,
+			Message:  fmt.Sprintf("line is longer than %d bytes; it and the lines after it are not read", tooLong.Limit),
+			Type:     IssueLineTooLong,
+		})
+	}
+	return issues
+}
+
+// sortIssues orders issues by .info file and line
+func sortIssues(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].InfoFile != issues[j].InfoFile {
+			return issues[i].InfoFile < issues[j].InfoFile
+		}
+		return issues[i].Line < issues[j].Line
+	})
+}
@@ -0,0 +1,91 @@
+package infofile_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins/infofile"
+)
+
+func TestValidate(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":     "# comment\nREADME.md  Overview\nold.go  Removed file\nsrc\nREADME.md\tAgain\n",
+		"README.md": "# project",
+		"src": map[string]interface{}{
+			".info":   "main.go  Entry point\n",
+			"main.go": "package main",
+		},
+	})
+
+	issues, err := infofile.Validate(fs, "/project")
+	require.NoError(t, err)
+
+	assert.Equal(t, []infofile.Issue{
+		{InfoFile: ".info", Line: 3, Path: "old.go", Message: "annotated path does not exist", Type: infofile.IssueMissingPath},
+		{InfoFile: ".info", Line: 4, Path: "src", Message: "annotation has no text", Type: infofile.IssueNoText},
+		{InfoFile: ".info", Line: 5, Path: "README.md", Message: "path already annotated on line 2", Type: infofile.IssueDuplicate},
+	}, issues)
+}
+
+func TestValidateNoInfoFiles(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{"main.go": "package main"})
+
+	issues, err := infofile.Validate(fs, "/project")
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestValidateContent(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"README.md": "# project",
+		"src":       map[string]interface{}{"main.go": "package main"},
+	})
+
+	// Unsaved content is validated as if it were src/.info
+	issues := infofile.ValidateContent(fs, "/project", "src/.info", []byte("main.go  Entry point\n../README.md  Overview\n..  The project\nlib.go  Library\n"))
+	assert.Equal(t, []infofile.Issue{
+		{InfoFile: "src/.info", Line: 2, Path: "README.md", Message: "annotated path is outside the .info file's directory", Type: infofile.IssueOutsideDir},
+		{InfoFile: "src/.info", Line: 3, Path: ".", Message: "annotated path is outside the .info file's directory", Type: infofile.IssueOutsideDir},
+		{InfoFile: "src/.info", Line: 4, Path: "src/lib.go", Message: "annotated path does not exist", Type: infofile.IssueMissingPath},
+	}, issues)
+	assert.True(t, issues[0].Removable())
+}
+
+func TestValidateContentLineTooLong(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{"src": map[string]interface{}{"main.go": "package main"}})
+	original := infofile.MaxLineSize
+	infofile.MaxLineSize = 32
+	t.Cleanup(func() { infofile.MaxLineSize = original })
+
+	issues := infofile.ValidateContent(fs, "/project", "src/.info", []byte("main.go  Entry point\nlib.go  "+strings.Repeat("x", 40)+"\ngone.go\n"))
+	assert.Equal(t, []infofile.Issue{
+		{InfoFile: "src/.info", Line: 2, Path: "src", Message: "line is longer than 32 bytes; it and the lines after it are not read", Type: infofile.IssueLineTooLong},
+	}, issues)
+	assert.False(t, issues[0].Removable(), "--fix never drops generated content")
+}
+
+func TestFixIssuesKeepsWindowsLineEndings(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":   "\ufeffgone.go  Removed\r\nmain.go  Entry point\r\n",
+		"main.go": "package main",
+	})
+
+	issues, err := infofile.Validate(fs, "/project")
+	require.NoError(t, err)
+	require.Len(t, issues, 1, "the first line has no phantom issue")
+	_, err = infofile.FixIssues(fs, "/project", issues)
+	require.NoError(t, err)
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "\ufeffmain.go  Entry point\r\n", string(content))
+}
@@ -0,0 +1,189 @@
+package infofile
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"treex/treex/pathutil"
+)
+
+// AddAnnotation writes an annotation for targetPath (relative to root) into the .info
+// file of the target's parent directory. An existing entry for the same name in that
+// file is replaced; otherwise the entry is appended. Returns the .info path relative to root.
+func AddAnnotation(fs afero.Fs, root, targetPath, notes string) (string, error) {
+	written, err := AddAnnotations(fs, root, []Annotation{{Path: targetPath, Notes: notes}})
+	if err != nil {
+		return "", err
+	}
+	return written[0], nil
+}
+
+// AddAnnotations writes many annotations at once, each into the .info file of its
+// target's parent directory as AddAnnotation does. Paths are relative to root; InfoFile
+// and Line are ignored. Every target is checked before anything is written and each
+// .info file is rewritten once. Returns the .info paths written, relative to root, sorted.
+func AddAnnotations(fs afero.Fs, root string, annotations []Annotation) ([]string, error) {
+	entries := make([]pendingEntry, len(annotations))
+	for i, annotation := range annotations {
+		entries[i] = pendingEntry{path: annotation.Path, notes: annotation.Notes}
+	}
+	written, _, err := writeEntries(fs, root, entries)
+	return written, err
+}
+
+// pendingEntry is an annotation waiting to be written; generated entries carry the
+// source named in their marker comment
+type pendingEntry struct {
+	path   string
+	notes  string
+	source string
+}
+
+// writeEntries checks every target, then rewrites each affected .info file once
+// Returns the .info paths written (sorted) and the generated entries kept because
+// they would replace a hand-written one
+func writeEntries(fs afero.Fs, root string, entries []pendingEntry) ([]string, []string, error) {
+	byInfoFile := make(map[string][]pendingEntry)
+	for _, entry := range entries {
+		target, notes, err := checkAnnotation(fs, root, entry.path, entry.notes)
+		if err != nil {
+			return nil, nil, err
+		}
+		infoPath := path.Join(path.Dir(target), ".info")
+		byInfoFile[infoPath] = append(byInfoFile[infoPath], pendingEntry{path: path.Base(target), notes: notes, source: entry.source})
+	}
+
+	infoPaths := make([]string, 0, len(byInfoFile))
+	for infoPath := range byInfoFile {
+		infoPaths = append(infoPaths, infoPath)
+	}
+	sort.Strings(infoPaths)
+
+	var written, kept []string
+	for _, infoPath := range infoPaths {
+		var keptNames []string
+		var changed bool
+		fullInfoPath := filepath.Join(root, filepath.FromSlash(infoPath))
+		err := withLock(fs, fullInfoPath, infoPath, func() error {
+			var err error
+			keptNames, changed, err = updateInfoFile(fs, root, infoPath, byInfoFile[infoPath])
+			return err
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, name := range keptNames {
+			kept = append(kept, path.Join(path.Dir(infoPath), name))
+		}
+		if changed {
+			written = append(written, infoPath)
+		}
+	}
+	return written, kept, nil
+}
+
+// checkAnnotation validates a target inside root, returning its normalized path and notes
+func checkAnnotation(fs afero.Fs, root, targetPath, notes string) (string, string, error) {
+	target := pathutil.Normalize(targetPath)
+	if target == "." || target == ".." || strings.HasPrefix(target, "../") {
+		return "", "", fmt.Errorf("cannot annotate %q: path must be inside the root", targetPath)
+	}
+
+	notes = strings.Join(strings.Fields(notes), " ")
+	if notes == "" {
+		return "", "", fmt.Errorf("cannot annotate %q: annotation text is empty", targetPath)
+	}
+
+	if exists, _ := afero.Exists(fs, filepath.Join(root, filepath.FromSlash(target))); !exists {
+		return "", "", fmt.Errorf("cannot annotate %q: path does not exist", targetPath)
+	}
+	return target, notes, nil
+}
+
+// updateInfoFile replaces or appends entries (names relative to the .info directory)
+// The first existing entry for a name gets the new annotation, keeping its path and the
+// whitespace after it as written; new entries follow the file's layout (see
+// detectStyle), above any blank lines ending it. Other lines are kept byte for byte.
+// Generated entries are written below a marker comment and never replace an entry
+// without one; a hand-written replacement drops the marker so the entry is not refreshed.
+// Callers hold the lock of the .info file (see withLock).
+func updateInfoFile(fs afero.Fs, root, infoPath string, entries []pendingEntry) ([]string, bool, error) {
+	fullInfoPath := filepath.Join(root, filepath.FromSlash(infoPath))
+	content, err := afero.ReadFile(fs, fullInfoPath)
+	if err != nil {
+		if exists, _ := afero.Exists(fs, fullInfoPath); exists {
+			return nil, false, fmt.Errorf("failed to read %s: %w", infoPath, err)
+		}
+	}
+
+	file := splitLines(content)
+	lines, tail := splitTrailingBlank(file.lines)
+	style := detectStyle(lines)
+
+	type existing struct {
+		index     int
+		syntax    Syntax
+		generated bool
+	}
+	byName := make(map[string]existing)
+	parsed, err := Parse(content)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", infoPath, err)
+	}
+	for _, entry := range parsed {
+		name := pathutil.Normalize(entry.Path)
+		if _, seen := byName[name]; !seen {
+			index := entry.Line - 1
+			generated := index > 0 && isGeneratedMarker(lines[index-1])
+			byName[name] = existing{index: index, syntax: entry.Syntax, generated: generated}
+		}
+	}
+
+	var kept []string
+	removed := make(map[int]bool)
+	changed := false
+	for _, entry := range entries {
+		previous, found := byName[entry.path]
+		switch {
+		case found && entry.source != "" && !previous.generated:
+			kept = append(kept, entry.path)
+			continue
+		case found && entry.source != "":
+			lines[previous.index-1] = generatedMarker(entry.source)
+			lines[previous.index] = replaceNotes(lines[previous.index], entry.path, entry.notes, previous.syntax, style)
+		case found:
+			lines[previous.index] = replaceNotes(lines[previous.index], entry.path, entry.notes, previous.syntax, style)
+			if previous.generated {
+				removed[previous.index-1] = true
+				previous.generated = false
+				byName[entry.path] = previous
+			}
+		case entry.source != "":
+			lines = append(lines, generatedMarker(entry.source), style.format(entry.path, entry.notes, SyntaxSpace))
+			byName[entry.path] = existing{index: len(lines) - 1, syntax: SyntaxSpace, generated: true}
+		default:
+			lines = append(lines, style.format(entry.path, entry.notes, SyntaxSpace))
+			byName[entry.path] = existing{index: len(lines) - 1, syntax: SyntaxSpace}
+		}
+		changed = true
+	}
+	if !changed {
+		return kept, false, nil
+	}
+
+	output := make([]string, 0, len(lines)+len(tail))
+	for i, line := range lines {
+		if !removed[i] {
+			output = append(output, line)
+		}
+	}
+	output = append(output, tail...)
+	if err := writeAtomic(fs, fullInfoPath, file.join(output)); err != nil {
+		return nil, false, fmt.Errorf("failed to write %s: %w", infoPath, err)
+	}
+	return kept, true, nil
+}
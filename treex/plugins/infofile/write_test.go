@@ -0,0 +1,207 @@
+package infofile_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins/infofile"
+)
+
+func TestAddAnnotation(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"README.md": "# project",
+		"src": map[string]interface{}{
+			".info":   "# sources\nmain.go  Old text\n",
+			"main.go": "package main",
+			"util.go": "package main",
+		},
+	})
+
+	t.Run("creates .info file", func(t *testing.T) {
+		infoPath, err := infofile.AddAnnotation(fs, "/project", "README.md", "Project   overview\n")
+		require.NoError(t, err)
+		assert.Equal(t, ".info", infoPath)
+
+		content, err := afero.ReadFile(fs, "/project/.info")
+		require.NoError(t, err)
+		assert.Equal(t, "README.md  Project overview\n", string(content))
+	})
+
+	t.Run("replaces existing entry", func(t *testing.T) {
+		infoPath, err := infofile.AddAnnotation(fs, "/project", "./src/main.go", "Entry point")
+		require.NoError(t, err)
+		assert.Equal(t, "src/.info", infoPath)
+
+		content, err := afero.ReadFile(fs, "/project/src/.info")
+		require.NoError(t, err)
+		assert.Equal(t, "# sources\nmain.go  Entry point\n", string(content))
+	})
+
+	t.Run("appends new entry", func(t *testing.T) {
+		_, err := infofile.AddAnnotation(fs, "/project", "src/util.go", "Helpers")
+		require.NoError(t, err)
+
+		content, err := afero.ReadFile(fs, "/project/src/.info")
+		require.NoError(t, err)
+		assert.Equal(t, "# sources\nmain.go  Entry point\nutil.go  Helpers\n", string(content))
+	})
+}
+
+func TestAddAnnotationKeepsLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		target   string
+		expected string
+	}{
+		{
+			name:     "aligned annotations",
+			before:   "# Sources\nmain.go    Entry point\nutil.go    Helpers\n",
+			target:   "config.go",
+			expected: "# Sources\nmain.go    Entry point\nutil.go    Helpers\nconfig.go  New text\n",
+		},
+		{
+			name:     "path past the aligned column",
+			before:   "a.go   First\nb.go   Second\n",
+			target:   "config.go",
+			expected: "a.go   First\nb.go   Second\nconfig.go   New text\n",
+		},
+		{
+			name:     "replaced annotation keeps its path and spacing",
+			before:   "./main.go       Old text   \n\n# Helpers\nutil.go  Helpers\n",
+			target:   "main.go",
+			expected: "./main.go       New text\n\n# Helpers\nutil.go  Helpers\n",
+		},
+		{
+			name:     "tab separator",
+			before:   "main.go\tEntry point\n",
+			target:   "util.go",
+			expected: "main.go\tEntry point\nutil.go\tNew text\n",
+		},
+		{
+			name:     "closing blank lines stay last",
+			before:   "main.go  Entry point\n\n\n",
+			target:   "util.go",
+			expected: "main.go  Entry point\nutil.go  New text\n\n\n",
+		},
+		{
+			name:     "no final newline",
+			before:   "main.go  Entry point",
+			target:   "main.go",
+			expected: "main.go  New text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := testutil.NewTestFS()
+			fs.MustCreateTree("/project", map[string]interface{}{
+				".info":     tt.before,
+				"main.go":   "package main",
+				"util.go":   "package main",
+				"config.go": "package main",
+			})
+
+			_, err := infofile.AddAnnotation(fs, "/project", tt.target, "New text")
+			require.NoError(t, err)
+
+			content, err := afero.ReadFile(fs, "/project/.info")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(content))
+		})
+	}
+}
+
+func TestAddAnnotationKeepsWindowsLineEndings(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":   "\ufeffmain.go  Old text\r\n# comment\r\n",
+		"main.go": "package main",
+		"util.go": "package main",
+	})
+
+	_, err := infofile.AddAnnotation(fs, "/project", "main.go", "Entry point")
+	require.NoError(t, err)
+	_, err = infofile.AddAnnotation(fs, "/project", "util.go", "Helpers")
+	require.NoError(t, err)
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "\ufeffmain.go  Entry point\r\n# comment\r\nutil.go  Helpers\r\n", string(content))
+}
+
+func TestAddAnnotationErrors(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{"main.go": "package main"})
+
+	tests := []struct {
+		name   string
+		target string
+		notes  string
+		errMsg string
+	}{
+		{"root", ".", "Project", "inside the root"},
+		{"outside root", "../other.go", "Other", "inside the root"},
+		{"empty notes", "main.go", "  ", "annotation text is empty"},
+		{"missing path", "missing.go", "Gone", "does not exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := infofile.AddAnnotation(fs, "/project", tt.target, tt.notes)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestAddAnnotations(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"cmd": map[string]interface{}{
+			".info":   "root.go: Old text\n",
+			"root.go": "package cmd",
+			"add.go":  "package cmd",
+		},
+		"docs": map[string]interface{}{"guide.md": "# guide"},
+	})
+
+	written, err := infofile.AddAnnotations(fs, "/project", []infofile.Annotation{
+		{Path: "cmd/root.go", Notes: "CLI entry points"},
+		{Path: "cmd/add.go", Notes: "CLI entry points"},
+		{Path: "docs/guide.md", Notes: "User guide"},
+		{Path: "docs", Notes: "Documentation"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{".info", "cmd/.info", "docs/.info"}, written)
+
+	content, err := afero.ReadFile(fs, "/project/cmd/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "root.go: CLI entry points\nadd.go  CLI entry points\n", string(content))
+
+	content, err = afero.ReadFile(fs, "/project/docs/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "guide.md  User guide\n", string(content))
+
+	content, err = afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "docs  Documentation\n", string(content))
+}
+
+func TestAddAnnotationsChecksEveryTargetFirst(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{"main.go": "package main"})
+
+	_, err := infofile.AddAnnotations(fs, "/project", []infofile.Annotation{
+		{Path: "main.go", Notes: "Entry point"},
+		{Path: "missing.go", Notes: "Gone"},
+	})
+	assert.ErrorContains(t, err, "missing.go")
+
+	exists, _ := afero.Exists(fs, "/project/.info")
+	assert.False(t, exists, "nothing is written when a target is invalid")
+}
@@ -0,0 +1,134 @@
+// Package kind provides a plugin telling binary, generated and vendored files apart from
+// the ones people write
+package kind
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/afero"
+	"treex/treex/plugins"
+	"treex/treex/types"
+)
+
+// headSize is how much of a file is read to classify it: enough for a generated header,
+// and for a NUL byte in the leading bytes, which marks binaries as it does for git
+const headSize = 1024
+
+// VendorDirs are the directories holding third-party code; they and everything below
+// them are vendored
+var VendorDirs = []string{"vendor", "third_party", "third-party", "node_modules", "bower_components"}
+
+// GeneratedNames are the globs of file names that code generators write
+var GeneratedNames = []string{"*.pb.go", "*_pb.go", "*.pb.gw.go", "*_pb2.py", "*_pb2_grpc.py", "*.pb.cc", "*.pb.h", "*_generated.go", "zz_generated*.go", "*.min.js", "*.min.css"}
+
+// generatedHeader matches the markers generators leave in the first lines of a file: Go's
+// "Code generated ... DO NOT EDIT.", and the @generated tag other ecosystems use
+var generatedHeader = regexp.MustCompile(`(?m)^(// Code generated .* DO NOT EDIT\.$|.*@generated\b)`)
+
+// KindPlugin classifies files as binary, generated or vendored, so renderers can dim them
+// and coverage statistics can leave them out
+type KindPlugin struct{}
+
+// NewKindPlugin creates a new kind plugin instance
+func NewKindPlugin() *KindPlugin {
+	return &KindPlugin{}
+}
+
+// Name returns the plugin identifier
+func (p *KindPlugin) Name() string {
+	return "kind"
+}
+
+// FindRoots returns the search root itself: files of any kind can be anywhere
+func (p *KindPlugin) FindRoots(fs afero.Fs, searchRoot string) ([]string, error) {
+	if _, err := fs.Stat(searchRoot); err != nil {
+		return nil, err
+	}
+	return []string{"."}, nil
+}
+
+// ProcessRoot categorizes nothing: kinds are attached during enrichment
+func (p *KindPlugin) ProcessRoot(fs afero.Fs, rootPath string) (*plugins.Result, error) {
+	return &plugins.Result{
+		PluginName: p.Name(),
+		RootPath:   rootPath,
+		Categories: make(map[string][]string),
+		Metadata:   make(map[string]interface{}),
+	}, nil
+}
+
+// EnrichData attaches a types.FileKind to the entries among filePaths that people do not
+// write; vendored directories are classified too, other directories never are
+// Implements DataPluginV2 interface
+func (p *KindPlugin) EnrichData(fs afero.Fs, rootPath string, filePaths []string, cache plugins.CacheMap) (plugins.DataEnrichmentMap, error) {
+	enrichmentMap := make(plugins.DataEnrichmentMap)
+	for _, filePath := range filePaths {
+		if filePath == "" || filePath == "." {
+			continue
+		}
+		if kind := Classify(fs, filepath.Join(rootPath, filePath), filepath.ToSlash(filePath)); kind != "" {
+			enrichmentMap[filePath] = kind
+		}
+	}
+	return enrichmentMap, nil
+}
+
+// Classify returns the kind of the entry at absPath, whose slash-separated path in the
+// tree is relPath: vendored by its path, else generated by its name, else binary or
+// generated by its first bytes. Directories and unreadable files get no kind unless vendored.
+func Classify(fs afero.Fs, absPath, relPath string) types.FileKind {
+	if IsVendored(relPath) {
+		return types.KindVendored
+	}
+	name := path.Base(relPath)
+	for _, glob := range GeneratedNames {
+		if matched, _ := path.Match(glob, name); matched {
+			return types.KindGenerated
+		}
+	}
+
+	file, err := fs.Open(absPath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	head := make([]byte, headSize)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "" // Directories cannot be read, and empty files are not classified
+	}
+	head = head[:n]
+
+	switch {
+	case bytes.IndexByte(head, 0) >= 0:
+		return types.KindBinary
+	case generatedHeader.Match(head):
+		return types.KindGenerated
+	}
+	return ""
+}
+
+// IsVendored reports whether the slash-separated relPath is, or is below, one of VendorDirs
+func IsVendored(relPath string) bool {
+	for _, part := range strings.Split(relPath, "/") {
+		for _, dir := range VendorDirs {
+			if part == dir {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// init registers the kind plugin with the default registry
+func init() {
+	if err := plugins.RegisterPlugin(NewKindPlugin()); err != nil {
+		log.Fatalf("failed to register kind plugin: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package kind_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins"
+	"treex/treex/plugins/kind"
+	"treex/treex/types"
+)
+
+func newProject() *testutil.TestFS {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"main.go":        "package main\n",
+		"kind_string.go": "// Code generated by \"stringer -type=Kind\"; DO NOT EDIT.\n\npackage main\n",
+		"api.pb.go":      "package main\n",
+		"schema.ts":      "/**\n * @generated by graphql-codegen\n */\n",
+		"logo.png":       "\x89PNG\r\n\x1a\n\x00\x00",
+		"empty.txt":      "",
+		"notes.md":       "Says Code generated but is prose. DO NOT EDIT.\n",
+		"vendor": map[string]interface{}{
+			"github.com": map[string]interface{}{"lib": map[string]interface{}{"lib.go": "package lib\n"}},
+		},
+		"src": map[string]interface{}{"app.js": "export {}\n"},
+	})
+	return fs
+}
+
+func TestClassify(t *testing.T) {
+	fs := newProject()
+
+	tests := []struct {
+		path     string
+		expected types.FileKind
+		desc     string
+	}{
+		{"main.go", "", "hand-written source"},
+		{"kind_string.go", types.KindGenerated, "Go generated header"},
+		{"api.pb.go", types.KindGenerated, "generated by name"},
+		{"schema.ts", types.KindGenerated, "@generated tag"},
+		{"logo.png", types.KindBinary, "NUL byte in the head"},
+		{"empty.txt", "", "empty files"},
+		{"notes.md", "", "the Go header must start a line"},
+		{"vendor", types.KindVendored, "vendor directory"},
+		{"vendor/github.com/lib/lib.go", types.KindVendored, "below a vendor directory"},
+		{"src", "", "other directories"},
+		{"missing.go", "", "unreadable files"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, kind.Classify(fs, "/project/"+tt.path, tt.path), tt.desc)
+	}
+}
+
+func TestEnrichData(t *testing.T) {
+	plugin := kind.NewKindPlugin()
+	var _ plugins.DataPluginV2 = plugin
+
+	data, err := plugin.EnrichData(newProject(), "/project", []string{".", "main.go", "api.pb.go", "logo.png", "vendor", "src", "src/app.js"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, plugins.DataEnrichmentMap{
+		"api.pb.go": types.KindGenerated,
+		"logo.png":  types.KindBinary,
+		"vendor":    types.KindVendored,
+	}, data)
+
+	node := &types.Node{Path: "api.pb.go"}
+	node.SetPluginData("kind", data["api.pb.go"])
+	assert.Equal(t, types.KindGenerated, node.GetKind())
+	assert.Empty(t, (&types.Node{Path: "main.go"}).GetKind())
+}
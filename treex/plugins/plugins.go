@@ -3,6 +3,8 @@ package plugins
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/spf13/afero"
 	"treex/treex/types"
@@ -67,6 +69,17 @@ type CachedDataPlugin interface {
 	EnrichNodeWithCache(fs afero.Fs, node *types.Node, pluginResults []*Result) error
 }
 
+// BadgePlugin extends Plugin with a short marker shown before entry names
+// Badges are read from the data the plugin attached during enrichment, so showing them
+// costs nothing extra
+type BadgePlugin interface {
+	Plugin
+
+	// Badge returns a few characters describing the node, e.g. "M" or "??"
+	// An empty badge shows nothing for the node
+	Badge(node *types.Node) string
+}
+
 // DataEnrichmentMap represents the data that a plugin wants to attach to nodes
 // Maps file paths (relative to root) to the data that should be attached to those nodes
 type DataEnrichmentMap map[string]interface{}
@@ -180,6 +193,40 @@ func (r *Registry) GetAllPlugins() []Plugin {
 	return plugins
 }
 
+// BadgePlugins returns the registered plugins that provide badges, without the disabled
+// ones; plugins named in order come first, in that order, and the others follow by name
+// Naming a plugin that provides no badge is an error.
+func (r *Registry) BadgePlugins(order, disabled []string) ([]BadgePlugin, error) {
+	available := make(map[string]BadgePlugin)
+	var names []string
+	for name, plugin := range r.plugins {
+		if badgePlugin, ok := plugin.(BadgePlugin); ok {
+			available[name] = badgePlugin
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range append(append([]string{}, order...), disabled...) {
+		if available[name] == nil {
+			return nil, fmt.Errorf("unknown badge plugin %q (available: %s)", name, strings.Join(names, ", "))
+		}
+	}
+	hidden := make(map[string]bool, len(disabled))
+	for _, name := range disabled {
+		hidden[name] = true
+	}
+
+	var badges []BadgePlugin
+	for _, name := range append(append([]string{}, order...), names...) {
+		if !hidden[name] {
+			badges = append(badges, available[name])
+			hidden[name] = true // Each plugin once, where order first puts it
+		}
+	}
+	return badges, nil
+}
+
 // Engine orchestrates plugin execution across multiple roots
 // It coordinates the work between plugins and handles parallel processing
 type Engine struct {
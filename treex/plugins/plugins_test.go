@@ -4,11 +4,15 @@ package plugins_test
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/spf13/afero"
-	"treex/treex/internal/testutil"
+	"treex/internal/testutil"
 	"treex/treex/plugins"
 	"treex/treex/plugins/dummy"
 	_ "treex/treex/plugins/git" // Import for plugin registration
+	"treex/treex/types"
 )
 
 func TestRegistry(t *testing.T) {
@@ -417,3 +421,45 @@ func TestNewDefaultEngine(t *testing.T) {
 		t.Errorf("Default engine should be able to process: %v", err)
 	}
 }
+
+// MockBadgePlugin shows its name as the badge of every node
+type MockBadgePlugin struct {
+	MockPlugin
+}
+
+func (m *MockBadgePlugin) Badge(node *types.Node) string {
+	return m.name
+}
+
+func TestBadgePlugins(t *testing.T) {
+	registry := plugins.NewRegistry()
+	for _, plugin := range []plugins.Plugin{
+		&MockBadgePlugin{MockPlugin{name: "owners"}},
+		&MockPlugin{name: "plain"},
+		&MockBadgePlugin{MockPlugin{name: "git"}},
+		&MockBadgePlugin{MockPlugin{name: "coverage"}},
+	} {
+		require.NoError(t, registry.Register(plugin))
+	}
+	names := func(badges []plugins.BadgePlugin) []string {
+		var result []string
+		for _, badge := range badges {
+			result = append(result, badge.Name())
+		}
+		return result
+	}
+
+	badges, err := registry.BadgePlugins(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"coverage", "git", "owners"}, names(badges), "plugins without badges are left out")
+
+	badges, err = registry.BadgePlugins([]string{"owners", "git"}, []string{"coverage"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"owners", "git"}, names(badges))
+
+	_, err = registry.BadgePlugins([]string{"plain"}, nil)
+	assert.ErrorContains(t, err, `unknown badge plugin "plain" (available: coverage, git, owners)`)
+
+	_, err = registry.BadgePlugins(nil, []string{"missing"})
+	assert.Error(t, err)
+}
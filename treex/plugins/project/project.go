@@ -0,0 +1,166 @@
+// Package project provides a plugin marking the subproject roots of a monorepo
+package project
+
+import (
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"treex/treex/plugins"
+	"treex/treex/types"
+)
+
+// Marker is a build file that makes the directory holding it a subproject root
+type Marker struct {
+	File string // Build file name, e.g. "go.mod"
+	Kind string // Short name of the ecosystem, shown as the badge
+}
+
+// Markers are the build files recognized, in badge order
+var Markers = []Marker{
+	{File: "go.mod", Kind: "go"},
+	{File: "package.json", Kind: "npm"},
+	{File: "Cargo.toml", Kind: "cargo"},
+	{File: "pom.xml", Kind: "maven"},
+}
+
+// Project is the data attached to the node of a subproject root
+type Project struct {
+	Kinds []string // Ecosystems of the markers found, in Markers order
+}
+
+// ProjectPlugin marks directories holding a build file (go.mod, package.json, Cargo.toml,
+// pom.xml) as subproject roots, so large monorepos show where each project starts
+type ProjectPlugin struct{}
+
+// NewProjectPlugin creates a new project plugin instance
+func NewProjectPlugin() *ProjectPlugin {
+	return &ProjectPlugin{}
+}
+
+// Name returns the plugin identifier
+func (p *ProjectPlugin) Name() string {
+	return "project"
+}
+
+// FindRoots returns the subproject roots below searchRoot, relative to it
+func (p *ProjectPlugin) FindRoots(fs afero.Fs, searchRoot string) ([]string, error) {
+	var roots []string
+	err := afero.Walk(fs, searchRoot, func(walkPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			if walkPath == searchRoot {
+				return err
+			}
+			return nil // Skip unreadable paths, don't fail the entire search
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if info.Name() == ".git" || info.Name() == dependencyDir {
+			return filepath.SkipDir
+		}
+		if len(Detect(fs, walkPath)) > 0 {
+			relativeRoot, err := filepath.Rel(searchRoot, walkPath)
+			if err != nil {
+				return nil
+			}
+			roots = append(roots, filepath.ToSlash(relativeRoot))
+		}
+		return nil
+	})
+	return roots, err
+}
+
+// ProcessRoot reports the ecosystems of the subproject at rootPath in Metadata["kinds"]
+func (p *ProjectPlugin) ProcessRoot(fs afero.Fs, rootPath string) (*plugins.Result, error) {
+	return &plugins.Result{
+		PluginName: p.Name(),
+		RootPath:   rootPath,
+		Categories: make(map[string][]string),
+		Metadata:   map[string]interface{}{"kinds": Detect(fs, rootPath)},
+	}, nil
+}
+
+// EnrichData attaches a *Project to the directories among filePaths that are subproject roots
+// Implements DataPluginV2 interface; the tree root itself is left out
+func (p *ProjectPlugin) EnrichData(fs afero.Fs, rootPath string, filePaths []string, cache plugins.CacheMap) (plugins.DataEnrichmentMap, error) {
+	enrichmentMap := make(plugins.DataEnrichmentMap)
+
+	// Entries with children in the tree are directories; the others need a stat
+	parents := make(map[string]bool)
+	for _, filePath := range filePaths {
+		if parent := path.Dir(filepath.ToSlash(filePath)); parent != filePath {
+			parents[parent] = true
+		}
+	}
+
+	for _, filePath := range filePaths {
+		if filePath == "" || filePath == "." || isDependency(filePath) {
+			continue
+		}
+		dir := filepath.Join(rootPath, filePath)
+		if !parents[filepath.ToSlash(filePath)] {
+			if info, err := fs.Stat(dir); err != nil || !info.IsDir() {
+				continue
+			}
+		}
+		if kinds := Detect(fs, dir); len(kinds) > 0 {
+			enrichmentMap[filePath] = &Project{Kinds: kinds}
+		}
+	}
+	return enrichmentMap, nil
+}
+
+// Badge returns the ecosystems of a subproject root, e.g. "go" or "go+npm"
+// Implements BadgePlugin interface
+func (p *ProjectPlugin) Badge(node *types.Node) string {
+	if project := Of(node); project != nil {
+		return strings.Join(project.Kinds, "+")
+	}
+	return ""
+}
+
+// dependencyDir holds the installed packages of an npm project, each with its package.json
+// Its build files belong to other projects, so nothing below it is a subproject root
+const dependencyDir = "node_modules"
+
+// isDependency reports whether filePath lies below a dependencyDir
+func isDependency(filePath string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(filePath), "/") {
+		if part == dependencyDir {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect returns the ecosystems of the build files in dir, in Markers order
+func Detect(fs afero.Fs, dir string) []string {
+	var kinds []string
+	for _, marker := range Markers {
+		if info, err := fs.Stat(filepath.Join(dir, marker.File)); err == nil && !info.IsDir() {
+			kinds = append(kinds, marker.Kind)
+		}
+	}
+	return kinds
+}
+
+// Of returns the project data of node, nil when it is not a subproject root
+func Of(node *types.Node) *Project {
+	data, ok := node.GetPluginData("project")
+	if !ok {
+		return nil
+	}
+	project, _ := data.(*Project)
+	return project
+}
+
+// init registers the project plugin with the default registry
+func init() {
+	if err := plugins.RegisterPlugin(NewProjectPlugin()); err != nil {
+		log.Fatalf("failed to register project plugin: %v", err)
+	}
+}
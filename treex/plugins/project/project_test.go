@@ -0,0 +1,60 @@
+package project_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/plugins/project"
+	"treex/treex/types"
+)
+
+func newMonorepo() *testutil.TestFS {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/mono", map[string]interface{}{
+		"go.mod": "module mono",
+		"services": map[string]interface{}{
+			"api":    map[string]interface{}{"go.mod": "module api", "main.go": "package main"},
+			"worker": map[string]interface{}{"Cargo.toml": "[package]", "package.json": "{}"},
+		},
+		"web": map[string]interface{}{
+			"package.json":  "{}",
+			"node_modules":  map[string]interface{}{"left-pad": map[string]interface{}{"package.json": "{}"}},
+			"pom.xml":       map[string]interface{}{}, // A directory, not a build file
+			"tsconfig.json": "{}",
+		},
+		"docs": map[string]interface{}{"guide.md": "# Guide"},
+	})
+	return fs
+}
+
+func TestFindRoots(t *testing.T) {
+	roots, err := project.NewProjectPlugin().FindRoots(newMonorepo(), "/mono")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{".", "services/api", "services/worker", "web"}, roots, "dependencies are not subprojects")
+}
+
+func TestDetect(t *testing.T) {
+	fs := newMonorepo()
+	assert.Equal(t, []string{"npm", "cargo"}, project.Detect(fs, "/mono/services/worker"), "kinds follow Markers order")
+	assert.Equal(t, []string{"npm"}, project.Detect(fs, "/mono/web"))
+	assert.Empty(t, project.Detect(fs, "/mono/docs"))
+}
+
+func TestEnrichDataAndBadge(t *testing.T) {
+	plugin := project.NewProjectPlugin()
+	paths := []string{".", "services", "services/api", "services/api/main.go", "services/worker", "web", "web/package.json", "web/node_modules/left-pad", "docs"}
+	data, err := plugin.EnrichData(newMonorepo(), "/mono", paths, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, len(data), "the tree root, dependencies and plain directories are left out")
+
+	badges := make(map[string]string)
+	for path, value := range data {
+		node := &types.Node{Path: path, IsDir: true}
+		node.SetPluginData("project", value)
+		badges[path] = plugin.Badge(node)
+	}
+	assert.Equal(t, map[string]string{"services/api": "go", "services/worker": "npm+cargo", "web": "npm"}, badges)
+	assert.Empty(t, plugin.Badge(&types.Node{Path: "docs", IsDir: true}))
+}
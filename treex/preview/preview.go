@@ -0,0 +1,259 @@
+// Package preview runs the edits of a writing command against an in-memory overlay of
+// the project and reports the files they would change, for --diff output.
+//
+// Commands keep their edit functions unchanged: the same closure that runs against the
+// undo journal (see package undo) runs against the overlay, so the preview is exactly
+// what the command would write.
+package preview
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/afero"
+	"treex/treex/undo"
+)
+
+// FileChange is one file an edit would change
+type FileChange struct {
+	Path   string // Relative to the root, slash-separated (absolute when outside it)
+	Before []byte // Current content; nil when the file does not exist
+	After  []byte // Content after the edit; nil when the edit removes the file
+}
+
+// Run runs edit against an overlay of fs that keeps every write in memory and returns the
+// files it would change, sorted by path. fs is never written to. Changes made before edit
+// fails are returned with its error.
+func Run(fs afero.Fs, root string, edit func(fs afero.Fs) error) ([]FileChange, error) {
+	layer := newOverlay(fs)
+	recorder := undo.NewRecorder(layer, root)
+	editErr := edit(recorder)
+
+	var changes []FileChange
+	for _, change := range recorder.Changes() {
+		if change.Kind != undo.KindFile {
+			continue
+		}
+		fileChange := FileChange{Path: change.Path}
+		if change.Existed {
+			fileChange.Before = change.Before
+			if fileChange.Before == nil {
+				fileChange.Before = []byte{}
+			}
+		}
+		if after, err := afero.ReadFile(layer, resolve(root, change.Path)); err == nil {
+			fileChange.After = after
+		}
+		if (fileChange.Before == nil) == (fileChange.After == nil) && bytes.Equal(fileChange.Before, fileChange.After) {
+			continue
+		}
+		changes = append(changes, fileChange)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, editErr
+}
+
+// WriteDiff prints changes as unified diffs, created files from /dev/null and removed
+// files to it
+func WriteDiff(out io.Writer, changes []FileChange) error {
+	for _, change := range changes {
+		fromFile, toFile := "a/"+change.Path, "b/"+change.Path
+		if change.Before == nil {
+			fromFile = "/dev/null"
+		}
+		if change.After == nil {
+			toFile = "/dev/null"
+		}
+		if len(change.Before) == 0 && len(change.After) == 0 {
+			// Creating or removing an empty file has no hunks; print the header alone
+			if _, err := fmt.Fprintf(out, "--- %s\n+++ %s\n", fromFile, toFile); err != nil {
+				return err
+			}
+			continue
+		}
+		err := difflib.WriteUnifiedDiff(out, difflib.UnifiedDiff{
+			A:        diffLines(change.Before),
+			B:        diffLines(change.After),
+			FromFile: fromFile,
+			ToFile:   toFile,
+			Context:  3,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffLines splits content into newline-terminated lines for the diff
+func diffLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// resolve turns a change path back into a filesystem path
+func resolve(root, path string) string {
+	if filepath.IsAbs(filepath.FromSlash(path)) {
+		return filepath.FromSlash(path)
+	}
+	return filepath.Join(root, filepath.FromSlash(path))
+}
+
+// overlay is a copy-on-write view of a base filesystem that also supports removing and
+// renaming base files, which afero.CopyOnWriteFs refuses. Removed base files are hidden
+// from Stat and Open; directory listings still show them.
+type overlay struct {
+	*afero.CopyOnWriteFs
+	base afero.Fs
+
+	mu      sync.Mutex
+	removed map[string]bool
+}
+
+func newOverlay(base afero.Fs) *overlay {
+	return &overlay{
+		CopyOnWriteFs: afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(base), afero.NewMemMapFs()).(*afero.CopyOnWriteFs),
+		base:          base,
+		removed:       make(map[string]bool),
+	}
+}
+
+// isRemoved reports whether name was removed through the overlay
+func (o *overlay) isRemoved(name string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.removed[filepath.Clean(name)]
+}
+
+// setRemoved marks name as removed (or present again)
+func (o *overlay) setRemoved(name string, removed bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if removed {
+		o.removed[filepath.Clean(name)] = true
+	} else {
+		delete(o.removed, filepath.Clean(name))
+	}
+}
+
+func notExist(op, name string) error {
+	return &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+}
+
+func (o *overlay) Stat(name string) (os.FileInfo, error) {
+	if o.isRemoved(name) {
+		return nil, notExist("stat", name)
+	}
+	return o.CopyOnWriteFs.Stat(name)
+}
+
+func (o *overlay) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	if o.isRemoved(name) {
+		return nil, false, notExist("lstat", name)
+	}
+	return o.CopyOnWriteFs.LstatIfPossible(name)
+}
+
+func (o *overlay) Open(name string) (afero.File, error) {
+	if o.isRemoved(name) {
+		return nil, notExist("open", name)
+	}
+	return o.CopyOnWriteFs.Open(name)
+}
+
+func (o *overlay) Create(name string) (afero.File, error) {
+	return o.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+func (o *overlay) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if o.isRemoved(name) {
+		if flag&os.O_CREATE == 0 {
+			return nil, notExist("open", name)
+		}
+		// Recreating a removed base file starts from empty content
+		o.setRemoved(name, false)
+		flag = flag&^os.O_EXCL | os.O_TRUNC
+	}
+	return o.CopyOnWriteFs.OpenFile(name, flag, perm)
+}
+
+func (o *overlay) Mkdir(name string, perm os.FileMode) error {
+	o.setRemoved(name, false)
+	return o.CopyOnWriteFs.Mkdir(name, perm)
+}
+
+func (o *overlay) MkdirAll(name string, perm os.FileMode) error {
+	o.setRemoved(name, false)
+	return o.CopyOnWriteFs.MkdirAll(name, perm)
+}
+
+func (o *overlay) Remove(name string) error {
+	if o.isRemoved(name) {
+		return notExist("remove", name)
+	}
+	// Removes the layer copy; a file only in the base is reported as EPERM or not found
+	layerErr := o.CopyOnWriteFs.Remove(name)
+	if _, err := o.base.Stat(name); err == nil {
+		o.setRemoved(name, true)
+		return nil
+	}
+	return layerErr
+}
+
+func (o *overlay) RemoveAll(name string) error {
+	var paths []string
+	_ = afero.Walk(o, name, func(walkPath string, info os.FileInfo, err error) error {
+		if err == nil {
+			paths = append(paths, walkPath)
+		}
+		return nil
+	})
+	for i := len(paths) - 1; i >= 0; i-- {
+		if err := o.Remove(paths[i]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *overlay) Rename(oldname, newname string) error {
+	info, err := o.Stat(oldname)
+	if err != nil {
+		return err
+	}
+	if err := o.CopyOnWriteFs.Rename(oldname, newname); err == nil {
+		o.setRemoved(newname, false)
+		if _, err := o.base.Stat(oldname); err == nil {
+			o.setRemoved(oldname, true)
+		}
+		return nil
+	} else if !errors.Is(err, syscall.EPERM) || info.IsDir() {
+		return err
+	}
+
+	// A base file: copy it into the layer under the new name, then hide the old one
+	content, err := afero.ReadFile(o, oldname)
+	if err != nil {
+		return err
+	}
+	o.setRemoved(newname, false)
+	if err := afero.WriteFile(o, newname, content, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return o.Remove(oldname)
+}
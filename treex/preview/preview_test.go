@@ -0,0 +1,50 @@
+package preview_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/preview"
+)
+
+func TestRunLeavesFsUntouched(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info": "old.go  Old\n",
+		"keep":  "same",
+		"docs":  map[string]interface{}{"guide.md": "# Guide"},
+	})
+
+	changes, err := preview.Run(fs, "/project", func(fs afero.Fs) error {
+		require.NoError(t, afero.WriteFile(fs, "/project/docs/.info", []byte("guide.md  Guide\n"), 0644))
+		require.NoError(t, afero.WriteFile(fs, "/project/keep", []byte("same"), 0644))
+		require.NoError(t, fs.Remove("/project/.info"))
+		exists, _ := afero.Exists(fs, "/project/.info")
+		assert.False(t, exists, "removed files are gone inside the preview")
+		require.NoError(t, afero.WriteFile(fs, "/project/tmp", []byte("x"), 0644))
+		return fs.Rename("/project/tmp", "/project/new")
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []preview.FileChange{
+		{Path: ".info", Before: []byte("old.go  Old\n")},
+		{Path: "docs/.info", After: []byte("guide.md  Guide\n")},
+		{Path: "new", After: []byte("x")},
+	}, changes)
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "old.go  Old\n", string(content))
+	for _, name := range []string{"/project/docs/.info", "/project/new", "/project/tmp"} {
+		exists, _ := afero.Exists(fs, name)
+		assert.False(t, exists, name)
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, preview.WriteDiff(&out, changes[:1]))
+	assert.Equal(t, "--- a/.info\n+++ /dev/null\n@@ -1 +0,0 @@\n-old.go  Old\n", out.String())
+}
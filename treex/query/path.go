@@ -1,9 +1,8 @@
 package query
 
 import (
-	"path/filepath"
-
 	"github.com/bmatcuk/doublestar/v4"
+	"treex/treex/pathutil"
 	"treex/treex/types"
 )
 
@@ -26,7 +25,7 @@ func (q *PathQuery) Match(node *types.Node) bool {
 	}
 
 	// Normalize the path for consistent matching
-	nodePath := filepath.ToSlash(node.Path)
+	nodePath := pathutil.Normalize(node.Path)
 
 	// Use doublestar for gitignore-compatible glob matching
 	matched, err := doublestar.Match(q.pattern, nodePath)
@@ -145,7 +145,7 @@ func (b *Builder) Build() *Processor {
 // copyDataMap creates a deep copy of a plugin data map
 func copyDataMap(original map[string]interface{}) map[string]interface{} {
 	if original == nil {
-		return make(map[string]interface{})
+		return nil // Data is allocated lazily, see types.Node.SetPluginData
 	}
 
 	copied := make(map[string]interface{}, len(original))
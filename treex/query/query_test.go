@@ -4,7 +4,7 @@ package query_test
 import (
 	"testing"
 
-	"treex/treex/internal/testutil"
+	"treex/internal/testutil"
 	"treex/treex/query"
 	"treex/treex/types"
 )
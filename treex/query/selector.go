@@ -0,0 +1,468 @@
+package query
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"treex/treex/pathutil"
+	"treex/treex/types"
+)
+
+// Selector matches nodes with a CSS-like expression over the built tree:
+//
+//	dir[name=internal] > file[ext=go][annotated=false]
+//
+// A compound is an optional type (dir, file or *) followed by attribute conditions in
+// brackets; compounds are joined by ">" (child of) or whitespace (anywhere below), and
+// comma-separated selectors match any of them. Conditions compare an attribute to a value
+// with = != ^= (prefix) $= (suffix) *= (contains) ~= (regular expression), and numbers
+// also with < <= > >=; a bare [annotated] means [annotated=true]. Values may be quoted.
+//
+// The tree root is not an entry: it neither matches nor counts as an ancestor.
+type Selector struct {
+	source       string
+	alternatives []complexSelector
+}
+
+// complexSelector is a chain of compounds; combinators[i] joins compounds[i] and
+// compounds[i+1]: '>' for a child, ' ' for a descendant
+type complexSelector struct {
+	compounds   []compound
+	combinators []byte
+}
+
+// compound is a type and the conditions one node must meet
+type compound struct {
+	kind       string // "dir", "file" or "" for any
+	conditions []condition
+}
+
+// condition compares one attribute of a node to a value
+type condition struct {
+	attribute attribute
+	op        string
+	value     string
+	number    float64        // Parsed value of numeric attributes
+	pattern   *regexp.Regexp // Compiled value of ~=
+}
+
+// attributeType is what an attribute's values are compared as
+type attributeType int
+
+const (
+	stringAttribute attributeType = iota
+	numberAttribute
+	boolAttribute
+)
+
+// attribute is a property of a node conditions can test
+type attribute struct {
+	name  string
+	kind  attributeType
+	value func(node *types.Node) string // Strings and booleans ("true"/"false")
+	num   func(node *types.Node) float64
+}
+
+// attributes are the attributes selectors know, by name
+var attributes = map[string]attribute{
+	"name": {name: "name", value: func(n *types.Node) string { return n.Name }},
+	"path": {name: "path", value: func(n *types.Node) string { return pathutil.Normalize(n.Path) }},
+	"ext":  {name: "ext", value: func(n *types.Node) string { return strings.TrimPrefix(path.Ext(n.Name), ".") }},
+	"annotation": {name: "annotation", value: func(n *types.Node) string {
+		if annotation := n.GetAnnotation(); annotation != nil {
+			return annotation.Notes
+		}
+		return ""
+	}},
+	"kind":      {name: "kind", value: func(n *types.Node) string { return string(n.GetKind()) }},
+	"size":      {name: "size", kind: numberAttribute, num: func(n *types.Node) float64 { return float64(n.Size) }},
+	"depth":     {name: "depth", kind: numberAttribute, num: func(n *types.Node) float64 { return float64(depthOf(n)) }},
+	"annotated": {name: "annotated", kind: boolAttribute, value: func(n *types.Node) string { return boolString(isAnnotated(n)) }},
+	"hidden":    {name: "hidden", kind: boolAttribute, value: func(n *types.Node) string { return boolString(strings.HasPrefix(n.Name, ".")) }},
+	"untracked": {name: "untracked", kind: boolAttribute, value: func(n *types.Node) string { return boolString(gitStatus(n).Untracked) }},
+	"staged":    {name: "staged", kind: boolAttribute, value: func(n *types.Node) string { return boolString(gitStatus(n).Staged) }},
+	"modified":  {name: "modified", kind: boolAttribute, value: func(n *types.Node) string { return boolString(gitStatus(n).Unstaged) }},
+}
+
+// sizeUnits are the suffixes number values may carry, in powers of 1024 as treex prints sizes
+var sizeUnits = []struct {
+	suffix string
+	factor float64
+}{
+	{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+}
+
+// ParseSelector parses a selector expression (see Selector)
+func ParseSelector(expr string) (*Selector, error) {
+	p := &selectorParser{input: expr}
+	selector := &Selector{source: expr}
+	for {
+		complex, err := p.complex()
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", expr, err)
+		}
+		selector.alternatives = append(selector.alternatives, complex)
+		p.skipSpace()
+		if p.done() {
+			return selector, nil
+		}
+		if !p.consume(",") {
+			return nil, fmt.Errorf("invalid selector %q: unexpected %q at offset %d", expr, p.input[p.pos:p.pos+1], p.pos)
+		}
+	}
+}
+
+// Match returns true if node is an entry (not the root) matching any alternative
+func (s *Selector) Match(node *types.Node) bool {
+	if node == nil || node.Parent == nil {
+		return false
+	}
+	for _, complex := range s.alternatives {
+		if complex.matchAt(len(complex.compounds)-1, node) {
+			return true
+		}
+	}
+	return false
+}
+
+// Name returns the selector as written
+func (s *Selector) Name() string {
+	return "select:" + s.source
+}
+
+// Find returns the nodes below root matching q, in tree order
+func Find(root *types.Node, q Query) []*types.Node {
+	var matches []*types.Node
+	_ = types.WalkTree(root, func(node *types.Node, depth int) error {
+		if q.Match(node) {
+			matches = append(matches, node)
+		}
+		return nil
+	})
+	return matches
+}
+
+// matchAt reports whether node matches compounds[i], with the compounds before it
+// matching its ancestors as the combinators require
+func (c complexSelector) matchAt(i int, node *types.Node) bool {
+	if node == nil || node.Parent == nil || !c.compounds[i].match(node) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	if c.combinators[i-1] == '>' {
+		return c.matchAt(i-1, node.Parent)
+	}
+	for ancestor := node.Parent; ancestor != nil && ancestor.Parent != nil; ancestor = ancestor.Parent {
+		if c.matchAt(i-1, ancestor) {
+			return true
+		}
+	}
+	return false
+}
+
+// match reports whether node has the compound's type and meets all its conditions
+func (c compound) match(node *types.Node) bool {
+	switch {
+	case c.kind == "dir" && !node.IsDir, c.kind == "file" && node.IsDir:
+		return false
+	}
+	for _, cond := range c.conditions {
+		if !cond.match(node) {
+			return false
+		}
+	}
+	return true
+}
+
+// match reports whether node meets the condition
+func (c condition) match(node *types.Node) bool {
+	if c.attribute.kind == numberAttribute {
+		value := c.attribute.num(node)
+		switch c.op {
+		case "=":
+			return value == c.number
+		case "!=":
+			return value != c.number
+		case "<":
+			return value < c.number
+		case "<=":
+			return value <= c.number
+		case ">":
+			return value > c.number
+		case ">=":
+			return value >= c.number
+		}
+		return false
+	}
+
+	value := c.attribute.value(node)
+	switch c.op {
+	case "=":
+		return value == c.value
+	case "!=":
+		return value != c.value
+	case "^=":
+		return strings.HasPrefix(value, c.value)
+	case "$=":
+		return strings.HasSuffix(value, c.value)
+	case "*=":
+		return strings.Contains(value, c.value)
+	case "~=":
+		return c.pattern.MatchString(value)
+	}
+	return false
+}
+
+// selectorParser reads a selector expression left to right
+type selectorParser struct {
+	input string
+	pos   int
+}
+
+func (p *selectorParser) done() bool { return p.pos >= len(p.input) }
+
+func (p *selectorParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+// consume advances past token if the input continues with it
+func (p *selectorParser) consume(token string) bool {
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+// skipSpace advances past whitespace, reporting whether there was any
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for !p.done() && strings.IndexByte(" \t\n", p.peek()) >= 0 {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+// complex reads compounds and the combinators between them, up to a comma or the end
+func (p *selectorParser) complex() (complexSelector, error) {
+	var c complexSelector
+	p.skipSpace()
+	for {
+		comp, err := p.compound()
+		if err != nil {
+			return c, err
+		}
+		c.compounds = append(c.compounds, comp)
+
+		spaced := p.skipSpace()
+		switch {
+		case p.done() || p.peek() == ',':
+			return c, nil
+		case p.consume(">"):
+			p.skipSpace()
+			c.combinators = append(c.combinators, '>')
+		case spaced:
+			c.combinators = append(c.combinators, ' ')
+		default:
+			return c, fmt.Errorf("unexpected %q at offset %d", p.input[p.pos:p.pos+1], p.pos)
+		}
+	}
+}
+
+// compound reads an optional type and its bracketed conditions
+func (p *selectorParser) compound() (compound, error) {
+	var c compound
+	start := p.pos
+	for !p.done() && isWordByte(p.peek()) {
+		p.pos++
+	}
+	switch word := p.input[start:p.pos]; word {
+	case "dir", "file":
+		c.kind = word
+	case "*":
+	case "":
+		if p.peek() != '[' {
+			return c, fmt.Errorf("expected dir, file, * or [ at offset %d", p.pos)
+		}
+	default:
+		return c, fmt.Errorf("unknown type %q at offset %d (valid: dir, file, *)", word, start)
+	}
+
+	for p.consume("[") {
+		cond, err := p.condition()
+		if err != nil {
+			return c, err
+		}
+		c.conditions = append(c.conditions, cond)
+	}
+	return c, nil
+}
+
+// condition reads an attribute condition after its opening bracket, through the closing one
+func (p *selectorParser) condition() (condition, error) {
+	p.skipSpace()
+	start := p.pos
+	for !p.done() && isWordByte(p.peek()) && p.peek() != '*' {
+		p.pos++
+	}
+	name := p.input[start:p.pos]
+	attr, ok := attributes[name]
+	if !ok {
+		return condition{}, fmt.Errorf("unknown attribute %q at offset %d (valid: %s)", name, start, strings.Join(attributeNames(), ", "))
+	}
+	cond := condition{attribute: attr}
+	p.skipSpace()
+
+	if p.consume("]") {
+		if attr.kind != boolAttribute {
+			return cond, fmt.Errorf("attribute %q needs a value at offset %d", name, start)
+		}
+		cond.op, cond.value = "=", "true"
+		return cond, nil
+	}
+
+	for _, op := range []string{"!=", "^=", "$=", "*=", "~=", "<=", ">=", "=", "<", ">"} {
+		if p.consume(op) {
+			cond.op = op
+			break
+		}
+	}
+	if cond.op == "" {
+		return cond, fmt.Errorf("expected an operator after %q at offset %d", name, p.pos)
+	}
+	p.skipSpace()
+	value, err := p.value()
+	if err != nil {
+		return cond, err
+	}
+	p.skipSpace()
+	if !p.consume("]") {
+		return cond, fmt.Errorf("expected ] at offset %d", p.pos)
+	}
+	return cond, cond.compile(value)
+}
+
+// value reads a quoted value, or a bare one up to the closing bracket
+func (p *selectorParser) value() (string, error) {
+	if quote := p.peek(); quote == '"' || quote == '\'' {
+		end := strings.IndexByte(p.input[p.pos+1:], quote)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string at offset %d", p.pos)
+		}
+		value := p.input[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return value, nil
+	}
+	end := strings.IndexByte(p.input[p.pos:], ']')
+	if end < 0 {
+		return "", fmt.Errorf("expected ] at offset %d", len(p.input))
+	}
+	value := strings.TrimSpace(p.input[p.pos : p.pos+end])
+	p.pos += end
+	return value, nil
+}
+
+// compile checks that the operator suits the attribute and parses the value
+func (c *condition) compile(value string) error {
+	name := c.attribute.name
+	switch c.attribute.kind {
+	case numberAttribute:
+		number, err := parseNumber(value)
+		if err != nil {
+			return fmt.Errorf("attribute %q needs a number, got %q", name, value)
+		}
+		if strings.ContainsAny(c.op, "^$*~") {
+			return fmt.Errorf("operator %s does not apply to the number %q", c.op, name)
+		}
+		c.number = number
+	case boolAttribute:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("attribute %q is true or false, got %q", name, value)
+		}
+		if c.op != "=" && c.op != "!=" {
+			return fmt.Errorf("operator %s does not apply to %q, which is true or false", c.op, name)
+		}
+	default:
+		if strings.ContainsAny(c.op, "<>") {
+			return fmt.Errorf("operator %s does not apply to the text %q", c.op, name)
+		}
+		if name == "ext" {
+			value = strings.TrimPrefix(value, ".")
+		}
+		if c.op == "~=" {
+			pattern, err := regexp.Compile(value)
+			if err != nil {
+				return fmt.Errorf("invalid pattern for %q: %w", name, err)
+			}
+			c.pattern = pattern
+		}
+	}
+	c.value = value
+	return nil
+}
+
+// parseNumber parses a number, optionally with a size unit such as 10KB or 1.5M
+func parseNumber(value string) (float64, error) {
+	upper := strings.ToUpper(value)
+	for _, unit := range sizeUnits {
+		if number, ok := strings.CutSuffix(upper, unit.suffix); ok && number != "" {
+			parsed, err := strconv.ParseFloat(number, 64)
+			return parsed * unit.factor, err
+		}
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// attributeNames returns the names of the known attributes, sorted
+func attributeNames() []string {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isWordByte reports whether b can be part of a type or attribute name
+func isWordByte(b byte) bool {
+	return b == '*' || b == '-' || b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// depthOf returns the number of ancestors of node: 1 for the entries of the root
+func depthOf(node *types.Node) int {
+	depth := 0
+	for parent := node.Parent; parent != nil; parent = parent.Parent {
+		depth++
+	}
+	return depth
+}
+
+// isAnnotated reports whether node has annotation notes
+func isAnnotated(node *types.Node) bool {
+	annotation := node.GetAnnotation()
+	return annotation != nil && annotation.Notes != ""
+}
+
+// gitStatus returns the git status the git plugin attached to node (zero when none)
+func gitStatus(node *types.Node) types.GitStatus {
+	if data, ok := node.GetPluginData("git"); ok {
+		if status, ok := data.(*types.GitStatus); ok && status != nil {
+			return *status
+		}
+	}
+	return types.GitStatus{}
+}
+
+// boolString formats a boolean attribute value
+func boolString(b bool) string {
+	return strconv.FormatBool(b)
+}
@@ -0,0 +1,140 @@
+package query_test
+
+import (
+	"strings"
+	"testing"
+
+	"treex/treex/query"
+	"treex/treex/types"
+)
+
+// selectorTree builds:
+//
+//	root/
+//	  cmd/main.go (annotated "Entry point")
+//	  internal/
+//	    store/db.go (2048 bytes, staged)
+//	    util.go
+//	  .env
+func selectorTree() *types.Node {
+	root := &types.Node{Name: "root", Path: ".", IsDir: true}
+	add := func(parent *types.Node, name string, isDir bool) *types.Node {
+		path := name
+		if parent.Path != "." {
+			path = parent.Path + "/" + name
+		}
+		node := &types.Node{Name: name, Path: path, IsDir: isDir, Parent: parent}
+		parent.Children = append(parent.Children, node)
+		return node
+	}
+	cmd := add(root, "cmd", true)
+	main := add(cmd, "main.go", false)
+	main.SetAnnotation(&types.Annotation{Path: "cmd/main.go", Notes: "Entry point"})
+	internal := add(root, "internal", true)
+	store := add(internal, "store", true)
+	db := add(store, "db.go", false)
+	db.Size = 2048
+	db.SetPluginData("git", &types.GitStatus{Path: "internal/store/db.go", Staged: true})
+	add(internal, "util.go", false)
+	add(root, ".env", false)
+	return root
+}
+
+func TestSelector_Find(t *testing.T) {
+	tests := []struct {
+		selector string
+		expected []string
+	}{
+		{"file", []string{"cmd/main.go", "internal/store/db.go", "internal/util.go", ".env"}},
+		{"dir", []string{"cmd", "internal", "internal/store"}},
+		{"*", []string{"cmd", "cmd/main.go", "internal", "internal/store", "internal/store/db.go", "internal/util.go", ".env"}},
+		{"dir[name=internal] > file[ext=go]", []string{"internal/util.go"}},
+		{"dir[name=internal] file[ext=go]", []string{"internal/store/db.go", "internal/util.go"}},
+		{"dir[name=internal] > file[ext=go][annotated=false]", []string{"internal/util.go"}},
+		{"file[annotated]", []string{"cmd/main.go"}},
+		{"file[annotation*=entry]", nil},
+		{"file[annotation~='(?i)entry']", []string{"cmd/main.go"}},
+		{"[hidden]", []string{".env"}},
+		{"file[size>=2K]", []string{"internal/store/db.go"}},
+		{"file[size<1kb]", []string{"cmd/main.go", "internal/util.go", ".env"}},
+		{"[depth=1]", []string{"cmd", "internal", ".env"}},
+		{"file[staged]", []string{"internal/store/db.go"}},
+		{"file[modified]", nil},
+		{"[path^=internal/store]", []string{"internal/store", "internal/store/db.go"}},
+		{"file[name$=.go][name!=db.go]", []string{"cmd/main.go", "internal/util.go"}},
+		{"file[ext=.go] , [hidden]", []string{"cmd/main.go", "internal/store/db.go", "internal/util.go", ".env"}},
+		{`dir[name="cmd"]>file`, []string{"cmd/main.go"}},
+		{"dir[name=root] > *", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := query.ParseSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("ParseSelector() error = %v", err)
+			}
+			var got []string
+			for _, node := range query.Find(selectorTree(), selector) {
+				got = append(got, node.Path)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Find() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSelector_ParseErrors(t *testing.T) {
+	tests := []struct {
+		selector string
+		contains string
+	}{
+		{"", "expected dir, file, * or ["},
+		{"folder", `unknown type "folder"`},
+		{"file[owner=me]", `unknown attribute "owner"`},
+		{"file[name]", `attribute "name" needs a value`},
+		{"file[name=main.go", "expected ]"},
+		{"file[name='main.go]", "unterminated string"},
+		{"file[size=big]", `attribute "size" needs a number`},
+		{"file[size^=1]", "operator ^= does not apply"},
+		{"file[name>a]", "operator > does not apply"},
+		{"file[hidden=yes]", "is true or false"},
+		{"file[name~=(]", "invalid pattern"},
+		{"file > ", "expected dir, file, * or ["},
+		{"file,", "expected dir, file, * or ["},
+		{"file]", `unexpected "]"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			_, err := query.ParseSelector(tt.selector)
+			if err == nil {
+				t.Fatalf("ParseSelector(%q) succeeded, want error", tt.selector)
+			}
+			if !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.contains)
+			}
+		})
+	}
+}
+
+func TestSelector_Query(t *testing.T) {
+	selector, err := query.ParseSelector("file[ext=go]")
+	if err != nil {
+		t.Fatalf("ParseSelector() error = %v", err)
+	}
+	if selector.Name() != "select:file[ext=go]" {
+		t.Errorf("Name() = %q", selector.Name())
+	}
+
+	processor := query.NewProcessor()
+	processor.AddQuery(selector)
+	var matched []string
+	_ = types.WalkTree(processor.Process(selectorTree()), func(node *types.Node, depth int) error {
+		matched = append(matched, node.Name)
+		return nil
+	})
+	if strings.Join(matched, ",") != "root,cmd,main.go,internal,store,db.go,util.go" {
+		t.Errorf("Process() = %v", matched)
+	}
+}
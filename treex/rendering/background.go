@@ -0,0 +1,72 @@
+package rendering
+
+import (
+	"strconv"
+	"strings"
+)
+
+// BackgroundMode selects how adaptive colors pick their light or dark variant
+type BackgroundMode string
+
+const (
+	BackgroundAuto  BackgroundMode = "auto"  // Detect the terminal background
+	BackgroundDark  BackgroundMode = "dark"  // Force the dark palette
+	BackgroundLight BackgroundMode = "light" // Force the light palette
+)
+
+// ThemeEnvVar is the environment variable consulted when --theme is not given
+const ThemeEnvVar = "TREEX_THEME"
+
+// ParseThemeSelection interprets a --theme / TREEX_THEME value
+// "auto", "dark" and "light" select a background mode with the default theme;
+// any other value names a theme whose background is auto-detected
+// An empty value means "auto"
+func ParseThemeSelection(value string) (BackgroundMode, string) {
+	switch mode := BackgroundMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "", BackgroundAuto:
+		return BackgroundAuto, DefaultThemeName
+	case BackgroundDark, BackgroundLight:
+		return mode, DefaultThemeName
+	default:
+		return BackgroundAuto, strings.TrimSpace(value)
+	}
+}
+
+// ResolveDarkBackground decides whether adaptive colors should use their dark variant
+// Forced modes win. In auto mode the COLORFGBG convention is trusted first; inside
+// tmux or over SSH terminal queries are unreliable (they time out or report the
+// outer terminal), so dark is assumed there. Otherwise the terminal is queried.
+func ResolveDarkBackground(mode BackgroundMode, getenv func(string) string, queryTerminal func() bool) bool {
+	switch mode {
+	case BackgroundDark:
+		return true
+	case BackgroundLight:
+		return false
+	}
+
+	if dark, ok := parseColorFgBg(getenv("COLORFGBG")); ok {
+		return dark
+	}
+
+	if getenv("TMUX") != "" || getenv("SSH_CONNECTION") != "" || getenv("SSH_TTY") != "" {
+		return true
+	}
+
+	return queryTerminal()
+}
+
+// parseColorFgBg reads the background color from a COLORFGBG value ("fg;bg" or "fg;other;bg")
+// ANSI colors 0-6 and 8 are dark backgrounds; 7 and 9-15 are light
+func parseColorFgBg(value string) (dark bool, ok bool) {
+	if value == "" {
+		return false, false
+	}
+
+	fields := strings.Split(value, ";")
+	bg, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || bg < 0 || bg > 15 {
+		return false, false
+	}
+
+	return bg <= 6 || bg == 8, true
+}
@@ -0,0 +1,71 @@
+package rendering_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"treex/treex/rendering"
+)
+
+// envMap returns a getenv function backed by a map
+func envMap(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func TestParseThemeSelection(t *testing.T) {
+	tests := []struct {
+		value        string
+		expectedMode rendering.BackgroundMode
+		expectedName string
+	}{
+		{"", rendering.BackgroundAuto, rendering.DefaultThemeName},
+		{"auto", rendering.BackgroundAuto, rendering.DefaultThemeName},
+		{"dark", rendering.BackgroundDark, rendering.DefaultThemeName},
+		{"LIGHT", rendering.BackgroundLight, rendering.DefaultThemeName},
+		{"ocean", rendering.BackgroundAuto, "ocean"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			mode, name := rendering.ParseThemeSelection(tt.value)
+			assert.Equal(t, tt.expectedMode, mode)
+			assert.Equal(t, tt.expectedName, name)
+		})
+	}
+}
+
+func TestResolveDarkBackground(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          rendering.BackgroundMode
+		env           map[string]string
+		terminalDark  bool
+		expectedDark  bool
+		expectedQuery bool
+	}{
+		{"forced dark ignores terminal", rendering.BackgroundDark, nil, false, true, false},
+		{"forced light ignores terminal", rendering.BackgroundLight, map[string]string{"COLORFGBG": "15;0"}, true, false, false},
+		{"COLORFGBG dark background", rendering.BackgroundAuto, map[string]string{"COLORFGBG": "15;0"}, false, true, false},
+		{"COLORFGBG light background", rendering.BackgroundAuto, map[string]string{"COLORFGBG": "0;default;15"}, true, false, false},
+		{"tmux skips terminal query", rendering.BackgroundAuto, map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0"}, false, true, false},
+		{"ssh skips terminal query", rendering.BackgroundAuto, map[string]string{"SSH_CONNECTION": "10.0.0.1 22 10.0.0.2 22"}, false, true, false},
+		{"invalid COLORFGBG falls back to query", rendering.BackgroundAuto, map[string]string{"COLORFGBG": "default"}, false, false, true},
+		{"no hints queries terminal", rendering.BackgroundAuto, nil, true, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queried := false
+			query := func() bool {
+				queried = true
+				return tt.terminalDark
+			}
+
+			dark := rendering.ResolveDarkBackground(tt.mode, envMap(tt.env), query)
+			assert.Equal(t, tt.expectedDark, dark)
+			assert.Equal(t, tt.expectedQuery, queried)
+		})
+	}
+}
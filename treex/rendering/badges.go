@@ -0,0 +1,50 @@
+package rendering
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"treex/treex/types"
+)
+
+// BadgeSource contributes a short badge per node, such as a git status; an empty badge
+// shows nothing for the node
+type BadgeSource interface {
+	Badge(node *types.Node) string
+}
+
+// badgeWidths returns the widest badge of each source in the tree below root (the root
+// shows no badges). Sources without any badge get width 0 and take no column.
+func badgeWidths(sources []BadgeSource, root *types.Node) []int {
+	if len(sources) == 0 || root == nil {
+		return nil
+	}
+	widths := make([]int, len(sources))
+	_ = types.WalkTree(root, func(node *types.Node, depth int) error {
+		if depth == 0 {
+			return nil
+		}
+		for i, source := range sources {
+			widths[i] = max(widths[i], ansi.StringWidth(source.Badge(node)))
+		}
+		return nil
+	})
+	return widths
+}
+
+// badges returns the badge columns of node, each padded to its width and followed by
+// a space, so names stay aligned whether or not a node has badges; the root has none
+func (r *Renderer) badges(node *types.Node) string {
+	if node.Parent == nil {
+		return ""
+	}
+	var b strings.Builder
+	for i, width := range r.badgeWidths {
+		if width == 0 {
+			continue
+		}
+		badge := r.config.Badges[i].Badge(node)
+		b.WriteString(badge + strings.Repeat(" ", width-ansi.StringWidth(badge)+1))
+	}
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package rendering_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+// mapBadges returns badges by node name
+type mapBadges map[string]string
+
+func (m mapBadges) Badge(node *types.Node) string {
+	return m[node.Name]
+}
+
+func TestRendererBadges(t *testing.T) {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	src := &types.Node{Name: "src", Path: "src", IsDir: true, Parent: root}
+	mainFile := &types.Node{Name: "main.go", Path: "src/main.go", Parent: src}
+	readme := &types.Node{Name: "README.md", Path: "README.md", Parent: root}
+	src.Children = []*types.Node{mainFile}
+	root.Children = []*types.Node{src, readme}
+
+	render := func(badges ...rendering.BadgeSource) string {
+		var buf bytes.Buffer
+		renderer := rendering.NewRenderer(rendering.RenderConfig{
+			Format: rendering.FormatPlain,
+			Writer: &buf,
+			Badges: badges,
+		})
+		require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: root}))
+		return buf.String()
+	}
+
+	t.Run("columns keep names aligned", func(t *testing.T) {
+		output := render(mapBadges{"main.go": "MM", "README.md": "??", "project": "!"}, mapBadges{"README.md": "👥"})
+		assert.Equal(t, "project\n"+
+			"├─       src\n"+
+			"│  └─ MM    main.go\n"+
+			"└─ ?? 👥 README.md\n", output)
+	})
+
+	t.Run("sources without badges take no column", func(t *testing.T) {
+		output := render(mapBadges{"project": "root badges are not shown"}, mapBadges{"main.go": "M"})
+		assert.Equal(t, "project\n"+
+			"├─   src\n"+
+			"│  └─ M main.go\n"+
+			"└─   README.md\n", output)
+	})
+}
@@ -0,0 +1,105 @@
+package rendering
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// FileClass is a semantic style for file names, chosen by extension or size
+// Themes style classes under "classes"; an unstyled class looks like any file name,
+// except large files, which use the warning presentation style.
+type FileClass string
+
+const (
+	ClassNone   FileClass = ""
+	ClassCode   FileClass = "code"   // Source code
+	ClassDocs   FileClass = "docs"   // Documentation and prose
+	ClassConfig FileClass = "config" // Configuration and data files
+	ClassBinary FileClass = "binary" // Binaries, archives and media
+	ClassLarge  FileClass = "large"  // Files at or above the large file size, whatever their extension
+)
+
+// FileClassNames lists the file classes a theme can style
+var FileClassNames = []string{string(ClassCode), string(ClassDocs), string(ClassConfig), string(ClassBinary), string(ClassLarge)}
+
+// extensionGroups are the builtin extensions of each class (lowercase, without the dot)
+var extensionGroups = map[FileClass][]string{
+	ClassCode: {
+		"go", "py", "js", "mjs", "cjs", "ts", "jsx", "tsx", "rs", "c", "h", "cc", "cpp", "hpp", "cs",
+		"java", "kt", "scala", "swift", "m", "rb", "php", "pl", "lua", "r", "dart", "ex", "exs", "erl",
+		"hs", "clj", "ml", "zig", "nim", "sh", "bash", "zsh", "fish", "ps1", "sql", "html", "css",
+		"scss", "vue", "svelte",
+	},
+	ClassDocs: {"md", "markdown", "txt", "rst", "adoc", "org", "tex", "info", "pdf", "1", "man"},
+	ClassConfig: {
+		"json", "yaml", "yml", "toml", "ini", "cfg", "conf", "xml", "env", "properties", "lock",
+		"gitignore", "gitattributes", "editorconfig", "dockerignore",
+	},
+	ClassBinary: {
+		"exe", "dll", "so", "dylib", "a", "o", "bin", "class", "jar", "wasm", "zip", "tar", "gz",
+		"tgz", "bz2", "xz", "zst", "7z", "rar", "png", "jpg", "jpeg", "gif", "bmp", "ico", "webp",
+		"mp3", "mp4", "mov", "avi", "wav", "woff", "woff2", "ttf", "otf", "db", "sqlite",
+	},
+}
+
+// ColorRules decide the file class of each file name
+type ColorRules struct {
+	Extensions map[string]FileClass // Class by lowercase extension without the dot
+	LargeSize  int64                // Files of at least this many bytes are ClassLarge (0 = off)
+}
+
+// DefaultColorRules returns the builtin extension groups, without a large file size
+func DefaultColorRules() ColorRules {
+	rules := ColorRules{Extensions: make(map[string]FileClass)}
+	for class, extensions := range extensionGroups {
+		rules.AddExtensions(class, extensions)
+	}
+	return rules
+}
+
+// AddExtensions assigns extensions (with or without the dot) to class, replacing their
+// previous class
+func (r *ColorRules) AddExtensions(class FileClass, extensions []string) {
+	for _, ext := range extensions {
+		r.Extensions[strings.ToLower(strings.TrimPrefix(ext, "."))] = class
+	}
+}
+
+// Class returns the class of a node: large files first, then by extension
+// Directories have no class.
+func (r *ColorRules) Class(name string, isDir bool, size int64) FileClass {
+	if isDir {
+		return ClassNone
+	}
+	if r.LargeSize > 0 && size >= r.LargeSize {
+		return ClassLarge
+	}
+	if ext := strings.TrimPrefix(path.Ext(strings.ToLower(name)), "."); ext != "" {
+		return r.Extensions[ext]
+	}
+	return ClassNone
+}
+
+// ParseSize parses a size such as "10MB", "512K", "1.5GiB" or "2048" (bytes)
+// Units are powers of 1024, as treex prints sizes; "0", "off" and "" mean no size.
+func ParseSize(value string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(value))
+	if text == "" || text == "OFF" {
+		return 0, nil
+	}
+
+	number := strings.TrimRight(strings.TrimSuffix(strings.TrimSuffix(text, "IB"), "B"), "KMGT")
+	unit := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(text, number), "IB"), "B")
+	size, err := strconv.ParseFloat(number, 64)
+	if err != nil || size < 0 || math.IsInf(size, 0) || math.IsNaN(size) || len(unit) > 1 {
+		return 0, fmt.Errorf("invalid size %q (use bytes or a number with K, M, G or T, e.g. 10MB)", value)
+	}
+	multiplier := int64(1)
+	if unit != "" {
+		multiplier = 1 << (10 * (strings.Index("KMGT", unit) + 1))
+	}
+	return int64(size * float64(multiplier)), nil
+}
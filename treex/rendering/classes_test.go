@@ -0,0 +1,126 @@
+package rendering_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+func TestColorRulesClass(t *testing.T) {
+	rules := rendering.DefaultColorRules()
+	rules.LargeSize = 1024
+
+	tests := []struct {
+		name     string
+		isDir    bool
+		size     int64
+		expected rendering.FileClass
+	}{
+		{"main.go", false, 10, rendering.ClassCode},
+		{"README.MD", false, 10, rendering.ClassDocs},
+		{"config.yaml", false, 10, rendering.ClassConfig},
+		{".gitignore", false, 10, rendering.ClassConfig},
+		{"logo.png", false, 10, rendering.ClassBinary},
+		{"Makefile", false, 10, rendering.ClassNone},
+		{"notes.unknown", false, 10, rendering.ClassNone},
+		{"data.json", false, 1024, rendering.ClassLarge},
+		{"src.go", true, 4096, rendering.ClassNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rules.Class(tt.name, tt.isDir, tt.size))
+		})
+	}
+
+	t.Run("no large size", func(t *testing.T) {
+		rules := rendering.DefaultColorRules()
+		assert.Equal(t, rendering.ClassCode, rules.Class("huge.go", false, 1<<40))
+	})
+
+	t.Run("added extensions replace their class", func(t *testing.T) {
+		rules := rendering.DefaultColorRules()
+		rules.AddExtensions(rendering.ClassCode, []string{".ODIN", "txt"})
+		assert.Equal(t, rendering.ClassCode, rules.Class("main.odin", false, 0))
+		assert.Equal(t, rendering.ClassCode, rules.Class("notes.txt", false, 0))
+	})
+}
+
+func TestParseSize(t *testing.T) {
+	valid := map[string]int64{
+		"":       0,
+		"0":      0,
+		"off":    0,
+		"2048":   2048,
+		"512B":   512,
+		"4k":     4096,
+		"10MB":   10 << 20,
+		"1.5GiB": 3 << 29,
+		"1T":     1 << 40,
+	}
+	for value, expected := range valid {
+		size, err := rendering.ParseSize(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, size, value)
+	}
+
+	for _, value := range []string{"big", "10XB", "10MM", "-1K", "inf", "MB"} {
+		_, err := rendering.ParseSize(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestRendererFileClasses(t *testing.T) {
+	colorful, err := rendering.FindTheme(afero.NewMemMapFs(), "/themes", "colorful")
+	require.NoError(t, err)
+	rules, err := colorful.ColorRules()
+	require.NoError(t, err)
+
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	for _, name := range []string{"Makefile", "main.go", "video.mp4"} {
+		root.Children = append(root.Children, &types.Node{Name: name, Path: name, Parent: root})
+	}
+	root.Children[2].Size = 20 << 20
+
+	render := func(classes *rendering.ColorRules) map[string]string {
+		var buf bytes.Buffer
+		renderer := rendering.NewRenderer(rendering.RenderConfig{
+			Writer:      &buf,
+			AutoDetect:  true,
+			Theme:       colorful,
+			Background:  rendering.BackgroundDark,
+			Getenv:      envMap(map[string]string{"CLICOLOR_FORCE": "1"}),
+			FileClasses: classes,
+		})
+		require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: root}))
+
+		lines := make(map[string]string)
+		for _, line := range strings.Split(buf.String(), "\n") {
+			for _, child := range root.Children {
+				if strings.Contains(line, child.Name) {
+					lines[child.Name] = line
+				}
+			}
+		}
+		return lines
+	}
+
+	t.Run("classes color names", func(t *testing.T) {
+		lines := render(&rules)
+		assert.True(t, strings.HasSuffix(lines["Makefile"], "\x1b[0mMakefile"), "files without a class keep the file name style: %q", lines["Makefile"])
+		assert.Contains(t, lines["main.go"], "main.go\x1b[0m")
+		assert.Contains(t, lines["video.mp4"], "\x1b[1;", "large files are bold in the colorful theme")
+	})
+
+	t.Run("nil rules leave names unstyled", func(t *testing.T) {
+		for name, line := range render(nil) {
+			assert.True(t, strings.HasSuffix(line, "\x1b[0m"+name), "%q", line)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package rendering
+
+import (
+	"fmt"
+
+	"treex/treex/types"
+)
+
+// collapseMarker is appended to the name of a collapsed directory
+// Collapsed directories keep their place in the tree as a single summary line, so deep
+// content is never cut off silently.
+const collapseMarker = "/…"
+
+// subtreeSummary counts what a collapsed directory hides
+type subtreeSummary struct {
+	Files     int // Files anywhere below the directory
+	Annotated int // Files and directories below the directory that have annotation notes
+}
+
+// summarizeSubtree counts the descendants of node (node itself is not counted)
+func summarizeSubtree(node *types.Node) subtreeSummary {
+	var summary subtreeSummary
+	for _, child := range node.Children {
+		if !child.IsDir {
+			summary.Files++
+		}
+		if annotation := child.GetAnnotation(); annotation != nil && annotation.Notes != "" {
+			summary.Annotated++
+		}
+
+		nested := summarizeSubtree(child)
+		summary.Files += nested.Files
+		summary.Annotated += nested.Annotated
+	}
+	return summary
+}
+
+// String formats the summary as "(42 files, 12 annotated)", omitting a zero annotated count
+func (s subtreeSummary) String() string {
+	files := "files"
+	if s.Files == 1 {
+		files = "file"
+	}
+	if s.Annotated == 0 {
+		return fmt.Sprintf("(%d %s)", s.Files, files)
+	}
+	return fmt.Sprintf("(%d %s, %d annotated)", s.Files, files, s.Annotated)
+}
+
+// shouldCollapse reports whether a directory at depth is rendered as a summary line
+// Like --level, a collapse depth of n shows entries down to depth n; directories at
+// depth n have their contents summarized instead of listed
+func (r *Renderer) shouldCollapse(node *types.Node, depth int) bool {
+	return r.config.CollapseDepth > 0 && node.IsDir && depth >= r.config.CollapseDepth && len(node.Children) > 0
+}
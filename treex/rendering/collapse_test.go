@@ -0,0 +1,93 @@
+package rendering_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+// deepTree builds project/internal/{api/{handler.go, routes.go}, db/schema.sql}, main.go
+func deepTree() *types.Node {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	internal := &types.Node{Name: "internal", Path: "internal", IsDir: true}
+	api := &types.Node{Name: "api", Path: "internal/api", IsDir: true}
+	db := &types.Node{Name: "db", Path: "internal/db", IsDir: true}
+	handler := &types.Node{Name: "handler.go", Path: "internal/api/handler.go"}
+	routes := &types.Node{Name: "routes.go", Path: "internal/api/routes.go"}
+	schema := &types.Node{Name: "schema.sql", Path: "internal/db/schema.sql"}
+	main := &types.Node{Name: "main.go", Path: "main.go"}
+
+	handler.SetAnnotation(&types.Annotation{Path: handler.Path, Notes: "HTTP handlers"})
+	api.SetAnnotation(&types.Annotation{Path: api.Path, Notes: "Public API"})
+
+	addChildren(root, internal, main)
+	addChildren(internal, api, db)
+	addChildren(api, handler, routes)
+	addChildren(db, schema)
+	return root
+}
+
+// addChildren attaches children to a parent node
+func addChildren(parent *types.Node, children ...*types.Node) {
+	for _, child := range children {
+		child.Parent = parent
+		parent.Children = append(parent.Children, child)
+	}
+}
+
+func renderCollapsed(t *testing.T, depth int) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format:        rendering.FormatPlain,
+		Writer:        &buf,
+		Charset:       rendering.CharsetASCII,
+		Width:         -1,
+		CollapseDepth: depth,
+	})
+	require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: deepTree()}))
+	return buf.String()
+}
+
+func TestCollapseDirs(t *testing.T) {
+	tests := []struct {
+		depth    int
+		expected string
+	}{
+		{
+			depth: 1,
+			expected: "project\n" +
+				"+- internal/… (3 files, 2 annotated)\n" +
+				"\\- main.go\n",
+		},
+		{
+			depth: 2,
+			expected: "project\n" +
+				"+- internal\n" +
+				"|  +- api/… (2 files, 1 annotated)\n" +
+				"|  \\- db/… (1 file)\n" +
+				"\\- main.go\n",
+		},
+		{
+			depth: 0,
+			expected: "project\n" +
+				"+- internal\n" +
+				"|  +- api\n" +
+				"|  |  +- handler.go\n" +
+				"|  |  \\- routes.go\n" +
+				"|  \\- db\n" +
+				"|     \\- schema.sql\n" +
+				"\\- main.go\n",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, renderCollapsed(t, tt.depth), "collapse depth %d", tt.depth)
+	}
+}
@@ -0,0 +1,68 @@
+package rendering
+
+import (
+	"os"
+)
+
+// ColorMode is the color decision derived from the environment
+// The NO_COLOR (https://no-color.org) and CLICOLOR / CLICOLOR_FORCE
+// (https://bixense.com/clicolors) conventions are resolved here once, so individual
+// renderers never inspect the environment themselves.
+type ColorMode int
+
+const (
+	ColorAuto   ColorMode = iota // Color when writing to a terminal
+	ColorNever                   // Never emit color (NO_COLOR, CLICOLOR=0)
+	ColorAlways                  // Emit color even when piping (CLICOLOR_FORCE)
+)
+
+// String returns the string representation of the color mode
+func (m ColorMode) String() string {
+	switch m {
+	case ColorNever:
+		return "never"
+	case ColorAlways:
+		return "always"
+	default:
+		return "auto"
+	}
+}
+
+// ColorModeFromEnv applies the color environment conventions in precedence order:
+// 1. NO_COLOR (any non-empty value) disables color
+// 2. CLICOLOR_FORCE (non-empty, not "0") forces color, even when piping
+// 3. CLICOLOR=0 disables color
+// 4. Otherwise color depends on whether the output is a terminal
+func ColorModeFromEnv(getenv func(string) string) ColorMode {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+
+	if getenv("NO_COLOR") != "" {
+		return ColorNever
+	}
+	if force := getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return ColorAlways
+	}
+	if getenv("CLICOLOR") == "0" {
+		return ColorNever
+	}
+	return ColorAuto
+}
+
+// ColorSettings decides whether styles are enabled and whether color must be forced
+// for the given output format. noColor is an explicit caller override (e.g. a flag)
+func ColorSettings(format OutputFormat, noColor bool, getenv func(string) string) (enabled bool, forced bool) {
+	if format != FormatTerm || noColor {
+		return false, false
+	}
+
+	switch ColorModeFromEnv(getenv) {
+	case ColorNever:
+		return false, false
+	case ColorAlways:
+		return true, true
+	default:
+		return true, false
+	}
+}
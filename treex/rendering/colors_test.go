@@ -0,0 +1,99 @@
+package rendering_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+func TestColorModeFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected rendering.ColorMode
+	}{
+		{"empty environment", map[string]string{}, rendering.ColorAuto},
+		{"NO_COLOR", map[string]string{"NO_COLOR": "1"}, rendering.ColorNever},
+		{"CLICOLOR_FORCE", map[string]string{"CLICOLOR_FORCE": "1"}, rendering.ColorAlways},
+		{"CLICOLOR_FORCE=0 is ignored", map[string]string{"CLICOLOR_FORCE": "0"}, rendering.ColorAuto},
+		{"CLICOLOR=0", map[string]string{"CLICOLOR": "0"}, rendering.ColorNever},
+		{"CLICOLOR=1", map[string]string{"CLICOLOR": "1"}, rendering.ColorAuto},
+		{"NO_COLOR wins over CLICOLOR_FORCE", map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"}, rendering.ColorNever},
+		{"CLICOLOR_FORCE wins over CLICOLOR=0", map[string]string{"CLICOLOR": "0", "CLICOLOR_FORCE": "1"}, rendering.ColorAlways},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rendering.ColorModeFromEnv(envMap(tt.env)))
+		})
+	}
+}
+
+func TestColorSettings(t *testing.T) {
+	tests := []struct {
+		name            string
+		format          rendering.OutputFormat
+		noColor         bool
+		env             map[string]string
+		expectedEnabled bool
+		expectedForced  bool
+	}{
+		{"term auto", rendering.FormatTerm, false, map[string]string{}, true, false},
+		{"term NO_COLOR", rendering.FormatTerm, false, map[string]string{"NO_COLOR": "1"}, false, false},
+		{"term forced", rendering.FormatTerm, false, map[string]string{"CLICOLOR_FORCE": "1"}, true, true},
+		{"explicit no color beats force", rendering.FormatTerm, true, map[string]string{"CLICOLOR_FORCE": "1"}, false, false},
+		{"plain never colors", rendering.FormatPlain, false, map[string]string{"CLICOLOR_FORCE": "1"}, false, false},
+		{"json never colors", rendering.FormatJSON, false, map[string]string{"CLICOLOR_FORCE": "1"}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enabled, forced := rendering.ColorSettings(tt.format, tt.noColor, envMap(tt.env))
+			assert.Equal(t, tt.expectedEnabled, enabled)
+			assert.Equal(t, tt.expectedForced, forced)
+		})
+	}
+}
+
+func TestRendererColorEnvironment(t *testing.T) {
+	colorful, err := rendering.FindTheme(afero.NewMemMapFs(), "/themes", "colorful")
+	require.NoError(t, err)
+
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	child := &types.Node{Name: "main.go", Path: "main.go", Parent: root}
+	child.SetAnnotation(&types.Annotation{Path: "main.go", Notes: "Entry point"})
+	root.Children = []*types.Node{child}
+
+	render := func(env map[string]string) string {
+		var buf bytes.Buffer
+		renderer := rendering.NewRenderer(rendering.RenderConfig{
+			Writer:     &buf,
+			AutoDetect: true,
+			ShowNotes:  true,
+			Theme:      colorful,
+			Background: rendering.BackgroundDark,
+			Getenv:     envMap(env),
+		})
+		require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: root}))
+		return buf.String()
+	}
+
+	t.Run("CLICOLOR_FORCE emits ANSI when not a terminal", func(t *testing.T) {
+		output := render(map[string]string{"CLICOLOR_FORCE": "1"})
+		assert.Contains(t, output, "\x1b[")
+		assert.Contains(t, output, "main.go")
+	})
+
+	t.Run("NO_COLOR emits plain text", func(t *testing.T) {
+		output := render(map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"})
+		assert.False(t, strings.Contains(output, "\x1b["), "unexpected escape sequences in %q", output)
+		assert.Contains(t, output, "Entry point")
+	})
+}
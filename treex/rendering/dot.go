@@ -0,0 +1,88 @@
+package rendering
+
+import (
+	"fmt"
+	"strings"
+
+	"treex/treex"
+	"treex/treex/types"
+)
+
+// RankDirs lists the Graphviz rankdir values accepted by ParseRankDir
+var RankDirs = []string{"LR", "TB", "RL", "BT"}
+
+// ParseRankDir validates a --rankdir value; an empty value means LR (left to right),
+// which keeps wide trees readable
+func ParseRankDir(value string) (string, error) {
+	rankDir := strings.ToUpper(strings.TrimSpace(value))
+	if rankDir == "" {
+		return "LR", nil
+	}
+	for _, valid := range RankDirs {
+		if rankDir == valid {
+			return rankDir, nil
+		}
+	}
+	return "", fmt.Errorf("unknown rankdir %q (valid: %s)", value, strings.Join(RankDirs, ", "))
+}
+
+// renderDot outputs the tree as a Graphviz digraph: directories are folders, files are
+// boxes, and annotations become tooltips (and a second label line with ShowNotes)
+// Nodes are numbered in tree order so the output is stable for the same tree.
+func (r *Renderer) renderDot(result *treex.TreeResult) error {
+	rankDir, err := ParseRankDir(r.config.RankDir)
+	if err != nil {
+		return err
+	}
+
+	var out strings.Builder
+	out.WriteString("digraph treex {\n")
+	fmt.Fprintf(&out, "  rankdir=%s;\n", rankDir)
+	out.WriteString("  node [shape=box, fontname=\"Helvetica\", fontsize=10];\n")
+	out.WriteString("  edge [arrowhead=none];\n")
+
+	next := 0
+	var walk func(node *types.Node) string
+	walk = func(node *types.Node) string {
+		id := fmt.Sprintf("n%d", next)
+		next++
+
+		label := node.Name
+		attributes := []string{}
+		if node.IsDir {
+			label += "/"
+			attributes = append(attributes, "shape=folder")
+		}
+		if annotation := node.GetAnnotation(); annotation != nil && annotation.Notes != "" {
+			if r.config.ShowNotes {
+				label += "\n" + annotation.Notes
+			}
+			attributes = append(attributes, "tooltip="+dotQuote(annotation.Notes))
+		}
+		if r.config.ShowErrors && node.Error != "" {
+			label += "\n[" + node.Error + "]"
+			attributes = append(attributes, "color=red")
+		}
+		attributes = append([]string{"label=" + dotQuote(label)}, attributes...)
+		fmt.Fprintf(&out, "  %s [%s];\n", id, strings.Join(attributes, ", "))
+
+		for _, child := range node.Children {
+			childID := walk(child)
+			fmt.Fprintf(&out, "  %s -> %s;\n", id, childID)
+		}
+		return id
+	}
+	if result.Root != nil {
+		walk(result.Root)
+	}
+	out.WriteString("}\n")
+
+	_, err = r.config.Writer.Write([]byte(out.String()))
+	return err
+}
+
+// dotQuote quotes s as a DOT string; newlines become centered line breaks
+func dotQuote(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + replacer.Replace(s) + `"`
+}
@@ -0,0 +1,58 @@
+package rendering_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+func TestRenderDot(t *testing.T) {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	src := &types.Node{Name: "src", Path: "src", IsDir: true, Parent: root}
+	main := &types.Node{Name: "main.go", Path: "src/main.go", Parent: src}
+	main.SetAnnotation(&types.Annotation{Path: "src/main.go", Notes: `Entry "point"`})
+	src.Children = []*types.Node{main}
+	root.Children = []*types.Node{src, {Name: "README.md", Path: "README.md", Parent: root}}
+	result := &treex.TreeResult{Root: root}
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatDot, Writer: &buf, ShowNotes: true})
+	require.NoError(t, renderer.RenderTree(result))
+	assert.Equal(t, `digraph treex {
+  rankdir=LR;
+  node [shape=box, fontname="Helvetica", fontsize=10];
+  edge [arrowhead=none];
+  n0 [label="project/", shape=folder];
+  n1 [label="src/", shape=folder];
+  n2 [label="main.go\nEntry \"point\"", tooltip="Entry \"point\""];
+  n1 -> n2;
+  n0 -> n1;
+  n3 [label="README.md"];
+  n0 -> n3;
+}
+`, buf.String())
+
+	buf.Reset()
+	renderer = rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatDot, Writer: &buf, RankDir: "tb"})
+	require.NoError(t, renderer.RenderTree(result))
+	assert.Contains(t, buf.String(), "  rankdir=TB;\n")
+	assert.Contains(t, buf.String(), `n2 [label="main.go", tooltip="Entry \"point\""];`, "notes stay tooltips without ShowNotes")
+}
+
+func TestParseRankDir(t *testing.T) {
+	rankDir, err := rendering.ParseRankDir("")
+	require.NoError(t, err)
+	assert.Equal(t, "LR", rankDir)
+
+	rankDir, err = rendering.ParseRankDir("bt")
+	require.NoError(t, err)
+	assert.Equal(t, "BT", rankDir)
+
+	_, err = rendering.ParseRankDir("up")
+	assert.ErrorContains(t, err, "valid: LR, TB, RL, BT")
+}
@@ -0,0 +1,49 @@
+package rendering
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"treex/treex"
+	"treex/treex/types"
+)
+
+// RenderDuplicates renders the duplicate files of report as the tree below root (pruned
+// to them, see treex.PruneToDuplicates) with each file's group number as a badge, or as JSON
+func (r *Renderer) RenderDuplicates(root *types.Node, report *treex.DuplicateReport) error {
+	if r.config.Format == FormatJSON {
+		encoder := json.NewEncoder(r.config.Writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	if len(report.Groups) == 0 {
+		_, err := fmt.Fprintln(r.config.Writer, r.styles.StatsItem("No duplicate files"))
+		return err
+	}
+
+	groups := make(duplicateBadges)
+	for i, group := range report.Groups {
+		for _, groupPath := range group.Paths {
+			groups[groupPath] = fmt.Sprintf("#%d", i+1)
+		}
+	}
+	r.config.Badges = append([]BadgeSource{groups}, r.config.Badges...)
+	if err := r.renderText(context.Background(), &treex.TreeResult{Root: root}); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(r.config.Writer, "\n%s\n", r.styles.StatsItem(fmt.Sprintf("%d groups of duplicates: %d files, %s reclaimable",
+		len(report.Groups), report.Files, formatSize(report.Reclaimable))))
+	return err
+}
+
+// duplicateBadges maps the slash-separated path of each duplicate file to its group badge
+type duplicateBadges map[string]string
+
+// Badge returns the group of node, "" for entries that are not duplicates
+func (b duplicateBadges) Badge(node *types.Node) string {
+	return b[filepath.ToSlash(node.Path)]
+}
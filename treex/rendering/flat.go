@@ -0,0 +1,105 @@
+package rendering
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"treex/treex"
+	"treex/treex/pathutil"
+	"treex/treex/types"
+)
+
+// FlatOrders lists the orders accepted by ParseFlatOrder
+var FlatOrders = []string{"tree", "path", "annotated"}
+
+// ParseFlatOrder validates a --flat-order value; an empty value means tree order
+func ParseFlatOrder(value string) (string, error) {
+	order := strings.ToLower(strings.TrimSpace(value))
+	if order == "" {
+		return "tree", nil
+	}
+	for _, valid := range FlatOrders {
+		if order == valid {
+			return order, nil
+		}
+	}
+	return "", fmt.Errorf("unknown flat order %q (valid: %s)", value, strings.Join(FlatOrders, ", "))
+}
+
+// flatEntry is one line of the flat listing
+type flatEntry struct {
+	path  string
+	notes string
+}
+
+// renderFlat outputs one path per line, relative to the root, with its annotation on a
+// shared column and no tree connectors, so every line can be grepped on its own
+// Directories end in "/"; multi-line annotations are joined into one line. Entries of
+// combined results start with the name of their root.
+func (r *Renderer) renderFlat(result *treex.TreeResult) error {
+	order, err := ParseFlatOrder(r.config.FlatOrder)
+	if err != nil {
+		return err
+	}
+
+	var entries []flatEntry
+	if result.Roots != nil {
+		for _, root := range result.Roots {
+			if root.Root != nil {
+				entries = append(entries, flatEntries(root.Root, root.Root.Name+"/", r.config.Traversal)...)
+			}
+		}
+	} else if result.Root != nil {
+		entries = flatEntries(result.Root, "", r.config.Traversal)
+	}
+
+	switch order {
+	case "path":
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].path < entries[j].path })
+	case "annotated":
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].notes != "" && entries[j].notes == "" })
+	}
+
+	column := 0
+	for _, entry := range entries {
+		if entry.notes != "" {
+			column = max(column, ansi.StringWidth(entry.path))
+		}
+	}
+
+	var out strings.Builder
+	for _, entry := range entries {
+		out.WriteString(entry.path)
+		if entry.notes != "" {
+			out.WriteString(strings.Repeat(" ", column-ansi.StringWidth(entry.path)+2))
+			out.WriteString(entry.notes)
+		}
+		out.WriteString("\n")
+	}
+	if _, err := r.config.Writer.Write([]byte(out.String())); err != nil {
+		return err
+	}
+
+	if r.config.ShowStats {
+		return r.renderStats(result)
+	}
+	return nil
+}
+
+// flatEntries lists the nodes below root in traversal order, each path prefixed with prefix
+func flatEntries(root *types.Node, prefix string, traversal Traversal) []flatEntry {
+	var entries []flatEntry
+	walkEntries(root, traversal, func(node *types.Node) {
+		entry := flatEntry{path: prefix + pathutil.Normalize(node.Path)}
+		if node.IsDir {
+			entry.path += "/"
+		}
+		if annotation := node.GetAnnotation(); annotation != nil {
+			entry.notes = strings.Join(strings.Fields(annotation.Notes), " ")
+		}
+		entries = append(entries, entry)
+	})
+	return entries
+}
@@ -0,0 +1,117 @@
+package rendering_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+func flatTree() *treex.TreeResult {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	src := &types.Node{Name: "src", Path: "src", IsDir: true, Parent: root}
+	main := &types.Node{Name: "main.go", Path: "src/main.go", Parent: src}
+	main.SetAnnotation(&types.Annotation{Path: "src/main.go", Notes: "Entry point\nParses flags"})
+	src.Children = []*types.Node{main}
+	docs := &types.Node{Name: "docs", Path: "docs", IsDir: true, Parent: root}
+	docs.SetAnnotation(&types.Annotation{Path: "docs", Notes: "User guide"})
+	readme := &types.Node{Name: "README.md", Path: "README.md", Parent: root}
+	root.Children = []*types.Node{src, docs, readme}
+	return &treex.TreeResult{Root: root}
+}
+
+func renderFlat(t *testing.T, result *treex.TreeResult, order string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatFlat, Writer: &buf, FlatOrder: order})
+	require.NoError(t, renderer.RenderTree(result))
+	return buf.String()
+}
+
+func TestRenderFlat(t *testing.T) {
+	assert.Equal(t, "src/\n"+
+		"src/main.go  Entry point Parses flags\n"+
+		"docs/        User guide\n"+
+		"README.md\n", renderFlat(t, flatTree(), ""))
+}
+
+func TestRenderFlatOrders(t *testing.T) {
+	assert.Equal(t, "README.md\n"+
+		"docs/        User guide\n"+
+		"src/\n"+
+		"src/main.go  Entry point Parses flags\n", renderFlat(t, flatTree(), "path"))
+
+	assert.Equal(t, "src/main.go  Entry point Parses flags\n"+
+		"docs/        User guide\n"+
+		"src/\n"+
+		"README.md\n", renderFlat(t, flatTree(), "annotated"), "annotated paths first, each group in tree order")
+}
+
+func TestRenderFlatCombinedRoots(t *testing.T) {
+	combined := treex.CombineResults("2 roots", []string{"one", "two"}, []*treex.TreeResult{flatTree(), flatTree()})
+
+	output := renderFlat(t, combined, "")
+	assert.Contains(t, output, "one/src/main.go")
+	assert.Contains(t, output, "two/README.md\n")
+}
+
+func TestParseFlatOrder(t *testing.T) {
+	order, err := rendering.ParseFlatOrder("")
+	require.NoError(t, err)
+	assert.Equal(t, "tree", order)
+
+	_, err = rendering.ParseFlatOrder("size")
+	assert.ErrorContains(t, err, "valid: tree, path, annotated")
+}
+
+func TestRenderFullPaths(t *testing.T) {
+	result := flatTree()
+	result.Root.Name = "project"
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatPlain, Writer: &buf, FullPaths: true})
+	require.NoError(t, renderer.RenderTree(result))
+	assert.Equal(t, "project\n"+
+		"├─ project/src\n"+
+		"│  └─ project/src/main.go\n"+
+		"├─ project/docs\n"+
+		"└─ project/README.md\n", buf.String())
+}
+
+func TestRenderFlatWideCharacters(t *testing.T) {
+	result := flatTree()
+	result.Root.Children[0].Children[0].Name = "主程序.go"
+	result.Root.Children[0].Children[0].Path = "src/主程序.go"
+
+	assert.Equal(t, "src/\n"+
+		"src/主程序.go  Entry point Parses flags\n"+
+		"docs/          User guide\n"+
+		"README.md\n", renderFlat(t, result, ""))
+}
+
+func TestRenderFlatBreadthFirst(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatFlat, Writer: &buf, Traversal: rendering.TraversalBFS})
+	require.NoError(t, renderer.RenderTree(flatTree()))
+	assert.Equal(t, "src/\n"+
+		"docs/        User guide\n"+
+		"README.md\n"+
+		"src/main.go  Entry point Parses flags\n", buf.String(), "every entry of a level before the next")
+}
+
+func TestParseTraversal(t *testing.T) {
+	traversal, err := rendering.ParseTraversal("")
+	require.NoError(t, err)
+	assert.Equal(t, rendering.TraversalDFS, traversal)
+
+	traversal, err = rendering.ParseTraversal("BFS")
+	require.NoError(t, err)
+	assert.Equal(t, rendering.TraversalBFS, traversal)
+
+	_, err = rendering.ParseTraversal("random")
+	assert.ErrorContains(t, err, "valid: dfs, bfs")
+}
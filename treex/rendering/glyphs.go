@@ -0,0 +1,87 @@
+package rendering
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Charset names a connector glyph set
+type Charset string
+
+const (
+	CharsetAuto    Charset = "auto"    // Unicode when the locale supports UTF-8, ASCII otherwise
+	CharsetUnicode Charset = "unicode" // ├─ └─ │
+	CharsetASCII   Charset = "ascii"   // +- \- |
+	CharsetRounded Charset = "rounded" // ├─ ╰─ │
+	CharsetDouble  Charset = "double"  // ╠═ ╚═ ║
+)
+
+// GlyphSet holds the connectors drawn in front of each node
+// Every glyph has the same display width so prefixes stay aligned
+type GlyphSet struct {
+	Branch   string // Connector for a node followed by siblings
+	Last     string // Connector for the last child of a directory
+	Vertical string // Prefix continuing a parent that has more siblings
+	Blank    string // Prefix below a parent that was the last child
+}
+
+// glyphSets maps each concrete charset to its glyphs
+var glyphSets = map[Charset]GlyphSet{
+	CharsetUnicode: {Branch: "├─ ", Last: "└─ ", Vertical: "│  ", Blank: "   "},
+	CharsetASCII:   {Branch: "+- ", Last: "\\- ", Vertical: "|  ", Blank: "   "},
+	CharsetRounded: {Branch: "├─ ", Last: "╰─ ", Vertical: "│  ", Blank: "   "},
+	CharsetDouble:  {Branch: "╠═ ", Last: "╚═ ", Vertical: "║  ", Blank: "   "},
+}
+
+// CharsetNames lists the values accepted by ParseCharset
+var CharsetNames = []string{
+	string(CharsetAuto), string(CharsetUnicode), string(CharsetASCII),
+	string(CharsetRounded), string(CharsetDouble),
+}
+
+// ParseCharset validates a --charset value; an empty value means auto
+func ParseCharset(value string) (Charset, error) {
+	charset := Charset(strings.ToLower(strings.TrimSpace(value)))
+	if charset == "" || charset == CharsetAuto {
+		return CharsetAuto, nil
+	}
+	if _, ok := glyphSets[charset]; !ok {
+		return "", fmt.Errorf("unknown charset %q (valid: %s)", value, strings.Join(CharsetNames, ", "))
+	}
+	return charset, nil
+}
+
+// Glyphs returns the glyph set for a charset
+// Auto is resolved from the locale; unknown charsets fall back to unicode
+func Glyphs(charset Charset, getenv func(string) string) GlyphSet {
+	if charset == "" || charset == CharsetAuto {
+		charset = charsetFromLocale(getenv)
+	}
+	if glyphs, ok := glyphSets[charset]; ok {
+		return glyphs
+	}
+	return glyphSets[CharsetUnicode]
+}
+
+// charsetFromLocale picks ASCII when the effective locale does not use UTF-8
+// The effective locale is the first non-empty of LC_ALL, LC_CTYPE and LANG.
+// An unset locale is assumed to be UTF-8 capable, as on most modern terminals.
+func charsetFromLocale(getenv func(string) string) Charset {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		locale := getenv(key)
+		if locale == "" {
+			continue
+		}
+		lower := strings.ToLower(locale)
+		if strings.Contains(lower, "utf-8") || strings.Contains(lower, "utf8") {
+			return CharsetUnicode
+		}
+		return CharsetASCII
+	}
+	return CharsetUnicode
+}
@@ -0,0 +1,94 @@
+package rendering_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+func TestParseCharset(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    rendering.Charset
+		expectError bool
+	}{
+		{"", rendering.CharsetAuto, false},
+		{"auto", rendering.CharsetAuto, false},
+		{"ascii", rendering.CharsetASCII, false},
+		{"Unicode", rendering.CharsetUnicode, false},
+		{"rounded", rendering.CharsetRounded, false},
+		{"double", rendering.CharsetDouble, false},
+		{"emoji", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			charset, err := rendering.ParseCharset(tt.value)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, charset)
+		})
+	}
+}
+
+func TestGlyphsAutoFromLocale(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{"no locale", map[string]string{}, "└─ "},
+		{"UTF-8 LANG", map[string]string{"LANG": "en_US.UTF-8"}, "└─ "},
+		{"utf8 LC_CTYPE", map[string]string{"LC_CTYPE": "C.utf8"}, "└─ "},
+		{"C locale", map[string]string{"LANG": "C"}, "\\- "},
+		{"LC_ALL overrides LANG", map[string]string{"LC_ALL": "POSIX", "LANG": "en_US.UTF-8"}, "\\- "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			glyphs := rendering.Glyphs(rendering.CharsetAuto, envMap(tt.env))
+			assert.Equal(t, tt.expected, glyphs.Last)
+		})
+	}
+}
+
+func TestRendererCharset(t *testing.T) {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	src := &types.Node{Name: "src", Path: "src", IsDir: true, Parent: root}
+	readme := &types.Node{Name: "README.txt", Path: "README.txt", Parent: root}
+	main := &types.Node{Name: "main.go", Path: "src/main.go", Parent: src}
+	util := &types.Node{Name: "util.go", Path: "src/util.go", Parent: src}
+	root.Children = []*types.Node{src, readme}
+	src.Children = []*types.Node{main, util}
+
+	tests := []struct {
+		charset  rendering.Charset
+		expected string
+	}{
+		{rendering.CharsetASCII, "project\n+- src\n|  +- main.go\n|  \\- util.go\n\\- README.txt\n"},
+		{rendering.CharsetUnicode, "project\n├─ src\n│  ├─ main.go\n│  └─ util.go\n└─ README.txt\n"},
+		{rendering.CharsetRounded, "project\n├─ src\n│  ├─ main.go\n│  ╰─ util.go\n╰─ README.txt\n"},
+		{rendering.CharsetDouble, "project\n╠═ src\n║  ╠═ main.go\n║  ╚═ util.go\n╚═ README.txt\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.charset), func(t *testing.T) {
+			var buf bytes.Buffer
+			renderer := rendering.NewRenderer(rendering.RenderConfig{
+				Format:  rendering.FormatPlain,
+				Writer:  &buf,
+				Charset: tt.charset,
+			})
+			require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: root}))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package rendering
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// HyperlinkMode decides when entry names are rendered as terminal hyperlinks
+type HyperlinkMode string
+
+const (
+	HyperlinksAuto   HyperlinkMode = "auto"   // When the output is a terminal known to support OSC 8
+	HyperlinksAlways HyperlinkMode = "always" // Whenever the terminal format is used, even when piping
+	HyperlinksNever  HyperlinkMode = "never"
+)
+
+// HyperlinkModes lists the values accepted by ParseHyperlinkMode
+var HyperlinkModes = []string{string(HyperlinksAuto), string(HyperlinksAlways), string(HyperlinksNever)}
+
+// ParseHyperlinkMode validates a --hyperlinks value; an empty value means auto
+func ParseHyperlinkMode(value string) (HyperlinkMode, error) {
+	mode := HyperlinkMode(strings.ToLower(strings.TrimSpace(value)))
+	switch mode {
+	case "":
+		return HyperlinksAuto, nil
+	case HyperlinksAuto, HyperlinksAlways, HyperlinksNever:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown hyperlinks mode %q (valid: %s)", value, strings.Join(HyperlinkModes, ", "))
+}
+
+// HyperlinkSettings decides whether entry names are hyperlinks for the given output
+// format. Only the terminal format carries escape sequences; auto also needs the output
+// to be a terminal that supports OSC 8 (see HyperlinksSupported).
+func HyperlinkSettings(mode HyperlinkMode, format OutputFormat, output io.Writer, getenv func(string) string) bool {
+	if format != FormatTerm {
+		return false
+	}
+	switch mode {
+	case HyperlinksAlways:
+		return true
+	case HyperlinksNever:
+		return false
+	}
+	file, ok := output.(*os.File)
+	if !ok || !term.IsTerminal(file.Fd()) {
+		return false
+	}
+	return HyperlinksSupported(getenv)
+}
+
+// HyperlinksSupported reports whether the terminal described by the environment renders
+// OSC 8 hyperlinks. FORCE_HYPERLINK overrides detection (non-empty and not "0" enables
+// links, "0" disables them), as in the supports-hyperlinks convention.
+func HyperlinksSupported(getenv func(string) string) bool {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+
+	if force := getenv("FORCE_HYPERLINK"); force != "" {
+		return force != "0"
+	}
+	if getenv("WT_SESSION") != "" || getenv("KITTY_WINDOW_ID") != "" ||
+		getenv("KONSOLE_VERSION") != "" || getenv("DOMTERM") != "" {
+		return true
+	}
+	if version, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && version >= 5000 {
+		return true
+	}
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper":
+		return true
+	}
+	switch getenv("TERM") {
+	case "xterm-kitty", "alacritty", "foot", "xterm-ghostty", "wezterm":
+		return true
+	}
+	return false
+}
+
+// osc8 wraps text in an OSC 8 hyperlink to target
+func osc8(text, target string) string {
+	return "\x1b]8;;" + target + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// LinkURL returns the hyperlink target of the entry at relPath (slash-separated, relative
+// to absRoot). Without a template it is a file:// URL of the entry. A template, such as
+// "https://github.com/owner/repo/blob/main/{path}", replaces {path} with the escaped
+// relative path and {abspath} with the escaped absolute path.
+func LinkURL(template, absRoot, relPath string) string {
+	absPath := filepath.Join(absRoot, filepath.FromSlash(relPath))
+	if template == "" {
+		link := url.URL{Scheme: "file", Path: filepath.ToSlash(absPath)}
+		if !strings.HasPrefix(link.Path, "/") {
+			// Windows drive paths: file:///C:/...
+			link.Path = "/" + link.Path
+		}
+		return link.String()
+	}
+	relPath = strings.TrimPrefix(relPath, "./")
+	if relPath == "." {
+		relPath = ""
+	}
+	return strings.NewReplacer(
+		"{path}", escapePath(relPath),
+		"{abspath}", escapePath(filepath.ToSlash(absPath)),
+	).Replace(template)
+}
+
+// escapePath escapes each segment of a slash-separated path for use in a URL
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
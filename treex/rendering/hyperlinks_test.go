@@ -0,0 +1,93 @@
+package rendering_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+func TestParseHyperlinkMode(t *testing.T) {
+	mode, err := rendering.ParseHyperlinkMode("")
+	require.NoError(t, err)
+	assert.Equal(t, rendering.HyperlinksAuto, mode)
+
+	mode, err = rendering.ParseHyperlinkMode("Always")
+	require.NoError(t, err)
+	assert.Equal(t, rendering.HyperlinksAlways, mode)
+
+	_, err = rendering.ParseHyperlinkMode("sometimes")
+	assert.ErrorContains(t, err, "valid: auto, always, never")
+}
+
+func TestHyperlinksSupported(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"unknown terminal", map[string]string{"TERM": "xterm-256color"}, false},
+		{"iTerm2", map[string]string{"TERM_PROGRAM": "iTerm.app"}, true},
+		{"kitty", map[string]string{"TERM": "xterm-kitty"}, true},
+		{"recent VTE", map[string]string{"VTE_VERSION": "6003"}, true},
+		{"old VTE", map[string]string{"VTE_VERSION": "4601"}, false},
+		{"forced on", map[string]string{"FORCE_HYPERLINK": "1"}, true},
+		{"forced off", map[string]string{"FORCE_HYPERLINK": "0", "TERM_PROGRAM": "WezTerm"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rendering.HyperlinksSupported(func(key string) string { return tt.env[key] }))
+		})
+	}
+}
+
+func TestHyperlinkSettings(t *testing.T) {
+	getenv := func(string) string { return "" }
+	var buf bytes.Buffer
+	assert.True(t, rendering.HyperlinkSettings(rendering.HyperlinksAlways, rendering.FormatTerm, &buf, getenv))
+	assert.False(t, rendering.HyperlinkSettings(rendering.HyperlinksAlways, rendering.FormatPlain, &buf, getenv), "plain output never has escapes")
+	assert.False(t, rendering.HyperlinkSettings(rendering.HyperlinksNever, rendering.FormatTerm, &buf, getenv))
+	assert.False(t, rendering.HyperlinkSettings(rendering.HyperlinksAuto, rendering.FormatTerm, &buf, getenv), "auto needs a terminal")
+}
+
+func TestLinkURL(t *testing.T) {
+	assert.Equal(t, "file:///work/my%20project/src/main.go", rendering.LinkURL("", "/work/my project", "src/main.go"))
+	assert.Equal(t, "https://github.com/owner/repo/blob/main/docs/a%23b.md",
+		rendering.LinkURL("https://github.com/owner/repo/blob/main/{path}", "/work/repo", "docs/a#b.md"))
+	assert.Equal(t, "https://github.com/owner/repo/blob/main/",
+		rendering.LinkURL("https://github.com/owner/repo/blob/main/{path}", "/work/repo", "."))
+	assert.Equal(t, "vscode://file/work/repo/go.mod", rendering.LinkURL("vscode://file{abspath}", "/work/repo", "go.mod"))
+}
+
+func TestRenderHyperlinks(t *testing.T) {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	root.Children = []*types.Node{{Name: "main.go", Path: "main.go", Parent: root}}
+	result := &treex.TreeResult{Root: root}
+	target := func(node *types.Node) string { return "file:///work/project/" + node.Path }
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format:     rendering.FormatTerm,
+		Writer:     &buf,
+		NoColor:    true,
+		Hyperlinks: rendering.HyperlinksAlways,
+		LinkTarget: target,
+	})
+	require.NoError(t, renderer.RenderTree(result))
+	assert.Contains(t, buf.String(), "\x1b]8;;file:///work/project/main.go\x1b\\main.go\x1b]8;;\x1b\\\n")
+
+	buf.Reset()
+	renderer = rendering.NewRenderer(rendering.RenderConfig{
+		Format:     rendering.FormatTerm,
+		Writer:     &buf,
+		NoColor:    true,
+		Hyperlinks: rendering.HyperlinksNever,
+		LinkTarget: target,
+	})
+	require.NoError(t, renderer.RenderTree(result))
+	assert.Equal(t, "project\n└─ main.go\n", buf.String())
+}
@@ -0,0 +1,248 @@
+package rendering
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+)
+
+const (
+	// annotationGap is the number of spaces between the widest tree entry and the annotation column
+	annotationGap = 3
+
+	// minAnnotationWidth keeps annotations readable on very narrow terminals;
+	// when less room is left the annotation column moves left to the entry itself
+	minAnnotationWidth = 20
+
+	// ellipsis marks annotations truncated by --annotation-width and names cut to fit
+	ellipsis = "…"
+)
+
+// AnnotationLayout places annotations relative to their entry
+type AnnotationLayout string
+
+const (
+	AnnotationsAligned AnnotationLayout = "aligned" // On a column shared by all entries (the default)
+	AnnotationsInline  AnnotationLayout = "inline"  // Right after each entry
+	AnnotationsBelow   AnnotationLayout = "below"   // On their own lines, under the entry's name
+)
+
+// AnnotationLayoutNames lists the valid --annotation-layout values
+var AnnotationLayoutNames = []string{string(AnnotationsAligned), string(AnnotationsInline), string(AnnotationsBelow)}
+
+// ParseAnnotationLayout validates an --annotation-layout value; an empty value means aligned
+func ParseAnnotationLayout(value string) (AnnotationLayout, error) {
+	switch placement := AnnotationLayout(strings.ToLower(strings.TrimSpace(value))); placement {
+	case "", AnnotationsAligned:
+		return AnnotationsAligned, nil
+	case AnnotationsInline, AnnotationsBelow:
+		return placement, nil
+	}
+	return "", fmt.Errorf("unknown annotation layout %q (valid: %s)", value, strings.Join(AnnotationLayoutNames, ", "))
+}
+
+// layoutLine is one rendered node before annotation placement
+type layoutLine struct {
+	tree       string   // Styled prefix, connector and name
+	treeWidth  int      // Display width of tree
+	nameStart  int      // Display width of tree before the name (prefix, connector and badges)
+	continuing string   // Unstyled prefix drawn in front of continuation lines
+	notes      string   // Raw annotation text (empty when none)
+	details    []string // Long listing columns (permissions, owner, group) shown before the tree
+}
+
+// columnLayout places annotations for a set of lines
+// The tree column holds connectors and names; annotations start at a shared column
+// and wrap onto continuation lines that keep the tree's vertical guides intact.
+type columnLayout struct {
+	width           int // Total output width (0 = unlimited, no wrapping)
+	annotationWidth int // Maximum annotation width; longer notes are truncated (0 = no limit)
+	minNameWidth    int // Narrowest a name is cut to when the tree is too wide (0 = never cut)
+
+	placement AnnotationLayout // Where annotations go (empty = aligned)
+}
+
+// forDetails returns the layout left for the tree once long listing columns of widths
+// take their room
+func (l columnLayout) forDetails(widths []int) columnLayout {
+	if len(widths) > 0 && l.width > 0 {
+		l.width -= ansi.StringWidth(formatDetails(make([]string, len(widths)), widths))
+	}
+	return l
+}
+
+// overflows reports whether any line of the tree is wider than the output
+func (l columnLayout) overflows(lines []layoutLine) bool {
+	l = l.forDetails(detailColumnWidths(lines))
+	if l.width <= 0 {
+		return false
+	}
+	for _, line := range lines {
+		if line.treeWidth > l.treeLimit(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// treeLimit is the widest the tree part of line should be: the output width, less room
+// for the annotation on annotated lines that share it with their annotation
+func (l columnLayout) treeLimit(line layoutLine) int {
+	if line.notes != "" && l.placement != AnnotationsBelow {
+		return l.width - annotationGap - minAnnotationWidth
+	}
+	return l.width
+}
+
+// fit cuts the name of line with an ellipsis so the tree stays within treeLimit; names
+// keep at least minNameWidth cells
+func (l columnLayout) fit(line layoutLine) layoutLine {
+	if l.width <= 0 || l.minNameWidth <= 0 {
+		return line
+	}
+	limit := max(l.treeLimit(line), line.nameStart+l.minNameWidth)
+	if line.treeWidth <= limit {
+		return line
+	}
+	line.tree = ansi.Truncate(line.tree, limit, ellipsis)
+	line.treeWidth = limit
+	return line
+}
+
+// column returns the display column where annotations start
+// Only annotated lines are measured so that long unannotated names do not push notes away
+func (l columnLayout) column(lines []layoutLine) int {
+	widest := 0
+	for _, line := range lines {
+		if line.notes != "" && line.treeWidth > widest {
+			widest = line.treeWidth
+		}
+	}
+	return widest + annotationGap
+}
+
+// place returns the annotation lines for a single entry, each line being unstyled text
+// The first element goes on the entry's own line, unless annotations go below; the rest
+// are continuation lines. column is the shared column of aligned annotations; it is
+// adjusted to the entry for the other layouts, or when it leaves too little room.
+func (l columnLayout) place(line layoutLine, column int) (int, []string) {
+	notes := strings.Join(strings.Fields(line.notes), " ")
+	switch l.placement {
+	case AnnotationsInline:
+		column = line.treeWidth + annotationGap
+	case AnnotationsBelow:
+		column = line.nameStart
+	}
+
+	if l.annotationWidth > 0 {
+		return column, []string{ansi.Truncate(notes, l.annotationWidth, ellipsis)}
+	}
+
+	if l.width <= 0 {
+		return column, []string{notes}
+	}
+
+	available := l.width - column
+	if available < minAnnotationWidth && l.placement != AnnotationsBelow {
+		column = line.treeWidth + annotationGap
+		available = l.width - column
+	}
+	available = max(available, minAnnotationWidth)
+
+	return column, strings.Split(ansi.Wrap(notes, available, ""), "\n")
+}
+
+// detailColumnWidths returns the widest value of each long listing column
+func detailColumnWidths(lines []layoutLine) []int {
+	var widths []int
+	for _, line := range lines {
+		for i, detail := range line.details {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], ansi.StringWidth(detail))
+		}
+	}
+	return widths
+}
+
+// formatDetails pads long listing columns to their widths and separates them from the tree
+func formatDetails(details []string, widths []int) string {
+	var b strings.Builder
+	for i, detail := range details {
+		b.WriteString(detail)
+		b.WriteString(strings.Repeat(" ", widths[i]-ansi.StringWidth(detail)+1))
+	}
+	b.WriteString(" ")
+	return b.String()
+}
+
+// PadRight pads text with spaces to width terminal columns, measuring wide (CJK, emoji)
+// characters by the cells they occupy rather than their bytes
+func PadRight(text string, width int) string {
+	return text + strings.Repeat(" ", max(width-ansi.StringWidth(text), 0))
+}
+
+// StripANSI removes terminal escape sequences from text: SGR colors, OSC 8 hyperlinks
+// and titles (ended by BEL or ST), DCS/APC strings, and their 8-bit C1 forms, raw or
+// UTF-8 encoded. x/ansi's parser follows the terminal state machine but reads its input
+// as UTF-8, so C1 controls are first rewritten to their 7-bit form and other invalid
+// bytes to U+FFFD; a stray lead byte would otherwise swallow the ESC after it.
+func StripANSI(text string) string {
+	if utf8.ValidString(text) && !strings.ContainsFunc(text, isC1Control) {
+		return ansi.Strip(text)
+	}
+
+	var b strings.Builder
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		if r == utf8.RuneError && size == 1 && isC1Control(rune(text[0])) {
+			r = rune(text[0])
+		}
+		if isC1Control(r) {
+			b.WriteByte(ansi.ESC)
+			b.WriteRune(r - 0x40)
+		} else {
+			b.WriteRune(r)
+		}
+		text = text[size:]
+	}
+	return ansi.Strip(b.String())
+}
+
+// isC1Control reports whether r is a C1 control character (U+0080 to U+009F)
+func isC1Control(r rune) bool {
+	return r >= 0x80 && r <= 0x9f
+}
+
+// valueOr returns value, or fallback when value is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// detectWidth returns the terminal width for writer, falling back to $COLUMNS when the
+// terminal size is unknown. Output that is not a terminal is unlimited (0), whatever
+// $COLUMNS says.
+func detectWidth(writer io.Writer, getenv func(string) string) int {
+	file, ok := writer.(*os.File)
+	if !ok || !term.IsTerminal(file.Fd()) {
+		return 0
+	}
+	if width, _, err := term.GetSize(file.Fd()); err == nil && width > 0 {
+		return width
+	}
+
+	if columns, err := strconv.Atoi(getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return 0
+}
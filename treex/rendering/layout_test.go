@@ -0,0 +1,443 @@
+package rendering_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/fs"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/display"
+	"treex/treex/rendering"
+	"treex/treex/schema"
+	"treex/treex/types"
+)
+
+// annotatedTree builds project/{src/main.go, README.txt} with the given notes on main.go and README.txt
+func annotatedTree(mainNotes, readmeNotes string) *types.Node {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	src := &types.Node{Name: "src", Path: "src", IsDir: true, Parent: root}
+	readme := &types.Node{Name: "README.txt", Path: "README.txt", Parent: root}
+	main := &types.Node{Name: "main.go", Path: "src/main.go", Parent: src}
+	root.Children = []*types.Node{src, readme}
+	src.Children = []*types.Node{main}
+
+	main.SetAnnotation(&types.Annotation{Path: "src/main.go", Notes: mainNotes})
+	readme.SetAnnotation(&types.Annotation{Path: "README.txt", Notes: readmeNotes})
+	return root
+}
+
+func renderLayout(t *testing.T, root *types.Node, width, annotationWidth int) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format:          rendering.FormatPlain,
+		Writer:          &buf,
+		ShowNotes:       true,
+		Charset:         rendering.CharsetASCII,
+		Width:           width,
+		AnnotationWidth: annotationWidth,
+	})
+	require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: root}))
+	return buf.String()
+}
+
+func TestLayoutAlignsAnnotations(t *testing.T) {
+	output := renderLayout(t, annotatedTree("Entry point", "Docs"), -1, 0)
+
+	expected := "project\n" +
+		"+- src\n" +
+		"|  \\- main.go   Entry point\n" +
+		"\\- README.txt   Docs\n"
+	assert.Equal(t, expected, output)
+}
+
+func TestLayoutWrapsLongAnnotations(t *testing.T) {
+	notes := "The application entry point wires configuration and starts the server"
+	output := renderLayout(t, annotatedTree(notes, "Docs"), 40, 0)
+
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	require.Greater(t, len(lines), 4, "expected continuation lines in:\n%s", output)
+
+	column := strings.Index(lines[2], "The")
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), 40, "line exceeds width: %q", line)
+	}
+
+	// Continuation lines keep the parent's vertical guide and align at the annotation column
+	for _, line := range lines[3 : len(lines)-1] {
+		assert.True(t, strings.HasPrefix(line, "|  "), "missing guide in %q", line)
+		assert.Equal(t, strings.Repeat(" ", column-3), line[3:column])
+	}
+	assert.True(t, strings.HasPrefix(lines[len(lines)-1], "\\- README.txt"))
+}
+
+func TestLayoutTruncatesWithAnnotationWidth(t *testing.T) {
+	output := renderLayout(t, annotatedTree("The application entry point", "Docs"), 40, 10)
+
+	assert.Contains(t, output, "main.go   The appli…\n")
+	assert.Contains(t, output, "README.txt   Docs\n")
+}
+
+func TestLayoutNarrowTerminal(t *testing.T) {
+	output := renderLayout(t, annotatedTree("Entry point of the application", "Docs"), 10, 0)
+
+	// The annotation column cannot fit; annotations still wrap at a readable width
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		assert.NotEmpty(t, strings.TrimSpace(line))
+	}
+	assert.Contains(t, output, "Entry point of the")
+}
+
+func TestLayoutPipedOutputIgnoresColumns(t *testing.T) {
+	notes := "The application entry point wires configuration and starts the server"
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format:    rendering.FormatTerm,
+		Writer:    &buf,
+		NoColor:   true,
+		ShowNotes: true,
+		Charset:   rendering.CharsetASCII,
+		Getenv:    envMap(map[string]string{"COLUMNS": "40"}),
+	})
+	require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: annotatedTree(notes, "Docs")}))
+
+	assert.Contains(t, buf.String(), "main.go   "+notes+"\n", "piped output never wraps")
+}
+
+func TestParseAnnotationLayout(t *testing.T) {
+	for value, expected := range map[string]rendering.AnnotationLayout{
+		"":        rendering.AnnotationsAligned,
+		"aligned": rendering.AnnotationsAligned,
+		"Inline":  rendering.AnnotationsInline,
+		"below":   rendering.AnnotationsBelow,
+	} {
+		placement, err := rendering.ParseAnnotationLayout(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, placement, value)
+	}
+
+	_, err := rendering.ParseAnnotationLayout("beside")
+	assert.ErrorContains(t, err, "unknown annotation layout")
+}
+
+func TestLayoutAnnotationPlacement(t *testing.T) {
+	root := annotatedTree("Entry point", "Docs")
+
+	t.Run("inline", func(t *testing.T) {
+		output := renderNarrow(t, root, rendering.RenderConfig{Width: -1, AnnotationLayout: rendering.AnnotationsInline})
+		expected := "project\n" +
+			"+- src\n" +
+			"|  \\- main.go   Entry point\n" +
+			"\\- README.txt   Docs\n"
+		assert.Equal(t, expected, output)
+	})
+
+	t.Run("below", func(t *testing.T) {
+		output := renderNarrow(t, root, rendering.RenderConfig{Width: -1, AnnotationLayout: rendering.AnnotationsBelow})
+		expected := "project\n" +
+			"+- src\n" +
+			"|  \\- main.go\n" +
+			"|     Entry point\n" +
+			"\\- README.txt\n" +
+			"   Docs\n"
+		assert.Equal(t, expected, output)
+	})
+
+	t.Run("below wraps under the name", func(t *testing.T) {
+		root := annotatedTree("The application entry point wires configuration and starts the server", "Docs")
+		output := renderNarrow(t, root, rendering.RenderConfig{Width: 30, AnnotationLayout: rendering.AnnotationsBelow})
+		assert.Contains(t, output, "|  \\- main.go\n|     The application entry\n|     point wires\n")
+	})
+}
+
+// chainTree builds project/{a/b/c/<file>, notes.txt} with notes on notes.txt
+func chainTree(file string) *types.Node {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	a := &types.Node{Name: "a", Path: "a", IsDir: true, Parent: root}
+	b := &types.Node{Name: "b", Path: "a/b", IsDir: true, Parent: a}
+	c := &types.Node{Name: "c", Path: "a/b/c", IsDir: true, Parent: b}
+	leaf := &types.Node{Name: file, Path: "a/b/c/" + file, Parent: c}
+	notes := &types.Node{Name: "notes.txt", Path: "notes.txt", Parent: root}
+	root.Children = []*types.Node{a, notes}
+	a.Children = []*types.Node{b}
+	b.Children = []*types.Node{c}
+	c.Children = []*types.Node{leaf}
+	notes.SetAnnotation(&types.Annotation{Path: "notes.txt", Notes: "Meeting notes"})
+	return root
+}
+
+func renderNarrow(t *testing.T, root *types.Node, config rendering.RenderConfig) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	config.Format = rendering.FormatPlain
+	config.Writer = &buf
+	config.ShowNotes = true
+	config.Charset = rendering.CharsetASCII
+	renderer := rendering.NewRenderer(config)
+	require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: root}))
+	return buf.String()
+}
+
+func TestLayoutCutsWideNames(t *testing.T) {
+	root := chainTree("a_file_name_long_enough_to_overflow.go")
+
+	t.Run("names are cut to the width", func(t *testing.T) {
+		output := renderNarrow(t, root, rendering.RenderConfig{Width: 40, MinNameWidth: 8})
+		assert.Contains(t, output, "|        \\- a_file_name_long_enough_to_…\n")
+		assert.Contains(t, output, "\\- notes.txt   Meeting notes\n")
+		for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+			assert.LessOrEqual(t, len([]rune(line)), 40, line)
+		}
+	})
+
+	t.Run("names keep their minimum width", func(t *testing.T) {
+		output := renderNarrow(t, root, rendering.RenderConfig{Width: 14, MinNameWidth: 8})
+		assert.Contains(t, output, "\\- a_file_…\n")
+	})
+
+	t.Run("zero minimum never cuts", func(t *testing.T) {
+		output := renderNarrow(t, root, rendering.RenderConfig{Width: 30})
+		assert.Contains(t, output, "a_file_name_long_enough_to_overflow.go")
+	})
+
+	t.Run("unlimited width never cuts", func(t *testing.T) {
+		output := renderNarrow(t, root, rendering.RenderConfig{Width: -1, MinNameWidth: 8})
+		assert.Contains(t, output, "a_file_name_long_enough_to_overflow.go")
+	})
+}
+
+func TestLayoutFlattensDirectoryChains(t *testing.T) {
+	t.Run("chains flatten when the tree is too wide", func(t *testing.T) {
+		output := renderNarrow(t, chainTree("main_program.go"), rendering.RenderConfig{Width: 24, FlattenDirs: true})
+		expected := "project\n" +
+			"+- a/b/c\n" +
+			"|  \\- main_program.go\n" +
+			"\\- notes.txt   Meeting notes\n"
+		assert.Equal(t, expected, output)
+	})
+
+	t.Run("trees that fit keep their chains", func(t *testing.T) {
+		output := renderNarrow(t, chainTree("main.go"), rendering.RenderConfig{Width: 80, FlattenDirs: true})
+		assert.Contains(t, output, "+- a\n|  \\- b\n")
+	})
+
+	t.Run("annotated directories end a chain", func(t *testing.T) {
+		root := chainTree("main_program.go")
+		b := root.Children[0].Children[0]
+		b.SetAnnotation(&types.Annotation{Path: "a/b", Notes: "Builds"})
+		output := renderNarrow(t, root, rendering.RenderConfig{Width: 24, FlattenDirs: true})
+		assert.Contains(t, output, "+- a/b")
+		assert.Contains(t, output, "|  \\- c\n")
+	})
+}
+
+// BenchmarkRenderAnnotatedTree measures rendering a wide tree where every entry is annotated
+func BenchmarkRenderAnnotatedTree(b *testing.B) {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	for i := 0; i < 2000; i++ {
+		name := "file" + strings.Repeat("x", i%17) + ".go"
+		child := &types.Node{Name: name, Path: name, Parent: root}
+		child.SetAnnotation(&types.Annotation{Path: name, Notes: "Handles request routing, validation and the response encoding for this endpoint"})
+		root.Children = append(root.Children, child)
+	}
+	result := &treex.TreeResult{Root: root}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		renderer := rendering.NewRenderer(rendering.RenderConfig{
+			Format:    rendering.FormatTerm,
+			Writer:    &buf,
+			ShowNotes: true,
+			Width:     80,
+		})
+		if err := renderer.RenderTree(result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestLayoutWithIcons(t *testing.T) {
+	icons := &display.IconMap{
+		Directory:  "D",
+		File:       "F",
+		Extensions: map[string]string{"go": "G"},
+	}
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format:    rendering.FormatPlain,
+		Writer:    &buf,
+		ShowNotes: true,
+		Charset:   rendering.CharsetASCII,
+		Width:     -1,
+		Icons:     icons,
+	})
+	require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: annotatedTree("Entry point", "Docs")}))
+
+	expected := "D project\n" +
+		"+- D src\n" +
+		"|  \\- G main.go   Entry point\n" +
+		"\\- F README.txt   Docs\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestLayoutLongListing(t *testing.T) {
+	root := annotatedTree("Entry point", "Docs")
+	root.Mode = fs.ModeDir | 0755
+	root.Owner, root.Group = "root", "wheel"
+	main := root.Children[0].Children[0]
+	main.Mode = 0644
+	main.Owner, main.Group = "alice", "staff"
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format:    rendering.FormatPlain,
+		Writer:    &buf,
+		ShowNotes: true,
+		Charset:   rendering.CharsetASCII,
+		Width:     -1,
+		Long:      true,
+	})
+	require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: root}))
+
+	expected := "drwxr-xr-x root  wheel  project\n" +
+		"---------- -     -      +- src\n" +
+		"-rw-r--r-- alice staff  |  \\- main.go   Entry point\n" +
+		"---------- -     -      \\- README.txt   Docs\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestRenderJSONIncludesOwnership(t *testing.T) {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true, Mode: fs.ModeDir | 0750, Owner: "alice", Group: "staff"}
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatJSON, Writer: &buf})
+	require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: root}))
+
+	var output struct {
+		Tree map[string]interface{} `json:"tree"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+	assert.Equal(t, "drwxr-x---", output.Tree["mode"])
+	assert.Equal(t, "alice", output.Tree["owner"])
+	assert.Equal(t, "staff", output.Tree["group"])
+}
+
+func TestRenderJSONIncludesKind(t *testing.T) {
+	root := annotatedTree("Entry point", "Overview")
+	root.Children[0].Children[0].SetPluginData("kind", types.KindGenerated)
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatJSON, Writer: &buf})
+	require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: root}))
+	require.NoError(t, schema.Validate("tree", buf.Bytes()))
+
+	var output struct {
+		Tree struct {
+			Children []map[string]interface{} `json:"children"`
+		} `json:"tree"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+	src := output.Tree.Children[0]
+	assert.NotContains(t, src, "kind", "entries people write have no kind")
+	assert.Equal(t, "generated", src["children"].([]interface{})[0].(map[string]interface{})["kind"])
+}
+
+func TestRenderTreeContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, format := range []rendering.OutputFormat{rendering.FormatPlain, rendering.FormatJSON} {
+		var buf bytes.Buffer
+		renderer := rendering.NewRenderer(rendering.RenderConfig{Format: format, Writer: &buf})
+		err := renderer.RenderTreeContext(ctx, &treex.TreeResult{Root: annotatedTree("Entry point", "Overview")})
+		assert.ErrorIs(t, err, context.Canceled, format)
+		assert.Empty(t, buf.String(), format)
+	}
+}
+
+func TestLayoutMeasuresWideCharacters(t *testing.T) {
+	t.Run("annotations align after CJK and emoji names", func(t *testing.T) {
+		root := annotatedTree("Entry point", "Docs")
+		root.Children[1].Name = "说明书.txt"
+		root.Children[0].Children[0].Name = "🚀.go"
+
+		expected := "project\n" +
+			"+- src\n" +
+			"|  \\- 🚀.go     Entry point\n" +
+			"\\- 说明书.txt   Docs\n"
+		assert.Equal(t, expected, renderLayout(t, root, -1, 0))
+	})
+
+	t.Run("wide names are cut by display width", func(t *testing.T) {
+		output := renderNarrow(t, chainTree(strings.Repeat("数据", 12)+".go"), rendering.RenderConfig{Width: 40, MinNameWidth: 8})
+		for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+			assert.LessOrEqual(t, ansi.StringWidth(line), 40, line)
+		}
+		assert.Contains(t, output, "…\n")
+	})
+
+	t.Run("wrapped CJK annotations stay within the width", func(t *testing.T) {
+		output := renderLayout(t, annotatedTree(strings.Repeat("程序入口", 10), "文档 📚"), 40, 0)
+		lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+		require.Greater(t, len(lines), 4, "expected continuation lines in:\n%s", output)
+		for _, line := range lines {
+			assert.LessOrEqual(t, ansi.StringWidth(line), 40, line)
+		}
+		assert.Equal(t, "\\- README.txt   文档 📚", lines[len(lines)-1])
+	})
+}
+
+func TestStripANSI(t *testing.T) {
+	for input, expected := range map[string]string{
+		"\x1b[1;31mred\x1b[0m plain":                         "red plain",
+		"\x1b]8;;file:///main.go\x1b\\main.go\x1b]8;;\x1b\\": "main.go",
+		"\x1b]8;;https://example.com\alink\x1b]8;;\a":        "link",
+		"\x1b]0;title\a数据 📚":                                 "数据 📚",
+		"\x9b31mraw 8-bit\x9b0m":                             "raw 8-bit",
+		"\u009b31mencoded 8-bit\u009b0m":                     "encoded 8-bit",
+		"\u009d8;;https://example.com\u009clink":             "link",
+		"a\x1bPdevice control\x1b\\b":                        "ab",
+		"unterminated\x1b[":                                  "unterminated",
+		"plain text, é and 囗":                                "plain text, é and 囗",
+	} {
+		assert.Equal(t, expected, rendering.StripANSI(input), "%q", input)
+	}
+}
+
+func FuzzStripANSI(f *testing.F) {
+	for _, seed := range []string{
+		"\x1b[1;31mred\x1b[0m",
+		"\x1b]8;;file:///a\x1b\\a\x1b]8;;\x1b\\",
+		"\x1b]8;;x\ay\x1b]8;;\a",
+		"\x9b31mz\u009b0m",
+		"数据 📚 é",
+		"\x1bP\x1b_\x1b^\x1b",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		stripped := rendering.StripANSI(input)
+		if strings.ContainsRune(stripped, '\x1b') {
+			t.Fatalf("escape left in %q (from %q)", stripped, input)
+		}
+		if again := rendering.StripANSI(stripped); again != stripped {
+			t.Fatalf("not idempotent: %q then %q (from %q)", stripped, again, input)
+		}
+		// Text without control characters passes through untouched
+		if utf8.ValidString(input) && !strings.ContainsFunc(input, unicode.IsControl) && stripped != input {
+			t.Fatalf("plain text changed: %q became %q", input, stripped)
+		}
+	})
+}
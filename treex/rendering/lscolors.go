@@ -0,0 +1,145 @@
+package rendering
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"treex/treex/types"
+)
+
+// ColorScheme selects where file name colors come from
+type ColorScheme string
+
+const (
+	SchemeTheme    ColorScheme = "theme"     // The theme's styles and file classes (the default)
+	SchemeLSColors ColorScheme = "ls-colors" // The LS_COLORS database, as ls and dircolors use it
+)
+
+// ColorSchemeNames lists the valid --color-scheme values
+var ColorSchemeNames = []string{string(SchemeTheme), string(SchemeLSColors)}
+
+// LSColorsEnvVar is the environment variable dircolors writes the color database to
+const LSColorsEnvVar = "LS_COLORS"
+
+// ParseColorScheme validates a --color-scheme value; an empty value means theme
+func ParseColorScheme(value string) (ColorScheme, error) {
+	switch scheme := ColorScheme(strings.ToLower(strings.TrimSpace(value))); scheme {
+	case "", SchemeTheme:
+		return SchemeTheme, nil
+	case SchemeLSColors:
+		return scheme, nil
+	}
+	return "", fmt.Errorf("unknown color scheme %q (valid: %s)", value, strings.Join(ColorSchemeNames, ", "))
+}
+
+// defaultLSColors are the colors GNU ls uses when LS_COLORS is unset
+const defaultLSColors = "di=01;34:ln=01;36:pi=33:so=01;35:do=01;35:bd=01;33:cd=01;33:" +
+	"su=37;41:sg=30;43:tw=30;42:ow=34;42:st=37;44:ex=01;32"
+
+// LSColors is a parsed LS_COLORS database: SGR codes by file type indicator (di, ln,
+// ex, ...) and by name suffix ("*.go", "*README")
+type LSColors struct {
+	types    map[string]string
+	suffixes []lsSuffix // Longest first, so the most specific suffix wins
+}
+
+// lsSuffix is a "*suffix=code" entry
+type lsSuffix struct {
+	suffix string
+	code   string
+}
+
+// ParseLSColors parses an LS_COLORS value; an empty value uses the GNU ls defaults
+// Entries ls would reject (no "=", or codes other than digits and semicolons) are
+// skipped, so a stray entry cannot inject escape sequences.
+func ParseLSColors(value string) *LSColors {
+	if strings.TrimSpace(value) == "" {
+		value = defaultLSColors
+	}
+
+	colors := &LSColors{types: make(map[string]string)}
+	for _, entry := range strings.Split(value, ":") {
+		key, code, ok := strings.Cut(entry, "=")
+		if !ok || key == "" || !validSGR(code) {
+			continue
+		}
+		if suffix, isSuffix := strings.CutPrefix(key, "*"); isSuffix {
+			colors.suffixes = append(colors.suffixes, lsSuffix{suffix: suffix, code: code})
+			continue
+		}
+		colors.types[key] = code
+	}
+	sort.SliceStable(colors.suffixes, func(i, j int) bool {
+		return len(colors.suffixes[i].suffix) > len(colors.suffixes[j].suffix)
+	})
+	return colors
+}
+
+// validSGR reports whether code is a list of SGR parameters such as "01;34"
+func validSGR(code string) bool {
+	return strings.Trim(code, "0123456789;") == ""
+}
+
+// Code returns the SGR code for a node, following ls: special files and executables
+// by type, other regular files by suffix, then "fi". An empty code means no color.
+func (c *LSColors) Code(node *types.Node) string {
+	mode := node.Mode
+	switch {
+	case node.IsDir || mode.IsDir():
+		sticky, otherWritable := mode&fs.ModeSticky != 0, mode&0002 != 0
+		switch {
+		case sticky && otherWritable:
+			return c.first("tw", "di")
+		case otherWritable:
+			return c.first("ow", "di")
+		case sticky:
+			return c.first("st", "di")
+		}
+		return c.types["di"]
+	case mode&fs.ModeSymlink != 0:
+		// "ln=target" asks for the target's color, which needs the target's mode;
+		// without it the link is left uncolored
+		if code := c.types["ln"]; code != "target" {
+			return code
+		}
+		return ""
+	case mode&fs.ModeNamedPipe != 0:
+		return c.types["pi"]
+	case mode&fs.ModeSocket != 0:
+		return c.types["so"]
+	case mode&fs.ModeDevice != 0 && mode&fs.ModeCharDevice != 0:
+		return c.types["cd"]
+	case mode&fs.ModeDevice != 0:
+		return c.types["bd"]
+	case mode&fs.ModeSetuid != 0 && c.types["su"] != "":
+		return c.types["su"]
+	case mode&fs.ModeSetgid != 0 && c.types["sg"] != "":
+		return c.types["sg"]
+	case mode&0111 != 0 && c.types["ex"] != "":
+		return c.types["ex"]
+	}
+
+	for _, entry := range c.suffixes {
+		if hasSuffixFold(node.Name, entry.suffix) {
+			return entry.code
+		}
+	}
+	return c.types["fi"]
+}
+
+// first returns the code of the first indicator that has one
+func (c *LSColors) first(indicators ...string) string {
+	for _, indicator := range indicators {
+		if code := c.types[indicator]; code != "" {
+			return code
+		}
+	}
+	return ""
+}
+
+// hasSuffixFold reports whether name ends in suffix, ignoring case as GNU ls does
+func hasSuffixFold(name, suffix string) bool {
+	return len(name) >= len(suffix) && strings.EqualFold(name[len(name)-len(suffix):], suffix)
+}
@@ -0,0 +1,96 @@
+package rendering_test
+
+import (
+	"bytes"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+func TestParseColorScheme(t *testing.T) {
+	for value, expected := range map[string]rendering.ColorScheme{
+		"":          rendering.SchemeTheme,
+		"theme":     rendering.SchemeTheme,
+		"LS-Colors": rendering.SchemeLSColors,
+	} {
+		scheme, err := rendering.ParseColorScheme(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, scheme, value)
+	}
+
+	_, err := rendering.ParseColorScheme("dircolors")
+	assert.ErrorContains(t, err, "unknown color scheme")
+}
+
+func TestLSColorsCode(t *testing.T) {
+	colors := rendering.ParseLSColors("di=01;34:ln=01;36:ex=01;32:tw=30;42:fi=00:*.gz=01;31:*.tar.gz=35:*README=04:*.bad=\x1b[31")
+
+	tests := []struct {
+		name     string
+		node     *types.Node
+		expected string
+	}{
+		{"directory", &types.Node{Name: "src", IsDir: true, Mode: fs.ModeDir | 0755}, "01;34"},
+		{"sticky writable directory", &types.Node{Name: "tmp", IsDir: true, Mode: fs.ModeDir | fs.ModeSticky | 0777}, "30;42"},
+		{"other writable falls back to di", &types.Node{Name: "pub", IsDir: true, Mode: fs.ModeDir | 0777}, "01;34"},
+		{"symlink", &types.Node{Name: "link.gz", Mode: fs.ModeSymlink | 0777}, "01;36"},
+		{"executable wins over suffix", &types.Node{Name: "run.gz", Mode: 0755}, "01;32"},
+		{"longest suffix wins", &types.Node{Name: "src.tar.gz", Mode: 0644}, "35"},
+		{"suffix ignores case", &types.Node{Name: "logs.GZ", Mode: 0644}, "01;31"},
+		{"suffix without a dot", &types.Node{Name: "README", Mode: 0644}, "04"},
+		{"regular file", &types.Node{Name: "main.go", Mode: 0644}, "00"},
+		{"invalid codes are skipped", &types.Node{Name: "x.bad", Mode: 0644}, "00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, colors.Code(tt.node))
+		})
+	}
+
+	t.Run("ln=target leaves links uncolored", func(t *testing.T) {
+		colors := rendering.ParseLSColors("ln=target")
+		assert.Empty(t, colors.Code(&types.Node{Name: "link", Mode: fs.ModeSymlink}))
+	})
+
+	t.Run("empty value uses the ls defaults", func(t *testing.T) {
+		colors := rendering.ParseLSColors("")
+		assert.Equal(t, "01;34", colors.Code(&types.Node{Name: "src", IsDir: true, Mode: fs.ModeDir | 0755}))
+		assert.Empty(t, colors.Code(&types.Node{Name: "main.go", Mode: 0644}))
+	})
+}
+
+func TestRendererLSColors(t *testing.T) {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true, Mode: fs.ModeDir | 0755}
+	root.Children = []*types.Node{
+		{Name: "run.sh", Path: "run.sh", Mode: 0755, Parent: root},
+		{Name: "src.tar.gz", Path: "src.tar.gz", Mode: 0644, Parent: root},
+	}
+	colors := rendering.ParseLSColors("di=01;34:ex=01;32:*.tar.gz=35")
+
+	render := func(env map[string]string) string {
+		var buf bytes.Buffer
+		renderer := rendering.NewRenderer(rendering.RenderConfig{
+			Writer:      &buf,
+			AutoDetect:  true,
+			Getenv:      envMap(env),
+			LSColors:    colors,
+			FileClasses: &rendering.ColorRules{},
+		})
+		require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: root}))
+		return buf.String()
+	}
+
+	output := render(map[string]string{"CLICOLOR_FORCE": "1"})
+	assert.Contains(t, output, "\x1b[01;34mproject\x1b[0m")
+	assert.Contains(t, output, "\x1b[01;32mrun.sh\x1b[0m")
+	assert.Contains(t, output, "\x1b[35msrc.tar.gz\x1b[0m")
+
+	plain := render(map[string]string{"NO_COLOR": "1"})
+	assert.NotContains(t, plain, "\x1b[")
+	assert.Contains(t, plain, "src.tar.gz")
+}
@@ -0,0 +1,39 @@
+package rendering
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"treex/treex"
+)
+
+// renderMarkdown writes the plain text tree inside a fenced code block, ready to paste
+// into a README or generated docs. The fence is longer than any run of backticks in
+// the tree, so annotations quoting code cannot end the block early.
+func (r *Renderer) renderMarkdown(ctx context.Context, result *treex.TreeResult) error {
+	var body bytes.Buffer
+	text := *r
+	text.config.Writer = &body
+	if err := text.renderText(ctx, result); err != nil {
+		return err
+	}
+
+	fence := strings.Repeat("`", max(longestRun(body.String(), '`')+1, 3))
+	_, err := r.config.Writer.Write([]byte(fence + "text\n" + body.String() + fence + "\n"))
+	return err
+}
+
+// longestRun returns the length of the longest run of c in s
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != c {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
@@ -0,0 +1,39 @@
+package rendering_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+)
+
+func renderMarkdown(t *testing.T, mainNotes string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format:    rendering.FormatMarkdown,
+		Writer:    &buf,
+		ShowNotes: true,
+		Charset:   rendering.CharsetASCII,
+	})
+	require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: annotatedTree(mainNotes, "Docs")}))
+	return buf.String()
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	assert.Equal(t, "```text\n"+
+		"project\n"+
+		"+- src\n"+
+		"|  \\- main.go   Entry point\n"+
+		"\\- README.txt   Docs\n"+
+		"```\n", renderMarkdown(t, "Entry point"))
+}
+
+func TestRenderMarkdownLengthensTheFence(t *testing.T) {
+	output := renderMarkdown(t, "Run ```go run .```")
+	assert.Contains(t, output, "````text\n")
+	assert.Contains(t, output, "\n````\n")
+}
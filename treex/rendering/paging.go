@@ -0,0 +1,71 @@
+package rendering
+
+import (
+	"encoding/json"
+
+	"treex/treex"
+	"treex/treex/types"
+)
+
+// paged reports whether data formats page through entries (--limit or --offset), or
+// list them breadth-first, which JSON can only do as a flat list
+func (c RenderConfig) paged() bool {
+	return c.Limit > 0 || c.Offset > 0 || c.Traversal == TraversalBFS
+}
+
+// renderJSONPage outputs one page of the tree's entries as a flat list
+// Entries are listed in Traversal order without their children; the root itself is not an
+// entry. next_cursor, present when more entries follow, is the offset of the next page.
+// Entries of combined results (see treex.CombineResults) carry the name of their root.
+func (r *Renderer) renderJSONPage(result *treex.TreeResult) error {
+	var entries []map[string]interface{}
+	if result.Roots != nil {
+		for _, root := range result.Roots {
+			if root.Root == nil {
+				continue
+			}
+			for _, entry := range pageEntries(root.Root, r.config.Traversal) {
+				entry["root"] = root.Root.Name
+				entries = append(entries, entry)
+			}
+		}
+	} else {
+		entries = pageEntries(result.Root, r.config.Traversal)
+	}
+
+	total := len(entries)
+	start := min(r.config.Offset, total)
+	end := total
+	if r.config.Limit > 0 {
+		end = min(start+r.config.Limit, total)
+	}
+
+	output := map[string]interface{}{
+		"entries": append([]map[string]interface{}{}, entries[start:end]...),
+		"offset":  start,
+		"total":   total,
+		"stats":   result.Stats,
+	}
+	if end < total {
+		output["next_cursor"] = end
+	}
+	if r.config.ShowErrors {
+		output["warnings"] = treeWarnings(result)
+	}
+	if r.config.ShowStats {
+		output["timing"] = timingJSON(result)
+	}
+
+	encoder := json.NewEncoder(r.config.Writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+// pageEntries lists the nodes below root in traversal order as JSON objects without children
+func pageEntries(root *types.Node, traversal Traversal) []map[string]interface{} {
+	var entries []map[string]interface{}
+	walkEntries(root, traversal, func(node *types.Node) {
+		entries = append(entries, nodeFieldsJSON(node))
+	})
+	return entries
+}
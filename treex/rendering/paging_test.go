@@ -0,0 +1,103 @@
+package rendering_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+// jsonPage is the paged JSON document
+type jsonPage struct {
+	Entries []map[string]interface{} `json:"entries"`
+	Offset  int                      `json:"offset"`
+	Total   int                      `json:"total"`
+	Next    *int                     `json:"next_cursor"`
+}
+
+func renderPage(t *testing.T, result *treex.TreeResult, limit, offset int) jsonPage {
+	t.Helper()
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatJSON, Writer: &buf, Limit: limit, Offset: offset})
+	require.NoError(t, renderer.RenderTree(result))
+
+	var page jsonPage
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &page))
+	return page
+}
+
+func entryPaths(page jsonPage) []string {
+	var paths []string
+	for _, entry := range page.Entries {
+		paths = append(paths, entry["path"].(string))
+	}
+	return paths
+}
+
+func TestRenderJSONPages(t *testing.T) {
+	root := &types.Node{Name: ".", Path: ".", IsDir: true}
+	src := &types.Node{Name: "src", Path: "src", IsDir: true, Parent: root}
+	src.Children = []*types.Node{
+		{Name: "main.go", Path: "src/main.go", Parent: src},
+		{Name: "util.go", Path: "src/util.go", Parent: src},
+	}
+	root.Children = []*types.Node{src, {Name: "README.md", Path: "README.md", Parent: root}}
+	result := &treex.TreeResult{Root: root}
+
+	page := renderPage(t, result, 2, 0)
+	assert.Equal(t, []string{"src", "src/main.go"}, entryPaths(page))
+	assert.Equal(t, 4, page.Total)
+	require.NotNil(t, page.Next)
+	assert.Equal(t, 2, *page.Next)
+	assert.NotContains(t, page.Entries[0], "children", "entries are flat")
+
+	page = renderPage(t, result, 2, *page.Next)
+	assert.Equal(t, []string{"src/util.go", "README.md"}, entryPaths(page))
+	assert.Equal(t, 2, page.Offset)
+	assert.Nil(t, page.Next, "the last page has no cursor")
+
+	page = renderPage(t, result, 0, 10)
+	assert.Empty(t, page.Entries)
+	assert.NotNil(t, page.Entries, "an empty page still lists entries")
+	assert.Equal(t, 4, page.Offset)
+}
+
+func TestRenderJSONPagesCombinedRoots(t *testing.T) {
+	combined := treex.CombineResults("2 roots", []string{"src", "docs"},
+		[]*treex.TreeResult{rootResult("main.go"), rootResult("index.md", "guide.md")})
+
+	page := renderPage(t, combined, 2, 1)
+	assert.Equal(t, []string{"index.md", "guide.md"}, entryPaths(page))
+	assert.Equal(t, "docs", page.Entries[0]["root"])
+	assert.Nil(t, page.Next)
+}
+
+func TestRenderJSONBreadthFirst(t *testing.T) {
+	root := &types.Node{Name: ".", Path: ".", IsDir: true}
+	src := &types.Node{Name: "src", Path: "src", IsDir: true, Parent: root}
+	lib := &types.Node{Name: "lib", Path: "src/lib", IsDir: true, Parent: src}
+	lib.Children = []*types.Node{{Name: "lib.go", Path: "src/lib/lib.go", Parent: lib}}
+	src.Children = []*types.Node{lib, {Name: "main.go", Path: "src/main.go", Parent: src}}
+	root.Children = []*types.Node{src, {Name: "README.md", Path: "README.md", Parent: root}}
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatJSON, Writer: &buf, Traversal: rendering.TraversalBFS, Limit: 3})
+	require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: root}))
+	var page jsonPage
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &page))
+	assert.Equal(t, []string{"src", "README.md", "src/lib"}, entryPaths(page), "levels in order, pages cut across them")
+	assert.Equal(t, 5, page.Total)
+
+	buf.Reset()
+	renderer = rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatJSON, Writer: &buf, Traversal: rendering.TraversalBFS})
+	require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: root}))
+	page = jsonPage{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &page))
+	assert.Equal(t, []string{"src", "README.md", "src/lib", "src/main.go", "src/lib/lib.go"}, entryPaths(page), "listed flat without a limit")
+	assert.Nil(t, page.Next)
+}
@@ -0,0 +1,117 @@
+package rendering
+
+import (
+	"fmt"
+	"strings"
+
+	"treex/treex"
+	"treex/treex/types"
+)
+
+// PlantUMLStyles lists the diagram styles accepted by ParsePlantUMLStyle
+var PlantUMLStyles = []string{"component", "salt"}
+
+// ParsePlantUMLStyle validates a --plantuml value; an empty value means component
+func ParsePlantUMLStyle(value string) (string, error) {
+	style := strings.ToLower(strings.TrimSpace(value))
+	if style == "" {
+		return "component", nil
+	}
+	for _, valid := range PlantUMLStyles {
+		if style == valid {
+			return style, nil
+		}
+	}
+	return "", fmt.Errorf("unknown plantuml style %q (valid: %s)", value, strings.Join(PlantUMLStyles, ", "))
+}
+
+// renderPlantUML outputs the tree as a PlantUML diagram, for embedding in design docs:
+//   - component: each annotated top-level directory is a component described by its
+//     annotation, inside a package for the root
+//   - salt: the whole tree as a Salt tree table with an annotation column
+func (r *Renderer) renderPlantUML(result *treex.TreeResult) error {
+	style, err := ParsePlantUMLStyle(r.config.PlantUMLStyle)
+	if err != nil {
+		return err
+	}
+
+	var out strings.Builder
+	if result.Root != nil {
+		if style == "salt" {
+			writeSalt(&out, result.Root)
+		} else {
+			writeComponents(&out, result.Root)
+		}
+	}
+	_, err = r.config.Writer.Write([]byte(out.String()))
+	return err
+}
+
+// writeComponents writes a component diagram of the annotated top-level directories
+func writeComponents(out *strings.Builder, root *types.Node) {
+	out.WriteString("@startuml\n")
+	fmt.Fprintf(out, "package %s {\n", umlQuote(root.Name))
+	next := 0
+	for _, child := range root.Children {
+		annotation := child.GetAnnotation()
+		if !child.IsDir || annotation == nil || annotation.Notes == "" {
+			continue
+		}
+		fmt.Fprintf(out, "  component c%d [\n", next)
+		next++
+		fmt.Fprintf(out, "    %s/\n", umlText(child.Name))
+		out.WriteString("    ----\n")
+		for _, line := range strings.Split(annotation.Notes, "\n") {
+			fmt.Fprintf(out, "    %s\n", umlText(line))
+		}
+		out.WriteString("  ]\n")
+	}
+	out.WriteString("}\n")
+	out.WriteString("@enduml\n")
+}
+
+// writeSalt writes the tree as a Salt tree table: one "+" per level, then the annotation
+func writeSalt(out *strings.Builder, root *types.Node) {
+	out.WriteString("@startsalt\n")
+	out.WriteString("{\n")
+	out.WriteString("{T\n")
+	out.WriteString(" + Path | Annotation\n")
+	var walk func(node *types.Node, depth int)
+	walk = func(node *types.Node, depth int) {
+		name := node.Name
+		if node.IsDir {
+			name += "/"
+		}
+		notes := ""
+		if annotation := node.GetAnnotation(); annotation != nil {
+			// Salt cells are single lines
+			notes = strings.Join(strings.Fields(annotation.Notes), " ")
+		}
+		fmt.Fprintf(out, " %s %s | %s\n", strings.Repeat("+", depth), saltText(name), saltText(notes))
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+	walk(root, 1)
+	out.WriteString("}\n")
+	out.WriteString("}\n")
+	out.WriteString("@endsalt\n")
+}
+
+// umlQuote quotes s as a PlantUML element name
+func umlQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `'`) + `"`
+}
+
+// umlText keeps a description line from closing the component body early
+func umlText(s string) string {
+	if strings.TrimSpace(s) == "]" {
+		return "~" + s
+	}
+	return s
+}
+
+// saltText escapes the Salt cell separator
+func saltText(s string) string {
+	return strings.ReplaceAll(s, "|", "~|")
+}
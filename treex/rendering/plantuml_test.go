@@ -0,0 +1,75 @@
+package rendering_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+func plantUMLTree() *treex.TreeResult {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	api := &types.Node{Name: "api", Path: "api", IsDir: true, Parent: root}
+	api.SetAnnotation(&types.Annotation{Path: "api", Notes: "HTTP handlers\nVersioned under /v1"})
+	handler := &types.Node{Name: "users.go", Path: "api/users.go", Parent: api}
+	handler.SetAnnotation(&types.Annotation{Path: "api/users.go", Notes: "Users | accounts"})
+	api.Children = []*types.Node{handler}
+	scripts := &types.Node{Name: "scripts", Path: "scripts", IsDir: true, Parent: root}
+	readme := &types.Node{Name: "README.md", Path: "README.md", Parent: root}
+	readme.SetAnnotation(&types.Annotation{Path: "README.md", Notes: "Overview"})
+	root.Children = []*types.Node{api, scripts, readme}
+	return &treex.TreeResult{Root: root}
+}
+
+func TestRenderPlantUMLComponents(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatPlantUML, Writer: &buf})
+	require.NoError(t, renderer.RenderTree(plantUMLTree()))
+	assert.Equal(t, `@startuml
+package "project" {
+  component c0 [
+    api/
+    ----
+    HTTP handlers
+    Versioned under /v1
+  ]
+}
+@enduml
+`, buf.String(), "only annotated top-level directories become components")
+}
+
+func TestRenderPlantUMLSalt(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatPlantUML, Writer: &buf, PlantUMLStyle: "salt"})
+	require.NoError(t, renderer.RenderTree(plantUMLTree()))
+	assert.Equal(t, `@startsalt
+{
+{T
+ + Path | Annotation
+ + project/ | 
+ ++ api/ | HTTP handlers Versioned under /v1
+ +++ users.go | Users ~| accounts
+ ++ scripts/ | 
+ ++ README.md | Overview
+}
+}
+@endsalt
+`, buf.String())
+}
+
+func TestParsePlantUMLStyle(t *testing.T) {
+	style, err := rendering.ParsePlantUMLStyle("")
+	require.NoError(t, err)
+	assert.Equal(t, "component", style)
+
+	style, err = rendering.ParsePlantUMLStyle("Salt")
+	require.NoError(t, err)
+	assert.Equal(t, "salt", style)
+
+	_, err = rendering.ParsePlantUMLStyle("c4")
+	assert.ErrorContains(t, err, "valid: component, salt")
+}
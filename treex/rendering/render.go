@@ -3,12 +3,20 @@
 package rendering
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/charmbracelet/x/ansi"
 	"treex/treex"
+	"treex/treex/display"
+	"treex/treex/pathutil"
+	"treex/treex/plugins/infofile"
 	"treex/treex/types"
 )
 
@@ -16,67 +24,232 @@ import (
 type OutputFormat string
 
 const (
-	FormatJSON  OutputFormat = "json"
-	FormatPlain OutputFormat = "plain"
-	FormatTerm  OutputFormat = "term"
+	FormatJSON     OutputFormat = "json"
+	FormatPlain    OutputFormat = "plain"
+	FormatTerm     OutputFormat = "term"
+	FormatDot      OutputFormat = "dot"      // Graphviz digraph
+	FormatPlantUML OutputFormat = "plantuml" // PlantUML component diagram or Salt tree
+	FormatFlat     OutputFormat = "flat"     // One path per line with its annotation
+	FormatMarkdown OutputFormat = "markdown" // The plain tree in a fenced code block
 )
 
 // RenderConfig configures the rendering process
 type RenderConfig struct {
-	Format     OutputFormat // Output format to use
-	Writer     io.Writer    // Where to write output
-	AutoDetect bool         // Whether to auto-detect terminal capabilities
-	NoColor    bool         // Force disable colors
-	ShowStats  bool         // Whether to show statistics
-	ShowNotes  bool         // Whether to show annotation notes
+	Format     OutputFormat     // Output format to use
+	Writer     io.Writer        // Where to write output
+	Terminal   io.Writer        // Where Writer's output ends up, for terminal detection (nil = Writer)
+	AutoDetect bool             // Whether to auto-detect terminal capabilities
+	NoColor    bool             // Force disable colors
+	ShowStats  bool             // Show build statistics after text output and a "timing" object in JSON
+	Summary    bool             // Print a footer counting directories, files and annotated entries
+	ShowNotes  bool             // Whether to show annotation notes
+	Theme      *Theme           // Theme for presentation styles (nil uses the default theme)
+	Background BackgroundMode   // Light/dark palette override for adaptive colors (empty = auto)
+	Charset    Charset          // Connector glyph set (empty = auto from locale)
+	Icons      *display.IconMap // File-type icons drawn before names (nil = no icons)
+	Long       bool             // Show permissions, owner and group columns before each entry
+	ShowErrors bool             // Mark unreadable directories and list them under "warnings" in JSON
+	FullPaths  bool             // Show entries with their path from the root, as tree -f does
+
+	// CollapseDepth summarizes the contents of directories at this depth and below (0 = never collapse)
+	CollapseDepth int
+	// MaxFilesPerDir lists at most this many files per directory (0 = all); directories are always listed
+	MaxFilesPerDir int
+
+	// Width is the output width annotations wrap at (0 = detect the terminal, negative = never wrap)
+	Width int
+	// AnnotationWidth truncates annotations to this many cells with an ellipsis (0 = wrap instead)
+	AnnotationWidth int
+	// AnnotationLayout places annotations: aligned on a shared column, inline after each
+	// entry, or below it on their own lines (empty = aligned)
+	AnnotationLayout AnnotationLayout
+	// MinNameWidth lets names wider than Width be cut with an ellipsis, down to this many
+	// cells (0 = never cut)
+	MinNameWidth int
+	// FlattenDirs shows chains of directories that hold a single directory as one entry
+	// ("a/b/c") when the tree is wider than Width
+	FlattenDirs bool
+
+	// Limit and Offset page through the entries of data formats: JSON then lists at most
+	// Limit entries (0 = all) starting at Offset, flat, with a next_cursor for the next page
+	Limit  int
+	Offset int
+
+	// RankDir is the Graphviz rankdir of dot output: LR, TB, RL or BT (empty = LR)
+	RankDir string
+
+	// PlantUMLStyle is the diagram of plantuml output: component or salt (empty = component)
+	PlantUMLStyle string
+
+	// FlatOrder sorts flat output: tree, path or annotated (empty = tree)
+	FlatOrder string
+
+	// Traversal orders the entries of flat output and JSON: depth-first as the tree is
+	// printed, or breadth-first, which JSON lists flat as it does pages (empty = dfs)
+	Traversal Traversal
+
+	// Hyperlinks decides when terminal output links entry names (empty = auto)
+	// LinkTarget returns the URL of an entry ("" = no link); nil disables links
+	Hyperlinks HyperlinkMode
+	LinkTarget func(node *types.Node) string
+
+	// FileClasses colors file names by extension group and size (see ColorRules); nil
+	// styles every name alike
+	FileClasses *ColorRules
+
+	// Badges contribute short markers per node, shown in columns between the connector
+	// and the name in this order (see plugins.BadgePlugin)
+	Badges []BadgeSource
+
+	// Heat colors names on a cold-to-hot gradient by their step, 0 to treex.HeatLevels-1
+	// (see treex.HeatScale); it takes precedence over every other name color, and
+	// entries without a step are styled as usual
+	Heat map[*types.Node]int
+
+	// LSColors colors file names from the LS_COLORS database instead of the theme
+	// (--color-scheme ls-colors); it takes precedence over FileClasses
+	LSColors *LSColors
+
+	// Renderers provides the custom formats (x-...) Format may name; nil uses
+	// DefaultRenderers
+	Renderers *RendererRegistry
+
+	// DroppedAnnotations are the .info entries whose annotation the tree cannot show (see
+	// infofile.Dropped), listed with their file and line after text output
+	DroppedAnnotations []infofile.Issue
+
+	// Getenv reads the color environment (NO_COLOR, CLICOLOR, CLICOLOR_FORCE); nil uses os.Getenv
+	Getenv func(string) string
 }
 
 // Renderer handles output formatting for tree results
 type Renderer struct {
 	config RenderConfig
 	styles *StyleManager
+	glyphs GlyphSet
+	layout columnLayout
+
+	badgeWidths []int // Column width of each badge source in the tree being rendered
+	flatten     bool  // Whether directory chains are shown as one entry (see FlattenDirs)
 }
 
 // NewRenderer creates a new renderer with the specified configuration
 func NewRenderer(config RenderConfig) *Renderer {
+	if config.Getenv == nil {
+		config.Getenv = os.Getenv
+	}
+
+	// A buffered writer (e.g. for a pager) is styled for the terminal it is shown on
+	terminal := config.Terminal
+	if terminal == nil {
+		terminal = config.Writer
+	}
+
 	// Auto-detect format if not specified
 	if config.Format == "" {
-		config.Format = detectOutputFormat(config.Writer, config.AutoDetect)
+		config.Format = detectOutputFormat(terminal, config.AutoDetect, ColorModeFromEnv(config.Getenv))
 	}
 
 	// Default to stdout if no writer specified
 	if config.Writer == nil {
 		config.Writer = os.Stdout
 	}
+	if terminal == nil {
+		terminal = config.Writer
+	}
+
+	// Wrap annotations at the terminal width unless a width is given
+	width := config.Width
+	if width == 0 && config.Format == FormatTerm {
+		width = detectWidth(terminal, config.Getenv)
+	}
+
+	// Color policy is decided once here for every renderer
+	enableColors, forceColors := ColorSettings(config.Format, config.NoColor, config.Getenv)
 
 	return &Renderer{
 		config: config,
-		styles: NewStyleManager(config.Format == FormatTerm && !config.NoColor),
+		styles: NewStyleManagerWithConfig(StyleConfig{
+			EnableColors: enableColors,
+			ForceColors:  forceColors,
+			Theme:        config.Theme,
+			Background:   config.Background,
+			Output:       terminal,
+			Hyperlinks:   config.LinkTarget != nil && HyperlinkSettings(config.Hyperlinks, config.Format, terminal, config.Getenv),
+		}),
+		glyphs: Glyphs(config.Charset, config.Getenv),
+		layout: columnLayout{
+			width:           width,
+			annotationWidth: config.AnnotationWidth,
+			minNameWidth:    config.MinNameWidth,
+			placement:       config.AnnotationLayout,
+		},
 	}
 }
 
 // RenderTree renders a tree result according to the configured format
 func (r *Renderer) RenderTree(result *treex.TreeResult) error {
+	return r.RenderTreeContext(context.Background(), result)
+}
+
+// RenderTreeContext renders a tree result, giving up if ctx is canceled
+// Text output is laid out before anything is written, so a render canceled during
+// layout writes nothing
+func (r *Renderer) RenderTreeContext(ctx context.Context, result *treex.TreeResult) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	switch r.config.Format {
 	case FormatJSON:
 		return r.renderJSON(result)
+	case FormatDot:
+		return r.renderDot(result)
+	case FormatPlantUML:
+		return r.renderPlantUML(result)
+	case FormatFlat:
+		return r.renderFlat(result)
+	case FormatMarkdown:
+		return r.renderMarkdown(ctx, result)
 	case FormatPlain, FormatTerm:
-		return r.renderText(result)
-	default:
-		return r.renderText(result) // Default to text rendering
+		return r.renderText(ctx, result)
+	}
+
+	renderers := r.config.Renderers
+	if renderers == nil {
+		renderers = DefaultRenderers
+	}
+	if renderer := renderers.Get(r.config.Format); renderer != nil {
+		return renderer.Render(ctx, r.config, result)
 	}
+	return r.renderText(ctx, result) // Default to text rendering
 }
 
 // renderJSON outputs the tree result as JSON
+// Combined results (see treex.CombineResults) list each root's tree under "trees"
 func (r *Renderer) renderJSON(result *treex.TreeResult) error {
-	// Create a JSON-friendly representation
-	output := map[string]interface{}{
-		"tree":  nodeToJSON(result.Root),
-		"stats": result.Stats,
+	if r.config.paged() {
+		return r.renderJSONPage(result)
 	}
 
-	if len(result.PluginResults) > 0 {
-		output["plugins"] = result.PluginResults
+	var output map[string]interface{}
+	if result.Roots != nil {
+		trees := make([]map[string]interface{}, len(result.Roots))
+		for i, root := range result.Roots {
+			trees[i] = resultToJSON(root)
+		}
+		output = map[string]interface{}{
+			"trees": trees,
+			"stats": result.Stats,
+		}
+	} else {
+		output = resultToJSON(result)
+	}
+	if r.config.ShowErrors {
+		output["warnings"] = treeWarnings(result)
+	}
+	if r.config.ShowStats {
+		output["timing"] = timingJSON(result)
 	}
 
 	encoder := json.NewEncoder(r.config.Writer)
@@ -84,21 +257,59 @@ func (r *Renderer) renderJSON(result *treex.TreeResult) error {
 	return encoder.Encode(output)
 }
 
+// resultToJSON creates a JSON-friendly representation of a single tree result
+func resultToJSON(result *treex.TreeResult) map[string]interface{} {
+	output := map[string]interface{}{
+		"tree":  nodeToJSON(result.Root),
+		"stats": result.Stats,
+	}
+	if len(result.PluginResults) > 0 {
+		output["plugins"] = result.PluginResults
+	}
+	return output
+}
+
 // renderText outputs the tree result as formatted text
-func (r *Renderer) renderText(result *treex.TreeResult) error {
+func (r *Renderer) renderText(ctx context.Context, result *treex.TreeResult) error {
 	if result.Root == nil {
 		return nil
 	}
 
+	// Lay out the tree first so annotations can share a column; a tree too wide for the
+	// output is laid out again with its directory chains flattened
+	r.badgeWidths = badgeWidths(r.config.Badges, result.Root)
+	r.flatten = false
+	lines := r.layOut(result)
+	if r.config.FlattenDirs && r.layout.overflows(lines) {
+		r.flatten = true
+		lines = r.layOut(result)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	// Render the tree structure
-	err := r.renderNode(result.Root, "", true)
+	err := r.writeLines(lines)
 	if err != nil {
 		return err
 	}
 
+	if r.config.Summary {
+		_, err = fmt.Fprintf(r.config.Writer, "\n%s\n", r.styles.StatsItem(summaryLine(result)))
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(r.config.DroppedAnnotations) > 0 {
+		if err := r.renderDroppedAnnotations(); err != nil {
+			return err
+		}
+	}
+
 	// Render statistics if requested
 	if r.config.ShowStats {
-		err = r.renderStats(result.Stats)
+		err = r.renderStats(result)
 		if err != nil {
 			return err
 		}
@@ -107,10 +318,52 @@ func (r *Renderer) renderText(result *treex.TreeResult) error {
 	return nil
 }
 
-// renderNode recursively renders a node and its children
-func (r *Renderer) renderNode(node *types.Node, prefix string, isLast bool) error {
+// layOut collects the lines of a tree result
+func (r *Renderer) layOut(result *treex.TreeResult) []layoutLine {
+	var lines []layoutLine
+	if result.Roots != nil {
+		r.collectCombinedLines(result.Root, &lines)
+	} else {
+		r.collectLines(result.Root, "", true, 0, &lines)
+	}
+	return lines
+}
+
+// chained reports whether a flattened tree shows node together with its only child, a
+// directory: directories with an annotation, an error or a collapsed summary keep their line
+func (r *Renderer) chained(node *types.Node, depth int) bool {
+	if node.Parent == nil || !node.IsDir || len(node.Children) != 1 || !node.Children[0].IsDir {
+		return false
+	}
+	if annotation := node.GetAnnotation(); r.config.ShowNotes && annotation != nil && strings.TrimSpace(annotation.Notes) != "" {
+		return false
+	}
+	return node.Error == "" && !r.shouldCollapse(node, depth)
+}
+
+// styleName styles the displayed name of node: by its heat with Heat, as ls would with
+// LSColors, dimmed for binary, generated and vendored entries, by file class when
+// FileClasses is set
+func (r *Renderer) styleName(node *types.Node, name string) string {
+	if level, ok := r.config.Heat[node]; ok {
+		return r.styles.HeatName(level, name)
+	}
+	if r.config.LSColors != nil {
+		return r.styles.SGRName(r.config.LSColors.Code(node), name)
+	}
+	if node.GetKind() != "" {
+		return r.styles.DimmedFileName(name)
+	}
+	if r.config.FileClasses == nil {
+		return r.styles.FileName(name)
+	}
+	return r.styles.ClassifiedFileName(r.config.FileClasses.Class(node.Name, node.IsDir, node.Size), name)
+}
+
+// collectLines recursively lays out a node and its children
+func (r *Renderer) collectLines(node *types.Node, prefix string, isLast bool, depth int, lines *[]layoutLine) {
 	if node == nil {
-		return nil
+		return
 	}
 
 	// Determine the tree connector
@@ -119,76 +372,220 @@ func (r *Renderer) renderNode(node *types.Node, prefix string, isLast bool) erro
 		// Root node
 		connector = ""
 	} else if isLast {
-		connector = "└─ "
+		connector = r.glyphs.Last
 	} else {
-		connector = "├─ "
+		connector = r.glyphs.Branch
 	}
 
-	// Apply styling
-	styledConnector := r.styles.TreeConnector(connector)
-	styledName := r.styles.FileName(node.Name)
+	// Calculate prefix for children (also used by annotation continuation lines)
+	var childPrefix string
+	if node.Parent == nil {
+		// Root node children don't get additional prefix
+		childPrefix = ""
+	} else if isLast {
+		childPrefix = prefix + r.glyphs.Blank
+	} else {
+		childPrefix = prefix + r.glyphs.Vertical
+	}
 
-	// Build the node line with optional annotation notes
-	line := prefix + styledConnector + styledName
+	// Flattened trees show a chain of single directories as one entry
+	name := node.Name
+	for r.flatten && !r.config.FullPaths && r.chained(node, depth) {
+		node, depth = node.Children[0], depth+1
+		name += "/" + node.Name
+	}
+	if r.config.FullPaths {
+		name = fullPath(node)
+	}
+	if r.config.Icons != nil {
+		if icon := r.config.Icons.Icon(node.Name, node.IsDir); icon != "" {
+			name = icon + " " + name
+		}
+	}
+
+	// Collapsed directories show a summary of their contents instead of their children
+	collapsed := r.shouldCollapse(node, depth)
+	// Names link to their entry when hyperlinks are enabled
+	var target string
+	if r.config.LinkTarget != nil {
+		target = r.config.LinkTarget(node)
+	}
+	styledName := r.styles.Link(r.styleName(node, name), target)
+	if r.config.ShowErrors && node.Error != "" {
+		marker := " [" + node.Error + "]"
+		styledName += r.styles.ErrorMessage(marker)
+		name += marker
+	}
+	if collapsed {
+		summary := " " + summarizeSubtree(node).String()
+		styledName = r.styles.Link(r.styles.FileName(name+collapseMarker), target) + r.styles.Metadata(summary)
+		name += collapseMarker + summary
+	}
+
+	badges := r.badges(node)
+	line := layoutLine{
+		// Apply styling
+		tree:       prefix + r.styles.TreeConnector(connector) + r.styles.PluginResult(badges) + styledName,
+		treeWidth:  ansi.StringWidth(prefix + connector + badges + name),
+		nameStart:  ansi.StringWidth(prefix + connector + badges),
+		continuing: childPrefix,
+	}
+
+	if r.config.Long {
+		line.details = []string{node.Mode.String(), valueOr(node.Owner, "-"), valueOr(node.Group, "-")}
+	}
 
 	// Add annotation notes if ShowNotes is enabled and node has annotation
 	if r.config.ShowNotes {
-		if annotation := node.GetAnnotation(); annotation != nil && annotation.Notes != "" {
-			styledNotes := r.styles.Annotation("   " + annotation.Notes)
-			line += styledNotes
+		if annotation := node.GetAnnotation(); annotation != nil {
+			line.notes = strings.TrimSpace(annotation.Notes)
 		}
 	}
+	*lines = append(*lines, line)
 
-	line += "\n"
+	if collapsed {
+		return
+	}
 
-	// Write the node line
-	_, err := r.config.Writer.Write([]byte(line))
-	if err != nil {
-		return err
+	// Lay out children, replacing files beyond the per-directory limit with an indicator
+	children, hidden := visibleChildren(node, r.config.MaxFilesPerDir)
+	for i, child := range children {
+		r.collectLines(child, childPrefix, i == len(children)-1 && hidden.Files == 0, depth+1, lines)
+	}
+	if hidden.Files > 0 {
+		indicator := hiddenFilesIndicator(hidden)
+		indicatorLine := layoutLine{
+			tree:       childPrefix + r.styles.TreeConnector(r.glyphs.Last) + r.styles.Metadata(indicator),
+			treeWidth:  ansi.StringWidth(childPrefix + r.glyphs.Last + indicator),
+			nameStart:  ansi.StringWidth(childPrefix + r.glyphs.Last),
+			continuing: childPrefix + r.glyphs.Blank,
+		}
+		if r.config.Long {
+			indicatorLine.details = make([]string, len(line.details))
+		}
+		*lines = append(*lines, indicatorLine)
 	}
+}
 
-	// Render children
-	for i, child := range node.Children {
-		childIsLast := i == len(node.Children)-1
+// fullPath is the path of node prefixed with the name of its root; roots keep their name
+func fullPath(node *types.Node) string {
+	root := node
+	for root.Path != "." && root.Parent != nil {
+		root = root.Parent
+	}
+	if root == node {
+		return node.Name
+	}
+	return strings.TrimSuffix(root.Name, "/") + "/" + filepath.ToSlash(node.Path)
+}
 
-		// Calculate prefix for child
-		var childPrefix string
-		if node.Parent == nil {
-			// Root node children don't get additional prefix
-			childPrefix = ""
-		} else if isLast {
-			childPrefix = prefix + "   "
-		} else {
-			childPrefix = prefix + "│  "
+// collectCombinedLines lays out the synthetic root of combined results, separating its
+// trees with a guide line; each tree counts depth from its own root
+func (r *Renderer) collectCombinedLines(root *types.Node, lines *[]layoutLine) {
+	label := layoutLine{
+		tree:      r.styles.Metadata(root.Name),
+		treeWidth: ansi.StringWidth(root.Name),
+	}
+	separator := strings.TrimRight(r.glyphs.Vertical, " ")
+	separatorLine := layoutLine{
+		tree:      r.styles.TreeConnector(separator),
+		treeWidth: ansi.StringWidth(separator),
+	}
+	if r.config.Long {
+		label.details = make([]string, 3)
+		separatorLine.details = make([]string, 3)
+	}
+
+	*lines = append(*lines, label)
+	for i, child := range root.Children {
+		if i > 0 {
+			*lines = append(*lines, separatorLine)
 		}
+		r.collectLines(child, "", i == len(root.Children)-1, 0, lines)
+	}
+}
 
-		err = r.renderNode(child, childPrefix, childIsLast)
-		if err != nil {
-			return err
+// writeLines writes laid out lines, aligning annotations on a shared column
+func (r *Renderer) writeLines(lines []layoutLine) error {
+	detailWidths := detailColumnWidths(lines)
+	layout := r.layout.forDetails(detailWidths)
+
+	// Names too wide for the output are cut before annotations are placed
+	for i := range lines {
+		lines[i] = layout.fit(lines[i])
+	}
+	column := layout.column(lines)
+
+	var out strings.Builder
+	for _, line := range lines {
+		if len(line.details) > 0 {
+			out.WriteString(r.styles.Metadata(formatDetails(line.details, detailWidths)))
 		}
+		out.WriteString(line.tree)
+
+		if line.notes != "" {
+			lineColumn, notes := layout.place(line, column)
+			for i, note := range notes {
+				if i == 0 && layout.placement != AnnotationsBelow {
+					out.WriteString(strings.Repeat(" ", lineColumn-line.treeWidth))
+				} else {
+					guide := line.continuing
+					out.WriteString("\n")
+					if len(line.details) > 0 {
+						out.WriteString(formatDetails(make([]string, len(line.details)), detailWidths))
+					}
+					out.WriteString(r.styles.TreeConnector(guide) +
+						strings.Repeat(" ", max(lineColumn-ansi.StringWidth(guide), 0)))
+				}
+				out.WriteString(r.styles.Annotation(note))
+			}
+		}
+
+		out.WriteString("\n")
 	}
 
-	return nil
+	_, err := r.config.Writer.Write([]byte(out.String()))
+	return err
 }
 
 // renderStats renders statistics information
-func (r *Renderer) renderStats(stats treex.TreeStats) error {
-	statsText := r.styles.StatsHeader("\nStatistics:\n") +
+func (r *Renderer) renderStats(result *treex.TreeResult) error {
+	stats := result.Stats
+	statsText := "\n" + r.styles.StatsHeader("Statistics:") + "\n" +
 		r.styles.StatsItem("  Files: ") + r.styles.StatsValue(formatNumber(stats.TotalFiles)) + "\n" +
 		r.styles.StatsItem("  Directories: ") + r.styles.StatsValue(formatNumber(stats.TotalDirectories)) + "\n" +
+		r.styles.StatsItem("  Annotated: ") + r.styles.StatsValue(formatNumber(stats.Annotated)) + "\n" +
 		r.styles.StatsItem("  Max Depth: ") + r.styles.StatsValue(formatNumber(stats.MaxDepthReached)) + "\n"
 
 	if stats.FilteredOut > 0 {
 		statsText += r.styles.StatsItem("  Filtered Out: ") + r.styles.StatsValue(formatNumber(stats.FilteredOut)) + "\n"
 	}
+	if stats.Errors > 0 {
+		statsText += r.styles.StatsItem("  Unreadable: ") + r.styles.StatsValue(formatNumber(stats.Errors)) + "\n"
+	}
+	statsText += r.styles.StatsItem("  Build Time: ") + r.styles.StatsValue(formatDuration(result.Elapsed)) + "\n"
 
 	_, err := r.config.Writer.Write([]byte(statsText))
 	return err
 }
 
+// formatDuration rounds d for display: to the millisecond, or the microsecond below 1ms
+func formatDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// timingJSON is the "timing" object JSON output carries with ShowStats
+func timingJSON(result *treex.TreeResult) map[string]interface{} {
+	return map[string]interface{}{"build_ms": float64(result.Elapsed.Microseconds()) / 1000}
+}
+
 // detectOutputFormat automatically determines the appropriate output format
-func detectOutputFormat(writer io.Writer, autoDetect bool) OutputFormat {
-	if !autoDetect {
+// CLICOLOR_FORCE (ColorAlways) keeps the terminal format even when output is piped
+func detectOutputFormat(writer io.Writer, autoDetect bool, colorMode ColorMode) OutputFormat {
+	if !autoDetect || colorMode == ColorAlways {
 		return FormatTerm
 	}
 
@@ -214,24 +611,51 @@ func nodeToJSON(node *types.Node) interface{} {
 		return nil
 	}
 
+	result := nodeFieldsJSON(node)
+	if len(node.Children) > 0 {
+		children := make([]interface{}, len(node.Children))
+		for i, child := range node.Children {
+			children[i] = nodeToJSON(child)
+		}
+		result["children"] = children
+	}
+
+	return result
+}
+
+// nodeFieldsJSON converts a single node, without its children, to JSON-serializable format
+func nodeFieldsJSON(node *types.Node) map[string]interface{} {
 	result := map[string]interface{}{
 		"name":  node.Name,
-		"path":  node.Path,
+		"path":  pathutil.Normalize(node.Path),
 		"isDir": node.IsDir,
 		"size":  node.Size,
 	}
 
+	// Include permissions and ownership when captured during the walk
+	if node.Mode != 0 {
+		result["mode"] = node.Mode.String()
+	}
+	if node.Owner != "" {
+		result["owner"] = node.Owner
+	}
+	if node.Group != "" {
+		result["group"] = node.Group
+	}
+
+	// Include why a directory could not be read
+	if node.Error != "" {
+		result["error"] = node.Error
+	}
+
 	// Include annotation notes if present
 	if annotation := node.GetAnnotation(); annotation != nil && annotation.Notes != "" {
 		result["notes"] = annotation.Notes
 	}
 
-	if len(node.Children) > 0 {
-		children := make([]interface{}, len(node.Children))
-		for i, child := range node.Children {
-			children[i] = nodeToJSON(child)
-		}
-		result["children"] = children
+	// Include the kind of binary, generated and vendored entries
+	if kind := node.GetKind(); kind != "" {
+		result["kind"] = string(kind)
 	}
 
 	return result
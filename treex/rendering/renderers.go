@@ -0,0 +1,86 @@
+package rendering
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"treex/treex"
+)
+
+// CustomFormatPrefix starts the name of every format registered from outside treex,
+// so third-party formats never collide with built-in ones
+const CustomFormatPrefix = "x-"
+
+// customFormatName is a registered format name: the prefix, then lowercase words
+var customFormatName = regexp.MustCompile(`^x-[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// FormatRenderer renders trees in an output format added by a program embedding treex
+// Register one with RegisterRenderer (usually from an init function); --format then
+// accepts its name like a built-in format.
+type FormatRenderer interface {
+	// Format returns the format name, e.g. "x-orgchart"
+	Format() OutputFormat
+
+	// Description is a short text listed with the format names in help
+	Description() string
+
+	// Render writes result to config.Writer
+	// config is the renderer's configuration with its defaults filled in; colors are
+	// never enabled for custom formats
+	Render(ctx context.Context, config RenderConfig, result *treex.TreeResult) error
+}
+
+// RendererRegistry holds the custom output formats by name
+type RendererRegistry struct {
+	renderers map[OutputFormat]FormatRenderer
+}
+
+// NewRendererRegistry creates an empty renderer registry
+func NewRendererRegistry() *RendererRegistry {
+	return &RendererRegistry{renderers: make(map[OutputFormat]FormatRenderer)}
+}
+
+// Register adds a renderer to the registry
+// Returns an error if its name lacks the x- namespace, is not lowercase words joined by
+// hyphens, or is already registered
+func (r *RendererRegistry) Register(renderer FormatRenderer) error {
+	format := renderer.Format()
+	if !strings.HasPrefix(string(format), CustomFormatPrefix) {
+		return fmt.Errorf("format %q must start with %q", format, CustomFormatPrefix)
+	}
+	if !customFormatName.MatchString(string(format)) {
+		return fmt.Errorf("format %q must be lowercase letters and digits joined by hyphens", format)
+	}
+	if _, exists := r.renderers[format]; exists {
+		return fmt.Errorf("format %q is already registered", format)
+	}
+
+	r.renderers[format] = renderer
+	return nil
+}
+
+// Get returns the renderer of format, or nil if none is registered
+func (r *RendererRegistry) Get(format OutputFormat) FormatRenderer {
+	return r.renderers[format]
+}
+
+// Renderers returns the registered renderers sorted by format name
+func (r *RendererRegistry) Renderers() []FormatRenderer {
+	renderers := make([]FormatRenderer, 0, len(r.renderers))
+	for _, renderer := range r.renderers {
+		renderers = append(renderers, renderer)
+	}
+	sort.Slice(renderers, func(i, j int) bool { return renderers[i].Format() < renderers[j].Format() })
+	return renderers
+}
+
+// DefaultRenderers is the registry the treex command reads --format from
+var DefaultRenderers = NewRendererRegistry()
+
+// RegisterRenderer is a convenience function to register with DefaultRenderers
+func RegisterRenderer(renderer FormatRenderer) error {
+	return DefaultRenderers.Register(renderer)
+}
@@ -0,0 +1,70 @@
+package rendering_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+)
+
+// orgChart lists the root's children under the root's name
+type orgChart struct {
+	format rendering.OutputFormat
+}
+
+func (o orgChart) Format() rendering.OutputFormat { return o.format }
+
+func (o orgChart) Description() string { return "Top-level entries as an org chart" }
+
+func (o orgChart) Render(ctx context.Context, config rendering.RenderConfig, result *treex.TreeResult) error {
+	fmt.Fprintf(config.Writer, "[%s]\n", result.Root.Name)
+	for _, child := range result.Root.Children {
+		fmt.Fprintf(config.Writer, "  %s\n", child.Name)
+	}
+	return nil
+}
+
+func TestRendererRegistry(t *testing.T) {
+	registry := rendering.NewRendererRegistry()
+	require.NoError(t, registry.Register(orgChart{format: "x-orgchart"}))
+	require.NoError(t, registry.Register(orgChart{format: "x-dept-2"}))
+
+	assert.ErrorContains(t, registry.Register(orgChart{format: "x-orgchart"}), "already registered")
+	assert.ErrorContains(t, registry.Register(orgChart{format: "orgchart"}), `must start with "x-"`)
+	assert.ErrorContains(t, registry.Register(orgChart{format: "json"}), `must start with "x-"`)
+	for _, format := range []rendering.OutputFormat{"x-", "x-Org", "x-org chart", "x--org", "x-org-"} {
+		assert.ErrorContains(t, registry.Register(orgChart{format: format}), "lowercase", format)
+	}
+
+	assert.NotNil(t, registry.Get("x-orgchart"))
+	assert.Nil(t, registry.Get("x-missing"))
+
+	var formats []rendering.OutputFormat
+	for _, renderer := range registry.Renderers() {
+		formats = append(formats, renderer.Format())
+	}
+	assert.Equal(t, []rendering.OutputFormat{"x-dept-2", "x-orgchart"}, formats)
+}
+
+func TestRenderCustomFormat(t *testing.T) {
+	registry := rendering.NewRendererRegistry()
+	require.NoError(t, registry.Register(orgChart{format: "x-orgchart"}))
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: "x-orgchart", Writer: &buf, Renderers: registry})
+	require.NoError(t, renderer.RenderTree(flatTree()))
+	assert.Equal(t, "[project]\n  src\n  docs\n  README.md\n", buf.String())
+
+	t.Run("unregistered formats fall back to text", func(t *testing.T) {
+		buf.Reset()
+		renderer := rendering.NewRenderer(rendering.RenderConfig{Format: "x-unknown", Writer: &buf, Renderers: registry})
+		require.NoError(t, renderer.RenderTree(flatTree()))
+		assert.Contains(t, buf.String(), "README.md")
+		assert.NotContains(t, buf.String(), "[project]")
+	})
+}
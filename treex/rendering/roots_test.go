@@ -0,0 +1,61 @@
+package rendering_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+// rootResult builds a single-level tree result with the given files
+func rootResult(files ...string) *treex.TreeResult {
+	root := &types.Node{Name: ".", Path: ".", IsDir: true}
+	for _, file := range files {
+		root.Children = append(root.Children, &types.Node{Name: file, Path: file, Parent: root})
+	}
+	return &treex.TreeResult{Root: root, Stats: treex.TreeStats{TotalFiles: len(files), TotalDirectories: 1}}
+}
+
+func TestRenderCombinedRoots(t *testing.T) {
+	combined := treex.CombineResults("2 roots", []string{"src", "../docs"},
+		[]*treex.TreeResult{rootResult("main.go", "util.go"), rootResult("index.md")})
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatPlain, Writer: &buf, Charset: rendering.CharsetUnicode})
+	require.NoError(t, renderer.RenderTree(combined))
+	assert.Equal(t, "2 roots\n"+
+		"├─ src\n"+
+		"│  ├─ main.go\n"+
+		"│  └─ util.go\n"+
+		"│\n"+
+		"└─ ../docs\n"+
+		"   └─ index.md\n", buf.String())
+}
+
+func TestRenderCombinedRootsJSON(t *testing.T) {
+	combined := treex.CombineResults("2 roots", []string{"src", "docs"},
+		[]*treex.TreeResult{rootResult("main.go"), rootResult("index.md", "guide.md")})
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatJSON, Writer: &buf})
+	require.NoError(t, renderer.RenderTree(combined))
+
+	var output struct {
+		Trees []struct {
+			Tree  map[string]interface{} `json:"tree"`
+			Stats treex.TreeStats        `json:"stats"`
+		} `json:"trees"`
+		Stats treex.TreeStats `json:"stats"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+	require.Len(t, output.Trees, 2)
+	assert.Equal(t, "src", output.Trees[0].Tree["name"])
+	assert.Equal(t, "docs", output.Trees[1].Tree["name"])
+	assert.Equal(t, 2, output.Trees[1].Stats.TotalFiles)
+	assert.Equal(t, treex.TreeStats{TotalFiles: 3, TotalDirectories: 2}, output.Stats)
+}
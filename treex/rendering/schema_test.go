@@ -0,0 +1,67 @@
+package rendering_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/schema"
+)
+
+// TestJSONOutputsMatchSchemas renders every shape of the JSON outputs and validates it
+// against the published schema, so the schemas cannot drift from the renderers
+func TestJSONOutputsMatchSchemas(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":     "README.md  Overview\n",
+		"README.md": "# project",
+		"src":       map[string]interface{}{"main.go": "package main"},
+	})
+	built, err := treex.BuildTree(treex.TreeConfig{
+		Root:          "/project",
+		Filesystem:    fs,
+		PluginFilters: map[string]map[string]bool{"info": {"annotated": true}},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, built.PluginResults)
+
+	combined := treex.CombineResults("2 roots", []string{"src", "docs"},
+		[]*treex.TreeResult{rootResult("main.go"), rootResult("index.md", "guide.md")})
+
+	outputs := map[string]rendering.RenderConfig{
+		"built tree":     {},
+		"combined roots": {},
+		"warnings":       {ShowErrors: true},
+		"page":           {Limit: 1},
+		"combined page":  {Limit: 2, Offset: 1, ShowErrors: true},
+		"timing":         {ShowStats: true},
+		"timed page":     {Limit: 1, ShowStats: true},
+	}
+	results := map[string]*treex.TreeResult{
+		"built tree":     built,
+		"combined roots": combined,
+		"warnings":       unreadableResult(),
+		"page":           unreadableResult(),
+		"combined page":  combined,
+		"timing":         combined,
+		"timed page":     built,
+	}
+	for name, config := range outputs {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			config.Format, config.Writer = rendering.FormatJSON, &buf
+			require.NoError(t, rendering.NewRenderer(config).RenderTree(results[name]))
+			require.NoError(t, schema.Validate("tree", buf.Bytes()), buf.String())
+		})
+	}
+
+	t.Run("structure stats", func(t *testing.T) {
+		var buf bytes.Buffer
+		renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatJSON, Writer: &buf})
+		require.NoError(t, renderer.RenderStructureStats(treex.AnalyzeStructure(deepTree(), 0)))
+		require.NoError(t, schema.Validate("stats", buf.Bytes()), buf.String())
+	})
+}
@@ -0,0 +1,122 @@
+package rendering
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"treex/treex"
+)
+
+// RenderStructureStats renders structural statistics as tables, or as JSON
+func (r *Renderer) RenderStructureStats(stats *treex.StructureStats) error {
+	if r.config.Format == FormatJSON {
+		encoder := json.NewEncoder(r.config.Writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+
+	var out strings.Builder
+
+	out.WriteString(r.styles.StatsHeader("Summary") + "\n")
+	r.writeTable(&out, nil, [][]string{
+		{"Files", formatNumber(stats.Files)},
+		{"Directories", formatNumber(stats.Directories)},
+		{".info files", formatNumber(stats.InfoFiles)},
+		{"Annotated entries", formatNumber(stats.Annotated)},
+	})
+
+	out.WriteString("\n" + r.styles.StatsHeader("By depth") + "\n")
+	rows := make([][]string, 0, len(stats.ByDepth))
+	for _, depth := range stats.ByDepth {
+		rows = append(rows, []string{formatNumber(depth.Depth), formatNumber(depth.Directories), formatNumber(depth.Files)})
+	}
+	r.writeTable(&out, []string{"Depth", "Dirs", "Files"}, rows)
+
+	out.WriteString("\n" + r.styles.StatsHeader("By extension") + "\n")
+	rows = make([][]string, 0, len(stats.ByExtension))
+	for _, ext := range stats.ByExtension {
+		name := "(none)"
+		if ext.Extension != "" {
+			name = "." + ext.Extension
+		}
+		rows = append(rows, []string{name, formatNumber(ext.Files), formatSize(ext.Size)})
+	}
+	r.writeTable(&out, []string{"Extension", "Files", "Size"}, rows)
+
+	out.WriteString("\n" + r.styles.StatsHeader("Largest directories") + "\n")
+	rows = make([][]string, 0, len(stats.LargestDirectories))
+	for _, dir := range stats.LargestDirectories {
+		rows = append(rows, []string{dir.Path, formatNumber(dir.Files), formatSize(dir.Size)})
+	}
+	r.writeTable(&out, []string{"Directory", "Files", "Size"}, rows)
+
+	out.WriteString("\n" + r.styles.StatsHeader("Annotation coverage") + "\n")
+	rows = make([][]string, 0, len(stats.Coverage))
+	for _, coverage := range stats.Coverage {
+		rows = append(rows, []string{
+			coverage.Path,
+			fmt.Sprintf("%d/%d", coverage.Annotated, coverage.Entries),
+			fmt.Sprintf("%.0f%%", coverage.Percent),
+		})
+	}
+	r.writeTable(&out, []string{"Subtree", "Annotated", "Coverage"}, rows)
+
+	_, err := r.config.Writer.Write([]byte(out.String()))
+	return err
+}
+
+// writeTable writes rows as left-aligned columns indented by two spaces
+// The first column holds labels; the others hold values
+func (r *Renderer) writeTable(out *strings.Builder, header []string, rows [][]string) {
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], ansi.StringWidth(cell))
+		}
+	}
+
+	writeRow := func(row []string, labelStyle, valueStyle func(string) string) {
+		out.WriteString("  ")
+		for i, cell := range row {
+			padded := PadRight(cell, widths[i])
+			if i == len(row)-1 {
+				padded = strings.TrimRight(padded, " ")
+			}
+			if i == 0 {
+				out.WriteString(labelStyle(padded))
+			} else {
+				out.WriteString("  " + valueStyle(padded))
+			}
+		}
+		out.WriteString("\n")
+	}
+
+	if len(header) > 0 {
+		writeRow(header, r.styles.StatsItem, r.styles.StatsItem)
+	}
+	for _, row := range rows {
+		writeRow(row, r.styles.StatsItem, r.styles.StatsValue)
+	}
+}
+
+// formatSize formats a byte count with a binary unit (B, KB, MB, GB)
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value := float64(size)
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		value /= unit
+		if value < unit || suffix == "GB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", size)
+}
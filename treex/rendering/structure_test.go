@@ -0,0 +1,53 @@
+package rendering_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+func TestRenderStructureStats(t *testing.T) {
+	stats := treex.AnalyzeStructure(deepTree(), 0)
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatPlain, Writer: &buf})
+		require.NoError(t, renderer.RenderStructureStats(stats))
+
+		output := buf.String()
+		assert.Contains(t, output, "Summary\n  Files              4\n  Directories        4\n")
+		assert.Contains(t, output, "  Extension  Files  Size\n  .go        3      0 B\n  .sql       1      0 B\n")
+		assert.Contains(t, output, "  internal  2/6        33%\n")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatJSON, Writer: &buf})
+		require.NoError(t, renderer.RenderStructureStats(stats))
+
+		var decoded treex.StructureStats
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+		assert.Equal(t, *stats, decoded)
+	})
+}
+
+func TestRenderStructureStatsWideCharacters(t *testing.T) {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	data := &types.Node{Name: "数据", Path: "数据", IsDir: true}
+	src := &types.Node{Name: "src", Path: "src", IsDir: true}
+	addChildren(root, data, src)
+	addChildren(data, &types.Node{Name: "a.go", Path: "数据/a.go"}, &types.Node{Name: "b.go", Path: "数据/b.go"})
+	addChildren(src, &types.Node{Name: "c.go", Path: "src/c.go"})
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatPlain, Writer: &buf})
+	require.NoError(t, renderer.RenderStructureStats(treex.AnalyzeStructure(root, 0)))
+
+	assert.Contains(t, buf.String(), "  Directory  Files  Size\n  src        1      0 B\n  数据       2      0 B\n")
+}
@@ -3,17 +3,28 @@
 package rendering
 
 import (
+	"io"
+	"os"
 	"strconv"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 // StyleManager manages the two-layer styling system
 type StyleManager struct {
-	enabled            bool // Whether styling is enabled
+	enabled            bool   // Whether styling is enabled
+	hyperlinks         bool   // Whether Link emits OSC 8 hyperlinks
+	theme              *Theme // Theme providing the presentation styles
 	presentationStyles *PresentationStyles
+	classStyles        map[FileClass]lipgloss.Style // File class styles the theme defines
+	rawColors          bool                         // Whether raw SGR codes reach the output (see SGRName)
+	heatStyles         []lipgloss.Style             // Heat gradient from cold to hot (see HeatName)
 }
 
+// heatColors are the ANSI 256 colors of the heat gradient, from cold (blue) to hot (red)
+var heatColors = []string{"33", "37", "178", "208", "196"}
+
 // PresentationStyles defines the visual properties (CSS-like properties)
 type PresentationStyles struct {
 	// Text strength variations
@@ -36,32 +47,104 @@ type PresentationStyles struct {
 	SubtleText lipgloss.Style
 }
 
-// NewStyleManager creates a new style manager
+// StyleConfig configures a style manager
+type StyleConfig struct {
+	EnableColors bool           // Whether styling is enabled
+	ForceColors  bool           // Emit color even if the output is not a terminal (CLICOLOR_FORCE)
+	Theme        *Theme         // Theme for presentation styles (nil uses the default theme)
+	Background   BackgroundMode // Light/dark palette selection for adaptive colors (empty = auto)
+	Output       io.Writer      // Output used for color profile and background detection (nil = stdout)
+	Hyperlinks   bool           // Emit OSC 8 hyperlinks from Link (see HyperlinkSettings)
+}
+
+// NewStyleManager creates a new style manager using the default theme
 func NewStyleManager(enableColors bool) *StyleManager {
+	return NewStyleManagerWithConfig(StyleConfig{EnableColors: enableColors})
+}
+
+// NewStyleManagerWithTheme creates a style manager whose presentation styles come from theme
+// A nil theme uses the default builtin theme
+func NewStyleManagerWithTheme(enableColors bool, theme *Theme) *StyleManager {
+	return NewStyleManagerWithConfig(StyleConfig{EnableColors: enableColors, Theme: theme})
+}
+
+// NewStyleManagerWithConfig creates a style manager from a full style configuration
+func NewStyleManagerWithConfig(config StyleConfig) *StyleManager {
+	if config.Theme == nil {
+		config.Theme = BuiltinThemes()[0]
+	}
+	if config.Output == nil {
+		config.Output = os.Stdout
+	}
+
+	// A dedicated lipgloss renderer keeps background overrides local to this manager
+	renderer := lipgloss.NewRenderer(config.Output)
+	if config.EnableColors && config.ForceColors {
+		renderer.SetColorProfile(termenv.ANSI256)
+	}
+	if config.EnableColors {
+		renderer.SetHasDarkBackground(ResolveDarkBackground(config.Background, os.Getenv, renderer.HasDarkBackground))
+	}
+
+	classStyles := make(map[FileClass]lipgloss.Style)
+	if config.EnableColors {
+		for name, spec := range config.Theme.Classes {
+			classStyles[FileClass(name)] = spec.Style(renderer)
+		}
+	}
+
 	return &StyleManager{
-		enabled:            enableColors,
-		presentationStyles: newPresentationStyles(enableColors),
+		enabled:            config.EnableColors,
+		hyperlinks:         config.Hyperlinks,
+		theme:              config.Theme,
+		presentationStyles: newPresentationStyles(config.EnableColors, config.Theme, renderer),
+		classStyles:        classStyles,
+		heatStyles:         newHeatStyles(config.EnableColors, renderer),
+		rawColors:          config.EnableColors && renderer.ColorProfile() != termenv.Ascii,
 	}
 }
 
-// newPresentationStyles creates presentation styles with adaptive theming
-func newPresentationStyles(enableColors bool) *PresentationStyles {
-	// For now, start with empty styles as instructed
-	// "at first we can use empty styles, just to get the structure right"
-	emptyStyle := lipgloss.NewStyle()
+// Theme returns the theme backing this style manager
+func (sm *StyleManager) Theme() *Theme {
+	return sm.theme
+}
+
+// newPresentationStyles creates presentation styles from a theme with adaptive theming
+// When colors are disabled every style is empty, regardless of the theme
+func newPresentationStyles(enableColors bool, theme *Theme, renderer *lipgloss.Renderer) *PresentationStyles {
+	styleFor := func(name string) lipgloss.Style {
+		if !enableColors {
+			return renderer.NewStyle()
+		}
+		// Styles missing from the theme stay empty
+		return theme.Styles[name].Style(renderer)
+	}
+
 	return &PresentationStyles{
-		StrongText:   emptyStyle,
-		NormalText:   emptyStyle,
-		WeakText:     emptyStyle,
-		ActiveText:   emptyStyle,
-		InactiveText: emptyStyle,
-		SuccessText:  emptyStyle,
-		ErrorText:    emptyStyle,
-		WarningText:  emptyStyle,
-		InfoText:     emptyStyle,
-		HeaderText:   emptyStyle,
-		SubtleText:   emptyStyle,
+		StrongText:   styleFor("strong"),
+		NormalText:   styleFor("normal"),
+		WeakText:     styleFor("weak"),
+		ActiveText:   styleFor("active"),
+		InactiveText: styleFor("inactive"),
+		SuccessText:  styleFor("success"),
+		ErrorText:    styleFor("error"),
+		WarningText:  styleFor("warning"),
+		InfoText:     styleFor("info"),
+		HeaderText:   styleFor("header"),
+		SubtleText:   styleFor("subtle"),
+	}
+}
+
+// newHeatStyles creates the styles of the heat gradient; they are empty without colors
+func newHeatStyles(enableColors bool, renderer *lipgloss.Renderer) []lipgloss.Style {
+	styles := make([]lipgloss.Style, len(heatColors))
+	for i, color := range heatColors {
+		styles[i] = renderer.NewStyle()
+		if enableColors {
+			styles[i] = styles[i].Foreground(lipgloss.Color(color))
+		}
 	}
+	return styles
 }
 
 // Semantic Style Methods
@@ -77,6 +160,42 @@ func (sm *StyleManager) FileName(text string) string {
 	return sm.presentationStyles.NormalText.Render(text)
 }
 
+// ClassifiedFileName styles a file name by its file class
+// Classes the theme does not style fall back to FileName, and large files to warnings.
+func (sm *StyleManager) ClassifiedFileName(class FileClass, text string) string {
+	if style, ok := sm.classStyles[class]; ok {
+		return style.Render(text)
+	}
+	if class == ClassLarge {
+		return sm.presentationStyles.WarningText.Render(text)
+	}
+	return sm.FileName(text)
+}
+
+// HeatName styles a name by its step on the heat gradient, 0 the coldest
+// Steps beyond the gradient are clamped to its ends.
+func (sm *StyleManager) HeatName(level int, text string) string {
+	return sm.heatStyles[min(max(level, 0), len(sm.heatStyles)-1)].Render(text)
+}
+
+// SGRName styles a file name with a raw SGR code such as "01;34" (see LSColors)
+// The code is ignored when colors are disabled, the output has no colors, or it is empty.
+func (sm *StyleManager) SGRName(code, text string) string {
+	if !sm.rawColors || code == "" {
+		return sm.FileName(text)
+	}
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
+
+// Link makes already styled text a hyperlink to target when hyperlinks are enabled
+// An empty target leaves the text as is.
+func (sm *StyleManager) Link(text, target string) string {
+	if !sm.hyperlinks || target == "" {
+		return text
+	}
+	return osc8(text, target)
+}
+
 // DirectoryName styles directory names specifically
 func (sm *StyleManager) DirectoryName(text string) string {
 	return sm.presentationStyles.ActiveText.Render(text)
@@ -87,6 +206,11 @@ func (sm *StyleManager) Annotation(text string) string {
 	return sm.presentationStyles.InfoText.Render(text)
 }
 
+// Metadata styles secondary node details such as permissions and ownership
+func (sm *StyleManager) Metadata(text string) string {
+	return sm.presentationStyles.WeakText.Render(text)
+}
+
 // ErrorMessage styles error messages
 func (sm *StyleManager) ErrorMessage(text string) string {
 	return sm.presentationStyles.ErrorText.Render(text)
@@ -122,6 +246,12 @@ func (sm *StyleManager) HiddenFile(text string) string {
 	return sm.presentationStyles.SubtleText.Render(text)
 }
 
+// DimmedFileName styles the names of files people do not write: binary, generated or
+// vendored (see types.FileKind)
+func (sm *StyleManager) DimmedFileName(text string) string {
+	return sm.presentationStyles.SubtleText.Render(text)
+}
+
 // PluginResult styles plugin-generated content
 func (sm *StyleManager) PluginResult(text string) string {
 	return sm.presentationStyles.InfoText.Render(text)
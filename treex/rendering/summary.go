@@ -0,0 +1,36 @@
+package rendering
+
+import (
+	"math"
+
+	"treex/treex"
+	"treex/treex/i18n"
+)
+
+// summaryLine formats the footer printed after the tree, as tree(1) does:
+// "12 directories, 148 files, 36 annotated (24%)". Root directories are not counted, and
+// the annotated part is omitted when nothing is annotated
+func summaryLine(result *treex.TreeResult) string {
+	roots := 1
+	if result.Roots != nil {
+		roots = 0
+		for _, root := range result.Roots {
+			if root.Root != nil {
+				roots++
+			}
+		}
+	}
+	stats := result.Stats
+	directories := max(stats.TotalDirectories-roots, 0)
+
+	line := i18n.Plural(directories, "%d directory", "%d directories") + ", " + i18n.Plural(stats.TotalFiles, "%d file", "%d files")
+	if stats.Annotated == 0 {
+		return line
+	}
+	entries := directories + stats.TotalFiles
+	if entries == 0 {
+		return i18n.T("%s, %d annotated", line, stats.Annotated)
+	}
+	percent := math.Round(float64(stats.Annotated) * 100 / float64(entries))
+	return i18n.T("%s, %d annotated (%.0f%%)", line, stats.Annotated, percent)
+}
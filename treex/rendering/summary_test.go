@@ -0,0 +1,100 @@
+package rendering_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"treex/treex"
+	"treex/treex/i18n"
+	"treex/treex/rendering"
+)
+
+// renderSummary renders result as plain text with the summary footer and returns its last line
+func renderSummary(t *testing.T, result *treex.TreeResult) string {
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatPlain, Writer: &buf, Summary: true})
+	require.NoError(t, renderer.RenderTree(result))
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+	require.GreaterOrEqual(t, len(lines), 3)
+	assert.Empty(t, lines[len(lines)-2], "a blank line separates the summary from the tree")
+	return string(lines[len(lines)-1])
+}
+
+func TestRenderSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		stats    treex.TreeStats
+		expected string
+	}{
+		{"root only counts its contents", treex.TreeStats{TotalFiles: 2, TotalDirectories: 1}, "0 directories, 2 files"},
+		{"singular nouns", treex.TreeStats{TotalFiles: 1, TotalDirectories: 2}, "1 directory, 1 file"},
+		{"annotated share", treex.TreeStats{TotalFiles: 148, TotalDirectories: 3, Annotated: 36}, "2 directories, 148 files, 36 annotated (24%)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := rootResult("main.go")
+			result.Stats = tt.stats
+			assert.Equal(t, tt.expected, renderSummary(t, result))
+		})
+	}
+}
+
+func TestRenderSummaryCombinedRoots(t *testing.T) {
+	combined := treex.CombineResults("2 roots", []string{"src", "docs"},
+		[]*treex.TreeResult{rootResult("main.go", "util.go"), rootResult("index.md")})
+	assert.Equal(t, "0 directories, 3 files", renderSummary(t, combined), "each root is left out of the count")
+}
+
+func TestRenderSummaryTranslated(t *testing.T) {
+	i18n.SetLanguage(language.BrazilianPortuguese)
+	defer i18n.SetLanguage(language.English)
+
+	result := rootResult("main.go")
+	result.Stats = treex.TreeStats{TotalFiles: 1234, TotalDirectories: 2, Annotated: 617}
+	assert.Equal(t, "1 diretório, 1.234 arquivos, 617 com anotação (50%)", renderSummary(t, result))
+}
+
+func TestRenderSummaryOff(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatPlain, Writer: &buf})
+	require.NoError(t, renderer.RenderTree(rootResult("main.go")))
+	assert.NotContains(t, buf.String(), "files")
+}
+
+func TestRenderStats(t *testing.T) {
+	result := rootResult("main.go")
+	result.Stats.Annotated = 1
+	result.Elapsed = 1500 * time.Microsecond
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatPlain, Writer: &buf, ShowStats: true})
+		require.NoError(t, renderer.RenderTree(result))
+		assert.Contains(t, buf.String(), "Statistics:\n  Files: 1\n  Directories: 1\n  Annotated: 1\n  Max Depth: 0\n  Build Time: 2ms\n")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatJSON, Writer: &buf, ShowStats: true})
+		require.NoError(t, renderer.RenderTree(result))
+		var output struct {
+			Timing struct {
+				BuildMS float64 `json:"build_ms"`
+			} `json:"timing"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+		assert.Equal(t, 1.5, output.Timing.BuildMS)
+	})
+
+	t.Run("json without stats", func(t *testing.T) {
+		var buf bytes.Buffer
+		renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatJSON, Writer: &buf})
+		require.NoError(t, renderer.RenderTree(result))
+		assert.NotContains(t, buf.String(), "timing")
+	})
+}
@@ -0,0 +1,338 @@
+package rendering
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+	"treex/treex/pathutil"
+)
+
+// DefaultThemeName is the theme used when none is selected
+const DefaultThemeName = "default"
+
+// PresentationStyleNames lists the presentation style keys a theme can define
+var PresentationStyleNames = []string{
+	"strong", "normal", "weak",
+	"active", "inactive",
+	"success", "error", "warning", "info",
+	"header", "subtle",
+}
+
+// Theme maps presentation styles to visual properties
+// Semantic styles keep pointing at presentation styles, so a theme restyles every
+// renderer consistently.
+//
+// Theme files are YAML:
+//
+//	name: ocean
+//	description: Blue tones
+//	styles:
+//	  strong: { foreground: "#268bd2", bold: true }
+//	  weak:   { foreground: { light: "#93a1a1", dark: "#586e75" } }
+//	classes:
+//	  code:  { foreground: "#859900" }
+//	  large: { foreground: "#dc322f", bold: true }
+//	extensions:
+//	  code: [zig, odin]
+//	large-files: 10MB
+type Theme struct {
+	Name        string               `yaml:"name"`
+	Description string               `yaml:"description"`
+	Styles      map[string]StyleSpec `yaml:"styles"`
+
+	// Classes styles file names by FileClass (code, docs, config, binary, large)
+	Classes map[string]StyleSpec `yaml:"classes"`
+	// Extensions adds extensions to the builtin groups of each class
+	Extensions map[string][]string `yaml:"extensions"`
+	// LargeFiles is the size from which files are "large", e.g. 10MB (empty = off)
+	LargeFiles string `yaml:"large-files"`
+
+	// Source is where the theme was loaded from ("builtin" or a file path)
+	Source string `yaml:"-"`
+}
+
+// StyleSpec describes the visual properties of a single presentation style
+type StyleSpec struct {
+	Foreground ColorSpec `yaml:"foreground"`
+	Background ColorSpec `yaml:"background"`
+	Bold       bool      `yaml:"bold"`
+	Italic     bool      `yaml:"italic"`
+	Faint      bool      `yaml:"faint"`
+	Underline  bool      `yaml:"underline"`
+}
+
+// ColorSpec is a color that may differ between light and dark terminal backgrounds
+// In theme files it is either a single color ("#ff0000", "9") or a {light, dark} pair
+type ColorSpec struct {
+	Light string `yaml:"light"`
+	Dark  string `yaml:"dark"`
+}
+
+// UnmarshalYAML accepts both the scalar and the {light, dark} forms
+func (c *ColorSpec) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		c.Light = value.Value
+		c.Dark = value.Value
+		return nil
+	}
+
+	type plain ColorSpec
+	var pair plain
+	if err := value.Decode(&pair); err != nil {
+		return err
+	}
+	*c = ColorSpec(pair)
+	return nil
+}
+
+// IsZero reports whether no color is set
+func (c ColorSpec) IsZero() bool {
+	return c.Light == "" && c.Dark == ""
+}
+
+// color converts the spec to a lipgloss color, adaptive when light and dark differ
+func (c ColorSpec) color() lipgloss.TerminalColor {
+	if c.Light == c.Dark {
+		return lipgloss.Color(c.Light)
+	}
+	return lipgloss.AdaptiveColor{Light: c.Light, Dark: c.Dark}
+}
+
+// Style builds the lipgloss style for this spec
+// The renderer decides the color profile and which variant adaptive colors use
+func (s StyleSpec) Style(renderer *lipgloss.Renderer) lipgloss.Style {
+	style := renderer.NewStyle().
+		Bold(s.Bold).
+		Italic(s.Italic).
+		Faint(s.Faint).
+		Underline(s.Underline)
+
+	if !s.Foreground.IsZero() {
+		style = style.Foreground(s.Foreground.color())
+	}
+	if !s.Background.IsZero() {
+		style = style.Background(s.Background.color())
+	}
+	return style
+}
+
+// Validate checks that the theme only references known presentation styles
+func (t *Theme) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("theme name cannot be empty")
+	}
+
+	known := make(map[string]bool, len(PresentationStyleNames))
+	for _, name := range PresentationStyleNames {
+		known[name] = true
+	}
+
+	for name := range t.Styles {
+		if !known[name] {
+			return fmt.Errorf("theme %q: unknown style %q (valid: %s)",
+				t.Name, name, strings.Join(PresentationStyleNames, ", "))
+		}
+	}
+
+	classes := make(map[string]bool, len(FileClassNames))
+	for _, name := range FileClassNames {
+		classes[name] = true
+	}
+	for name := range t.Classes {
+		if !classes[name] {
+			return fmt.Errorf("theme %q: unknown file class %q (valid: %s)",
+				t.Name, name, strings.Join(FileClassNames, ", "))
+		}
+	}
+	for name := range t.Extensions {
+		if !classes[name] || FileClass(name) == ClassLarge {
+			return fmt.Errorf("theme %q: extensions for unknown file class %q (valid: code, docs, config, binary)", t.Name, name)
+		}
+	}
+	if _, err := ParseSize(t.LargeFiles); err != nil {
+		return fmt.Errorf("theme %q: large-files: %w", t.Name, err)
+	}
+	return nil
+}
+
+// ColorRules returns the builtin extension groups extended by the theme, with the
+// theme's large file size
+func (t *Theme) ColorRules() (ColorRules, error) {
+	rules := DefaultColorRules()
+	for name, extensions := range t.Extensions {
+		rules.AddExtensions(FileClass(name), extensions)
+	}
+	size, err := ParseSize(t.LargeFiles)
+	if err != nil {
+		return rules, fmt.Errorf("theme %q: large-files: %w", t.Name, err)
+	}
+	rules.LargeSize = size
+	return rules, nil
+}
+
+// BuiltinThemes returns the themes shipped with treex
+func BuiltinThemes() []*Theme {
+	return []*Theme{
+		{
+			Name:        DefaultThemeName,
+			Description: "Plain styling without colors",
+			Styles:      map[string]StyleSpec{},
+			Source:      "builtin",
+		},
+		{
+			Name:        "colorful",
+			Description: "Adaptive colors for light and dark terminals",
+			Styles: map[string]StyleSpec{
+				"strong":   {Bold: true},
+				"weak":     {Foreground: ColorSpec{Light: "245", Dark: "243"}},
+				"active":   {Foreground: ColorSpec{Light: "25", Dark: "75"}, Bold: true},
+				"inactive": {Foreground: ColorSpec{Light: "250", Dark: "240"}},
+				"success":  {Foreground: ColorSpec{Light: "28", Dark: "78"}},
+				"error":    {Foreground: ColorSpec{Light: "160", Dark: "203"}, Bold: true},
+				"warning":  {Foreground: ColorSpec{Light: "130", Dark: "214"}},
+				"info":     {Foreground: ColorSpec{Light: "30", Dark: "116"}},
+				"header":   {Bold: true, Underline: true},
+				"subtle":   {Foreground: ColorSpec{Light: "248", Dark: "239"}},
+			},
+			Classes: map[string]StyleSpec{
+				"code":   {Foreground: ColorSpec{Light: "28", Dark: "114"}},
+				"docs":   {Foreground: ColorSpec{Light: "31", Dark: "117"}},
+				"config": {Foreground: ColorSpec{Light: "136", Dark: "222"}},
+				"binary": {Foreground: ColorSpec{Light: "97", Dark: "176"}},
+				"large":  {Foreground: ColorSpec{Light: "160", Dark: "203"}, Bold: true},
+			},
+			LargeFiles: "10MB",
+			Source:     "builtin",
+		},
+	}
+}
+
+// ParseTheme parses a theme definition from YAML content
+func ParseTheme(content []byte) (*Theme, error) {
+	theme, err := decodeTheme(content)
+	if err != nil {
+		return nil, err
+	}
+	if err := theme.Validate(); err != nil {
+		return nil, err
+	}
+	return theme, nil
+}
+
+// LoadTheme reads and parses a single theme file
+// The theme name defaults to the file name (without extension) when not set in the file
+func LoadTheme(fs afero.Fs, path string) (*Theme, error) {
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme file %s: %w", path, err)
+	}
+
+	theme, err := decodeTheme(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if theme.Name == "" {
+		base := filepath.Base(path)
+		theme.Name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	if err := theme.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	theme.Source = path
+	return theme, nil
+}
+
+// decodeTheme unmarshals theme YAML without validating it
+func decodeTheme(content []byte) (*Theme, error) {
+	var theme Theme
+	if err := yaml.Unmarshal(content, &theme); err != nil {
+		return nil, fmt.Errorf("invalid theme definition: %w", err)
+	}
+	if theme.Styles == nil {
+		theme.Styles = make(map[string]StyleSpec)
+	}
+	return &theme, nil
+}
+
+// LoadThemes loads every *.yaml / *.yml theme in dir
+// A missing directory is not an error. Invalid files are reported in the returned
+// error while the valid themes are still returned.
+func LoadThemes(fs afero.Fs, dir string) ([]*Theme, error) {
+	entries, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read themes directory %s: %w", dir, err)
+	}
+
+	var themes []*Theme
+	var errs []error
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		theme, err := LoadTheme(fs, filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		themes = append(themes, theme)
+	}
+
+	sort.Slice(themes, func(i, j int) bool {
+		return themes[i].Name < themes[j].Name
+	})
+
+	return themes, errors.Join(errs...)
+}
+
+// AvailableThemes returns user themes from dir followed by builtin themes
+// User themes shadow builtin themes with the same name
+func AvailableThemes(fs afero.Fs, dir string) ([]*Theme, error) {
+	userThemes, err := LoadThemes(fs, dir)
+
+	themes := make([]*Theme, 0, len(userThemes)+2)
+	seen := make(map[string]bool)
+	for _, theme := range userThemes {
+		themes = append(themes, theme)
+		seen[theme.Name] = true
+	}
+	for _, theme := range BuiltinThemes() {
+		if !seen[theme.Name] {
+			themes = append(themes, theme)
+		}
+	}
+
+	return themes, err
+}
+
+// FindTheme looks up a theme by name among user themes in dir and builtin themes
+func FindTheme(fs afero.Fs, dir, name string) (*Theme, error) {
+	themes, loadErr := AvailableThemes(fs, dir)
+	for _, theme := range themes {
+		if theme.Name == name {
+			return theme, nil
+		}
+	}
+
+	if loadErr != nil {
+		return nil, fmt.Errorf("theme %q not found (some theme files failed to load: %w)", name, loadErr)
+	}
+	return nil, fmt.Errorf("theme %q not found", name)
+}
+
+// DefaultThemesDir returns the user themes directory using the XDG config directory
+func DefaultThemesDir() string {
+	return pathutil.ConfigPath("themes")
+}
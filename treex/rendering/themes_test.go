@@ -0,0 +1,157 @@
+package rendering_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/rendering"
+)
+
+func TestParseTheme(t *testing.T) {
+	theme, err := rendering.ParseTheme([]byte(`
+name: ocean
+description: Blue tones
+styles:
+  strong: { foreground: "#268bd2", bold: true }
+  weak:
+    foreground: { light: "#93a1a1", dark: "#586e75" }
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "ocean", theme.Name)
+	assert.Equal(t, "Blue tones", theme.Description)
+	assert.Equal(t, rendering.ColorSpec{Light: "#268bd2", Dark: "#268bd2"}, theme.Styles["strong"].Foreground)
+	assert.True(t, theme.Styles["strong"].Bold)
+	assert.Equal(t, rendering.ColorSpec{Light: "#93a1a1", Dark: "#586e75"}, theme.Styles["weak"].Foreground)
+}
+
+func TestParseThemeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errText string
+	}{
+		{"missing name", "styles: {}", "name cannot be empty"},
+		{"unknown style", "name: x\nstyles:\n  sparkly: { bold: true }", "unknown style"},
+		{"invalid yaml", "name: [unclosed", "invalid theme definition"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := rendering.ParseTheme([]byte(tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errText)
+		})
+	}
+}
+
+func TestLoadThemes(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/config/themes", map[string]interface{}{
+		"ocean.yaml":  "description: Blue tones\nstyles:\n  info: { foreground: \"33\" }\n",
+		"forest.yml":  "name: woods\nstyles:\n  info: { foreground: \"28\" }\n",
+		"broken.yaml": "styles:\n  nope: { bold: true }\n",
+		"notes.txt":   "not a theme",
+	})
+
+	themes, err := rendering.LoadThemes(fs, "/config/themes")
+
+	// Broken files are reported but valid themes still load
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken.yaml")
+
+	names := make([]string, len(themes))
+	for i, theme := range themes {
+		names[i] = theme.Name
+	}
+	assert.Equal(t, []string{"ocean", "woods"}, names, "name defaults to file name, sorted by name")
+	assert.Equal(t, "/config/themes/ocean.yaml", themes[0].Source)
+}
+
+func TestLoadThemesMissingDirectory(t *testing.T) {
+	fs := testutil.NewTestFS()
+
+	themes, err := rendering.LoadThemes(fs, "/does/not/exist")
+	assert.NoError(t, err)
+	assert.Empty(t, themes)
+}
+
+func TestFindTheme(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/themes", map[string]interface{}{
+		"colorful.yaml": "description: My override\n",
+	})
+
+	t.Run("user themes shadow builtin themes", func(t *testing.T) {
+		theme, err := rendering.FindTheme(fs, "/themes", "colorful")
+		require.NoError(t, err)
+		assert.Equal(t, "My override", theme.Description)
+	})
+
+	t.Run("builtin themes are available", func(t *testing.T) {
+		theme, err := rendering.FindTheme(fs, "/themes", rendering.DefaultThemeName)
+		require.NoError(t, err)
+		assert.Equal(t, "builtin", theme.Source)
+	})
+
+	t.Run("unknown theme is an error", func(t *testing.T) {
+		_, err := rendering.FindTheme(fs, "/themes", "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
+func TestStyleManagerWithTheme(t *testing.T) {
+	theme, err := rendering.ParseTheme([]byte("name: bold\nstyles:\n  strong: { bold: true }\n"))
+	require.NoError(t, err)
+
+	t.Run("colors disabled ignores the theme", func(t *testing.T) {
+		styles := rendering.NewStyleManagerWithTheme(false, theme)
+		assert.Equal(t, "text", styles.StatsValue("text"))
+		assert.Equal(t, theme, styles.Theme())
+	})
+
+	t.Run("nil theme uses default", func(t *testing.T) {
+		styles := rendering.NewStyleManagerWithTheme(true, nil)
+		assert.Equal(t, rendering.DefaultThemeName, styles.Theme().Name)
+	})
+}
+
+func TestParseThemeFileClasses(t *testing.T) {
+	theme, err := rendering.ParseTheme([]byte(`
+name: classy
+classes:
+  code: { foreground: "#859900" }
+  large: { bold: true }
+extensions:
+  code: [zig, .odin]
+large-files: 1MB
+`))
+	require.NoError(t, err)
+	assert.True(t, theme.Classes["large"].Bold)
+
+	rules, err := theme.ColorRules()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1<<20), rules.LargeSize)
+	assert.Equal(t, rendering.ClassCode, rules.Class("main.odin", false, 0))
+	assert.Equal(t, rendering.ClassLarge, rules.Class("main.odin", false, 1<<20))
+
+	tests := []struct {
+		name    string
+		content string
+		errText string
+	}{
+		{"unknown class", "name: x\nclasses:\n  shiny: { bold: true }", "unknown file class"},
+		{"extensions for large", "name: x\nextensions:\n  large: [iso]", "extensions for unknown file class"},
+		{"invalid size", "name: x\nlarge-files: huge", "large-files"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := rendering.ParseTheme([]byte(tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errText)
+		})
+	}
+}
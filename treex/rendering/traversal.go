@@ -0,0 +1,41 @@
+package rendering
+
+import (
+	"fmt"
+	"strings"
+
+	"treex/treex/types"
+)
+
+// Traversal is the order data formats list entries in
+type Traversal string
+
+const (
+	TraversalDFS Traversal = "dfs" // Depth-first, as the tree is printed (the default)
+	TraversalBFS Traversal = "bfs" // Breadth-first: level by level, shallowest first
+)
+
+// ParseTraversal validates a --traversal value; an empty value means depth-first
+func ParseTraversal(value string) (Traversal, error) {
+	switch traversal := Traversal(strings.ToLower(strings.TrimSpace(value))); traversal {
+	case "":
+		return TraversalDFS, nil
+	case TraversalDFS, TraversalBFS:
+		return traversal, nil
+	}
+	return "", fmt.Errorf("unknown traversal %q (valid: dfs, bfs)", value)
+}
+
+// walkEntries calls visit for the nodes below root, root excluded, in traversal order
+func walkEntries(root *types.Node, traversal Traversal, visit func(node *types.Node)) {
+	walk := types.WalkTree
+	if traversal == TraversalBFS {
+		walk = types.WalkTreeBFS
+	}
+	_ = walk(root, func(node *types.Node, depth int) error {
+		if depth > 0 {
+			visit(node)
+		}
+		return nil
+	})
+}
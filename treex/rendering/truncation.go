@@ -0,0 +1,50 @@
+package rendering
+
+import (
+	"fmt"
+
+	"treex/treex/types"
+)
+
+// visibleChildren returns the children to render and a summary of hidden files
+// Files beyond maxFiles (counted per directory, in order) are hidden; 0 shows all.
+// Directories are always listed.
+func visibleChildren(node *types.Node, maxFiles int) ([]*types.Node, subtreeSummary) {
+	if maxFiles <= 0 {
+		return node.Children, subtreeSummary{}
+	}
+
+	var visible []*types.Node
+	var hidden subtreeSummary
+	files := 0
+	for _, child := range node.Children {
+		if child.IsDir {
+			visible = append(visible, child)
+			continue
+		}
+
+		files++
+		if files <= maxFiles {
+			visible = append(visible, child)
+			continue
+		}
+
+		hidden.Files++
+		if annotation := child.GetAnnotation(); annotation != nil && annotation.Notes != "" {
+			hidden.Annotated++
+		}
+	}
+	return visible, hidden
+}
+
+// hiddenFilesIndicator formats the line shown in place of hidden files
+func hiddenFilesIndicator(hidden subtreeSummary) string {
+	files := "files"
+	if hidden.Files == 1 {
+		files = "file"
+	}
+	if hidden.Annotated == 0 {
+		return fmt.Sprintf("… %d more %s not shown", hidden.Files, files)
+	}
+	return fmt.Sprintf("… %d more %s not shown (%d annotated)", hidden.Files, files, hidden.Annotated)
+}
@@ -0,0 +1,103 @@
+package rendering_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+func renderMaxFiles(t *testing.T, root *types.Node, maxFiles int) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format:         rendering.FormatPlain,
+		Writer:         &buf,
+		Charset:        rendering.CharsetASCII,
+		Width:          -1,
+		MaxFilesPerDir: maxFiles,
+	})
+	require.NoError(t, renderer.RenderTree(&treex.TreeResult{Root: root}))
+	return buf.String()
+}
+
+// wideTree builds project/{docs/, file1.txt ... file5.txt} with file4.txt and file5.txt annotated
+func wideTree() *types.Node {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	addChildren(root, &types.Node{Name: "docs", Path: "docs", IsDir: true})
+	for i := 1; i <= 5; i++ {
+		name := fmt.Sprintf("file%d.txt", i)
+		file := &types.Node{Name: name, Path: name}
+		if i >= 4 {
+			file.SetAnnotation(&types.Annotation{Path: name, Notes: "Annotated"})
+		}
+		addChildren(root, file)
+	}
+	return root
+}
+
+func TestMaxFilesPerDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxFiles int
+		expected string
+	}{
+		{
+			name:     "limit hides trailing files and reports annotated ones",
+			maxFiles: 2,
+			expected: "project\n" +
+				"+- docs\n" +
+				"+- file1.txt\n" +
+				"+- file2.txt\n" +
+				"\\- … 3 more files not shown (2 annotated)\n",
+		},
+		{
+			name:     "single hidden file",
+			maxFiles: 4,
+			expected: "project\n" +
+				"+- docs\n" +
+				"+- file1.txt\n" +
+				"+- file2.txt\n" +
+				"+- file3.txt\n" +
+				"+- file4.txt\n" +
+				"\\- … 1 more file not shown (1 annotated)\n",
+		},
+		{
+			name:     "limit not reached",
+			maxFiles: 5,
+			expected: "project\n" +
+				"+- docs\n" +
+				"+- file1.txt\n" +
+				"+- file2.txt\n" +
+				"+- file3.txt\n" +
+				"+- file4.txt\n" +
+				"\\- file5.txt\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, renderMaxFiles(t, wideTree(), tt.maxFiles))
+		})
+	}
+}
+
+func TestMaxFilesPerDirKeepsDirectories(t *testing.T) {
+	output := renderMaxFiles(t, deepTree(), 1)
+
+	expected := "project\n" +
+		"+- internal\n" +
+		"|  +- api\n" +
+		"|  |  +- handler.go\n" +
+		"|  |  \\- … 1 more file not shown\n" +
+		"|  \\- db\n" +
+		"|     \\- schema.sql\n" +
+		"\\- main.go\n"
+	assert.Equal(t, expected, output)
+}
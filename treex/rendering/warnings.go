@@ -0,0 +1,58 @@
+package rendering
+
+import (
+	"fmt"
+
+	"treex/treex"
+	"treex/treex/i18n"
+	"treex/treex/types"
+)
+
+// treeWarnings lists the directories of result whose contents could not be read, in tree
+// order; entries of combined results carry the name of their root
+func treeWarnings(result *treex.TreeResult) []map[string]interface{} {
+	warnings := []map[string]interface{}{}
+	if result.Roots == nil {
+		collectWarnings(result.Root, "", &warnings)
+		return warnings
+	}
+	for _, root := range result.Roots {
+		if root.Root != nil {
+			collectWarnings(root.Root, root.Root.Name, &warnings)
+		}
+	}
+	return warnings
+}
+
+// collectWarnings appends a warning for node and each node below it with an error
+func collectWarnings(node *types.Node, rootName string, warnings *[]map[string]interface{}) {
+	if node == nil {
+		return
+	}
+	if node.Error != "" {
+		warning := map[string]interface{}{"path": node.Path, "error": node.Error}
+		if rootName != "" {
+			warning["root"] = rootName
+		}
+		*warnings = append(*warnings, warning)
+	}
+	for _, child := range node.Children {
+		collectWarnings(child, rootName, warnings)
+	}
+}
+
+// renderDroppedAnnotations lists the dropped annotations after the tree, one
+// "file:line: path: reason" line each, as treex check reports them
+func (r *Renderer) renderDroppedAnnotations() error {
+	dropped := r.config.DroppedAnnotations
+	header := i18n.Plural(len(dropped), "%d annotation not shown:", "%d annotations not shown:")
+	if _, err := fmt.Fprintf(r.config.Writer, "\n%s\n", r.styles.WarningMessage(header)); err != nil {
+		return err
+	}
+	for _, issue := range dropped {
+		if _, err := fmt.Fprintf(r.config.Writer, "  %s:%d: %s: %s\n", issue.InfoFile, issue.Line, issue.Path, issue.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package rendering_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex"
+	"treex/treex/plugins/infofile"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+// unreadableResult has a secrets directory that could not be listed
+func unreadableResult() *treex.TreeResult {
+	root := &types.Node{Name: ".", Path: ".", IsDir: true}
+	root.Children = []*types.Node{
+		{Name: "secrets", Path: "secrets", IsDir: true, Error: "permission denied", Parent: root},
+		{Name: "main.go", Path: "main.go", Parent: root},
+	}
+	return &treex.TreeResult{Root: root, Stats: treex.TreeStats{TotalFiles: 1, TotalDirectories: 2, Errors: 1}}
+}
+
+func TestRenderErrorMarkers(t *testing.T) {
+	for _, showErrors := range []bool{true, false} {
+		var buf bytes.Buffer
+		renderer := rendering.NewRenderer(rendering.RenderConfig{
+			Format: rendering.FormatPlain, Writer: &buf, Charset: rendering.CharsetUnicode, ShowErrors: showErrors,
+		})
+		require.NoError(t, renderer.RenderTree(unreadableResult()))
+
+		if showErrors {
+			assert.Equal(t, ".\n├─ secrets [permission denied]\n└─ main.go\n", buf.String())
+		} else {
+			assert.Equal(t, ".\n├─ secrets\n└─ main.go\n", buf.String())
+		}
+	}
+}
+
+func TestRenderJSONWarnings(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatJSON, Writer: &buf, ShowErrors: true})
+	require.NoError(t, renderer.RenderTree(unreadableResult()))
+
+	var output struct {
+		Tree struct {
+			Children []map[string]interface{} `json:"children"`
+		} `json:"tree"`
+		Stats    treex.TreeStats     `json:"stats"`
+		Warnings []map[string]string `json:"warnings"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+	assert.Equal(t, []map[string]string{{"path": "secrets", "error": "permission denied"}}, output.Warnings)
+	assert.Equal(t, "permission denied", output.Tree.Children[0]["error"])
+	assert.Equal(t, 1, output.Stats.Errors)
+}
+
+func TestRenderDroppedAnnotations(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format: rendering.FormatPlain, Writer: &buf, Charset: rendering.CharsetUnicode, Summary: true,
+		DroppedAnnotations: []infofile.Issue{
+			{InfoFile: ".info", Line: 3, Path: "old.go", Message: "annotated path does not exist", Type: infofile.IssueMissingPath},
+			{InfoFile: "src/.info", Line: 1, Path: "src/util.go", Message: "annotation has no text", Type: infofile.IssueNoText},
+		},
+	})
+	require.NoError(t, renderer.RenderTree(rootResult("main.go")))
+
+	assert.Contains(t, buf.String(), "1 file\n\n2 annotations not shown:\n"+
+		"  .info:3: old.go: annotated path does not exist\n"+
+		"  src/.info:1: src/util.go: annotation has no text\n", "the list follows the summary")
+}
+
+func TestRenderDroppedAnnotationsOnlyInText(t *testing.T) {
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format: rendering.FormatJSON, Writer: &buf,
+		DroppedAnnotations: []infofile.Issue{{InfoFile: ".info", Line: 3, Path: "old.go", Message: "annotated path does not exist"}},
+	})
+	require.NoError(t, renderer.RenderTree(rootResult("main.go")))
+	assert.NotContains(t, buf.String(), "not shown")
+}
@@ -0,0 +1,34 @@
+package treex
+
+import "treex/treex/types"
+
+// CombineResults puts the trees of several roots under one synthetic root node named
+// label, so they render as a single tree
+//
+// Each root node is renamed to the matching entry of names (typically the path as given
+// on the command line) and becomes a child of the synthetic root; the paths of its nodes
+// stay relative to its own root. Stats and build times are summed, and the combined result is Partial when
+// any root is. Empty results are kept in Roots but add no child.
+func CombineResults(label string, names []string, results []*TreeResult) *TreeResult {
+	root := &types.Node{Name: label, Path: ".", IsDir: true}
+	combined := &TreeResult{Root: root, Roots: results}
+
+	for i, result := range results {
+		combined.Partial = combined.Partial || result.Partial
+		combined.Stats.TotalFiles += result.Stats.TotalFiles
+		combined.Stats.TotalDirectories += result.Stats.TotalDirectories
+		combined.Stats.FilteredOut += result.Stats.FilteredOut
+		combined.Stats.Errors += result.Stats.Errors
+		combined.Stats.Annotated += result.Stats.Annotated
+		combined.Elapsed += result.Elapsed
+		combined.Stats.MaxDepthReached = max(combined.Stats.MaxDepthReached, result.Stats.MaxDepthReached)
+
+		if result.Root == nil {
+			continue
+		}
+		result.Root.Name = names[i]
+		result.Root.Parent = root
+		root.Children = append(root.Children, result.Root)
+	}
+	return combined
+}
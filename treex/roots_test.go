@@ -0,0 +1,46 @@
+package treex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/types"
+)
+
+func TestCombineResults(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/work", map[string]interface{}{
+		"api": map[string]interface{}{".info": "main.go  Server\n", "main.go": "package main"},
+		"web": map[string]interface{}{"src": map[string]interface{}{"app.ts": ""}},
+	})
+
+	var results []*TreeResult
+	for _, root := range []string{"/work/api", "/work/web"} {
+		config := DefaultTreeConfig(root)
+		config.Filesystem = fs
+		result, err := BuildTree(config)
+		require.NoError(t, err)
+		results = append(results, result)
+	}
+	results = append(results, &TreeResult{Partial: true})
+
+	combined := CombineResults("3 roots", []string{"api", "web", "empty"}, results)
+	assert.Equal(t, "3 roots", combined.Root.Name)
+	assert.Equal(t, results, combined.Roots)
+	assert.True(t, combined.Partial)
+	assert.Equal(t, TreeStats{TotalFiles: 3, TotalDirectories: 3, MaxDepthReached: 2, Annotated: 1}, combined.Stats)
+
+	require.Len(t, combined.Root.Children, 2, "empty results add no child")
+	api, web := combined.Root.Children[0], combined.Root.Children[1]
+	assert.Equal(t, "api", api.Name)
+	assert.Same(t, combined.Root, api.Parent)
+	assert.Equal(t, "web", web.Name)
+
+	// Paths stay relative to each root, so annotations still resolve
+	var paths []string
+	walkTree(web, func(node *types.Node) { paths = append(paths, node.Path) })
+	assert.Equal(t, []string{".", "src", "src/app.ts"}, paths)
+	assert.Equal(t, "Server", api.Children[1].GetAnnotation().Notes)
+}
@@ -0,0 +1,37 @@
+// Package schema publishes the JSON Schemas of treex's machine-readable outputs, the
+// contract downstream tools code against, and checks documents against them.
+//
+//	tree        treex --format json (single tree, several roots, or a page)
+//	validation  treex check --format json, GET /validate of treex serve
+//	stats       treex stats --json
+//
+// Validate implements the subset of JSON Schema (draft 2020-12) the published schemas
+// use, so outputs can be checked in tests without a third-party validator.
+package schema
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+)
+
+//go:embed *.json
+var files embed.FS
+
+// names lists the published schemas in display order
+var names = []string{"tree", "validation", "stats"}
+
+// Names returns the names of the published schemas
+func Names() []string {
+	return append([]string(nil), names...)
+}
+
+// Get returns the JSON Schema document published as name
+func Get(name string) ([]byte, error) {
+	for _, known := range names {
+		if known == name {
+			return files.ReadFile(name + ".json")
+		}
+	}
+	return nil, fmt.Errorf("unknown schema %q (valid: %s)", name, strings.Join(names, ", "))
+}
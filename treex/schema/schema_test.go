@@ -0,0 +1,49 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex/schema"
+)
+
+func TestPublishedSchemasAreJSON(t *testing.T) {
+	for _, name := range schema.Names() {
+		content, err := schema.Get(name)
+		require.NoError(t, err, name)
+		var document map[string]interface{}
+		require.NoError(t, json.Unmarshal(content, &document), name)
+		assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", document["$schema"], name)
+	}
+
+	_, err := schema.Get("nope")
+	assert.ErrorContains(t, err, "valid: tree, validation, stats")
+}
+
+func TestValidate(t *testing.T) {
+	valid := `{"valid": false, "issues": [{"infoFile": ".info", "line": 2, "path": "a.go", "message": "gone", "type": "missing-path"}]}`
+	require.NoError(t, schema.Validate("validation", []byte(valid)))
+
+	for document, message := range map[string]string{
+		`{"issues": []}`:                            `$: missing required property "valid"`,
+		`{"valid": "yes", "issues": []}`:            `$.valid: expected boolean, got string`,
+		`{"valid": true, "issues": [], "extra": 1}`: `$.extra: unexpected property`,
+		`{"valid": false, "issues": [{"infoFile": ".info", "line": 1.5, "path": "a", "message": "m"}]}`:              `$.issues[0].line: expected integer, got number`,
+		`{"valid": false, "issues": [{"infoFile": ".info", "line": -1, "path": "a", "message": "m"}]}`:               `$.issues[0].line: -1 is below the minimum 0`,
+		`{"valid": false, "issues": [{"infoFile": ".info", "line": 1, "path": "a", "message": "m", "type": "odd"}]}`: `$.issues[0].type: odd is not one of`,
+		`not json`: `invalid JSON`,
+	} {
+		err := schema.Validate("validation", []byte(document))
+		require.Error(t, err, document)
+		assert.Contains(t, err.Error(), message, document)
+	}
+
+	tree := `{"tree": {"name": ".", "path": ".", "isDir": true, "size": 0, "children": [{"name": "a", "path": "a", "isDir": false}]},
+		"stats": {"TotalFiles": 1, "TotalDirectories": 1, "MaxDepthReached": 1, "FilteredOut": 0, "Errors": 0, "Annotated": 0}}`
+	err := schema.Validate("tree", []byte(tree))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "matches none of the allowed shapes")
+	assert.Contains(t, err.Error(), `$.tree.children[0]: missing required property "size"`)
+}
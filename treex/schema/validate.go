@@ -0,0 +1,235 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Validate checks a JSON document against the schema published as name
+// The error names the first location that does not conform, as a JSON path ("$.tree.children[0]").
+func Validate(name string, document []byte) error {
+	content, err := Get(name)
+	if err != nil {
+		return err
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(content, &root); err != nil {
+		return fmt.Errorf("invalid schema %s: %w", name, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return (&validator{root: root}).check(root, value, "$")
+}
+
+// validator checks values against schemas that may refer to the definitions of root
+type validator struct {
+	root map[string]interface{}
+}
+
+// check validates value (decoded with json.Number) at location against schema
+func (v *validator) check(schema map[string]interface{}, value interface{}, location string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := v.resolve(ref)
+		if err != nil {
+			return err
+		}
+		return v.check(target, value, location)
+	}
+
+	if types, ok := schema["type"]; ok && !matchesType(types, value) {
+		return fmt.Errorf("%s: expected %v, got %s", location, types, typeName(value))
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && !inEnum(enum, value) {
+		return fmt.Errorf("%s: %v is not one of %v", location, value, enum)
+	}
+	if minimum, ok := schema["minimum"].(float64); ok {
+		if number, isNumber := value.(json.Number); isNumber {
+			if f, err := number.Float64(); err == nil && f < minimum {
+				return fmt.Errorf("%s: %v is below the minimum %v", location, number, minimum)
+			}
+		}
+	}
+
+	if alternatives, ok := schema["anyOf"].([]interface{}); ok {
+		if matched, firstErr := v.matching(alternatives, value, location); matched == 0 {
+			return fmt.Errorf("%s: matches none of the allowed shapes (first: %w)", location, firstErr)
+		}
+	}
+	if alternatives, ok := schema["oneOf"].([]interface{}); ok {
+		matched, firstErr := v.matching(alternatives, value, location)
+		if matched == 0 {
+			return fmt.Errorf("%s: matches none of the allowed shapes (first: %w)", location, firstErr)
+		}
+		if matched > 1 {
+			return fmt.Errorf("%s: matches %d shapes, expected exactly one", location, matched)
+		}
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		return v.checkObject(schema, typed, location)
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range typed {
+				if err := v.check(items, item, fmt.Sprintf("%s[%d]", location, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkObject validates required, properties and additionalProperties
+func (v *validator) checkObject(schema, object map[string]interface{}, location string) error {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, key := range required {
+			if _, present := object[key.(string)]; !present {
+				return fmt.Errorf("%s: missing required property %q", location, key)
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		child := location + "." + key
+		if property, ok := properties[key].(map[string]interface{}); ok {
+			if err := v.check(property, object[key], child); err != nil {
+				return err
+			}
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				return fmt.Errorf("%s: unexpected property", child)
+			}
+		case map[string]interface{}:
+			if err := v.check(additional, object[key], child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matching counts the alternatives value conforms to, returning the first failure
+func (v *validator) matching(alternatives []interface{}, value interface{}, location string) (int, error) {
+	matched := 0
+	var firstErr error
+	for _, alternative := range alternatives {
+		schema, ok := alternative.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := v.check(schema, value, location); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		matched++
+	}
+	return matched, firstErr
+}
+
+// resolve finds a local reference such as "#/$defs/node"
+func (v *validator) resolve(ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported schema reference %q", ref)
+	}
+	var current interface{} = v.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolved schema reference %q", ref)
+		}
+		current = object[part]
+	}
+	target, ok := current.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unresolved schema reference %q", ref)
+	}
+	return target, nil
+}
+
+// matchesType reports whether value has the JSON type named by types (a name or list)
+func matchesType(types interface{}, value interface{}) bool {
+	switch typed := types.(type) {
+	case string:
+		return typeMatches(typed, value)
+	case []interface{}:
+		for _, name := range typed {
+			if name, ok := name.(string); ok && typeMatches(name, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func typeMatches(name string, value interface{}) bool {
+	actual := typeName(value)
+	switch {
+	case name == actual:
+		return true
+	case name == "number" && actual == "integer":
+		return true
+	}
+	return false
+}
+
+// typeName is the JSON Schema type of a decoded value
+func typeName(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := typed.Float64(); err == nil && f == math.Trunc(f) && !strings.ContainsAny(typed.String(), ".eE") {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// inEnum reports whether value equals one of the enumerated values
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		switch allowed := allowed.(type) {
+		case float64:
+			if number, ok := value.(json.Number); ok {
+				if f, err := number.Float64(); err == nil && f == allowed {
+					return true
+				}
+			}
+		default:
+			if allowed == value {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+// Package secrets keeps annotations that should not be readable in the repository, such
+// as notes on infrastructure hosts. The .info entry only marks the path:
+//
+//	deploy/hosts.txt  @secret
+//
+// and the text lives in an encrypted sidecar, .info.age, next to it: a .info file
+// encrypted with age (https://age-encryption.org) whose entries annotate paths relative
+// to its directory. Marked annotations are shown as Redacted unless a Decrypter can read
+// the sidecar; the .info files themselves are never changed.
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"treex/treex/plugins/infofile"
+	"treex/treex/types"
+)
+
+const (
+	// Marker is the annotation text of an entry whose annotation is secret
+	Marker = "@secret"
+	// SidecarName is the encrypted file holding the secret annotations of a directory
+	SidecarName = ".info.age"
+	// Redacted is shown in place of a secret annotation that cannot be decrypted
+	Redacted = "[secret]"
+)
+
+// Decrypter returns the plaintext of an encrypted sidecar
+type Decrypter func(ciphertext []byte) ([]byte, error)
+
+// AgeDecrypter decrypts sidecars with the age command and the identity (private key)
+// file identityFile
+func AgeDecrypter(identityFile string) Decrypter {
+	return func(ciphertext []byte) ([]byte, error) {
+		command := exec.Command("age", "--decrypt", "--identity", identityFile)
+		command.Stdin = bytes.NewReader(ciphertext)
+		var stderr bytes.Buffer
+		command.Stderr = &stderr
+		plaintext, err := command.Output()
+		if err != nil {
+			if message := strings.TrimSpace(stderr.String()); message != "" {
+				return nil, fmt.Errorf("age: %s", message)
+			}
+			return nil, fmt.Errorf("age: %w", err)
+		}
+		return plaintext, nil
+	}
+}
+
+// IdentityFile returns the age identity file named by TREEX_AGE_KEY_FILE or, as sops
+// reads it, SOPS_AGE_KEY_FILE; "" when neither is set
+func IdentityFile(getenv func(string) string) string {
+	for _, name := range []string{"TREEX_AGE_KEY_FILE", "SOPS_AGE_KEY_FILE"} {
+		if file := getenv(name); file != "" {
+			return file
+		}
+	}
+	return ""
+}
+
+// Tree replaces the marked annotations below and including node, whose paths are relative
+// to root, with their text from the sidecars below root, decrypted with decrypt. Without
+// decrypt, or when a sidecar cannot be read or lacks the path, Redacted is shown instead.
+// Sidecars are only read when the tree holds a marked annotation.
+func Tree(fs afero.Fs, root string, node *types.Node, decrypt Decrypter) {
+	var marked []*types.Node
+	var walk func(node *types.Node)
+	walk = func(node *types.Node) {
+		if annotation := node.GetAnnotation(); annotation != nil && annotation.Notes == Marker {
+			marked = append(marked, node)
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	if node != nil {
+		walk(node)
+	}
+	if len(marked) == 0 {
+		return
+	}
+
+	var revealed map[string]infofile.Annotation
+	if decrypt != nil {
+		revealed = readSidecars(fs, root, marked, decrypt)
+	}
+	base := filepath.ToSlash(root)
+	for _, node := range marked {
+		text := Redacted
+		if annotation, ok := revealed[path.Join(base, node.Path)]; ok {
+			text = annotation.Notes
+		}
+		node.GetAnnotation().Notes = text
+	}
+}
+
+// readSidecars merges the sidecars below root by the rules of .info files (see
+// infofile.Gather), decrypting only those whose directory holds a marked node
+func readSidecars(fs afero.Fs, root string, marked []*types.Node, decrypt Decrypter) map[string]infofile.Annotation {
+	needed := map[string]bool{".": true}
+	for _, node := range marked {
+		for dir := path.Dir(node.Path); dir != "."; dir = path.Dir(dir) {
+			needed[dir] = true
+		}
+	}
+
+	revealed, err := infofile.GatherSidecars(fs, root, infofile.Sidecar{
+		Name: SidecarName,
+		Read: func(filePath string, ciphertext []byte) ([]byte, error) {
+			dir, err := filepath.Rel(root, filepath.Dir(filePath))
+			if err != nil || !needed[filepath.ToSlash(dir)] {
+				return nil, err
+			}
+			plaintext, err := decrypt(ciphertext)
+			if err != nil {
+				return nil, fmt.Errorf("leaving secret annotations redacted: %w", err)
+			}
+			return plaintext, nil
+		},
+	})
+	if err != nil {
+		slog.Warn("cannot read secret annotations", "root", root, "error", err)
+	}
+	return revealed
+}
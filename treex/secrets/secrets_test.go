@@ -0,0 +1,102 @@
+package secrets_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"treex/internal/testutil"
+	"treex/treex/secrets"
+	"treex/treex/types"
+)
+
+// annotated returns a node with an annotation
+func annotated(path, notes string) *types.Node {
+	node := &types.Node{Path: path}
+	node.SetAnnotation(&types.Annotation{Path: path, Notes: notes})
+	return node
+}
+
+// fakeDecrypt "decrypts" content prefixed with "age:" and rejects anything else
+func fakeDecrypt(ciphertext []byte) ([]byte, error) {
+	if plaintext, ok := bytes.CutPrefix(ciphertext, []byte("age:")); ok {
+		return plaintext, nil
+	}
+	return nil, errors.New("no identity matched")
+}
+
+func newProject() (*testutil.TestFS, *types.Node) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info.age": "age:deploy/hosts.txt  Shared host list\nREADME.md  Not marked\n",
+		"deploy": map[string]interface{}{
+			".info.age": "age:hosts.txt  Bastion at 10.0.0.4\n",
+			"hosts.txt": "",
+			"keys.txt":  "",
+		},
+		"legacy": map[string]interface{}{
+			".info.age": "plain text",
+			"db.txt":    "",
+		},
+		"public": map[string]interface{}{
+			".info.age": "age:site.txt  Nothing marked here\n",
+			"site.txt":  "",
+		},
+	})
+	root := &types.Node{Path: ".", IsDir: true}
+	root.Children = []*types.Node{
+		annotated("README.md", "Overview"),
+		annotated("deploy/hosts.txt", secrets.Marker),
+		annotated("deploy/keys.txt", secrets.Marker),
+		annotated("legacy/db.txt", secrets.Marker),
+	}
+	return fs, root
+}
+
+func TestTree(t *testing.T) {
+	fs, root := newProject()
+	secrets.Tree(fs, "/project", root, fakeDecrypt)
+
+	notes := make(map[string]string)
+	for _, child := range root.Children {
+		notes[child.Path] = child.GetAnnotation().Notes
+	}
+	assert.Equal(t, map[string]string{
+		"README.md":        "Overview",
+		"deploy/hosts.txt": "Bastion at 10.0.0.4", // The closest sidecar wins
+		"deploy/keys.txt":  secrets.Redacted,      // Marked but in no sidecar
+		"legacy/db.txt":    secrets.Redacted,      // Sidecar cannot be decrypted
+	}, notes)
+}
+
+func TestTreeDecryptsOnlyNeededSidecars(t *testing.T) {
+	fs, root := newProject()
+	var decrypted int
+	secrets.Tree(fs, "/project", root, func(ciphertext []byte) ([]byte, error) {
+		decrypted++
+		return fakeDecrypt(ciphertext)
+	})
+	assert.Equal(t, 3, decrypted, "the sidecar of public/, with no marked path, is not decrypted")
+}
+
+func TestTreeWithoutKey(t *testing.T) {
+	fs, root := newProject()
+	secrets.Tree(fs, "/project", root, nil)
+
+	assert.Equal(t, "Overview", root.Children[0].GetAnnotation().Notes)
+	for _, child := range root.Children[1:] {
+		assert.Equal(t, secrets.Redacted, child.GetAnnotation().Notes, child.Path)
+	}
+}
+
+func TestIdentityFile(t *testing.T) {
+	env := map[string]string{"SOPS_AGE_KEY_FILE": "/keys/sops.txt"}
+	getenv := func(name string) string { return env[name] }
+	assert.Equal(t, "/keys/sops.txt", secrets.IdentityFile(getenv))
+
+	env["TREEX_AGE_KEY_FILE"] = "/keys/treex.txt"
+	assert.Equal(t, "/keys/treex.txt", secrets.IdentityFile(getenv))
+
+	assert.Empty(t, secrets.IdentityFile(func(string) string { return "" }))
+}
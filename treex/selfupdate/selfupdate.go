@@ -0,0 +1,385 @@
+// Package selfupdate finds the latest treex release on GitHub and installs it over the
+// running binary. Releases publish a checksums.txt listing the SHA-256 of every archive;
+// the archive is checked against it before anything is replaced. Releases are not
+// signed, so the checksum only proves the archive is the one the release lists.
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// DefaultAPI is the GitHub API the releases are read from
+const DefaultAPI = "https://api.github.com"
+
+// DefaultRepository is the GitHub repository treex is released from
+const DefaultRepository = "arthur-debert/treex"
+
+// ChecksumsAsset is the release asset listing the SHA-256 of every archive
+const ChecksumsAsset = "checksums.txt"
+
+// maxArchiveSize bounds downloads; release archives are a few megabytes
+const maxArchiveSize = 200 << 20
+
+// Release is a published GitHub release
+type Release struct {
+	Tag    string  `json:"tag_name"`
+	URL    string  `json:"html_url"`
+	Assets []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// asset returns the release asset called name
+func (r *Release) asset(name string) (Asset, error) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, nil
+		}
+	}
+	return Asset{}, fmt.Errorf("release %s has no %s", r.Tag, name)
+}
+
+// Updater downloads releases of a repository for one platform
+type Updater struct {
+	HTTP       *http.Client // nil uses http.DefaultClient
+	API        string       // GitHub API base URL ("" = DefaultAPI)
+	Repository string       // owner/name ("" = DefaultRepository)
+	Token      string       // Optional GitHub token, raising the API rate limit
+	GOOS       string       // Platform of the archive to install
+	GOARCH     string
+}
+
+// Latest returns the latest release (drafts and pre-releases are never latest)
+func (u *Updater) Latest(ctx context.Context) (*Release, error) {
+	api, repository := u.API, u.Repository
+	if api == "" {
+		api = DefaultAPI
+	}
+	if repository == "" {
+		repository = DefaultRepository
+	}
+
+	body, err := u.get(ctx, strings.TrimSuffix(api, "/")+"/repos/"+repository+"/releases/latest", 1<<20)
+	if err != nil {
+		return nil, err
+	}
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("invalid release from %s: %w", api, err)
+	}
+	if release.Tag == "" {
+		return nil, fmt.Errorf("release from %s has no tag", api)
+	}
+	return &release, nil
+}
+
+// Download fetches the archive of the updater's platform from release, checks it
+// against the release checksums and returns the treex binary inside it
+func (u *Updater) Download(ctx context.Context, release *Release) ([]byte, error) {
+	var name string
+	var archive Asset
+	names := ArchiveNames(u.GOOS, u.GOARCH)
+	for _, candidate := range names {
+		if asset, err := release.asset(candidate); err == nil {
+			name, archive = candidate, asset
+			break
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("release %s has no %s", release.Tag, strings.Join(names, " or "))
+	}
+	checksums, err := release.asset(ChecksumsAsset)
+	if err != nil {
+		return nil, err
+	}
+
+	sums, err := u.get(ctx, checksums.URL, 1<<20)
+	if err != nil {
+		return nil, err
+	}
+	want, err := checksumOf(sums, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := u.get(ctx, archive.URL, maxArchiveSize)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("checksum mismatch for %s: got %s, %s lists %s", name, got, ChecksumsAsset, want)
+	}
+
+	return extractBinary(name, data, BinaryName(u.GOOS))
+}
+
+// get fetches url, reading at most limit bytes of a successful reply
+func (u *Updater) get(ctx context.Context, url string, limit int64) ([]byte, error) {
+	client := u.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/vnd.github+json, application/octet-stream")
+	if u.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+u.Token)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", url, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return nil, fmt.Errorf("request to %s failed: %s: %s", url, response.Status, strings.TrimSpace(string(detail)))
+	}
+	body, err := io.ReadAll(io.LimitReader(response.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", url, err)
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("response from %s is larger than %d bytes", url, limit)
+	}
+	return body, nil
+}
+
+// ArchiveNames are the release archives that hold the binary of a platform, as named by
+// the release build, preferred first: treex_Linux_x86_64.tar.gz, treex_Windows_arm64.zip.
+// macOS prefers the universal treex_Darwin_all.tar.gz (universal_binaries in
+// .goreleaser.yml) and falls back to its own architecture's archive.
+func ArchiveNames(goos, goarch string) []string {
+	arch := goarch
+	switch goarch {
+	case "amd64":
+		arch = "x86_64"
+	case "386":
+		arch = "i386"
+	}
+	extension := ".tar.gz"
+	if goos == "windows" {
+		extension = ".zip"
+	}
+	prefix := "treex_" + strings.ToUpper(goos[:1]) + goos[1:] + "_"
+	if goos == "darwin" {
+		return []string{prefix + "all" + extension, prefix + arch + extension}
+	}
+	return []string{prefix + arch + extension}
+}
+
+// BinaryName is the file name of the treex binary on a platform
+func BinaryName(goos string) string {
+	if goos == "windows" {
+		return "treex.exe"
+	}
+	return "treex"
+}
+
+// checksumOf returns the SHA-256 checksums.txt lists for name ("<hex>  <name>" lines)
+func checksumOf(checksums []byte, name string) (string, error) {
+	for _, line := range strings.Split(string(checksums), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s does not list %s", ChecksumsAsset, name)
+}
+
+// extractBinary returns the file called binary from a .tar.gz or .zip archive
+func extractBinary(name string, data []byte, binary string) ([]byte, error) {
+	if strings.HasSuffix(name, ".zip") {
+		reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive %s: %w", name, err)
+		}
+		for _, file := range reader.File {
+			if path.Base(file.Name) != binary || file.FileInfo().IsDir() {
+				continue
+			}
+			content, err := file.Open()
+			if err != nil {
+				return nil, fmt.Errorf("invalid archive %s: %w", name, err)
+			}
+			defer content.Close()
+			return readBinary(name, content)
+		}
+		return nil, fmt.Errorf("archive %s has no %s", name, binary)
+	}
+
+	compressed, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive %s: %w", name, err)
+	}
+	reader := tar.NewReader(compressed)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("archive %s has no %s", name, binary)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive %s: %w", name, err)
+		}
+		if header.Typeflag == tar.TypeReg && path.Base(header.Name) == binary {
+			return readBinary(name, reader)
+		}
+	}
+}
+
+// readBinary reads the binary out of an archive, bounded like the download
+func readBinary(name string, content io.Reader) ([]byte, error) {
+	binary, err := io.ReadAll(io.LimitReader(content, maxArchiveSize))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive %s: %w", name, err)
+	}
+	return binary, nil
+}
+
+// Replace installs binary as the executable at target: it is written next to target
+// and renamed over it, which is atomic on POSIX systems, so target is never missing or
+// half written. Where a running executable cannot be replaced (Windows), the previous
+// binary is moved to .<name>.old first and moved back if the new one cannot take its
+// place; it is removed once the new one is in place, where the platform allows it.
+func Replace(fs afero.Fs, target string, binary []byte) error {
+	dir, base := filepath.Split(target)
+	staged := filepath.Join(dir, "."+base+".new")
+	old := filepath.Join(dir, "."+base+".old")
+
+	if err := afero.WriteFile(fs, staged, binary, 0755); err != nil {
+		return fmt.Errorf("failed to write %s: %w", staged, err)
+	}
+	// WriteFile keeps the mode of an existing file, and the umask may have dropped bits
+	if err := fs.Chmod(staged, 0755); err != nil {
+		_ = fs.Remove(staged)
+		return fmt.Errorf("failed to make %s executable: %w", staged, err)
+	}
+	if err := fs.Rename(staged, target); err == nil {
+		return nil
+	}
+
+	_ = fs.Remove(old)
+	if err := fs.Rename(target, old); err != nil {
+		_ = fs.Remove(staged)
+		return fmt.Errorf("failed to move %s aside: %w", target, err)
+	}
+	if err := fs.Rename(staged, target); err != nil {
+		_ = fs.Remove(staged)
+		if restoreErr := fs.Rename(old, target); restoreErr != nil {
+			return fmt.Errorf("failed to replace %s: %w (the previous binary is at %s)", target, err, old)
+		}
+		return fmt.Errorf("failed to replace %s: %w", target, err)
+	}
+	_ = fs.Remove(old)
+	return nil
+}
+
+// Valid reports whether text is a release version Newer can compare; development
+// builds ("dev", a git describe output) are not
+func Valid(text string) bool {
+	_, ok := parseVersion(text)
+	return ok
+}
+
+// Newer reports whether version latest is newer than current. Versions are compared
+// as vMAJOR.MINOR.PATCH with an optional -prerelease, which sorts before the release
+// and is ordered by its dot-separated identifiers as in semver (rc.9 before rc.10);
+// false when either version does not parse (see Valid).
+func Newer(current, latest string) bool {
+	cur, curOK := parseVersion(current)
+	next, nextOK := parseVersion(latest)
+	if !curOK || !nextOK {
+		return false
+	}
+	for i := range 3 {
+		if cur.numbers[i] != next.numbers[i] {
+			return next.numbers[i] > cur.numbers[i]
+		}
+	}
+	switch {
+	case cur.prerelease == next.prerelease:
+		return false
+	case next.prerelease == "":
+		return true
+	case cur.prerelease == "":
+		return false
+	default:
+		return comparePrerelease(next.prerelease, cur.prerelease) > 0
+	}
+}
+
+// comparePrerelease orders prereleases by their dot-separated identifiers: numeric ones
+// by value and before alphanumeric ones, the others as text, and a prefix first
+func comparePrerelease(a, b string) int {
+	left, right := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(left) && i < len(right); i++ {
+		x, xErr := strconv.Atoi(left[i])
+		y, yErr := strconv.Atoi(right[i])
+		switch {
+		case xErr == nil && yErr == nil:
+			if x != y {
+				return cmp.Compare(x, y)
+			}
+		case xErr == nil:
+			return -1
+		case yErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(left[i], right[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return cmp.Compare(len(left), len(right))
+}
+
+// version is a parsed release version
+type version struct {
+	numbers    [3]int
+	prerelease string
+}
+
+// parseVersion parses "v1.2.3", "1.2.3" or "1.2.3-rc.1" (build metadata is ignored)
+func parseVersion(text string) (version, bool) {
+	text = strings.TrimPrefix(strings.TrimSpace(text), "v")
+	text, _, _ = strings.Cut(text, "+")
+	text, prerelease, _ := strings.Cut(text, "-")
+	parts := strings.Split(text, ".")
+	if len(parts) != 3 {
+		return version{}, false
+	}
+	parsed := version{prerelease: prerelease}
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return version{}, false
+		}
+		parsed.numbers[i] = number
+	}
+	return parsed, true
+}
@@ -0,0 +1,270 @@
+package selfupdate_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex/selfupdate"
+)
+
+// tarGz archives files as a .tar.gz
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	compressed := gzip.NewWriter(&buf)
+	writer := tar.NewWriter(compressed)
+	for name, content := range files {
+		require.NoError(t, writer.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := writer.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	require.NoError(t, compressed.Close())
+	return buf.Bytes()
+}
+
+// zipped archives files as a .zip
+func zipped(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		file, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = file.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+// newReleaseServer serves a latest release v1.2.0 with the given archives and
+// checksums.txt; sums overrides the checksum of an archive
+func newReleaseServer(t *testing.T, archives map[string][]byte, sums map[string]string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	release := selfupdate.Release{Tag: "v1.2.0", URL: server.URL + "/releases/v1.2.0"}
+	var checksums bytes.Buffer
+	for name, data := range archives {
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+		if override, ok := sums[name]; ok {
+			hash = override
+		}
+		checksums.WriteString(hash + "  " + name + "\n")
+		release.Assets = append(release.Assets, selfupdate.Asset{Name: name, URL: server.URL + "/download/" + name})
+		mux.HandleFunc("/download/"+name, func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(data) })
+	}
+	release.Assets = append(release.Assets, selfupdate.Asset{Name: "checksums.txt", URL: server.URL + "/download/checksums.txt"})
+	mux.HandleFunc("/download/checksums.txt", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(checksums.Bytes()) })
+	mux.HandleFunc("/repos/arthur-debert/treex/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(release)
+	})
+	return server
+}
+
+func TestLatest(t *testing.T) {
+	server := newReleaseServer(t, nil, nil)
+	updater := &selfupdate.Updater{API: server.URL}
+
+	release, err := updater.Latest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.0", release.Tag)
+	assert.Equal(t, server.URL+"/releases/v1.2.0", release.URL)
+}
+
+func TestLatestReportsHTTPErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		http.Error(w, "rate limited", http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := (&selfupdate.Updater{API: server.URL, Token: "secret"}).Latest(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403 Forbidden: rate limited")
+}
+
+func TestDownloadTarGz(t *testing.T) {
+	archives := map[string][]byte{
+		"treex_Linux_x86_64.tar.gz": tarGz(t, map[string]string{"README.md": "readme", "treex": "linux binary"}),
+		"treex_Darwin_all.tar.gz":   tarGz(t, map[string]string{"treex": "darwin binary"}),
+	}
+	server := newReleaseServer(t, archives, nil)
+	updater := &selfupdate.Updater{API: server.URL, GOOS: "linux", GOARCH: "amd64"}
+
+	release, err := updater.Latest(context.Background())
+	require.NoError(t, err)
+	binary, err := updater.Download(context.Background(), release)
+	require.NoError(t, err)
+	assert.Equal(t, "linux binary", string(binary))
+}
+
+func TestDownloadZip(t *testing.T) {
+	archives := map[string][]byte{
+		"treex_Windows_arm64.zip": zipped(t, map[string]string{"LICENSE": "MIT", "treex.exe": "windows binary"}),
+	}
+	server := newReleaseServer(t, archives, nil)
+	updater := &selfupdate.Updater{API: server.URL, GOOS: "windows", GOARCH: "arm64"}
+
+	release, err := updater.Latest(context.Background())
+	require.NoError(t, err)
+	binary, err := updater.Download(context.Background(), release)
+	require.NoError(t, err)
+	assert.Equal(t, "windows binary", string(binary))
+}
+
+func TestDownloadRejectsChecksumMismatch(t *testing.T) {
+	archives := map[string][]byte{"treex_Linux_arm64.tar.gz": tarGz(t, map[string]string{"treex": "tampered"})}
+	server := newReleaseServer(t, archives, map[string]string{"treex_Linux_arm64.tar.gz": "00ff"})
+	updater := &selfupdate.Updater{API: server.URL, GOOS: "linux", GOARCH: "arm64"}
+
+	release, err := updater.Latest(context.Background())
+	require.NoError(t, err)
+	_, err = updater.Download(context.Background(), release)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch for treex_Linux_arm64.tar.gz")
+}
+
+func TestDownloadMissingPlatform(t *testing.T) {
+	archives := map[string][]byte{"treex_Linux_x86_64.tar.gz": tarGz(t, map[string]string{"treex": "linux binary"})}
+	server := newReleaseServer(t, archives, nil)
+	updater := &selfupdate.Updater{API: server.URL, GOOS: "freebsd", GOARCH: "amd64"}
+
+	release, err := updater.Latest(context.Background())
+	require.NoError(t, err)
+	_, err = updater.Download(context.Background(), release)
+	assert.EqualError(t, err, "release v1.2.0 has no treex_Freebsd_x86_64.tar.gz")
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, selfupdate.Valid("v1.2.3-rc.1"))
+	assert.False(t, selfupdate.Valid("dev"))
+	assert.False(t, selfupdate.Valid("abc1234-dirty"), "git describe without tags")
+}
+
+func TestArchiveNames(t *testing.T) {
+	assert.Equal(t, []string{"treex_Linux_x86_64.tar.gz"}, selfupdate.ArchiveNames("linux", "amd64"))
+	assert.Equal(t, []string{"treex_Linux_arm64.tar.gz"}, selfupdate.ArchiveNames("linux", "arm64"))
+	assert.Equal(t, []string{"treex_Darwin_all.tar.gz", "treex_Darwin_arm64.tar.gz"}, selfupdate.ArchiveNames("darwin", "arm64"))
+	assert.Equal(t, []string{"treex_Windows_x86_64.zip"}, selfupdate.ArchiveNames("windows", "amd64"))
+}
+
+func TestDownloadDarwin(t *testing.T) {
+	tests := []struct {
+		archive  string
+		expected string
+	}{
+		{"treex_Darwin_all.tar.gz", "universal binary"},
+		{"treex_Darwin_x86_64.tar.gz", "intel binary"},
+	}
+	for _, tt := range tests {
+		archives := map[string][]byte{tt.archive: tarGz(t, map[string]string{"treex": tt.expected})}
+		server := newReleaseServer(t, archives, nil)
+		updater := &selfupdate.Updater{API: server.URL, GOOS: "darwin", GOARCH: "amd64"}
+		release, err := updater.Latest(context.Background())
+		require.NoError(t, err)
+		binary, err := updater.Download(context.Background(), release)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, string(binary), tt.archive)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/usr/local/bin/treex", []byte("old"), 0755))
+
+	require.NoError(t, selfupdate.Replace(fs, "/usr/local/bin/treex", []byte("new")))
+
+	content, err := afero.ReadFile(fs, "/usr/local/bin/treex")
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+	info, err := fs.Stat("/usr/local/bin/treex")
+	require.NoError(t, err)
+	assert.Equal(t, "-rwxr-xr-x", info.Mode().String())
+	entries, err := afero.ReadDir(fs, "/usr/local/bin")
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the staged and previous binaries are removed")
+}
+
+// renameFs fails the renames onto the paths in failTo, as Windows does for a running executable
+type renameFs struct {
+	afero.Fs
+	failTo map[string]int // Remaining failures per destination (-1 = always)
+}
+
+func (fs *renameFs) Rename(oldname, newname string) error {
+	if n := fs.failTo[newname]; n != 0 {
+		fs.failTo[newname] = n - 1
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: os.ErrPermission}
+	}
+	return fs.Fs.Rename(oldname, newname)
+}
+
+func TestReplaceMovesARunningBinaryAside(t *testing.T) {
+	fs := &renameFs{Fs: afero.NewMemMapFs(), failTo: map[string]int{"/usr/local/bin/treex": 1}}
+	require.NoError(t, afero.WriteFile(fs, "/usr/local/bin/treex", []byte("old"), 0755))
+
+	require.NoError(t, selfupdate.Replace(fs, "/usr/local/bin/treex", []byte("new")))
+	content, err := afero.ReadFile(fs, "/usr/local/bin/treex")
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+	entries, err := afero.ReadDir(fs, "/usr/local/bin")
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestReplaceRestoresThePreviousBinary(t *testing.T) {
+	fs := &renameFs{Fs: afero.NewMemMapFs(), failTo: map[string]int{"/usr/local/bin/treex": 2}}
+	require.NoError(t, afero.WriteFile(fs, "/usr/local/bin/treex", []byte("old"), 0755))
+
+	err := selfupdate.Replace(fs, "/usr/local/bin/treex", []byte("new"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to replace")
+	content, err := afero.ReadFile(fs, "/usr/local/bin/treex")
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(content), "the previous binary is moved back")
+	entries, err := afero.ReadDir(fs, "/usr/local/bin")
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the staged binary is removed")
+}
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		newer           bool
+	}{
+		{"1.0.0", "v1.0.1", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"1.10.0", "v1.9.0", false},
+		{"2.0.0-rc.1", "v2.0.0", true},
+		{"2.0.0", "v2.1.0-rc.1", true},
+		{"2.1.0", "v2.1.0-rc.1", false},
+		{"2.1.0-rc.9", "v2.1.0-rc.10", true},
+		{"2.1.0-rc.10", "v2.1.0-rc.9", false},
+		{"2.1.0-beta.2", "v2.1.0-rc.1", true},
+		{"2.1.0-rc", "v2.1.0-rc.1", true},
+		{"2.1.0-1", "v2.1.0-alpha", true},
+		{"dev", "v0.1.0", false},
+		{"1.0.0", "nightly", false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.newer, selfupdate.Newer(test.current, test.latest), "%s -> %s", test.current, test.latest)
+	}
+}
@@ -0,0 +1,226 @@
+// Package server exposes trees and annotations over read-only HTTP endpoints.
+// It is a thin shell over the core API like the CLI: requests are translated into
+// TreeConfig values and results are written with the rendering package.
+//
+// Endpoints:
+//
+//	GET /tree?path=&depth=   Tree JSON (same format as the JSON renderer)
+//	GET /annotations         Annotated paths and their notes
+//	GET /validate            Problems found in .info files
+//	GET /                    Plain HTML view of the tree (when enabled)
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+	"treex/treex"
+	"treex/treex/pathutil"
+	"treex/treex/plugins/infofile"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+// Config configures a tree server
+type Config struct {
+	// Root directory served; requests cannot reach outside it
+	Root string
+
+	// Filesystem the root lives on (nil uses the OS filesystem)
+	Filesystem afero.Fs
+
+	// Tree holds the filter options applied to every request (Root, Filesystem and
+	// MaxDepth are set per request)
+	Tree treex.TreeConfig
+
+	// EnableHTML serves a plain HTML view of the tree at "/"
+	EnableHTML bool
+}
+
+// Server handles tree requests for a single root directory
+type Server struct {
+	config Config
+	fs     afero.Fs // Filesystem rooted at config.Root
+}
+
+// AnnotationEntry is an annotated path returned by /annotations
+type AnnotationEntry struct {
+	Path  string `json:"path"`
+	Notes string `json:"notes"`
+}
+
+// NewServer creates a server for the configured root
+func NewServer(config Config) *Server {
+	if config.Filesystem == nil {
+		config.Filesystem = afero.NewOsFs()
+	}
+
+	// Requests are resolved inside a filesystem rooted at the served directory, so
+	// ".." in a request path cannot escape it and plugins see the root as "/"
+	return &Server{
+		config: config,
+		fs:     afero.NewBasePathFs(config.Filesystem, config.Root),
+	}
+}
+
+// Handler returns the HTTP handler with all endpoints registered
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tree", s.handleTree)
+	mux.HandleFunc("GET /annotations", s.handleAnnotations)
+	mux.HandleFunc("GET /validate", s.handleValidate)
+	if s.config.EnableHTML {
+		mux.HandleFunc("GET /{$}", s.handleHTML)
+	}
+	return mux
+}
+
+// handleTree returns the tree for ?path= (relative to the root) limited to ?depth=
+func (s *Server) handleTree(w http.ResponseWriter, r *http.Request) {
+	result, status, err := s.buildTree(r)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{Format: rendering.FormatJSON, Writer: &buf})
+	if err := renderer.RenderTree(result); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// handleAnnotations lists every annotated path under the root
+func (s *Server) handleAnnotations(w http.ResponseWriter, r *http.Request) {
+	result, status, err := s.buildTree(r)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	entries := []AnnotationEntry{}
+	collectAnnotations(result.Root, &entries)
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// handleValidate reports problems in the .info files under the root
+func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	issues, err := infofile.Validate(s.fs, "/")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if issues == nil {
+		issues = []infofile.Issue{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"valid":  len(issues) == 0,
+		"issues": issues,
+	})
+}
+
+// handleHTML renders the plain text tree inside a minimal HTML page
+func (s *Server) handleHTML(w http.ResponseWriter, r *http.Request) {
+	result, status, err := s.buildTree(r)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format:    rendering.FormatPlain,
+		Writer:    &buf,
+		ShowNotes: true,
+		Width:     -1,
+	})
+	if err := renderer.RenderTree(result); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>treex</title></head>\n<body><pre>%s</pre></body></html>\n",
+		html.EscapeString(buf.String()))
+}
+
+// buildTree builds the tree for a request's path and depth parameters
+// Returns the HTTP status to use when an error is returned
+func (s *Server) buildTree(r *http.Request) (*treex.TreeResult, int, error) {
+	query := r.URL.Query()
+
+	depth := 0
+	if value := query.Get("depth"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid depth %q", value)
+		}
+		depth = parsed
+	}
+
+	requestPath := pathutil.Normalize(strings.TrimPrefix(query.Get("path"), "/"))
+	if requestPath == ".." || strings.HasPrefix(requestPath, "../") {
+		return nil, http.StatusBadRequest, fmt.Errorf("path %q is outside the served root", query.Get("path"))
+	}
+	root := path.Join("/", requestPath)
+
+	info, err := s.fs.Stat(root)
+	if err != nil {
+		return nil, http.StatusNotFound, fmt.Errorf("path %q not found", query.Get("path"))
+	}
+	if !info.IsDir() {
+		return nil, http.StatusBadRequest, fmt.Errorf("path %q is not a directory", query.Get("path"))
+	}
+
+	// Root the tree at the requested directory so node and annotation paths are relative to it
+	config := s.config.Tree
+	config.Root = "/"
+	config.Filesystem = afero.NewBasePathFs(s.fs, root)
+	config.MaxDepth = depth
+
+	// Client disconnects cancel the walk
+	result, err := treex.BuildTreeContext(r.Context(), config)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return result, http.StatusOK, nil
+}
+
+// collectAnnotations appends the annotated nodes of a tree in tree order
+func collectAnnotations(node *types.Node, entries *[]AnnotationEntry) {
+	if node == nil {
+		return
+	}
+	if annotation := node.GetAnnotation(); annotation != nil && annotation.Notes != "" {
+		*entries = append(*entries, AnnotationEntry{Path: pathutil.Normalize(node.Path), Notes: annotation.Notes})
+	}
+	for _, child := range node.Children {
+		collectAnnotations(child, entries)
+	}
+}
+
+// writeJSON writes value as an indented JSON response
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(value)
+}
+
+// writeError writes an error as a JSON response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,162 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex"
+	_ "treex/treex/plugins/infofile" // Import for plugin registration
+	"treex/treex/schema"
+	"treex/treex/server"
+)
+
+// newTestServer serves /project from an in-memory filesystem
+func newTestServer(t *testing.T, html bool) *httptest.Server {
+	t.Helper()
+
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":     "README.md  Project overview\nmissing.go  Gone\n",
+		"README.md": "# project",
+		"src": map[string]interface{}{
+			".info":   "main.go  Entry point\n",
+			"main.go": "package main",
+			"lib": map[string]interface{}{
+				"util.go": "package lib",
+			},
+		},
+	})
+
+	srv := server.NewServer(server.Config{
+		Root:       "/project",
+		Filesystem: fs,
+		Tree:       treex.DefaultTreeConfig("/project"),
+		EnableHTML: html,
+	})
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// getJSON fetches url and decodes the JSON body into target
+func getJSON(t *testing.T, url string, target interface{}) int {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(target))
+	return resp.StatusCode
+}
+
+// childNames returns the names of a JSON tree node's children
+func childNames(node map[string]interface{}) []string {
+	var names []string
+	children, _ := node["children"].([]interface{})
+	for _, child := range children {
+		names = append(names, child.(map[string]interface{})["name"].(string))
+	}
+	return names
+}
+
+func TestTreeEndpoint(t *testing.T) {
+	ts := newTestServer(t, false)
+
+	var body struct {
+		Tree map[string]interface{} `json:"tree"`
+	}
+	status := getJSON(t, ts.URL+"/tree", &body)
+	assert.Equal(t, http.StatusOK, status)
+	assert.ElementsMatch(t, []string{".info", "README.md", "src"}, childNames(body.Tree))
+
+	t.Run("path and depth", func(t *testing.T) {
+		var body struct {
+			Tree map[string]interface{} `json:"tree"`
+		}
+		status := getJSON(t, ts.URL+"/tree?path=src&depth=1", &body)
+		assert.Equal(t, http.StatusOK, status)
+		assert.ElementsMatch(t, []string{".info", "main.go", "lib"}, childNames(body.Tree))
+
+		for _, child := range body.Tree["children"].([]interface{}) {
+			assert.Nil(t, child.(map[string]interface{})["children"], "depth 1 must not include grandchildren")
+		}
+	})
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{"outside root", "?path=../etc", http.StatusBadRequest},
+		{"missing path", "?path=nope", http.StatusNotFound},
+		{"file path", "?path=README.md", http.StatusBadRequest},
+		{"bad depth", "?depth=deep", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]string
+			assert.Equal(t, tt.status, getJSON(t, ts.URL+"/tree"+tt.query, &body))
+			assert.NotEmpty(t, body["error"])
+		})
+	}
+}
+
+func TestAnnotationsEndpoint(t *testing.T) {
+	ts := newTestServer(t, false)
+
+	var entries []server.AnnotationEntry
+	status := getJSON(t, ts.URL+"/annotations", &entries)
+	assert.Equal(t, http.StatusOK, status)
+	assert.ElementsMatch(t, []server.AnnotationEntry{
+		{Path: "README.md", Notes: "Project overview"},
+		{Path: "src/main.go", Notes: "Entry point"},
+	}, entries)
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	ts := newTestServer(t, false)
+
+	var body struct {
+		Valid  bool `json:"valid"`
+		Issues []struct {
+			InfoFile string `json:"infoFile"`
+			Line     int    `json:"line"`
+			Path     string `json:"path"`
+		} `json:"issues"`
+	}
+	var raw json.RawMessage
+	status := getJSON(t, ts.URL+"/validate", &raw)
+	assert.Equal(t, http.StatusOK, status)
+	require.NoError(t, schema.Validate("validation", raw))
+	require.NoError(t, json.Unmarshal(raw, &body))
+	assert.False(t, body.Valid)
+	require.Len(t, body.Issues, 1)
+	assert.Equal(t, ".info", body.Issues[0].InfoFile)
+	assert.Equal(t, 2, body.Issues[0].Line)
+	assert.Equal(t, "missing.go", body.Issues[0].Path)
+}
+
+func TestHTMLView(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		resp, err := http.Get(newTestServer(t, false).URL + "/")
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		resp, err := http.Get(newTestServer(t, true).URL + "/")
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+	})
+}
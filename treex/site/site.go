@@ -0,0 +1,217 @@
+// Package site generates a static documentation site from an annotated tree, ready to
+// publish with GitHub Pages or any static file host.
+//
+// The site has an index page for the root, one page per top-level directory and a
+// search index:
+//
+//	index.html             Root annotation, top-level entries, search box
+//	<dir>/index.html       Breadcrumbs, the directory's tree and annotated entries
+//	search-index.json      Every directory and annotated path, with its page
+//	style.css
+//
+// Trees are drawn by the plain text renderer, as treex serve --html shows them.
+package site
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/afero"
+	"treex/treex"
+	"treex/treex/pathutil"
+	"treex/treex/rendering"
+	"treex/treex/types"
+)
+
+// SearchIndexFile is the name of the search index written at the site root
+const SearchIndexFile = "search-index.json"
+
+// Options configures a generated site
+type Options struct {
+	// Title names the site in page titles and breadcrumbs (default: the root's name)
+	Title string
+}
+
+// SearchEntry is one searchable path in the search index
+type SearchEntry struct {
+	Path  string `json:"path"`  // Relative to the root, slash-separated
+	Notes string `json:"notes"` // Annotation text ("" for unannotated directories)
+	IsDir bool   `json:"isDir"`
+	Page  string `json:"page"` // Page showing the path, relative to the site root
+}
+
+// Generate writes the site for the tree rooted at root into outDir on fs, creating it
+// as needed. Returns the files written, relative to outDir, sorted.
+func Generate(fs afero.Fs, root *types.Node, outDir string, opts Options) ([]string, error) {
+	if root == nil {
+		return nil, fmt.Errorf("no tree to generate a site from")
+	}
+	title := opts.Title
+	if title == "" {
+		title = root.Name
+	}
+
+	files := map[string][]byte{"style.css": []byte(stylesheet)}
+
+	var entries []entry
+	for _, child := range root.Children {
+		entry := newEntry(child)
+		if child.IsDir {
+			entry.Page = pagePath(child)
+			content, err := renderSection(child, title)
+			if err != nil {
+				return nil, err
+			}
+			files[entry.Page] = content
+		}
+		entries = append(entries, entry)
+	}
+
+	var index bytes.Buffer
+	err := indexTemplate.Execute(&index, indexPage{
+		Title:   title,
+		Notes:   notes(root),
+		Entries: entries,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render index page: %w", err)
+	}
+	files["index.html"] = index.Bytes()
+
+	search, err := json.MarshalIndent(SearchIndex(root), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode search index: %w", err)
+	}
+	files[SearchIndexFile] = append(search, '\n')
+
+	written := make([]string, 0, len(files))
+	for name := range files {
+		written = append(written, name)
+	}
+	sort.Strings(written)
+	for _, name := range written {
+		fullPath := filepath.Join(outDir, filepath.FromSlash(name))
+		if err := fs.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(fullPath), err)
+		}
+		if err := afero.WriteFile(fs, fullPath, files[name], 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return written, nil
+}
+
+// SearchIndex lists every directory and annotated path below root in tree order, each
+// with the page that shows it
+func SearchIndex(root *types.Node) []SearchEntry {
+	index := []SearchEntry{}
+	var walk func(node *types.Node, page string)
+	walk = func(node *types.Node, page string) {
+		for _, child := range node.Children {
+			childPage := page
+			if node == root {
+				childPage = "index.html"
+				if child.IsDir {
+					childPage = pagePath(child)
+				}
+			}
+			if child.IsDir || notes(child) != "" {
+				index = append(index, SearchEntry{
+					Path:  pathutil.Normalize(child.Path),
+					Notes: notes(child),
+					IsDir: child.IsDir,
+					Page:  childPage,
+				})
+			}
+			walk(child, childPage)
+		}
+	}
+	walk(root, "index.html")
+	return index
+}
+
+// renderSection renders the page of a top-level directory
+func renderSection(dir *types.Node, title string) ([]byte, error) {
+	var tree bytes.Buffer
+	renderer := rendering.NewRenderer(rendering.RenderConfig{
+		Format:    rendering.FormatPlain,
+		Writer:    &tree,
+		ShowNotes: true,
+		Width:     -1,
+	})
+	// Drawn as a root, without the connector it has below the tree root
+	subtree := *dir
+	subtree.Parent = nil
+	if err := renderer.RenderTree(&treex.TreeResult{Root: &subtree}); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", dir.Path, err)
+	}
+
+	var annotated []entry
+	var walk func(node *types.Node)
+	walk = func(node *types.Node) {
+		for _, child := range node.Children {
+			if notes(child) != "" {
+				annotated = append(annotated, newEntry(child))
+			}
+			walk(child)
+		}
+	}
+	walk(dir)
+
+	var page bytes.Buffer
+	err := sectionTemplate.Execute(&page, sectionPage{
+		Title:     title,
+		Name:      dir.Name,
+		Notes:     notes(dir),
+		Tree:      tree.String(),
+		Annotated: annotated,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", dir.Path, err)
+	}
+	return page.Bytes(), nil
+}
+
+// pagePath is the page of a top-level directory, relative to the site root
+func pagePath(dir *types.Node) string {
+	return path.Join(dir.Name, "index.html")
+}
+
+// notes returns the annotation text of a node, or ""
+func notes(node *types.Node) string {
+	if annotation := node.GetAnnotation(); annotation != nil {
+		return annotation.Notes
+	}
+	return ""
+}
+
+// entry is a row of an entry table
+type entry struct {
+	Path  string
+	Name  string
+	IsDir bool
+	Notes string
+	Page  string // Page of a top-level directory ("" elsewhere)
+}
+
+func newEntry(node *types.Node) entry {
+	return entry{Path: pathutil.Normalize(node.Path), Name: node.Name, IsDir: node.IsDir, Notes: notes(node)}
+}
+
+type indexPage struct {
+	Title   string
+	Notes   string
+	Entries []entry
+}
+
+type sectionPage struct {
+	Title     string
+	Name      string
+	Notes     string
+	Tree      string
+	Annotated []entry
+}
@@ -0,0 +1,70 @@
+package site_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex"
+	"treex/treex/site"
+)
+
+func buildTree(t *testing.T) *treex.TreeResult {
+	t.Helper()
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":     ".  Acme <services>\nREADME.md  Overview\napi  HTTP handlers\n",
+		"README.md": "# project",
+		"api": map[string]interface{}{
+			".info":     "server.go  Request routing\n",
+			"server.go": "package api",
+			"v1":        map[string]interface{}{"users.go": "package v1"},
+		},
+		"docs": map[string]interface{}{"guide.md": "# Guide"},
+	})
+	result, err := treex.BuildTree(treex.TreeConfig{Root: "/project", Filesystem: fs})
+	require.NoError(t, err)
+	return result
+}
+
+func TestGenerate(t *testing.T) {
+	out := afero.NewMemMapFs()
+	written, err := site.Generate(out, buildTree(t).Root, "/site", site.Options{Title: "Acme"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api/index.html", "docs/index.html", "index.html", "search-index.json", "style.css"}, written)
+
+	index, err := afero.ReadFile(out, "/site/index.html")
+	require.NoError(t, err)
+	assert.Contains(t, string(index), "<title>Acme</title>")
+	assert.Contains(t, string(index), `<p class="notes">Acme &lt;services&gt;</p>`, "annotations are escaped")
+	assert.Contains(t, string(index), `<a href="api/index.html">api/</a></td><td>HTTP handlers</td>`)
+	assert.Contains(t, string(index), `<td class="name">README.md</td><td>Overview</td>`)
+
+	page, err := afero.ReadFile(out, "/site/api/index.html")
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `<nav class="breadcrumbs"><a href="../index.html">Acme</a> / api</nav>`)
+	assert.Contains(t, string(page), "<pre class=\"tree\">api")
+	assert.Contains(t, string(page), "server.go")
+	assert.Contains(t, string(page), `<td class="name">api/server.go</td><td>Request routing</td>`)
+}
+
+func TestSearchIndex(t *testing.T) {
+	out := afero.NewMemMapFs()
+	_, err := site.Generate(out, buildTree(t).Root, "/site", site.Options{})
+	require.NoError(t, err)
+
+	content, err := afero.ReadFile(out, "/site/"+site.SearchIndexFile)
+	require.NoError(t, err)
+	var index []site.SearchEntry
+	require.NoError(t, json.Unmarshal(content, &index))
+	assert.Equal(t, []site.SearchEntry{
+		{Path: "README.md", Notes: "Overview", Page: "index.html"},
+		{Path: "api", Notes: "HTTP handlers", IsDir: true, Page: "api/index.html"},
+		{Path: "api/server.go", Notes: "Request routing", Page: "api/index.html"},
+		{Path: "api/v1", IsDir: true, Page: "api/index.html"},
+		{Path: "docs", IsDir: true, Page: "docs/index.html"},
+	}, index)
+}
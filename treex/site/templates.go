@@ -0,0 +1,81 @@
+package site
+
+import "html/template"
+
+// indexTemplate renders the root page; the search box filters search-index.json in the
+// browser and needs the site to be served over HTTP(S)
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="style.css">
+</head>
+<body>
+<nav class="breadcrumbs">{{.Title}}</nav>
+<h1>{{.Title}}</h1>
+{{with .Notes}}<p class="notes">{{.}}</p>
+{{end}}<input id="search" type="search" placeholder="Search paths and annotations" autocomplete="off">
+<ul id="results"></ul>
+<table>
+{{range .Entries}}<tr><td class="name">{{if .Page}}<a href="{{.Page}}">{{.Name}}/</a>{{else}}{{.Name}}{{end}}</td><td>{{.Notes}}</td></tr>
+{{end}}</table>
+<script>
+(function () {
+  var input = document.getElementById("search"), results = document.getElementById("results"), index = [];
+  fetch("search-index.json").then(function (r) { return r.json(); }).then(function (data) { index = data; });
+  input.addEventListener("input", function () {
+    var query = input.value.trim().toLowerCase();
+    results.textContent = "";
+    if (!query) { return; }
+    index.filter(function (e) {
+      return e.path.toLowerCase().indexOf(query) >= 0 || e.notes.toLowerCase().indexOf(query) >= 0;
+    }).slice(0, 50).forEach(function (e) {
+      var item = document.createElement("li"), link = document.createElement("a");
+      link.href = e.page;
+      link.textContent = e.path + (e.isDir ? "/" : "");
+      item.appendChild(link);
+      if (e.notes) { item.appendChild(document.createTextNode("  " + e.notes)); }
+      results.appendChild(item);
+    });
+  });
+})();
+</script>
+</body>
+</html>
+`))
+
+// sectionTemplate renders the page of a top-level directory
+var sectionTemplate = template.Must(template.New("section").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Name}} - {{.Title}}</title>
+<link rel="stylesheet" href="../style.css">
+</head>
+<body>
+<nav class="breadcrumbs"><a href="../index.html">{{.Title}}</a> / {{.Name}}</nav>
+<h1>{{.Name}}/</h1>
+{{with .Notes}}<p class="notes">{{.}}</p>
+{{end}}<pre class="tree">{{.Tree}}</pre>
+{{if .Annotated}}<h2>Annotated paths</h2>
+<table>
+{{range .Annotated}}<tr><td class="name">{{.Path}}{{if .IsDir}}/{{end}}</td><td>{{.Notes}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+// stylesheet is shared by every page
+const stylesheet = `body { font-family: system-ui, sans-serif; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+.breadcrumbs { color: #666; font-size: 0.9rem; }
+.notes { font-size: 1.1rem; }
+.tree { background: #f6f8fa; padding: 1rem; overflow-x: auto; }
+table { border-collapse: collapse; width: 100%; }
+td { padding: 0.25rem 0.5rem; border-bottom: 1px solid #eee; vertical-align: top; }
+td.name { font-family: ui-monospace, monospace; white-space: nowrap; }
+#search { width: 100%; padding: 0.5rem; margin: 1rem 0 0.5rem; font-size: 1rem; }
+#results li { font-family: ui-monospace, monospace; }
+`
@@ -0,0 +1,199 @@
+package treex
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"treex/treex/types"
+)
+
+// DefaultLargestDirectories is the number of directories reported by AnalyzeStructure
+const DefaultLargestDirectories = 10
+
+// StructureStats is a structural overview of a tree
+type StructureStats struct {
+	Files              int               `json:"files"`
+	Directories        int               `json:"directories"`
+	InfoFiles          int               `json:"infoFiles"`
+	Annotated          int               `json:"annotated"`
+	ByDepth            []DepthCount      `json:"byDepth"`
+	ByExtension        []ExtensionCount  `json:"byExtension"`
+	LargestDirectories []DirectoryStats  `json:"largestDirectories"`
+	Coverage           []SubtreeCoverage `json:"coverage"`
+}
+
+// DepthCount counts entries at one depth (the root is depth 0)
+type DepthCount struct {
+	Depth       int `json:"depth"`
+	Files       int `json:"files"`
+	Directories int `json:"directories"`
+}
+
+// ExtensionCount counts files sharing an extension ("" for files without one)
+type ExtensionCount struct {
+	Extension string `json:"extension"`
+	Files     int    `json:"files"`
+	Size      int64  `json:"size"`
+}
+
+// DirectoryStats totals the files below a directory, recursively
+type DirectoryStats struct {
+	Path  string `json:"path"`
+	Files int    `json:"files"`
+	Size  int64  `json:"size"`
+}
+
+// SubtreeCoverage reports how many entries of a top-level subtree are annotated
+// The subtree's own directory is counted as an entry. Binary, generated and vendored
+// entries (see types.FileKind) are left out, as nobody is expected to document them.
+type SubtreeCoverage struct {
+	Path      string  `json:"path"`
+	Entries   int     `json:"entries"`
+	Annotated int     `json:"annotated"`
+	Percent   float64 `json:"percent"`
+	Excluded  int     `json:"excluded"` // Binary, generated and vendored entries not counted
+}
+
+// AnalyzeStructure computes structural statistics for the tree rooted at root
+// largest limits the number of directories reported (0 uses DefaultLargestDirectories).
+// It works on the node tree only, so it reflects the same filters (excludes, depth,
+// hidden files) that were used to build the tree.
+func AnalyzeStructure(root *types.Node, largest int) *StructureStats {
+	stats := &StructureStats{
+		ByDepth:            []DepthCount{},
+		ByExtension:        []ExtensionCount{},
+		LargestDirectories: []DirectoryStats{},
+		Coverage:           []SubtreeCoverage{},
+	}
+	if root == nil {
+		return stats
+	}
+	if largest <= 0 {
+		largest = DefaultLargestDirectories
+	}
+
+	extensions := make(map[string]*ExtensionCount)
+	directories := analyzeNodes(root, stats, extensions)
+
+	for _, count := range extensions {
+		stats.ByExtension = append(stats.ByExtension, *count)
+	}
+	sort.Slice(stats.ByExtension, func(i, j int) bool {
+		a, b := stats.ByExtension[i], stats.ByExtension[j]
+		if a.Files != b.Files {
+			return a.Files > b.Files
+		}
+		return a.Extension < b.Extension
+	})
+
+	sort.Slice(directories, func(i, j int) bool {
+		a, b := directories[i], directories[j]
+		if a.Size != b.Size {
+			return a.Size > b.Size
+		}
+		return a.Path < b.Path
+	})
+	if len(directories) > largest {
+		directories = directories[:largest]
+	}
+	stats.LargestDirectories = directories
+
+	for _, child := range root.Children {
+		if child.IsDir {
+			if coverage := subtreeCoverage(child); coverage.Entries > 0 {
+				stats.Coverage = append(stats.Coverage, coverage)
+			}
+		}
+	}
+
+	return stats
+}
+
+// analyzeNodes accumulates counts for the nodes of the tree rooted at root, returning
+// the recursive file count and size of each directory below it
+func analyzeNodes(root *types.Node, stats *StructureStats, extensions map[string]*ExtensionCount) []DirectoryStats {
+	tally := func(node *types.Node, depth int) error {
+		for len(stats.ByDepth) <= depth {
+			stats.ByDepth = append(stats.ByDepth, DepthCount{Depth: len(stats.ByDepth)})
+		}
+		if isAnnotated(node) {
+			stats.Annotated++
+		}
+		if node.IsDir {
+			stats.Directories++
+			stats.ByDepth[depth].Directories++
+			return nil
+		}
+
+		stats.Files++
+		stats.ByDepth[depth].Files++
+		if node.Name == ".info" {
+			stats.InfoFiles++
+		}
+		ext := strings.ToLower(strings.TrimPrefix(path.Ext(node.Name), "."))
+		count, ok := extensions[ext]
+		if !ok {
+			count = &ExtensionCount{Extension: ext}
+			extensions[ext] = count
+		}
+		count.Files++
+		count.Size += node.Size
+		return nil
+	}
+
+	// Directory totals are summed from their children's, which post-order visits first
+	var directories []DirectoryStats
+	totals := make(map[*types.Node]DirectoryStats)
+	sum := func(node *types.Node, depth int) error {
+		if !node.IsDir {
+			totals[node] = DirectoryStats{Files: 1, Size: node.Size}
+			return nil
+		}
+		dir := DirectoryStats{Path: node.Path}
+		for _, child := range node.Children {
+			dir.Files += totals[child].Files
+			dir.Size += totals[child].Size
+			delete(totals, child)
+		}
+		totals[node] = dir
+
+		// The root is summarized by the totals, so only subdirectories are ranked
+		if depth > 0 {
+			directories = append(directories, dir)
+		}
+		return nil
+	}
+
+	_ = types.WalkTreePrePost(root, tally, sum)
+	return directories
+}
+
+// subtreeCoverage counts annotated entries in the subtree rooted at node
+func subtreeCoverage(node *types.Node) SubtreeCoverage {
+	coverage := SubtreeCoverage{Path: node.Path}
+
+	_ = types.WalkTree(node, func(n *types.Node, depth int) error {
+		if n.GetKind() != "" {
+			coverage.Excluded++
+		} else {
+			coverage.Entries++
+			if isAnnotated(n) {
+				coverage.Annotated++
+			}
+		}
+		return nil
+	})
+	if coverage.Entries == 0 {
+		return coverage // Only machine-made entries: nothing to cover
+	}
+
+	coverage.Percent = float64(coverage.Annotated) * 100 / float64(coverage.Entries)
+	return coverage
+}
+
+// isAnnotated reports whether a node has annotation notes
+func isAnnotated(node *types.Node) bool {
+	annotation := node.GetAnnotation()
+	return annotation != nil && annotation.Notes != ""
+}
@@ -0,0 +1,145 @@
+package treex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	_ "treex/treex/plugins/infofile" // Import for plugin registration
+	"treex/treex/types"
+)
+
+func TestAnalyzeStructure(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/test", map[string]interface{}{
+		".info":     "src  Application source\n",
+		"README.md": "# readme",
+		"src": map[string]interface{}{
+			".info":   "main.go  Entry point\n",
+			"main.go": "package main",
+			"util.go": "package main\n\nfunc util() {}\n",
+			"lib": map[string]interface{}{
+				"helper.go": "package lib",
+			},
+		},
+		"docs": map[string]interface{}{
+			"guide.md": "guide",
+		},
+	})
+
+	result, err := BuildTree(TreeConfig{Root: "/test", Filesystem: fs, IncludeHidden: true})
+	require.NoError(t, err)
+
+	// Annotate directly so the test does not depend on how enrichment resolves the root
+	annotate(t, result.Root, "src", "Application source")
+	annotate(t, result.Root, "src/main.go", "Entry point")
+
+	stats := AnalyzeStructure(result.Root, 0)
+
+	assert.Equal(t, 7, stats.Files)
+	assert.Equal(t, 4, stats.Directories)
+	assert.Equal(t, 2, stats.InfoFiles)
+	assert.Equal(t, 2, stats.Annotated)
+
+	assert.Equal(t, []DepthCount{
+		{Depth: 0, Files: 0, Directories: 1},
+		{Depth: 1, Files: 2, Directories: 2},
+		{Depth: 2, Files: 4, Directories: 1},
+		{Depth: 3, Files: 1, Directories: 0},
+	}, stats.ByDepth)
+
+	require.NotEmpty(t, stats.ByExtension)
+	assert.Equal(t, "go", stats.ByExtension[0].Extension)
+	assert.Equal(t, 3, stats.ByExtension[0].Files)
+
+	require.Len(t, stats.LargestDirectories, 3)
+	assert.Equal(t, "src", stats.LargestDirectories[0].Path)
+	assert.Equal(t, 4, stats.LargestDirectories[0].Files)
+
+	require.Len(t, stats.Coverage, 2)
+	assert.Equal(t, SubtreeCoverage{Path: "docs", Entries: 2, Annotated: 0, Percent: 0}, stats.Coverage[0])
+	assert.Equal(t, "src", stats.Coverage[1].Path)
+	assert.Equal(t, 6, stats.Coverage[1].Entries)
+	assert.Equal(t, 2, stats.Coverage[1].Annotated)
+}
+
+func TestAnalyzeStructureLimitsLargestDirectories(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/test", map[string]interface{}{
+		"a": map[string]interface{}{"file.txt": "aaaa"},
+		"b": map[string]interface{}{"file.txt": "bb"},
+		"c": map[string]interface{}{"file.txt": "c"},
+	})
+
+	result, err := BuildTree(TreeConfig{Root: "/test", Filesystem: fs})
+	require.NoError(t, err)
+
+	stats := AnalyzeStructure(result.Root, 2)
+	require.Len(t, stats.LargestDirectories, 2)
+	assert.Equal(t, "a", stats.LargestDirectories[0].Path)
+	assert.Equal(t, "b", stats.LargestDirectories[1].Path)
+}
+
+func TestAnalyzeStructureCoverageSkipsMachineFiles(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/test", map[string]interface{}{
+		"api": map[string]interface{}{
+			"server.go":   "package api",
+			"api.pb.go":   "package api",
+			"vendor":      map[string]interface{}{"lib.go": "package lib"},
+			"favicon.ico": "\x00",
+			"handlers.go": "package api",
+		},
+		"third_party": map[string]interface{}{"lib.go": "package lib"},
+	})
+	result, err := BuildTree(TreeConfig{Root: "/test", Filesystem: fs})
+	require.NoError(t, err)
+
+	annotate(t, result.Root, "api/server.go", "HTTP server")
+	for nodePath, kind := range map[string]types.FileKind{
+		"api/api.pb.go": types.KindGenerated, "api/vendor": types.KindVendored, "api/vendor/lib.go": types.KindVendored,
+		"api/favicon.ico": types.KindBinary, "third_party": types.KindVendored, "third_party/lib.go": types.KindVendored,
+	} {
+		findNode(t, result.Root, nodePath).SetPluginData("kind", kind)
+	}
+
+	stats := AnalyzeStructure(result.Root, 0)
+	assert.Equal(t, []SubtreeCoverage{{Path: "api", Entries: 3, Annotated: 1, Percent: 100.0 / 3, Excluded: 4}}, stats.Coverage,
+		"subtrees of machine-made entries only are left out")
+	assert.Equal(t, 6, stats.Files, "other statistics count every file")
+}
+
+func TestAnalyzeStructureNilRoot(t *testing.T) {
+	stats := AnalyzeStructure(nil, 0)
+	assert.Zero(t, stats.Files)
+	assert.Empty(t, stats.ByDepth)
+}
+
+// annotate sets annotation notes on the node at nodePath
+func annotate(t *testing.T, root *types.Node, nodePath, notes string) {
+	t.Helper()
+	findNode(t, root, nodePath).SetAnnotation(&types.Annotation{Path: nodePath, Notes: notes})
+}
+
+// findNode returns the node at nodePath below root
+func findNode(t *testing.T, root *types.Node, nodePath string) *types.Node {
+	t.Helper()
+
+	var find func(n *types.Node) *types.Node
+	find = func(n *types.Node) *types.Node {
+		if n.Path == nodePath {
+			return n
+		}
+		for _, child := range n.Children {
+			if found := find(child); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+
+	node := find(root)
+	require.NotNil(t, node, "node %q not found", nodePath)
+	return node
+}
@@ -0,0 +1,138 @@
+package suggest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxResponseTokens bounds the reply; annotations are a single short line
+const maxResponseTokens = 100
+
+// Client sends a prompt to a model and returns its text reply
+type Client interface {
+	Complete(ctx context.Context, prompt string) (string, error)
+}
+
+// NewClient returns the client for the configured provider (a nil httpClient uses http.DefaultClient)
+func NewClient(config Config, httpClient *http.Client) (Client, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	switch config.Provider {
+	case ProviderOpenAI:
+		return &openAIClient{config: config, http: httpClient}, nil
+	case ProviderAnthropic:
+		return &anthropicClient{config: config, http: httpClient}, nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", config.Provider)
+	}
+}
+
+// message is a chat message in both request formats
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// openAIClient calls POST {endpoint}/chat/completions
+type openAIClient struct {
+	config Config
+	http   *http.Client
+}
+
+func (c *openAIClient) Complete(ctx context.Context, prompt string) (string, error) {
+	request := map[string]interface{}{
+		"model":      c.config.Model,
+		"max_tokens": maxResponseTokens,
+		"messages":   []message{{Role: "user", Content: prompt}},
+	}
+	headers := map[string]string{}
+	if c.config.APIKey != "" {
+		headers["Authorization"] = "Bearer " + c.config.APIKey
+	}
+
+	var response struct {
+		Choices []struct {
+			Message message `json:"message"`
+		} `json:"choices"`
+	}
+	if err := postJSON(ctx, c.http, c.config.Endpoint+"/chat/completions", headers, request, &response); err != nil {
+		return "", err
+	}
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("empty response from %s", c.config.Endpoint)
+	}
+	return response.Choices[0].Message.Content, nil
+}
+
+// anthropicClient calls POST {endpoint}/v1/messages
+type anthropicClient struct {
+	config Config
+	http   *http.Client
+}
+
+func (c *anthropicClient) Complete(ctx context.Context, prompt string) (string, error) {
+	request := map[string]interface{}{
+		"model":      c.config.Model,
+		"max_tokens": maxResponseTokens,
+		"messages":   []message{{Role: "user", Content: prompt}},
+	}
+	headers := map[string]string{"anthropic-version": "2023-06-01"}
+	if c.config.APIKey != "" {
+		headers["x-api-key"] = c.config.APIKey
+	}
+
+	var response struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := postJSON(ctx, c.http, c.config.Endpoint+"/v1/messages", headers, request, &response); err != nil {
+		return "", err
+	}
+	for _, block := range response.Content {
+		if block.Type == "text" {
+			return block.Text, nil
+		}
+	}
+	return "", fmt.Errorf("empty response from %s", c.config.Endpoint)
+}
+
+// postJSON sends body as JSON and decodes a successful JSON reply into result
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body, result interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", url, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("request to %s failed: %s: %s", url, response.Status, strings.TrimSpace(string(detail)))
+	}
+	if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", url, err)
+	}
+	return nil
+}
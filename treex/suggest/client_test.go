@@ -0,0 +1,95 @@
+package suggest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex/suggest"
+)
+
+// recordedRequest is what the fake endpoint received
+type recordedRequest struct {
+	path    string
+	headers http.Header
+	body    map[string]interface{}
+}
+
+// newEndpoint serves reply as JSON and records the last request
+func newEndpoint(t *testing.T, status int, reply string) (*httptest.Server, *recordedRequest) {
+	t.Helper()
+
+	recorded := &recordedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorded.path = r.URL.Path
+		recorded.headers = r.Header.Clone()
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&recorded.body))
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(reply))
+	}))
+	t.Cleanup(server.Close)
+	return server, recorded
+}
+
+func TestOpenAIClient(t *testing.T) {
+	server, recorded := newEndpoint(t, http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":"Entry point"}}]}`)
+
+	client, err := suggest.NewClient(suggest.Config{Provider: suggest.ProviderOpenAI, Endpoint: server.URL + "/v1", Model: "m", APIKey: "key"}, nil)
+	require.NoError(t, err)
+
+	reply, err := client.Complete(context.Background(), "describe main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "Entry point", reply)
+
+	assert.Equal(t, "/v1/chat/completions", recorded.path)
+	assert.Equal(t, "Bearer key", recorded.headers.Get("Authorization"))
+	assert.Equal(t, "m", recorded.body["model"])
+	messages := recorded.body["messages"].([]interface{})
+	assert.Equal(t, "describe main.go", messages[0].(map[string]interface{})["content"])
+}
+
+func TestAnthropicClient(t *testing.T) {
+	server, recorded := newEndpoint(t, http.StatusOK, `{"content":[{"type":"text","text":"Entry point"}]}`)
+
+	client, err := suggest.NewClient(suggest.Config{Provider: suggest.ProviderAnthropic, Endpoint: server.URL, Model: "m", APIKey: "key"}, nil)
+	require.NoError(t, err)
+
+	reply, err := client.Complete(context.Background(), "describe main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "Entry point", reply)
+
+	assert.Equal(t, "/v1/messages", recorded.path)
+	assert.Equal(t, "key", recorded.headers.Get("x-api-key"))
+	assert.NotEmpty(t, recorded.headers.Get("anthropic-version"))
+	assert.Equal(t, "m", recorded.body["model"])
+}
+
+func TestClientErrors(t *testing.T) {
+	t.Run("error status", func(t *testing.T) {
+		server, _ := newEndpoint(t, http.StatusUnauthorized, `{"error":"bad key"}`)
+		client, err := suggest.NewClient(suggest.Config{Provider: suggest.ProviderOpenAI, Endpoint: server.URL, Model: "m"}, nil)
+		require.NoError(t, err)
+
+		_, err = client.Complete(context.Background(), "prompt")
+		assert.ErrorContains(t, err, "401")
+		assert.ErrorContains(t, err, "bad key")
+	})
+
+	t.Run("empty reply", func(t *testing.T) {
+		server, _ := newEndpoint(t, http.StatusOK, `{"choices":[]}`)
+		client, err := suggest.NewClient(suggest.Config{Provider: suggest.ProviderOpenAI, Endpoint: server.URL, Model: "m"}, nil)
+		require.NoError(t, err)
+
+		_, err = client.Complete(context.Background(), "prompt")
+		assert.ErrorContains(t, err, "empty response")
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		_, err := suggest.NewClient(suggest.Config{Provider: "other"}, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,101 @@
+// Package suggest proposes annotations for unannotated paths using a language model
+// behind an OpenAI or Anthropic compatible HTTP endpoint.
+//
+// The package only produces suggestions; reviewing them and writing accepted ones to
+// .info files is left to the caller (the "treex suggest" command).
+package suggest
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+	"treex/treex/pathutil"
+)
+
+// Provider selects the wire protocol used to talk to the endpoint
+type Provider string
+
+const (
+	// ProviderOpenAI speaks the chat completions API (OpenAI, and compatible local servers)
+	ProviderOpenAI Provider = "openai"
+	// ProviderAnthropic speaks the Messages API
+	ProviderAnthropic Provider = "anthropic"
+)
+
+// Default endpoints used when none is configured
+const (
+	DefaultOpenAIEndpoint    = "https://api.openai.com/v1"
+	DefaultAnthropicEndpoint = "https://api.anthropic.com"
+)
+
+// Config selects the endpoint and model used for suggestions
+type Config struct {
+	Provider Provider `yaml:"provider"` // Wire protocol (default openai)
+	Endpoint string   `yaml:"endpoint"` // Base URL of the API
+	Model    string   `yaml:"model"`    // Model name sent with every request (required)
+	APIKey   string   `yaml:"api_key"`  // API key; may be empty for local servers
+}
+
+// DefaultConfigPath returns the suggest configuration file in the XDG config directory
+func DefaultConfigPath() string {
+	return pathutil.ConfigPath("suggest.yaml")
+}
+
+// LoadConfig reads the configuration file at path (a missing file is fine), then applies
+// the environment: TREEX_SUGGEST_PROVIDER, TREEX_SUGGEST_ENDPOINT, TREEX_SUGGEST_MODEL and
+// TREEX_SUGGEST_API_KEY, falling back to OPENAI_API_KEY or ANTHROPIC_API_KEY for the key
+func LoadConfig(fs afero.Fs, path string, getenv func(string) string) (Config, error) {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+
+	var config Config
+	if content, err := afero.ReadFile(fs, path); err == nil {
+		if err := yaml.Unmarshal(content, &config); err != nil {
+			return Config{}, fmt.Errorf("invalid suggest config %s: %w", path, err)
+		}
+	} else if exists, _ := afero.Exists(fs, path); exists {
+		return Config{}, fmt.Errorf("failed to read suggest config %s: %w", path, err)
+	}
+
+	override := func(target *string, name string) {
+		if value := strings.TrimSpace(getenv(name)); value != "" {
+			*target = value
+		}
+	}
+	provider := string(config.Provider)
+	override(&provider, "TREEX_SUGGEST_PROVIDER")
+	override(&config.Endpoint, "TREEX_SUGGEST_ENDPOINT")
+	override(&config.Model, "TREEX_SUGGEST_MODEL")
+	override(&config.APIKey, "TREEX_SUGGEST_API_KEY")
+
+	config.Provider = Provider(strings.ToLower(provider))
+	switch config.Provider {
+	case "", ProviderOpenAI:
+		config.Provider = ProviderOpenAI
+		if config.Endpoint == "" {
+			config.Endpoint = DefaultOpenAIEndpoint
+		}
+		if config.APIKey == "" {
+			config.APIKey = getenv("OPENAI_API_KEY")
+		}
+	case ProviderAnthropic:
+		if config.Endpoint == "" {
+			config.Endpoint = DefaultAnthropicEndpoint
+		}
+		if config.APIKey == "" {
+			config.APIKey = getenv("ANTHROPIC_API_KEY")
+		}
+	default:
+		return Config{}, fmt.Errorf("unknown provider %q (expected openai or anthropic)", provider)
+	}
+
+	if config.Model == "" {
+		return Config{}, fmt.Errorf("no model configured: set TREEX_SUGGEST_MODEL or model in %s", path)
+	}
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+	return config, nil
+}
@@ -0,0 +1,68 @@
+package suggest_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/suggest"
+)
+
+// envOf returns a getenv function over a fixed environment
+func envOf(env map[string]string) func(string) string {
+	return func(name string) string { return env[name] }
+}
+
+func TestLoadConfig(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/config", map[string]interface{}{
+		"suggest.yaml": "provider: anthropic\nmodel: file-model\napi_key: file-key\n",
+		"invalid.yaml": "model: [unclosed\n",
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		env      map[string]string
+		expected suggest.Config
+		err      string
+	}{
+		{
+			name:     "file",
+			path:     "/config/suggest.yaml",
+			expected: suggest.Config{Provider: suggest.ProviderAnthropic, Endpoint: suggest.DefaultAnthropicEndpoint, Model: "file-model", APIKey: "file-key"},
+		},
+		{
+			name: "environment overrides file",
+			path: "/config/suggest.yaml",
+			env: map[string]string{
+				"TREEX_SUGGEST_PROVIDER": "OpenAI",
+				"TREEX_SUGGEST_ENDPOINT": "http://localhost:11434/v1/",
+				"TREEX_SUGGEST_MODEL":    "env-model",
+			},
+			expected: suggest.Config{Provider: suggest.ProviderOpenAI, Endpoint: "http://localhost:11434/v1", Model: "env-model", APIKey: "file-key"},
+		},
+		{
+			name:     "provider key fallback",
+			path:     "/config/missing.yaml",
+			env:      map[string]string{"TREEX_SUGGEST_MODEL": "m", "OPENAI_API_KEY": "sk-openai"},
+			expected: suggest.Config{Provider: suggest.ProviderOpenAI, Endpoint: suggest.DefaultOpenAIEndpoint, Model: "m", APIKey: "sk-openai"},
+		},
+		{name: "model required", path: "/config/missing.yaml", err: "no model configured"},
+		{name: "unknown provider", path: "/config/missing.yaml", env: map[string]string{"TREEX_SUGGEST_PROVIDER": "other", "TREEX_SUGGEST_MODEL": "m"}, err: `unknown provider "other"`},
+		{name: "invalid file", path: "/config/invalid.yaml", err: "invalid suggest config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := suggest.LoadConfig(fs, tt.path, envOf(tt.env))
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, config)
+		})
+	}
+}
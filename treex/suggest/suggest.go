@@ -0,0 +1,177 @@
+package suggest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/afero"
+	"treex/treex/pathutil"
+	"treex/treex/types"
+)
+
+// Defaults for sampling paths
+const (
+	DefaultSampleBytes = 2048 // Leading bytes of a file included in its prompt
+	maxListedEntries   = 30   // Directory entries listed in a directory prompt
+	maxAnnotationRunes = 120  // Longer replies are cut at a word boundary
+)
+
+// Suggestion is a proposed annotation for a path relative to the tree root
+type Suggestion struct {
+	Path       string `json:"path"`
+	IsDir      bool   `json:"is_dir"`
+	Annotation string `json:"annotation"`
+}
+
+// Candidates returns the unannotated nodes below root in tree order, at most limit of
+// them (0 = no limit). The root itself and .info files are never candidates.
+func Candidates(root *types.Node, limit int) []*types.Node {
+	var candidates []*types.Node
+	var walk func(node *types.Node) bool
+	walk = func(node *types.Node) bool {
+		for _, child := range node.Children {
+			if limit > 0 && len(candidates) >= limit {
+				return false
+			}
+			if child.Name == ".info" {
+				continue
+			}
+			if annotation := child.GetAnnotation(); annotation == nil || annotation.Notes == "" {
+				candidates = append(candidates, child)
+			}
+			if !walk(child) {
+				return false
+			}
+		}
+		return true
+	}
+	if root != nil {
+		walk(root)
+	}
+	return candidates
+}
+
+// Generator asks a model for annotations of nodes read from a filesystem
+type Generator struct {
+	Client      Client
+	Filesystem  afero.Fs // Filesystem where node paths resolve (rooted at the tree root)
+	SampleBytes int      // Leading bytes of a file sent to the model (0 = DefaultSampleBytes)
+}
+
+// Suggest proposes an annotation for node
+func (g *Generator) Suggest(ctx context.Context, node *types.Node) (Suggestion, error) {
+	prompt, err := g.Prompt(node)
+	if err != nil {
+		return Suggestion{}, err
+	}
+
+	reply, err := g.Client.Complete(ctx, prompt)
+	if err != nil {
+		return Suggestion{}, err
+	}
+
+	annotation := CleanReply(reply)
+	if annotation == "" {
+		return Suggestion{}, fmt.Errorf("no annotation suggested for %s", node.Path)
+	}
+	return Suggestion{Path: pathutil.Normalize(node.Path), IsDir: node.IsDir, Annotation: annotation}, nil
+}
+
+// Prompt builds the request for node: file prompts carry a sample of the content,
+// directory prompts list the entries (with their annotations when known)
+func (g *Generator) Prompt(node *types.Node) (string, error) {
+	var b strings.Builder
+	kind := "file"
+	if node.IsDir {
+		kind = "directory"
+	}
+	fmt.Fprintf(&b, "You write annotations for a project tree viewer. Describe the purpose of the %s %q ", kind, pathutil.Normalize(node.Path))
+	b.WriteString("in one line of at most 80 characters. Reply with the annotation only, without quotes or a trailing period.\n\n")
+
+	if node.IsDir {
+		b.WriteString("Entries:\n")
+		for i, child := range node.Children {
+			if i == maxListedEntries {
+				fmt.Fprintf(&b, "... and %d more\n", len(node.Children)-i)
+				break
+			}
+			name := child.Name
+			if child.IsDir {
+				name += "/"
+			}
+			if annotation := child.GetAnnotation(); annotation != nil && annotation.Notes != "" {
+				name += "  " + annotation.Notes
+			}
+			b.WriteString(name + "\n")
+		}
+		return b.String(), nil
+	}
+
+	sample, size, err := g.sample(node.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", node.Path, err)
+	}
+	if bytes.IndexByte(sample, 0) >= 0 {
+		fmt.Fprintf(&b, "The file is binary (%d bytes).\n", size)
+		return b.String(), nil
+	}
+	if int64(len(sample)) < size {
+		fmt.Fprintf(&b, "First %d of %d bytes:\n", len(sample), size)
+	} else {
+		b.WriteString("Content:\n")
+	}
+	b.WriteString("```\n" + strings.ToValidUTF8(string(sample), "") + "\n```\n")
+	return b.String(), nil
+}
+
+// sample reads the leading bytes of a file and reports its size
+func (g *Generator) sample(path string) ([]byte, int64, error) {
+	limit := g.SampleBytes
+	if limit <= 0 {
+		limit = DefaultSampleBytes
+	}
+
+	file, err := g.Filesystem.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	content, err := io.ReadAll(io.LimitReader(file, int64(limit)))
+	if err != nil {
+		return nil, 0, err
+	}
+	return content, info.Size(), nil
+}
+
+// CleanReply turns a model reply into a single-line annotation: the first non-empty
+// line, without surrounding quotes, backticks or a trailing period
+func CleanReply(reply string) string {
+	var line string
+	for _, candidate := range strings.Split(reply, "\n") {
+		if candidate = strings.TrimSpace(candidate); candidate != "" && !strings.HasPrefix(candidate, "```") {
+			line = candidate
+			break
+		}
+	}
+
+	line = strings.Trim(line, "\"'`")
+	line = strings.TrimSuffix(strings.TrimSpace(line), ".")
+	line = strings.Join(strings.Fields(line), " ")
+
+	if runes := []rune(line); len(runes) > maxAnnotationRunes {
+		cut := string(runes[:maxAnnotationRunes])
+		if space := strings.LastIndex(cut, " "); space > 0 {
+			cut = cut[:space]
+		}
+		line = cut
+	}
+	return line
+}
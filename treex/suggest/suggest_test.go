@@ -0,0 +1,130 @@
+package suggest_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/suggest"
+	"treex/treex/types"
+)
+
+// fakeClient returns a fixed reply and records the prompts it received
+type fakeClient struct {
+	reply   string
+	prompts []string
+}
+
+func (c *fakeClient) Complete(_ context.Context, prompt string) (string, error) {
+	c.prompts = append(c.prompts, prompt)
+	return c.reply, nil
+}
+
+// newNode creates a node and links it under parent
+func newNode(parent *types.Node, name string, isDir bool, notes string) *types.Node {
+	node := &types.Node{Name: name, Path: name, IsDir: isDir, Parent: parent}
+	if parent != nil {
+		if parent.Path != "." {
+			node.Path = parent.Path + "/" + name
+		}
+		parent.Children = append(parent.Children, node)
+	}
+	if notes != "" {
+		node.SetAnnotation(&types.Annotation{Path: node.Path, Notes: notes})
+	}
+	return node
+}
+
+func TestCandidates(t *testing.T) {
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	newNode(root, ".info", false, "")
+	newNode(root, "README.md", false, "Project overview")
+	src := newNode(root, "src", true, "")
+	newNode(src, "main.go", false, "")
+	newNode(src, "util.go", false, "Helpers")
+	newNode(root, "go.mod", false, "")
+
+	paths := func(nodes []*types.Node) []string {
+		var result []string
+		for _, node := range nodes {
+			result = append(result, node.Path)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"src", "src/main.go", "go.mod"}, paths(suggest.Candidates(root, 0)))
+	assert.Equal(t, []string{"src", "src/main.go"}, paths(suggest.Candidates(root, 2)))
+	assert.Empty(t, suggest.Candidates(nil, 0))
+}
+
+func TestGeneratorPrompts(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"src": map[string]interface{}{
+			"main.go":  "package main\n\nfunc main() {}\n",
+			"logo.png": "\x89PNG\x00\x00",
+		},
+	})
+
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	src := newNode(root, "src", true, "")
+	mainGo := newNode(src, "main.go", false, "")
+	logo := newNode(src, "logo.png", false, "Logo")
+
+	generator := &suggest.Generator{Client: &fakeClient{}, Filesystem: afero.NewBasePathFs(fs, "/project"), SampleBytes: 12}
+
+	prompt, err := generator.Prompt(mainGo)
+	require.NoError(t, err)
+	assert.Contains(t, prompt, `file "src/main.go"`)
+	assert.Contains(t, prompt, "First 12 of 29 bytes:\n```\npackage main\n```")
+
+	prompt, err = generator.Prompt(logo)
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "The file is binary (6 bytes)")
+
+	prompt, err = generator.Prompt(src)
+	require.NoError(t, err)
+	assert.Contains(t, prompt, `directory "src"`)
+	assert.Contains(t, prompt, "Entries:\nmain.go\nlogo.png  Logo\n")
+
+	_, err = generator.Prompt(newNode(src, "missing.go", false, ""))
+	assert.ErrorContains(t, err, "failed to read src/missing.go")
+}
+
+func TestGeneratorSuggest(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{"main.go": "package main"})
+
+	root := &types.Node{Name: "project", Path: ".", IsDir: true}
+	mainGo := newNode(root, "main.go", false, "")
+
+	client := &fakeClient{reply: "\"Program entry point.\"\n"}
+	generator := &suggest.Generator{Client: client, Filesystem: afero.NewBasePathFs(fs, "/project")}
+
+	suggestion, err := generator.Suggest(context.Background(), mainGo)
+	require.NoError(t, err)
+	assert.Equal(t, suggest.Suggestion{Path: "main.go", Annotation: "Program entry point"}, suggestion)
+	assert.Len(t, client.prompts, 1)
+
+	client.reply = "   \n"
+	_, err = generator.Suggest(context.Background(), mainGo)
+	assert.ErrorContains(t, err, "no annotation suggested")
+}
+
+func TestCleanReply(t *testing.T) {
+	tests := map[string]string{
+		"Entry point":                         "Entry point",
+		"  `Entry point.`  ":                  "Entry point",
+		"\n\nFirst line\nSecond line":         "First line",
+		"```\nFenced   reply\n```":            "Fenced reply",
+		"'Single quoted'":                     "Single quoted",
+		strings.Repeat("word ", 40) + "tail.": strings.TrimSpace(strings.Repeat("word ", 24)),
+	}
+	for reply, expected := range tests {
+		assert.Equal(t, expected, suggest.CleanReply(reply), "reply %q", reply)
+	}
+}
@@ -2,12 +2,21 @@
 package treex
 
 import (
+	"context"
 	"path/filepath"
+	"regexp"
+	"time"
 
 	"github.com/spf13/afero"
+	"treex/treex/expand"
 	"treex/treex/pathcollection"
+	"treex/treex/pathutil"
 	"treex/treex/pattern"
 	"treex/treex/plugins"
+	"treex/treex/plugins/infofile"
+	"treex/treex/plugins/project"
+	"treex/treex/query"
+	"treex/treex/secrets"
 	"treex/treex/treeconstruction"
 	"treex/treex/types"
 )
@@ -23,20 +32,76 @@ type TreeConfig struct {
 	// Basic options (start simple as instructed)
 	MaxDepth int // Maximum depth to traverse (0 = no limit)
 
+	// DepthOverrides limits the depth shown below specific directories (slash-separated
+	// paths relative to Root), counted from the directory. Directories can also limit
+	// themselves with a "# treex:max-depth=N" line in their .info file; the tightest
+	// limit applies.
+	DepthOverrides map[string]int
+
 	// Path filtering options (added incrementally)
 	// Multiple exclusion mechanisms work together:
 	// 1. BuiltinIgnores - default patterns for VCS/build artifacts (can be disabled)
 	// 2. ExcludeGlobs - user-specified patterns via --exclude
 	// 3. Gitignore files - .gitignore pattern support
-	// 4. IncludeHidden - hidden file visibility control
+	// 4. IncludeHidden - hidden file visibility control (annotated hidden files always show)
 	// 5. PluginFilters - filter by plugin categories (e.g., --git-staged, --info-annotated)
 	BuiltinIgnores  bool                       // Whether to apply built-in ignore patterns (default: true)
 	ExcludeGlobs    []string                   // User-specified exclude patterns
 	IncludeHidden   bool                       // Whether to include hidden files (default: true)
+	HiddenOnly      bool                       // Show only hidden files and files below hidden directories
 	DirectoriesOnly bool                       // Whether to show directories only (default: false)
+	FilesOnly       bool                       // Show files, with only the directories leading to them
 	PluginFilters   map[string]map[string]bool // Plugin category filters: plugin -> category -> enabled
+
+	// Presets names the ignore sets of languages and package managers to apply, e.g.
+	// "node" or "go" (see pattern.Presets); annotated paths stay visible
+	Presets []string
+
+	// Paths restricts the tree to these paths (slash-separated, relative to Root) and
+	// their ancestor directories, e.g. the output of git ls-files (nil = no restriction).
+	// The other filters still apply to the listed paths.
+	Paths []string
+
+	// AnnotationFilter prunes the tree to the nodes whose annotation matches, plus their
+	// ancestor directories (nil = no pruning)
+	AnnotationFilter *regexp.Regexp
+
+	// Select prunes the tree to the nodes matching the query, plus their ancestor
+	// directories (nil = no pruning); see query.ParseSelector
+	Select query.Query
+
+	// ProjectsOnly prunes the tree to subproject roots (directories holding go.mod,
+	// package.json, Cargo.toml or pom.xml; see package project) and their ancestors
+	ProjectsOnly bool
+
+	// PruneEmpty removes directories that hold no files once every filter has applied,
+	// like tree --prune
+	PruneEmpty bool
+
+	// Sort orders the children of each directory (empty = byte-wise by name); Locale is
+	// the BCP 47 language collated by treeconstruction.SortLocale
+	Sort   treeconstruction.SortOrder
+	Locale string
+
+	// CaseInsensitive matches paths ignoring case (plugin results, annotations, duplicates)
+	// Defaults to the platform behavior: true on Windows and macOS
+	CaseInsensitive bool
+
+	// Progress receives the number of entries visited during the walk (nil = no reporting)
+	Progress ProgressReporter
+
+	// Expand fills the placeholders of annotation text once it is attached, before
+	// AnnotationFilter matches it (see package expand); nil shows annotations as written
+	Expand *expand.Values
+
+	// Secrets decrypts the sidecars holding annotations marked secret (see package
+	// secrets); nil shows them redacted
+	Secrets secrets.Decrypter
 }
 
+// ProgressReporter receives walk progress during BuildTree; see pathcollection.ProgressReporter
+type ProgressReporter = pathcollection.ProgressReporter
+
 // TreeResult represents the result of tree building operations
 type TreeResult struct {
 	// Root node of the built tree
@@ -45,8 +110,19 @@ type TreeResult struct {
 	// Statistics about the tree building process
 	Stats TreeStats
 
+	// Elapsed is the time building the tree took
+	Elapsed time.Duration
+
 	// Plugin results (if any plugins were applied)
 	PluginResults map[string][]*plugins.Result
+
+	// Partial reports that building was canceled: the tree holds the entries
+	// collected before cancellation and may lack plugin data
+	Partial bool
+
+	// Roots holds the result of each root when Root is the synthetic parent of several
+	// trees (see CombineResults); nil for a single tree
+	Roots []*TreeResult
 }
 
 // TreeStats provides statistics about the tree building process
@@ -55,11 +131,25 @@ type TreeStats struct {
 	TotalDirectories int
 	MaxDepthReached  int
 	FilteredOut      int // Number of files/directories filtered out
+	Errors           int // Directories whose contents could not be read (e.g. permission denied)
+	Annotated        int // Entries below the root with annotation notes
 }
 
 // BuildTree constructs a file tree based on the provided configuration.
 // This is the main tree building function that orchestrates the entire process.
 func BuildTree(config TreeConfig) (*TreeResult, error) {
+	return BuildTreeContext(context.Background(), config)
+}
+
+// BuildTreeContext is BuildTree with cancellation.
+// When ctx is canceled during the walk, the entries collected so far are returned as a
+// Partial result together with ctx's error; cancellation before the walk returns no result.
+func BuildTreeContext(ctx context.Context, config TreeConfig) (*TreeResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
 	// Set default filesystem if not provided
 	if config.Filesystem == nil {
 		config.Filesystem = afero.NewOsFs()
@@ -68,11 +158,20 @@ func BuildTree(config TreeConfig) (*TreeResult, error) {
 	// Phase 1: Pattern Matching - Build composite filter combining multiple exclusion mechanisms
 	// This coordinates: built-in ignores, user excludes, gitignore files, and hidden file filtering
 	var compositeFilter *pattern.CompositeFilter
-	if config.BuiltinIgnores || len(config.ExcludeGlobs) > 0 || !config.IncludeHidden {
-		filterBuilder := pattern.NewFilterBuilder(config.Filesystem)
+	if config.BuiltinIgnores || len(config.Presets) > 0 || len(config.ExcludeGlobs) > 0 || !config.IncludeHidden || config.HiddenOnly || len(config.Paths) > 0 {
+		filterBuilder := pattern.NewFilterBuilder(config.Filesystem).
+			WithCaseInsensitive(config.CaseInsensitive)
+
+		// Annotated paths escape the filters that hide entries by name alone
+		var annotated map[string]bool
+		if len(config.Presets) > 0 || (!config.IncludeHidden && !config.HiddenOnly) {
+			annotated = annotatedPaths(config.Filesystem, config.Root)
+		}
 
-		// 1. Add built-in ignore patterns (VCS dirs, build artifacts, etc.)
+		// 1. Add built-in ignore patterns (VCS dirs, build artifacts, etc.) and the
+		// language presets, which documenting an artifact overrides
 		filterBuilder.AddBuiltinIgnores(config.BuiltinIgnores)
+		filterBuilder.AddPresets(config.Presets, annotated)
 
 		// 2. Add user exclude patterns (--exclude flags)
 		if len(config.ExcludeGlobs) > 0 {
@@ -82,16 +181,37 @@ func BuildTree(config TreeConfig) (*TreeResult, error) {
 		// 3. Add gitignore support (automatic .gitignore detection)
 		filterBuilder.AddGitignore(".gitignore", false) // TODO: Make gitignore configurable
 
-		// 4. Add hidden file filtering (--hidden flag control)
-		filterBuilder.AddHiddenFilter(config.IncludeHidden)
+		// 4. Add hidden file filtering (--hidden and --hidden-only flag control)
+		// Annotated hidden files stay visible: documenting them is a request to show them
+		switch {
+		case config.HiddenOnly:
+			filterBuilder.AddHiddenOnlyFilter()
+		case config.IncludeHidden:
+			filterBuilder.AddHiddenFilter(true)
+		default:
+			filterBuilder.AddHiddenExceptFilter(annotated)
+		}
+
+		// 5. Keep only an explicit path list and its ancestors (show --stdin)
+		if len(config.Paths) > 0 {
+			listed := make(map[string]bool, len(config.Paths))
+			for _, listedPath := range config.Paths {
+				listed[listedPath] = true
+			}
+			filterBuilder.AddPluginFilter(listed)
+		}
 
 		compositeFilter = filterBuilder.Build()
 	}
 
 	// Phase 2: Path Collection - Basic collection with depth limit and optional filtering
 	collector := pathcollection.NewConfigurator(config.Filesystem).
+		WithContext(ctx).
+		WithProgress(config.Progress).
 		WithRoot(config.Root).
-		WithMaxDepth(config.MaxDepth)
+		WithMaxDepth(config.MaxDepth).
+		WithSubtreeDepth(subtreeDepth(config.Filesystem, config.DepthOverrides)).
+		WithCaseInsensitive(config.CaseInsensitive)
 
 	if compositeFilter != nil {
 		collector = collector.WithFilter(compositeFilter)
@@ -105,7 +225,7 @@ func BuildTree(config TreeConfig) (*TreeResult, error) {
 	// Phase 3: Plugin Filtering - Apply plugin filtering during path collection
 	pluginResults := make(map[string][]*plugins.Result)
 	if len(config.PluginFilters) > 0 {
-		pluginFilter, results, err := createPluginFilter(config.Filesystem, config.Root, config.PluginFilters)
+		pluginFilter, results, err := createPluginFilter(ctx, config.Filesystem, config.Root, config.PluginFilters, config.CaseInsensitive)
 		if err != nil {
 			return nil, err
 		}
@@ -116,29 +236,152 @@ func BuildTree(config TreeConfig) (*TreeResult, error) {
 	}
 
 	pathInfos, err := collector.Collect()
-	if err != nil {
+	if err != nil && ctx.Err() == nil {
 		return nil, err
 	}
 
 	// Phase 4: Tree Construction - Build tree structure from collected paths
 	constructor := treeconstruction.NewConstructor()
 	root := constructor.BuildTree(pathInfos)
+	treeconstruction.SortChildren(root, config.Sort, config.Locale)
 
 	// Phase 5: Data Enrichment - Enrich surviving nodes with plugin data
 	// This runs after filtering to avoid expensive operations on filtered-out files
-	err = applyDataEnrichment(config.Filesystem, root, pluginResults)
-	if err != nil {
-		return nil, err
+	if ctx.Err() == nil {
+		err = applyDataEnrichment(ctx, config.Filesystem, config.Root, root, pluginResults, pathutil.NewNormalizer(config.CaseInsensitive))
+		if err != nil && ctx.Err() == nil {
+			return nil, err
+		}
+	}
+
+	// Annotations are revealed and expanded before pruning, so the filter sees the text shown
+	if ctx.Err() == nil {
+		secrets.Tree(config.Filesystem, config.Root, root, config.Secrets)
+	}
+	if config.Expand != nil && ctx.Err() == nil {
+		expand.Tree(root, *config.Expand)
 	}
 
-	// Calculate statistics
-	stats := calculateStats(pathInfos)
+	// Phase 6: Annotation Pruning - Keep matching annotations and their ancestors
+	// Annotations are only known after enrichment, so this cannot happen during the walk
+	if config.AnnotationFilter != nil && ctx.Err() == nil {
+		kept := make(map[string]bool)
+		pruneTree(root, func(node *types.Node) bool {
+			annotation := node.GetAnnotation()
+			return annotation != nil && config.AnnotationFilter.MatchString(annotation.Notes)
+		}, kept)
+		pathInfos = keptPathInfos(pathInfos, kept)
+	}
 
-	return &TreeResult{
+	// Selectors test annotations and plugin data too, so they also wait for enrichment
+	if config.Select != nil && ctx.Err() == nil {
+		kept := make(map[string]bool)
+		pruneTree(root, config.Select.Match, kept)
+		pathInfos = keptPathInfos(pathInfos, kept)
+	}
+
+	// Subproject roots are only known after enrichment, like annotations
+	if config.ProjectsOnly && ctx.Err() == nil {
+		kept := make(map[string]bool)
+		pruneTree(root, func(node *types.Node) bool { return project.Of(node) != nil }, kept)
+		pathInfos = keptPathInfos(pathInfos, kept)
+	}
+
+	// Phase 7: Empty Directory Pruning - Drop directories left without files
+	// Files-only trees keep directories for structure only. Hidden-only trees walk every
+	// directory to reach hidden entries; drop the regular directories that lead to none
+	if (config.PruneEmpty || config.FilesOnly) && ctx.Err() == nil {
+		kept := make(map[string]bool)
+		pruneTree(root, func(node *types.Node) bool { return !node.IsDir }, kept)
+		pathInfos = keptPathInfos(pathInfos, kept)
+	}
+	if config.HiddenOnly && ctx.Err() == nil {
+		kept := make(map[string]bool)
+		pruneTree(root, func(node *types.Node) bool { return pattern.IsHiddenPath(node.Path) }, kept)
+		pathInfos = keptPathInfos(pathInfos, kept)
+	}
+
+	result := &TreeResult{
 		Root:          root,
-		Stats:         stats,
+		Stats:         calculateStats(pathInfos),
 		PluginResults: pluginResults,
-	}, nil
+	}
+	result.Stats.Annotated = countAnnotated(root)
+	result.Elapsed = time.Since(start)
+
+	// A canceled build still yields the tree collected so far
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		result.Partial = true
+		return result, ctxErr
+	}
+	return result, nil
+}
+
+// subtreeDepth returns the per-directory depth limits of DepthOverrides and .info
+// max-depth directives
+func subtreeDepth(fs afero.Fs, overrides map[string]int) func(relativePath, absolutePath string) (int, bool) {
+	normalized := make(map[string]int, len(overrides))
+	for dir, depth := range overrides {
+		normalized[pathutil.Normalize(dir)] = depth
+	}
+
+	return func(relativePath, absolutePath string) (int, bool) {
+		limit, limited := normalized[relativePath]
+		if content, err := afero.ReadFile(fs, filepath.Join(absolutePath, ".info")); err == nil {
+			if depth, ok := infofile.MaxDepth(content); ok && (!limited || depth < limit) {
+				limit, limited = depth, true
+			}
+		}
+		return limit, limited
+	}
+}
+
+// pruneTree removes the nodes below node that neither satisfy keep nor lead to a node
+// that does, recording the paths it keeps; it reports whether node is kept
+func pruneTree(node *types.Node, keep func(*types.Node) bool, kept map[string]bool) bool {
+	if node == nil {
+		return false
+	}
+
+	children := node.Children[:0]
+	for _, child := range node.Children {
+		if pruneTree(child, keep, kept) {
+			children = append(children, child)
+		}
+	}
+	node.Children = children
+
+	if len(children) > 0 || node.Parent == nil || keep(node) {
+		kept[node.Path] = true
+		return true
+	}
+	return false
+}
+
+// annotatedPaths returns the paths annotated in the .info files below root, relative to it
+func annotatedPaths(fs afero.Fs, root string) map[string]bool {
+	annotations, err := infofile.Gather(fs, root)
+	if err != nil {
+		return nil
+	}
+	paths := make(map[string]bool, len(annotations))
+	for annotationPath := range annotations {
+		if rel, err := filepath.Rel(root, annotationPath); err == nil {
+			paths[rel] = true
+		}
+	}
+	return paths
+}
+
+// keptPathInfos returns the collected paths that survived pruning
+func keptPathInfos(pathInfos []pathcollection.PathInfo, kept map[string]bool) []pathcollection.PathInfo {
+	var result []pathcollection.PathInfo
+	for _, pathInfo := range pathInfos {
+		if kept[pathInfo.Path] {
+			result = append(result, pathInfo)
+		}
+	}
+	return result
 }
 
 // calculateStats computes statistics about the collected paths
@@ -152,6 +395,10 @@ func calculateStats(pathInfos []pathcollection.PathInfo) TreeStats {
 			stats.TotalFiles++
 		}
 
+		if pathInfo.Error != "" {
+			stats.Errors++
+		}
+
 		if pathInfo.Depth > stats.MaxDepthReached {
 			stats.MaxDepthReached = pathInfo.Depth
 		}
@@ -160,9 +407,24 @@ func calculateStats(pathInfos []pathcollection.PathInfo) TreeStats {
 	return stats
 }
 
+// countAnnotated counts the descendants of node that have annotation notes
+func countAnnotated(node *types.Node) int {
+	if node == nil {
+		return 0
+	}
+	count := 0
+	for _, child := range node.Children {
+		if isAnnotated(child) {
+			count++
+		}
+		count += countAnnotated(child)
+	}
+	return count
+}
+
 // createPluginFilter creates a filter that includes only paths matching plugin categories
 // Returns the filter and plugin results for metadata
-func createPluginFilter(fs afero.Fs, rootPath string, pluginFilters map[string]map[string]bool) (*pattern.CompositeFilter, map[string][]*plugins.Result, error) {
+func createPluginFilter(ctx context.Context, fs afero.Fs, rootPath string, pluginFilters map[string]map[string]bool, caseInsensitive bool) (*pattern.CompositeFilter, map[string][]*plugins.Result, error) {
 	registry := plugins.GetDefaultRegistry()
 	pluginResults := make(map[string][]*plugins.Result)
 	allowedPaths := make(map[string]bool)
@@ -182,6 +444,10 @@ func createPluginFilter(fs afero.Fs, rootPath string, pluginFilters map[string]m
 
 		// Process each root
 		for _, pluginRoot := range roots {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+
 			// Make plugin root absolute by joining with search root
 			var absolutePluginRoot string
 			if pluginRoot != "." {
@@ -218,7 +484,7 @@ func createPluginFilter(fs afero.Fs, rootPath string, pluginFilters map[string]m
 	}
 
 	// Create a filter that only allows matching paths and their parent directories
-	filterBuilder := pattern.NewFilterBuilder(fs)
+	filterBuilder := pattern.NewFilterBuilder(fs).WithCaseInsensitive(caseInsensitive)
 	filterBuilder.AddPluginFilter(allowedPaths)
 	pluginFilter := filterBuilder.Build()
 
@@ -229,7 +495,7 @@ func createPluginFilter(fs afero.Fs, rootPath string, pluginFilters map[string]m
 // Runs through all registered DataPlugin implementations and enriches matching nodes
 // Uses cached plugin results when available to avoid expensive re-computation
 // Supports both legacy DataPlugin and new DataPluginV2 interfaces during transition
-func applyDataEnrichment(fs afero.Fs, root *types.Node, pluginResults map[string][]*plugins.Result) error {
+func applyDataEnrichment(ctx context.Context, fs afero.Fs, rootPath string, root *types.Node, pluginResults map[string][]*plugins.Result, normalizer *pathutil.Normalizer) error {
 	if root == nil {
 		return nil
 	}
@@ -262,10 +528,13 @@ func applyDataEnrichment(fs afero.Fs, root *types.Node, pluginResults map[string
 	}
 
 	// Apply new DataPluginV2 enrichment using batch processing
-	err := applyDataPluginV2Enrichment(fs, root, dataPluginsV2, pluginResults)
+	err := applyDataPluginV2Enrichment(ctx, fs, rootPath, root, dataPluginsV2, pluginResults, normalizer)
 	if err != nil {
 		return err
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	// Apply legacy DataPlugin enrichment (recursive per-node)
 	return enrichNodeRecursively(fs, root, dataPlugins, cachedDataPlugins, pluginResults)
@@ -273,7 +542,7 @@ func applyDataEnrichment(fs afero.Fs, root *types.Node, pluginResults map[string
 
 // applyDataPluginV2Enrichment applies enrichment using the new map-based DataPluginV2 interface
 // This is more efficient as it processes all nodes in batch rather than per-node
-func applyDataPluginV2Enrichment(fs afero.Fs, root *types.Node, dataPluginsV2 []plugins.DataPluginV2, pluginResults map[string][]*plugins.Result) error {
+func applyDataPluginV2Enrichment(ctx context.Context, fs afero.Fs, rootPath string, root *types.Node, dataPluginsV2 []plugins.DataPluginV2, pluginResults map[string][]*plugins.Result, normalizer *pathutil.Normalizer) error {
 	if root == nil || len(dataPluginsV2) == 0 {
 		return nil
 	}
@@ -286,6 +555,9 @@ func applyDataPluginV2Enrichment(fs afero.Fs, root *types.Node, dataPluginsV2 []
 
 	// Process each DataPluginV2
 	for _, dataPlugin := range dataPluginsV2 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		pluginName := dataPlugin.Name()
 
 		// Build cache from plugin results for this plugin
@@ -299,8 +571,8 @@ func applyDataPluginV2Enrichment(fs afero.Fs, root *types.Node, dataPluginsV2 []
 			}
 		}
 
-		// Get enrichment data for all paths at once
-		enrichmentData, err := dataPlugin.EnrichData(fs, ".", allPaths, cache)
+		// Get enrichment data for all paths at once; node paths are relative to the tree root
+		enrichmentData, err := dataPlugin.EnrichData(fs, rootPath, allPaths, cache)
 		if err != nil {
 			// Log error but continue with other plugins
 			// TODO: Add proper logging when available
@@ -308,7 +580,7 @@ func applyDataPluginV2Enrichment(fs afero.Fs, root *types.Node, dataPluginsV2 []
 		}
 
 		// Apply the enrichment data to the corresponding nodes
-		err = applyEnrichmentDataToNodes(root, pluginName, enrichmentData)
+		err = applyEnrichmentDataToNodes(root, pluginName, enrichmentData, normalizer)
 		if err != nil {
 			// Log error but continue with other plugins
 			// TODO: Add proper logging when available
@@ -344,28 +616,34 @@ func walkTreeForPaths(node *types.Node, paths *[]string) {
 }
 
 // applyEnrichmentDataToNodes applies enrichment data from a plugin to the corresponding nodes
-func applyEnrichmentDataToNodes(root *types.Node, pluginName string, enrichmentData plugins.DataEnrichmentMap) error {
+// Plugin paths are matched through the normalizer so separator and case differences still resolve
+func applyEnrichmentDataToNodes(root *types.Node, pluginName string, enrichmentData plugins.DataEnrichmentMap, normalizer *pathutil.Normalizer) error {
 	if root == nil || len(enrichmentData) == 0 {
 		return nil
 	}
 
-	return applyEnrichmentRecursively(root, pluginName, enrichmentData)
+	keyedData := make(map[string]interface{}, len(enrichmentData))
+	for dataPath, data := range enrichmentData {
+		keyedData[normalizer.Key(dataPath)] = data
+	}
+
+	return applyEnrichmentRecursively(root, pluginName, keyedData, normalizer)
 }
 
 // applyEnrichmentRecursively recursively applies enrichment data to nodes
-func applyEnrichmentRecursively(node *types.Node, pluginName string, enrichmentData plugins.DataEnrichmentMap) error {
+func applyEnrichmentRecursively(node *types.Node, pluginName string, keyedData map[string]interface{}, normalizer *pathutil.Normalizer) error {
 	if node == nil {
 		return nil
 	}
 
 	// Check if we have enrichment data for this node
-	if data, exists := enrichmentData[node.Path]; exists {
+	if data, exists := keyedData[normalizer.Key(node.Path)]; exists {
 		node.SetPluginData(pluginName, data)
 	}
 
 	// Recursively apply to children
 	for _, child := range node.Children {
-		err := applyEnrichmentRecursively(child, pluginName, enrichmentData)
+		err := applyEnrichmentRecursively(child, pluginName, keyedData, normalizer)
 		if err != nil {
 			return err
 		}
@@ -429,12 +707,13 @@ func enrichNodeRecursively(fs afero.Fs, node *types.Node, dataPlugins []plugins.
 func DefaultTreeConfig(root string) TreeConfig {
 	return TreeConfig{
 		Root:            root,
-		Filesystem:      nil,                              // Will use OS filesystem
-		MaxDepth:        0,                                // No depth limit
-		BuiltinIgnores:  true,                             // Enable built-in ignores by default (.git, node_modules, etc.)
-		ExcludeGlobs:    []string{},                       // No user excludes by default
-		IncludeHidden:   true,                             // Show hidden files by default (as per options.txt)
-		DirectoriesOnly: false,                            // Show both files and directories by default
-		PluginFilters:   make(map[string]map[string]bool), // No plugin filters by default
+		Filesystem:      nil,                               // Will use OS filesystem
+		MaxDepth:        0,                                 // No depth limit
+		BuiltinIgnores:  true,                              // Enable built-in ignores by default (.git, node_modules, etc.)
+		ExcludeGlobs:    []string{},                        // No user excludes by default
+		IncludeHidden:   true,                              // Show hidden files by default (as per options.txt)
+		DirectoriesOnly: false,                             // Show both files and directories by default
+		PluginFilters:   make(map[string]map[string]bool),  // No plugin filters by default
+		CaseInsensitive: pathutil.DefaultCaseInsensitive(), // Follow the platform's filesystem semantics
 	}
 }
@@ -5,7 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"treex/treex/internal/testutil"
+	"treex/internal/testutil"
 	_ "treex/treex/plugins/infofile" // Import for plugin registration
 	"treex/treex/types"
 )
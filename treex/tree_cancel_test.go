@@ -0,0 +1,67 @@
+package treex
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+)
+
+func TestBuildTreeContextCanceledBeforeWalk(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{"a.txt": "content"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	config := DefaultTreeConfig("/project")
+	config.Filesystem = fs
+	result, err := BuildTreeContext(ctx, config)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, result)
+}
+
+func TestBuildTreeContextCanceledDuringWalk(t *testing.T) {
+	memFs := testutil.NewTestFS()
+	memFs.MustCreateTree("/project", map[string]interface{}{
+		"a": map[string]interface{}{"a.txt": "content"},
+		"b": map[string]interface{}{"b.txt": "content"},
+		"c": map[string]interface{}{"c.txt": "content"},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultTreeConfig("/project")
+	config.Filesystem = &testutil.OpenHookFS{Fs: memFs, OnOpen: func(name string) {
+		if name == "/project/b" {
+			cancel()
+		}
+	}}
+
+	result, err := BuildTreeContext(ctx, config)
+	require.ErrorIs(t, err, context.Canceled)
+	require.NotNil(t, result)
+	assert.True(t, result.Partial)
+
+	var names []string
+	for _, child := range result.Root.Children {
+		names = append(names, child.Name)
+	}
+	assert.Equal(t, []string{"a", "b"}, names)
+	assert.Equal(t, 1, result.Stats.TotalFiles)
+}
+
+func TestBuildTreeContextCompletes(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{"a.txt": "content"})
+
+	config := DefaultTreeConfig("/project")
+	config.Filesystem = fs
+	result, err := BuildTreeContext(context.Background(), config)
+	require.NoError(t, err)
+	assert.False(t, result.Partial)
+	assert.Len(t, result.Root.Children, 1)
+}
@@ -6,7 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"treex/treex/internal/testutil"
+	"treex/internal/testutil"
 	_ "treex/treex/plugins/infofile" // Import for plugin registration
 	"treex/treex/types"
 )
@@ -144,6 +144,29 @@ func TestDataPluginV2FreshDataPath(t *testing.T) {
 	}
 }
 
+func TestDataPluginV2AbsoluteRootWithoutFilters(t *testing.T) {
+	// Without plugin filters nothing is cached, so annotations are gathered fresh from the
+	// tree root rather than the working directory
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/elsewhere/project", map[string]interface{}{
+		".info": "src  Sources\nsrc/main.go  Entry point\n",
+		"src":   map[string]interface{}{"main.go": "package main"},
+	})
+
+	config := DefaultTreeConfig("/elsewhere/project")
+	config.Filesystem = fs
+	result, err := BuildTree(config)
+	require.NoError(t, err)
+
+	notes := make(map[string]string)
+	walkTree(result.Root, func(node *types.Node) {
+		if annotation := node.GetAnnotation(); annotation != nil {
+			notes[node.Path] = annotation.Notes
+		}
+	})
+	assert.Equal(t, map[string]string{"src": "Sources", "src/main.go": "Entry point"}, notes)
+}
+
 func TestDataPluginV2BatchProcessing(t *testing.T) {
 	// Create test filesystem with many files to test batch processing efficiency
 	fs := testutil.NewTestFS()
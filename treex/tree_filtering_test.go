@@ -1,12 +1,14 @@
 package treex
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"treex/treex/internal/testutil"
+	"treex/internal/testutil"
 	_ "treex/treex/plugins/infofile" // Import for plugin registration
+	"treex/treex/query"
 	"treex/treex/types"
 )
 
@@ -106,3 +108,282 @@ func collectFileNames(node *types.Node) []string {
 
 	return files
 }
+
+func TestTreeBuildingWithDepthOverrides(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"vendor": map[string]interface{}{
+			".info": "# treex:max-depth=1\nlib  Vendored library\n",
+			"lib":   map[string]interface{}{"lib.go": "package lib"},
+		},
+		"third_party": map[string]interface{}{"pkg": map[string]interface{}{"pkg.go": "package pkg"}},
+		"src":         map[string]interface{}{"app": map[string]interface{}{"main.go": "package main"}},
+	})
+
+	config := DefaultTreeConfig("/project")
+	config.Filesystem = fs
+	config.DepthOverrides = map[string]int{"third_party/": 0}
+	result, err := BuildTree(config)
+	require.NoError(t, err)
+
+	var paths []string
+	walkTree(result.Root, func(node *types.Node) { paths = append(paths, node.Path) })
+	assert.ElementsMatch(t, []string{
+		".", "src", "src/app", "src/app/main.go",
+		"third_party",
+		"vendor", "vendor/.info", "vendor/lib",
+	}, paths)
+
+	var lib *types.Node
+	walkTree(result.Root, func(node *types.Node) {
+		if node.Path == "vendor/lib" {
+			lib = node
+		}
+	})
+	require.NotNil(t, lib)
+	assert.Equal(t, "Vendored library", lib.GetAnnotation().Notes, "limited directories keep their annotations")
+}
+
+func TestTreeBuildingWithPathList(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info": "src/main.go  Entry point\n",
+		"src": map[string]interface{}{
+			"main.go":  "package main",
+			"extra.go": "package main",
+		},
+		"docs":      map[string]interface{}{"guide.md": "# Guide"},
+		"README.md": "# Project",
+	})
+
+	config := DefaultTreeConfig("/project")
+	config.Filesystem = fs
+	config.Paths = []string{"src/main.go", "README.md"}
+	result, err := BuildTree(config)
+	require.NoError(t, err)
+
+	var paths []string
+	var main *types.Node
+	walkTree(result.Root, func(node *types.Node) {
+		paths = append(paths, node.Path)
+		if node.Path == "src/main.go" {
+			main = node
+		}
+	})
+	assert.ElementsMatch(t, []string{".", "README.md", "src", "src/main.go"}, paths)
+	require.NotNil(t, main)
+	assert.Equal(t, "Entry point", main.GetAnnotation().Notes, "listed paths keep their annotations")
+}
+
+func TestTreeBuildingWithAnnotationFilter(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info": "legacy  Deprecated: replaced by core\ncore  Current implementation\n",
+		"legacy": map[string]interface{}{
+			".info":   "old.go  Old entry point\nshim.go  deprecated shim\n",
+			"old.go":  "package legacy",
+			"shim.go": "package legacy",
+		},
+		"core": map[string]interface{}{
+			".info":  "api.go  Deprecated API, use v2\n",
+			"api.go": "package core",
+			"v2.go":  "package core",
+		},
+		"README.md": "# Project",
+	})
+
+	config := DefaultTreeConfig("/project")
+	config.Filesystem = fs
+	config.AnnotationFilter = regexp.MustCompile("(?i)deprecated")
+	result, err := BuildTree(config)
+	require.NoError(t, err)
+
+	var paths []string
+	walkTree(result.Root, func(node *types.Node) { paths = append(paths, node.Path) })
+	assert.ElementsMatch(t, []string{".", "legacy", "legacy/shim.go", "core", "core/api.go"}, paths)
+	assert.Equal(t, 2, result.Stats.TotalFiles)
+	assert.Equal(t, 3, result.Stats.TotalDirectories)
+
+	config.AnnotationFilter = regexp.MustCompile("no such annotation")
+	result, err = BuildTree(config)
+	require.NoError(t, err)
+	assert.Equal(t, ".", result.Root.Path)
+	assert.Empty(t, result.Root.Children)
+}
+
+func TestTreeBuildingWithSelect(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"internal": map[string]interface{}{
+			".info":   "api.go  Public handlers\n",
+			"api.go":  "package internal",
+			"util.go": "package internal",
+			"store":   map[string]interface{}{"db.go": "package store"},
+		},
+		"cmd": map[string]interface{}{"main.go": "package main"},
+	})
+
+	config := DefaultTreeConfig("/project")
+	config.Filesystem = fs
+	selector, err := query.ParseSelector("dir[name=internal] > file[ext=go][annotated=false]")
+	require.NoError(t, err)
+	config.Select = selector
+	result, err := BuildTree(config)
+	require.NoError(t, err)
+
+	var paths []string
+	walkTree(result.Root, func(node *types.Node) { paths = append(paths, node.Path) })
+	assert.ElementsMatch(t, []string{".", "internal", "internal/util.go"}, paths)
+	assert.Equal(t, 1, result.Stats.TotalFiles)
+}
+
+func TestTreeBuildingWithPresets(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":  "dist  Published bundle\n",
+		"dist":   map[string]interface{}{"app.js": ""},
+		"build":  map[string]interface{}{"app.js": ""},
+		"vendor": map[string]interface{}{"lib.go": "package lib"},
+		"src":    map[string]interface{}{"index.js": ""},
+	})
+
+	config := DefaultTreeConfig("/project")
+	config.Filesystem = fs
+	config.Presets = []string{"node"}
+	result, err := BuildTree(config)
+	require.NoError(t, err)
+
+	var paths []string
+	walkTree(result.Root, func(node *types.Node) { paths = append(paths, node.Path) })
+	assert.ElementsMatch(t, []string{".", ".info", "dist", "dist/app.js", "vendor", "vendor/lib.go", "src", "src/index.js"}, paths, "annotated artifacts stay visible")
+}
+
+func TestTreeBuildingWithProjectsOnly(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":  "services/api  Public API\n",
+		"go.mod": "module mono",
+		"services": map[string]interface{}{
+			"api": map[string]interface{}{
+				"go.mod":   "module api",
+				"internal": map[string]interface{}{"handler.go": "package internal"},
+			},
+			"shared": map[string]interface{}{"util.go": "package shared"},
+		},
+		"web":  map[string]interface{}{"package.json": "{}", "src": map[string]interface{}{"app.js": ""}},
+		"docs": map[string]interface{}{"guide.md": "# Guide"},
+	})
+
+	config := DefaultTreeConfig("/project")
+	config.Filesystem = fs
+	config.MaxDepth = 2
+	config.ProjectsOnly = true
+	result, err := BuildTree(config)
+	require.NoError(t, err)
+
+	var paths []string
+	walkTree(result.Root, func(node *types.Node) { paths = append(paths, node.Path) })
+	assert.ElementsMatch(t, []string{".", "services", "services/api", "web"}, paths, "roots are detected past the depth limit's leaves")
+	assert.Equal(t, "Public API", result.Root.Children[0].Children[0].GetAnnotation().Notes)
+	assert.Equal(t, 0, result.Stats.TotalFiles)
+	assert.Equal(t, 4, result.Stats.TotalDirectories)
+}
+
+func TestTreeBuildingWithPruneEmpty(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"src": map[string]interface{}{
+			"main.go":  "package main",
+			"empty":    map[string]interface{}{},
+			"fixtures": map[string]interface{}{"data.log": "log"},
+		},
+		"build":     map[string]interface{}{"nested": map[string]interface{}{}},
+		"README.md": "# Project",
+	})
+
+	config := DefaultTreeConfig("/project")
+	config.Filesystem = fs
+	config.ExcludeGlobs = []string{"*.log"}
+	config.PruneEmpty = true
+	result, err := BuildTree(config)
+	require.NoError(t, err)
+
+	var paths []string
+	walkTree(result.Root, func(node *types.Node) { paths = append(paths, node.Path) })
+	assert.ElementsMatch(t, []string{".", "src", "src/main.go", "README.md"}, paths, "directories emptied by filters are pruned too")
+	assert.Equal(t, 2, result.Stats.TotalFiles)
+	assert.Equal(t, 2, result.Stats.TotalDirectories)
+}
+
+func TestTreeBuildingKeepsAnnotatedHiddenFiles(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":        ".env.example  Template for local settings\n.github/workflows/ci.yml  CI pipeline\n",
+		".env":         "SECRET=1",
+		".env.example": "SECRET=",
+		".github": map[string]interface{}{
+			"CODEOWNERS": "* @team",
+			"workflows":  map[string]interface{}{"ci.yml": "on: push"},
+		},
+		"main.go": "package main",
+	})
+
+	config := DefaultTreeConfig("/project")
+	config.Filesystem = fs
+	config.IncludeHidden = false
+	result, err := BuildTree(config)
+	require.NoError(t, err)
+
+	var paths []string
+	walkTree(result.Root, func(node *types.Node) { paths = append(paths, node.Path) })
+	assert.ElementsMatch(t, []string{".", ".env.example", ".github", ".github/workflows", ".github/workflows/ci.yml", "main.go"}, paths)
+}
+
+func TestTreeBuildingWithHiddenOnly(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".env": "SECRET=1",
+		".github": map[string]interface{}{
+			"workflows": map[string]interface{}{"ci.yml": "on: push"},
+		},
+		"web": map[string]interface{}{
+			".eslintrc": "{}",
+			"app.js":    "",
+		},
+		"src":     map[string]interface{}{"main.go": "package main"},
+		"main.go": "package main",
+	})
+
+	config := DefaultTreeConfig("/project")
+	config.Filesystem = fs
+	config.HiddenOnly = true
+	result, err := BuildTree(config)
+	require.NoError(t, err)
+
+	var paths []string
+	walkTree(result.Root, func(node *types.Node) { paths = append(paths, node.Path) })
+	assert.ElementsMatch(t, []string{".", ".env", ".github", ".github/workflows", ".github/workflows/ci.yml", "web", "web/.eslintrc"}, paths)
+	assert.Equal(t, 3, result.Stats.TotalFiles)
+}
+
+func TestTreeBuildingWithFilesOnly(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		"src": map[string]interface{}{
+			"main.go":  "package main",
+			"internal": map[string]interface{}{"empty": map[string]interface{}{}},
+		},
+		"assets":    map[string]interface{}{},
+		"README.md": "# Project",
+	})
+
+	config := DefaultTreeConfig("/project")
+	config.Filesystem = fs
+	config.FilesOnly = true
+	result, err := BuildTree(config)
+	require.NoError(t, err)
+
+	var paths []string
+	walkTree(result.Root, func(node *types.Node) { paths = append(paths, node.Path) })
+	assert.ElementsMatch(t, []string{".", "src", "src/main.go", "README.md"}, paths, "only directories leading to files remain")
+}
@@ -2,7 +2,7 @@
 package treeconstruction
 
 import (
-	"path/filepath"
+	"path"
 	"sort"
 
 	"treex/treex/pathcollection"
@@ -20,6 +20,8 @@ func NewConstructor() *Constructor {
 // BuildTree constructs a tree from a list of PathInfo objects.
 // The algorithm relies on the input paths being sorted to ensure that parent
 // directories are always processed before their children.
+// The nodes share a few large allocations, so holding any node keeps the whole tree in
+// memory; copy what outlives the tree.
 func (c *Constructor) BuildTree(paths []pathcollection.PathInfo) *types.Node {
 	if len(paths) == 0 {
 		return nil
@@ -31,38 +33,60 @@ func (c *Constructor) BuildTree(paths []pathcollection.PathInfo) *types.Node {
 		return paths[i].Path < paths[j].Path
 	})
 
-	// nodeMap provides fast O(1) average time complexity access to any node by its path.
-	nodeMap := make(map[string]*types.Node)
-	var root *types.Node
+	// Every node lives in one slab, allocated at once instead of node by node; Data maps
+	// are left nil until a plugin stores something (see types.Node.SetPluginData)
+	nodes := make([]types.Node, len(paths))
+	parents := make([]int, len(paths))
+	childCounts := make([]int, len(paths))
 
-	for _, p := range paths {
-		node := &types.Node{
-			Name:  filepath.Base(p.Path),
+	// index maps each path to its node for O(1) parent lookups
+	index := make(map[string]int, len(paths))
+
+	for i, p := range paths {
+		nodes[i] = types.Node{
+			Name:  path.Base(p.Path), // A substring of Path, so it shares Path's memory
 			Path:  p.Path,
 			IsDir: p.IsDir,
 			Size:  p.Size,
-			Data:  make(map[string]interface{}),
+			Mode:  p.Mode,
+			Owner: p.Owner,
+			Group: p.Group,
+			Error: p.Error,
 		}
-
-		// Store the newly created node in the map for future lookups.
-		nodeMap[p.Path] = node
+		index[p.Path] = i
+		parents[i] = -1
 
 		// The first path in the sorted slice is the root.
-		if root == nil {
-			root = node
+		if i == 0 {
 			continue
 		}
 
 		// Determine the parent's path. For a path like "a/b/c", the parent is "a/b".
 		// For a top-level path like "a", the parent is ".".
-		parentPath := filepath.Dir(p.Path)
+		// Collected paths always use forward slashes (see pathutil), so path.Dir is correct on every OS.
+		// The parent must exist in the index due to the lexicographical sort order.
+		if parent, ok := index[path.Dir(p.Path)]; ok {
+			parents[i] = parent
+			childCounts[parent]++
+		}
+	}
 
-		// The parent must exist in the map due to the lexicographical sort order.
-		if parent, ok := nodeMap[parentPath]; ok {
-			node.Parent = parent
-			parent.Children = append(parent.Children, node)
+	// Child lists are carved from one pool as well. Each list is capped at its own length,
+	// so a later append copies the list instead of overwriting its neighbor
+	pool := make([]*types.Node, len(paths))
+	offset := 0
+	for i, count := range childCounts {
+		if count > 0 {
+			nodes[i].Children = pool[offset : offset : offset+count]
+			offset += count
+		}
+	}
+	for i, parent := range parents {
+		if parent >= 0 {
+			nodes[i].Parent = &nodes[parent]
+			nodes[parent].Children = append(nodes[parent].Children, &nodes[i])
 		}
 	}
 
-	return root
+	return &nodes[0]
 }
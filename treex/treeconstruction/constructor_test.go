@@ -1,6 +1,8 @@
 package treeconstruction_test
 
 import (
+	"fmt"
+	"runtime"
 	"testing"
 
 	"treex/treex/pathcollection"
@@ -209,3 +211,62 @@ func TestBuildTree_FlatStructure(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildTree_ChildListsAreIndependent(t *testing.T) {
+	constructor := treeconstruction.NewConstructor()
+	paths := []pathcollection.PathInfo{
+		{Path: ".", IsDir: true},
+		{Path: "a", IsDir: true},
+		{Path: "a/one.txt"},
+		{Path: "b", IsDir: true},
+		{Path: "b/two.txt"},
+	}
+
+	root := constructor.BuildTree(paths)
+	a, b := findNodeByPath(root, "a"), findNodeByPath(root, "b")
+	if a.Data != nil {
+		t.Error("Expected Data to stay nil until plugin data is stored")
+	}
+
+	// Child lists share one backing array; appending must not overwrite a neighbor
+	a.Children = append(a.Children, &types.Node{Name: "extra.txt", Path: "a/extra.txt", Parent: a})
+	if len(b.Children) != 1 || b.Children[0].Path != "b/two.txt" {
+		t.Errorf("Appending to a's children changed b's children: %v", b.Children)
+	}
+}
+
+// syntheticPaths lists a root with directories of 10 files each, nodes entries in total
+func syntheticPaths(nodes int) []pathcollection.PathInfo {
+	paths := []pathcollection.PathInfo{{Path: ".", IsDir: true}}
+	for dir := 0; len(paths) < nodes; dir++ {
+		dirPath := fmt.Sprintf("dir%05d", dir)
+		paths = append(paths, pathcollection.PathInfo{Path: dirPath, IsDir: true})
+		for file := 0; file < 10 && len(paths) < nodes; file++ {
+			paths = append(paths, pathcollection.PathInfo{Path: fmt.Sprintf("%s/file%d.go", dirPath, file), Size: 100})
+		}
+	}
+	return paths
+}
+
+// BenchmarkBuildTree measures construction time and the memory allocated per node
+func BenchmarkBuildTree(b *testing.B) {
+	for _, nodes := range []int{10_000, 100_000} {
+		b.Run(fmt.Sprintf("%dk", nodes/1_000), func(b *testing.B) {
+			paths := syntheticPaths(nodes)
+			constructor := treeconstruction.NewConstructor()
+			b.ReportAllocs()
+
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if constructor.BuildTree(paths) == nil {
+					b.Fatal("BuildTree returned a nil root")
+				}
+			}
+			b.StopTimer()
+			runtime.ReadMemStats(&after)
+			b.ReportMetric(float64(after.TotalAlloc-before.TotalAlloc)/float64(b.N*nodes), "B/node")
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package treeconstruction
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"treex/treex/types"
+)
+
+// SortOrder selects how the children of a directory are ordered
+type SortOrder string
+
+const (
+	// SortName orders names byte-wise, as BuildTree does: "File10" before "File2",
+	// uppercase before lowercase
+	SortName SortOrder = "name"
+	// SortNatural compares digit runs by value and letters ignoring case, like file
+	// managers do: "file2" before "File10"
+	SortNatural SortOrder = "natural"
+	// SortLocale collates names by the rules of a language, comparing digit runs by value
+	SortLocale SortOrder = "locale"
+)
+
+// ParseSortOrder validates a sort order name; empty selects SortName
+func ParseSortOrder(value string) (SortOrder, error) {
+	switch order := SortOrder(strings.ToLower(value)); order {
+	case "":
+		return SortName, nil
+	case SortName, SortNatural, SortLocale:
+		return order, nil
+	}
+	return "", fmt.Errorf("unknown sort order %q (valid: name, natural, locale)", value)
+}
+
+// SortChildren reorders the children of every directory below root
+// locale is the BCP 47 language collated by SortLocale ("de", "sv-SE"); empty or unknown
+// uses the language-neutral collation. SortName leaves the tree as built.
+func SortChildren(root *types.Node, order SortOrder, locale string) {
+	var less func(a, b string) bool
+	switch order {
+	case SortNatural:
+		less = func(a, b string) bool { return naturalLess(a, b) }
+	case SortLocale:
+		tag, err := language.Parse(locale)
+		if err != nil {
+			tag = language.Und
+		}
+		collator := collate.New(tag, collate.Numeric)
+		less = func(a, b string) bool {
+			if c := collator.CompareString(a, b); c != 0 {
+				return c < 0
+			}
+			return a < b
+		}
+	default:
+		return
+	}
+	sortChildren(root, less)
+}
+
+// sortChildren sorts the children of node and its descendants by name
+func sortChildren(node *types.Node, less func(a, b string) bool) {
+	if node == nil {
+		return
+	}
+	sort.SliceStable(node.Children, func(i, j int) bool {
+		return less(node.Children[i].Name, node.Children[j].Name)
+	})
+	for _, child := range node.Children {
+		sortChildren(child, less)
+	}
+}
+
+// naturalLess compares names chunk by chunk: digit runs by numeric value, other runes
+// ignoring case; names equal under those rules fall back to byte order
+func naturalLess(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	i, j := 0, 0
+	for i < len(ra) && j < len(rb) {
+		if unicode.IsDigit(ra[i]) && unicode.IsDigit(rb[j]) {
+			startA, startB := i, j
+			for i < len(ra) && unicode.IsDigit(ra[i]) {
+				i++
+			}
+			for j < len(rb) && unicode.IsDigit(rb[j]) {
+				j++
+			}
+			numA := strings.TrimLeft(string(ra[startA:i]), "0")
+			numB := strings.TrimLeft(string(rb[startB:j]), "0")
+			if len(numA) != len(numB) {
+				return len(numA) < len(numB)
+			}
+			if numA != numB {
+				return numA < numB
+			}
+			continue
+		}
+
+		ca, cb := unicode.ToLower(ra[i]), unicode.ToLower(rb[j])
+		if ca != cb {
+			return ca < cb
+		}
+		i++
+		j++
+	}
+	if len(ra)-i != len(rb)-j {
+		return len(ra)-i < len(rb)-j
+	}
+	return a < b
+}
+
+// LocaleFromEnv returns the collation language of the environment as a BCP 47 tag, from
+// LC_ALL, LC_COLLATE or LANG ("de_DE.UTF-8" becomes "de-DE"); empty when unset or "C"
+func LocaleFromEnv(getenv func(string) string) string {
+	for _, name := range []string{"LC_ALL", "LC_COLLATE", "LANG"} {
+		value := getenv(name)
+		if value == "" {
+			continue
+		}
+		if cut := strings.IndexAny(value, ".@"); cut >= 0 {
+			value = value[:cut]
+		}
+		if value == "C" || value == "POSIX" {
+			return ""
+		}
+		return strings.ReplaceAll(value, "_", "-")
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package treeconstruction_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/treex/treeconstruction"
+	"treex/treex/types"
+)
+
+// namedChildren builds a directory whose children have the given names
+func namedChildren(names ...string) *types.Node {
+	root := &types.Node{Name: ".", Path: ".", IsDir: true}
+	for _, name := range names {
+		root.Children = append(root.Children, &types.Node{Name: name, Path: name, Parent: root})
+	}
+	return root
+}
+
+func childNames(node *types.Node) []string {
+	var names []string
+	for _, child := range node.Children {
+		names = append(names, child.Name)
+	}
+	return names
+}
+
+func TestSortChildren(t *testing.T) {
+	names := []string{"File10.txt", "File2.txt", "file1.txt", "apple", "Zebra", "v1.10", "v1.9", "img007", "img7"}
+
+	tests := []struct {
+		order    treeconstruction.SortOrder
+		locale   string
+		expected []string
+	}{
+		{treeconstruction.SortName, "", names},
+		{treeconstruction.SortNatural, "", []string{"apple", "file1.txt", "File2.txt", "File10.txt", "img007", "img7", "v1.9", "v1.10", "Zebra"}},
+		{treeconstruction.SortLocale, "en", []string{"apple", "file1.txt", "File2.txt", "File10.txt", "img007", "img7", "v1.9", "v1.10", "Zebra"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			root := namedChildren(names...)
+			treeconstruction.SortChildren(root, tt.order, tt.locale)
+			assert.Equal(t, tt.expected, childNames(root))
+		})
+	}
+}
+
+func TestSortChildrenLocale(t *testing.T) {
+	// Swedish sorts ö after z; the language-neutral collation next to o
+	root := namedChildren("zeta", "öl", "orm")
+	treeconstruction.SortChildren(root, treeconstruction.SortLocale, "sv")
+	assert.Equal(t, []string{"orm", "zeta", "öl"}, childNames(root))
+
+	treeconstruction.SortChildren(root, treeconstruction.SortLocale, "")
+	assert.Equal(t, []string{"öl", "orm", "zeta"}, childNames(root))
+}
+
+func TestSortChildrenRecurses(t *testing.T) {
+	root := namedChildren("dir")
+	dir := root.Children[0]
+	dir.IsDir = true
+	dir.Children = namedChildren("b10", "b9").Children
+
+	treeconstruction.SortChildren(root, treeconstruction.SortNatural, "")
+	assert.Equal(t, []string{"b9", "b10"}, childNames(dir))
+}
+
+func TestParseSortOrder(t *testing.T) {
+	order, err := treeconstruction.ParseSortOrder("")
+	require.NoError(t, err)
+	assert.Equal(t, treeconstruction.SortName, order)
+
+	order, err = treeconstruction.ParseSortOrder("Natural")
+	require.NoError(t, err)
+	assert.Equal(t, treeconstruction.SortNatural, order)
+
+	_, err = treeconstruction.ParseSortOrder("size")
+	assert.ErrorContains(t, err, "unknown sort order")
+}
+
+func TestLocaleFromEnv(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(name string) string { return values[name] }
+	}
+	assert.Equal(t, "de-DE", treeconstruction.LocaleFromEnv(env(map[string]string{"LANG": "de_DE.UTF-8"})))
+	assert.Equal(t, "sv-SE", treeconstruction.LocaleFromEnv(env(map[string]string{"LC_COLLATE": "sv_SE", "LANG": "en_US.UTF-8"})))
+	assert.Equal(t, "", treeconstruction.LocaleFromEnv(env(map[string]string{"LC_ALL": "C.UTF-8", "LANG": "en_US"})))
+	assert.Equal(t, "", treeconstruction.LocaleFromEnv(env(nil)))
+}
@@ -0,0 +1,247 @@
+// Package treetext parses tree diagrams written as text back into paths and annotations.
+//
+// It accepts treex's own output in every charset (including aligned and wrapped
+// annotation columns, icons, --long details and colors), output of the tree(1) command
+// (with or without [size] columns) and hand-drawn trees using the same connectors.
+package treetext
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"treex/treex/rendering"
+)
+
+// Entry is a file or directory drawn in a tree diagram
+type Entry struct {
+	Path  string // Slash-separated path relative to the diagram's root line
+	IsDir bool   // Drawn with children, a trailing "/" or as a collapsed directory
+	Notes string // Annotation text, joined across wrapped lines
+	Line  int    // 1-based line of the entry in the input
+
+	// Content is the text of a fenced block drawn below the entry (see Parse)
+	Content    string
+	HasContent bool
+}
+
+// connectors are the branch glyphs that start an entry, longest first so "├── " is not
+// read as "├─ " followed by a stray character
+var connectors = []string{
+	"├── ", "└── ", "|-- ", "`-- ", "+-- ", "\\-- ",
+	"├─ ", "└─ ", "╰─ ", "╠═ ", "╚═ ", "+- ", "\\- ",
+}
+
+var (
+	// longDetails matches the mode, owner and group columns of --long output; the columns
+	// are padded to one width, so the first (unindented) match gives the width for all lines
+	longDetails = regexp.MustCompile(`^[-dlcbpsD?][rwxsStT-]{9}[@+.]?\s+\S+\s+\S+\s+`)
+	// sizeColumn matches the [size] or [mode user size] column of tree -s/-h/-p
+	sizeColumn = regexp.MustCompile(`^\[[^\]]*\]\s+`)
+	// summaryLine matches the "3 directories, 12 files" footer of tree(1)
+	summaryLine = regexp.MustCompile(`^\d+ director(y|ies)(, \d+ files?)?$`)
+	// notesSeparator splits a name from its annotation: a tab or at least two spaces
+	notesSeparator = regexp.MustCompile(`\t+| {2,}`)
+)
+
+// MaxLineSize is the longest diagram line read, in bytes
+var MaxLineSize = 1024 * 1024
+
+// Parse reads a tree diagram and returns its entries in drawing order
+//
+// The first line without a connector is the root and is not an entry. Later lines
+// without a connector continue the previous entry's annotation; anything else that
+// cannot be read is reported with its line number rather than guessed at.
+//
+// A block fenced with ``` below an entry holds the entry's content. Guides to the left
+// of the opening fence are removed from every line of the block:
+//
+//	└─ main.go   Entry point
+//	   ```
+//	   package main
+//	   ```
+func Parse(r io.Reader) ([]Entry, error) {
+	type level struct {
+		column int
+		index  int // Index of the entry in entries
+	}
+
+	var entries []Entry
+	var stack []level
+	rootSeen := false
+	detailsWidth := 0 // Width of the --long columns, taken from the first line showing them
+	fenceColumn := -1 // Column of the open content fence, -1 outside content blocks
+	var content []string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, min(bufio.MaxScanTokenSize, MaxLineSize+1)), MaxLineSize+1)
+	lineNum := 1
+	for ; scanner.Scan(); lineNum++ {
+		line := cleanLine(scanner.Text())
+		if detailsWidth == 0 {
+			detailsWidth = len(longDetails.FindString(line))
+		}
+		if detailsWidth > 0 && longDetails.MatchString(line) && len(line) >= detailsWidth {
+			line = line[detailsWidth:]
+		}
+		if fenceColumn >= 0 {
+			if guided := strings.TrimSpace(strings.TrimLeft(line, "│║| \t")); guided == "```" {
+				entry := &entries[len(entries)-1]
+				if len(content) > 0 {
+					entry.Content = strings.Join(content, "\n") + "\n"
+				}
+				fenceColumn, content = -1, nil
+				continue
+			}
+			content = append(content, stripGuides(line, fenceColumn))
+			continue
+		}
+		if strings.TrimSpace(line) == "" || summaryLine.MatchString(strings.TrimSpace(line)) {
+			continue
+		}
+
+		column, text, found := splitConnector(line)
+		if !found {
+			text = strings.TrimSpace(strings.TrimLeft(line, "│║| \t"))
+			switch {
+			case strings.HasPrefix(text, "```") && len(entries) > 0:
+				entry := &entries[len(entries)-1]
+				if entry.HasContent {
+					return nil, fmt.Errorf("line %d: %s already has content", lineNum, entry.Path)
+				}
+				entry.HasContent = true
+				fenceColumn = utf8.RuneCountInString(line[:strings.Index(line, "```")])
+			case !rootSeen && len(entries) == 0:
+				rootSeen = true
+			case len(entries) > 0 && entries[len(entries)-1].Notes != "" && text != "":
+				entries[len(entries)-1].Notes += " " + text
+			default:
+				return nil, fmt.Errorf("line %d: cannot read %q as a tree entry", lineNum, strings.TrimSpace(line))
+			}
+			continue
+		}
+
+		// "… 12 more files not shown" stands for entries that were not drawn
+		if strings.HasPrefix(text, "…") {
+			continue
+		}
+
+		name, notes, isDir, err := splitEntry(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].column >= column {
+			stack = stack[:len(stack)-1]
+		}
+		entryPath := name
+		if len(stack) > 0 {
+			parent := &entries[stack[len(stack)-1].index]
+			parent.IsDir = true
+			entryPath = path.Join(parent.Path, name)
+		}
+
+		entries = append(entries, Entry{Path: entryPath, IsDir: isDir, Notes: notes, Line: lineNum})
+		stack = append(stack, level{column: column, index: len(entries) - 1})
+	}
+	if err := scanner.Err(); errors.Is(err, bufio.ErrTooLong) {
+		return nil, fmt.Errorf("line %d is longer than %d bytes", lineNum, MaxLineSize)
+	} else if err != nil {
+		return nil, err
+	}
+	if fenceColumn >= 0 {
+		entry := entries[len(entries)-1]
+		return nil, fmt.Errorf("line %d: content block of %s is not closed", entry.Line, entry.Path)
+	}
+	return entries, nil
+}
+
+// cleanLine removes colors and hyperlinks, non-breaking spaces (tree(1) pads with them),
+// a UTF-8 byte order mark and a trailing carriage return
+func cleanLine(line string) string {
+	line = rendering.StripANSI(strings.TrimPrefix(strings.TrimRight(line, "\r"), "\ufeff"))
+	return strings.ReplaceAll(line, "\u00a0", " ")
+}
+
+// splitConnector finds the branch connector of an entry line, returning its column and
+// the text after it. Only guide glyphs and spaces may come before the connector.
+func splitConnector(line string) (int, string, bool) {
+	column := 0
+	for offset, r := range line {
+		rest := line[offset:]
+		for _, connector := range connectors {
+			if strings.HasPrefix(rest, connector) {
+				return column, strings.TrimRight(rest[len(connector):], " "), true
+			}
+		}
+		if !strings.ContainsRune("│║| \t", r) {
+			return 0, "", false
+		}
+		column++
+	}
+	return 0, "", false
+}
+
+// stripGuides removes guides and spaces in the first columns of a content line
+func stripGuides(line string, columns int) string {
+	for offset, r := range line {
+		if columns == 0 || !strings.ContainsRune("│║| \t", r) {
+			return line[offset:]
+		}
+		columns--
+	}
+	return ""
+}
+
+// splitEntry separates the name of an entry from its annotation, dropping icons, size
+// columns, symlink targets and collapsed directory summaries
+func splitEntry(text string) (string, string, bool, error) {
+	text = strings.TrimSpace(sizeColumn.ReplaceAllString(text, ""))
+	text = stripIcon(text)
+
+	name, notes := text, ""
+	if location := notesSeparator.FindStringIndex(text); location != nil {
+		name, notes = text[:location[0]], strings.TrimSpace(text[location[1]:])
+	}
+
+	isDir := false
+	if collapsed := strings.Index(name, "/…"); collapsed >= 0 {
+		name, isDir = name[:collapsed], true
+	}
+	if target := strings.Index(name, " -> "); target >= 0 {
+		name = name[:target]
+	}
+	if strings.HasSuffix(name, "/") {
+		name, isDir = strings.TrimRight(name, "/"), true
+	}
+
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", "", false, fmt.Errorf("entry has no name")
+	case name == "." || name == ".." || strings.Contains(name, "/"):
+		return "", "", false, fmt.Errorf("invalid entry name %q", name)
+	}
+	return name, notes, isDir, nil
+}
+
+// stripIcon drops a leading icon (nerd font or emoji) followed by a space
+func stripIcon(text string) string {
+	icon, rest, found := strings.Cut(text, " ")
+	if !found || icon == "" {
+		return text
+	}
+	for _, r := range icon {
+		isIcon := unicode.In(r, unicode.So, unicode.Co, unicode.Mn) || r == '\u200d' || r == '\ufe0f'
+		if !isIcon {
+			return text
+		}
+	}
+	return strings.TrimLeft(rest, " ")
+}
@@ -0,0 +1,222 @@
+package treetext_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex"
+	"treex/treex/display"
+	_ "treex/treex/plugins/infofile" // Import for plugin registration
+	"treex/treex/rendering"
+	"treex/treex/treetext"
+)
+
+// summarize maps each annotated entry to its notes and lists directories
+func summarize(entries []treetext.Entry) (map[string]string, []string) {
+	notes := map[string]string{}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.Notes != "" {
+			notes[entry.Path] = entry.Notes
+		}
+		if entry.IsDir {
+			dirs = append(dirs, entry.Path)
+		}
+	}
+	return notes, dirs
+}
+
+func TestParseRoundTripsTreexOutput(t *testing.T) {
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info": "README.md  Project overview\n" +
+			"src  Source code with a long annotation that wraps onto several lines in narrow terminals\n",
+		"README.md": "# project",
+		"src": map[string]interface{}{
+			".info":   "main.go  Entry point\n",
+			"main.go": "package main",
+			"lib":     map[string]interface{}{"util.go": "package lib"},
+		},
+	})
+
+	config := treex.DefaultTreeConfig("/")
+	config.Filesystem = afero.NewBasePathFs(fs, "/project")
+	result, err := treex.BuildTree(config)
+	require.NoError(t, err)
+
+	expectedNotes := map[string]string{
+		"README.md":   "Project overview",
+		"src":         "Source code with a long annotation that wraps onto several lines in narrow terminals",
+		"src/main.go": "Entry point",
+	}
+
+	variants := map[string]rendering.RenderConfig{
+		"unicode":      {Charset: rendering.CharsetUnicode},
+		"ascii":        {Charset: rendering.CharsetASCII},
+		"rounded":      {Charset: rendering.CharsetRounded},
+		"double":       {Charset: rendering.CharsetDouble},
+		"colors":       {Charset: rendering.CharsetUnicode, Format: rendering.FormatTerm},
+		"nerd icons":   {Charset: rendering.CharsetUnicode, Icons: display.BuiltinIcons(display.IconsNerd)},
+		"emoji icons":  {Charset: rendering.CharsetUnicode, Icons: display.BuiltinIcons(display.IconsEmoji)},
+		"long listing": {Charset: rendering.CharsetUnicode, Long: true},
+		"wrapped":      {Charset: rendering.CharsetUnicode, Width: 48},
+	}
+
+	for name, renderConfig := range variants {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			renderConfig.Writer = &out
+			renderConfig.ShowNotes = true
+			if renderConfig.Format == "" {
+				renderConfig.Format = rendering.FormatPlain
+			}
+			if renderConfig.Width == 0 {
+				renderConfig.Width = -1
+			}
+			if renderConfig.Format == rendering.FormatTerm {
+				renderConfig.Getenv = func(key string) string {
+					return map[string]string{"CLICOLOR_FORCE": "1"}[key]
+				}
+			}
+			require.NoError(t, rendering.NewRenderer(renderConfig).RenderTree(result))
+
+			entries, err := treetext.Parse(&out)
+			require.NoError(t, err, out.String())
+
+			notes, dirs := summarize(entries)
+			assert.Equal(t, expectedNotes, notes, out.String())
+			assert.Equal(t, []string{"src", "src/lib"}, dirs)
+			assert.Len(t, entries, 7, "every entry except the root")
+		})
+	}
+}
+
+func TestParseTreeCommandOutput(t *testing.T) {
+	// tree -h -F output, padded with non-breaking spaces as tree(1) does
+	input := strings.Join([]string{
+		".",
+		"├── [4.0K]  cmd/",
+		"│   └── [ 13K]  root.go",
+		"├── [ 120]  go.mod",
+		"├── [  42]  link -> go.mod",
+		"└── [4.0K]  pkg/",
+		"    └── [4.0K]  api/",
+		"",
+		"3 directories, 3 files",
+	}, "\n")
+
+	entries, err := treetext.Parse(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []treetext.Entry{
+		{Path: "cmd", IsDir: true, Line: 2},
+		{Path: "cmd/root.go", Line: 3},
+		{Path: "go.mod", Line: 4},
+		{Path: "link", Line: 5},
+		{Path: "pkg", IsDir: true, Line: 6},
+		{Path: "pkg/api", IsDir: true, Line: 7},
+	}, entries)
+}
+
+func TestParseHandWrittenTree(t *testing.T) {
+	input := "my-project\r\n" +
+		"|-- docs\t\tDocumentation\r\n" +
+		"|   `-- guide.md   User guide\r\n" +
+		"`-- src/\r\n" +
+		"    |-- main.go    Entry point\r\n" +
+		"    `-- src/… 2 files   Collapsed\r\n"
+
+	entries, err := treetext.Parse(strings.NewReader(input))
+	require.NoError(t, err)
+
+	notes, dirs := summarize(entries)
+	assert.Equal(t, map[string]string{
+		"docs":          "Documentation",
+		"docs/guide.md": "User guide",
+		"src/main.go":   "Entry point",
+		"src/src":       "Collapsed",
+	}, notes)
+	assert.Equal(t, []string{"docs", "src", "src/src"}, dirs)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		".\nsome stray text\n": "line 2: cannot read",
+		".\n├─ ..\n":           `line 2: invalid entry name ".."`,
+		".\n├─ a/b   notes\n":  `line 2: invalid entry name "a/b"`,
+		".\n├─ \n":             "line 2",
+	}
+	for input, expected := range tests {
+		_, err := treetext.Parse(strings.NewReader(input))
+		assert.ErrorContains(t, err, expected, "input %q", input)
+	}
+}
+
+func TestParseLongLines(t *testing.T) {
+	entries, err := treetext.Parse(strings.NewReader(".\n├─ schema.json  " + strings.Repeat("x", 100*1024) + "\n└─ main.go\n"))
+	require.NoError(t, err, "lines past bufio's 64KB default are read")
+	require.Len(t, entries, 2)
+	assert.Len(t, entries[0].Notes, 100*1024)
+
+	original := treetext.MaxLineSize
+	treetext.MaxLineSize = 32
+	t.Cleanup(func() { treetext.MaxLineSize = original })
+
+	_, err = treetext.Parse(strings.NewReader(".\n├─ main.go\n└─ schema.json  " + strings.Repeat("x", 40) + "\n"))
+	assert.EqualError(t, err, "line 3 is longer than 32 bytes")
+}
+
+func TestParseWindowsDiagram(t *testing.T) {
+	entries, err := treetext.Parse(strings.NewReader("\ufeffproject\r\n├─ main.go  Entry point\r\n└─ docs/\r\n"))
+	require.NoError(t, err)
+
+	notes, dirs := summarize(entries)
+	assert.Equal(t, map[string]string{"main.go": "Entry point"}, notes)
+	assert.Equal(t, []string{"docs"}, dirs)
+}
+
+func TestParseContentBlocks(t *testing.T) {
+	input := strings.Join([]string{
+		"project",
+		"├─ cmd",
+		"│  └─ main.go   Entry point",
+		"│     ```go",
+		"│     package main",
+		"│",
+		"│     func main() {",
+		"│     \t// ├─ not an entry",
+		"│     }",
+		"│     ```",
+		"├─ empty.txt",
+		"│  ```",
+		"│  ```",
+		"└─ notes.md",
+	}, "\n")
+
+	entries, err := treetext.Parse(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	assert.Equal(t, "cmd/main.go", entries[1].Path)
+	assert.True(t, entries[1].HasContent)
+	assert.Equal(t, "package main\n\nfunc main() {\n\t// ├─ not an entry\n}\n", entries[1].Content)
+	assert.Equal(t, "Entry point", entries[1].Notes)
+
+	assert.Equal(t, "empty.txt", entries[2].Path)
+	assert.True(t, entries[2].HasContent)
+	assert.Empty(t, entries[2].Content)
+
+	assert.False(t, entries[3].HasContent)
+}
+
+func TestParseContentBlockErrors(t *testing.T) {
+	_, err := treetext.Parse(strings.NewReader(".\n└─ main.go\n   ```\n   package main\n"))
+	assert.ErrorContains(t, err, "line 2: content block of main.go is not closed")
+
+	_, err = treetext.Parse(strings.NewReader(".\n└─ a\n   ```\n   ```\n   ```\n   ```\n"))
+	assert.ErrorContains(t, err, "a already has content")
+}
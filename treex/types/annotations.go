@@ -14,3 +14,12 @@ type GitStatus struct {
 	Untracked bool   // File is untracked
 	Status    string // Human-readable status description
 }
+
+// FileKind tells the files a tool produced or copied in from the ones people write
+type FileKind string
+
+const (
+	KindBinary    FileKind = "binary"    // Contents are not text
+	KindGenerated FileKind = "generated" // Written by a code generator, e.g. *.pb.go or a "Code generated" header
+	KindVendored  FileKind = "vendored"  // Third-party code copied into the tree, e.g. below vendor/
+)
@@ -1,15 +1,21 @@
 package types
 
+import "io/fs"
+
 // Node represents a file or directory in the tree
 type Node struct {
 	Name       string                 // Just the filename/dirname, e.g., "main.go"
 	Path       string                 // The unique, relative path from the tree root, e.g., "src/main.go"
 	IsDir      bool                   // Whether this is a directory
 	Size       int64                  // File size in bytes (0 for directories)
+	Mode       fs.FileMode            // Type and permission bits captured during the walk
+	Owner      string                 // Owner name (empty when unavailable)
+	Group      string                 // Group name (empty when unavailable)
+	Error      string                 // Why a directory's contents could not be read (empty when readable)
 	Annotation *Annotation            // Associated annotation if any (DEPRECATED: use Data["info"])
 	Children   []*Node                // Child nodes (for directories)
 	Parent     *Node                  // Parent node (nil for root)
-	Data       map[string]interface{} // Plugin-specific data storage
+	Data       map[string]interface{} // Plugin-specific data storage (nil until SetPluginData)
 }
 
 // SetPluginData stores data for a specific plugin namespace
@@ -47,3 +53,14 @@ func (n *Node) GetAnnotation() *Annotation {
 func (n *Node) SetAnnotation(annotation *Annotation) {
 	n.SetPluginData("info", annotation)
 }
+
+// GetKind returns the kind the kind plugin attached to this node; "" for the entries
+// people write
+func (n *Node) GetKind() FileKind {
+	if data, exists := n.GetPluginData("kind"); exists {
+		if kind, ok := data.(FileKind); ok {
+			return kind
+		}
+	}
+	return ""
+}
@@ -26,8 +26,14 @@ type TreeDisplayOptions struct {
 	// Show only directories
 	DirsOnly bool
 
+	// Show only files, with the directories leading to them
+	FilesOnly bool
+
 	// Show hidden files/directories (starting with .)
 	ShowHidden bool
+
+	// Show only hidden files and the files below hidden directories (overrides ShowHidden)
+	HiddenOnly bool
 }
 
 // PatternOptions handles all pattern-based filtering
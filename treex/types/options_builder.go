@@ -30,12 +30,30 @@ func (b *OptionsBuilder) WithDirsOnly() *OptionsBuilder {
 	return b
 }
 
+// WithFilesOnly enables files-only mode
+func (b *OptionsBuilder) WithFilesOnly() *OptionsBuilder {
+	b.opts.Tree.FilesOnly = true
+	return b
+}
+
 // WithHidden enables showing hidden files
 func (b *OptionsBuilder) WithHidden() *OptionsBuilder {
 	b.opts.Tree.ShowHidden = true
 	return b
 }
 
+// WithoutHidden hides hidden files, except annotated ones
+func (b *OptionsBuilder) WithoutHidden() *OptionsBuilder {
+	b.opts.Tree.ShowHidden = false
+	return b
+}
+
+// WithHiddenOnly shows only hidden files and the files below hidden directories
+func (b *OptionsBuilder) WithHiddenOnly() *OptionsBuilder {
+	b.opts.Tree.HiddenOnly = true
+	return b
+}
+
 // WithExclude adds an exclude pattern
 func (b *OptionsBuilder) WithExclude(pattern string) *OptionsBuilder {
 	b.opts.Patterns.Excludes = append(b.opts.Patterns.Excludes, pattern)
@@ -130,6 +148,10 @@ func (opts *TreeOptions) Validate() error {
 		opts.Tree.MaxDepth = 3
 	}
 
+	if opts.Tree.DirsOnly && opts.Tree.FilesOnly {
+		return ErrInvalidOptions{Message: "directories-only and files-only cannot be combined"}
+	}
+
 	return nil
 }
 
@@ -76,4 +76,10 @@ func TestOptionsValidation(t *testing.T) {
 	if opts2.Tree.MaxDepth != 3 {
 		t.Errorf("Expected maxDepth to be defaulted to 3, got %d", opts2.Tree.MaxDepth)
 	}
+
+	// Test directories-only and files-only are exclusive
+	opts3 := types.NewOptionsBuilder().WithDirsOnly().WithFilesOnly().Build()
+	if err := opts3.Validate(); err == nil {
+		t.Error("Expected an error combining dirs-only and files-only")
+	}
 }
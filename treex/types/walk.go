@@ -0,0 +1,89 @@
+package types
+
+import "errors"
+
+// SkipChildren is returned by a WalkFunc to skip the children of the node it was called
+// for; the walk goes on with the rest of the tree. Post-order walks ignore it.
+var SkipChildren = errors.New("skip children")
+
+// SkipAll is returned by a WalkFunc to stop the walk; the walk then returns nil
+var SkipAll = errors.New("skip all")
+
+// WalkFunc is called for each node of a walk, with depth 0 for the root. Returning
+// SkipChildren or SkipAll prunes the walk; any other error stops it and is returned.
+type WalkFunc func(node *Node, depth int) error
+
+// WalkTree visits root and the nodes below it depth-first, each node before its children
+// and siblings in order, the order trees are printed in
+func WalkTree(root *Node, visit WalkFunc) error {
+	return ignoreSkipAll(walkTree(root, 0, visit, nil))
+}
+
+// WalkTreePostOrder visits root and the nodes below it depth-first, each node after its
+// children, so aggregates such as directory sizes can be computed from the children's
+func WalkTreePostOrder(root *Node, visit WalkFunc) error {
+	return ignoreSkipAll(walkTree(root, 0, nil, visit))
+}
+
+// WalkTreePrePost visits root and the nodes below it depth-first, calling pre before the
+// children of each node and post after them; either may be nil. post is not called for
+// nodes whose children pre skipped.
+func WalkTreePrePost(root *Node, pre, post WalkFunc) error {
+	return ignoreSkipAll(walkTree(root, 0, pre, post))
+}
+
+// walkTree walks the subtree of node for WalkTreePrePost, returning SkipAll unchanged
+func walkTree(node *Node, depth int, pre, post WalkFunc) error {
+	if node == nil {
+		return nil
+	}
+	if pre != nil {
+		if err := pre(node, depth); err == SkipChildren {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	for _, child := range node.Children {
+		if err := walkTree(child, depth+1, pre, post); err != nil {
+			return err
+		}
+	}
+	if post != nil {
+		if err := post(node, depth); err != nil && err != SkipChildren {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkTreeBFS visits root and the nodes below it breadth-first: every node of a depth
+// before any deeper one, siblings in order and the children of earlier nodes first
+func WalkTreeBFS(root *Node, visit WalkFunc) error {
+	if root == nil {
+		return nil
+	}
+	level := []*Node{root}
+	for depth := 0; len(level) > 0; depth++ {
+		var next []*Node
+		for _, node := range level {
+			switch err := visit(node, depth); err {
+			case nil:
+				next = append(next, node.Children...)
+			case SkipChildren:
+			default:
+				return ignoreSkipAll(err)
+			}
+		}
+		level = next
+	}
+	return nil
+}
+
+// ignoreSkipAll returns nil for SkipAll, which ends a walk without an error
+func ignoreSkipAll(err error) error {
+	if err == SkipAll {
+		return nil
+	}
+	return err
+}
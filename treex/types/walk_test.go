@@ -0,0 +1,105 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// walkTestTree builds a/{b/{d}, c/{e, f}}
+func walkTestTree() *Node {
+	a := &Node{Name: "a", Path: ".", IsDir: true}
+	b := &Node{Name: "b", Path: "b", IsDir: true, Parent: a}
+	c := &Node{Name: "c", Path: "c", IsDir: true, Parent: a}
+	b.Children = []*Node{{Name: "d", Path: "b/d", Parent: b}}
+	c.Children = []*Node{{Name: "e", Path: "c/e", Parent: c}, {Name: "f", Path: "c/f", Parent: c}}
+	a.Children = []*Node{b, c}
+	return a
+}
+
+// recorder returns a WalkFunc appending "<name><depth>" to visited, returning sentinel
+// for the node named at
+func recorder(visited *[]string, at string, sentinel error) WalkFunc {
+	return func(node *Node, depth int) error {
+		*visited = append(*visited, fmt.Sprintf("%s%d", node.Name, depth))
+		if node.Name == at {
+			return sentinel
+		}
+		return nil
+	}
+}
+
+func TestWalkTreeOrders(t *testing.T) {
+	tests := []struct {
+		name     string
+		walk     func(*Node, WalkFunc) error
+		at       string
+		sentinel error
+		expected string
+	}{
+		{"pre-order", WalkTree, "", nil, "a0 b1 d2 c1 e2 f2"},
+		{"breadth-first", WalkTreeBFS, "", nil, "a0 b1 c1 d2 e2 f2"},
+		{"post-order", WalkTreePostOrder, "", nil, "d2 b1 e2 f2 c1 a0"},
+		{"pre-order skipping children", WalkTree, "b", SkipChildren, "a0 b1 c1 e2 f2"},
+		{"breadth-first skipping children", WalkTreeBFS, "c", SkipChildren, "a0 b1 c1 d2"},
+		{"post-order ignores SkipChildren", WalkTreePostOrder, "b", SkipChildren, "d2 b1 e2 f2 c1 a0"},
+		{"pre-order stopping", WalkTree, "d", SkipAll, "a0 b1 d2"},
+		{"breadth-first stopping", WalkTreeBFS, "c", SkipAll, "a0 b1 c1"},
+		{"post-order stopping", WalkTreePostOrder, "e", SkipAll, "d2 b1 e2"},
+	}
+	for _, tt := range tests {
+		var visited []string
+		if err := tt.walk(walkTestTree(), recorder(&visited, tt.at, tt.sentinel)); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if got := strings.Join(visited, " "); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestWalkTreePrePost(t *testing.T) {
+	var visited []string
+	pre := recorder(&visited, "c", SkipChildren)
+	post := func(node *Node, depth int) error {
+		visited = append(visited, "/"+node.Name)
+		return nil
+	}
+	if err := WalkTreePrePost(walkTestTree(), pre, post); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if got, expected := strings.Join(visited, " "), "a0 b1 d2 /d /b c1 /a"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	// Sizes aggregate bottom-up
+	root := walkTestTree()
+	root.Children[1].Children[0].Size = 3
+	root.Children[1].Children[1].Size = 4
+	root.Children[0].Children[0].Size = 5
+	if err := WalkTreePostOrder(root, func(node *Node, depth int) error {
+		for _, child := range node.Children {
+			node.Size += child.Size
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if root.Size != 12 || root.Children[1].Size != 7 {
+		t.Errorf("Expected sizes 12 and 7, got %d and %d", root.Size, root.Children[1].Size)
+	}
+}
+
+func TestWalkTreeErrors(t *testing.T) {
+	failure := errors.New("failure")
+	for name, walk := range map[string]func(*Node, WalkFunc) error{"pre-order": WalkTree, "post-order": WalkTreePostOrder, "breadth-first": WalkTreeBFS} {
+		var visited []string
+		if err := walk(walkTestTree(), recorder(&visited, "b", failure)); err != failure {
+			t.Errorf("%s: expected the callback's error, got %v", name, err)
+		}
+		if err := walk(nil, recorder(&visited, "", nil)); err != nil {
+			t.Errorf("%s: expected no error for a nil root, got %v", name, err)
+		}
+	}
+}
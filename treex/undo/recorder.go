@@ -0,0 +1,273 @@
+// Package undo journals the changes treex commands make to a project so the last
+// operation can be reverted.
+//
+// Commands run their edits against a Recorder, an afero filesystem that snapshots each
+// file before its first change, then Save the journal under .treex/undo in the project
+// root. Revert restores the snapshots of the newest journal.
+package undo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Dir is the journal directory, relative to the project root
+const Dir = ".treex/undo"
+
+// MaxJournals is the number of journals kept; older ones are pruned on Save
+const MaxJournals = 20
+
+// Change kinds
+const (
+	KindFile = "file"
+	KindDir  = "dir"
+)
+
+// Change is the state of one path before an operation touched it
+type Change struct {
+	Path    string      `json:"path"`             // Relative to the root (absolute when outside it)
+	Kind    string      `json:"kind"`             // KindFile or KindDir
+	Existed bool        `json:"existed"`          // Whether the path existed before
+	Before  []byte      `json:"before,omitempty"` // File content before the operation
+	Mode    os.FileMode `json:"mode,omitempty"`   // File mode before the operation
+	After   string      `json:"after,omitempty"`  // SHA-256 of the file after the operation ("" = absent)
+}
+
+// Journal records one operation
+type Journal struct {
+	Command string    `json:"command"`
+	Time    time.Time `json:"time"`
+	Changes []Change  `json:"changes"`
+
+	// ID names the journal file; set when loading
+	ID string `json:"-"`
+}
+
+// Recorder is a filesystem that records the prior state of everything changed through it
+// Reads pass through unchanged.
+type Recorder struct {
+	afero.Fs
+	root string
+
+	mu      sync.Mutex
+	seen    map[string]bool
+	changes []Change
+	now     func() time.Time
+}
+
+// NewRecorder wraps fs, journaling changes for the project at root (an absolute path)
+func NewRecorder(fs afero.Fs, root string) *Recorder {
+	return &Recorder{Fs: fs, root: filepath.Clean(root), seen: make(map[string]bool), now: time.Now}
+}
+
+// Changes returns the changes recorded so far
+func (r *Recorder) Changes() []Change {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Change(nil), r.changes...)
+}
+
+func (r *Recorder) Create(name string) (afero.File, error) {
+	r.recordFile(name)
+	return r.Fs.Create(name)
+}
+
+func (r *Recorder) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		r.recordFile(name)
+	}
+	return r.Fs.OpenFile(name, flag, perm)
+}
+
+func (r *Recorder) Remove(name string) error {
+	r.recordPath(name)
+	return r.Fs.Remove(name)
+}
+
+func (r *Recorder) RemoveAll(name string) error {
+	_ = afero.Walk(r.Fs, name, func(walkPath string, info os.FileInfo, err error) error {
+		if err == nil {
+			r.recordPath(walkPath)
+		}
+		return nil
+	})
+	return r.Fs.RemoveAll(name)
+}
+
+func (r *Recorder) Rename(oldname, newname string) error {
+	r.recordPath(oldname)
+	r.recordFile(newname)
+	return r.Fs.Rename(oldname, newname)
+}
+
+func (r *Recorder) Mkdir(name string, perm os.FileMode) error {
+	r.recordDir(name)
+	return r.Fs.Mkdir(name, perm)
+}
+
+func (r *Recorder) MkdirAll(name string, perm os.FileMode) error {
+	// Record the missing ancestors outermost first, so they are removed innermost first
+	var missing []string
+	for dir := filepath.Clean(name); ; dir = filepath.Dir(dir) {
+		if _, err := r.Fs.Stat(dir); err == nil {
+			break
+		}
+		missing = append(missing, dir)
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		r.recordDir(missing[i])
+	}
+	return r.Fs.MkdirAll(name, perm)
+}
+
+// recordPath records a file or directory about to be removed or moved
+func (r *Recorder) recordPath(name string) {
+	if info, err := r.Fs.Stat(name); err == nil && info.IsDir() {
+		r.recordDir(name)
+		return
+	}
+	r.recordFile(name)
+}
+
+// recordFile snapshots a file before its first change
+func (r *Recorder) recordFile(name string) {
+	key := filepath.Clean(name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seen[key] {
+		return
+	}
+	r.seen[key] = true
+
+	change := Change{Path: r.relative(key), Kind: KindFile}
+	if info, err := r.Fs.Stat(key); err == nil && !info.IsDir() {
+		if content, err := afero.ReadFile(r.Fs, key); err == nil {
+			change.Existed, change.Before, change.Mode = true, content, info.Mode().Perm()
+		}
+	}
+	r.changes = append(r.changes, change)
+}
+
+// recordDir notes whether a directory existed before its first change
+func (r *Recorder) recordDir(name string) {
+	key := filepath.Clean(name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seen[key] {
+		return
+	}
+	r.seen[key] = true
+
+	_, err := r.Fs.Stat(key)
+	r.changes = append(r.changes, Change{Path: r.relative(key), Kind: KindDir, Existed: err == nil})
+}
+
+// relative expresses a path relative to the root when it is inside it
+func (r *Recorder) relative(name string) string {
+	rel, err := filepath.Rel(r.root, name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(name)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Save writes the journal for command, returning its ID ("" when nothing changed)
+// Files are hashed as they are now so Revert can detect later edits. Files created and
+// removed again during the operation, such as lock and temporary files, are left out.
+func (r *Recorder) Save(command string) (string, error) {
+	var changes []Change
+	for _, change := range r.Changes() {
+		if change.Kind == KindFile {
+			change.After = hashFile(r.Fs, resolve(r.root, change.Path))
+			if !change.Existed && change.After == "" {
+				continue
+			}
+		}
+		changes = append(changes, change)
+	}
+	if len(changes) == 0 {
+		return "", nil
+	}
+
+	now := r.now()
+	journal := Journal{Command: command, Time: now, Changes: changes}
+	content, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(r.root, filepath.FromSlash(Dir))
+	if err := r.Fs.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", Dir, err)
+	}
+	// IDs sort by time; bump past an existing journal on clocks too coarse to differ
+	id := fmt.Sprintf("%020d", now.UnixNano())
+	for n := now.UnixNano() + 1; ; n++ {
+		if exists, _ := afero.Exists(r.Fs, filepath.Join(dir, id+".json")); !exists {
+			break
+		}
+		id = fmt.Sprintf("%020d", n)
+	}
+	if err := afero.WriteFile(r.Fs, filepath.Join(dir, id+".json"), content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write undo journal: %w", err)
+	}
+
+	prune(r.Fs, dir)
+	return id, nil
+}
+
+// prune removes the oldest journals beyond MaxJournals
+func prune(fs afero.Fs, dir string) {
+	ids := journalIDs(fs, dir)
+	for len(ids) > MaxJournals {
+		_ = fs.Remove(filepath.Join(dir, ids[0]+".json"))
+		ids = ids[1:]
+	}
+}
+
+// journalIDs lists the journal IDs in dir, oldest first
+func journalIDs(fs afero.Fs, dir string) []string {
+	entries, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			ids = append(ids, strings.TrimSuffix(entry.Name(), ".json"))
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// resolve turns a journal path back into a filesystem path
+func resolve(root, path string) string {
+	if filepath.IsAbs(filepath.FromSlash(path)) {
+		return filepath.FromSlash(path)
+	}
+	return filepath.Join(root, filepath.FromSlash(path))
+}
+
+// hashFile returns the SHA-256 of a file's content, or "" when it does not exist
+func hashFile(fs afero.Fs, path string) string {
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
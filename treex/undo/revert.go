@@ -0,0 +1,124 @@
+package undo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// ErrNothingToUndo is returned when the project has no journals
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// List returns the journals of the project at root, newest first
+func List(fs afero.Fs, root string) ([]Journal, error) {
+	dir := filepath.Join(root, filepath.FromSlash(Dir))
+	ids := journalIDs(fs, dir)
+
+	journals := make([]Journal, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		journal, err := load(fs, dir, ids[i])
+		if err != nil {
+			return nil, err
+		}
+		journals = append(journals, journal)
+	}
+	return journals, nil
+}
+
+// RevertResult reports what Revert did
+type RevertResult struct {
+	Journal  Journal
+	Restored []string // Files written back or removed
+	Kept     []string // Directories left in place because they are not empty
+}
+
+// Revert restores the state recorded by the newest journal and deletes the journal
+//
+// Files edited since the operation are not overwritten unless force is set; the error
+// lists them.
+func Revert(fs afero.Fs, root string, force bool) (*RevertResult, error) {
+	dir := filepath.Join(root, filepath.FromSlash(Dir))
+	ids := journalIDs(fs, dir)
+	if len(ids) == 0 {
+		return nil, ErrNothingToUndo
+	}
+	journal, err := load(fs, dir, ids[len(ids)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	if !force {
+		var modified []string
+		for _, change := range journal.Changes {
+			if change.Kind == KindFile && hashFile(fs, resolve(root, change.Path)) != change.After {
+				modified = append(modified, change.Path)
+			}
+		}
+		if len(modified) > 0 {
+			return nil, fmt.Errorf("changed since %q ran: %s (use --force to undo anyway)",
+				journal.Command, strings.Join(modified, ", "))
+		}
+	}
+
+	result := &RevertResult{Journal: journal}
+	for i := len(journal.Changes) - 1; i >= 0; i-- {
+		change := journal.Changes[i]
+		target := resolve(root, change.Path)
+
+		switch {
+		case change.Kind == KindFile && change.Existed:
+			if err := fs.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return nil, fmt.Errorf("failed to restore %s: %w", change.Path, err)
+			}
+			if err := afero.WriteFile(fs, target, change.Before, change.Mode); err != nil {
+				return nil, fmt.Errorf("failed to restore %s: %w", change.Path, err)
+			}
+			result.Restored = append(result.Restored, change.Path)
+		case change.Kind == KindFile:
+			if exists, _ := afero.Exists(fs, target); exists {
+				if err := fs.Remove(target); err != nil {
+					return nil, fmt.Errorf("failed to remove %s: %w", change.Path, err)
+				}
+				result.Restored = append(result.Restored, change.Path)
+			}
+		case change.Kind == KindDir && change.Existed:
+			if err := fs.MkdirAll(target, 0755); err != nil {
+				return nil, fmt.Errorf("failed to restore %s: %w", change.Path, err)
+			}
+		default:
+			if exists, _ := afero.DirExists(fs, target); !exists {
+				continue
+			}
+			if empty, _ := afero.IsEmpty(fs, target); !empty {
+				result.Kept = append(result.Kept, change.Path)
+				continue
+			}
+			if err := fs.Remove(target); err != nil {
+				return nil, fmt.Errorf("failed to remove %s: %w", change.Path, err)
+			}
+		}
+	}
+
+	if err := fs.Remove(filepath.Join(dir, journal.ID+".json")); err != nil {
+		return nil, fmt.Errorf("failed to remove undo journal: %w", err)
+	}
+	return result, nil
+}
+
+// load reads one journal
+func load(fs afero.Fs, dir, id string) (Journal, error) {
+	content, err := afero.ReadFile(fs, filepath.Join(dir, id+".json"))
+	if err != nil {
+		return Journal{}, fmt.Errorf("failed to read undo journal %s: %w", id, err)
+	}
+	var journal Journal
+	if err := json.Unmarshal(content, &journal); err != nil {
+		return Journal{}, fmt.Errorf("invalid undo journal %s: %w", id, err)
+	}
+	journal.ID = id
+	return journal, nil
+}
@@ -0,0 +1,123 @@
+package undo_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex/undo"
+)
+
+func newProject(t *testing.T) afero.Fs {
+	t.Helper()
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", map[string]interface{}{
+		".info":     "README.md  Overview\n",
+		"README.md": "# project",
+	})
+	return fs
+}
+
+func TestRevertRestoresEditedAndCreatedFiles(t *testing.T) {
+	fs := newProject(t)
+
+	recorder := undo.NewRecorder(fs, "/project")
+	require.NoError(t, afero.WriteFile(recorder, "/project/.info", []byte("README.md  Changed\n"), 0644))
+	require.NoError(t, recorder.MkdirAll("/project/cmd/sub", 0755))
+	require.NoError(t, afero.WriteFile(recorder, "/project/cmd/sub/.info", []byte("x  New\n"), 0644))
+	require.NoError(t, afero.WriteFile(recorder, "/project/.info", []byte("README.md  Twice\n"), 0644))
+
+	changes := recorder.Changes()
+	require.Len(t, changes, 4)
+	assert.Equal(t, undo.Change{Path: ".info", Kind: undo.KindFile, Existed: true, Before: []byte("README.md  Overview\n"), Mode: 0644}, changes[0])
+	assert.Equal(t, "cmd", changes[1].Path)
+	assert.Equal(t, "cmd/sub", changes[2].Path)
+
+	id, err := recorder.Save("treex add README.md")
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+
+	journals, err := undo.List(fs, "/project")
+	require.NoError(t, err)
+	require.Len(t, journals, 1)
+	assert.Equal(t, "treex add README.md", journals[0].Command)
+
+	result, err := undo.Revert(fs, "/project", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cmd/sub/.info", ".info"}, result.Restored)
+
+	content, err := afero.ReadFile(fs, "/project/.info")
+	require.NoError(t, err)
+	assert.Equal(t, "README.md  Overview\n", string(content))
+	exists, _ := afero.Exists(fs, "/project/cmd")
+	assert.False(t, exists, "created directories are removed")
+
+	_, err = undo.Revert(fs, "/project", false)
+	assert.ErrorIs(t, err, undo.ErrNothingToUndo)
+}
+
+func TestRevertRefusesLaterEdits(t *testing.T) {
+	fs := newProject(t)
+
+	recorder := undo.NewRecorder(fs, "/project")
+	require.NoError(t, afero.WriteFile(recorder, "/project/.info", []byte("README.md  Changed\n"), 0644))
+	_, err := recorder.Save("treex harvest")
+	require.NoError(t, err)
+
+	require.NoError(t, afero.WriteFile(fs, "/project/.info", []byte("README.md  By hand\n"), 0644))
+
+	_, err = undo.Revert(fs, "/project", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ".info")
+	assert.Contains(t, err.Error(), "--force")
+
+	_, err = undo.Revert(fs, "/project", true)
+	require.NoError(t, err)
+	content, _ := afero.ReadFile(fs, "/project/.info")
+	assert.Equal(t, "README.md  Overview\n", string(content))
+}
+
+func TestRevertKeepsNonEmptyDirectories(t *testing.T) {
+	fs := newProject(t)
+
+	recorder := undo.NewRecorder(fs, "/project")
+	require.NoError(t, recorder.MkdirAll("/project/docs", 0755))
+	require.NoError(t, afero.WriteFile(recorder, "/project/docs/a.md", []byte("a"), 0644))
+	_, err := recorder.Save("treex make-tree")
+	require.NoError(t, err)
+
+	require.NoError(t, afero.WriteFile(fs, "/project/docs/b.md", []byte("b"), 0644))
+
+	result, err := undo.Revert(fs, "/project", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs"}, result.Kept)
+	exists, _ := afero.Exists(fs, "/project/docs/b.md")
+	assert.True(t, exists)
+}
+
+func TestSaveWithoutChangesWritesNothing(t *testing.T) {
+	fs := newProject(t)
+
+	id, err := undo.NewRecorder(fs, "/project").Save("treex add")
+	require.NoError(t, err)
+	assert.Empty(t, id)
+	exists, _ := afero.Exists(fs, "/project/.treex")
+	assert.False(t, exists)
+}
+
+func TestSavePrunesOldJournals(t *testing.T) {
+	fs := newProject(t)
+
+	for i := 0; i < undo.MaxJournals+3; i++ {
+		recorder := undo.NewRecorder(fs, "/project")
+		require.NoError(t, afero.WriteFile(recorder, "/project/.info", []byte{byte('a' + i)}, 0644))
+		_, err := recorder.Save("treex add")
+		require.NoError(t, err)
+	}
+
+	journals, err := undo.List(fs, "/project")
+	require.NoError(t, err)
+	assert.Len(t, journals, undo.MaxJournals)
+}
@@ -0,0 +1,102 @@
+// Package verify compares a built tree against an expected structure, written as a tree
+// diagram or structure input (see treetext.Parse and maketree.ParseStructure), so a project
+// layout convention can be enforced in CI.
+//
+// The spec root and every spec directory with listed children are closed: anything else
+// found directly inside them is extra. A directory listed without children may hold
+// anything. An extra path with the same name and kind as a missing one is reported once,
+// as misplaced. .info files are never extra.
+package verify
+
+import (
+	"path"
+	"sort"
+
+	"treex/treex/treetext"
+	"treex/treex/types"
+)
+
+// Finding kinds
+const (
+	KindMissing   = "missing"    // Required by the spec but absent
+	KindExtra     = "extra"      // Present inside a closed directory but not in the spec
+	KindMisplaced = "misplaced"  // Required path found elsewhere under the same name
+	KindWrongType = "wrong-type" // Present, but a file where a directory is required or vice versa
+)
+
+// Finding is one difference between the tree and the spec; paths are relative to the root
+type Finding struct {
+	Kind  string `json:"kind"`
+	Path  string `json:"path"`            // Spec path (tree path for extra entries)
+	Found string `json:"found,omitempty"` // Where a misplaced path was found
+	IsDir bool   `json:"is_dir"`
+}
+
+// Compare reports how the tree below root differs from the spec entries, sorted by path
+func Compare(spec []treetext.Entry, root *types.Node) []Finding {
+	expected := make(map[string]treetext.Entry, len(spec))
+	closed := map[string]bool{".": true}
+	for _, entry := range spec {
+		expected[entry.Path] = entry
+		closed[path.Dir(entry.Path)] = true
+	}
+	// Parents a spec leaves implicit are still required directories
+	for _, entry := range spec {
+		for dir := path.Dir(entry.Path); dir != "."; dir = path.Dir(dir) {
+			if _, listed := expected[dir]; !listed {
+				expected[dir] = treetext.Entry{Path: dir, IsDir: true}
+			}
+		}
+	}
+
+	actual := make(map[string]*types.Node)
+	var extra []Finding
+	var walk func(node *types.Node)
+	walk = func(node *types.Node) {
+		for _, child := range node.Children {
+			actual[child.Path] = child
+			if _, listed := expected[child.Path]; !listed {
+				if closed[node.Path] && child.Name != ".info" {
+					extra = append(extra, Finding{Kind: KindExtra, Path: child.Path, IsDir: child.IsDir})
+				}
+				continue
+			}
+			walk(child)
+		}
+	}
+	if root != nil {
+		walk(root)
+	}
+
+	var findings, missing []Finding
+	for _, entry := range spec {
+		node, found := actual[entry.Path]
+		switch {
+		case !found:
+			missing = append(missing, Finding{Kind: KindMissing, Path: entry.Path, IsDir: entry.IsDir})
+		case node.IsDir != entry.IsDir:
+			findings = append(findings, Finding{Kind: KindWrongType, Path: entry.Path, IsDir: entry.IsDir})
+		}
+	}
+
+	// Pair each missing path with an extra one of the same name and kind
+	for i := range missing {
+		for j := range extra {
+			if extra[j].Kind == KindExtra && extra[j].IsDir == missing[i].IsDir &&
+				path.Base(extra[j].Path) == path.Base(missing[i].Path) {
+				missing[i].Kind, missing[i].Found = KindMisplaced, extra[j].Path
+				extra[j].Kind = ""
+				break
+			}
+		}
+	}
+	findings = append(findings, missing...)
+	for _, finding := range extra {
+		if finding.Kind != "" {
+			findings = append(findings, finding)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Path < findings[j].Path })
+	return findings
+}
@@ -0,0 +1,91 @@
+package verify_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"treex/internal/testutil"
+	"treex/treex"
+	"treex/treex/treetext"
+	"treex/treex/types"
+	"treex/treex/verify"
+)
+
+// buildTree builds the tree of structure rooted at /project
+func buildTree(t *testing.T, structure map[string]interface{}) *types.Node {
+	t.Helper()
+	fs := testutil.NewTestFS()
+	fs.MustCreateTree("/project", structure)
+	config := treex.DefaultTreeConfig("/project")
+	config.Filesystem = fs
+	result, err := treex.BuildTree(config)
+	require.NoError(t, err)
+	return result.Root
+}
+
+// parseSpec parses a tree diagram spec
+func parseSpec(t *testing.T, diagram string) []treetext.Entry {
+	t.Helper()
+	entries, err := treetext.Parse(strings.NewReader(diagram))
+	require.NoError(t, err)
+	return entries
+}
+
+const spec = `project
+├─ cmd/
+│  └─ main.go     Entry point
+├─ docs/
+│  └─ index.md
+├─ vendor/        Anything goes
+├─ build/
+└─ README.md
+`
+
+func TestCompareMatches(t *testing.T) {
+	root := buildTree(t, map[string]interface{}{
+		".info":     "README.md  Overview\n",
+		"README.md": "# project",
+		"cmd":       map[string]interface{}{"main.go": "package main"},
+		"docs":      map[string]interface{}{"index.md": "# docs", ".info": "index.md  Start here\n"},
+		"vendor":    map[string]interface{}{"lib": map[string]interface{}{"x.go": "package lib"}},
+		"build":     map[string]interface{}{},
+		".git":      map[string]interface{}{"HEAD": "ref: refs/heads/main"},
+	})
+
+	assert.Empty(t, verify.Compare(parseSpec(t, spec), root))
+}
+
+func TestCompareReportsDifferences(t *testing.T) {
+	root := buildTree(t, map[string]interface{}{
+		"README.md": map[string]interface{}{},
+		"main.go":   "package main",
+		"tmp.txt":   "scratch",
+		"cmd":       map[string]interface{}{"notes.txt": "todo"},
+		"docs":      map[string]interface{}{},
+		"vendor":    map[string]interface{}{"anything": "ok"},
+		"stray":     map[string]interface{}{"deep.txt": "not reported separately"},
+	})
+
+	assert.Equal(t, []verify.Finding{
+		{Kind: verify.KindWrongType, Path: "README.md"},
+		{Kind: verify.KindMissing, Path: "build", IsDir: true},
+		{Kind: verify.KindMisplaced, Path: "cmd/main.go", Found: "main.go"},
+		{Kind: verify.KindExtra, Path: "cmd/notes.txt"},
+		{Kind: verify.KindMissing, Path: "docs/index.md"},
+		{Kind: verify.KindExtra, Path: "stray", IsDir: true},
+		{Kind: verify.KindExtra, Path: "tmp.txt"},
+	}, verify.Compare(parseSpec(t, spec), root))
+}
+
+func TestCompareImplicitParents(t *testing.T) {
+	root := buildTree(t, map[string]interface{}{
+		"src": map[string]interface{}{"app": map[string]interface{}{"main.go": "package main"}},
+	})
+	spec := []treetext.Entry{{Path: "src/app/main.go"}}
+
+	assert.Empty(t, verify.Compare(spec, root))
+	assert.Equal(t, []verify.Finding{{Kind: verify.KindMissing, Path: "src/app/main.go"}},
+		verify.Compare(spec, buildTree(t, map[string]interface{}{"src": map[string]interface{}{"app": map[string]interface{}{}}})))
+}